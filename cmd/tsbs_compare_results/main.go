@@ -0,0 +1,113 @@
+// tsbs_compare_results checks that the queries of a run returned the same
+// results as the queries of a reference run.
+//
+// Both runs save a digest of the result of each query with the
+// --results-digest flag of the query runners. The runs must use the same query
+// file, or query files generated with the same parameters and seed, for the
+// queries to be compared by ID. The reference can be another database loaded
+// with the same data, or a previous run against a trusted configuration.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Program option vars:
+var (
+	referenceFile string
+	resultsFile   string
+	maxReported   int
+)
+
+// Parse args:
+func init() {
+	pflag.StringVar(&referenceFile, "reference", "", "Result digests of the reference run")
+	pflag.StringVar(&resultsFile, "results", "", "Result digests of the run to check")
+	pflag.IntVar(&maxReported, "max-reported", 20, "Max number of differing queries to list, 0 = all")
+	pflag.Parse()
+
+	if referenceFile == "" || resultsFile == "" {
+		fmt.Fprintln(os.Stderr, "both --reference and --results are required")
+		pflag.Usage()
+		os.Exit(2)
+	}
+}
+
+func main() {
+	reference, err := query.ReadResultDigests(referenceFile)
+	if err != nil {
+		fatal(err)
+	}
+	results, err := query.ReadResultDigests(resultsFile)
+	if err != nil {
+		fatal(err)
+	}
+	if reference.Precision != results.Precision {
+		fatal(fmt.Errorf("the digests were made with different precisions: %d and %d", reference.Precision, results.Precision))
+	}
+	checkHeaders(reference.QueryFileHeader, results.QueryFileHeader)
+
+	c := query.CompareResultDigests(reference, results)
+	fmt.Printf("%d queries compared: %d match, %d differ, %d missing, %d extra\n",
+		len(reference.Results), c.Matched, len(c.Mismatched), len(c.Missing), len(c.Extra))
+	printMismatchesByLabel(c)
+	for i, m := range c.Mismatched {
+		if maxReported > 0 && i == maxReported {
+			fmt.Printf("... and %d more\n", len(c.Mismatched)-i)
+			break
+		}
+		fmt.Printf("differs: query %d (%s): %d rows in reference, %d rows in results (%s)\n",
+			m.Reference.ID, m.Reference.Label, m.Reference.Rows, m.Result.Rows, m.Result.Label)
+	}
+	for _, d := range c.Missing {
+		fmt.Printf("missing: query %d (%s) has no result\n", d.ID, d.Label)
+	}
+	for _, d := range c.Extra {
+		fmt.Printf("extra: query %d (%s) is not in the reference\n", d.ID, d.Label)
+	}
+	if !c.OK() {
+		os.Exit(1)
+	}
+}
+
+// checkHeaders warns when the query files of the runs were not generated
+// with the same use case and seed, as the queries are then unrelated.
+func checkHeaders(reference, results *query.Header) {
+	if reference == nil || results == nil {
+		return
+	}
+	if reference.Use != results.Use || reference.Seed != results.Seed {
+		fmt.Fprintf(os.Stderr, "warning: the query files differ: use case %s, seed %d in reference, use case %s, seed %d in results\n",
+			reference.Use, reference.Seed, results.Use, results.Seed)
+	}
+}
+
+// printMismatchesByLabel prints how many queries of each type differ.
+func printMismatchesByLabel(c *query.DigestComparison) {
+	if len(c.Mismatched) == 0 {
+		return
+	}
+	counts := map[string]int{}
+	for _, m := range c.Mismatched {
+		counts[m.Reference.Label]++
+	}
+	labels := make([]string, 0, len(counts))
+	for l := range counts {
+		labels = append(labels, l)
+	}
+	sort.Strings(labels)
+	fmt.Println("differing queries by type:")
+	for _, l := range labels {
+		fmt.Printf("  %s: %d\n", l, counts[l])
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
@@ -0,0 +1,91 @@
+// tsbs_coordinator runs one benchmark with several client processes, on one
+// machine or on many, when a single client cannot saturate the database.
+//
+// The loaders and query runners join it with --coordinator (or
+// loader.runner.coordinator and queries.runner.coordinator in the config
+// files) and are then its agents: each one loads every n-th point, or runs
+// every n-th query, of its input, so the agents together cover the whole
+// input once if they all read the same one. The coordinator starts them
+// together once all --agents have joined and are ready, prints their summed
+// progress and, once they are all done, merges their counters and latency
+// histograms into a single result.
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/coordination"
+)
+
+// Program option vars:
+var (
+	addr            string
+	agents          uint
+	resultsFile     string
+	reportingPeriod time.Duration
+)
+
+// Parse args:
+func init() {
+	pflag.StringVar(&addr, "addr", ":8090", "Address to listen on for the agents")
+	pflag.UintVar(&agents, "agents", 2, "Number of agents taking part in the benchmark")
+	pflag.StringVar(&resultsFile, "results-file", "", "Write the merged results json to this file")
+	pflag.DurationVar(&reportingPeriod, "reporting-period", 10*time.Second, "Period to print the progress of the agents at, 0 = never")
+	pflag.Parse()
+}
+
+func main() {
+	c := coordination.NewCoordinator(agents, resultsFile)
+	listening, err := c.Start(addr)
+	if err != nil {
+		fatal(err)
+	}
+	fmt.Printf("waiting for %d agents on %s\n", agents, listening)
+	merged, err := c.Wait(reportingPeriod)
+	if err != nil {
+		fatal(err)
+	}
+	printTotals(merged)
+}
+
+func printTotals(m *coordination.MergedResult) {
+	took := time.Duration(m.DurationMillis) * time.Millisecond
+	fmt.Printf("%d agents ran %s in %s\n", m.Agents, m.Kind, took)
+	for _, a := range m.AgentResults {
+		fmt.Printf("  agent %d: %v in %s\n", a.Agent+1, a.Counters, time.Duration(a.DurationMillis)*time.Millisecond)
+	}
+	switch m.Kind {
+	case coordination.KindLoad:
+		fmt.Printf("loaded %v metrics (%.2f/sec)", m.Totals[coordination.CounterMetrics], m.Totals["metricRate"])
+		if rate, ok := m.Totals["rowRate"]; ok {
+			fmt.Printf(", %v rows (%.2f/sec)", m.Totals[coordination.CounterRows], rate)
+		}
+		fmt.Println()
+	case coordination.KindQueries:
+		fmt.Printf("ran %v queries (%.2f/sec), %v errors, %v timeouts\n", m.Totals[coordination.CounterQueries],
+			m.Totals["queryRate"], m.Totals[coordination.CounterErrors], m.Totals[coordination.CounterTimeouts])
+		quantiles, _ := m.Totals["overallQuantiles"].(map[string]interface{})
+		for _, label := range sortedKeys(quantiles) {
+			q := quantiles[label].(map[string]float64)
+			fmt.Printf("  %s: p50 %.2fms, p95 %.2fms, p99 %.2fms, max %.2fms\n", label, q["q50"], q["q95"], q["q99"], q["q100"])
+		}
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
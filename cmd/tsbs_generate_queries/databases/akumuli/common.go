@@ -4,8 +4,10 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 // BaseGenerator contains settings specific for Akumuli database.
@@ -43,3 +45,8 @@ func (g *BaseGenerator) NewDevops(start, end time.Time, scale int) (utils.QueryG
 
 	return devops, nil
 }
+
+// FillInTemplate fills in a query.Query from the 'akumuli' query of a query template.
+func (g *BaseGenerator) FillInTemplate(qi query.Query, t *templates.Instance) {
+	g.fillInQuery(qi, t.Label, t.Description, t.MustRender(constants.FormatAkumuli), t.Interval.StartUnixNano(), t.Interval.EndUnixNano())
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	internalutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -126,6 +127,10 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	if d.UseRollups {
+		d.groupByTimeAndPrimaryTagRollup(qi, metrics, interval)
+		return
+	}
 
 	selectClauses := make([]string, numMetrics)
 	meanClauses := make([]string, numMetrics)
@@ -175,6 +180,51 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
 }
 
+// groupByTimeAndPrimaryTagRollup is the form of GroupByTimeAndPrimaryTag that
+// merges the hourly aggregate states stored in the rollup materialized view
+// instead of aggregating the raw data.
+func (d *Devops) groupByTimeAndPrimaryTagRollup(qi query.Query, metrics []string, interval *internalutils.TimeInterval) {
+	selectClauses := make([]string, len(metrics))
+	meanClauses := make([]string, len(metrics))
+	for i, m := range metrics {
+		meanClauses[i] = "mean_" + m
+		selectClauses[i] = fmt.Sprintf("avgMerge(%s) AS %s", m, meanClauses[i])
+	}
+
+	// the rollup only keeps tags_id, so hostname has to come from the tags table
+	sql := fmt.Sprintf(`
+        SELECT
+            hour,
+            hostname,
+            %s
+        FROM
+        (
+            SELECT
+                hour,
+                tags_id AS id,
+                %s
+            FROM %s
+            WHERE (hour >= '%s') AND (hour < '%s')
+            GROUP BY
+                hour,
+                id
+        ) AS cpu_avg
+        ANY INNER JOIN tags USING (id)
+        ORDER BY
+            hour ASC,
+            hostname
+        `,
+		strings.Join(meanClauses, ", "),                     // main SELECT %s
+		strings.Join(selectClauses, ", "),                   // cpu_avg SELECT %s
+		devops.RollupTableName,                              // cpu_avg FROM %s
+		interval.Start().Format(clickhouseTimeStringFormat), // cpu_avg hour >= '%s'
+		interval.End().Format(clickhouseTimeStringFormat))   // cpu_avg hour < '%s'
+
+	humanLabel := devops.GetDoubleGroupByRollupLabel("ClickHouse", len(metrics))
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, devops.RollupTableName, sql)
+}
+
 // GroupByOrderByLimit populates a query.Query that has a time WHERE clause, that groups by a truncated date, orders by that date, and takes a limit:
 // SELECT time_bucket('1 minute', time) AS t, MAX(cpu)
 // FROM cpu
@@ -327,3 +377,75 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
 	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same nHosts hosts,
+// e.g. in pseudo-SQL:
+//
+// SELECT hour, hostname, mean_usage_user, mean_used_percent
+// FROM (SELECT hour, hostname, AVG(usage_user) FROM cpu ...)
+// INNER JOIN (SELECT hour, hostname, AVG(used_percent) FROM mem ...)
+// USING (hour, hostname)
+// ORDER BY hour, hostname
+//
+// Resultsets:
+// cpu-mem-correlation
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostWhere := d.getHostWhereString(nHosts)
+
+	partitionColumn := "hostname"
+	partitionSelect := "hostname"
+	joinClause := ""
+	if d.UseTags {
+		partitionColumn = "id"
+		partitionSelect = "tags_id AS id"
+		joinClause = "ANY INNER JOIN tags USING (id)"
+	}
+
+	sql := fmt.Sprintf(`
+        SELECT
+            hour,
+            hostname,
+            mean_usage_user,
+            mean_used_percent
+        FROM
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                %[1]s,
+                avg(usage_user) AS mean_usage_user
+            FROM cpu
+            WHERE %[3]s AND (created_at >= '%[4]s') AND (created_at < '%[5]s')
+            GROUP BY
+                hour,
+                %[2]s
+        ) AS cpu_avg
+        ALL INNER JOIN
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                %[1]s,
+                avg(used_percent) AS mean_used_percent
+            FROM mem
+            WHERE %[3]s AND (created_at >= '%[4]s') AND (created_at < '%[5]s')
+            GROUP BY
+                hour,
+                %[2]s
+        ) AS mem_avg USING (hour, %[2]s)
+        %[6]s
+        ORDER BY
+            hour ASC,
+            hostname
+        `,
+		partitionSelect, // cpu_avg and mem_avg SELECT %s
+		partitionColumn, // GROUP BY and USING %s
+		hostWhere,
+		interval.Start().Format(clickhouseTimeStringFormat),
+		interval.End().Format(clickhouseTimeStringFormat),
+		joinClause) // JOIN tags clause
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("ClickHouse", nHosts, duration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
+}
@@ -164,6 +164,105 @@ func TestMaxAllCPU(t *testing.T) {
 	runTestCases(t, testFunc, start, end, cases)
 }
 
+func TestCPUMemCorrelation(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:               "2 hosts",
+			input:              2,
+			expectedHumanLabel: "ClickHouse cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h",
+			expectedHumanDesc:  "ClickHouse cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z",
+			expectedQuery: `
+        SELECT
+            hour,
+            hostname,
+            mean_usage_user,
+            mean_used_percent
+        FROM
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                hostname,
+                avg(usage_user) AS mean_usage_user
+            FROM cpu
+            WHERE (hostname = 'host_9' OR hostname = 'host_3') AND (created_at >= '1970-01-01 00:16:22') AND (created_at < '1970-01-01 12:16:22')
+            GROUP BY
+                hour,
+                hostname
+        ) AS cpu_avg
+        ALL INNER JOIN
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                hostname,
+                avg(used_percent) AS mean_used_percent
+            FROM mem
+            WHERE (hostname = 'host_9' OR hostname = 'host_3') AND (created_at >= '1970-01-01 00:16:22') AND (created_at < '1970-01-01 12:16:22')
+            GROUP BY
+                hour,
+                hostname
+        ) AS mem_avg USING (hour, hostname)
+        
+        ORDER BY
+            hour ASC,
+            hostname
+        `,
+		},
+		{
+			desc:               "2 hosts w/ tags",
+			input:              2,
+			devopsUseTags:      true,
+			expectedHumanLabel: "ClickHouse cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h",
+			expectedHumanDesc:  "ClickHouse cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h: 1970-01-01T00:37:12Z",
+			expectedQuery: `
+        SELECT
+            hour,
+            hostname,
+            mean_usage_user,
+            mean_used_percent
+        FROM
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                tags_id AS id,
+                avg(usage_user) AS mean_usage_user
+            FROM cpu
+            WHERE tags_id IN (SELECT id FROM tags WHERE hostname IN ('host_9','host_5')) AND (created_at >= '1970-01-01 00:37:12') AND (created_at < '1970-01-01 12:37:12')
+            GROUP BY
+                hour,
+                id
+        ) AS cpu_avg
+        ALL INNER JOIN
+        (
+            SELECT
+                toStartOfHour(created_at) AS hour,
+                tags_id AS id,
+                avg(used_percent) AS mean_used_percent
+            FROM mem
+            WHERE tags_id IN (SELECT id FROM tags WHERE hostname IN ('host_9','host_5')) AND (created_at >= '1970-01-01 00:37:12') AND (created_at < '1970-01-01 12:37:12')
+            GROUP BY
+                hour,
+                id
+        ) AS mem_avg USING (hour, id)
+        ANY INNER JOIN tags USING (id)
+        ORDER BY
+            hour ASC,
+            hostname
+        `,
+		},
+	}
+
+	testFunc := func(d *Devops, c testCase) query.Query {
+		q := d.GenerateEmptyQuery()
+		d.CPUMemCorrelation(q, c.input, devops.CPUMemCorrelationDuration)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(devops.CPUMemCorrelationDuration).Add(time.Hour)
+
+	runTestCases(t, testFunc, start, end, cases)
+}
+
 func TestGroupByTimeAndPrimaryTag(t *testing.T) {
 	cases := []testCase{
 		{
@@ -283,6 +382,51 @@ func TestGroupByTimeAndPrimaryTag(t *testing.T) {
 	runTestCases(t, testFunc, start, end, cases)
 }
 
+func TestGroupByTimeAndPrimaryTagRollup(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:               "one metric",
+			input:              1,
+			expectedHumanLabel: "ClickHouse mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup)",
+			expectedHumanDesc:  "ClickHouse mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup): 1970-01-01T00:16:22Z",
+			expectedQuery: `
+        SELECT
+            hour,
+            hostname,
+            mean_usage_user
+        FROM
+        (
+            SELECT
+                hour,
+                tags_id AS id,
+                avgMerge(usage_user) AS mean_usage_user
+            FROM cpu_1h
+            WHERE (hour >= '1970-01-01 00:16:22') AND (hour < '1970-01-01 12:16:22')
+            GROUP BY
+                hour,
+                id
+        ) AS cpu_avg
+        ANY INNER JOIN tags USING (id)
+        ORDER BY
+            hour ASC,
+            hostname
+        `,
+		},
+	}
+
+	testFunc := func(d *Devops, c testCase) query.Query {
+		d.UseRollups = true
+		q := d.GenerateEmptyQuery()
+		d.GroupByTimeAndPrimaryTag(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(devops.DoubleGroupByDuration).Add(time.Hour)
+
+	runTestCases(t, testFunc, start, end, cases)
+}
+
 func TestGroupByOrderByLimit(t *testing.T) {
 	cases := []testCase{
 		{
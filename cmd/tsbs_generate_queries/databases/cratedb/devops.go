@@ -214,3 +214,42 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
 	d.fillInQuery(qi, humanLabel, humanDesc, sql)
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same N random hosts
+//
+// Queries:
+// cpu-mem-correlation
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hosts, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT c.hour, c.host, c.mean_usage_user, m.mean_used_percent
+		FROM (
+			SELECT date_trunc('hour', ts) AS hour, %[1]s AS host, avg(usage_user) AS mean_usage_user
+			FROM cpu
+			WHERE %[1]s IN ('%[2]s')
+			  AND ts >= %[3]d
+			  AND ts < %[4]d
+			GROUP BY hour, host
+		) c
+		JOIN (
+			SELECT date_trunc('hour', ts) AS hour, %[1]s AS host, avg(used_percent) AS mean_used_percent
+			FROM mem
+			WHERE %[1]s IN ('%[2]s')
+			  AND ts >= %[3]d
+			  AND ts < %[4]d
+			GROUP BY hour, host
+		) m ON c.hour = m.hour AND c.host = m.host
+		ORDER BY c.hour, c.host`,
+		hostnameField,
+		strings.Join(hosts, "', '"),
+		interval.StartUnixMillis(),
+		interval.EndUnixMillis())
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("CrateDB", nHosts, duration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
@@ -259,3 +259,47 @@ func TestDevopsGroupByTimeQuery(t *testing.T) {
 			got.SqlQuery, want.SqlQuery)
 	}
 }
+
+func TestDevopsCPUMemCorrelationQuery(t *testing.T) {
+	// return the same set of random hosts deterministic
+	rand.Seed(100)
+
+	start := time.Date(2006, 1, 1, 10, 0, 0, 0, time.UTC)
+	end := time.Date(2006, 1, 2, 10, 0, 0, 0, time.UTC)
+	d := assertNewDevops(t, start, end)
+
+	want := &query.CrateDB{
+		Table: []byte("cpu"),
+		SqlQuery: []byte(`
+		SELECT c.hour, c.host, c.mean_usage_user, m.mean_used_percent
+		FROM (
+			SELECT date_trunc('hour', ts) AS hour, tags['hostname'] AS host, avg(usage_user) AS mean_usage_user
+			FROM cpu
+			WHERE tags['hostname'] IN ('host_8', 'host_0')
+			  AND ts >= 1136134513823
+			  AND ts < 1136177713823
+			GROUP BY hour, host
+		) c
+		JOIN (
+			SELECT date_trunc('hour', ts) AS hour, tags['hostname'] AS host, avg(used_percent) AS mean_used_percent
+			FROM mem
+			WHERE tags['hostname'] IN ('host_8', 'host_0')
+			  AND ts >= 1136134513823
+			  AND ts < 1136177713823
+			GROUP BY hour, host
+		) m ON c.hour = m.hour AND c.host = m.host
+		ORDER BY c.hour, c.host`),
+	}
+
+	got := &query.CrateDB{}
+	d.CPUMemCorrelation(got, 2, devops.CPUMemCorrelationDuration)
+
+	if !reflect.DeepEqual(want.SqlQuery, got.SqlQuery) {
+		t.Errorf("incorrect sql query:\ngot: %s\n want:\n %s",
+			got.SqlQuery, want.SqlQuery)
+	}
+	if !reflect.DeepEqual(want.Table, got.Table) {
+		t.Errorf("incorrect table:\ngot: %s\n want:\n %s",
+			got.Table, want.Table)
+	}
+}
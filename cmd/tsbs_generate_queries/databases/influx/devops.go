@@ -89,6 +89,21 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	databases.PanicIfErr(err)
 	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	if d.UseRollups {
+		// the rollup measurement stores the hourly means as mean_<metric>,
+		// one point per host and hour
+		rollupMetrics := make([]string, len(metrics))
+		for i, m := range metrics {
+			rollupMetrics[i] = "mean_" + m
+		}
+		selectClauses := d.getSelectClausesAggMetrics("mean", rollupMetrics)
+
+		humanLabel := devops.GetDoubleGroupByRollupLabel("Influx", numMetrics)
+		humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+		influxql := fmt.Sprintf("SELECT %s from %s where time >= '%s' and time < '%s' group by time(1h),hostname", strings.Join(selectClauses, ", "), devops.RollupTableName, interval.StartString(), interval.EndString())
+		d.fillInQuery(qi, humanLabel, humanDesc, influxql)
+		return
+	}
 	selectClauses := d.getSelectClausesAggMetrics("mean", metrics)
 
 	humanLabel := devops.GetDoubleGroupByLabel("Influx", numMetrics)
@@ -115,6 +130,29 @@ func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
 	d.fillInQuery(qi, humanLabel, humanDesc, influxql)
 }
 
+// CPUMemCorrelation selects the hourly MEAN of usage_user under 'cpu' and of
+// used_percent under 'mem' for the same nHosts hosts. InfluxQL cannot join
+// measurements, so each is read by a statement of the query and the client
+// joins their results on the hostname and hour:
+//
+// SELECT mean(usage_user) AS mean_usage_user FROM cpu
+// WHERE (hostname = '$HOSTNAME_1' OR ... OR hostname = '$HOSTNAME_N')
+// AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY time(1h), hostname fill(none);
+// SELECT mean(used_percent) AS mean_used_percent FROM mem
+// WHERE ... GROUP BY time(1h), hostname fill(none)
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	whereHosts := d.getHostWhereString(nHosts)
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("Influx", nHosts, duration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	statement := "SELECT mean(%s) AS mean_%s from %s where %s and time >= '%s' and time < '%s' group by time(1h),hostname fill(none)"
+	influxql := fmt.Sprintf(statement, "usage_user", "usage_user", "cpu", whereHosts, interval.StartString(), interval.EndString()) + "; " +
+		fmt.Sprintf(statement, "used_percent", "used_percent", "mem", whereHosts, interval.StartString(), interval.EndString())
+	d.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
 // LastPointPerHost finds the last row for every host in the dataset
 func (d *Devops) LastPointPerHost(qi query.Query) {
 	humanLabel := "Influx last row per host"
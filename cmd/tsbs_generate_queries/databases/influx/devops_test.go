@@ -233,6 +233,42 @@ func TestDevopsGroupByTimeAndPrimaryTag(t *testing.T) {
 	runTestCases(t, testFunc, start, end, cases)
 }
 
+func TestDevopsGroupByTimeAndPrimaryTagRollup(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:               "1 metric",
+			input:              1,
+			expectedHumanLabel: "Influx mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup)",
+			expectedHumanDesc:  "Influx mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup): 1970-01-01T00:16:22Z",
+			expectedQuery: "SELECT mean(mean_usage_user) from cpu_1h " +
+				"where time >= '1970-01-01T00:16:22Z' and time < '1970-01-01T12:16:22Z' " +
+				"group by time(1h),hostname",
+		},
+		{
+			desc:               "5 metrics",
+			input:              5,
+			expectedHumanLabel: "Influx mean of 5 metrics, all hosts, random 12h0m0s by 1h (rollup)",
+			expectedHumanDesc:  "Influx mean of 5 metrics, all hosts, random 12h0m0s by 1h (rollup): 1970-01-01T00:54:10Z",
+			expectedQuery: "SELECT mean(mean_usage_user), mean(mean_usage_system), mean(mean_usage_idle), mean(mean_usage_nice), mean(mean_usage_iowait) " +
+				"from cpu_1h " +
+				"where time >= '1970-01-01T00:54:10Z' and time < '1970-01-01T12:54:10Z' " +
+				"group by time(1h),hostname",
+		},
+	}
+
+	testFunc := func(d *Devops, c testCase) query.Query {
+		d.UseRollups = true
+		q := d.GenerateEmptyQuery()
+		d.GroupByTimeAndPrimaryTag(q, c.input)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(devops.DoubleGroupByDuration).Add(time.Hour)
+
+	runTestCases(t, testFunc, start, end, cases)
+}
+
 func TestMaxAllCPU(t *testing.T) {
 	cases := []testCase{
 		{
@@ -354,6 +390,42 @@ func TestHighCPUForHosts(t *testing.T) {
 	runTestCases(t, testFunc, start, end, cases)
 }
 
+func TestCPUMemCorrelation(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:    "zero hosts",
+			input:   0,
+			fail:    true,
+			failMsg: "number of hosts cannot be < 1; got 0",
+		},
+		{
+			desc:               "2 hosts",
+			input:              2,
+			expectedHumanLabel: "Influx cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h",
+			expectedHumanDesc:  "Influx cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h: 1970-01-01T00:54:10Z",
+			expectedQuery: "SELECT mean(usage_user) AS mean_usage_user from cpu " +
+				"where (hostname = 'host_3' or hostname = 'host_5') " +
+				"and time >= '1970-01-01T00:54:10Z' and time < '1970-01-01T12:54:10Z' " +
+				"group by time(1h),hostname fill(none); " +
+				"SELECT mean(used_percent) AS mean_used_percent from mem " +
+				"where (hostname = 'host_3' or hostname = 'host_5') " +
+				"and time >= '1970-01-01T00:54:10Z' and time < '1970-01-01T12:54:10Z' " +
+				"group by time(1h),hostname fill(none)",
+		},
+	}
+
+	testFunc := func(d *Devops, c testCase) query.Query {
+		q := d.GenerateEmptyQuery()
+		d.CPUMemCorrelation(q, c.input, devops.CPUMemCorrelationDuration)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(devops.CPUMemCorrelationDuration).Add(time.Hour)
+
+	runTestCases(t, testFunc, start, end, cases)
+}
+
 func TestDevopsFillInQuery(t *testing.T) {
 	humanLabel := "this is my label"
 	humanDesc := "and now my description"
@@ -0,0 +1,65 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/energy"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Energy produces Influx-specific queries for all the energy query types.
+type Energy struct {
+	*BaseGenerator
+	*energy.Core
+}
+
+// RegionDemand selects the energy consumed per region per hour over a random
+// day:
+//
+// SELECT sum(energy_kwh) FROM meter_readings
+// WHERE time >= '$DAY_START' AND time < '$DAY_END'
+// GROUP BY time(1h), region
+func (e *Energy) RegionDemand(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.RegionDemandDuration)
+	influxql := fmt.Sprintf(`SELECT sum("energy_kwh") AS "demand_kwh" FROM "%s"
+		WHERE time >= '%s' AND time < '%s'
+		GROUP BY time(1h), "region"`,
+		energy.ReadingsTableName, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx hourly demand per region, random 24h"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// PeakDemand selects, for every feeder of a random region, the 15 minutes of
+// a random day with the highest demand and that demand.
+func (e *Energy) PeakDemand(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.PeakDemandDuration)
+	region := e.GetRandomRegion()
+	influxql := fmt.Sprintf(`SELECT max("demand_kw") AS "demand_kw" FROM (
+		SELECT sum("demand_kw") AS "demand_kw" FROM "%s"
+		WHERE "region" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(15m), "feeder")
+		GROUP BY "feeder"`,
+		energy.ReadingsTableName, region, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx peak 15m demand per feeder, random region, random 24h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, region, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// GapFillReadings selects the demand and voltage of a random meter every 15
+// minutes of a random day, carrying the last reading forward over the
+// missing ones.
+func (e *Energy) GapFillReadings(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.GapFillDuration)
+	meter := e.GetRandomMeter()
+	influxql := fmt.Sprintf(`SELECT mean("demand_kw") AS "demand_kw", mean("voltage") AS "voltage" FROM "%s"
+		WHERE "meter" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(15m) fill(previous)`,
+		energy.ReadingsTableName, meter, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx gap-filled readings of a random meter, random 24h by 15m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, meter, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
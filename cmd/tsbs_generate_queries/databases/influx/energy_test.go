@@ -0,0 +1,76 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestEnergyQueries(t *testing.T) {
+	cases := []struct {
+		desc string
+		fill func(*Energy, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc: "region demand",
+			fill: (*Energy).RegionDemand,
+
+			expectedHumanLabel: "Influx hourly demand per region, random 24h",
+			expectedHumanDesc:  "Influx hourly demand per region, random 24h: 1970-01-01T18:16:22Z",
+			expectedQuery: `SELECT sum("energy_kwh") AS "demand_kwh" FROM "meter_readings"
+		WHERE time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'
+		GROUP BY time(1h), "region"`,
+		},
+		{
+			desc: "peak demand",
+			fill: (*Energy).PeakDemand,
+
+			expectedHumanLabel: "Influx peak 15m demand per feeder, random region, random 24h",
+			expectedHumanDesc:  "Influx peak 15m demand per feeder, random region, random 24h: region_0 1970-01-01T18:16:22Z",
+			expectedQuery: `SELECT max("demand_kw") AS "demand_kw" FROM (
+		SELECT sum("demand_kw") AS "demand_kw" FROM "meter_readings"
+		WHERE "region" = 'region_0' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'
+		GROUP BY time(15m), "feeder")
+		GROUP BY "feeder"`,
+		},
+		{
+			desc: "gap fill",
+			fill: (*Energy).GapFillReadings,
+
+			expectedHumanLabel: "Influx gap-filled readings of a random meter, random 24h by 15m",
+			expectedHumanDesc:  "Influx gap-filled readings of a random meter, random 24h by 15m: meter_9 1970-01-01T18:16:22Z",
+			expectedQuery: `SELECT mean("demand_kw") AS "demand_kw", mean("voltage") AS "voltage" FROM "meter_readings"
+		WHERE "meter" = 'meter_9' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'
+		GROUP BY time(15m) fill(previous)`,
+		},
+	}
+
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			g, err := b.NewEnergy(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating energy generator")
+			}
+			q := b.GenerateEmptyQuery()
+			c.fill(g.(*Energy), q)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package influx
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Events produces Influx-specific queries for all the events query types.
+type Events struct {
+	*BaseGenerator
+	*events.Core
+}
+
+// MessageSearch selects the last log lines of a random hour whose message
+// contains a random term:
+//
+// SELECT host, severity, message FROM logs
+// WHERE message =~ /$TERM/ AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// ORDER BY time DESC LIMIT 100
+func (e *Events) MessageSearch(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.MessageSearchDuration)
+	term := e.GetRandomSearchTerm()
+	influxql := fmt.Sprintf(`SELECT "host", "severity", "message" FROM "%s"
+		WHERE "message" =~ /%s/ AND time >= '%s' AND time < '%s'
+		ORDER BY time DESC LIMIT %d`,
+		events.LogsTableName,
+		strings.Replace(regexp.QuoteMeta(term), "/", `\/`, -1),
+		interval.StartString(), interval.EndString(),
+		events.MessageSearchLimit)
+
+	humanLabel := fmt.Sprintf("Influx last %d log lines matching a random term, random 1h", events.MessageSearchLimit)
+	humanDesc := fmt.Sprintf("%s: %q %s", humanLabel, term, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// SeverityCounts selects the number of log lines of a random service per
+// severity per minute over a random hour. InfluxQL cannot group by a field,
+// so the lines of each severity are counted by a statement of their own.
+func (e *Events) SeverityCounts(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.SeverityCountsDuration)
+	service := e.GetRandomService()
+	statements := make([]string, len(events.Severities()))
+	for i, severity := range events.Severities() {
+		statements[i] = fmt.Sprintf(`SELECT count("message") AS "%s" FROM "%s"
+		WHERE "service" = '%s' AND "severity" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(1m)`,
+			severity, events.LogsTableName, service, severity, interval.StartString(), interval.EndString())
+	}
+
+	humanLabel := "Influx log lines per severity of a random service, random 1h by 1m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, service, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, strings.Join(statements, "; "))
+}
+
+// TopErrors selects the number of occurrences of every error message over a
+// random day. InfluxQL cannot group by a field, so each known error message
+// is counted by a statement of its own and the client keeps the most
+// frequent ones.
+func (e *Events) TopErrors(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.TopErrorsDuration)
+	messages := events.ErrorMessages()
+	statements := make([]string, len(messages))
+	for i, message := range messages {
+		statements[i] = fmt.Sprintf(`SELECT count("message") AS "occurrences" FROM "%s"
+		WHERE "severity" = 'error' AND "message" = '%s' AND time >= '%s' AND time < '%s'`,
+			events.LogsTableName, strings.Replace(message, "'", `\'`, -1), interval.StartString(), interval.EndString())
+	}
+
+	humanLabel := fmt.Sprintf("Influx top %d error messages, random 24h", events.TopErrorsLimit)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, strings.Join(statements, "; "))
+}
@@ -0,0 +1,95 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestEventsQueries(t *testing.T) {
+	cases := []struct {
+		desc string
+		fill func(*Events, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc: "message search",
+			fill: (*Events).MessageSearch,
+
+			expectedHumanLabel: "Influx last 100 log lines matching a random term, random 1h",
+			expectedHumanDesc:  "Influx last 100 log lines matching a random term, random 1h: \"slow request\" 1970-01-03T18:16:22Z",
+			expectedQuery: `SELECT "host", "severity", "message" FROM "logs"
+		WHERE "message" =~ /slow request/ AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		ORDER BY time DESC LIMIT 100`,
+		},
+		{
+			desc: "severity counts",
+			fill: (*Events).SeverityCounts,
+
+			expectedHumanLabel: "Influx log lines per severity of a random service, random 1h by 1m",
+			expectedHumanDesc:  "Influx log lines per severity of a random service, random 1h by 1m: auth 1970-01-03T18:16:22Z",
+			expectedQuery: `SELECT count("message") AS "debug" FROM "logs"
+		WHERE "service" = 'auth' AND "severity" = 'debug' AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(1m); SELECT count("message") AS "info" FROM "logs"
+		WHERE "service" = 'auth' AND "severity" = 'info' AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(1m); SELECT count("message") AS "warn" FROM "logs"
+		WHERE "service" = 'auth' AND "severity" = 'warn' AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(1m); SELECT count("message") AS "error" FROM "logs"
+		WHERE "service" = 'auth' AND "severity" = 'error' AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(1m)`,
+		},
+		{
+			desc: "top errors",
+			fill: (*Events).TopErrors,
+
+			expectedHumanLabel: "Influx top 10 error messages, random 24h",
+			expectedHumanDesc:  "Influx top 10 error messages, random 24h: 1970-01-01T18:16:22Z",
+			expectedQuery: `SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'upstream checkout timed out after 30s' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'connection refused by auth:8080' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'rate limit exceeded for client' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'key "cart" not found in session' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'deadlock detected while updating table orders' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'call to payments timed out after 10s' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'connection reset by search-index:9200' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'query parse error near unexpected token' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'invalid token signature' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'connection refused by auth-db:5432' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'card declined by payment provider' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'payment provider did not answer within 15s' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'NullPointerException in PaymentProcessor.capture' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'duplicate key value violates unique constraint reservations_pkey' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'java.lang.OutOfMemoryError: Java heap space' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'connection timed out to smtp-relay:587' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'; SELECT count("message") AS "occurrences" FROM "logs"
+		WHERE "severity" = 'error' AND "message" = 'invalid recipient address' AND time >= '1970-01-01T18:16:22Z' AND time < '1970-01-02T18:16:22Z'`,
+		},
+	}
+
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			g, err := b.NewEvents(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating events generator")
+			}
+			q := b.GenerateEmptyQuery()
+			c.fill(g.(*Events), q)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -323,3 +323,23 @@ func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
 	leftover := minutesPerHour * duration.Hours()
 	return int((durationMinutes - leftover) / 10)
 }
+
+// ReadingsJoinDiagnostics selects the hourly average velocity, fuel state and
+// load of the trucks of a random fleet. InfluxQL cannot join measurements, so
+// readings and diagnostics are each read by a statement of the query and the
+// client joins their results on the truck and hour.
+func (i *IoT) ReadingsJoinDiagnostics(qi query.Query) {
+	interval := i.Interval.MustRandWindow(iot.ReadingsJoinDiagnosticsDuration)
+	where := fmt.Sprintf(`"fleet" = '%s' AND time >= '%s' AND time < '%s'`,
+		i.GetRandomFleet(),
+		interval.Start().Format(time.RFC3339),
+		interval.End().Format(time.RFC3339))
+	influxql := fmt.Sprintf(`SELECT mean("velocity") AS "mean_velocity" FROM "readings" WHERE %[1]s GROUP BY time(1h),"name" fill(none); `+
+		`SELECT mean("fuel_state") AS "mean_fuel_state", mean("current_load") AS "mean_current_load" FROM "diagnostics" WHERE %[1]s GROUP BY time(1h),"name" fill(none)`,
+		where)
+
+	humanLabel := "Influx readings joined with diagnostics per truck per hour"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	i.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
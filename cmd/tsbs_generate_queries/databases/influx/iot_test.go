@@ -471,6 +471,28 @@ func TestTruckBreakdownFrequency(t *testing.T) {
 	}
 }
 
+func TestReadingsJoinDiagnostics(t *testing.T) {
+	b := BaseGenerator{}
+	ig, err := b.NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(25*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("Error while creating iot generator")
+	}
+	g := ig.(*IoT)
+
+	q := g.GenerateEmptyQuery()
+	rand.Seed(123)
+	g.ReadingsJoinDiagnostics(q)
+
+	where := `"fleet" = 'West' AND time >= '1970-01-01T04:16:22Z' AND time < '1970-01-01T16:16:22Z'`
+	v := url.Values{}
+	v.Set("q", `SELECT mean("velocity") AS "mean_velocity" FROM "readings" WHERE `+where+` GROUP BY time(1h),"name" fill(none); `+
+		`SELECT mean("fuel_state") AS "mean_fuel_state", mean("current_load") AS "mean_current_load" FROM "diagnostics" WHERE `+where+` GROUP BY time(1h),"name" fill(none)`)
+	verifyQuery(t, q,
+		"Influx readings joined with diagnostics per truck per hour",
+		"Influx readings joined with diagnostics per truck per hour: 1970-01-01T04:16:22Z",
+		"/query?"+v.Encode())
+}
+
 func TestTenMinutePeriods(t *testing.T) {
 	cases := []struct {
 		minutesPerHour float64
@@ -0,0 +1,124 @@
+package influx
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// K8s produces Influx-specific queries for all the k8s query types.
+type K8s struct {
+	*BaseGenerator
+	*k8s.Core
+}
+
+// NamespaceCPU selects the cores used per namespace of a random cluster, per
+// 5 minutes over a random hour, from the increase of the CPU usage counters:
+//
+// SELECT sum(cpu) / 300 FROM (
+// SELECT spread(usage_seconds_total) AS cpu FROM container_cpu
+// WHERE cluster = '$CLUSTER' AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY time(5m), namespace, pod, container)
+// WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY time(5m), namespace
+func (k *K8s) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NamespaceCPUDuration)
+	cluster := k.GetRandomCluster()
+	influxql := fmt.Sprintf(`SELECT sum("cpu") / 300 AS "cores" FROM (
+		SELECT spread("usage_seconds_total") AS "cpu" FROM "%s"
+		WHERE "cluster" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(5m), "namespace", "pod", "container")
+		WHERE time >= '%[3]s' AND time < '%[4]s'
+		GROUP BY time(5m), "namespace"`,
+		k8s.CPUTableName, cluster, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx cores used per namespace, random cluster, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// NodeNetwork selects the network traffic, in bytes per second, per node of
+// a random cluster, per 5 minutes over a random hour.
+func (k *K8s) NodeNetwork(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NodeNetworkDuration)
+	cluster := k.GetRandomCluster()
+	influxql := fmt.Sprintf(`SELECT (sum("rx") + sum("tx")) / 300 AS "bytes_per_second" FROM (
+		SELECT spread("receive_bytes_total") AS "rx", spread("transmit_bytes_total") AS "tx" FROM "%s"
+		WHERE "cluster" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(5m), "node", "pod", "container")
+		WHERE time >= '%[3]s' AND time < '%[4]s'
+		GROUP BY time(5m), "node"`,
+		k8s.NetworkTableName, cluster, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx network traffic per node, random cluster, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// TopPodsMemory selects the limit pods of a random namespace of a random
+// cluster whose containers used the most memory over a random hour.
+func (k *K8s) TopPodsMemory(qi query.Query, limit int) {
+	interval := k.Interval.MustRandWindow(k8s.TopPodsMemoryDuration)
+	cluster, namespace := k.GetRandomCluster(), k.GetRandomNamespace()
+	influxql := fmt.Sprintf(`SELECT top("max_working_set_bytes", "pod", %d) FROM (
+		SELECT max("working_set_bytes") AS "max_working_set_bytes" FROM "%s"
+		WHERE "cluster" = '%s' AND "namespace" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY "pod")`,
+		limit, k8s.MemoryTableName, cluster, namespace, interval.StartString(), interval.EndString())
+
+	humanLabel := fmt.Sprintf("Influx top %d pods by memory, random namespace, random 1h", limit)
+	humanDesc := fmt.Sprintf("%s: %s/%s %s", humanLabel, cluster, namespace, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// ContainerRestarts selects the number of container restarts per deployment
+// of a random cluster over a random 12 hours.
+func (k *K8s) ContainerRestarts(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.ContainerRestartsDuration)
+	cluster := k.GetRandomCluster()
+	influxql := fmt.Sprintf(`SELECT sum("restarts") AS "restarts" FROM (
+		SELECT spread("restarts_total") AS "restarts" FROM "%s"
+		WHERE "cluster" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY "namespace", "deployment", "pod", "container")
+		GROUP BY "namespace", "deployment"`,
+		k8s.StateTableName, cluster, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx container restarts per deployment, random cluster, random 12h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// PodChurn selects the number of pods that ran in a random cluster per hour
+// over a random 12 hours.
+func (k *K8s) PodChurn(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.PodChurnDuration)
+	cluster := k.GetRandomCluster()
+	influxql := fmt.Sprintf(`SELECT count("ready") AS "pods" FROM (
+		SELECT last("ready") AS "ready" FROM "%s"
+		WHERE "cluster" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY time(1h), "pod")
+		WHERE time >= '%[3]s' AND time < '%[4]s'
+		GROUP BY time(1h)`,
+		k8s.StateTableName, cluster, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx pods per hour, random cluster, random 12h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// LastPointPerContainer selects the last memory reading of every container of
+// a random deployment still running at a random time.
+func (k *K8s) LastPointPerContainer(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.LastPointDuration)
+	cluster := k.GetRandomCluster()
+	namespace, deployment := k.GetRandomDeployment()
+	influxql := fmt.Sprintf(`SELECT last("working_set_bytes") FROM "%s"
+		WHERE "cluster" = '%s' AND "namespace" = '%s' AND "deployment" = '%s' AND time >= '%s' AND time < '%s'
+		GROUP BY "pod", "container"`,
+		k8s.MemoryTableName, cluster, namespace, deployment, interval.StartString(), interval.EndString())
+
+	humanLabel := "Influx last memory reading per container, random deployment"
+	humanDesc := fmt.Sprintf("%s: %s/%s/%s %s", humanLabel, cluster, namespace, deployment, interval.EndString())
+	k.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
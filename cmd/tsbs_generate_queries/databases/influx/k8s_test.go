@@ -0,0 +1,116 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestK8sQueries(t *testing.T) {
+	cases := []struct {
+		desc string
+		fill func(*K8s, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc: "namespace cpu",
+			fill: (*K8s).NamespaceCPU,
+
+			expectedHumanLabel: "Influx cores used per namespace, random cluster, random 1h by 5m",
+			expectedHumanDesc:  "Influx cores used per namespace, random cluster, random 1h by 5m: cluster_0 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT sum("cpu") / 300 AS "cores" FROM (
+		SELECT spread("usage_seconds_total") AS "cpu" FROM "container_cpu"
+		WHERE "cluster" = 'cluster_0' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(5m), "namespace", "pod", "container")
+		WHERE time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(5m), "namespace"`,
+		},
+		{
+			desc: "node network",
+			fill: (*K8s).NodeNetwork,
+
+			expectedHumanLabel: "Influx network traffic per node, random cluster, random 1h by 5m",
+			expectedHumanDesc:  "Influx network traffic per node, random cluster, random 1h by 5m: cluster_0 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT (sum("rx") + sum("tx")) / 300 AS "bytes_per_second" FROM (
+		SELECT spread("receive_bytes_total") AS "rx", spread("transmit_bytes_total") AS "tx" FROM "container_network"
+		WHERE "cluster" = 'cluster_0' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(5m), "node", "pod", "container")
+		WHERE time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY time(5m), "node"`,
+		},
+		{
+			desc: "top pods memory",
+			fill: func(k *K8s, q query.Query) { k.TopPodsMemory(q, 10) },
+
+			expectedHumanLabel: "Influx top 10 pods by memory, random namespace, random 1h",
+			expectedHumanDesc:  "Influx top 10 pods by memory, random namespace, random 1h: cluster_0/frontend 1970-01-01T20:16:22Z",
+			expectedQuery: `SELECT top("max_working_set_bytes", "pod", 10) FROM (
+		SELECT max("working_set_bytes") AS "max_working_set_bytes" FROM "container_memory"
+		WHERE "cluster" = 'cluster_0' AND "namespace" = 'frontend' AND time >= '1970-01-01T20:16:22Z' AND time < '1970-01-01T21:16:22Z'
+		GROUP BY "pod")`,
+		},
+		{
+			desc: "container restarts",
+			fill: (*K8s).ContainerRestarts,
+
+			expectedHumanLabel: "Influx container restarts per deployment, random cluster, random 12h",
+			expectedHumanDesc:  "Influx container restarts per deployment, random cluster, random 12h: cluster_0 1970-01-01T06:16:22Z",
+			expectedQuery: `SELECT sum("restarts") AS "restarts" FROM (
+		SELECT spread("restarts_total") AS "restarts" FROM "kube_pod_container"
+		WHERE "cluster" = 'cluster_0' AND time >= '1970-01-01T06:16:22Z' AND time < '1970-01-01T18:16:22Z'
+		GROUP BY "namespace", "deployment", "pod", "container")
+		GROUP BY "namespace", "deployment"`,
+		},
+		{
+			desc: "pod churn",
+			fill: (*K8s).PodChurn,
+
+			expectedHumanLabel: "Influx pods per hour, random cluster, random 12h",
+			expectedHumanDesc:  "Influx pods per hour, random cluster, random 12h: cluster_0 1970-01-01T06:16:22Z",
+			expectedQuery: `SELECT count("ready") AS "pods" FROM (
+		SELECT last("ready") AS "ready" FROM "kube_pod_container"
+		WHERE "cluster" = 'cluster_0' AND time >= '1970-01-01T06:16:22Z' AND time < '1970-01-01T18:16:22Z'
+		GROUP BY time(1h), "pod")
+		WHERE time >= '1970-01-01T06:16:22Z' AND time < '1970-01-01T18:16:22Z'
+		GROUP BY time(1h)`,
+		},
+		{
+			desc: "lastpoint",
+			fill: (*K8s).LastPointPerContainer,
+
+			expectedHumanLabel: "Influx last memory reading per container, random deployment",
+			expectedHumanDesc:  "Influx last memory reading per container, random deployment: cluster_0/monitoring/node-exporter 1970-01-01T23:41:22Z",
+			expectedQuery: `SELECT last("working_set_bytes") FROM "container_memory"
+		WHERE "cluster" = 'cluster_0' AND "namespace" = 'monitoring' AND "deployment" = 'node-exporter' AND time >= '1970-01-01T23:36:22Z' AND time < '1970-01-01T23:41:22Z'
+		GROUP BY "pod", "container"`,
+		},
+	}
+
+	s := time.Unix(0, 0)
+	e := s.Add(24 * time.Hour)
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			g, err := b.NewK8s(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating k8s generator")
+			}
+			q := b.GenerateEmptyQuery()
+			c.fill(g.(*K8s), q)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package influx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Netflow produces Influx-specific queries for all the netflow query types.
+type Netflow struct {
+	*BaseGenerator
+	*netflow.Core
+}
+
+// TopTalkers selects the sources that sent the most bytes over a random
+// hour:
+//
+// SELECT top(bytes, src_ip, 10) FROM (
+// SELECT sum(bytes) AS bytes FROM flows
+// WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY src_ip)
+func (n *Netflow) TopTalkers(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.TopTalkersDuration)
+	influxql := fmt.Sprintf(`SELECT top("bytes", "src_ip", %d) AS "bytes" FROM (
+		SELECT sum("bytes") AS "bytes" FROM "%s"
+		WHERE time >= '%s' AND time < '%s'
+		GROUP BY "src_ip")`,
+		netflow.TopTalkersLimit, netflow.FlowsTableName, interval.StartString(), interval.EndString())
+
+	humanLabel := fmt.Sprintf("Influx top %d talkers, random 1h", netflow.TopTalkersLimit)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
+
+// SubnetTraffic selects the bytes sent and received by the hosts of a random
+// subnet every 5 minutes over a random hour, one statement each.
+func (n *Netflow) SubnetTraffic(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.SubnetTrafficDuration)
+	prefix := n.GetRandomSubnetPrefix()
+	regex := "^" + strings.Replace(prefix, ".", `\.`, -1)
+	statements := make([]string, 0, 2)
+	for _, dir := range []struct{ alias, tag string }{{"bytes_out", "src_ip"}, {"bytes_in", "dst_ip"}} {
+		statements = append(statements, fmt.Sprintf(`SELECT sum("bytes") AS "%s" FROM "%s"
+		WHERE "%s" =~ /%s/ AND time >= '%s' AND time < '%s'
+		GROUP BY time(5m)`,
+			dir.alias, netflow.FlowsTableName, dir.tag, regex, interval.StartString(), interval.EndString()))
+	}
+
+	humanLabel := "Influx traffic of a random subnet, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s0/24 %s", humanLabel, prefix, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, strings.Join(statements, "; "))
+}
+
+// PortScans selects the sources that connected to many ports of the same
+// destination over a random hour, and the number of ports. The ports are
+// counted as the groups of the flows per destination port.
+func (n *Netflow) PortScans(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.PortScansDuration)
+	influxql := fmt.Sprintf(`SELECT "ports" FROM (
+		SELECT count("packets") AS "ports" FROM (
+		SELECT sum("packets") AS "packets" FROM "%s"
+		WHERE time >= '%s' AND time < '%s'
+		GROUP BY "src_ip", "dst_ip", "dst_port")
+		GROUP BY "src_ip", "dst_ip")
+		WHERE "ports" > %d
+		GROUP BY "src_ip", "dst_ip"`,
+		netflow.FlowsTableName, interval.StartString(), interval.EndString(), netflow.PortScanThreshold)
+
+	humanLabel := fmt.Sprintf("Influx sources connecting to more than %d ports of a destination, random 1h", netflow.PortScanThreshold)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, influxql)
+}
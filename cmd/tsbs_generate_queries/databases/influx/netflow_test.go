@@ -0,0 +1,82 @@
+package influx
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func TestNetflowQueries(t *testing.T) {
+	cases := []struct {
+		desc string
+		fill func(*Netflow, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedQuery      string
+	}{
+		{
+			desc: "top talkers",
+			fill: (*Netflow).TopTalkers,
+
+			expectedHumanLabel: "Influx top 10 talkers, random 1h",
+			expectedHumanDesc:  "Influx top 10 talkers, random 1h: 1970-01-03T18:16:22Z",
+			expectedQuery: `SELECT top("bytes", "src_ip", 10) AS "bytes" FROM (
+		SELECT sum("bytes") AS "bytes" FROM "flows"
+		WHERE time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY "src_ip")`,
+		},
+		{
+			desc: "subnet traffic",
+			fill: (*Netflow).SubnetTraffic,
+
+			expectedHumanLabel: "Influx traffic of a random subnet, random 1h by 5m",
+			expectedHumanDesc:  "Influx traffic of a random subnet, random 1h by 5m: 10.0.0.0/24 1970-01-03T18:16:22Z",
+			expectedQuery: `SELECT sum("bytes") AS "bytes_out" FROM "flows"
+		WHERE "src_ip" =~ /^10\.0\.0\./ AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(5m); SELECT sum("bytes") AS "bytes_in" FROM "flows"
+		WHERE "dst_ip" =~ /^10\.0\.0\./ AND time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY time(5m)`,
+		},
+		{
+			desc: "port scans",
+			fill: (*Netflow).PortScans,
+
+			expectedHumanLabel: "Influx sources connecting to more than 100 ports of a destination, random 1h",
+			expectedHumanDesc:  "Influx sources connecting to more than 100 ports of a destination, random 1h: 1970-01-03T18:16:22Z",
+			expectedQuery: `SELECT "ports" FROM (
+		SELECT count("packets") AS "ports" FROM (
+		SELECT sum("packets") AS "packets" FROM "flows"
+		WHERE time >= '1970-01-03T18:16:22Z' AND time < '1970-01-03T19:16:22Z'
+		GROUP BY "src_ip", "dst_ip", "dst_port")
+		GROUP BY "src_ip", "dst_ip")
+		WHERE "ports" > 100
+		GROUP BY "src_ip", "dst_ip"`,
+		},
+	}
+
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			b := BaseGenerator{}
+			g, err := b.NewNetflow(s, e, testScale)
+			if err != nil {
+				t.Fatalf("Error while creating netflow generator")
+			}
+			q := b.GenerateEmptyQuery()
+			c.fill(g.(*Netflow), q)
+
+			v := url.Values{}
+			v.Set("q", c.expectedQuery)
+			expectedPath := fmt.Sprintf("/query?%s", v.Encode())
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, expectedPath)
+		})
+	}
+}
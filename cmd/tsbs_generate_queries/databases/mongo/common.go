@@ -1,17 +1,33 @@
 package mongo
 
 import (
+	"encoding/gob"
+	"fmt"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
+func init() {
+	// needed for serializing the dates of query template pipelines,
+	// which are parsed from Extended JSON, to gob
+	gob.Register(primitive.DateTime(0))
+}
+
 // BaseGenerator contains settings specific for Mongo database.
 type BaseGenerator struct {
-	UseNaive bool
+	UseNaive   bool
+	UseRollups bool
 }
 
 // GenerateEmptyQuery returns an empty query.Mongo.
@@ -54,3 +70,34 @@ func (g *BaseGenerator) NewFinance(start, end time.Time, scale int) (utils.Query
 		Core: core,
 	}, nil
 }
+
+// NewNetflow creates a new netflow use case query generator.
+func (g *BaseGenerator) NewNetflow(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := netflow.NewCore(start, end, scale)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Netflow{
+		BaseGenerator: g,
+		Core:          core,
+	}, nil
+}
+
+// FillInTemplate fills in a query.Query from the 'mongo' query of a query
+// template, which is the aggregation pipeline in (relaxed) MongoDB Extended JSON.
+func (g *BaseGenerator) FillInTemplate(qi query.Query, t *templates.Instance) {
+	pipeline := struct {
+		Stages mongo.Pipeline `bson:"pipeline"`
+	}{}
+	doc := fmt.Sprintf(`{"pipeline": %s}`, t.MustRender(constants.FormatMongo))
+	if err := bson.UnmarshalExtJSON([]byte(doc), false, &pipeline); err != nil {
+		panic(fmt.Sprintf("cannot parse mongo query template: %v", err))
+	}
+
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(t.Label)
+	q.Pipeline = pipeline.Stages
+	q.CollectionName = []byte(t.Table)
+	q.HumanDescription = []byte(t.Description)
+}
@@ -60,20 +60,21 @@ func (d *NaiveDevops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRa
 			}},
 		},
 		{
-			{"$group", bson.M{
-				"_id": bson.M{
+			{"$group", bson.D{
+				{"_id", bson.M{
 					"$dateTrunc": bson.M{"date": "$time", "unit": "minute"},
-				},
+				}},
 			}},
 		},
 		{
 			{"$sort", bson.M{"_id": 1}},
 		},
 	}
-	resultMap := pipelineQuery[1][0].Value.(bson.M)
+	group := pipelineQuery[1][0].Value.(bson.D)
 	for _, metric := range metrics {
-		resultMap["max_"+metric] = bson.M{"$max": "$" + metric}
+		group = append(group, bson.E{Key: "max_" + metric, Value: bson.M{"$max": "$" + metric}})
 	}
+	pipelineQuery[1][0].Value = group
 
 	humanLabel := []byte(fmt.Sprintf("Mongo [NAIVE] %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange))
 	q := qi.(*query.Mongo)
@@ -94,6 +95,10 @@ func (d *NaiveDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
+	if d.UseRollups {
+		fillInRollupGroupByTimeAndPrimaryTag(qi, "Mongo [NAIVE]", interval, metrics)
+		return
+	}
 
 	pipelineQuery := mongo.Pipeline{
 		{
@@ -106,23 +111,24 @@ func (d *NaiveDevops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 			}},
 		},
 		{
-			{"$group", bson.M{
-				"_id": bson.M{
-					"time": bson.M{
+			{"$group", bson.D{
+				{"_id", bson.D{
+					{"time", bson.M{
 						"$dateTrunc": bson.M{"date": "$time", "unit": "hour"},
-					},
-					"hostname": "$tags.hostname",
-				},
+					}},
+					{"hostname", "$tags.hostname"},
+				}},
 			}},
 		},
 		{
 			{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}},
 		},
 	}
-	resultMap := pipelineQuery[1][0].Value.(bson.M)
+	group := pipelineQuery[1][0].Value.(bson.D)
 	for _, metric := range metrics {
-		resultMap["avg_"+metric] = bson.M{"$avg": "$" + metric}
+		group = append(group, bson.E{Key: "avg_" + metric, Value: bson.M{"$avg": "$" + metric}})
 	}
+	pipelineQuery[1][0].Value = group
 
 	humanLabel := devops.GetDoubleGroupByLabel("Mongo [NAIVE]", numMetrics)
 	q := qi.(*query.Mongo)
@@ -159,20 +165,21 @@ func (d *NaiveDevops) MaxAllCPU(qi query.Query, nHosts int, duration time.Durati
 			}},
 		},
 		{
-			{"$group", bson.M{
-				"_id": bson.M{
+			{"$group", bson.D{
+				{"_id", bson.M{
 					"$dateTrunc": bson.M{"date": "$time", "unit": "hour"},
-				},
+				}},
 			}},
 		},
 		{
 			{"$sort", bson.M{"_id": 1}},
 		},
 	}
-	resultMap := pipelineQuery[1][0].Value.(bson.M)
+	group := pipelineQuery[1][0].Value.(bson.D)
 	for _, metric := range metrics {
-		resultMap["max_"+metric] = bson.M{"$max": "$" + metric}
+		group = append(group, bson.E{Key: "max_" + metric, Value: bson.M{"$max": "$" + metric}})
 	}
+	pipelineQuery[1][0].Value = group
 
 	humanLabel := devops.GetMaxAllLabel("Mongo", nHosts)
 	q := qi.(*query.Mongo)
@@ -233,20 +240,20 @@ func (d *NaiveDevops) LastPointPerHost(qi query.Query) {
 	pipelineQuery := mongo.Pipeline{
 		{{"$sort", bson.D{{"tags.hostname", 1}, {"time", -1}}}},
 		{{
-			"$group", bson.M{
-				"_id":              bson.M{"hostname": "$tags.hostname"},
-				"time":             bson.M{"$first": "$time"},
-				"usage_guest":      bson.M{"$first": "$usage_guest"},
-				"usage_guest_nice": bson.M{"$first": "$usage_guest_nice"},
-				"usage_idle":       bson.M{"$first": "$usage_idle"},
-				"usage_iowait":     bson.M{"$first": "$usage_iowait"},
-				"usage_irq":        bson.M{"$first": "$usage_irq"},
-				"usage_nice":       bson.M{"$first": "$usage_nice"},
-				"usage_softirq":    bson.M{"$first": "$usage_softirq"},
-				"usage_steal":      bson.M{"$first": "$usage_steal"},
-				"usage_system":     bson.M{"$first": "$usage_system"},
-				"usage_user":       bson.M{"$first": "$usage_user"},
-				"measurement":      bson.M{"$first": "$measurement"},
+			"$group", bson.D{
+				{"_id", bson.M{"hostname": "$tags.hostname"}},
+				{"time", bson.M{"$first": "$time"}},
+				{"usage_guest", bson.M{"$first": "$usage_guest"}},
+				{"usage_guest_nice", bson.M{"$first": "$usage_guest_nice"}},
+				{"usage_idle", bson.M{"$first": "$usage_idle"}},
+				{"usage_iowait", bson.M{"$first": "$usage_iowait"}},
+				{"usage_irq", bson.M{"$first": "$usage_irq"}},
+				{"usage_nice", bson.M{"$first": "$usage_nice"}},
+				{"usage_softirq", bson.M{"$first": "$usage_softirq"}},
+				{"usage_steal", bson.M{"$first": "$usage_steal"}},
+				{"usage_system", bson.M{"$first": "$usage_system"}},
+				{"usage_user", bson.M{"$first": "$usage_user"}},
+				{"measurement", bson.M{"$first": "$measurement"}},
 			},
 		}},
 	}
@@ -302,3 +309,88 @@ func (d *NaiveDevops) GroupByOrderByLimit(qi query.Query) {
 	q.CollectionName = []byte("point_data")
 	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.EndString()))
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same nHosts hosts using a
+// $lookup, e.g. in pseudo-SQL:
+//
+// SELECT hour, hostname, mean_usage_user, mean_used_percent
+// FROM (SELECT hour, hostname, AVG(usage_user) FROM cpu ...)
+// JOIN (SELECT hour, hostname, AVG(used_percent) FROM mem ...)
+// USING (hour, hostname)
+// ORDER BY hour, hostname
+func (d *NaiveDevops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostnames, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+
+	timeFilter := bson.M{
+		"$gte": interval.Start(),
+		"$lt":  interval.End(),
+	}
+	hourTrunc := bson.M{"$dateTrunc": bson.M{"date": "$time", "unit": "hour"}}
+
+	pipelineQuery := mongo.Pipeline{
+		{
+			{"$match", bson.M{
+				"measurement":   "cpu",
+				"time":          timeFilter,
+				"tags.hostname": bson.M{"$in": hostnames},
+			}},
+		},
+		{
+			{"$group", bson.D{
+				{"_id", bson.D{
+					{"time", hourTrunc},
+					{"hostname", "$tags.hostname"},
+				}},
+				{"mean_usage_user", bson.M{"$avg": "$usage_user"}},
+			}},
+		},
+		{
+			{"$lookup", bson.M{
+				"from": "point_data",
+				"let":  bson.M{"time": "$_id.time", "hostname": "$_id.hostname"},
+				// a []bson.M rather than a mongo.Pipeline, since that is
+				// registered with gob by the query runner
+				"pipeline": []bson.M{
+					{"$match": bson.M{
+						"measurement":   "mem",
+						"time":          timeFilter,
+						"tags.hostname": bson.M{"$in": hostnames},
+						"$expr": bson.M{
+							"$and": []interface{}{
+								bson.M{"$eq": []interface{}{"$tags.hostname", "$$hostname"}},
+								bson.M{"$eq": []interface{}{hourTrunc, "$$time"}},
+							},
+						},
+					}},
+					{"$group": bson.M{
+						"_id":               nil,
+						"mean_used_percent": bson.M{"$avg": "$used_percent"},
+					}},
+				},
+				"as": "mem",
+			}},
+		},
+		{
+			{"$unwind", "$mem"},
+		},
+		{
+			{"$project", bson.D{
+				{"mean_usage_user", 1},
+				{"mean_used_percent", "$mem.mean_used_percent"},
+			}},
+		},
+		{
+			{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}},
+		},
+	}
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("Mongo [NAIVE]", nHosts, duration)
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
@@ -140,16 +140,12 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 		}},
 	})
 
-	group := bson.D{
-		{"$group", bson.M{
-			"_id": "$time_bucket",
-		}},
-	}
-	resultMap := group[0].Value.(bson.M)
+	// a bson.D keeps the result columns in the order of the metrics
+	group := bson.D{{"_id", "$time_bucket"}}
 	for _, metric := range metrics {
-		resultMap["max_"+metric] = bson.M{"$max": "$events." + metric}
+		group = append(group, bson.E{Key: "max_" + metric, Value: bson.M{"$max": "$events." + metric}})
 	}
-	pipelineQuery = append(pipelineQuery, group)
+	pipelineQuery = append(pipelineQuery, bson.D{{"$group", group}})
 	pipelineQuery = append(pipelineQuery, bson.D{{"$sort", bson.M{"_id": 1}}})
 
 	humanLabel := []byte(fmt.Sprintf("Mongo %d cpu metric(s), random %4d hosts, random %s by 1m", numMetrics, nHosts, timeRange))
@@ -205,16 +201,11 @@ func (d *Devops) MaxAllCPU(qi query.Query, nHosts int, duration time.Duration) {
 		}},
 	})
 
-	group := bson.D{
-		{"$group", bson.M{
-			"_id": "$time_bucket",
-		}},
-	}
-	resultMap := group[0].Value.(bson.M)
+	group := bson.D{{"_id", "$time_bucket"}}
 	for _, metric := range metrics {
-		resultMap["max_"+metric] = bson.M{"$max": "$events." + metric}
+		group = append(group, bson.E{Key: "max_" + metric, Value: bson.M{"$max": "$events." + metric}})
 	}
-	pipelineQuery = append(pipelineQuery, group)
+	pipelineQuery = append(pipelineQuery, bson.D{{"$group", group}})
 	pipelineQuery = append(pipelineQuery, bson.D{{"$sort", bson.M{"_id": 1}}})
 
 	humanLabel := devops.GetMaxAllLabel("Mongo", nHosts)
@@ -236,6 +227,10 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
+	if d.UseRollups {
+		fillInRollupGroupByTimeAndPrimaryTag(qi, "Mongo", interval, metrics)
+		return
+	}
 	docs := getTimeFilterDocs(interval)
 
 	pipelineQuery := mongo.Pipeline{
@@ -273,19 +268,14 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	}...)
 
 	// Add groupby operator
-	group := bson.D{
-		{"$group", bson.M{
-			"_id": bson.M{
-				"time":     "$time_bucket",
-				"hostname": "$tags",
-			},
-		}},
-	}
-	resultMap := group[0].Value.(bson.M)
+	group := bson.D{{"_id", bson.D{
+		{"time", "$time_bucket"},
+		{"hostname", "$tags"},
+	}}}
 	for _, metric := range metrics {
-		resultMap["avg_"+metric] = bson.M{"$avg": "$events." + metric}
+		group = append(group, bson.E{Key: "avg_" + metric, Value: bson.M{"$avg": "$events." + metric}})
 	}
-	pipelineQuery = append(pipelineQuery, group)
+	pipelineQuery = append(pipelineQuery, bson.D{{"$group", group}})
 
 	// Add sort operators
 	sort := bson.D{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}}
@@ -299,6 +289,37 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
 }
 
+// fillInRollupGroupByTimeAndPrimaryTag populates a GroupByTimeAndPrimaryTag query
+// that reads the hourly averages from the rollup collection. Documents there
+// are already one per hour and tag set, so no grouping is needed.
+func fillInRollupGroupByTimeAndPrimaryTag(qi query.Query, dbName string, interval *utils.TimeInterval, metrics []string) {
+	project := bson.D{{"_id", bson.D{
+		{"time", "$time"},
+		{"hostname", "$tags.hostname"},
+	}}}
+	for _, metric := range metrics {
+		project = append(project, bson.E{Key: "avg_" + metric, Value: "$" + metric})
+	}
+
+	pipelineQuery := mongo.Pipeline{
+		{{"$match", bson.M{
+			"time": bson.M{
+				"$gte": interval.Start(),
+				"$lt":  interval.End(),
+			},
+		}}},
+		{{"$project", project}},
+		{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}},
+	}
+
+	humanLabel := devops.GetDoubleGroupByRollupLabel(dbName, len(metrics))
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte(devops.RollupTableName)
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
+
 // HighCPUForHosts populates a query that gets CPU metrics when the CPU has high
 // usage between a time period for a number of hosts (if 0, it will search all hosts),
 // e.g. in pseudo-SQL:
@@ -493,3 +514,80 @@ func (d *Devops) GroupByOrderByLimit(qi query.Query) {
 	q.CollectionName = []byte("point_data")
 	q.HumanDescription = []byte(fmt.Sprintf("%s: %s", humanLabel, interval.EndString()))
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same nHosts hosts using a
+// $lookup, e.g. in pseudo-SQL:
+//
+// SELECT hour, hostname, mean_usage_user, mean_used_percent
+// FROM (SELECT hour, hostname, AVG(usage_user) FROM cpu ...)
+// JOIN (SELECT hour, hostname, AVG(used_percent) FROM mem ...)
+// USING (hour, hostname)
+// ORDER BY hour, hostname
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostnames, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+	docs := getTimeFilterDocs(interval)
+	hourTrunc := bson.M{"$dateTrunc": bson.M{"date": "$events.time", "unit": "hour"}}
+
+	// the mem events of the hour and host of a cpu group; a bson.A rather
+	// than a mongo.Pipeline, since that is not registered with gob
+	memPipeline := bson.A{
+		bson.D{{"$match", bson.M{
+			"measurement":   "mem",
+			"key_id":        bson.M{"$in": docs},
+			"tags.hostname": bson.M{"$in": hostnames},
+			"$expr":         bson.M{"$eq": []interface{}{"$tags.hostname", "$$hostname"}},
+		}}},
+	}
+	for _, stage := range getTimeFilterPipeline(interval) {
+		memPipeline = append(memPipeline, stage)
+	}
+	memPipeline = append(memPipeline,
+		bson.D{{"$match", bson.M{
+			"$expr": bson.M{"$eq": []interface{}{hourTrunc, "$$time"}},
+		}}},
+		bson.D{{"$group", bson.D{
+			{"_id", nil},
+			{"mean_used_percent", bson.M{"$avg": "$events.used_percent"}},
+		}}},
+	)
+
+	pipelineQuery := mongo.Pipeline{
+		{{"$match", bson.M{
+			"measurement":   "cpu",
+			"key_id":        bson.M{"$in": docs},
+			"tags.hostname": bson.M{"$in": hostnames},
+		}}},
+	}
+	pipelineQuery = append(pipelineQuery, getTimeFilterPipeline(interval)...)
+	pipelineQuery = append(pipelineQuery, mongo.Pipeline{
+		{{"$group", bson.D{
+			{"_id", bson.D{
+				{"time", hourTrunc},
+				{"hostname", "$tags.hostname"},
+			}},
+			{"mean_usage_user", bson.M{"$avg": "$events.usage_user"}},
+		}}},
+		{{"$lookup", bson.M{
+			"from":     "point_data",
+			"let":      bson.M{"time": "$_id.time", "hostname": "$_id.hostname"},
+			"pipeline": memPipeline,
+			"as":       "mem",
+		}}},
+		{{"$unwind", "$mem"}},
+		{{"$project", bson.D{
+			{"mean_usage_user", 1},
+			{"mean_used_percent", "$mem.mean_used_percent"},
+		}}},
+		{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}},
+	}...)
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("Mongo", nHosts, duration)
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipelineQuery
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s: %s (%s)", humanLabel, interval.StartString(), q.CollectionName))
+}
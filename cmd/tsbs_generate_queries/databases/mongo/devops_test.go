@@ -0,0 +1,179 @@
+package mongo
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const testScale = 10
+
+func assertNewDevops(t *testing.T, naive bool) interface{} {
+	b := BaseGenerator{UseNaive: naive}
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	end := time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)
+	dq, err := b.NewDevops(start, end, testScale)
+	if err != nil {
+		t.Fatalf("error while creating devops generator")
+	}
+	return dq
+}
+
+// the interval and hosts of the cpu-mem-correlation queries with seed 100
+var (
+	testCorrelationStart = time.Date(2016, 1, 1, 6, 55, 13, 823513298, time.UTC)
+	testCorrelationEnd   = time.Date(2016, 1, 1, 18, 55, 13, 823513298, time.UTC)
+	testCorrelationHosts = []string{"host_8", "host_0", "host_2", "host_9", "host_4", "host_1", "host_3", "host_6"}
+)
+
+func assertPipeline(t *testing.T, got *query.Mongo, wantLabel, wantDesc string, want mongo.Pipeline) {
+	t.Helper()
+	if string(got.HumanLabel) != wantLabel {
+		t.Errorf("incorrect human label:\ngot\n%s\nwant\n%s", got.HumanLabel, wantLabel)
+	}
+	if string(got.HumanDescription) != wantDesc {
+		t.Errorf("incorrect human description:\ngot\n%s\nwant\n%s", got.HumanDescription, wantDesc)
+	}
+	if string(got.CollectionName) != "point_data" {
+		t.Errorf("incorrect collection: got %s want point_data", got.CollectionName)
+	}
+	if len(got.Pipeline) != len(want) {
+		t.Fatalf("incorrect number of stages: got %d want %d\ngot\n%v", len(got.Pipeline), len(want), got.Pipeline)
+	}
+	for i := range want {
+		if !reflect.DeepEqual(got.Pipeline[i], want[i]) {
+			t.Errorf("incorrect stage %d:\ngot\n%v\nwant\n%v", i, got.Pipeline[i], want[i])
+		}
+	}
+}
+
+func TestDevopsCPUMemCorrelation(t *testing.T) {
+	interval, err := utils.NewTimeInterval(testCorrelationStart, testCorrelationEnd)
+	if err != nil {
+		t.Fatal(err)
+	}
+	docs := []interface{}{
+		"20160101_06", "20160101_07", "20160101_08", "20160101_09", "20160101_10", "20160101_11", "20160101_12",
+		"20160101_13", "20160101_14", "20160101_15", "20160101_16", "20160101_17", "20160101_18",
+	}
+	hourTrunc := bson.M{"$dateTrunc": bson.M{"date": "$events.time", "unit": "hour"}}
+	timeFilter := getTimeFilterPipeline(interval)
+
+	memPipeline := bson.A{
+		bson.D{{"$match", bson.M{
+			"measurement":   "mem",
+			"key_id":        bson.M{"$in": docs},
+			"tags.hostname": bson.M{"$in": testCorrelationHosts},
+			"$expr":         bson.M{"$eq": []interface{}{"$tags.hostname", "$$hostname"}},
+		}}},
+		timeFilter[0], timeFilter[1], timeFilter[2],
+		bson.D{{"$match", bson.M{
+			"$expr": bson.M{"$eq": []interface{}{hourTrunc, "$$time"}},
+		}}},
+		bson.D{{"$group", bson.D{
+			{"_id", nil},
+			{"mean_used_percent", bson.M{"$avg": "$events.used_percent"}},
+		}}},
+	}
+	want := mongo.Pipeline{
+		{{"$match", bson.M{
+			"measurement":   "cpu",
+			"key_id":        bson.M{"$in": docs},
+			"tags.hostname": bson.M{"$in": testCorrelationHosts},
+		}}},
+		timeFilter[0], timeFilter[1], timeFilter[2],
+		{{"$group", bson.D{
+			{"_id", bson.D{
+				{"time", hourTrunc},
+				{"hostname", "$tags.hostname"},
+			}},
+			{"mean_usage_user", bson.M{"$avg": "$events.usage_user"}},
+		}}},
+		{{"$lookup", bson.M{
+			"from":     "point_data",
+			"let":      bson.M{"time": "$_id.time", "hostname": "$_id.hostname"},
+			"pipeline": memPipeline,
+			"as":       "mem",
+		}}},
+		{{"$unwind", "$mem"}},
+		{{"$project", bson.D{
+			{"mean_usage_user", 1},
+			{"mean_used_percent", "$mem.mean_used_percent"},
+		}}},
+		{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}},
+	}
+
+	rand.Seed(100)
+	d := assertNewDevops(t, false).(*Devops)
+	got := query.NewMongo()
+	d.CPUMemCorrelation(got, devops.CPUMemCorrelationHosts, devops.CPUMemCorrelationDuration)
+
+	wantLabel := "Mongo cpu usage_user joined with mem used_percent, random    8 hosts, random 12h0m0s by 1h"
+	assertPipeline(t, got, wantLabel, wantLabel+": 2016-01-01T06:55:13Z (point_data)", want)
+}
+
+func TestNaiveDevopsCPUMemCorrelation(t *testing.T) {
+	rand.Seed(100)
+	d := assertNewDevops(t, true).(*NaiveDevops)
+	got := query.NewMongo()
+	d.CPUMemCorrelation(got, devops.CPUMemCorrelationHosts, devops.CPUMemCorrelationDuration)
+
+	timeFilter := bson.M{
+		"$gte": testCorrelationStart,
+		"$lt":  testCorrelationEnd,
+	}
+	hourTrunc := bson.M{"$dateTrunc": bson.M{"date": "$time", "unit": "hour"}}
+	want := mongo.Pipeline{
+		{{"$match", bson.M{
+			"measurement":   "cpu",
+			"time":          timeFilter,
+			"tags.hostname": bson.M{"$in": testCorrelationHosts},
+		}}},
+		{{"$group", bson.D{
+			{"_id", bson.D{
+				{"time", hourTrunc},
+				{"hostname", "$tags.hostname"},
+			}},
+			{"mean_usage_user", bson.M{"$avg": "$usage_user"}},
+		}}},
+		{{"$lookup", bson.M{
+			"from": "point_data",
+			"let":  bson.M{"time": "$_id.time", "hostname": "$_id.hostname"},
+			"pipeline": []bson.M{
+				{"$match": bson.M{
+					"measurement":   "mem",
+					"time":          timeFilter,
+					"tags.hostname": bson.M{"$in": testCorrelationHosts},
+					"$expr": bson.M{
+						"$and": []interface{}{
+							bson.M{"$eq": []interface{}{"$tags.hostname", "$$hostname"}},
+							bson.M{"$eq": []interface{}{hourTrunc, "$$time"}},
+						},
+					},
+				}},
+				{"$group": bson.M{
+					"_id":               nil,
+					"mean_used_percent": bson.M{"$avg": "$used_percent"},
+				}},
+			},
+			"as": "mem",
+		}}},
+		{{"$unwind", "$mem"}},
+		{{"$project", bson.D{
+			{"mean_usage_user", 1},
+			{"mean_used_percent", "$mem.mean_used_percent"},
+		}}},
+		{{"$sort", bson.D{{"_id.time", 1}, {"_id.hostname", 1}}}},
+	}
+
+	wantLabel := "Mongo [NAIVE] cpu usage_user joined with mem used_percent, random    8 hosts, random 12h0m0s by 1h"
+	assertPipeline(t, got, wantLabel, wantLabel+": 2016-01-01T06:55:13Z (point_data)", want)
+}
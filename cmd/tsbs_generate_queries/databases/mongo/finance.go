@@ -15,6 +15,12 @@ func init() {
 	gob.Register(bson.A{})
 }
 
+// Measurements of the finance use case
+const (
+	tradeMeasurement = "trade"
+	quoteMeasurement = "quote"
+)
+
 type Finance struct {
 	*BaseGenerator
 	*finance.Core
@@ -28,10 +34,13 @@ func idTimeSortStage() bson.D {
 	}
 }
 
-func hourDiffPipeline(end time.Time, span time.Duration) mongo.Pipeline {
+// hourDiffPipeline selects the events of the given measurement in the span
+// before end.
+func hourDiffPipeline(measurement string, end time.Time, span time.Duration) mongo.Pipeline {
 	return mongo.Pipeline{
 		{
 			{"$match", bson.D{
+				{"measurement", measurement},
 				{"$expr", bson.D{
 					{"$gte", bson.A{
 						"$time",
@@ -49,6 +58,20 @@ func hourDiffPipeline(end time.Time, span time.Duration) mongo.Pipeline {
 	}
 }
 
+// intervalGroupID groups the events of a symbol by interval.
+func intervalGroupID(interval time.Duration) bson.D {
+	return bson.D{
+		{"symbol", "$tags.symbol"},
+		{"time", bson.D{
+			{"$dateTrunc", bson.D{
+				{"date", "$time"},
+				{"unit", "minute"},
+				{"binSize", interval.Minutes()},
+			}},
+		}},
+	}
+}
+
 func sortOpenHighLowClosePipeline(interval time.Duration) mongo.Pipeline {
 	return mongo.Pipeline{
 		{
@@ -58,16 +81,7 @@ func sortOpenHighLowClosePipeline(interval time.Duration) mongo.Pipeline {
 		},
 		{
 			{"$group", bson.D{
-				{"_id", bson.D{
-					{"symbol", "$tags.symbol"},
-					{"time", bson.D{
-						{"$dateTrunc", bson.D{
-							{"date", "$time"},
-							{"unit", "minute"},
-							{"binSize", interval.Minutes()},
-						}},
-					}},
-				}},
+				{"_id", intervalGroupID(interval)},
 				{"high", bson.D{
 					{"$max", "$price"},
 				}},
@@ -87,7 +101,7 @@ func sortOpenHighLowClosePipeline(interval time.Duration) mongo.Pipeline {
 
 func hourDiffSortOpenHighLowClosePipeline(end time.Time, span, interval time.Duration) mongo.Pipeline {
 	pipeline := mongo.Pipeline{}
-	pipeline = append(pipeline, hourDiffPipeline(end, span)...)
+	pipeline = append(pipeline, hourDiffPipeline(tradeMeasurement, end, span)...)
 	pipeline = append(pipeline, sortOpenHighLowClosePipeline(interval)...)
 	return pipeline
 }
@@ -95,6 +109,11 @@ func hourDiffSortOpenHighLowClosePipeline(end time.Time, span, interval time.Dur
 func (f *Finance) LastPrice(q query.Query) {
 	query := q.(*query.Mongo)
 	query.Pipeline = mongo.Pipeline{
+		{
+			{"$match", bson.D{
+				{"measurement", tradeMeasurement},
+			}},
+		},
 		{
 			{"$sort", bson.D{
 				{"time", -1},
@@ -533,7 +552,7 @@ func (f *Finance) StochasticOscillator(q query.Query, span, interval time.Durati
 
 func (f *Finance) TopPercentChange(q query.Query, span, interval time.Duration) {
 	query := q.(*query.Mongo)
-	query.Pipeline = append(query.Pipeline, hourDiffPipeline(f.Core.Interval.End(), span)...)
+	query.Pipeline = append(query.Pipeline, hourDiffPipeline(tradeMeasurement, f.Core.Interval.End(), span)...)
 	query.Pipeline = append(query.Pipeline, mongo.Pipeline{
 		{
 			{"$sort", bson.D{
@@ -542,16 +561,7 @@ func (f *Finance) TopPercentChange(q query.Query, span, interval time.Duration)
 		},
 		{
 			{"$group", bson.D{
-				{"_id", bson.D{
-					{"symbol", "$tags.symbol"},
-					{"time", bson.D{
-						{"$dateTrunc", bson.D{
-							{"date", "$time"},
-							{"unit", "minute"},
-							{"binSize", interval.Minutes()},
-						}},
-					}},
-				}},
+				{"_id", intervalGroupID(interval)},
 				{"open", bson.D{
 					{"$first", "$price"},
 				}},
@@ -629,3 +639,80 @@ func (f *Finance) TopPercentChange(q query.Query, span, interval time.Duration)
 		span,
 		interval))
 }
+
+func (f *Finance) VWAP(q query.Query, span, interval time.Duration) {
+	query := q.(*query.Mongo)
+	query.Pipeline = append(query.Pipeline, hourDiffPipeline(tradeMeasurement, f.Core.Interval.End(), span)...)
+	query.Pipeline = append(query.Pipeline, mongo.Pipeline{
+		{
+			{"$group", bson.D{
+				{"_id", intervalGroupID(interval)},
+				{"notional", bson.D{
+					{"$sum", bson.D{
+						{"$multiply", bson.A{"$price", "$size"}},
+					}},
+				}},
+				{"volume", bson.D{
+					{"$sum", "$size"},
+				}},
+			}},
+		},
+		{
+			{"$addFields", bson.D{
+				{"vwap", bson.D{
+					{"$divide", bson.A{"$notional", "$volume"}},
+				}},
+			}},
+		},
+	}...)
+	query.Pipeline = append(query.Pipeline, idTimeSortStage())
+	query.CollectionName = []byte("point_data")
+	query.HumanLabel = []byte("MongoDB volume weighted average price")
+	query.HumanDescription = []byte(fmt.Sprintf("%s, last %s, interval %s",
+		query.HumanLabel,
+		span,
+		interval))
+}
+
+func (f *Finance) Spread(q query.Query, span, interval time.Duration) {
+	query := q.(*query.Mongo)
+	query.Pipeline = append(query.Pipeline, hourDiffPipeline(quoteMeasurement, f.Core.Interval.End(), span)...)
+	query.Pipeline = append(query.Pipeline, mongo.Pipeline{
+		{
+			{"$group", bson.D{
+				{"_id", intervalGroupID(interval)},
+				{"avgSpread", bson.D{
+					{"$avg", bson.D{
+						{"$subtract", bson.A{"$ask", "$bid"}},
+					}},
+				}},
+				{"maxSpread", bson.D{
+					{"$max", bson.D{
+						{"$subtract", bson.A{"$ask", "$bid"}},
+					}},
+				}},
+				// relative to the mid price, in basis points
+				{"avgSpreadBps", bson.D{
+					{"$avg", bson.D{
+						{"$multiply", bson.A{
+							20000,
+							bson.D{
+								{"$divide", bson.A{
+									bson.D{{"$subtract", bson.A{"$ask", "$bid"}}},
+									bson.D{{"$add", bson.A{"$ask", "$bid"}}},
+								}},
+							},
+						}},
+					}},
+				}},
+			}},
+		},
+	}...)
+	query.Pipeline = append(query.Pipeline, idTimeSortStage())
+	query.CollectionName = []byte("point_data")
+	query.HumanLabel = []byte("MongoDB bid-ask spread")
+	query.HumanDescription = []byte(fmt.Sprintf("%s, last %s, interval %s",
+		query.HumanLabel,
+		span,
+		interval))
+}
@@ -0,0 +1,263 @@
+package mongo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+var testFinanceEnd = time.Date(2016, 1, 2, 0, 0, 0, 0, time.UTC)
+
+func newTestFinance(t *testing.T) *Finance {
+	b := BaseGenerator{}
+	g, err := b.NewFinance(time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC), testFinanceEnd, testScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g.(*Finance)
+}
+
+// testFinanceMatch is the stage selecting the events of measurement in the
+// last 4 hours of the test interval.
+func testFinanceMatch(measurement string) bson.D {
+	return bson.D{
+		{"$match", bson.D{
+			{"measurement", measurement},
+			{"$expr", bson.D{
+				{"$gte", bson.A{
+					"$time",
+					bson.D{
+						{"$dateSubtract", bson.D{
+							{"startDate", testFinanceEnd},
+							{"unit", "hour"},
+							{"amount", 4.0},
+						}},
+					},
+				}},
+			}},
+		}},
+	}
+}
+
+// testFinanceGroupID groups the events of a symbol by 5 minutes.
+var testFinanceGroupID = bson.D{
+	{"symbol", "$tags.symbol"},
+	{"time", bson.D{
+		{"$dateTrunc", bson.D{
+			{"date", "$time"},
+			{"unit", "minute"},
+			{"binSize", 5.0},
+		}},
+	}},
+}
+
+// testOHLCStages are the stages of the trades of the last 4 hours, by symbol
+// and 5 minutes, that the moving averages and the oscillators start with.
+var testOHLCStages = mongo.Pipeline{
+	testFinanceMatch("trade"),
+	{{"$sort", bson.D{{"time", 1}}}},
+	{{"$group", bson.D{
+		{"_id", testFinanceGroupID},
+		{"high", bson.D{{"$max", "$price"}}},
+		{"low", bson.D{{"$min", "$price"}}},
+		{"open", bson.D{{"$first", "$price"}}},
+		{"close", bson.D{{"$last", "$price"}}},
+	}}},
+}
+
+var testIDTimeSort = bson.D{{"$sort", bson.D{{"_id.time", -1}}}}
+
+func TestFinanceLastPrice(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).LastPrice(q)
+	want := mongo.Pipeline{
+		{{"$match", bson.D{{"measurement", "trade"}}}},
+		{{"$sort", bson.D{{"time", -1}}}},
+		{{"$group", bson.D{
+			{"_id", "$tags.symbol"},
+			{"lastPrice", bson.D{{"$first", "$price"}}},
+		}}},
+	}
+	assertPipeline(t, q, "MongoDB last price per symbol", "MongoDB last price per symbol", want)
+}
+
+func TestFinanceMovingAverage(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).MovingAverage(q, 4*time.Hour, 5*time.Minute, 14)
+	want := append(append(mongo.Pipeline{}, testOHLCStages...),
+		bson.D{{"$setWindowFields", bson.D{
+			{"partitionBy", "$_id.symbol"},
+			{"sortBy", bson.D{{"_id.time", 1}}},
+			{"output", bson.D{
+				{"movingAverage", bson.D{
+					{"$avg", "$close"},
+					{"window", bson.D{{"documents", bson.A{-13, 0}}}},
+				}},
+			}},
+		}}},
+		testIDTimeSort,
+	)
+	assertPipeline(t, q, "MongoDB moving average",
+		"MongoDB moving average, last 4h0m0s, interval 5m0s, 14 previous data points", want)
+}
+
+func TestFinanceExponentialMovingAverage(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).ExponentialMovingAverage(q, 4*time.Hour, 5*time.Minute, 14)
+	want := append(append(mongo.Pipeline{}, testOHLCStages...),
+		bson.D{{"$setWindowFields", bson.D{
+			{"partitionBy", "$_id.symbol"},
+			{"sortBy", bson.D{{"_id.time", 1}}},
+			{"output", bson.D{
+				{"expMovingAverage", bson.D{
+					{"$expMovingAvg", bson.D{{"input", "$close"}, {"N", 14}}},
+				}},
+			}},
+		}}},
+		testIDTimeSort,
+	)
+	assertPipeline(t, q, "MongoDB exponential moving average",
+		"MongoDB exponential moving average, last 4h0m0s, interval 5m0s, 14 previous data points", want)
+}
+
+// TestFinanceOscillators checks the stages the oscillators share with the
+// moving averages: the trades of the span by interval first, sorted by time
+// last.
+func TestFinanceOscillators(t *testing.T) {
+	f := newTestFinance(t)
+	cases := []struct {
+		desc       string
+		fill       func(query.Query)
+		wantStages int
+		wantLabel  string
+		wantDesc   string
+	}{
+		{
+			desc:       "rsi",
+			fill:       func(q query.Query) { f.RSI(q, 4*time.Hour, 5*time.Minute, 14) },
+			wantStages: 10,
+			wantLabel:  "MongoDB relative strength index",
+			wantDesc:   "MongoDB relative strength index, last 4h0m0s, interval 5m0s, 14 previous data points",
+		},
+		{
+			desc:       "macd",
+			fill:       func(q query.Query) { f.MACD(q, 4*time.Hour, 5*time.Minute, 12, 26, 9) },
+			wantStages: 8,
+			wantLabel:  "MongoDB moving average convergence/divergence",
+			wantDesc:   "MongoDB moving average convergence/divergence, last 4h0m0s, interval 5m0s, (12, 26, 9) previous data points",
+		},
+		{
+			desc:       "stochastic oscillator",
+			fill:       func(q query.Query) { f.StochasticOscillator(q, 4*time.Hour, 5*time.Minute, 14) },
+			wantStages: 8,
+			wantLabel:  "MongoDB stochastic oscillator",
+			wantDesc:   "MongoDB stochastic oscillator, last 4h0m0s, interval 5m0s, 14 previous data points",
+		},
+	}
+	for _, c := range cases {
+		q := query.NewMongo()
+		c.fill(q)
+		if got := string(q.HumanLabel); got != c.wantLabel {
+			t.Errorf("%s: incorrect human label: got %s want %s", c.desc, got, c.wantLabel)
+		}
+		if got := string(q.HumanDescription); got != c.wantDesc {
+			t.Errorf("%s: incorrect human description: got %s want %s", c.desc, got, c.wantDesc)
+		}
+		if len(q.Pipeline) != c.wantStages {
+			t.Fatalf("%s: incorrect number of stages: got %d want %d", c.desc, len(q.Pipeline), c.wantStages)
+		}
+		for i, want := range testOHLCStages {
+			if !reflect.DeepEqual(q.Pipeline[i], want) {
+				t.Errorf("%s: incorrect stage %d:\ngot\n%v\nwant\n%v", c.desc, i, q.Pipeline[i], want)
+			}
+		}
+		if last := q.Pipeline[len(q.Pipeline)-1]; !reflect.DeepEqual(last, testIDTimeSort) {
+			t.Errorf("%s: incorrect last stage: got %v want %v", c.desc, last, testIDTimeSort)
+		}
+	}
+}
+
+func TestFinanceTopPercentChange(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).TopPercentChange(q, 4*time.Hour, 5*time.Minute)
+	diffPercentage := bson.D{
+		{"$round", bson.A{
+			bson.D{{"$multiply", bson.A{
+				100,
+				bson.D{{"$divide", bson.A{
+					bson.D{{"$subtract", bson.A{"$close", "$open"}}},
+					"$open",
+				}}},
+			}}},
+			2,
+		}},
+	}
+	ranked := func(op string) bson.D {
+		return bson.D{{op, bson.D{
+			{"output", bson.A{"$_id.symbol", "$diffPercentage", "$close"}},
+			{"sortBy", bson.D{{"diffPercentage", -1}}},
+			{"n", 3},
+		}}}
+	}
+	want := mongo.Pipeline{
+		testFinanceMatch("trade"),
+		{{"$sort", bson.D{{"time", 1}}}},
+		{{"$group", bson.D{
+			{"_id", testFinanceGroupID},
+			{"open", bson.D{{"$first", "$price"}}},
+			{"close", bson.D{{"$last", "$price"}}},
+		}}},
+		{{"$addFields", bson.D{{"diffPercentage", diffPercentage}}}},
+		{{"$group", bson.D{
+			{"_id", "$_id.time"},
+			{"topN", ranked("$topN")},
+			{"bottomN", ranked("$bottomN")},
+		}}},
+		{{"$sort", bson.D{{"_id", -1}}}},
+	}
+	assertPipeline(t, q, "MongoDB top percent change",
+		"MongoDB top percent change, last 4h0m0s, interval 5m0s", want)
+}
+
+func TestFinanceVWAP(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).VWAP(q, 4*time.Hour, 5*time.Minute)
+	want := mongo.Pipeline{
+		testFinanceMatch("trade"),
+		{{"$group", bson.D{
+			{"_id", testFinanceGroupID},
+			{"notional", bson.D{{"$sum", bson.D{{"$multiply", bson.A{"$price", "$size"}}}}}},
+			{"volume", bson.D{{"$sum", "$size"}}},
+		}}},
+		{{"$addFields", bson.D{{"vwap", bson.D{{"$divide", bson.A{"$notional", "$volume"}}}}}}},
+		testIDTimeSort,
+	}
+	assertPipeline(t, q, "MongoDB volume weighted average price",
+		"MongoDB volume weighted average price, last 4h0m0s, interval 5m0s", want)
+}
+
+func TestFinanceSpread(t *testing.T) {
+	q := query.NewMongo()
+	newTestFinance(t).Spread(q, 4*time.Hour, 5*time.Minute)
+	spread := bson.D{{"$subtract", bson.A{"$ask", "$bid"}}}
+	want := mongo.Pipeline{
+		testFinanceMatch("quote"),
+		{{"$group", bson.D{
+			{"_id", testFinanceGroupID},
+			{"avgSpread", bson.D{{"$avg", spread}}},
+			{"maxSpread", bson.D{{"$max", spread}}},
+			{"avgSpreadBps", bson.D{{"$avg", bson.D{{"$multiply", bson.A{
+				20000,
+				bson.D{{"$divide", bson.A{spread, bson.D{{"$add", bson.A{"$ask", "$bid"}}}}}},
+			}}}}}},
+		}}},
+		testIDTimeSort,
+	}
+	assertPipeline(t, q, "MongoDB bid-ask spread",
+		"MongoDB bid-ask spread, last 4h0m0s, interval 5m0s", want)
+}
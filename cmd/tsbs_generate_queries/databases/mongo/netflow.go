@@ -0,0 +1,113 @@
+package mongo
+
+import (
+	"fmt"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Netflow produces Mongo-specific queries for the netflow use case. The
+// queries expect one document per flow record, with the tags in the tags
+// meta field, as loaded with document-per-event or in a time-series
+// collection.
+type Netflow struct {
+	*BaseGenerator
+	*netflow.Core
+}
+
+// flowsMatch returns the stage selecting the flow records of interval.
+func flowsMatch(interval *utils.TimeInterval) bson.D {
+	return bson.D{
+		{"$match", bson.M{
+			"measurement": netflow.FlowsTableName,
+			"time": bson.M{
+				"$gte": interval.Start(),
+				"$lt":  interval.End(),
+			},
+		}},
+	}
+}
+
+func (n *Netflow) fillInQuery(qi query.Query, humanLabel, humanDesc string, pipeline mongo.Pipeline) {
+	q := qi.(*query.Mongo)
+	q.HumanLabel = []byte(humanLabel)
+	q.Pipeline = pipeline
+	q.CollectionName = []byte("point_data")
+	q.HumanDescription = []byte(fmt.Sprintf("%s (%s)", humanDesc, q.CollectionName))
+}
+
+// TopTalkers selects the sources that sent the most bytes over a random
+// hour.
+func (n *Netflow) TopTalkers(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.TopTalkersDuration)
+	pipeline := mongo.Pipeline{
+		flowsMatch(interval),
+		{{"$group", bson.M{
+			"_id":   "$tags.src_ip",
+			"bytes": bson.M{"$sum": "$bytes"},
+		}}},
+		{{"$sort", bson.M{"bytes": -1}}},
+		{{"$limit", netflow.TopTalkersLimit}},
+	}
+
+	humanLabel := fmt.Sprintf("Mongo top %d talkers, random 1h", netflow.TopTalkersLimit)
+	n.fillInQuery(qi, humanLabel, fmt.Sprintf("%s: %s", humanLabel, interval.StartString()), pipeline)
+}
+
+// SubnetTraffic selects the bytes sent and received by the hosts of a random
+// subnet every 5 minutes over a random hour.
+func (n *Netflow) SubnetTraffic(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.SubnetTrafficDuration)
+	prefix := n.GetRandomSubnetPrefix()
+	regex := "^" + strings.Replace(prefix, ".", `\.`, -1)
+	bytesIf := func(tag string) bson.M {
+		return bson.M{"$sum": bson.M{"$cond": bson.A{
+			bson.M{"$regexMatch": bson.M{"input": "$tags." + tag, "regex": regex}},
+			"$bytes",
+			0,
+		}}}
+	}
+	pipeline := mongo.Pipeline{
+		flowsMatch(interval),
+		{{"$match", bson.M{"$or": bson.A{
+			bson.M{"tags.src_ip": bson.M{"$regex": regex}},
+			bson.M{"tags.dst_ip": bson.M{"$regex": regex}},
+		}}}},
+		{{"$group", bson.D{
+			{"_id", bson.M{
+				"$dateTrunc": bson.M{"date": "$time", "unit": "minute", "binSize": 5},
+			}},
+			{"bytes_out", bytesIf("src_ip")},
+			{"bytes_in", bytesIf("dst_ip")},
+		}}},
+		{{"$sort", bson.M{"_id": 1}}},
+	}
+
+	humanLabel := "Mongo traffic of a random subnet, random 1h by 5m"
+	n.fillInQuery(qi, humanLabel, fmt.Sprintf("%s: %s0/24 %s", humanLabel, prefix, interval.StartString()), pipeline)
+}
+
+// PortScans selects the sources that connected to many ports of the same
+// destination over a random hour, and the number of ports.
+func (n *Netflow) PortScans(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.PortScansDuration)
+	pipeline := mongo.Pipeline{
+		flowsMatch(interval),
+		{{"$group", bson.M{
+			"_id":   bson.D{{"src_ip", "$tags.src_ip"}, {"dst_ip", "$tags.dst_ip"}},
+			"ports": bson.M{"$addToSet": "$tags.dst_port"},
+		}}},
+		{{"$project", bson.M{"ports": bson.M{"$size": "$ports"}}}},
+		{{"$match", bson.M{"ports": bson.M{"$gt": netflow.PortScanThreshold}}}},
+		{{"$sort", bson.M{"ports": -1}}},
+	}
+
+	humanLabel := fmt.Sprintf("Mongo sources connecting to more than %d ports of a destination, random 1h", netflow.PortScanThreshold)
+	n.fillInQuery(qi, humanLabel, fmt.Sprintf("%s: %s", humanLabel, interval.StartString()), pipeline)
+}
@@ -208,3 +208,41 @@ func (d *Devops) GroupByTime(qi query.Query, nHosts, numMetrics int, timeRange t
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
 	d.fillInQuery(qi, humanLabel, humanDesc, sql)
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same N random hosts
+//
+// Queries:
+// cpu-mem-correlation
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hosts, err := d.GetRandomHosts(nHosts)
+	panicIfErr(err)
+
+	sql := fmt.Sprintf(`
+		SELECT c.timestamp, c.hostname, c.mean_usage_user, m.mean_used_percent
+		FROM (
+			SELECT timestamp, hostname, avg(usage_user) AS mean_usage_user
+			FROM cpu
+			WHERE hostname IN ('%[1]s')
+			  AND timestamp >= '%[2]s'
+			  AND timestamp < '%[3]s'
+			SAMPLE BY 1h
+		) c
+		JOIN (
+			SELECT timestamp, hostname, avg(used_percent) AS mean_used_percent
+			FROM mem
+			WHERE hostname IN ('%[1]s')
+			  AND timestamp >= '%[2]s'
+			  AND timestamp < '%[3]s'
+			SAMPLE BY 1h
+		) m ON c.timestamp = m.timestamp AND c.hostname = m.hostname
+		ORDER BY c.timestamp, c.hostname`,
+		strings.Join(hosts, "', '"),
+		interval.StartString(),
+		interval.EndString())
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("QuestDB", nHosts, duration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, sql)
+}
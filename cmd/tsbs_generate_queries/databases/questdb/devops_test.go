@@ -139,6 +139,29 @@ func TestMaxAllCPU(t *testing.T) {
 	runTestCases(t, testFunc, start, end, cases)
 }
 
+func TestCPUMemCorrelation(t *testing.T) {
+	cases := []testCase{
+		{
+			desc:               "2 hosts",
+			input:              2,
+			expectedHumanLabel: "QuestDB cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h",
+			expectedHumanDesc:  "QuestDB cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z",
+			expectedQuery:      "SELECT c.timestamp, c.hostname, c.mean_usage_user, m.mean_used_percent FROM ( SELECT timestamp, hostname, avg(usage_user) AS mean_usage_user FROM cpu WHERE hostname IN ('host_9', 'host_3') AND timestamp >= '1970-01-01T00:16:22Z' AND timestamp < '1970-01-01T12:16:22Z' SAMPLE BY 1h ) c JOIN ( SELECT timestamp, hostname, avg(used_percent) AS mean_used_percent FROM mem WHERE hostname IN ('host_9', 'host_3') AND timestamp >= '1970-01-01T00:16:22Z' AND timestamp < '1970-01-01T12:16:22Z' SAMPLE BY 1h ) m ON c.timestamp = m.timestamp AND c.hostname = m.hostname ORDER BY c.timestamp, c.hostname",
+		},
+	}
+
+	testFunc := func(d *Devops, c testCase) query.Query {
+		q := d.GenerateEmptyQuery()
+		d.CPUMemCorrelation(q, c.input, devops.CPUMemCorrelationDuration)
+		return q
+	}
+
+	start := time.Unix(0, 0)
+	end := start.Add(devops.CPUMemCorrelationDuration).Add(time.Hour)
+
+	runTestCases(t, testFunc, start, end, cases)
+}
+
 func TestLastPointPerHost(t *testing.T) {
 	expectedHumanLabel := "QuestDB last row per host"
 	expectedHumanDesc := "QuestDB last row per host"
@@ -4,9 +4,15 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/energy"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/events"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 const goTimeFmt = "2006-01-02 15:04:05.999999 -0700"
@@ -16,6 +22,7 @@ type BaseGenerator struct {
 	UseJSON       bool
 	UseTags       bool
 	UseTimeBucket bool
+	UseRollups    bool
 }
 
 // GenerateEmptyQuery returns an empty query.TimescaleDB.
@@ -63,3 +70,72 @@ func (g *BaseGenerator) NewIoT(start, end time.Time, scale int) (utils.QueryGene
 
 	return iot, nil
 }
+
+// NewK8s creates a new k8s use case query generator.
+func (g *BaseGenerator) NewK8s(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := k8s.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	k8s := &K8s{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return k8s, nil
+}
+
+// NewEnergy creates a new energy use case query generator.
+func (g *BaseGenerator) NewEnergy(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := energy.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	energy := &Energy{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return energy, nil
+}
+
+// NewEvents creates a new events use case query generator.
+func (g *BaseGenerator) NewEvents(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := events.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := &Events{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return events, nil
+}
+
+// NewNetflow creates a new netflow use case query generator.
+func (g *BaseGenerator) NewNetflow(start, end time.Time, scale int) (utils.QueryGenerator, error) {
+	core, err := netflow.NewCore(start, end, scale)
+
+	if err != nil {
+		return nil, err
+	}
+
+	netflow := &Netflow{
+		BaseGenerator: g,
+		Core:          core,
+	}
+
+	return netflow, nil
+}
+
+// FillInTemplate fills in a query.Query from the 'timescaledb' query of a query template.
+func (g *BaseGenerator) FillInTemplate(qi query.Query, t *templates.Instance) {
+	g.fillInQuery(qi, t.Label, t.Description, t.Table, t.MustRender(constants.FormatTimescaleDB))
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	internalutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
 )
 
@@ -143,6 +144,10 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	metrics, err := devops.GetCPUMetricsSlice(numMetrics)
 	panicIfErr(err)
 	interval := d.Interval.MustRandWindow(devops.DoubleGroupByDuration)
+	if d.UseRollups {
+		d.groupByTimeAndPrimaryTagRollup(qi, metrics, interval)
+		return
+	}
 
 	selectClauses := make([]string, numMetrics)
 	meanClauses := make([]string, numMetrics)
@@ -188,6 +193,42 @@ func (d *Devops) GroupByTimeAndPrimaryTag(qi query.Query, numMetrics int) {
 	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
 }
 
+// groupByTimeAndPrimaryTagRollup is the form of GroupByTimeAndPrimaryTag that
+// reads the hourly averages from the rollup (continuous aggregate) instead of
+// computing them from the raw data, e.g. in pseudo-SQL:
+//
+// SELECT bucket, hostname, metric1, ..., metricN
+// FROM cpu_1h JOIN tags
+// WHERE bucket >= '$HOUR_START' AND bucket < '$HOUR_END'
+// ORDER BY bucket, hostname
+func (d *Devops) groupByTimeAndPrimaryTagRollup(qi query.Query, metrics []string, interval *internalutils.TimeInterval) {
+	meanClauses := make([]string, len(metrics))
+	for i, m := range metrics {
+		meanClauses[i] = fmt.Sprintf("%[1]s as mean_%[1]s", m)
+	}
+
+	// the rollup is grouped by tags_id, so the hostname always comes from the tags table
+	hostnameField := "tags.hostname"
+	if d.UseJSON {
+		hostnameField = "tags->>'hostname'"
+	}
+
+	sql := fmt.Sprintf(`SELECT bucket as hour, %s, %s
+        FROM %s
+        JOIN tags ON %s.tags_id = tags.id
+        WHERE bucket >= '%s' AND bucket < '%s'
+        ORDER BY hour, %s`,
+		hostnameField, strings.Join(meanClauses, ", "),
+		devops.RollupTableName,
+		devops.RollupTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		hostnameField)
+	humanLabel := devops.GetDoubleGroupByRollupLabel("TimescaleDB", len(metrics))
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, devops.RollupTableName, sql)
+}
+
 // MaxAllCPU selects the MAX of all metrics under 'cpu' per hour for nhosts hosts,
 // e.g. in pseudo-SQL:
 //
@@ -258,3 +299,59 @@ func (d *Devops) HighCPUForHosts(qi query.Query, nHosts int) {
 	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
 	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
 }
+
+// CPUMemCorrelation joins the hourly AVG of usage_user under 'cpu' with the
+// hourly AVG of used_percent under 'mem' for the same nHosts hosts,
+// e.g. in pseudo-SQL:
+//
+// WITH cpu_avg AS (SELECT hour, hostname, AVG(usage_user) FROM cpu ...),
+// mem_avg AS (SELECT hour, hostname, AVG(used_percent) FROM mem ...)
+// SELECT hour, hostname, mean_usage_user, mean_used_percent
+// FROM cpu_avg JOIN mem_avg USING (hour, hostname)
+// ORDER BY hour, hostname
+func (d *Devops) CPUMemCorrelation(qi query.Query, nHosts int, duration time.Duration) {
+	interval := d.Interval.MustRandWindow(duration)
+	hostWhere := d.getHostWhereString(nHosts)
+
+	hostnameField := "cpu_avg.hostname"
+	joinStr := ""
+	partitionGrouping := "hostname"
+	if d.UseJSON || d.UseTags {
+		if d.UseJSON {
+			hostnameField = "tags->>'hostname'"
+		} else if d.UseTags {
+			hostnameField = "tags.hostname"
+		}
+		joinStr = "JOIN tags ON cpu_avg.tags_id = tags.id"
+		partitionGrouping = "tags_id"
+	}
+
+	sql := fmt.Sprintf(`
+        WITH cpu_avg AS (
+          SELECT %[1]s as hour, %[2]s, avg(usage_user) as mean_usage_user
+          FROM cpu
+          WHERE %[3]s AND time >= '%[4]s' AND time < '%[5]s'
+          GROUP BY 1, 2
+        ), mem_avg AS (
+          SELECT %[1]s as hour, %[2]s, avg(used_percent) as mean_used_percent
+          FROM mem
+          WHERE %[3]s AND time >= '%[4]s' AND time < '%[5]s'
+          GROUP BY 1, 2
+        )
+        SELECT cpu_avg.hour, %[6]s, mean_usage_user, mean_used_percent
+        FROM cpu_avg
+        JOIN mem_avg ON cpu_avg.hour = mem_avg.hour AND cpu_avg.%[2]s = mem_avg.%[2]s
+        %[7]s
+        ORDER BY cpu_avg.hour, %[6]s`,
+		d.getTimeBucket(oneHour),
+		partitionGrouping,
+		hostWhere,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		hostnameField,
+		joinStr)
+
+	humanLabel := devops.GetCPUMemCorrelationLabel("TimescaleDB", nHosts, duration)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	d.fillInQuery(qi, humanLabel, humanDesc, devops.TableName, sql)
+}
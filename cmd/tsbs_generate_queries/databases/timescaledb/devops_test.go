@@ -353,6 +353,65 @@ func TestGroupByTimeAndPrimaryTag(t *testing.T) {
 	}
 }
 
+func TestGroupByTimeAndPrimaryTagRollup(t *testing.T) {
+	cases := []struct {
+		desc               string
+		useJSON            bool
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedHypertable string
+		expectedSQLQuery   string
+	}{
+		{
+			desc:               "no JSON",
+			expectedHumanLabel: "TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup)",
+			expectedHumanDesc:  "TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup): 1970-01-01T00:16:22Z",
+			expectedHypertable: "cpu_1h",
+			expectedSQLQuery: `SELECT bucket as hour, tags.hostname, usage_user as mean_usage_user
+        FROM cpu_1h
+        JOIN tags ON cpu_1h.tags_id = tags.id
+        WHERE bucket >= '1970-01-01 00:16:22.646325 +0000' AND bucket < '1970-01-01 12:16:22.646325 +0000'
+        ORDER BY hour, tags.hostname`,
+		},
+		{
+			desc:               "use JSON",
+			useJSON:            true,
+			expectedHumanLabel: "TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup)",
+			expectedHumanDesc:  "TimescaleDB mean of 1 metrics, all hosts, random 12h0m0s by 1h (rollup): 1970-01-01T00:54:10Z",
+			expectedHypertable: "cpu_1h",
+			expectedSQLQuery: `SELECT bucket as hour, tags->>'hostname', usage_user as mean_usage_user
+        FROM cpu_1h
+        JOIN tags ON cpu_1h.tags_id = tags.id
+        WHERE bucket >= '1970-01-01 00:54:10.138978 +0000' AND bucket < '1970-01-01 12:54:10.138978 +0000'
+        ORDER BY hour, tags->>'hostname'`,
+		},
+	}
+
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(devops.DoubleGroupByDuration).Add(time.Hour)
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			b := BaseGenerator{
+				UseJSON:       c.useJSON,
+				UseTimeBucket: true,
+				UseRollups:    true,
+			}
+			dq, err := b.NewDevops(s, e, 10)
+			if err != nil {
+				t.Fatalf("Error while creating devops generator")
+			}
+			d := dq.(*Devops)
+
+			q := d.GenerateEmptyQuery()
+			d.GroupByTimeAndPrimaryTag(q, 1)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
+
 func TestMaxAllCPU(t *testing.T) {
 	expectedHumanLabel := "TimescaleDB max of all CPU metrics, random    1 hosts, random 8h0m0s by 1h"
 	expectedHumanDesc := "TimescaleDB max of all CPU metrics, random    1 hosts, random 8h0m0s by 1h: 1970-01-01T00:16:22Z"
@@ -383,6 +442,46 @@ func TestMaxAllCPU(t *testing.T) {
 	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedHypertable, expectedSQLQuery)
 }
 
+func TestCPUMemCorrelation(t *testing.T) {
+	expectedHumanLabel := "TimescaleDB cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h"
+	expectedHumanDesc := "TimescaleDB cpu usage_user joined with mem used_percent, random    2 hosts, random 12h0m0s by 1h: 1970-01-01T00:16:22Z"
+	expectedHypertable := "cpu"
+	expectedSQLQuery := `
+        WITH cpu_avg AS (
+          SELECT time_bucket('3600 seconds', time) as hour, tags_id, avg(usage_user) as mean_usage_user
+          FROM cpu
+          WHERE tags_id IN (SELECT id FROM tags WHERE hostname IN ('host_9','host_3')) AND time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+          GROUP BY 1, 2
+        ), mem_avg AS (
+          SELECT time_bucket('3600 seconds', time) as hour, tags_id, avg(used_percent) as mean_used_percent
+          FROM mem
+          WHERE tags_id IN (SELECT id FROM tags WHERE hostname IN ('host_9','host_3')) AND time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+          GROUP BY 1, 2
+        )
+        SELECT cpu_avg.hour, tags.hostname, mean_usage_user, mean_used_percent
+        FROM cpu_avg
+        JOIN mem_avg ON cpu_avg.hour = mem_avg.hour AND cpu_avg.tags_id = mem_avg.tags_id
+        JOIN tags ON cpu_avg.tags_id = tags.id
+        ORDER BY cpu_avg.hour, tags.hostname`
+	rand.Seed(123) // Setting seed for testing purposes.
+	s := time.Unix(0, 0)
+	e := s.Add(devops.CPUMemCorrelationDuration).Add(time.Hour)
+
+	b := BaseGenerator{
+		UseTimeBucket: true,
+		UseTags:       true,
+	}
+	dq, err := b.NewDevops(s, e, 10)
+	if err != nil {
+		t.Fatalf("Error while creating devops generator")
+	}
+	d := dq.(*Devops)
+
+	q := d.GenerateEmptyQuery()
+	d.CPUMemCorrelation(q, 2, devops.CPUMemCorrelationDuration)
+	verifyQuery(t, q, expectedHumanLabel, expectedHumanDesc, expectedHypertable, expectedSQLQuery)
+}
+
 func TestLastPointPerHost(t *testing.T) {
 	cases := []struct {
 		desc               string
@@ -0,0 +1,135 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/energy"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const fifteenMinutes = 15 * oneMinute
+
+// Energy produces TimescaleDB-specific queries for all the energy query types.
+type Energy struct {
+	*BaseGenerator
+	*energy.Core
+}
+
+func (e *Energy) getTimeBucket(seconds int) string {
+	if e.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// tagColumn returns the column of tag of the tags table aliased t.
+func (e *Energy) tagColumn(tag string) string {
+	if e.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", tag)
+	}
+	return "t." + tag
+}
+
+// tagEquals returns the condition on the tags table aliased t selecting the
+// meters whose tag is value.
+func (e *Energy) tagEquals(tag, value string) string {
+	if e.UseJSON {
+		return fmt.Sprintf("t.tagset @> '{\"%s\": \"%s\"}'", tag, value)
+	}
+	return fmt.Sprintf("t.%s = '%s'", tag, value)
+}
+
+// RegionDemand selects the energy consumed per region per hour over a random
+// day:
+//
+// SELECT hour, region, sum(energy_kwh) AS demand_kwh
+// FROM meter_readings r INNER JOIN tags t ON t.id = r.tags_id
+// WHERE time >= '$DAY_START' AND time < '$DAY_END'
+// GROUP BY hour, region ORDER BY hour, region
+func (e *Energy) RegionDemand(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.RegionDemandDuration)
+	sql := fmt.Sprintf(`SELECT %s AS hour, %s AS region, sum(energy_kwh) AS demand_kwh
+        FROM %s r
+        INNER JOIN tags t ON t.id = r.tags_id
+        WHERE time >= '%s' AND time < '%s'
+        GROUP BY hour, region
+        ORDER BY hour, region`,
+		e.getTimeBucket(oneHour),
+		e.tagColumn("region"),
+		energy.ReadingsTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB hourly demand per region, random 24h"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, energy.ReadingsTableName, sql)
+}
+
+// PeakDemand selects, for every feeder of a random region, the 15 minutes of
+// a random day with the highest demand and that demand.
+func (e *Energy) PeakDemand(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.PeakDemandDuration)
+	region := e.GetRandomRegion()
+	sql := fmt.Sprintf(`SELECT DISTINCT ON (feeder) feeder, bucket, demand_kw
+        FROM (
+            SELECT %s AS bucket, %s AS feeder, sum(demand_kw) AS demand_kw
+            FROM %s r
+            INNER JOIN tags t ON t.id = r.tags_id
+            WHERE %s
+            AND time >= '%s' AND time < '%s'
+            GROUP BY bucket, feeder) d
+        ORDER BY feeder, demand_kw DESC`,
+		e.getTimeBucket(fifteenMinutes),
+		e.tagColumn("feeder"),
+		energy.ReadingsTableName,
+		e.tagEquals("region", region),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB peak 15m demand per feeder, random region, random 24h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, region, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, energy.ReadingsTableName, sql)
+}
+
+// GapFillReadings selects the demand and voltage of a random meter every 15
+// minutes of a random day, carrying the last reading forward over the
+// missing ones. Without time_bucket, the last reading is looked up for every
+// 15 minutes of a generated series.
+func (e *Energy) GapFillReadings(qi query.Query) {
+	interval := e.Interval.MustRandWindow(energy.GapFillDuration)
+	meter := e.GetRandomMeter()
+	start, end := interval.Start().Format(goTimeFmt), interval.End().Format(goTimeFmt)
+	var sql string
+	if e.UseTimeBucket {
+		sql = fmt.Sprintf(`SELECT time_bucket_gapfill('%d seconds', time) AS bucket,
+            locf(avg(demand_kw)) AS demand_kw, locf(avg(voltage)) AS voltage
+        FROM %s
+        WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+        AND time >= '%s' AND time < '%s'
+        GROUP BY bucket
+        ORDER BY bucket`,
+			fifteenMinutes,
+			energy.ReadingsTableName,
+			e.tagEquals("meter", meter),
+			start, end)
+	} else {
+		sql = fmt.Sprintf(`SELECT g.bucket, r.demand_kw, r.voltage
+        FROM generate_series('%s'::timestamptz, '%s'::timestamptz - interval '%d seconds', interval '%[3]d seconds') AS g(bucket)
+        LEFT JOIN LATERAL (
+            SELECT demand_kw, voltage
+            FROM %s
+            WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+            AND time >= '%[1]s' AND time < g.bucket + interval '%[3]d seconds'
+            ORDER BY time DESC
+            LIMIT 1) r ON true
+        ORDER BY g.bucket`,
+			start, end,
+			fifteenMinutes,
+			energy.ReadingsTableName,
+			e.tagEquals("meter", meter))
+	}
+
+	humanLabel := "TimescaleDB gap-filled readings of a random meter, random 24h by 15m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, meter, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, energy.ReadingsTableName, sql)
+}
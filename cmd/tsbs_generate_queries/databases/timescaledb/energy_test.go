@@ -0,0 +1,112 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestEnergy(t *testing.T, b *BaseGenerator) *Energy {
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	g, err := b.NewEnergy(s, e, testScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g.(*Energy)
+}
+
+func TestEnergyQueries(t *testing.T) {
+	cases := []struct {
+		desc          string
+		useTimeBucket bool
+		useJSON       bool
+		fill          func(*Energy, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedHypertable string
+		expectedSQLQuery   string
+	}{
+		{
+			desc:          "region demand",
+			useTimeBucket: true,
+			fill:          (*Energy).RegionDemand,
+
+			expectedHumanLabel: "TimescaleDB hourly demand per region, random 24h",
+			expectedHumanDesc:  "TimescaleDB hourly demand per region, random 24h: 1970-01-01T18:16:22Z",
+			expectedHypertable: "meter_readings",
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, t.region AS region, sum(energy_kwh) AS demand_kwh
+        FROM meter_readings r
+        INNER JOIN tags t ON t.id = r.tags_id
+        WHERE time >= '1970-01-01 18:16:22.646325 +0000' AND time < '1970-01-02 18:16:22.646325 +0000'
+        GROUP BY hour, region
+        ORDER BY hour, region`,
+		},
+		{
+			desc:    "peak demand",
+			useJSON: true,
+			fill:    (*Energy).PeakDemand,
+
+			expectedHumanLabel: "TimescaleDB peak 15m demand per feeder, random region, random 24h",
+			expectedHumanDesc:  "TimescaleDB peak 15m demand per feeder, random region, random 24h: region_0 1970-01-01T18:16:22Z",
+			expectedHypertable: "meter_readings",
+			expectedSQLQuery: `SELECT DISTINCT ON (feeder) feeder, bucket, demand_kw
+        FROM (
+            SELECT to_timestamp(((extract(epoch from time)::int)/900)*900) AS bucket, t.tagset->>'feeder' AS feeder, sum(demand_kw) AS demand_kw
+            FROM meter_readings r
+            INNER JOIN tags t ON t.id = r.tags_id
+            WHERE t.tagset @> '{"region": "region_0"}'
+            AND time >= '1970-01-01 18:16:22.646325 +0000' AND time < '1970-01-02 18:16:22.646325 +0000'
+            GROUP BY bucket, feeder) d
+        ORDER BY feeder, demand_kw DESC`,
+		},
+		{
+			desc:          "gap fill",
+			useTimeBucket: true,
+			fill:          (*Energy).GapFillReadings,
+
+			expectedHumanLabel: "TimescaleDB gap-filled readings of a random meter, random 24h by 15m",
+			expectedHumanDesc:  "TimescaleDB gap-filled readings of a random meter, random 24h by 15m: meter_9 1970-01-01T18:16:22Z",
+			expectedHypertable: "meter_readings",
+			expectedSQLQuery: `SELECT time_bucket_gapfill('900 seconds', time) AS bucket,
+            locf(avg(demand_kw)) AS demand_kw, locf(avg(voltage)) AS voltage
+        FROM meter_readings
+        WHERE tags_id IN (SELECT id FROM tags t WHERE t.meter = 'meter_9')
+        AND time >= '1970-01-01 18:16:22.646325 +0000' AND time < '1970-01-02 18:16:22.646325 +0000'
+        GROUP BY bucket
+        ORDER BY bucket`,
+		},
+		{
+			desc: "gap fill without time bucket",
+			fill: (*Energy).GapFillReadings,
+
+			expectedHumanLabel: "TimescaleDB gap-filled readings of a random meter, random 24h by 15m",
+			expectedHumanDesc:  "TimescaleDB gap-filled readings of a random meter, random 24h by 15m: meter_9 1970-01-01T18:16:22Z",
+			expectedHypertable: "meter_readings",
+			expectedSQLQuery: `SELECT g.bucket, r.demand_kw, r.voltage
+        FROM generate_series('1970-01-01 18:16:22.646325 +0000'::timestamptz, '1970-01-02 18:16:22.646325 +0000'::timestamptz - interval '900 seconds', interval '900 seconds') AS g(bucket)
+        LEFT JOIN LATERAL (
+            SELECT demand_kw, voltage
+            FROM meter_readings
+            WHERE tags_id IN (SELECT id FROM tags t WHERE t.meter = 'meter_9')
+            AND time >= '1970-01-01 18:16:22.646325 +0000' AND time < g.bucket + interval '900 seconds'
+            ORDER BY time DESC
+            LIMIT 1) r ON true
+        ORDER BY g.bucket`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			g := newTestEnergy(t, &BaseGenerator{UseTimeBucket: c.useTimeBucket, UseJSON: c.useJSON})
+			q := g.GenerateEmptyQuery()
+			c.fill(g, q)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/events"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Events produces TimescaleDB-specific queries for all the events query types.
+type Events struct {
+	*BaseGenerator
+	*events.Core
+}
+
+func (e *Events) getTimeBucket(seconds int) string {
+	if e.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// tagColumn returns the column of tag of the tags table aliased t.
+func (e *Events) tagColumn(tag string) string {
+	if e.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", tag)
+	}
+	return "t." + tag
+}
+
+// tagEquals returns the condition on the tags table aliased t selecting the
+// hosts whose tag is value.
+func (e *Events) tagEquals(tag, value string) string {
+	if e.UseJSON {
+		return fmt.Sprintf("t.tagset @> '{\"%s\": \"%s\"}'", tag, value)
+	}
+	return fmt.Sprintf("t.%s = '%s'", tag, value)
+}
+
+// MessageSearch selects the last log lines of a random hour whose message
+// contains a random term:
+//
+// SELECT time, host, severity, message
+// FROM logs l INNER JOIN tags t ON t.id = l.tags_id
+// WHERE message ILIKE '%$TERM%' AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// ORDER BY time DESC LIMIT 100
+func (e *Events) MessageSearch(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.MessageSearchDuration)
+	term := e.GetRandomSearchTerm()
+	sql := fmt.Sprintf(`SELECT time, %s AS host, severity, message
+        FROM %s l
+        INNER JOIN tags t ON t.id = l.tags_id
+        WHERE message ILIKE '%%%s%%'
+        AND time >= '%s' AND time < '%s'
+        ORDER BY time DESC
+        LIMIT %d`,
+		e.tagColumn("host"),
+		events.LogsTableName,
+		term,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		events.MessageSearchLimit)
+
+	humanLabel := fmt.Sprintf("TimescaleDB last %d log lines matching a random term, random 1h", events.MessageSearchLimit)
+	humanDesc := fmt.Sprintf("%s: %q %s", humanLabel, term, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, events.LogsTableName, sql)
+}
+
+// SeverityCounts selects the number of log lines of a random service per
+// severity per minute over a random hour.
+func (e *Events) SeverityCounts(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.SeverityCountsDuration)
+	service := e.GetRandomService()
+	sql := fmt.Sprintf(`SELECT %s AS minute, severity, count(*) AS lines
+        FROM %s
+        WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+        AND time >= '%s' AND time < '%s'
+        GROUP BY minute, severity
+        ORDER BY minute, severity`,
+		e.getTimeBucket(oneMinute),
+		events.LogsTableName,
+		e.tagEquals("service", service),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB log lines per severity of a random service, random 1h by 1m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, service, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, events.LogsTableName, sql)
+}
+
+// TopErrors selects the most frequent error messages over a random day.
+func (e *Events) TopErrors(qi query.Query) {
+	interval := e.Interval.MustRandWindow(events.TopErrorsDuration)
+	sql := fmt.Sprintf(`SELECT message, error_type, count(*) AS occurrences
+        FROM %s
+        WHERE severity = 'error'
+        AND time >= '%s' AND time < '%s'
+        GROUP BY message, error_type
+        ORDER BY occurrences DESC
+        LIMIT %d`,
+		events.LogsTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		events.TopErrorsLimit)
+
+	humanLabel := fmt.Sprintf("TimescaleDB top %d error messages, random 24h", events.TopErrorsLimit)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	e.fillInQuery(qi, humanLabel, humanDesc, events.LogsTableName, sql)
+}
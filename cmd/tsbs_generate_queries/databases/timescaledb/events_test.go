@@ -0,0 +1,121 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestEvents(t *testing.T, b *BaseGenerator) *Events {
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	g, err := b.NewEvents(s, e, testScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g.(*Events)
+}
+
+func TestEventsQueries(t *testing.T) {
+	cases := []struct {
+		desc          string
+		useTimeBucket bool
+		useJSON       bool
+		fill          func(*Events, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedHypertable string
+		expectedSQLQuery   string
+	}{
+		{
+			desc: "message search",
+			fill: (*Events).MessageSearch,
+
+			expectedHumanLabel: "TimescaleDB last 100 log lines matching a random term, random 1h",
+			expectedHumanDesc:  "TimescaleDB last 100 log lines matching a random term, random 1h: \"slow request\" 1970-01-03T18:16:22Z",
+			expectedHypertable: "logs",
+			expectedSQLQuery: `SELECT time, t.host AS host, severity, message
+        FROM logs l
+        INNER JOIN tags t ON t.id = l.tags_id
+        WHERE message ILIKE '%slow request%'
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        ORDER BY time DESC
+        LIMIT 100`,
+		},
+		{
+			desc:    "message search with JSON tags",
+			useJSON: true,
+			fill:    (*Events).MessageSearch,
+
+			expectedHumanLabel: "TimescaleDB last 100 log lines matching a random term, random 1h",
+			expectedHumanDesc:  "TimescaleDB last 100 log lines matching a random term, random 1h: \"slow request\" 1970-01-03T18:16:22Z",
+			expectedHypertable: "logs",
+			expectedSQLQuery: `SELECT time, t.tagset->>'host' AS host, severity, message
+        FROM logs l
+        INNER JOIN tags t ON t.id = l.tags_id
+        WHERE message ILIKE '%slow request%'
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        ORDER BY time DESC
+        LIMIT 100`,
+		},
+		{
+			desc:          "severity counts",
+			useTimeBucket: true,
+			fill:          (*Events).SeverityCounts,
+
+			expectedHumanLabel: "TimescaleDB log lines per severity of a random service, random 1h by 1m",
+			expectedHumanDesc:  "TimescaleDB log lines per severity of a random service, random 1h by 1m: auth 1970-01-03T18:16:22Z",
+			expectedHypertable: "logs",
+			expectedSQLQuery: `SELECT time_bucket('60 seconds', time) AS minute, severity, count(*) AS lines
+        FROM logs
+        WHERE tags_id IN (SELECT id FROM tags t WHERE t.service = 'auth')
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY minute, severity
+        ORDER BY minute, severity`,
+		},
+		{
+			desc:    "severity counts with JSON tags",
+			useJSON: true,
+			fill:    (*Events).SeverityCounts,
+
+			expectedHumanLabel: "TimescaleDB log lines per severity of a random service, random 1h by 1m",
+			expectedHumanDesc:  "TimescaleDB log lines per severity of a random service, random 1h by 1m: auth 1970-01-03T18:16:22Z",
+			expectedHypertable: "logs",
+			expectedSQLQuery: `SELECT to_timestamp(((extract(epoch from time)::int)/60)*60) AS minute, severity, count(*) AS lines
+        FROM logs
+        WHERE tags_id IN (SELECT id FROM tags t WHERE t.tagset @> '{"service": "auth"}')
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY minute, severity
+        ORDER BY minute, severity`,
+		},
+		{
+			desc: "top errors",
+			fill: (*Events).TopErrors,
+
+			expectedHumanLabel: "TimescaleDB top 10 error messages, random 24h",
+			expectedHumanDesc:  "TimescaleDB top 10 error messages, random 24h: 1970-01-01T18:16:22Z",
+			expectedHypertable: "logs",
+			expectedSQLQuery: `SELECT message, error_type, count(*) AS occurrences
+        FROM logs
+        WHERE severity = 'error'
+        AND time >= '1970-01-01 18:16:22.646325 +0000' AND time < '1970-01-02 18:16:22.646325 +0000'
+        GROUP BY message, error_type
+        ORDER BY occurrences DESC
+        LIMIT 10`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			g := newTestEvents(t, &BaseGenerator{UseTimeBucket: c.useTimeBucket, UseJSON: c.useJSON})
+			q := g.GenerateEmptyQuery()
+			c.fill(g, q)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
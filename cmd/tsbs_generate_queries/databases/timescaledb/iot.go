@@ -432,3 +432,40 @@ func tenMinutePeriods(minutesPerHour float64, duration time.Duration) int {
 	leftover := minutesPerHour * duration.Hours()
 	return int((durationMinutes - leftover) / 10)
 }
+
+// ReadingsJoinDiagnostics joins the hourly average velocity of the trucks of
+// a random fleet with their hourly average fuel state and load.
+func (i *IoT) ReadingsJoinDiagnostics(qi query.Query) {
+	name, fleet := "name", "fleet"
+	interval := i.Interval.MustRandWindow(iot.ReadingsJoinDiagnosticsDuration)
+
+	sql := fmt.Sprintf(`WITH readings_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(velocity) AS mean_velocity
+			FROM readings
+			WHERE time >= '%[1]s' AND time < '%[2]s'
+			GROUP BY hour, tags_id
+			), diagnostics_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(fuel_state) AS mean_fuel_state, avg(current_load) AS mean_current_load
+			FROM diagnostics
+			WHERE time >= '%[1]s' AND time < '%[2]s'
+			GROUP BY hour, tags_id
+			)
+		SELECT r.hour, t.%[3]s, r.mean_velocity, d.mean_fuel_state, d.mean_current_load
+		FROM readings_avg r
+		INNER JOIN diagnostics_avg d ON r.hour = d.hour AND r.tags_id = d.tags_id
+		INNER JOIN tags t ON t.id = r.tags_id
+		WHERE t.%[4]s = '%[5]s'
+		ORDER BY r.hour, name`,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		i.withAlias(name),
+		i.columnSelect(fleet),
+		i.GetRandomFleet())
+
+	humanLabel := "TimescaleDB readings joined with diagnostics per truck per hour"
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+
+	i.fillInQuery(qi, humanLabel, humanDesc, iot.ReadingsTableName, sql)
+}
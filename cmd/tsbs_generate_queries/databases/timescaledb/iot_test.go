@@ -315,6 +315,78 @@ func TestStationaryTrucks(t *testing.T) {
 	}
 }
 
+func TestReadingsJoinDiagnostics(t *testing.T) {
+	cases := []testCase{
+		{
+			desc: "default to using tags",
+
+			expectedHumanLabel: "TimescaleDB readings joined with diagnostics per truck per hour",
+			expectedHumanDesc:  "TimescaleDB readings joined with diagnostics per truck per hour: 1970-01-01T00:16:22Z",
+			expectedHypertable: iot.ReadingsTableName,
+			expectedSQLQuery: `WITH readings_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(velocity) AS mean_velocity
+			FROM readings
+			WHERE time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+			GROUP BY hour, tags_id
+			), diagnostics_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(fuel_state) AS mean_fuel_state, avg(current_load) AS mean_current_load
+			FROM diagnostics
+			WHERE time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+			GROUP BY hour, tags_id
+			)
+		SELECT r.hour, t.name AS name, r.mean_velocity, d.mean_fuel_state, d.mean_current_load
+		FROM readings_avg r
+		INNER JOIN diagnostics_avg d ON r.hour = d.hour AND r.tags_id = d.tags_id
+		INNER JOIN tags t ON t.id = r.tags_id
+		WHERE t.fleet = 'West'
+		ORDER BY r.hour, name`,
+		},
+
+		{
+			desc: "use JSON",
+
+			useJSON:            true,
+			expectedHumanLabel: "TimescaleDB readings joined with diagnostics per truck per hour",
+			expectedHumanDesc:  "TimescaleDB readings joined with diagnostics per truck per hour: 1970-01-01T00:16:22Z",
+			expectedHypertable: iot.ReadingsTableName,
+			expectedSQLQuery: `WITH readings_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(velocity) AS mean_velocity
+			FROM readings
+			WHERE time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+			GROUP BY hour, tags_id
+			), diagnostics_avg
+		AS (
+			SELECT time_bucket('1 hour', time) AS hour, tags_id, avg(fuel_state) AS mean_fuel_state, avg(current_load) AS mean_current_load
+			FROM diagnostics
+			WHERE time >= '1970-01-01 00:16:22.646325 +0000' AND time < '1970-01-01 12:16:22.646325 +0000'
+			GROUP BY hour, tags_id
+			)
+		SELECT r.hour, t.tagset->>'name' AS name, r.mean_velocity, d.mean_fuel_state, d.mean_current_load
+		FROM readings_avg r
+		INNER JOIN diagnostics_avg d ON r.hour = d.hour AND r.tags_id = d.tags_id
+		INNER JOIN tags t ON t.id = r.tags_id
+		WHERE t.tagset->>'fleet' = 'West'
+		ORDER BY r.hour, name`,
+		},
+	}
+
+	for _, c := range cases {
+		b := &BaseGenerator{
+			UseJSON: c.useJSON,
+		}
+		g := NewIoT(time.Unix(0, 0), time.Unix(0, 0).Add(iot.ReadingsJoinDiagnosticsDuration).Add(time.Hour), 10, b)
+
+		q := g.GenerateEmptyQuery()
+		rand.Seed(123)
+		g.ReadingsJoinDiagnostics(q)
+
+		verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+	}
+}
+
 func TestTrucksWithLongDrivingSessions(t *testing.T) {
 	cases := []testCase{
 		{
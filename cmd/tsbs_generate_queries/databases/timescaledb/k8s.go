@@ -0,0 +1,217 @@
+package timescaledb
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const fiveMinutes = 5 * oneMinute
+
+// K8s produces TimescaleDB-specific queries for all the k8s query types.
+type K8s struct {
+	*BaseGenerator
+	*k8s.Core
+}
+
+func (k *K8s) getTimeBucket(seconds int) string {
+	if k.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// tagColumn returns the column of tag of the tags table aliased t.
+func (k *K8s) tagColumn(tag string) string {
+	if k.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", tag)
+	}
+	return "t." + tag
+}
+
+// withAlias returns the column of tag of the tags table aliased t, named tag.
+func (k *K8s) withAlias(tag string) string {
+	return fmt.Sprintf("%s AS %s", k.tagColumn(tag), tag)
+}
+
+// tagsWhere returns the conditions on the tags table aliased t selecting the
+// containers with the given tag values, given as key and value pairs.
+func (k *K8s) tagsWhere(keyValues ...string) string {
+	clauses := make([]string, 0, len(keyValues)/2)
+	for i := 0; i+1 < len(keyValues); i += 2 {
+		if k.UseJSON {
+			clauses = append(clauses, fmt.Sprintf("t.tagset @> '{\"%s\": \"%s\"}'", keyValues[i], keyValues[i+1]))
+		} else {
+			clauses = append(clauses, fmt.Sprintf("t.%s = '%s'", keyValues[i], keyValues[i+1]))
+		}
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// NamespaceCPU selects the cores used per namespace of a random cluster, per
+// 5 minutes over a random hour, from the increase of the CPU usage counters:
+//
+// SELECT bucket, namespace, sum(cpu) / 300 AS cores FROM (
+// SELECT 5min AS bucket, tags_id, max(usage_seconds_total) - min(usage_seconds_total) AS cpu
+// FROM container_cpu
+// WHERE tags_id IN (SELECT id FROM tags t WHERE t.cluster = '$CLUSTER')
+// AND time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY bucket, tags_id) c
+// INNER JOIN tags t ON t.id = c.tags_id
+// GROUP BY bucket, namespace ORDER BY bucket, namespace
+func (k *K8s) NamespaceCPU(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NamespaceCPUDuration)
+	cluster := k.GetRandomCluster()
+	sql := fmt.Sprintf(`SELECT bucket, %s, sum(cpu) / %d AS cores
+        FROM (
+            SELECT %s AS bucket, tags_id, max(usage_seconds_total) - min(usage_seconds_total) AS cpu
+            FROM %s
+            WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+            AND time >= '%s' AND time < '%s'
+            GROUP BY bucket, tags_id) c
+        INNER JOIN tags t ON t.id = c.tags_id
+        GROUP BY bucket, namespace
+        ORDER BY bucket, namespace`,
+		k.withAlias("namespace"),
+		fiveMinutes,
+		k.getTimeBucket(fiveMinutes),
+		k8s.CPUTableName,
+		k.tagsWhere("cluster", cluster),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB cores used per namespace, random cluster, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.CPUTableName, sql)
+}
+
+// NodeNetwork selects the network traffic, in bytes per second, per node of
+// a random cluster, per 5 minutes over a random hour.
+func (k *K8s) NodeNetwork(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.NodeNetworkDuration)
+	cluster := k.GetRandomCluster()
+	sql := fmt.Sprintf(`SELECT bucket, %s, sum(bytes) / %d AS bytes_per_second
+        FROM (
+            SELECT %s AS bucket, tags_id,
+                max(receive_bytes_total) - min(receive_bytes_total) + max(transmit_bytes_total) - min(transmit_bytes_total) AS bytes
+            FROM %s
+            WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+            AND time >= '%s' AND time < '%s'
+            GROUP BY bucket, tags_id) n
+        INNER JOIN tags t ON t.id = n.tags_id
+        GROUP BY bucket, node
+        ORDER BY bucket, node`,
+		k.withAlias("node"),
+		fiveMinutes,
+		k.getTimeBucket(fiveMinutes),
+		k8s.NetworkTableName,
+		k.tagsWhere("cluster", cluster),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB network traffic per node, random cluster, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.NetworkTableName, sql)
+}
+
+// TopPodsMemory selects the limit pods of a random namespace of a random
+// cluster whose containers used the most memory over a random hour.
+func (k *K8s) TopPodsMemory(qi query.Query, limit int) {
+	interval := k.Interval.MustRandWindow(k8s.TopPodsMemoryDuration)
+	cluster, namespace := k.GetRandomCluster(), k.GetRandomNamespace()
+	sql := fmt.Sprintf(`SELECT %s, max(working_set_bytes) AS max_working_set_bytes
+        FROM %s m
+        INNER JOIN tags t ON t.id = m.tags_id
+        WHERE %s
+        AND time >= '%s' AND time < '%s'
+        GROUP BY pod
+        ORDER BY max_working_set_bytes DESC, pod
+        LIMIT %d`,
+		k.withAlias("pod"),
+		k8s.MemoryTableName,
+		k.tagsWhere("cluster", cluster, "namespace", namespace),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		limit)
+
+	humanLabel := fmt.Sprintf("TimescaleDB top %d pods by memory, random namespace, random 1h", limit)
+	humanDesc := fmt.Sprintf("%s: %s/%s %s", humanLabel, cluster, namespace, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.MemoryTableName, sql)
+}
+
+// ContainerRestarts selects the number of container restarts per deployment
+// of a random cluster over a random 12 hours.
+func (k *K8s) ContainerRestarts(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.ContainerRestartsDuration)
+	cluster := k.GetRandomCluster()
+	sql := fmt.Sprintf(`SELECT %s, %s, sum(restarts) AS restarts
+        FROM (
+            SELECT tags_id, max(restarts_total) - min(restarts_total) AS restarts
+            FROM %s
+            WHERE tags_id IN (SELECT id FROM tags t WHERE %s)
+            AND time >= '%s' AND time < '%s'
+            GROUP BY tags_id) r
+        INNER JOIN tags t ON t.id = r.tags_id
+        GROUP BY namespace, deployment
+        ORDER BY namespace, deployment`,
+		k.withAlias("namespace"),
+		k.withAlias("deployment"),
+		k8s.StateTableName,
+		k.tagsWhere("cluster", cluster),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB container restarts per deployment, random cluster, random 12h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.StateTableName, sql)
+}
+
+// PodChurn selects the number of pods that ran in a random cluster per hour
+// over a random 12 hours.
+func (k *K8s) PodChurn(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.PodChurnDuration)
+	cluster := k.GetRandomCluster()
+	sql := fmt.Sprintf(`SELECT %s AS hour, count(DISTINCT %s) AS pods
+        FROM %s s
+        INNER JOIN tags t ON t.id = s.tags_id
+        WHERE %s
+        AND time >= '%s' AND time < '%s'
+        GROUP BY hour
+        ORDER BY hour`,
+		k.getTimeBucket(oneHour),
+		k.tagColumn("pod"),
+		k8s.StateTableName,
+		k.tagsWhere("cluster", cluster),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB pods per hour, random cluster, random 12h"
+	humanDesc := fmt.Sprintf("%s: %s %s", humanLabel, cluster, interval.StartString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.StateTableName, sql)
+}
+
+// LastPointPerContainer selects the last memory reading of every container of
+// a random deployment still running at a random time.
+func (k *K8s) LastPointPerContainer(qi query.Query) {
+	interval := k.Interval.MustRandWindow(k8s.LastPointDuration)
+	cluster := k.GetRandomCluster()
+	namespace, deployment := k.GetRandomDeployment()
+	sql := fmt.Sprintf(`SELECT DISTINCT ON (m.tags_id) %s, %s, time, working_set_bytes
+        FROM %s m
+        INNER JOIN tags t ON t.id = m.tags_id
+        WHERE %s
+        AND time >= '%s' AND time < '%s'
+        ORDER BY m.tags_id, time DESC`,
+		k.withAlias("pod"),
+		k.withAlias("container"),
+		k8s.MemoryTableName,
+		k.tagsWhere("cluster", cluster, "namespace", namespace, "deployment", deployment),
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB last memory reading per container, random deployment"
+	humanDesc := fmt.Sprintf("%s: %s/%s/%s %s", humanLabel, cluster, namespace, deployment, interval.EndString())
+	k.fillInQuery(qi, humanLabel, humanDesc, k8s.MemoryTableName, sql)
+}
@@ -0,0 +1,184 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestK8s(t *testing.T, b *BaseGenerator) *K8s {
+	s := time.Unix(0, 0)
+	e := s.Add(24 * time.Hour)
+	g, err := b.NewK8s(s, e, testScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g.(*K8s)
+}
+
+func TestK8sTagsWhere(t *testing.T) {
+	k := newTestK8s(t, &BaseGenerator{})
+	want := "t.cluster = 'cluster_0' AND t.namespace = 'web'"
+	if got := k.tagsWhere("cluster", "cluster_0", "namespace", "web"); got != want {
+		t.Errorf("incorrect tag conditions: got %s want %s", got, want)
+	}
+
+	k.UseJSON = true
+	want = `t.tagset @> '{"cluster": "cluster_0"}' AND t.tagset @> '{"namespace": "web"}'`
+	if got := k.tagsWhere("cluster", "cluster_0", "namespace", "web"); got != want {
+		t.Errorf("incorrect JSON tag conditions: got %s want %s", got, want)
+	}
+	if got := k.withAlias("pod"); got != "t.tagset->>'pod' AS pod" {
+		t.Errorf("incorrect JSON tag column: got %s", got)
+	}
+}
+
+func TestK8sQueries(t *testing.T) {
+	cases := []struct {
+		desc          string
+		useTimeBucket bool
+		useJSON       bool
+		fill          func(*K8s, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedHypertable string
+		expectedSQLQuery   string
+	}{
+		{
+			desc:          "namespace cpu",
+			useTimeBucket: true,
+			fill:          (*K8s).NamespaceCPU,
+
+			expectedHumanLabel: "TimescaleDB cores used per namespace, random cluster, random 1h by 5m",
+			expectedHumanDesc:  "TimescaleDB cores used per namespace, random cluster, random 1h by 5m: cluster_0 1970-01-01T20:16:22Z",
+			expectedHypertable: "container_cpu",
+			expectedSQLQuery: `SELECT bucket, t.namespace AS namespace, sum(cpu) / 300 AS cores
+        FROM (
+            SELECT time_bucket('300 seconds', time) AS bucket, tags_id, max(usage_seconds_total) - min(usage_seconds_total) AS cpu
+            FROM container_cpu
+            WHERE tags_id IN (SELECT id FROM tags t WHERE t.cluster = 'cluster_0')
+            AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+            GROUP BY bucket, tags_id) c
+        INNER JOIN tags t ON t.id = c.tags_id
+        GROUP BY bucket, namespace
+        ORDER BY bucket, namespace`,
+		},
+		{
+			desc:          "node network",
+			useTimeBucket: true,
+			fill:          (*K8s).NodeNetwork,
+
+			expectedHumanLabel: "TimescaleDB network traffic per node, random cluster, random 1h by 5m",
+			expectedHumanDesc:  "TimescaleDB network traffic per node, random cluster, random 1h by 5m: cluster_0 1970-01-01T20:16:22Z",
+			expectedHypertable: "container_network",
+			expectedSQLQuery: `SELECT bucket, t.node AS node, sum(bytes) / 300 AS bytes_per_second
+        FROM (
+            SELECT time_bucket('300 seconds', time) AS bucket, tags_id,
+                max(receive_bytes_total) - min(receive_bytes_total) + max(transmit_bytes_total) - min(transmit_bytes_total) AS bytes
+            FROM container_network
+            WHERE tags_id IN (SELECT id FROM tags t WHERE t.cluster = 'cluster_0')
+            AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+            GROUP BY bucket, tags_id) n
+        INNER JOIN tags t ON t.id = n.tags_id
+        GROUP BY bucket, node
+        ORDER BY bucket, node`,
+		},
+		{
+			desc:          "top pods memory",
+			useTimeBucket: true,
+			fill:          func(k *K8s, q query.Query) { k.TopPodsMemory(q, 10) },
+
+			expectedHumanLabel: "TimescaleDB top 10 pods by memory, random namespace, random 1h",
+			expectedHumanDesc:  "TimescaleDB top 10 pods by memory, random namespace, random 1h: cluster_0/frontend 1970-01-01T20:16:22Z",
+			expectedHypertable: "container_memory",
+			expectedSQLQuery: `SELECT t.pod AS pod, max(working_set_bytes) AS max_working_set_bytes
+        FROM container_memory m
+        INNER JOIN tags t ON t.id = m.tags_id
+        WHERE t.cluster = 'cluster_0' AND t.namespace = 'frontend'
+        AND time >= '1970-01-01 20:16:22.646325 +0000' AND time < '1970-01-01 21:16:22.646325 +0000'
+        GROUP BY pod
+        ORDER BY max_working_set_bytes DESC, pod
+        LIMIT 10`,
+		},
+		{
+			desc:          "container restarts",
+			useTimeBucket: true,
+			fill:          (*K8s).ContainerRestarts,
+
+			expectedHumanLabel: "TimescaleDB container restarts per deployment, random cluster, random 12h",
+			expectedHumanDesc:  "TimescaleDB container restarts per deployment, random cluster, random 12h: cluster_0 1970-01-01T06:16:22Z",
+			expectedHypertable: "kube_pod_container",
+			expectedSQLQuery: `SELECT t.namespace AS namespace, t.deployment AS deployment, sum(restarts) AS restarts
+        FROM (
+            SELECT tags_id, max(restarts_total) - min(restarts_total) AS restarts
+            FROM kube_pod_container
+            WHERE tags_id IN (SELECT id FROM tags t WHERE t.cluster = 'cluster_0')
+            AND time >= '1970-01-01 06:16:22.646325 +0000' AND time < '1970-01-01 18:16:22.646325 +0000'
+            GROUP BY tags_id) r
+        INNER JOIN tags t ON t.id = r.tags_id
+        GROUP BY namespace, deployment
+        ORDER BY namespace, deployment`,
+		},
+		{
+			desc:          "pod churn",
+			useTimeBucket: true,
+			fill:          (*K8s).PodChurn,
+
+			expectedHumanLabel: "TimescaleDB pods per hour, random cluster, random 12h",
+			expectedHumanDesc:  "TimescaleDB pods per hour, random cluster, random 12h: cluster_0 1970-01-01T06:16:22Z",
+			expectedHypertable: "kube_pod_container",
+			expectedSQLQuery: `SELECT time_bucket('3600 seconds', time) AS hour, count(DISTINCT t.pod) AS pods
+        FROM kube_pod_container s
+        INNER JOIN tags t ON t.id = s.tags_id
+        WHERE t.cluster = 'cluster_0'
+        AND time >= '1970-01-01 06:16:22.646325 +0000' AND time < '1970-01-01 18:16:22.646325 +0000'
+        GROUP BY hour
+        ORDER BY hour`,
+		},
+		{
+			desc:    "pod churn with JSON tags",
+			useJSON: true,
+			fill:    (*K8s).PodChurn,
+
+			expectedHumanLabel: "TimescaleDB pods per hour, random cluster, random 12h",
+			expectedHumanDesc:  "TimescaleDB pods per hour, random cluster, random 12h: cluster_0 1970-01-01T06:16:22Z",
+			expectedHypertable: "kube_pod_container",
+			expectedSQLQuery: `SELECT to_timestamp(((extract(epoch from time)::int)/3600)*3600) AS hour, count(DISTINCT t.tagset->>'pod') AS pods
+        FROM kube_pod_container s
+        INNER JOIN tags t ON t.id = s.tags_id
+        WHERE t.tagset @> '{"cluster": "cluster_0"}'
+        AND time >= '1970-01-01 06:16:22.646325 +0000' AND time < '1970-01-01 18:16:22.646325 +0000'
+        GROUP BY hour
+        ORDER BY hour`,
+		},
+		{
+			desc:          "lastpoint",
+			useTimeBucket: true,
+			fill:          (*K8s).LastPointPerContainer,
+
+			expectedHumanLabel: "TimescaleDB last memory reading per container, random deployment",
+			expectedHumanDesc:  "TimescaleDB last memory reading per container, random deployment: cluster_0/monitoring/node-exporter 1970-01-01T23:41:22Z",
+			expectedHypertable: "container_memory",
+			expectedSQLQuery: `SELECT DISTINCT ON (m.tags_id) t.pod AS pod, t.container AS container, time, working_set_bytes
+        FROM container_memory m
+        INNER JOIN tags t ON t.id = m.tags_id
+        WHERE t.cluster = 'cluster_0' AND t.namespace = 'monitoring' AND t.deployment = 'node-exporter'
+        AND time >= '1970-01-01 23:36:22.646325 +0000' AND time < '1970-01-01 23:41:22.646325 +0000'
+        ORDER BY m.tags_id, time DESC`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			g := newTestK8s(t, &BaseGenerator{UseTimeBucket: c.useTimeBucket, UseJSON: c.useJSON})
+			q := g.GenerateEmptyQuery()
+			c.fill(g, q)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
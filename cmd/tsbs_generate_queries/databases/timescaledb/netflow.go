@@ -0,0 +1,106 @@
+package timescaledb
+
+import (
+	"fmt"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Netflow produces TimescaleDB-specific queries for all the netflow query types.
+type Netflow struct {
+	*BaseGenerator
+	*netflow.Core
+}
+
+func (n *Netflow) getTimeBucket(seconds int) string {
+	if n.UseTimeBucket {
+		return fmt.Sprintf(timeBucketFmt, seconds)
+	}
+	return fmt.Sprintf(nonTimeBucketFmt, seconds, seconds)
+}
+
+// tagColumn returns the column of tag of the tags table aliased t.
+func (n *Netflow) tagColumn(tag string) string {
+	if n.UseJSON {
+		return fmt.Sprintf("t.tagset->>'%s'", tag)
+	}
+	return "t." + tag
+}
+
+// TopTalkers selects the sources that sent the most bytes over a random
+// hour:
+//
+// SELECT src_ip, sum(bytes) AS bytes
+// FROM flows f INNER JOIN tags t ON t.id = f.tags_id
+// WHERE time >= '$HOUR_START' AND time < '$HOUR_END'
+// GROUP BY src_ip ORDER BY bytes DESC LIMIT 10
+func (n *Netflow) TopTalkers(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.TopTalkersDuration)
+	sql := fmt.Sprintf(`SELECT %s AS src_ip, sum(bytes) AS bytes
+        FROM %s f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE time >= '%s' AND time < '%s'
+        GROUP BY src_ip
+        ORDER BY bytes DESC
+        LIMIT %d`,
+		n.tagColumn("src_ip"),
+		netflow.FlowsTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		netflow.TopTalkersLimit)
+
+	humanLabel := fmt.Sprintf("TimescaleDB top %d talkers, random 1h", netflow.TopTalkersLimit)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, netflow.FlowsTableName, sql)
+}
+
+// SubnetTraffic selects the bytes sent and received by the hosts of a random
+// subnet every 5 minutes over a random hour.
+func (n *Netflow) SubnetTraffic(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.SubnetTrafficDuration)
+	prefix := n.GetRandomSubnetPrefix()
+	src, dst := n.tagColumn("src_ip"), n.tagColumn("dst_ip")
+	sql := fmt.Sprintf(`SELECT %[1]s AS five_min,
+            sum(bytes) FILTER (WHERE %[2]s LIKE '%[3]s%%') AS bytes_out,
+            sum(bytes) FILTER (WHERE %[4]s LIKE '%[3]s%%') AS bytes_in
+        FROM %[5]s f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE (%[2]s LIKE '%[3]s%%' OR %[4]s LIKE '%[3]s%%')
+        AND time >= '%[6]s' AND time < '%[7]s'
+        GROUP BY five_min
+        ORDER BY five_min`,
+		n.getTimeBucket(5*oneMinute),
+		src, prefix, dst,
+		netflow.FlowsTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt))
+
+	humanLabel := "TimescaleDB traffic of a random subnet, random 1h by 5m"
+	humanDesc := fmt.Sprintf("%s: %s0/24 %s", humanLabel, prefix, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, netflow.FlowsTableName, sql)
+}
+
+// PortScans selects the sources that connected to many ports of the same
+// destination over a random hour, and the number of ports.
+func (n *Netflow) PortScans(qi query.Query) {
+	interval := n.Interval.MustRandWindow(netflow.PortScansDuration)
+	sql := fmt.Sprintf(`SELECT %s AS src_ip, %s AS dst_ip, count(DISTINCT %s) AS ports
+        FROM %s f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE time >= '%s' AND time < '%s'
+        GROUP BY src_ip, dst_ip
+        HAVING count(DISTINCT %[3]s) > %[7]d
+        ORDER BY ports DESC`,
+		n.tagColumn("src_ip"),
+		n.tagColumn("dst_ip"),
+		n.tagColumn("dst_port"),
+		netflow.FlowsTableName,
+		interval.Start().Format(goTimeFmt),
+		interval.End().Format(goTimeFmt),
+		netflow.PortScanThreshold)
+
+	humanLabel := fmt.Sprintf("TimescaleDB sources connecting to more than %d ports of a destination, random 1h", netflow.PortScanThreshold)
+	humanDesc := fmt.Sprintf("%s: %s", humanLabel, interval.StartString())
+	n.fillInQuery(qi, humanLabel, humanDesc, netflow.FlowsTableName, sql)
+}
@@ -0,0 +1,111 @@
+package timescaledb
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newTestNetflow(t *testing.T, b *BaseGenerator) *Netflow {
+	s := time.Unix(0, 0)
+	e := s.Add(72 * time.Hour)
+	g, err := b.NewNetflow(s, e, testScale)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return g.(*Netflow)
+}
+
+func TestNetflowQueries(t *testing.T) {
+	cases := []struct {
+		desc          string
+		useTimeBucket bool
+		useJSON       bool
+		fill          func(*Netflow, query.Query)
+
+		expectedHumanLabel string
+		expectedHumanDesc  string
+		expectedHypertable string
+		expectedSQLQuery   string
+	}{
+		{
+			desc: "top talkers",
+			fill: (*Netflow).TopTalkers,
+
+			expectedHumanLabel: "TimescaleDB top 10 talkers, random 1h",
+			expectedHumanDesc:  "TimescaleDB top 10 talkers, random 1h: 1970-01-03T18:16:22Z",
+			expectedHypertable: "flows",
+			expectedSQLQuery: `SELECT t.src_ip AS src_ip, sum(bytes) AS bytes
+        FROM flows f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY src_ip
+        ORDER BY bytes DESC
+        LIMIT 10`,
+		},
+		{
+			desc:          "subnet traffic",
+			useTimeBucket: true,
+			fill:          (*Netflow).SubnetTraffic,
+
+			expectedHumanLabel: "TimescaleDB traffic of a random subnet, random 1h by 5m",
+			expectedHumanDesc:  "TimescaleDB traffic of a random subnet, random 1h by 5m: 10.0.0.0/24 1970-01-03T18:16:22Z",
+			expectedHypertable: "flows",
+			expectedSQLQuery: `SELECT time_bucket('300 seconds', time) AS five_min,
+            sum(bytes) FILTER (WHERE t.src_ip LIKE '10.0.0.%') AS bytes_out,
+            sum(bytes) FILTER (WHERE t.dst_ip LIKE '10.0.0.%') AS bytes_in
+        FROM flows f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE (t.src_ip LIKE '10.0.0.%' OR t.dst_ip LIKE '10.0.0.%')
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY five_min
+        ORDER BY five_min`,
+		},
+		{
+			desc:    "subnet traffic with JSON tags",
+			useJSON: true,
+			fill:    (*Netflow).SubnetTraffic,
+
+			expectedHumanLabel: "TimescaleDB traffic of a random subnet, random 1h by 5m",
+			expectedHumanDesc:  "TimescaleDB traffic of a random subnet, random 1h by 5m: 10.0.0.0/24 1970-01-03T18:16:22Z",
+			expectedHypertable: "flows",
+			expectedSQLQuery: `SELECT to_timestamp(((extract(epoch from time)::int)/300)*300) AS five_min,
+            sum(bytes) FILTER (WHERE t.tagset->>'src_ip' LIKE '10.0.0.%') AS bytes_out,
+            sum(bytes) FILTER (WHERE t.tagset->>'dst_ip' LIKE '10.0.0.%') AS bytes_in
+        FROM flows f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE (t.tagset->>'src_ip' LIKE '10.0.0.%' OR t.tagset->>'dst_ip' LIKE '10.0.0.%')
+        AND time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY five_min
+        ORDER BY five_min`,
+		},
+		{
+			desc: "port scans",
+			fill: (*Netflow).PortScans,
+
+			expectedHumanLabel: "TimescaleDB sources connecting to more than 100 ports of a destination, random 1h",
+			expectedHumanDesc:  "TimescaleDB sources connecting to more than 100 ports of a destination, random 1h: 1970-01-03T18:16:22Z",
+			expectedHypertable: "flows",
+			expectedSQLQuery: `SELECT t.src_ip AS src_ip, t.dst_ip AS dst_ip, count(DISTINCT t.dst_port) AS ports
+        FROM flows f
+        INNER JOIN tags t ON t.id = f.tags_id
+        WHERE time >= '1970-01-03 18:16:22.646325 +0000' AND time < '1970-01-03 19:16:22.646325 +0000'
+        GROUP BY src_ip, dst_ip
+        HAVING count(DISTINCT t.dst_port) > 100
+        ORDER BY ports DESC`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			rand.Seed(123) // Setting seed for testing purposes.
+			g := newTestNetflow(t, &BaseGenerator{UseTimeBucket: c.useTimeBucket, UseJSON: c.useJSON})
+			q := g.GenerateEmptyQuery()
+			c.fill(g, q)
+
+			verifyQuery(t, q, c.expectedHumanLabel, c.expectedHumanDesc, c.expectedHypertable, c.expectedSQLQuery)
+		})
+	}
+}
@@ -7,9 +7,11 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	iutils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 type BaseGenerator struct{}
@@ -61,3 +63,15 @@ func (g *BaseGenerator) fillInQuery(qq query.Query, qi *queryInfo) {
 	q.Path = []byte(fmt.Sprintf("/api/v1/query_range?%s", v.Encode()))
 	q.Body = nil
 }
+
+// FillInTemplate fills in a query.Query from the 'victoriametrics' query of a
+// query template, using its bucket as the step.
+func (g *BaseGenerator) FillInTemplate(qq query.Query, t *templates.Instance) {
+	qi := &queryInfo{
+		query:    t.MustRender(constants.FormatVictoriaMetrics),
+		label:    t.Label,
+		interval: t.Interval,
+		step:     strconv.FormatInt(int64(t.Bucket.Seconds()), 10),
+	}
+	g.fillInQuery(qq, qi)
+}
@@ -12,8 +12,13 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/energy"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/events"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/finance"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/k8s"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/netflow"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	"github.com/timescale/tsbs/internal/inputs"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
@@ -37,6 +42,7 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 		devops.LabelHighCPU + "-all":          devops.NewHighCPU(0),
 		devops.LabelHighCPU + "-1":            devops.NewHighCPU(1),
 		devops.LabelLastpoint:                 devops.NewLastPointPerHost,
+		devops.LabelCPUMemCorrelation:         devops.NewCPUMemCorrelation(devops.CPUMemCorrelationHosts, devops.CPUMemCorrelationDuration),
 	},
 	"iot": {
 		iot.LabelLastLoc:                       iot.NewLastLocPerTruck,
@@ -52,6 +58,7 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 		iot.LabelAvgLoad:                       iot.NewAvgLoad,
 		iot.LabelDailyActivity:                 iot.NewDailyTruckActivity,
 		iot.LabelBreakdownFrequency:            iot.NewTruckBreakdownFrequency,
+		iot.LabelReadingsJoinDiagnostics:       iot.NewReadingsJoinDiagnostics,
 	},
 	"finance": {
 		finance.LabelLastPrice:                                finance.NewLastPrice,
@@ -123,6 +130,66 @@ var useCaseMatrix = map[string]map[string]utils.QueryFillerMaker{
 		finance.LabelTopPercentChange + "-4h-1h":              finance.NewTopPercentChange(24*time.Hour, time.Hour),
 		finance.LabelTopPercentChange + "-1d-4h":              finance.NewTopPercentChange(24*time.Hour, 4*time.Hour),
 		finance.LabelTopPercentChange + "-1w-1d":              finance.NewTopPercentChange(7*24*time.Hour, 24*time.Hour),
+		finance.LabelVWAP + "-1h-15m":                         finance.NewVWAP(time.Hour, 15*time.Minute),
+		finance.LabelVWAP + "-4h-1h":                          finance.NewVWAP(4*time.Hour, time.Hour),
+		finance.LabelVWAP + "-1d-4h":                          finance.NewVWAP(24*time.Hour, 4*time.Hour),
+		finance.LabelSpread + "-1h-15m":                       finance.NewSpread(time.Hour, 15*time.Minute),
+		finance.LabelSpread + "-4h-1h":                        finance.NewSpread(4*time.Hour, time.Hour),
+		finance.LabelSpread + "-1d-4h":                        finance.NewSpread(24*time.Hour, 4*time.Hour),
+	},
+	"k8s": {
+		k8s.LabelNamespaceCPU + "-1h":       k8s.NewNamespaceCPU,
+		k8s.LabelNodeNetwork + "-1h":        k8s.NewNodeNetwork,
+		k8s.LabelTopPodsMemory + "-10":      k8s.NewTopPodsMemory(k8s.TopPodsMemoryLimit),
+		k8s.LabelContainerRestarts + "-12h": k8s.NewContainerRestarts,
+		k8s.LabelPodChurn + "-12h":          k8s.NewPodChurn,
+		k8s.LabelLastPoint:                  k8s.NewLastPointPerContainer,
+	},
+	"energy": {
+		energy.LabelRegionDemand + "-24h": energy.NewRegionDemand,
+		energy.LabelPeakDemand + "-24h":   energy.NewPeakDemand,
+		energy.LabelGapFill + "-24h":      energy.NewGapFillReadings,
+	},
+	"events": {
+		events.LabelMessageSearch + "-1h":  events.NewMessageSearch,
+		events.LabelSeverityCounts + "-1h": events.NewSeverityCounts,
+		events.LabelTopErrors + "-24h":     events.NewTopErrors,
+	},
+	"netflow": {
+		netflow.LabelTopTalkers + "-10":    netflow.NewTopTalkers,
+		netflow.LabelSubnetTraffic + "-1h": netflow.NewSubnetTraffic,
+		netflow.LabelPortScans + "-1h":     netflow.NewPortScans,
+	},
+}
+
+// parameterizedMatrix lists the query types whose shape can be set with
+// params in a query mix file.
+var parameterizedMatrix = map[string]map[string]utils.ParameterizedQueryFillerMaker{
+	"devops": {
+		devops.LabelSingleGroupby:     devops.NewSingleGroupbyFromParams,
+		devops.LabelMaxAll:            devops.NewMaxAllCPUFromParams,
+		devops.LabelDoubleGroupby:     devops.NewGroupByFromParams,
+		devops.LabelHighCPU:           devops.NewHighCPUFromParams,
+		devops.LabelCPUMemCorrelation: devops.NewCPUMemCorrelationFromParams,
+		templates.LabelTemplate:       templates.NewQueryFromParams,
+	},
+	"iot": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
+	},
+	"finance": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
+	},
+	"k8s": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
+	},
+	"energy": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
+	},
+	"events": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
+	},
+	"netflow": {
+		templates.LabelTemplate: templates.NewQueryFromParams,
 	},
 }
 
@@ -131,6 +198,7 @@ var conf = &config.QueryGeneratorConfig{}
 // Parse args:
 func init() {
 	useCaseMatrix["cpu-only"] = useCaseMatrix["devops"]
+	parameterizedMatrix["cpu-only"] = parameterizedMatrix["devops"]
 	// Change the Usage function to print the use case matrix of choices:
 	oldUsage := pflag.Usage
 	pflag.Usage = func() {
@@ -166,6 +234,7 @@ func init() {
 
 func main() {
 	qg := inputs.NewQueryGenerator(useCaseMatrix)
+	qg.ParameterizedMatrix = parameterizedMatrix
 	err := qg.Generate(conf)
 	if err != nil {
 		fmt.Printf("error: %v\n", err)
@@ -21,6 +21,9 @@ type Core struct {
 
 	// Scale is the cardinality of the dataset in terms of devices/hosts
 	Scale int
+
+	// hostDist picks the devices/hosts of a query; nil means uniformly
+	hostDist HostDistribution
 }
 
 // NewCore returns a new Core for the given time range and cardinality
@@ -33,6 +36,26 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 	return &Core{Interval: ti, Scale: scale}, nil
 }
 
+// GetInterval returns the entire time range of the dataset
+func (c *Core) GetInterval() *internalutils.TimeInterval {
+	return c.Interval
+}
+
+// SetDistributions sets how random time windows and hosts are picked for
+// queries. A nil distribution keeps the default uniform one.
+func (c *Core) SetDistributions(window internalutils.WindowDistribution, hosts HostDistribution) {
+	c.Interval.SetWindowDistribution(window)
+	c.hostDist = hosts
+}
+
+// HostDistribution returns the HostDistribution used to pick devices/hosts.
+func (c *Core) HostDistribution() HostDistribution {
+	if c.hostDist == nil {
+		return UniformHosts{}
+	}
+	return c.hostDist
+}
+
 // PanicUnimplementedQuery generates a panic for the provided query generator.
 func PanicUnimplementedQuery(dg utils.QueryGenerator) {
 	panic(fmt.Sprintf("database (%v) does not implement query", reflect.TypeOf(dg)))
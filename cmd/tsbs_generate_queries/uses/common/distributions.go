@@ -0,0 +1,113 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+
+	internalutils "github.com/timescale/tsbs/internal/utils"
+)
+
+// Names of the supported HostDistributions
+const (
+	HostDistUniform = "uniform"
+	HostDistZipf    = "zipf"
+)
+
+const errUnknownHostDistFmt = "unknown host distribution '%s'"
+
+// HostDistributionChoices returns the names of the supported HostDistributions.
+func HostDistributionChoices() []string {
+	return []string{HostDistUniform, HostDistZipf}
+}
+
+// HostDistribution decides which hosts (or trucks, or other entities) a
+// query touches.
+type HostDistribution interface {
+	// Subset returns numItems distinct numbers from 0 to totalItems.
+	Subset(numItems, totalItems int) ([]int, error)
+}
+
+// NewHostDistribution returns the HostDistribution with the given name.
+// s is the exponent used by the zipf distribution.
+func NewHostDistribution(name string, s float64) (HostDistribution, error) {
+	switch name {
+	case HostDistUniform:
+		return UniformHosts{}, nil
+	case HostDistZipf:
+		return &ZipfHosts{S: s}, nil
+	default:
+		return nil, fmt.Errorf(errUnknownHostDistFmt, name)
+	}
+}
+
+// UniformHosts picks every host with the same probability.
+type UniformHosts struct{}
+
+// Subset returns a uniformly random subset, see GetRandomSubsetPerm.
+func (UniformHosts) Subset(numItems, totalItems int) ([]int, error) {
+	return GetRandomSubsetPerm(numItems, totalItems)
+}
+
+// ZipfHosts picks hosts following a Zipf distribution with exponent S: a few
+// hot hosts (host_0, host_1, ...) are in most queries, while the long tail
+// is rarely queried.
+type ZipfHosts struct {
+	S float64
+
+	zipfs map[int]*internalutils.Zipf // per total number of items
+}
+
+// Subset returns a Zipf distributed subset of numItems distinct numbers.
+func (z *ZipfHosts) Subset(numItems, totalItems int) ([]int, error) {
+	if numItems > totalItems {
+		// Cannot make a subset longer than the original set
+		return nil, fmt.Errorf(errMoreItemsThanScale)
+	}
+
+	// Redrawing until an unseen item comes up gets slow once most items
+	// have been picked, since the remaining ones are the unpopular ones.
+	if 2*numItems > totalItems {
+		return z.weightedSample(numItems, totalItems), nil
+	}
+
+	if z.zipfs == nil {
+		z.zipfs = make(map[int]*internalutils.Zipf)
+	}
+	zipf, ok := z.zipfs[totalItems]
+	if !ok {
+		zipf = internalutils.NewZipf(z.S, totalItems)
+		z.zipfs[totalItems] = zipf
+	}
+
+	seen := map[int]bool{}
+	res := make([]int, numItems)
+	for i := 0; i < numItems; i++ {
+		for {
+			n := zipf.Rank()
+			if !seen[n] {
+				seen[n] = true
+				res[i] = n
+				break
+			}
+		}
+	}
+	return res, nil
+}
+
+// weightedSample draws numItems without replacement in a single pass over
+// all items (Efraimidis-Spirakis): every item gets the key log(u)/weight and
+// the items with the largest keys are picked.
+func (z *ZipfHosts) weightedSample(numItems, totalItems int) []int {
+	keys := make([]float64, totalItems)
+	items := make([]int, totalItems)
+	for i := range items {
+		items[i] = i
+		keys[i] = math.Log(rand.Float64()) / internalutils.ZipfWeight(z.S, i)
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return keys[items[a]] > keys[items[b]]
+	})
+	return items[:numItems]
+}
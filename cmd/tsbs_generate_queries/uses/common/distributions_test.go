@@ -0,0 +1,75 @@
+package common
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewHostDistribution(t *testing.T) {
+	for _, name := range HostDistributionChoices() {
+		if _, err := NewHostDistribution(name, 1.1); err != nil {
+			t.Errorf("unexpected error for %s: %v", name, err)
+		}
+	}
+	if _, err := NewHostDistribution("foo", 1.1); err == nil {
+		t.Errorf("unexpected lack of error for unknown distribution")
+	}
+}
+
+func TestZipfHostsSubset(t *testing.T) {
+	rand.Seed(123)
+	z := &ZipfHosts{S: 1.1}
+	const total = 100
+
+	// both the rejection sampling and the weighted sampling paths
+	for _, numItems := range []int{1, 8, 50, 90, 100} {
+		res, err := z.Subset(numItems, total)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(res) != numItems {
+			t.Fatalf("incorrect number of items: got %d want %d", len(res), numItems)
+		}
+		seen := map[int]bool{}
+		for _, n := range res {
+			if n < 0 || n >= total {
+				t.Fatalf("item out of range: %d", n)
+			}
+			if seen[n] {
+				t.Fatalf("duplicate item %d in %v", n, res)
+			}
+			seen[n] = true
+		}
+	}
+
+	// host 0 should be the hottest one
+	counts := make([]int, total)
+	for i := 0; i < 2000; i++ {
+		res, _ := z.Subset(1, total)
+		counts[res[0]]++
+	}
+	if counts[0] < counts[total-1]*10 {
+		t.Errorf("hosts not zipf distributed: host 0 %d, host %d %d", counts[0], total-1, counts[total-1])
+	}
+
+	if _, err := z.Subset(total+1, total); err == nil {
+		t.Errorf("unexpected lack of error for more items than total")
+	}
+}
+
+func TestCoreSetDistributions(t *testing.T) {
+	s := time.Unix(0, 0)
+	c, err := NewCore(s, s.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := c.HostDistribution().(UniformHosts); !ok {
+		t.Errorf("default host distribution is not uniform")
+	}
+	z := &ZipfHosts{S: 1.1}
+	c.SetDistributions(nil, z)
+	if c.HostDistribution() != z {
+		t.Errorf("host distribution not set")
+	}
+}
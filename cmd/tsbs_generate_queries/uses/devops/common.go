@@ -6,6 +6,7 @@ import (
 
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
 	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 const (
@@ -16,6 +17,8 @@ const (
 
 	// TableName is the name of the table where the time series data is stored for devops use case.
 	TableName = "cpu"
+	// MemTableName is the name of the table where the memory time series data is stored.
+	MemTableName = "mem"
 
 	// DoubleGroupByDuration is the how big the time range for DoubleGroupBy query is
 	DoubleGroupByDuration = 12 * time.Hour
@@ -23,6 +26,10 @@ const (
 	HighCPUDuration = 12 * time.Hour
 	// MaxAllDuration is the how big the time range for MaxAll query is
 	MaxAllDuration = 8 * time.Hour
+	// CPUMemCorrelationDuration is how big the time range for CPUMemCorrelation query is
+	CPUMemCorrelationDuration = 12 * time.Hour
+	// CPUMemCorrelationHosts is the default number of hosts of the CPUMemCorrelation query
+	CPUMemCorrelationHosts = 8
 
 	// LabelSingleGroupby is the label prefix for queries of the single groupby variety
 	LabelSingleGroupby = "single-groupby"
@@ -36,6 +43,11 @@ const (
 	LabelGroupbyOrderbyLimit = "groupby-orderby-limit"
 	// LabelHighCPU is the prefix for queries of the high-CPU variety
 	LabelHighCPU = "high-cpu"
+	// LabelCPUMemCorrelation is the label for the cpu-mem-correlation join query
+	LabelCPUMemCorrelation = "cpu-mem-correlation"
+
+	// RollupTableName is the name of the hourly rollup of TableName
+	RollupTableName = TableName + constants.RollupSuffix
 )
 
 // Core is the common component of all generators for all systems
@@ -52,7 +64,7 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 // GetRandomHosts returns a random set of nHosts from a given Core
 func (d *Core) GetRandomHosts(nHosts int) ([]string, error) {
-	return getRandomHosts(nHosts, d.Scale)
+	return getRandomHosts(nHosts, d.Scale, d.HostDistribution())
 }
 
 // cpuMetrics is the list of metric names for CPU
@@ -120,11 +132,22 @@ type HighCPUFiller interface {
 	HighCPUForHosts(query.Query, int)
 }
 
+// CPUMemCorrelationFiller is a type that can fill in a cpu-mem-correlation query
+type CPUMemCorrelationFiller interface {
+	CPUMemCorrelation(query.Query, int, time.Duration)
+}
+
 // GetDoubleGroupByLabel returns the Query human-readable label for DoubleGroupBy queries
 func GetDoubleGroupByLabel(dbName string, numMetrics int) string {
 	return fmt.Sprintf("%s mean of %d metrics, all hosts, random %s by 1h", dbName, numMetrics, DoubleGroupByDuration)
 }
 
+// GetDoubleGroupByRollupLabel returns the Query human-readable label for DoubleGroupBy
+// queries that read from the hourly rollup instead of the raw data
+func GetDoubleGroupByRollupLabel(dbName string, numMetrics int) string {
+	return GetDoubleGroupByLabel(dbName, numMetrics) + constants.RollupLabelSuffix
+}
+
 // GetHighCPULabel returns the Query human-readable label for HighCPU queries
 func GetHighCPULabel(dbName string, nHosts int) (string, error) {
 	label := dbName + " CPU over threshold, "
@@ -143,10 +166,15 @@ func GetMaxAllLabel(dbName string, nHosts int) string {
 	return fmt.Sprintf("%s max of all CPU metrics, random %4d hosts, random %s by 1h", dbName, nHosts, MaxAllDuration)
 }
 
+// GetCPUMemCorrelationLabel returns the Query human-readable label for CPUMemCorrelation queries
+func GetCPUMemCorrelationLabel(dbName string, nHosts int, duration time.Duration) string {
+	return fmt.Sprintf("%s cpu usage_user joined with mem used_percent, random %4d hosts, random %s by 1h", dbName, nHosts, duration)
+}
+
 // getRandomHosts returns a subset of numHosts hostnames of a permutation of hostnames,
 // numbered from 0 to totalHosts.
 // Ex.: host_12, host_7, host_25 for numHosts=3 and totalHosts=30 (3 out of 30)
-func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
+func getRandomHosts(numHosts int, totalHosts int, dist common.HostDistribution) ([]string, error) {
 	if numHosts < 1 {
 		return nil, fmt.Errorf("number of hosts cannot be < 1; got %d", numHosts)
 	}
@@ -154,7 +182,7 @@ func getRandomHosts(numHosts int, totalHosts int) ([]string, error) {
 		return nil, fmt.Errorf("number of hosts (%d) larger than total hosts. See --scale (%d)", numHosts, totalHosts)
 	}
 
-	randomNumbers, err := common.GetRandomSubsetPerm(numHosts, totalHosts)
+	randomNumbers, err := dist.Subset(numHosts, totalHosts)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,56 @@
+package devops
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// CPUMemCorrelation contains info for filling in a query.Query for queries
+// that join the cpu and mem measurements of the same hosts and hours
+type CPUMemCorrelation struct {
+	core     utils.QueryGenerator
+	hosts    int
+	duration time.Duration
+}
+
+// NewCPUMemCorrelation produces a new function that produces a new CPUMemCorrelation
+func NewCPUMemCorrelation(hosts int, duration time.Duration) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &CPUMemCorrelation{
+			core:     core,
+			hosts:    hosts,
+			duration: duration,
+		}
+	}
+}
+
+// NewCPUMemCorrelationFromParams produces a CPUMemCorrelation maker from query mix
+// params 'hosts' (defaults to CPUMemCorrelationHosts) and 'duration' (defaults
+// to CPUMemCorrelationDuration)
+func NewCPUMemCorrelationFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("hosts", "duration"); err != nil {
+		return nil, err
+	}
+	hosts, err := p.Int("hosts", CPUMemCorrelationHosts)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := p.Duration("duration", CPUMemCorrelationDuration)
+	if err != nil {
+		return nil, err
+	}
+	return NewCPUMemCorrelation(hosts, duration), nil
+}
+
+// Fill fills in the query.Query with query details
+func (d *CPUMemCorrelation) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(CPUMemCorrelationFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.CPUMemCorrelation(q, d.hosts, d.duration)
+	return q
+}
@@ -22,6 +22,19 @@ func NewGroupBy(numMetrics int) utils.QueryFillerMaker {
 	}
 }
 
+// NewGroupByFromParams produces a Groupby maker from query mix param 'metrics'
+// (defaults to all CPU metrics)
+func NewGroupByFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("metrics"); err != nil {
+		return nil, err
+	}
+	metrics, err := p.Int("metrics", GetCPUMetricsLen())
+	if err != nil {
+		return nil, err
+	}
+	return NewGroupBy(metrics), nil
+}
+
 // Fill fills in the query.Query with query details
 func (d *Groupby) Fill(q query.Query) query.Query {
 	fc, ok := d.core.(DoubleGroupbyFiller)
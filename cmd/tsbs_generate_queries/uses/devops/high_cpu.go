@@ -22,6 +22,19 @@ func NewHighCPU(hosts int) utils.QueryFillerMaker {
 	}
 }
 
+// NewHighCPUFromParams produces a HighCPU maker from query mix param 'hosts'
+// (defaults to 0, i.e. all hosts)
+func NewHighCPUFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("hosts"); err != nil {
+		return nil, err
+	}
+	hosts, err := p.Int("hosts", 0)
+	if err != nil {
+		return nil, err
+	}
+	return NewHighCPU(hosts), nil
+}
+
 // Fill fills in the query.Query with query details
 func (d *HighCPU) Fill(q query.Query) query.Query {
 	fc, ok := d.core.(HighCPUFiller)
@@ -26,6 +26,23 @@ func NewMaxAllCPU(hosts int, duration time.Duration) utils.QueryFillerMaker {
 	}
 }
 
+// NewMaxAllCPUFromParams produces a MaxAllCPU maker from query mix params
+// 'hosts' (defaults to 1) and 'duration' (defaults to MaxAllDuration)
+func NewMaxAllCPUFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("hosts", "duration"); err != nil {
+		return nil, err
+	}
+	hosts, err := p.Int("hosts", 1)
+	if err != nil {
+		return nil, err
+	}
+	duration, err := p.Duration("duration", MaxAllDuration)
+	if err != nil {
+		return nil, err
+	}
+	return NewMaxAllCPU(hosts, duration), nil
+}
+
 // Fill fills in the query.Query with query details
 func (d *MaxAllCPU) Fill(q query.Query) query.Query {
 	fc, ok := d.core.(MaxAllFiller)
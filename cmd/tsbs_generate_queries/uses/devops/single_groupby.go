@@ -28,6 +28,27 @@ func NewSingleGroupby(metrics, hosts, hours int) utils.QueryFillerMaker {
 	}
 }
 
+// NewSingleGroupbyFromParams produces a SingleGroupby maker from query mix
+// params 'metrics', 'hosts' and 'hours' (each defaults to 1)
+func NewSingleGroupbyFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("metrics", "hosts", "hours"); err != nil {
+		return nil, err
+	}
+	metrics, err := p.Int("metrics", 1)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := p.Int("hosts", 1)
+	if err != nil {
+		return nil, err
+	}
+	hours, err := p.Int("hours", 1)
+	if err != nil {
+		return nil, err
+	}
+	return NewSingleGroupby(metrics, hosts, hours), nil
+}
+
 // Fill fills in the query.Query with query details
 func (d *SingleGroupby) Fill(q query.Query) query.Query {
 	fc, ok := d.core.(SingleGroupbyFiller)
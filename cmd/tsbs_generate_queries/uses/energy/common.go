@@ -0,0 +1,75 @@
+package energy
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/energy"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// ReadingsTableName is the name of the table of the meter readings.
+	ReadingsTableName = "meter_readings"
+	// PowerQualityTableName is the name of the table of the power quality events.
+	PowerQualityTableName = "power_quality"
+
+	// ReadingInterval is the interval between two readings of a meter the
+	// queries expect the data to be generated with.
+	ReadingInterval = 15 * time.Minute
+
+	// RegionDemandDuration is the time range of the hourly demand per region query.
+	RegionDemandDuration = 24 * time.Hour
+	// PeakDemandDuration is the time range of the peak demand per feeder query.
+	PeakDemandDuration = 24 * time.Hour
+	// GapFillDuration is the time range of the gap-filled meter readings query.
+	GapFillDuration = 24 * time.Hour
+
+	// LabelRegionDemand is the label prefix for queries of the hourly demand per region
+	LabelRegionDemand = "region-demand"
+	// LabelPeakDemand is the label prefix for queries of the peak demand per feeder
+	LabelPeakDemand = "peak-demand"
+	// LabelGapFill is the label prefix for queries of the gap-filled readings of a meter
+	LabelGapFill = "gapfill-meter"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and number of meters.
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomRegion returns the name of a random region.
+func (c *Core) GetRandomRegion() string {
+	regions := energy.RegionCount(c.Scale)
+	if regions < 1 {
+		regions = 1
+	}
+	return energy.RegionName(rand.Intn(regions))
+}
+
+// GetRandomMeter returns the name of a random meter.
+func (c *Core) GetRandomMeter() string {
+	return energy.MeterName(rand.Intn(c.Scale))
+}
+
+// RegionDemandFiller is a type that can fill in an hourly demand per region query.
+type RegionDemandFiller interface {
+	RegionDemand(query.Query)
+}
+
+// PeakDemandFiller is a type that can fill in a peak demand per feeder query.
+type PeakDemandFiller interface {
+	PeakDemand(query.Query)
+}
+
+// GapFillFiller is a type that can fill in a gap-filled meter readings query.
+type GapFillFiller interface {
+	GapFillReadings(query.Query)
+}
@@ -0,0 +1,27 @@
+package energy
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// GapFillReadings returns QueryFiller for the energy gap-filled meter readings case
+type GapFillReadings struct {
+	core utils.QueryGenerator
+}
+
+// NewGapFillReadings returns a new GapFillReadings for given parameters
+func NewGapFillReadings(core utils.QueryGenerator) utils.QueryFiller {
+	return &GapFillReadings{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *GapFillReadings) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(GapFillFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.GapFillReadings(q)
+	return q
+}
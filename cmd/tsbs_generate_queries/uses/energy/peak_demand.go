@@ -0,0 +1,27 @@
+package energy
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// PeakDemand returns QueryFiller for the energy peak demand per feeder case
+type PeakDemand struct {
+	core utils.QueryGenerator
+}
+
+// NewPeakDemand returns a new PeakDemand for given parameters
+func NewPeakDemand(core utils.QueryGenerator) utils.QueryFiller {
+	return &PeakDemand{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *PeakDemand) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(PeakDemandFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.PeakDemand(q)
+	return q
+}
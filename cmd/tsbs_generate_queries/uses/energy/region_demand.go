@@ -0,0 +1,27 @@
+package energy
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// RegionDemand returns QueryFiller for the energy hourly demand per region case
+type RegionDemand struct {
+	core utils.QueryGenerator
+}
+
+// NewRegionDemand returns a new RegionDemand for given parameters
+func NewRegionDemand(core utils.QueryGenerator) utils.QueryFiller {
+	return &RegionDemand{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *RegionDemand) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(RegionDemandFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.RegionDemand(q)
+	return q
+}
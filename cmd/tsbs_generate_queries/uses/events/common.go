@@ -0,0 +1,97 @@
+package events
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/events"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// LogsTableName is the name of the table of the log lines.
+	LogsTableName = "logs"
+
+	// MessageSearchDuration is the time range of the message search query.
+	MessageSearchDuration = time.Hour
+	// MessageSearchLimit is the number of lines returned by the message search query.
+	MessageSearchLimit = 100
+	// SeverityCountsDuration is the time range of the count by severity query.
+	SeverityCountsDuration = time.Hour
+	// TopErrorsDuration is the time range of the top error messages query.
+	TopErrorsDuration = 24 * time.Hour
+	// TopErrorsLimit is the number of messages returned by the top error messages query.
+	TopErrorsLimit = 10
+
+	// LabelMessageSearch is the label prefix for queries searching the log messages
+	LabelMessageSearch = "message-search"
+	// LabelSeverityCounts is the label prefix for queries counting the lines by severity
+	LabelSeverityCounts = "severity-counts"
+	// LabelTopErrors is the label prefix for queries of the most frequent error messages
+	LabelTopErrors = "top-errors"
+)
+
+// searchTerms are the terms searched in the log messages, some in many
+// messages, some in the messages of a few errors.
+var searchTerms = []string{
+	"timed out",
+	"connection refused",
+	"deadlock",
+	"slow request",
+	"cache miss",
+	"/api/v1/orders/",
+	"invalid token",
+}
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and number of hosts.
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomService returns the name of a random service among the ones run
+// by the hosts.
+func (c *Core) GetRandomService() string {
+	services := events.Services()
+	if c.Scale < len(services) {
+		services = services[:c.Scale]
+	}
+	return services[rand.Intn(len(services))]
+}
+
+// GetRandomSearchTerm returns a random term to search in the log messages.
+func (c *Core) GetRandomSearchTerm() string {
+	return searchTerms[rand.Intn(len(searchTerms))]
+}
+
+// Severities returns the severities of the log lines, from the least to the
+// most severe.
+func Severities() []string {
+	return events.Severities
+}
+
+// ErrorMessages returns the messages of all the errors the services log.
+func ErrorMessages() []string {
+	return events.ErrorMessages()
+}
+
+// MessageSearchFiller is a type that can fill in a message search query.
+type MessageSearchFiller interface {
+	MessageSearch(query.Query)
+}
+
+// SeverityCountsFiller is a type that can fill in a count by severity query.
+type SeverityCountsFiller interface {
+	SeverityCounts(query.Query)
+}
+
+// TopErrorsFiller is a type that can fill in a top error messages query.
+type TopErrorsFiller interface {
+	TopErrors(query.Query)
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// MessageSearch returns QueryFiller for the events message search case
+type MessageSearch struct {
+	core utils.QueryGenerator
+}
+
+// NewMessageSearch returns a new MessageSearch for given parameters
+func NewMessageSearch(core utils.QueryGenerator) utils.QueryFiller {
+	return &MessageSearch{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *MessageSearch) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(MessageSearchFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.MessageSearch(q)
+	return q
+}
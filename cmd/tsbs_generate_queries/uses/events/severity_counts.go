@@ -0,0 +1,27 @@
+package events
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// SeverityCounts returns QueryFiller for the events count by severity case
+type SeverityCounts struct {
+	core utils.QueryGenerator
+}
+
+// NewSeverityCounts returns a new SeverityCounts for given parameters
+func NewSeverityCounts(core utils.QueryGenerator) utils.QueryFiller {
+	return &SeverityCounts{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *SeverityCounts) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(SeverityCountsFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.SeverityCounts(q)
+	return q
+}
@@ -0,0 +1,27 @@
+package events
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// TopErrors returns QueryFiller for the events top error messages case
+type TopErrors struct {
+	core utils.QueryGenerator
+}
+
+// NewTopErrors returns a new TopErrors for given parameters
+func NewTopErrors(core utils.QueryGenerator) utils.QueryFiller {
+	return &TopErrors{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *TopErrors) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(TopErrorsFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.TopErrors(q)
+	return q
+}
@@ -15,6 +15,8 @@ const (
 	LabelMACD                     = "macd"
 	LabelStochasticOscillator     = "stochastic-oscillator"
 	LabelTopPercentChange         = "top-percent-change"
+	LabelVWAP                     = "vwap"
+	LabelSpread                   = "spread"
 )
 
 type Core struct {
@@ -53,3 +55,11 @@ type StochasticOscillatorFiller interface {
 type TopPercentChangeFiller interface {
 	TopPercentChange(query.Query, time.Duration, time.Duration)
 }
+
+type VWAPFiller interface {
+	VWAP(query.Query, time.Duration, time.Duration)
+}
+
+type SpreadFiller interface {
+	Spread(query.Query, time.Duration, time.Duration)
+}
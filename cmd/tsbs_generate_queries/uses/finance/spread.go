@@ -0,0 +1,32 @@
+package finance
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type Spread struct {
+	core     utils.QueryGenerator
+	span     time.Duration
+	interval time.Duration
+}
+
+func NewSpread(span, interval time.Duration) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &Spread{
+			core, span, interval,
+		}
+	}
+}
+
+func (d *Spread) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(SpreadFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.Spread(q, d.span, d.interval)
+	return q
+}
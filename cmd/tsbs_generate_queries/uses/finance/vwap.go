@@ -0,0 +1,32 @@
+package finance
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type VWAP struct {
+	core     utils.QueryGenerator
+	span     time.Duration
+	interval time.Duration
+}
+
+func NewVWAP(span, interval time.Duration) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &VWAP{
+			core, span, interval,
+		}
+	}
+}
+
+func (d *VWAP) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(VWAPFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.VWAP(q, d.span, d.interval)
+	return q
+}
@@ -25,6 +25,9 @@ const (
 	LongDrivingSessionDuration = 4 * time.Hour
 	// DailyDrivingDuration is time duration of one day of driving.
 	DailyDrivingDuration = 24 * time.Hour
+	// ReadingsJoinDiagnosticsDuration is the time range of the readings
+	// joined with diagnostics query.
+	ReadingsJoinDiagnosticsDuration = 12 * time.Hour
 
 	// LabelLastLoc is the label for the last location query.
 	LabelLastLoc = "last-loc"
@@ -52,6 +55,8 @@ const (
 	LabelDailyActivity = "daily-activity"
 	// LabelBreakdownFrequency is the label for the breakdown frequency query.
 	LabelBreakdownFrequency = "breakdown-frequency"
+	// LabelReadingsJoinDiagnostics is the label for the readings joined with diagnostics query.
+	LabelReadingsJoinDiagnostics = "iot-readings-join-diagnostics"
 )
 
 // Core is the common component of all generators for all systems.
@@ -73,13 +78,13 @@ func NewCore(start, end time.Time, scale int) (*Core, error) {
 
 // GetRandomTrucks returns a random set of nTrucks from a given Core
 func (c *Core) GetRandomTrucks(nTrucks int) ([]string, error) {
-	return getRandomTrucks(nTrucks, c.Scale)
+	return getRandomTrucks(nTrucks, c.Scale, c.HostDistribution())
 }
 
 // getRandomTruckNames returns a subset of numTrucks names of a permutation of truck names,
 // numbered from 0 to totalTrucks.
 // Ex.: truck_12, truck_7, truck_25 for numTrucks=3 and totalTrucks=30 (3 out of 30)
-func getRandomTrucks(numTrucks int, totalTrucks int) ([]string, error) {
+func getRandomTrucks(numTrucks int, totalTrucks int, dist common.HostDistribution) ([]string, error) {
 	if numTrucks < 1 {
 		return nil, fmt.Errorf("number of trucks cannot be < 1; got %d", numTrucks)
 	}
@@ -87,7 +92,7 @@ func getRandomTrucks(numTrucks int, totalTrucks int) ([]string, error) {
 		return nil, fmt.Errorf("number of trucks (%d) larger than total trucks. See --scale (%d)", numTrucks, totalTrucks)
 	}
 
-	randomNumbers, err := common.GetRandomSubsetPerm(numTrucks, totalTrucks)
+	randomNumbers, err := dist.Subset(numTrucks, totalTrucks)
 	if err != nil {
 		return nil, err
 	}
@@ -164,3 +169,8 @@ type DailyTruckActivityFiller interface {
 type TruckBreakdownFrequencyFiller interface {
 	TruckBreakdownFrequency(query.Query)
 }
+
+// ReadingsJoinDiagnosticsFiller is a type that can fill in the readings joined with diagnostics query.
+type ReadingsJoinDiagnosticsFiller interface {
+	ReadingsJoinDiagnostics(query.Query)
+}
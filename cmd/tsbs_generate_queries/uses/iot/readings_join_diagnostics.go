@@ -0,0 +1,29 @@
+package iot
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// ReadingsJoinDiagnostics contains info for filling in readings joined with diagnostics queries.
+type ReadingsJoinDiagnostics struct {
+	core utils.QueryGenerator
+}
+
+// NewReadingsJoinDiagnostics creates a new readings joined with diagnostics query filler.
+func NewReadingsJoinDiagnostics(core utils.QueryGenerator) utils.QueryFiller {
+	return &ReadingsJoinDiagnostics{
+		core: core,
+	}
+}
+
+// Fill fills in the query.Query with query details.
+func (i *ReadingsJoinDiagnostics) Fill(q query.Query) query.Query {
+	fc, ok := i.core.(ReadingsJoinDiagnosticsFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(i.core)
+	}
+	fc.ReadingsJoinDiagnostics(q)
+	return q
+}
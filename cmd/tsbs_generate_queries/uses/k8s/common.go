@@ -0,0 +1,113 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/k8s"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// CPUTableName is the name of the table of the container CPU usage.
+	CPUTableName = "container_cpu"
+	// MemoryTableName is the name of the table of the container memory usage.
+	MemoryTableName = "container_memory"
+	// NetworkTableName is the name of the table of the container network traffic.
+	NetworkTableName = "container_network"
+	// StateTableName is the name of the table of the container states.
+	StateTableName = "kube_pod_container"
+
+	// NamespaceCPUDuration is the time range of the CPU per namespace query.
+	NamespaceCPUDuration = time.Hour
+	// NodeNetworkDuration is the time range of the network per node query.
+	NodeNetworkDuration = time.Hour
+	// TopPodsMemoryDuration is the time range of the top pods by memory query.
+	TopPodsMemoryDuration = time.Hour
+	// TopPodsMemoryLimit is the number of pods of the top pods by memory query.
+	TopPodsMemoryLimit = 10
+	// ContainerRestartsDuration is the time range of the container restarts query.
+	ContainerRestartsDuration = 12 * time.Hour
+	// PodChurnDuration is the time range of the pod churn query.
+	PodChurnDuration = 12 * time.Hour
+	// LastPointDuration is how far back the last point per container query
+	// looks for the containers still running at its (random) end.
+	LastPointDuration = 5 * time.Minute
+
+	// LabelNamespaceCPU is the label prefix for queries of the CPU used per namespace
+	LabelNamespaceCPU = "namespace-cpu"
+	// LabelNodeNetwork is the label prefix for queries of the network traffic per node
+	LabelNodeNetwork = "node-network"
+	// LabelTopPodsMemory is the label prefix for queries of the pods using the most memory
+	LabelTopPodsMemory = "top-pods-memory"
+	// LabelContainerRestarts is the label prefix for queries of the container restarts per deployment
+	LabelContainerRestarts = "container-restarts"
+	// LabelPodChurn is the label prefix for queries of the number of pods over time
+	LabelPodChurn = "pod-churn"
+	// LabelLastPoint is the label for the last point per container of a deployment query
+	LabelLastPoint = "lastpoint-deployment"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and number of nodes.
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomCluster returns the name of a random cluster.
+func (c *Core) GetRandomCluster() string {
+	clusters := k8s.ClusterCount(c.Scale)
+	if clusters < 1 {
+		clusters = 1
+	}
+	return k8s.ClusterName(rand.Intn(clusters))
+}
+
+// GetRandomNamespace returns a random namespace.
+func (c *Core) GetRandomNamespace() string {
+	namespaces := k8s.Namespaces()
+	return namespaces[rand.Intn(len(namespaces))]
+}
+
+// GetRandomDeployment returns the namespace and the name of a random
+// workload. Daemon sets are included.
+func (c *Core) GetRandomDeployment() (string, string) {
+	w := k8s.Workloads[rand.Intn(len(k8s.Workloads))]
+	return w.Namespace, w.Name
+}
+
+// NamespaceCPUFiller is a type that can fill in a CPU per namespace query.
+type NamespaceCPUFiller interface {
+	NamespaceCPU(query.Query)
+}
+
+// NodeNetworkFiller is a type that can fill in a network per node query.
+type NodeNetworkFiller interface {
+	NodeNetwork(query.Query)
+}
+
+// TopPodsMemoryFiller is a type that can fill in a top pods by memory query.
+type TopPodsMemoryFiller interface {
+	TopPodsMemory(query.Query, int)
+}
+
+// ContainerRestartsFiller is a type that can fill in a container restarts query.
+type ContainerRestartsFiller interface {
+	ContainerRestarts(query.Query)
+}
+
+// PodChurnFiller is a type that can fill in a pod churn query.
+type PodChurnFiller interface {
+	PodChurn(query.Query)
+}
+
+// LastPointFiller is a type that can fill in a last point per container query.
+type LastPointFiller interface {
+	LastPointPerContainer(query.Query)
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// ContainerRestarts returns QueryFiller for the k8s container restarts case
+type ContainerRestarts struct {
+	core utils.QueryGenerator
+}
+
+// NewContainerRestarts returns a new ContainerRestarts for given parameters
+func NewContainerRestarts(core utils.QueryGenerator) utils.QueryFiller {
+	return &ContainerRestarts{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *ContainerRestarts) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(ContainerRestartsFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.ContainerRestarts(q)
+	return q
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// LastPointPerContainer returns QueryFiller for the k8s lastpoint case
+type LastPointPerContainer struct {
+	core utils.QueryGenerator
+}
+
+// NewLastPointPerContainer returns a new LastPointPerContainer for given parameters
+func NewLastPointPerContainer(core utils.QueryGenerator) utils.QueryFiller {
+	return &LastPointPerContainer{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *LastPointPerContainer) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(LastPointFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.LastPointPerContainer(q)
+	return q
+}
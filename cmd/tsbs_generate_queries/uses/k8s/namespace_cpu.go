@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// NamespaceCPU returns QueryFiller for the k8s CPU per namespace case
+type NamespaceCPU struct {
+	core utils.QueryGenerator
+}
+
+// NewNamespaceCPU returns a new NamespaceCPU for given parameters
+func NewNamespaceCPU(core utils.QueryGenerator) utils.QueryFiller {
+	return &NamespaceCPU{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *NamespaceCPU) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(NamespaceCPUFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.NamespaceCPU(q)
+	return q
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// NodeNetwork returns QueryFiller for the k8s network per node case
+type NodeNetwork struct {
+	core utils.QueryGenerator
+}
+
+// NewNodeNetwork returns a new NodeNetwork for given parameters
+func NewNodeNetwork(core utils.QueryGenerator) utils.QueryFiller {
+	return &NodeNetwork{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *NodeNetwork) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(NodeNetworkFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.NodeNetwork(q)
+	return q
+}
@@ -0,0 +1,27 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// PodChurn returns QueryFiller for the k8s pod churn case
+type PodChurn struct {
+	core utils.QueryGenerator
+}
+
+// NewPodChurn returns a new PodChurn for given parameters
+func NewPodChurn(core utils.QueryGenerator) utils.QueryFiller {
+	return &PodChurn{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *PodChurn) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(PodChurnFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.PodChurn(q)
+	return q
+}
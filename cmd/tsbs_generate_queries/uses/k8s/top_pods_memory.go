@@ -0,0 +1,33 @@
+package k8s
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// TopPodsMemory produces a QueryFiller for the k8s top pods by memory cases
+type TopPodsMemory struct {
+	core  utils.QueryGenerator
+	limit int
+}
+
+// NewTopPodsMemory produces a new function that produces a new TopPodsMemory
+func NewTopPodsMemory(limit int) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &TopPodsMemory{
+			core:  core,
+			limit: limit,
+		}
+	}
+}
+
+// Fill fills in the query.Query with query details
+func (d *TopPodsMemory) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(TopPodsMemoryFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.TopPodsMemory(q, d.limit)
+	return q
+}
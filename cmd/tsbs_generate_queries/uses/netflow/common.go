@@ -0,0 +1,70 @@
+package netflow
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/netflow"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const (
+	// FlowsTableName is the name of the table of the flow records.
+	FlowsTableName = "flows"
+
+	// TopTalkersDuration is the time range of the top talkers query.
+	TopTalkersDuration = time.Hour
+	// TopTalkersLimit is the number of sources returned by the top talkers query.
+	TopTalkersLimit = 10
+	// SubnetTrafficDuration is the time range of the subnet traffic query.
+	SubnetTrafficDuration = time.Hour
+	// PortScansDuration is the time range of the port scan detection query.
+	PortScansDuration = time.Hour
+	// PortScanThreshold is the number of ports of a destination a source has
+	// to connect to for the port scan detection query to report it.
+	PortScanThreshold = 100
+
+	// LabelTopTalkers is the label prefix for queries of the sources sending the most bytes
+	LabelTopTalkers = "top-talkers"
+	// LabelSubnetTraffic is the label prefix for queries of the traffic of a subnet
+	LabelSubnetTraffic = "subnet-traffic"
+	// LabelPortScans is the label prefix for queries detecting port scans
+	LabelPortScans = "port-scans"
+)
+
+// Core is the common component of all generators for all systems.
+type Core struct {
+	*common.Core
+}
+
+// NewCore returns a new Core for the given time range and number of hosts.
+func NewCore(start, end time.Time, scale int) (*Core, error) {
+	c, err := common.NewCore(start, end, scale)
+	return &Core{Core: c}, err
+}
+
+// GetRandomSubnetPrefix returns the first three bytes of the addresses of a
+// random /24 subnet, e.g. "10.0.3.".
+func (c *Core) GetRandomSubnetPrefix() string {
+	subnets := netflow.SubnetCount(c.Scale)
+	if subnets < 1 {
+		subnets = 1
+	}
+	return netflow.SubnetPrefix(rand.Intn(subnets))
+}
+
+// TopTalkersFiller is a type that can fill in a top talkers query.
+type TopTalkersFiller interface {
+	TopTalkers(query.Query)
+}
+
+// SubnetTrafficFiller is a type that can fill in a subnet traffic query.
+type SubnetTrafficFiller interface {
+	SubnetTraffic(query.Query)
+}
+
+// PortScansFiller is a type that can fill in a port scan detection query.
+type PortScansFiller interface {
+	PortScans(query.Query)
+}
@@ -0,0 +1,27 @@
+package netflow
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// PortScans returns QueryFiller for the netflow port scan detection case
+type PortScans struct {
+	core utils.QueryGenerator
+}
+
+// NewPortScans returns a new PortScans for given parameters
+func NewPortScans(core utils.QueryGenerator) utils.QueryFiller {
+	return &PortScans{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *PortScans) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(PortScansFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.PortScans(q)
+	return q
+}
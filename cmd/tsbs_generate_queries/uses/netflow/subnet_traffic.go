@@ -0,0 +1,27 @@
+package netflow
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// SubnetTraffic returns QueryFiller for the netflow subnet traffic case
+type SubnetTraffic struct {
+	core utils.QueryGenerator
+}
+
+// NewSubnetTraffic returns a new SubnetTraffic for given parameters
+func NewSubnetTraffic(core utils.QueryGenerator) utils.QueryFiller {
+	return &SubnetTraffic{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *SubnetTraffic) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(SubnetTrafficFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.SubnetTraffic(q)
+	return q
+}
@@ -0,0 +1,27 @@
+package netflow
+
+import (
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// TopTalkers returns QueryFiller for the netflow top talkers case
+type TopTalkers struct {
+	core utils.QueryGenerator
+}
+
+// NewTopTalkers returns a new TopTalkers for given parameters
+func NewTopTalkers(core utils.QueryGenerator) utils.QueryFiller {
+	return &TopTalkers{core}
+}
+
+// Fill fills in the query.Query with query details
+func (d *TopTalkers) Fill(q query.Query) query.Query {
+	fc, ok := d.core.(TopTalkersFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(d.core)
+	}
+	fc.TopTalkers(q)
+	return q
+}
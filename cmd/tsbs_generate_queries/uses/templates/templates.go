@@ -0,0 +1,327 @@
+// Package templates implements queries described by a template file instead
+// of Go code: one text/template body per format, filled in with random hosts,
+// a random time window, metrics and a bucket size drawn from the use case Core.
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	internalutils "github.com/timescale/tsbs/internal/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// LabelTemplate is the query type of queries read from a template file
+const LabelTemplate = "template"
+
+const (
+	errNoFileParam          = "query param 'file' is required"
+	errCannotReadFmt        = "cannot read query template '%s': %v"
+	errCannotParseFmt       = "cannot parse query template '%s': %v"
+	errNoQueries            = "query template has no queries"
+	errBadTemplateFmt       = "bad %s query template: %v"
+	errNegativeFmt          = "'%s' cannot be negative"
+	errNoFormatFmt          = "query template '%s' has no query for format '%s'"
+	errCannotRenderFmt      = "cannot render %s query template: %v"
+	errNoHostsForCoreFmt    = "use case of %T has no hosts or trucks to pick"
+	errNoIntervalForCoreFmt = "use case of %T has no time interval"
+)
+
+// Spec is the layout of a query template file, e.g.:
+//
+//	label: TimescaleDB top user CPU, 8 hosts, random 2h
+//	table: cpu
+//	hosts: 8
+//	metrics: 1
+//	window: 2h
+//	bucket: 5m
+//	queries:
+//	  timescaledb: |
+//	    SELECT hostname, max({{index .Metrics 0}}) FROM cpu
+//	    WHERE hostname IN ({{sqlList .Hosts}})
+//	    AND time >= '{{rfc3339 .Start}}' AND time < '{{rfc3339 .End}}'
+//	    GROUP BY hostname ORDER BY 2 DESC
+type Spec struct {
+	// Label is the human readable label of the queries, defaults to the
+	// name of the file
+	Label string `yaml:"label"`
+	// Table is the table (or collection) the queries are run against
+	Table string `yaml:"table"`
+	// Hosts is the number of random hosts (trucks for iot), 0 for none
+	Hosts int `yaml:"hosts"`
+	// Metrics is the number of CPU metrics, 0 for none
+	Metrics int `yaml:"metrics"`
+	// Window is the size of the random time window, 0 for the whole dataset
+	Window time.Duration `yaml:"window"`
+	// Bucket is the size of the time buckets to group by
+	Bucket time.Duration `yaml:"bucket"`
+	// Queries maps a format to the text/template body of its query
+	Queries map[string]string `yaml:"queries"`
+}
+
+// Template is a parsed query template file.
+type Template struct {
+	Spec
+	bodies map[string]*template.Template
+}
+
+// funcs are the functions available in the query template bodies
+var funcs = template.FuncMap{
+	"join": strings.Join,
+	"sqlList": func(items []string) string {
+		return quoteList(items, "'", ",")
+	},
+	"jsonList": func(items []string) string {
+		return "[" + quoteList(items, `"`, ",") + "]"
+	},
+	"regexAlt": func(items []string) string {
+		return strings.Join(items, "|")
+	},
+	"rfc3339": func(t time.Time) string {
+		return t.UTC().Format(time.RFC3339)
+	},
+	"unix": func(t time.Time) int64 {
+		return t.Unix()
+	},
+	"unixMillis": func(t time.Time) int64 {
+		return t.UnixNano() / int64(time.Millisecond)
+	},
+	"unixNano": func(t time.Time) int64 {
+		return t.UnixNano()
+	},
+	"seconds": func(d time.Duration) int64 {
+		return int64(d.Seconds())
+	},
+}
+
+func quoteList(items []string, quote, sep string) string {
+	quoted := make([]string, len(items))
+	for i, s := range items {
+		quoted[i] = quote + s + quote
+	}
+	return strings.Join(quoted, sep)
+}
+
+// Load reads and parses the query template file at path.
+func Load(path string) (*Template, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotReadFmt, path, err)
+	}
+	t, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotParseFmt, path, err)
+	}
+	if t.Label == "" {
+		t.Label = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	return t, nil
+}
+
+// Parse parses the contents of a query template file.
+func Parse(contents []byte) (*Template, error) {
+	t := &Template{}
+	if err := yaml.UnmarshalStrict(contents, &t.Spec); err != nil {
+		return nil, err
+	}
+	if len(t.Queries) == 0 {
+		return nil, fmt.Errorf(errNoQueries)
+	}
+	if t.Hosts < 0 {
+		return nil, fmt.Errorf(errNegativeFmt, "hosts")
+	}
+	if t.Metrics < 0 {
+		return nil, fmt.Errorf(errNegativeFmt, "metrics")
+	}
+	if t.Window < 0 || t.Bucket < 0 {
+		return nil, fmt.Errorf(errNegativeFmt, "window and bucket")
+	}
+
+	t.bodies = make(map[string]*template.Template, len(t.Queries))
+	for format, body := range t.Queries {
+		tmpl, err := template.New(format).Funcs(funcs).Option("missingkey=error").Parse(body)
+		if err != nil {
+			return nil, fmt.Errorf(errBadTemplateFmt, format, err)
+		}
+		t.bodies[format] = tmpl
+	}
+	return t, nil
+}
+
+// Data are the values a query template body is executed with.
+type Data struct {
+	Hosts   []string
+	Metrics []string
+	Start   time.Time
+	End     time.Time
+	Bucket  time.Duration
+	Table   string
+}
+
+// Instance is one query of a Template, with its random values drawn.
+type Instance struct {
+	Label       string
+	Description string
+	Table       string
+	Interval    *internalutils.TimeInterval
+	Bucket      time.Duration
+
+	t    *Template
+	data *Data
+}
+
+// Render executes the query template body of format.
+func (i *Instance) Render(format string) (string, error) {
+	tmpl, ok := i.t.bodies[format]
+	if !ok {
+		return "", fmt.Errorf(errNoFormatFmt, i.t.Label, format)
+	}
+	buf := &bytes.Buffer{}
+	if err := tmpl.Execute(buf, i.data); err != nil {
+		return "", fmt.Errorf(errCannotRenderFmt, format, err)
+	}
+	return buf.String(), nil
+}
+
+// MustRender is the form of Render that cannot error; if it does error,
+// it will panic.
+func (i *Instance) MustRender(format string) string {
+	s, err := i.Render(format)
+	if err != nil {
+		panic(err.Error())
+	}
+	return s
+}
+
+// TemplateFiller is a type that can fill in a query from a template Instance
+type TemplateFiller interface {
+	FillInTemplate(query.Query, *Instance)
+}
+
+type hostPicker interface {
+	GetRandomHosts(int) ([]string, error)
+}
+
+type truckPicker interface {
+	GetRandomTrucks(int) ([]string, error)
+}
+
+type intervalGetter interface {
+	GetInterval() *internalutils.TimeInterval
+}
+
+// NewInstance draws the random values of a query of t using the helpers of
+// core: its time interval and hosts (or trucks).
+func (t *Template) NewInstance(core utils.QueryGenerator) (*Instance, error) {
+	ig, ok := core.(intervalGetter)
+	if !ok {
+		return nil, fmt.Errorf(errNoIntervalForCoreFmt, core)
+	}
+	interval := ig.GetInterval()
+	if t.Window > 0 {
+		var err error
+		interval, err = interval.RandWindow(t.Window)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var hosts []string
+	if t.Hosts > 0 {
+		var err error
+		switch c := core.(type) {
+		case hostPicker:
+			hosts, err = c.GetRandomHosts(t.Hosts)
+		case truckPicker:
+			hosts, err = c.GetRandomTrucks(t.Hosts)
+		default:
+			err = fmt.Errorf(errNoHostsForCoreFmt, core)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var metrics []string
+	if t.Metrics > 0 {
+		var err error
+		metrics, err = devops.GetCPUMetricsSlice(t.Metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Instance{
+		Label:       t.Label,
+		Description: fmt.Sprintf("%s: %s", t.Label, interval.StartString()),
+		Table:       t.Table,
+		Interval:    interval,
+		Bucket:      t.Bucket,
+		t:           t,
+		data: &Data{
+			Hosts:   hosts,
+			Metrics: metrics,
+			Start:   interval.Start(),
+			End:     interval.End(),
+			Bucket:  t.Bucket,
+			Table:   t.Table,
+		},
+	}, nil
+}
+
+// Query contains info for filling in a query.Query from a template file
+type Query struct {
+	core utils.QueryGenerator
+	t    *Template
+}
+
+// NewQuery produces a new function that produces a new Query for t
+func NewQuery(t *Template) utils.QueryFillerMaker {
+	return func(core utils.QueryGenerator) utils.QueryFiller {
+		return &Query{
+			core: core,
+			t:    t,
+		}
+	}
+}
+
+// NewQueryFromParams produces a Query maker from query mix param 'file',
+// the path of the query template file
+func NewQueryFromParams(p utils.QueryParams) (utils.QueryFillerMaker, error) {
+	if err := p.CheckKeys("file"); err != nil {
+		return nil, err
+	}
+	path := p["file"]
+	if path == "" {
+		return nil, fmt.Errorf(errNoFileParam)
+	}
+	t, err := Load(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewQuery(t), nil
+}
+
+// Fill fills in the query.Query with query details
+func (q *Query) Fill(qi query.Query) query.Query {
+	fc, ok := q.core.(TemplateFiller)
+	if !ok {
+		common.PanicUnimplementedQuery(q.core)
+	}
+	instance, err := q.t.NewInstance(q.core)
+	if err != nil {
+		panic(err.Error())
+	}
+	fc.FillInTemplate(qi, instance)
+	return qi
+}
@@ -0,0 +1,196 @@
+package templates
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/iot"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const testTemplate = `
+label: top cpu
+table: cpu
+hosts: 2
+metrics: 2
+window: 1h
+bucket: 5m
+queries:
+  timescaledb: |-
+    SELECT {{join .Metrics ", "}} FROM {{.Table}} WHERE hostname IN ({{sqlList .Hosts}}) AND time >= '{{rfc3339 .Start}}' AND time < '{{rfc3339 .End}}' GROUP BY {{seconds .Bucket}}
+  victoriametrics: |-
+    max(cpu_usage_user{hostname=~'{{regexAlt .Hosts}}'}) [{{unix .Start}},{{unix .End}}] {{jsonList .Hosts}}
+`
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		desc   string
+		in     string
+		errMsg string
+	}{
+		{
+			desc: "valid",
+			in:   testTemplate,
+		},
+		{
+			desc:   "no queries",
+			in:     "label: foo\n",
+			errMsg: errNoQueries,
+		},
+		{
+			desc:   "unknown field",
+			in:     "lable: foo\nqueries:\n  influx: foo\n",
+			errMsg: "field lable not found",
+		},
+		{
+			desc:   "negative hosts",
+			in:     "hosts: -1\nqueries:\n  influx: foo\n",
+			errMsg: "'hosts' cannot be negative",
+		},
+		{
+			desc:   "bad template",
+			in:     "queries:\n  influx: '{{.Hosts'\n",
+			errMsg: "bad influx query template",
+		},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.in))
+		if c.errMsg == "" {
+			if err != nil {
+				t.Errorf("%s: unexpected error: %v", c.desc, err)
+			}
+		} else if err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !strings.Contains(err.Error(), c.errMsg) {
+			t.Errorf("%s: incorrect error: got %v want %s", c.desc, err, c.errMsg)
+		}
+	}
+}
+
+func TestNewQueryFromParams(t *testing.T) {
+	if _, err := NewQueryFromParams(utils.QueryParams{}); err == nil || err.Error() != errNoFileParam {
+		t.Errorf("incorrect error for missing file: %v", err)
+	}
+	if _, err := NewQueryFromParams(utils.QueryParams{"file": "x", "foo": "1"}); err == nil {
+		t.Errorf("unexpected lack of error for unknown param")
+	}
+	if _, err := NewQueryFromParams(utils.QueryParams{"file": "/does/not/exist.yaml"}); err == nil {
+		t.Errorf("unexpected lack of error for missing file")
+	}
+}
+
+func TestInstanceRender(t *testing.T) {
+	tmpl, err := Parse([]byte(testTemplate))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := time.Unix(0, 0)
+	e := s.Add(4 * time.Hour)
+	core, err := devops.NewCore(s, e, 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	f := &testFiller{Core: core}
+
+	rand.Seed(123)
+	i, err := tmpl.NewInstance(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := i.Interval.Duration(); got != time.Hour {
+		t.Errorf("incorrect window: got %v want %v", got, time.Hour)
+	}
+	got := i.MustRender("timescaledb")
+	want := "SELECT usage_user, usage_system FROM cpu WHERE hostname IN ('host_9','host_3') AND time >= '" +
+		i.Interval.Start().Format(time.RFC3339) + "' AND time < '" + i.Interval.End().Format(time.RFC3339) + "' GROUP BY 300"
+	if got != want {
+		t.Errorf("incorrect render:\ngot\n%s\nwant\n%s", got, want)
+	}
+	got = i.MustRender("victoriametrics")
+	if !strings.HasPrefix(got, "max(cpu_usage_user{hostname=~'host_9|host_3'}) [") || !strings.HasSuffix(got, `["host_9","host_3"]`) {
+		t.Errorf("incorrect render: %s", got)
+	}
+
+	// Same seed, same query
+	rand.Seed(123)
+	again, err := tmpl.NewInstance(f)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if again.MustRender("timescaledb") != i.MustRender("timescaledb") {
+		t.Errorf("same seed rendered different queries")
+	}
+
+	if _, err := i.Render("influx"); err == nil {
+		t.Errorf("unexpected lack of error for format without query")
+	}
+}
+
+func TestInstanceTrucks(t *testing.T) {
+	tmpl, err := Parse([]byte("hosts: 3\nqueries:\n  influx: '{{regexAlt .Hosts}}'\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := time.Unix(0, 0)
+	core, err := iot.NewCore(s, s.Add(time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	i, err := tmpl.NewInstance(&testIoT{Core: core})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := i.MustRender("influx"); strings.Count(got, "truck_") != 3 {
+		t.Errorf("incorrect trucks: %s", got)
+	}
+	if i.Interval.Duration() != time.Hour {
+		t.Errorf("expected the whole interval without a window, got %v", i.Interval.Duration())
+	}
+}
+
+type testFiller struct {
+	*devops.Core
+	instance *Instance
+}
+
+func (f *testFiller) GenerateEmptyQuery() query.Query {
+	return query.NewHTTP()
+}
+
+func (f *testFiller) FillInTemplate(q query.Query, i *Instance) {
+	f.instance = i
+}
+
+type testIoT struct {
+	*iot.Core
+}
+
+func (f *testIoT) GenerateEmptyQuery() query.Query {
+	return query.NewHTTP()
+}
+
+func TestQueryFill(t *testing.T) {
+	tmpl, err := Parse([]byte(testTemplate))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := time.Unix(0, 0)
+	core, err := devops.NewCore(s, s.Add(4*time.Hour), 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	f := &testFiller{Core: core}
+	q := f.GenerateEmptyQuery()
+	NewQuery(tmpl)(f).Fill(q)
+	if f.instance == nil {
+		t.Fatalf("FillInTemplate was not called")
+	}
+	if f.instance.Label != "top cpu" || f.instance.Table != "cpu" || f.instance.Bucket != 5*time.Minute {
+		t.Errorf("incorrect instance: %+v", f.instance)
+	}
+}
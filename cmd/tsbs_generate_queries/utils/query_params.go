@@ -0,0 +1,64 @@
+package utils
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// QueryParams are the parameters of a query type in a query mix,
+// e.g. the number of hosts or metrics a query touches.
+type QueryParams map[string]string
+
+// ParameterizedQueryFillerMaker is a function that takes QueryParams and
+// returns the QueryFillerMaker for the query type with those parameters
+type ParameterizedQueryFillerMaker func(QueryParams) (QueryFillerMaker, error)
+
+// CheckKeys returns an error if the params contain a key not in allowed.
+func (p QueryParams) CheckKeys(allowed ...string) error {
+	var unknown []string
+	for k := range p {
+		found := false
+		for _, a := range allowed {
+			if k == a {
+				found = true
+				break
+			}
+		}
+		if !found {
+			unknown = append(unknown, k)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown query params %v, allowed: %v", unknown, allowed)
+	}
+	return nil
+}
+
+// Int returns the value of key as an int, or def if it is not set.
+func (p QueryParams) Int(key string, def int) (int, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("query param '%s': %v", key, err)
+	}
+	return i, nil
+}
+
+// Duration returns the value of key as a time.Duration, or def if it is not set.
+func (p QueryParams) Duration(key string, def time.Duration) (time.Duration, error) {
+	v, ok := p[key]
+	if !ok {
+		return def, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("query param '%s': %v", key, err)
+	}
+	return d, nil
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	loadconfig "github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/data/source"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
@@ -55,19 +56,19 @@ func config(cmd *cobra.Command, _ []string) {
 	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
 }
 
-func getEmptyConfigWithoutDbSpecifics(target, dataSource string) *LoadConfig {
-	loadConfig := &LoadConfig{
-		Loader: &LoaderConfig{
+func getEmptyConfigWithoutDbSpecifics(target, dataSource string) *loadconfig.LoadConfig {
+	loadConfig := &loadconfig.LoadConfig{
+		Loader: &loadconfig.LoaderConfig{
 			Target: target,
 		},
 	}
 	switch dataSource {
 	case source.FileDataSourceType:
-		loadConfig.DataSource = &DataSourceConfig{
+		loadConfig.DataSource = &loadconfig.DataSourceConfig{
 			Type: source.FileDataSourceType,
 		}
 	case source.SimulatorDataSourceType:
-		loadConfig.DataSource = &DataSourceConfig{
+		loadConfig.DataSource = &loadconfig.DataSourceConfig{
 			Type: source.SimulatorDataSourceType,
 		}
 	}
@@ -82,7 +83,7 @@ func readFlag(cmd *cobra.Command, flag string) string {
 	return val
 }
 
-func setExampleConfigInViper(confWithoutDBSpecifics *LoadConfig, t targets.ImplementedTarget) *viper.Viper {
+func setExampleConfigInViper(confWithoutDBSpecifics *loadconfig.LoadConfig, t targets.ImplementedTarget) *viper.Viper {
 	v := viper.New()
 	v.SetConfigType("yaml")
 
@@ -99,7 +100,7 @@ func setExampleConfigInViper(confWithoutDBSpecifics *LoadConfig, t targets.Imple
 	// get loader.runner and data-source flags
 	// and remove either data-source.file or data-source.simulator depending on selected
 	// data source type
-	loadCmdFlagSet := cleanDataSourceFlags(confWithoutDBSpecifics.DataSource.Type, loadCmdFlags())
+	loadCmdFlagSet := loadconfig.CleanDataSourceFlags(confWithoutDBSpecifics.DataSource.Type, loadCmdFlags())
 
 	// bind loader.runner and data-source flags
 	if err := v.BindPFlags(loadCmdFlagSet); err != nil {
@@ -116,22 +117,3 @@ func setExampleConfigInViper(confWithoutDBSpecifics *LoadConfig, t targets.Imple
 
 	return v
 }
-
-func cleanDataSourceFlags(dataSource string, fs *pflag.FlagSet) *pflag.FlagSet {
-	var unwantedPrefix string
-	switch dataSource {
-	case source.FileDataSourceType:
-		unwantedPrefix = "data-source.simulator"
-	case source.SimulatorDataSourceType:
-		unwantedPrefix = "data-source.file"
-	default:
-		panic("unsupported data source type: " + dataSource)
-	}
-	reducedFs := pflag.NewFlagSet("", pflag.ContinueOnError)
-	fs.VisitAll(func(f *pflag.Flag) {
-		if !strings.HasPrefix(f.Name, unwantedPrefix) {
-			reducedFs.AddFlag(f)
-		}
-	})
-	return reducedFs
-}
@@ -5,6 +5,7 @@ import (
 	"github.com/blagojts/viper"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	loadconfig "github.com/timescale/tsbs/load/config"
 	"github.com/timescale/tsbs/pkg/targets"
 	"github.com/timescale/tsbs/pkg/targets/constants"
 	"github.com/timescale/tsbs/pkg/targets/initializers"
@@ -35,8 +36,8 @@ func initLoadCMD() (*cobra.Command, error) {
 
 func loadCmdFlags() *pflag.FlagSet {
 	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
-	addDataSourceFlags(fs)
-	addLoaderRunnerFlags(fs)
+	loadconfig.AddDataSourceFlags(fs)
+	loadconfig.AddLoaderRunnerFlags(fs)
 	return fs
 }
 
@@ -66,7 +67,7 @@ func createRunLoad(target targets.ImplementedTarget) cmdRunner {
 		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
 			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
 		}
-		bench, runner, err := parseConfig(target, viper.GetViper())
+		bench, runner, err := loadconfig.ParseConfig(target, viper.GetViper())
 		if err != nil {
 			panic(err)
 		}
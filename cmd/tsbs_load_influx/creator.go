@@ -8,6 +8,8 @@ import (
 	"net/http"
 	"net/url"
 	"time"
+
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 type dbCreator struct {
@@ -118,3 +120,76 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	time.Sleep(time.Second)
 	return nil
 }
+
+// CreateRollups backfills an hourly rollup of every measurement with SELECT INTO
+// and registers a continuous query that keeps it up to date with newer data.
+func (d *dbCreator) CreateRollups(dbName string) error {
+	measurements, err := d.listMeasurements(dbName)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range measurements {
+		rollup := m + constants.RollupSuffix
+		selectInto := fmt.Sprintf(`SELECT mean(*) INTO "%s" FROM "%s" GROUP BY time(%ds), * fill(none)`,
+			rollup, m, constants.RollupBucketSeconds)
+		cq := fmt.Sprintf(`CREATE CONTINUOUS QUERY "cq_%s" ON "%s" BEGIN %s END`, rollup, dbName, selectInto)
+		for _, q := range []string{selectInto, cq} {
+			if _, err := d.query(dbName, q); err != nil {
+				return fmt.Errorf("could not create rollup for %s: %v", m, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (d *dbCreator) listMeasurements(dbName string) ([]string, error) {
+	body, err := d.query(dbName, "SHOW MEASUREMENTS")
+	if err != nil {
+		return nil, fmt.Errorf("listMeasurements error: %s", err.Error())
+	}
+
+	// {"results":[{"series":[{"name":"measurements","columns":["name"],"values":[["cpu"],["mem"]]}]}]}
+	type listingType struct {
+		Results []struct {
+			Series []struct {
+				Values [][]string
+			}
+		}
+	}
+	var listing listingType
+	if err := json.Unmarshal(body, &listing); err != nil {
+		return nil, err
+	}
+
+	ret := []string{}
+	for _, result := range listing.Results {
+		for _, series := range result.Series {
+			for _, nestedName := range series.Values {
+				ret = append(ret, nestedName[0])
+			}
+		}
+	}
+	return ret, nil
+}
+
+// query runs an InfluxQL statement against the given database and returns the response body
+func (d *dbCreator) query(dbName, q string) ([]byte, error) {
+	v := url.Values{}
+	v.Set("db", dbName)
+	v.Set("q", q)
+	resp, err := http.PostForm(d.daemonURL+"/query", v)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("query returned non-200 code: %d: %s", resp.StatusCode, body)
+	}
+	return body, nil
+}
@@ -4,8 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
+	"github.com/timescale/tsbs/pkg/targets/constants"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
@@ -141,6 +143,90 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	return nil
 }
 
+// CreateRollups builds an hourly rollup per measurement with a $merge aggregation
+// pipeline. Only the document-per-event storage format is supported.
+func (d *dbCreator) CreateRollups(dbName string) error {
+	if !documentPer {
+		return fmt.Errorf("rollups require document-per-event=true")
+	}
+
+	// the loader's client may already be disconnected by Close
+	client, err := mongo.Connect(context.Background(), options.Client().ApplyURI(daemonURL))
+	if err != nil {
+		return err
+	}
+	defer client.Disconnect(context.Background())
+
+	coll := client.Database(dbName).Collection(collectionName)
+	measurements, err := coll.Distinct(context.Background(), "measurement", bson.D{})
+	if err != nil {
+		return err
+	}
+
+	for _, m := range measurements {
+		measurement := m.(string)
+		fields, err := getEventFields(coll, measurement)
+		if err != nil {
+			return err
+		}
+		rollup := measurement + constants.RollupSuffix
+		client.Database(dbName).Collection(rollup).Drop(context.Background())
+
+		cursor, err := coll.Aggregate(context.Background(), rollupPipeline(measurement, rollup, fields))
+		if err != nil {
+			return fmt.Errorf("could not create rollup for %s: %v", measurement, err)
+		}
+		cursor.Close(context.Background())
+
+		model := mongo.IndexModel{Keys: bson.D{{"tags." + metaFieldIndex, 1}, {timestampField, -1}}}
+		_, err = client.Database(dbName).Collection(rollup).Indexes().CreateOne(context.Background(), model)
+		if err != nil {
+			return fmt.Errorf("create rollup indexes err: %v", err.Error())
+		}
+	}
+	return nil
+}
+
+// getEventFields returns the names of the fields of a measurement, based on one
+// of its stored documents
+func getEventFields(coll *mongo.Collection, measurement string) ([]string, error) {
+	var doc bson.M
+	if err := coll.FindOne(context.Background(), bson.M{"measurement": measurement}).Decode(&doc); err != nil {
+		return nil, err
+	}
+	fields := []string{}
+	for k := range doc {
+		switch k {
+		case "_id", "measurement", "tags", timestampField:
+			continue
+		}
+		fields = append(fields, k)
+	}
+	sort.Strings(fields)
+	return fields, nil
+}
+
+// rollupPipeline averages each field per hour and tag set, and merges the
+// results into the rollup collection
+func rollupPipeline(measurement, rollup string, fields []string) mongo.Pipeline {
+	group := bson.M{
+		"_id": bson.M{
+			timestampField: bson.M{"$dateTrunc": bson.M{"date": "$" + timestampField, "unit": "hour"}},
+			"tags":         "$tags",
+		},
+	}
+	for _, f := range fields {
+		group[f] = bson.M{"$avg": "$" + f}
+	}
+
+	return mongo.Pipeline{
+		{{"$match", bson.M{"measurement": measurement}}},
+		{{"$group", group}},
+		{{"$addFields", bson.M{timestampField: "$_id." + timestampField, "tags": "$_id.tags"}}},
+		{{"$merge", bson.M{"into": rollup, "whenMatched": "replace"}}},
+	}
+}
+
 func (d *dbCreator) Close() {
 	d.client.Disconnect(context.Background())
 }
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"html/template"
+	"io"
+)
+
+// Geometry of the HTML charts, in pixels
+const (
+	svgIndent    = 16 // of the bars under the label of their group
+	svgBarWidth  = 640
+	svgBarHeight = 14
+	svgGroupGap  = 10
+)
+
+// svgColors are the colors of the bars of the runs, in order.
+var svgColors = []string{"#4e79a7", "#f28e2b", "#59a14f", "#e15759", "#76b7b2", "#edc948", "#b07aa1", "#ff9da7"}
+
+type htmlReport struct {
+	Title       string
+	Regressions []string
+	Tables      []htmlTable
+}
+
+type htmlTable struct {
+	Title  string
+	Unit   string
+	Runs   []htmlRun
+	Rows   []htmlRow
+	Chart  []svgBar
+	Width  int
+	Height int
+}
+
+type htmlRun struct {
+	Name     string
+	Baseline bool
+	Color    string
+}
+
+type htmlRow struct {
+	Label string
+	Cells []htmlCell
+}
+
+type htmlCell struct {
+	Text       string
+	Regression bool
+}
+
+// svgBar is a bar of a chart, or the label of a group of bars if Label is set.
+type svgBar struct {
+	Label  string
+	X, Y   int
+	Width  int
+	Height int
+	Color  string
+	Text   string
+	TextX  int
+}
+
+var htmlTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; }
+td { text-align: right; font-family: monospace; }
+td:first-child { text-align: left; }
+td.regression { background: #fdd; }
+.swatch { display: inline-block; width: 10px; height: 10px; margin-right: 4px; }
+svg text { font-size: 12px; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{if .Regressions}}<p>&#9888; {{len .Regressions}} regressions:</p>
+<ul>
+{{range .Regressions}}<li>{{.}}</li>
+{{end}}</ul>
+{{else}}<p>No regressions.</p>
+{{end}}{{range .Tables}}<h2>{{.Title}} ({{.Unit}})</h2>
+<table>
+<tr><th></th>{{range .Runs}}<th><span class="swatch" style="background: {{.Color}}"></span>{{.Name}}{{if .Baseline}} (baseline){{end}}</th>{{end}}</tr>
+{{range .Rows}}<tr><td>{{.Label}}</td>{{range .Cells}}<td{{if .Regression}} class="regression"{{end}}>{{.Text}}</td>{{end}}</tr>
+{{end}}</table>
+<svg width="{{.Width}}" height="{{.Height}}">
+{{range .Chart}}{{if .Label}}<text x="0" y="{{.Y}}" dy="12">{{.Label}}</text>
+{{else}}<rect x="{{.X}}" y="{{.Y}}" width="{{.Width}}" height="{{.Height}}" fill="{{.Color}}"></rect><text x="{{.TextX}}" y="{{.Y}}" dy="11">{{.Text}}</text>
+{{end}}{{end}}</svg>
+{{end}}</body>
+</html>
+`))
+
+// writeHTML writes rep as an HTML page, with a table and a bar chart of every
+// comparison.
+func writeHTML(w io.Writer, rep *report) error {
+	page := htmlReport{Title: rep.title, Regressions: rep.regressions()}
+	for _, t := range rep.tables {
+		page.Tables = append(page.Tables, newHTMLTable(t))
+	}
+	return htmlTemplate.Execute(w, page)
+}
+
+func newHTMLTable(t *table) htmlTable {
+	ht := htmlTable{Title: t.title, Unit: t.unit}
+	for i, name := range t.runs {
+		ht.Runs = append(ht.Runs, htmlRun{Name: name, Baseline: i == t.baseline, Color: svgColors[i%len(svgColors)]})
+	}
+	max := t.maxValue()
+	y := 0
+	for _, r := range t.rows {
+		hr := htmlRow{Label: r.label}
+		ht.Chart = append(ht.Chart, svgBar{Label: r.label, Y: y})
+		y += svgBarHeight + 2
+		for i, c := range r.cells {
+			hr.Cells = append(hr.Cells, htmlCell{Text: cellText(c), Regression: c.regression})
+			if c.ok && max > 0 {
+				width := int(svgBarWidth * c.value / max)
+				ht.Chart = append(ht.Chart, svgBar{
+					X:      svgIndent,
+					Y:      y,
+					Width:  width,
+					Height: svgBarHeight,
+					Color:  ht.Runs[i].Color,
+					Text:   formatValue(c.value),
+					TextX:  svgIndent + width + 4,
+				})
+			}
+			y += svgBarHeight + 2
+		}
+		ht.Rows = append(ht.Rows, hr)
+		y += svgGroupGap
+	}
+	ht.Width = svgIndent + svgBarWidth + 80
+	ht.Height = y
+	return ht
+}
@@ -0,0 +1,114 @@
+// tsbs_report compares the results of several benchmark runs, e.g. of
+// different databases or of nightly runs against new database releases.
+//
+// It reads the results json files written with --results-file by the loaders,
+// the query runners and tsbs_run_mixed, or merged by tsbs_coordinator, and
+// writes Markdown or HTML tables and charts of the load throughput, of the
+// query throughput and latency percentiles per query type and of the mixed
+// workload throughput and latency. Each run is compared to a baseline
+// run, and changes worse than the thresholds are flagged as regressions.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+// Output formats
+const (
+	formatMarkdown = "markdown"
+	formatHTML     = "html"
+)
+
+// Program option vars:
+var (
+	baseline            string
+	format              string
+	output              string
+	title               string
+	throughputThreshold float64
+	latencyThreshold    float64
+	failOnRegression    bool
+)
+
+// Parse args:
+func init() {
+	pflag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] result.json [result.json...]\n", os.Args[0])
+		pflag.PrintDefaults()
+	}
+	pflag.StringVar(&baseline, "baseline", "", "Results file to compare the other runs to, default the first results file of each kind")
+	pflag.StringVar(&format, "format", formatMarkdown, fmt.Sprintf("Format of the report (%s, %s)", formatMarkdown, formatHTML))
+	pflag.StringVar(&output, "output", "", "File to write the report to, default STDOUT")
+	pflag.StringVar(&title, "title", "TSBS report", "Title of the report")
+	pflag.Float64Var(&throughputThreshold, "throughput-threshold", 5, "Flag a throughput more than this many percent below the baseline as a regression")
+	pflag.Float64Var(&latencyThreshold, "latency-threshold", 10, "Flag a latency more than this many percent above the baseline as a regression")
+	pflag.BoolVar(&failOnRegression, "fail-on-regression", false, "Exit with status 1 if a regression is flagged")
+}
+
+func main() {
+	pflag.Parse()
+
+	if pflag.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "no results files given")
+		pflag.Usage()
+		os.Exit(2)
+	}
+	if format != formatMarkdown && format != formatHTML {
+		fmt.Fprintf(os.Stderr, "unknown format '%s'\n", format)
+		pflag.Usage()
+		os.Exit(2)
+	}
+
+	runs := make([]*run, 0, pflag.NArg())
+	for _, fileName := range pflag.Args() {
+		r, err := readRun(fileName)
+		if err != nil {
+			fatal(err)
+		}
+		runs = append(runs, r)
+	}
+	baselineName := ""
+	if baseline != "" {
+		baselineName = runName(baseline)
+	}
+	rep, err := newReport(title, runs, baselineName, thresholds{throughput: throughputThreshold, latency: latencyThreshold})
+	if err != nil {
+		fatal(err)
+	}
+
+	var w io.Writer = os.Stdout
+	if output != "" {
+		f, err := os.Create(output)
+		if err != nil {
+			fatal(err)
+		}
+		defer f.Close()
+		w = f
+	}
+	if format == formatHTML {
+		err = writeHTML(w, rep)
+	} else {
+		err = writeMarkdown(w, rep)
+	}
+	if err != nil {
+		fatal(err)
+	}
+
+	regressions := rep.regressions()
+	if len(regressions) > 0 {
+		fmt.Fprintf(os.Stderr, "%d regressions:\n  %s\n", len(regressions), strings.Join(regressions, "\n  "))
+		if failOnRegression {
+			os.Exit(1)
+		}
+	}
+}
+
+func fatal(err error) {
+	fmt.Fprintln(os.Stderr, err)
+	os.Exit(2)
+}
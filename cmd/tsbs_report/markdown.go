@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+)
+
+// chartWidth is the number of characters of the longest bar of the Markdown
+// charts.
+const chartWidth = 40
+
+const regressionMark = "⚠"
+
+// writeMarkdown writes rep as Markdown, with a table and a text bar chart of
+// every comparison.
+func writeMarkdown(w io.Writer, rep *report) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "# %s\n\n", rep.title)
+	if regressions := rep.regressions(); len(regressions) > 0 {
+		fmt.Fprintf(bw, "%s %d regressions:\n\n", regressionMark, len(regressions))
+		for _, r := range regressions {
+			fmt.Fprintf(bw, "- %s\n", r)
+		}
+		fmt.Fprintln(bw)
+	} else {
+		fmt.Fprint(bw, "No regressions.\n\n")
+	}
+	for _, t := range rep.tables {
+		writeMarkdownTable(bw, t)
+		writeMarkdownChart(bw, t)
+	}
+	return bw.Flush()
+}
+
+func writeMarkdownTable(w io.Writer, t *table) {
+	fmt.Fprintf(w, "## %s (%s)\n\n|", t.title, t.unit)
+	for i, name := range t.runs {
+		if i == t.baseline {
+			name += " (baseline)"
+		}
+		fmt.Fprintf(w, " | %s", escapeMarkdown(name))
+	}
+	fmt.Fprint(w, " |\n|:---")
+	for range t.runs {
+		fmt.Fprint(w, "|---:")
+	}
+	fmt.Fprintln(w, "|")
+	for _, r := range t.rows {
+		fmt.Fprintf(w, "| %s", escapeMarkdown(r.label))
+		for _, c := range r.cells {
+			fmt.Fprintf(w, " | %s", cellText(c))
+		}
+		fmt.Fprintln(w, " |")
+	}
+	fmt.Fprintln(w)
+}
+
+func writeMarkdownChart(w io.Writer, t *table) {
+	max := t.maxValue()
+	if max == 0 {
+		return
+	}
+	nameWidth := 0
+	for _, name := range t.runs {
+		if len(name) > nameWidth {
+			nameWidth = len(name)
+		}
+	}
+	fmt.Fprintln(w, "```text")
+	for _, r := range t.rows {
+		fmt.Fprintln(w, r.label)
+		for i, c := range r.cells {
+			if !c.ok {
+				fmt.Fprintf(w, "  %-*s  -\n", nameWidth, t.runs[i])
+				continue
+			}
+			bar := strings.Repeat("█", int(math.Round(chartWidth*c.value/max)))
+			fmt.Fprintf(w, "  %-*s  %s %s\n", nameWidth, t.runs[i], bar, formatValue(c.value))
+		}
+	}
+	fmt.Fprint(w, "```\n\n")
+}
+
+// cellText returns the value of c with its change from the baseline.
+func cellText(c cell) string {
+	if !c.ok {
+		return "-"
+	}
+	s := formatValue(c.value)
+	if c.hasDelta {
+		s += " (" + formatDelta(c.delta) + ")"
+	}
+	if c.regression {
+		s += " " + regressionMark
+	}
+	return s
+}
+
+func escapeMarkdown(s string) string {
+	return strings.NewReplacer("|", `\|`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,329 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Kinds of runs
+const (
+	kindLoad    = "load"
+	kindQueries = "queries"
+	kindMixed   = "mixed"
+)
+
+// allQueriesLabel is the label of the stats of all queries in the results of
+// the query runners, listed first in the tables.
+const allQueriesLabel = "all_queries"
+
+// resultFile holds the parts of the results files of the loaders, the query
+// runners, tsbs_run_mixed and tsbs_coordinator the report is made of.
+type resultFile struct {
+	DurationMillis int64                  `json:"DurationMillis"`
+	Totals         map[string]interface{} `json:"Totals"`
+}
+
+// run is the result of one benchmark run, named after its results file.
+type run struct {
+	name           string
+	kind           string
+	durationMillis int64
+	totals         map[string]interface{}
+}
+
+// runName returns the name of the run saved in fileName: its path relative
+// to the working directory without the extension, so that results files of
+// the same name in different directories make different runs.
+func runName(fileName string) string {
+	name := filepath.Clean(fileName)
+	if filepath.IsAbs(name) {
+		if wd, err := os.Getwd(); err == nil {
+			if rel, err := filepath.Rel(wd, name); err == nil {
+				name = rel
+			}
+		}
+	}
+	return filepath.ToSlash(strings.TrimSuffix(name, filepath.Ext(name)))
+}
+
+func readRun(fileName string) (*run, error) {
+	encoded, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var f resultFile
+	if err = json.Unmarshal(encoded, &f); err != nil {
+		return nil, fmt.Errorf("could not parse results file %s: %v", fileName, err)
+	}
+	r := &run{name: runName(fileName), durationMillis: f.DurationMillis, totals: f.Totals}
+	// the results of tsbs_run_mixed have a metric rate too, so they are told
+	// apart from those of the loaders by their query latencies
+	if _, ok := f.Totals["overallQuantiles"]; ok {
+		r.kind = kindQueries
+	} else if _, ok := f.Totals["queryLatencyMillis"]; ok {
+		r.kind = kindMixed
+	} else if _, ok := f.Totals["metricRate"]; ok {
+		r.kind = kindLoad
+	} else {
+		return nil, fmt.Errorf("%s is neither a load, a query nor a mixed results file", fileName)
+	}
+	return r, nil
+}
+
+// thresholds are the changes, in percent of the baseline, beyond which a
+// value is flagged as a regression.
+type thresholds struct {
+	throughput float64
+	latency    float64
+}
+
+// cell is the value of a row of a table for one run.
+type cell struct {
+	value float64
+	ok    bool // false if the run has no value for the row
+	// delta is the change from the baseline in percent, if hasDelta
+	delta      float64
+	hasDelta   bool
+	regression bool
+}
+
+type row struct {
+	label string
+	cells []cell // one per run of the table
+}
+
+// table compares a measure across runs, one row per measured item (e.g. per
+// query type).
+type table struct {
+	title          string
+	unit           string
+	higherIsBetter bool
+	threshold      float64 // 0 = changes are not flagged
+	runs           []string
+	baseline       int // index of the baseline run
+	rows           []row
+}
+
+// report is the comparison of all runs.
+type report struct {
+	title  string
+	tables []*table
+}
+
+// newReport compares runs. The runs of each kind are compared to the run
+// named baseline if it is of that kind, or else to the first run of that kind.
+func newReport(title string, runs []*run, baseline string, th thresholds) (*report, error) {
+	byKind := map[string][]*run{}
+	names := map[string]bool{}
+	found := baseline == ""
+	for _, r := range runs {
+		if names[r.name] {
+			return nil, fmt.Errorf("more than one results file of the run %s", r.name)
+		}
+		names[r.name] = true
+		byKind[r.kind] = append(byKind[r.kind], r)
+		if r.name == baseline {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("the baseline %s is not one of the results files", baseline)
+	}
+
+	rep := &report{title: title}
+	if loads := byKind[kindLoad]; len(loads) > 0 {
+		b := baselineIndex(loads, baseline)
+		t := newTable("Load throughput", "per sec", true, th.throughput, loads, b)
+		t.addRow("metrics", loads, func(r *run) (float64, bool) { return number(r.totals["metricRate"]) })
+		t.addRow("rows", loads, func(r *run) (float64, bool) { return number(r.totals["rowRate"]) })
+		rep.add(t)
+		t = newTable("Load duration", "sec", false, th.throughput, loads, b)
+		t.addRow("load", loads, func(r *run) (float64, bool) { return float64(r.durationMillis) / 1e3, true })
+		rep.add(t)
+	}
+	if queries := byKind[kindQueries]; len(queries) > 0 {
+		b := baselineIndex(queries, baseline)
+		labels := queryLabels(queries)
+		t := newTable("Query throughput", "queries/sec", true, th.throughput, queries, b)
+		for _, l := range labels {
+			label := l
+			t.addRow(label, queries, func(r *run) (float64, bool) { return labelValue(r.totals["overallQueryRates"], label) })
+		}
+		rep.add(t)
+		for _, q := range []struct{ name, key string }{{"p50", "q50"}, {"p95", "q95"}, {"p99", "q99"}} {
+			key := q.key
+			t = newTable("Query latency "+q.name, "ms", false, th.latency, queries, b)
+			for _, l := range labels {
+				label := l
+				t.addRow(label, queries, func(r *run) (float64, bool) { return quantile(r.totals["overallQuantiles"], label, key) })
+			}
+			rep.add(t)
+		}
+		t = newTable("Query errors and timeouts", "queries", false, 0, queries, b)
+		for _, l := range labels {
+			label := l
+			t.addRow(label+" errors", queries, func(r *run) (float64, bool) { return labelValue(r.totals["errors"], label) })
+			t.addRow(label+" timeouts", queries, func(r *run) (float64, bool) { return labelValue(r.totals["timeouts"], label) })
+		}
+		rep.add(t)
+	}
+	if mixed := byKind[kindMixed]; len(mixed) > 0 {
+		b := baselineIndex(mixed, baseline)
+		t := newTable("Mixed workload throughput", "per sec", true, th.throughput, mixed, b)
+		t.addRow("metrics", mixed, func(r *run) (float64, bool) { return number(r.totals["metricRate"]) })
+		t.addRow("rows", mixed, func(r *run) (float64, bool) { return number(r.totals["rowRate"]) })
+		t.addRow("queries", mixed, func(r *run) (float64, bool) { return number(r.totals["queryRate"]) })
+		rep.add(t)
+		t = newTable("Mixed workload query latency", "ms", false, th.latency, mixed, b)
+		for _, key := range []string{"p50", "p95", "p99"} {
+			k := key
+			t.addRow(k, mixed, func(r *run) (float64, bool) { return labelValue(r.totals["queryLatencyMillis"], k) })
+		}
+		rep.add(t)
+	}
+	return rep, nil
+}
+
+func baselineIndex(runs []*run, baseline string) int {
+	for i, r := range runs {
+		if r.name == baseline {
+			return i
+		}
+	}
+	return 0
+}
+
+func newTable(title, unit string, higherIsBetter bool, threshold float64, runs []*run, baseline int) *table {
+	t := &table{title: title, unit: unit, higherIsBetter: higherIsBetter, threshold: threshold, baseline: baseline}
+	for _, r := range runs {
+		t.runs = append(t.runs, r.name)
+	}
+	return t
+}
+
+// add adds t to the report unless no run has a value for it.
+func (rep *report) add(t *table) {
+	if len(t.rows) > 0 {
+		rep.tables = append(rep.tables, t)
+	}
+}
+
+// addRow adds the row label, with the value of each run, unless no run has a
+// value for it.
+func (t *table) addRow(label string, runs []*run, value func(*run) (float64, bool)) {
+	cells := make([]cell, len(runs))
+	found := false
+	for i, r := range runs {
+		cells[i].value, cells[i].ok = value(r)
+		found = found || cells[i].ok
+	}
+	if !found {
+		return
+	}
+	base := cells[t.baseline]
+	for i := range cells {
+		c := &cells[i]
+		if i == t.baseline || !c.ok || !base.ok || base.value == 0 {
+			continue
+		}
+		c.delta = 100 * (c.value - base.value) / base.value
+		c.hasDelta = true
+		if t.threshold > 0 {
+			c.regression = (t.higherIsBetter && c.delta < -t.threshold) || (!t.higherIsBetter && c.delta > t.threshold)
+		}
+	}
+	t.rows = append(t.rows, row{label: label, cells: cells})
+}
+
+// maxValue returns the largest value of t, for scaling its chart.
+func (t *table) maxValue() float64 {
+	max := 0.0
+	for _, r := range t.rows {
+		for _, c := range r.cells {
+			if c.ok {
+				max = math.Max(max, c.value)
+			}
+		}
+	}
+	return max
+}
+
+// regressions returns a description of every flagged regression.
+func (rep *report) regressions() []string {
+	var out []string
+	for _, t := range rep.tables {
+		for _, r := range t.rows {
+			for i, c := range r.cells {
+				if c.regression {
+					out = append(out, fmt.Sprintf("%s %s of %s: %s", t.title, r.label, t.runs[i], formatDelta(c.delta)))
+				}
+			}
+		}
+	}
+	return out
+}
+
+// queryLabels returns the labels of all query runs, all queries first.
+func queryLabels(runs []*run) []string {
+	seen := map[string]bool{}
+	for _, r := range runs {
+		if m, ok := r.totals["overallQuantiles"].(map[string]interface{}); ok {
+			for l := range m {
+				seen[l] = true
+			}
+		}
+	}
+	labels := make([]string, 0, len(seen))
+	for l := range seen {
+		if l != allQueriesLabel {
+			labels = append(labels, l)
+		}
+	}
+	sort.Strings(labels)
+	if seen[allQueriesLabel] {
+		labels = append([]string{allQueriesLabel}, labels...)
+	}
+	return labels
+}
+
+func number(v interface{}) (float64, bool) {
+	f, ok := v.(float64)
+	return f, ok
+}
+
+func labelValue(m interface{}, label string) (float64, bool) {
+	byLabel, ok := m.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	return number(byLabel[label])
+}
+
+func quantile(m interface{}, label, key string) (float64, bool) {
+	byLabel, ok := m.(map[string]interface{})
+	if !ok {
+		return 0, false
+	}
+	return labelValue(byLabel[label], key)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case v >= 100:
+		return fmt.Sprintf("%.0f", v)
+	case v >= 1:
+		return fmt.Sprintf("%.2f", v)
+	default:
+		return fmt.Sprintf("%.3f", v)
+	}
+}
+
+func formatDelta(delta float64) string {
+	return fmt.Sprintf("%+.1f%%", delta)
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func queryRun(name string, rate, p50 float64) *run {
+	return &run{name: name, kind: kindQueries, totals: map[string]interface{}{
+		"overallQueryRates": map[string]interface{}{"all_queries": rate, "a": rate},
+		"overallQuantiles": map[string]interface{}{
+			"all_queries": map[string]interface{}{"q50": p50, "q95": p50, "q99": p50},
+			"a":           map[string]interface{}{"q50": p50, "q95": p50, "q99": p50},
+		},
+	}}
+}
+
+func TestReadRun(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tsbs_report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cases := []struct {
+		content string
+		kind    string
+	}{
+		{content: `{"DurationMillis": 1000, "Totals": {"metricRate": 10.5}}`, kind: kindLoad},
+		{content: `{"DurationMillis": 1000, "Totals": {"overallQuantiles": {}}}`, kind: kindQueries},
+		{content: `{"DurationMillis": 1000, "Totals": {"metricRate": 10.5, "queryLatencyMillis": {"p50": 1}}}`, kind: kindMixed},
+		{content: `{"Totals": {"timeline": []}}`},
+	}
+	for i, c := range cases {
+		fileName := filepath.Join(dir, "run.json")
+		if err := ioutil.WriteFile(fileName, []byte(c.content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		r, err := readRun(fileName)
+		if c.kind == "" {
+			if err == nil {
+				t.Errorf("%d: no error reading a file of unknown kind", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("%d: unexpected error: %v", i, err)
+		}
+		if r.kind != c.kind || !strings.HasSuffix(r.name, "/run") {
+			t.Errorf("%d: incorrect run: got %s %s want %s .../run", i, r.kind, r.name, c.kind)
+		}
+	}
+}
+
+func TestRunName(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := map[string]string{
+		"run.json":                               "run",
+		"./nightly/2020-01-01/run.json":          "nightly/2020-01-01/run",
+		"release/run.json":                       "release/run",
+		filepath.Join(wd, "release", "run.json"): "release/run",
+	}
+	for fileName, want := range cases {
+		if got := runName(fileName); got != want {
+			t.Errorf("incorrect name of %s: got %s want %s", fileName, got, want)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	runs := []*run{
+		queryRun("base", 100, 10),
+		queryRun("slower", 90, 12),
+		queryRun("faster", 110, 9.5),
+		{name: "load", kind: kindLoad, durationMillis: 2000, totals: map[string]interface{}{"metricRate": 1000.0}},
+	}
+	rep, err := newReport("test", runs, "", thresholds{throughput: 5, latency: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, tbl := range rep.tables {
+		titles = append(titles, tbl.title)
+	}
+	want := "Load throughput,Load duration,Query throughput,Query latency p50,Query latency p95,Query latency p99"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("incorrect tables: got %s want %s", got, want)
+	}
+
+	throughput := rep.tables[2]
+	if throughput.rows[0].label != "all_queries" {
+		t.Errorf("all queries are not listed first: %s", throughput.rows[0].label)
+	}
+	c := throughput.rows[0].cells
+	if c[0].hasDelta || !c[1].hasDelta || c[1].delta != -10 || !c[1].regression || c[2].regression {
+		t.Errorf("incorrect throughput cells: %+v", c)
+	}
+	c = rep.tables[3].rows[0].cells
+	if c[1].delta != 20 || !c[1].regression || c[2].delta != -5 || c[2].regression {
+		t.Errorf("incorrect latency cells: %+v", c)
+	}
+	// all queries and a, in throughput and three latencies
+	if got := len(rep.regressions()); got != 8 {
+		t.Errorf("incorrect number of regressions: got %d want 8", got)
+	}
+
+	rep, err = newReport("test", runs, "slower", thresholds{throughput: 5, latency: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(rep.regressions()); got != 0 {
+		t.Errorf("regressions against a slower baseline: %v", rep.regressions())
+	}
+	if _, err = newReport("test", runs, "missing", thresholds{}); err == nil {
+		t.Errorf("no error with a baseline that is not a run")
+	}
+	if _, err = newReport("test", append(runs, queryRun("base", 100, 10)), "", thresholds{}); err == nil {
+		t.Errorf("no error with two runs of the same name")
+	}
+}
+
+func TestNewReportMixed(t *testing.T) {
+	mixedRun := func(name string, queryRate, p99 float64) *run {
+		return &run{name: name, kind: kindMixed, totals: map[string]interface{}{
+			"metricRate":         1000.0,
+			"queryRate":          queryRate,
+			"queryLatencyMillis": map[string]interface{}{"p50": 1.0, "p95": 2.0, "p99": p99},
+		}}
+	}
+	runs := []*run{
+		{name: "load", kind: kindLoad, durationMillis: 2000, totals: map[string]interface{}{"metricRate": 1000.0}},
+		mixedRun("mixed-base", 10, 5),
+		mixedRun("mixed-new", 10, 6),
+	}
+	rep, err := newReport("test", runs, "", thresholds{throughput: 5, latency: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, tbl := range rep.tables {
+		titles = append(titles, tbl.title)
+	}
+	want := "Load throughput,Load duration,Mixed workload throughput,Mixed workload query latency"
+	if got := strings.Join(titles, ","); got != want {
+		t.Errorf("incorrect tables: got %s want %s", got, want)
+	}
+	if got := len(rep.tables[0].runs); got != 1 {
+		t.Errorf("mixed runs compared with the load runs: %v", rep.tables[0].runs)
+	}
+	if got := rep.regressions(); len(got) != 1 || !strings.Contains(got[0], "p99 of mixed-new") {
+		t.Errorf("incorrect regressions: %v", got)
+	}
+}
+
+func TestWriteReport(t *testing.T) {
+	rep, err := newReport("test", []*run{queryRun("base", 100, 10), queryRun("slower", 90, 12)}, "", thresholds{throughput: 5, latency: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var md bytes.Buffer
+	if err = writeMarkdown(&md, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"# test", "| base (baseline) | slower |", "| all\\_queries | 10.00 | 12.00 (+20.0%) " + regressionMark + " |"} {
+		if !strings.Contains(md.String(), s) {
+			t.Errorf("Markdown report does not contain %q:\n%s", s, md.String())
+		}
+	}
+	var html bytes.Buffer
+	if err = writeHTML(&html, rep); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"<title>test</title>", `<td class="regression">12.00 (&#43;20.0%)`, "<svg"} {
+		if !strings.Contains(html.String(), s) {
+			t.Errorf("HTML report does not contain %q", s)
+		}
+	}
+}
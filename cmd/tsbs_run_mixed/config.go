@@ -0,0 +1,50 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	loadconfig "github.com/timescale/tsbs/load/config"
+)
+
+// MixedRunConfig is the layout of the yaml config file. The data-source and
+// loader objects are those of tsbs_load, the queries object is the one of
+// tsbs_run_queries.
+type MixedRunConfig struct {
+	DataSource *loadconfig.DataSourceConfig `yaml:"data-source" mapstructure:"data-source"`
+	Loader     *loadconfig.LoaderConfig     `yaml:"loader"`
+	Queries    *QueriesConfig               `yaml:"queries,omitempty"`
+	Mixed      *MixedConfig                 `yaml:"mixed,omitempty"`
+}
+
+type QueriesConfig struct {
+	Runner     interface{}
+	DBSpecific interface{} `yaml:"db-specific" mapstructure:"db-specific"`
+}
+
+// MixedConfig are the settings of the mixed run itself.
+type MixedConfig struct {
+	ReportPeriod time.Duration `yaml:"report-period" mapstructure:"report-period"`
+	ResultsFile  string        `yaml:"results-file" mapstructure:"results-file"`
+}
+
+func mixedFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.Duration("mixed.report-period", 10*time.Second, "Period of the combined write throughput and query latency timeline. 0 disables the timeline")
+	fs.String("mixed.results-file", "", "Write the combined results json, with the timeline, to this file")
+	return fs
+}
+
+func parseMixedConfig(v *viper.Viper) (*MixedConfig, error) {
+	mixedViper := v.Sub("mixed")
+	if mixedViper == nil {
+		return nil, fmt.Errorf("config file didn't have a top-level 'mixed' object")
+	}
+	var conf MixedConfig
+	if err := mixedViper.Unmarshal(&conf); err != nil {
+		return nil, err
+	}
+	return &conf, nil
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/data/source"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	dataSourceFlag = "data-source"
+	targetDbFlag   = "target"
+
+	writeConfigTo = "./config.yaml"
+)
+
+func initConfigCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Generate example config yaml file and save it to" + writeConfigTo,
+		Run:   config,
+	}
+
+	cmd.PersistentFlags().String(
+		dataSourceFlag,
+		source.SimulatorDataSourceType,
+		"specify data source, valid:"+strings.Join(source.ValidDataSourceTypes, ", "),
+	)
+	cmd.PersistentFlags().String(
+		targetDbFlag,
+		constants.FormatTimescaleDB,
+		"specify target db, valid: "+strings.Join(supportedFormats(), ", "),
+	)
+	return cmd
+}
+
+func config(cmd *cobra.Command, _ []string) {
+	dataSourceSelected := readFlag(cmd, dataSourceFlag)
+	targetSelected := readFlag(cmd, targetDbFlag)
+	target, err := getTarget(targetSelected)
+	if err != nil {
+		panic(err)
+	}
+
+	exampleConfig := &MixedRunConfig{
+		DataSource: &loadconfig.DataSourceConfig{Type: dataSourceSelected},
+		Loader:     &loadconfig.LoaderConfig{Target: targetSelected},
+	}
+	v := setExampleConfigInViper(exampleConfig, target)
+
+	if err := v.WriteConfigAs(writeConfigTo); err != nil {
+		panic(fmt.Errorf("could not write sample config to file %s: %v", writeConfigTo, err))
+	}
+	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
+}
+
+func readFlag(cmd *cobra.Command, flag string) string {
+	val, err := cmd.PersistentFlags().GetString(flag)
+	if err != nil {
+		panic(fmt.Sprintf("could not read value for %s flag: %v", flag, err))
+	}
+	return val
+}
+
+func setExampleConfigInViper(confWithoutDBSpecifics *MixedRunConfig, t *mixedTarget) *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	// convert MixedRunConfig to yaml to load into viper
+	configInBytes, err := yaml.Marshal(confWithoutDBSpecifics)
+	if err != nil {
+		panic(fmt.Errorf("could not convert example config to yaml: %v", err))
+	}
+
+	if err := v.ReadConfig(bytes.NewBuffer(configInBytes)); err != nil {
+		panic(fmt.Errorf("could not load example config in viper: %v", err))
+	}
+
+	// bind the loader.runner, data-source, queries.runner and mixed flags,
+	// without the flags of the data source type not selected
+	flagSet := loadconfig.CleanDataSourceFlags(confWithoutDBSpecifics.DataSource.Type, runCmdFlags())
+	if err := v.BindPFlags(flagSet); err != nil {
+		panic(fmt.Errorf("could not bind runner, data-source and mixed flags in viper: %v", err))
+	}
+
+	// bind target specific flags of both the loader and the queries
+	flagSet = pflag.NewFlagSet("", pflag.ContinueOnError)
+	t.load.TargetSpecificFlags(loaderDBSpecificFlagPrefix, flagSet)
+	t.queries.TargetSpecificFlags(query.DBSpecificFlagPrefix, flagSet)
+	if err := v.BindPFlags(flagSet); err != nil {
+		panic(fmt.Errorf("could not bind target specific config flags in viper: %v", err))
+	}
+
+	return v
+}
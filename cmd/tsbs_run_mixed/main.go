@@ -0,0 +1,8 @@
+// tsbs_run_mixed loads data into a target database and runs queries against it
+// at the same time, reporting the write throughput and the query latencies on
+// one timeline.
+package main
+
+func main() {
+	rootCmd.Execute()
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/timescale/tsbs/load"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// mixedBenchmark loads data into a target and runs queries against it at the
+// same time. The queries start once the loader has set up the database, and
+// the loader is stopped once the queries are done, so the run lasts as long
+// as the queries do (see the --duration of the query runner).
+type mixedBenchmark struct {
+	target     *mixedTarget
+	bench      targets.Benchmark
+	loader     load.BenchmarkRunner
+	queries    *query.BenchmarkRunner
+	dbSpecific *viper.Viper
+	config     MixedConfig
+
+	loaderConfig loadconfig.RunnerConfig
+
+	started   chan struct{} // closed once the loader starts reading data
+	startOnce sync.Once
+	stop      chan struct{} // closed to make the loader run out of data
+	exhausted uint32        // set if the data ran out before the queries did
+
+	timeline *timeline
+}
+
+func newMixedBenchmark(target *mixedTarget, v *viper.Viper) (*mixedBenchmark, error) {
+	bench, loader, err := loadconfig.ParseConfig(target.load, v)
+	if err != nil {
+		return nil, err
+	}
+	queries, dbSpecific, err := query.ParseConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	mixedConfig, err := parseMixedConfig(v)
+	if err != nil {
+		return nil, err
+	}
+	m := &mixedBenchmark{
+		target:     target,
+		bench:      bench,
+		loader:     loader,
+		queries:    queries,
+		dbSpecific: dbSpecific,
+		config:     *mixedConfig,
+		started:    make(chan struct{}),
+		stop:       make(chan struct{}),
+		timeline:   newTimeline(),
+	}
+	if err = v.Sub("loader").Sub("runner").Unmarshal(&m.loaderConfig); err != nil {
+		return nil, err
+	}
+
+	if loader.DatabaseName() != queries.DatabaseName() {
+		return nil, fmt.Errorf("loader.runner.db-name '%s' and queries.runner.db-name '%s' must name the same database", loader.DatabaseName(), queries.DatabaseName())
+	}
+	if queries.FileName == "" {
+		// stdin may be the data source of the loader
+		return nil, fmt.Errorf("queries.runner.file must be set")
+	}
+	return m, nil
+}
+
+// run runs the loader and the queries and reports their combined results.
+func (m *mixedBenchmark) run() error {
+	loaderDone := make(chan struct{})
+	go func() {
+		defer close(loaderDone)
+		m.loader.RunBenchmark(&mixedLoadBenchmark{Benchmark: m.bench, m: m})
+	}()
+
+	// the database is created by the loader before it reads the data
+	select {
+	case <-m.started:
+	case <-loaderDone:
+		return fmt.Errorf("loader finished before it started loading")
+	}
+
+	start := time.Now()
+	m.timeline.start(start)
+	reportDone := make(chan struct{})
+	go func() {
+		defer close(reportDone)
+		if m.config.ReportPeriod > 0 {
+			m.timeline.report(m.config.ReportPeriod, m.stop)
+		}
+	}()
+	err := m.queries.RunTarget(&mixedQueryTarget{ImplementedTarget: m.target.queries, m: m}, m.dbSpecific)
+	end := time.Now()
+	close(m.stop)
+	<-reportDone
+	<-loaderDone
+	if err != nil {
+		return err
+	}
+
+	if atomic.LoadUint32(&m.exhausted) == 1 {
+		fmt.Println("WARNING: the loader ran out of data before the queries finished, " +
+			"the end of the run had no concurrent writes")
+	}
+	m.timeline.summary(end.Sub(start))
+	if m.config.ResultsFile != "" {
+		return m.saveResults(start, end)
+	}
+	return nil
+}
+
+// mixedLoadBenchmark is the targets.Benchmark of the loader, which signals
+// when loading starts, stops the data when the queries are done and counts
+// the loaded metrics and rows for the timeline.
+type mixedLoadBenchmark struct {
+	targets.Benchmark
+	m *mixedBenchmark
+}
+
+// GetDataSource is called by the loader once the database is set up.
+func (b *mixedLoadBenchmark) GetDataSource() targets.DataSource {
+	b.m.startOnce.Do(func() { close(b.m.started) })
+	return &mixedDataSource{DataSource: b.Benchmark.GetDataSource(), m: b.m}
+}
+
+func (b *mixedLoadBenchmark) GetProcessor() targets.Processor {
+	return &mixedLoadProcessor{Processor: b.Benchmark.GetProcessor(), t: b.m.timeline}
+}
+
+type mixedDataSource struct {
+	targets.DataSource
+	m *mixedBenchmark
+}
+
+// NextItem returns an empty point, which ends the loading, once the queries
+// are done.
+func (d *mixedDataSource) NextItem() data.LoadedPoint {
+	select {
+	case <-d.m.stop:
+		return data.LoadedPoint{}
+	default:
+	}
+	item := d.DataSource.NextItem()
+	if item.Data == nil {
+		atomic.StoreUint32(&d.m.exhausted, 1)
+	}
+	return item
+}
+
+type mixedLoadProcessor struct {
+	targets.Processor
+	t *timeline
+}
+
+func (p *mixedLoadProcessor) ProcessBatch(b targets.Batch, doLoad bool) (uint64, uint64) {
+	metricCnt, rowCnt := p.Processor.ProcessBatch(b, doLoad)
+	p.t.addWrites(metricCnt, rowCnt)
+	return metricCnt, rowCnt
+}
+
+// Close closes the wrapped Processor if it is a targets.ProcessorCloser.
+func (p *mixedLoadProcessor) Close(doLoad bool) {
+	if c, ok := p.Processor.(targets.ProcessorCloser); ok {
+		c.Close(doLoad)
+	}
+}
+
+// mixedQueryTarget is the query.ImplementedTarget of the query runner, whose
+// processors time the queries for the timeline.
+type mixedQueryTarget struct {
+	query.ImplementedTarget
+	m *mixedBenchmark
+}
+
+func (t *mixedQueryTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	create, closeFn, err := t.ImplementedTarget.ProcessorFactory(runner, v)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func() query.Processor {
+		return &mixedQueryProcessor{Processor: create(), t: t.m.timeline}
+	}, closeFn, nil
+}
+
+type mixedQueryProcessor struct {
+	query.Processor
+	t *timeline
+}
+
+func (p *mixedQueryProcessor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	start := time.Now()
+	stats, err := p.Processor.ProcessQuery(q, isWarm)
+	p.t.addQuery(time.Since(start), err)
+	return stats, err
+}
+
+// ProcessQueryContext lets the query runner cancel the queries of the wrapped
+// Processor if it is a query.ContextProcessor.
+func (p *mixedQueryProcessor) ProcessQueryContext(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	cp, ok := p.Processor.(query.ContextProcessor)
+	if !ok {
+		return p.ProcessQuery(q, isWarm)
+	}
+	start := time.Now()
+	stats, err := cp.ProcessQueryContext(ctx, q, isWarm)
+	p.t.addQuery(time.Since(start), err)
+	return stats, err
+}
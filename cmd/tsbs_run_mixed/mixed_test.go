@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+type testDataSource struct {
+	items int
+}
+
+func (d *testDataSource) NextItem() data.LoadedPoint {
+	if d.items == 0 {
+		return data.LoadedPoint{}
+	}
+	d.items--
+	return data.NewLoadedPoint(d.items)
+}
+
+func (d *testDataSource) Headers() *common.GeneratedDataHeaders {
+	return nil
+}
+
+type testLoadProcessor struct {
+	closed bool
+}
+
+func (p *testLoadProcessor) Init(_ int, _, _ bool) {}
+
+func (p *testLoadProcessor) ProcessBatch(_ targets.Batch, _ bool) (uint64, uint64) {
+	return 10, 2
+}
+
+func (p *testLoadProcessor) Close(_ bool) {
+	p.closed = true
+}
+
+type testQueryProcessor struct {
+	err error
+}
+
+func (p *testQueryProcessor) Init(_ int) {}
+
+func (p *testQueryProcessor) ProcessQuery(_ query.Query, _ bool) ([]*query.Stat, error) {
+	time.Sleep(time.Millisecond)
+	return nil, p.err
+}
+
+func approx(got, want float64) bool {
+	return math.Abs(got-want) < 0.01*want
+}
+
+func TestMixedDataSource(t *testing.T) {
+	m := &mixedBenchmark{stop: make(chan struct{})}
+	ds := &mixedDataSource{DataSource: &testDataSource{items: 3}, m: m}
+	if item := ds.NextItem(); item.Data == nil {
+		t.Fatalf("data source stopped before the stop")
+	}
+	close(m.stop)
+	if item := ds.NextItem(); item.Data != nil {
+		t.Errorf("data source did not stop: got %v", item.Data)
+	}
+	if m.exhausted != 0 {
+		t.Errorf("stopped data source marked as exhausted")
+	}
+
+	m = &mixedBenchmark{stop: make(chan struct{})}
+	ds = &mixedDataSource{DataSource: &testDataSource{items: 1}, m: m}
+	ds.NextItem()
+	if item := ds.NextItem(); item.Data != nil || m.exhausted != 1 {
+		t.Errorf("data that ran out not marked as exhausted")
+	}
+}
+
+func TestMixedProcessors(t *testing.T) {
+	tl := newTimeline()
+	inner := &testLoadProcessor{}
+	lp := &mixedLoadProcessor{Processor: inner, t: tl}
+	lp.ProcessBatch(nil, true)
+	lp.ProcessBatch(nil, true)
+	lp.Close(true)
+	if tl.metricCnt != 20 || tl.rowCnt != 4 {
+		t.Errorf("incorrect write counts: got %d metrics %d rows, want 20 and 4", tl.metricCnt, tl.rowCnt)
+	}
+	if !inner.closed {
+		t.Errorf("wrapped processor not closed")
+	}
+
+	qp := &mixedQueryProcessor{Processor: &testQueryProcessor{}, t: tl}
+	qp.ProcessQuery(nil, false)
+	qp = &mixedQueryProcessor{Processor: &testQueryProcessor{err: errors.New("failed")}, t: tl}
+	qp.ProcessQuery(nil, false)
+	if tl.queryCnt != 2 || tl.errorCnt != 1 {
+		t.Errorf("incorrect query counts: got %d queries %d errors, want 2 and 1", tl.queryCnt, tl.errorCnt)
+	}
+	if got := tl.overall.TotalCount(); got != 1 {
+		t.Errorf("failed query recorded in the latencies: got %d latencies want 1", got)
+	}
+}
+
+func TestTimelineSample(t *testing.T) {
+	tl := newTimeline()
+	start := time.Now()
+	tl.start(start)
+	tl.addWrites(100, 10)
+	for i := 1; i <= 100; i++ {
+		tl.addQuery(time.Duration(i)*time.Millisecond, nil)
+	}
+	tl.addQuery(time.Second, errors.New("failed"))
+
+	s := tl.sample(start.Add(2 * time.Second))
+	if s.MetricRate != 50 || s.RowRate != 5 {
+		t.Errorf("incorrect write rates: got %f metrics/s %f rows/s, want 50 and 5", s.MetricRate, s.RowRate)
+	}
+	if s.Queries != 101 || s.Errors != 1 {
+		t.Errorf("incorrect query counts: got %d queries %d errors, want 101 and 1", s.Queries, s.Errors)
+	}
+	// the histogram keeps 4 significant digits
+	if !approx(s.LatencyP50, 50) || !approx(s.LatencyP95, 95) || !approx(s.LatencyMax, 100) {
+		t.Errorf("incorrect latencies: got p50 %f p95 %f max %f", s.LatencyP50, s.LatencyP95, s.LatencyMax)
+	}
+
+	// the next sample only covers the queries since the previous one
+	tl.addQuery(time.Millisecond, nil)
+	s = tl.sample(start.Add(3 * time.Second))
+	if s.Queries != 1 || s.MetricRate != 0 || !approx(s.LatencyMax, 1) {
+		t.Errorf("sample not reset: got %+v", s)
+	}
+	if len(tl.samples) != 2 {
+		t.Errorf("incorrect number of samples: got %d want 2", len(tl.samples))
+	}
+
+	totals := tl.totals(4 * time.Second)
+	if totals["metricRate"] != 25.0 || totals["queryCount"] != uint64(102) {
+		t.Errorf("incorrect totals: got %v", totals)
+	}
+}
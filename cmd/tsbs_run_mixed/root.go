@@ -0,0 +1,23 @@
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var (
+	cfgFile string
+	rootCmd = &cobra.Command{
+		Use:   "tsbs_run_mixed",
+		Short: "Load data into a db and run queries against it at the same time",
+	}
+)
+
+func init() {
+	runCmd, err := initRunCMD()
+	if err != nil {
+		panic(err)
+	}
+	rootCmd.AddCommand(runCmd)
+	configCmd := initConfigCMD()
+	rootCmd.AddCommand(configCmd)
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/query"
+	queryinitializers "github.com/timescale/tsbs/pkg/query/targets/initializers"
+	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	loadinitializers "github.com/timescale/tsbs/pkg/targets/initializers"
+)
+
+const loaderDBSpecificFlagPrefix = "loader.db-specific."
+
+type cmdRunner func(*cobra.Command, []string)
+
+// mixedTarget is a database that data can be both loaded into and queried.
+type mixedTarget struct {
+	load    targets.ImplementedTarget
+	queries query.ImplementedTarget
+}
+
+// supportedFormats returns the formats supported both by tsbs_load and by
+// tsbs_run_queries.
+func supportedFormats() []string {
+	queryFormats := make(map[string]bool)
+	for _, format := range queryinitializers.SupportedFormats() {
+		queryFormats[format] = true
+	}
+	var formats []string
+	for _, format := range constants.SupportedFormats() {
+		if queryFormats[format] {
+			formats = append(formats, format)
+		}
+	}
+	return formats
+}
+
+func getTarget(format string) (*mixedTarget, error) {
+	for _, f := range supportedFormats() {
+		if f == format {
+			return &mixedTarget{
+				load:    loadinitializers.GetTarget(format),
+				queries: queryinitializers.GetTarget(format),
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("target '%s' does not support both loading and queries", format)
+}
+
+func initRunCMD() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:              "run",
+		Short:            "Load data into and run queries against a specified target database at the same time",
+		PersistentPreRun: initViperConfig,
+	}
+	cmd.PersistentFlags().AddFlagSet(runCmdFlags())
+	err := viper.BindPFlags(cmd.PersistentFlags())
+	// don't bind --config which specifies the file from where to read config
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not bind flags to configuration: %v", err)
+	}
+
+	subCommands, err := initRunSubCommands()
+	if err != nil {
+		return nil, err
+	}
+	cmd.AddCommand(subCommands...)
+	return cmd, nil
+}
+
+func runCmdFlags() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	loadconfig.AddDataSourceFlags(fs)
+	loadconfig.AddLoaderRunnerFlags(fs)
+	fs.AddFlagSet(query.RunnerFlags())
+	fs.AddFlagSet(mixedFlags())
+	return fs
+}
+
+func initRunSubCommands() ([]*cobra.Command, error) {
+	allFormats := supportedFormats()
+	commands := make([]*cobra.Command, len(allFormats))
+	for i, format := range allFormats {
+		target, err := getTarget(format)
+		if err != nil {
+			return nil, err
+		}
+		cmd := &cobra.Command{
+			Use:   format,
+			Short: "Load data into and run queries against " + format + " as a target db",
+			Run:   createRunMixed(target),
+		}
+
+		target.load.TargetSpecificFlags(loaderDBSpecificFlagPrefix, cmd.PersistentFlags())
+		target.queries.TargetSpecificFlags(query.DBSpecificFlagPrefix, cmd.PersistentFlags())
+		commands[i] = cmd
+	}
+
+	return commands, nil
+}
+
+func createRunMixed(target *mixedTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command
+		// if we bind them at the time when the flags are defined in initRunSubCommands()
+		// then viper will have all the flags for all targets
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.load.TargetName(), err))
+		}
+		m, err := newMixedBenchmark(target, viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		if err := m.run(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func initViperConfig(*cobra.Command, []string) {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Search config in execution directory with name "config.yaml" (without extension).
+		viper.AddConfigPath(".")
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+	}
+
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	}
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	loadconfig "github.com/timescale/tsbs/load/config"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+const mixedTestResultVersion = "0.1"
+
+// MixedTestResult is the combined result of a mixed run, written to the
+// mixed.results-file.
+type MixedTestResult struct {
+	ResultFormatVersion string `json:"ResultFormatVersion"`
+
+	LoaderConfig      loadconfig.RunnerConfig     `json:"LoaderConfig"`
+	QueryRunnerConfig query.BenchmarkRunnerConfig `json:"QueryRunnerConfig"`
+
+	StartTime      int64 `json:"StartTime"`
+	EndTime        int64 `json:"EndTime"`
+	DurationMillis int64 `json:"DurationMillis"`
+
+	Timeline []TimelineSample       `json:"Timeline"`
+	Totals   map[string]interface{} `json:"Totals"`
+}
+
+// TimelineSample is the write throughput and the query latencies of one
+// report period. The latencies are in milliseconds, of the queries that
+// succeeded in the period.
+type TimelineSample struct {
+	Time       int64   `json:"time"`
+	MetricRate float64 `json:"metricRate"`
+	RowRate    float64 `json:"rowRate"`
+	Queries    uint64  `json:"queries"`
+	Errors     uint64  `json:"errors"`
+	QueryRate  float64 `json:"queryRate"`
+	LatencyP50 float64 `json:"latencyP50"`
+	LatencyP95 float64 `json:"latencyP95"`
+	LatencyP99 float64 `json:"latencyP99"`
+	LatencyMax float64 `json:"latencyMax"`
+}
+
+// timeline counts the writes and times the queries of a mixed run, and
+// samples them every report period.
+type timeline struct {
+	metricCnt uint64
+	rowCnt    uint64
+
+	mu        sync.Mutex
+	queries   uint64                  // queries since the last sample
+	errors    uint64                  // failed queries since the last sample
+	latencies *hdrhistogram.Histogram // of the queries since the last sample, in microseconds
+	overall   *hdrhistogram.Histogram // of all queries, in microseconds
+	queryCnt  uint64
+	errorCnt  uint64
+
+	samples       []TimelineSample
+	prevTime      time.Time
+	prevMetricCnt uint64
+	prevRowCnt    uint64
+}
+
+func newTimeline() *timeline {
+	return &timeline{
+		latencies: hdrhistogram.New(1, 3600000000, 4),
+		overall:   hdrhistogram.New(1, 3600000000, 4),
+	}
+}
+
+func (t *timeline) addWrites(metricCnt, rowCnt uint64) {
+	atomic.AddUint64(&t.metricCnt, metricCnt)
+	atomic.AddUint64(&t.rowCnt, rowCnt)
+}
+
+// addQuery records a query that took took and failed with err, if not nil.
+// Failed queries are counted but left out of the latencies.
+func (t *timeline) addQuery(took time.Duration, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.queries++
+	t.queryCnt++
+	if err != nil {
+		t.errors++
+		t.errorCnt++
+		return
+	}
+	micros := took.Microseconds()
+	if micros < 1 {
+		micros = 1
+	}
+	t.latencies.RecordValue(micros)
+	t.overall.RecordValue(micros)
+}
+
+// start sets the beginning of the first sample.
+func (t *timeline) start(now time.Time) {
+	t.prevTime = now
+	t.prevMetricCnt = atomic.LoadUint64(&t.metricCnt)
+	t.prevRowCnt = atomic.LoadUint64(&t.rowCnt)
+}
+
+// sample returns the sample of the period since the previous one, and starts
+// the next period.
+func (t *timeline) sample(now time.Time) TimelineSample {
+	metricCnt := atomic.LoadUint64(&t.metricCnt)
+	rowCnt := atomic.LoadUint64(&t.rowCnt)
+	took := now.Sub(t.prevTime).Seconds()
+
+	t.mu.Lock()
+	s := TimelineSample{
+		Time:       now.Unix(),
+		MetricRate: float64(metricCnt-t.prevMetricCnt) / took,
+		RowRate:    float64(rowCnt-t.prevRowCnt) / took,
+		Queries:    t.queries,
+		Errors:     t.errors,
+		QueryRate:  float64(t.queries) / took,
+		LatencyP50: float64(t.latencies.ValueAtQuantile(50.0)) / 1e3,
+		LatencyP95: float64(t.latencies.ValueAtQuantile(95.0)) / 1e3,
+		LatencyP99: float64(t.latencies.ValueAtQuantile(99.0)) / 1e3,
+		LatencyMax: float64(t.latencies.Max()) / 1e3,
+	}
+	t.queries = 0
+	t.errors = 0
+	t.latencies.Reset()
+	t.mu.Unlock()
+
+	t.samples = append(t.samples, s)
+	t.prevTime = now
+	t.prevMetricCnt = metricCnt
+	t.prevRowCnt = rowCnt
+	return s
+}
+
+// report prints a sample every period until stop is closed, and a last one
+// for the period cut short by the stop.
+func (t *timeline) report(period time.Duration, stop <-chan struct{}) {
+	fmt.Printf("time,metric/s,row/s,queries,errors,queries/s,q50 ms,q95 ms,q99 ms,max ms\n")
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case now := <-ticker.C:
+			t.printSample(t.sample(now))
+		case <-stop:
+			t.printSample(t.sample(time.Now()))
+			return
+		}
+	}
+}
+
+func (t *timeline) printSample(s TimelineSample) {
+	fmt.Printf("%d,%0.2f,%0.2f,%d,%d,%0.2f,%0.2f,%0.2f,%0.2f,%0.2f\n",
+		s.Time, s.MetricRate, s.RowRate, s.Queries, s.Errors, s.QueryRate,
+		s.LatencyP50, s.LatencyP95, s.LatencyP99, s.LatencyMax)
+}
+
+// totals returns the write throughput and the query latencies of the whole
+// run, which took took.
+func (t *timeline) totals(took time.Duration) map[string]interface{} {
+	metricCnt := atomic.LoadUint64(&t.metricCnt)
+	rowCnt := atomic.LoadUint64(&t.rowCnt)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	totals := map[string]interface{}{
+		"metricRate": float64(metricCnt) / took.Seconds(),
+		"queryRate":  float64(t.queryCnt) / took.Seconds(),
+		"queryCount": t.queryCnt,
+		"errorCount": t.errorCnt,
+		"queryLatencyMillis": map[string]float64{
+			"p50": float64(t.overall.ValueAtQuantile(50.0)) / 1e3,
+			"p95": float64(t.overall.ValueAtQuantile(95.0)) / 1e3,
+			"p99": float64(t.overall.ValueAtQuantile(99.0)) / 1e3,
+			"max": float64(t.overall.Max()) / 1e3,
+		},
+	}
+	if rowCnt > 0 {
+		totals["rowRate"] = float64(rowCnt) / took.Seconds()
+	}
+	return totals
+}
+
+// summary prints the totals of the whole run, which took took.
+func (t *timeline) summary(took time.Duration) {
+	totals := t.totals(took)
+	latencies := totals["queryLatencyMillis"].(map[string]float64)
+	fmt.Printf("\nMixed run summary (%0.3fsec):\n", took.Seconds())
+	fmt.Printf("writes: %0.2f metrics/sec", totals["metricRate"])
+	if rowRate, ok := totals["rowRate"]; ok {
+		fmt.Printf(", %0.2f rows/sec", rowRate)
+	}
+	fmt.Printf("\nqueries: %d (%d failed), %0.2f queries/sec, latency ms: p50 %0.2f, p95 %0.2f, p99 %0.2f, max %0.2f\n",
+		totals["queryCount"], totals["errorCount"], totals["queryRate"],
+		latencies["p50"], latencies["p95"], latencies["p99"], latencies["max"])
+}
+
+// saveResults writes the combined results of the run to the results file.
+func (m *mixedBenchmark) saveResults(start, end time.Time) error {
+	totals := m.timeline.totals(end.Sub(start))
+	totals["queries"] = m.queries.Totals()
+
+	result := MixedTestResult{
+		ResultFormatVersion: mixedTestResultVersion,
+		LoaderConfig:        m.loaderConfig,
+		QueryRunnerConfig:   m.queries.BenchmarkRunnerConfig,
+		StartTime:           start.Unix(),
+		EndTime:             end.Unix(),
+		DurationMillis:      end.Sub(start).Milliseconds(),
+		Timeline:            m.timeline.samples,
+		Totals:              totals,
+	}
+
+	fmt.Printf("Saving results json file to %s\n", m.config.ResultsFile)
+	encoded, err := json.MarshalIndent(result, "", " ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(m.config.ResultsFile, encoded, 0644)
+}
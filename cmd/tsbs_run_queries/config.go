@@ -0,0 +1,13 @@
+package main
+
+// RunQueriesConfig is the layout of the yaml config file. The runner settings
+// are the ones of query.BenchmarkRunnerConfig.
+type RunQueriesConfig struct {
+	Queries *QueriesConfig `yaml:"queries"`
+}
+
+type QueriesConfig struct {
+	Target     string
+	Runner     interface{}
+	DBSpecific interface{} `yaml:"db-specific" mapstructure:"db-specific"`
+}
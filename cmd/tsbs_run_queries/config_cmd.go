@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/query/targets/initializers"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	targetDbFlag = "target"
+
+	writeConfigTo = "./config.yaml"
+)
+
+func initConfigCMD() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Generate example config yaml file and save it to" + writeConfigTo,
+		Run:   config,
+	}
+
+	cmd.PersistentFlags().String(
+		targetDbFlag,
+		constants.FormatTimescaleDB,
+		"specify target db, valid: "+strings.Join(initializers.SupportedFormats(), ", "),
+	)
+	return cmd
+}
+
+func config(cmd *cobra.Command, _ []string) {
+	targetSelected, err := cmd.PersistentFlags().GetString(targetDbFlag)
+	if err != nil {
+		panic(fmt.Sprintf("could not read value for %s flag: %v", targetDbFlag, err))
+	}
+
+	exampleConfig := &RunQueriesConfig{
+		Queries: &QueriesConfig{
+			Target: targetSelected,
+		},
+	}
+	target := initializers.GetTarget(targetSelected)
+	v := setExampleConfigInViper(exampleConfig, target)
+
+	if err := v.WriteConfigAs(writeConfigTo); err != nil {
+		panic(fmt.Errorf("could not write sample config to file %s: %v", writeConfigTo, err))
+	}
+	fmt.Printf("Wrote example config to: %s\n", writeConfigTo)
+}
+
+func setExampleConfigInViper(confWithoutDBSpecifics *RunQueriesConfig, t query.ImplementedTarget) *viper.Viper {
+	v := viper.New()
+	v.SetConfigType("yaml")
+
+	// convert RunQueriesConfig to yaml to load into viper
+	configInBytes, err := yaml.Marshal(confWithoutDBSpecifics)
+	if err != nil {
+		panic(fmt.Errorf("could not convert example config to yaml: %v", err))
+	}
+
+	if err := v.ReadConfig(bytes.NewBuffer(configInBytes)); err != nil {
+		panic(fmt.Errorf("could not load example config in viper: %v", err))
+	}
+
+	// bind queries.runner flags
+	if err := v.BindPFlags(query.RunnerFlags()); err != nil {
+		panic(fmt.Errorf("could not bind queries.runner flags in viper: %v", err))
+	}
+
+	// get target specific flags
+	flagSet := pflag.NewFlagSet("", pflag.ContinueOnError)
+	t.TargetSpecificFlags(query.DBSpecificFlagPrefix, flagSet)
+	// bind target specific flags
+	if err := v.BindPFlags(flagSet); err != nil {
+		panic(fmt.Errorf("could not bind target specific config flags in viper: %v", err))
+	}
+
+	return v
+}
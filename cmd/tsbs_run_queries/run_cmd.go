@@ -0,0 +1,84 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/cobra"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/query/targets/initializers"
+)
+
+type cmdRunner func(*cobra.Command, []string)
+
+func initRunCMD() (*cobra.Command, error) {
+	cmd := &cobra.Command{
+		Use:              "run",
+		Short:            "Run queries against a specified target database",
+		PersistentPreRun: initViperConfig,
+	}
+	cmd.PersistentFlags().AddFlagSet(query.RunnerFlags())
+	err := viper.BindPFlags(cmd.PersistentFlags())
+	// don't bind --config which specifies the file from where to read config
+	cmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is ./config.yaml)")
+
+	if err != nil {
+		return nil, fmt.Errorf("could not bind flags to configuration: %v", err)
+	}
+
+	subCommands := initRunSubCommands()
+	cmd.AddCommand(subCommands...)
+	return cmd, nil
+}
+
+func initRunSubCommands() []*cobra.Command {
+	allFormats := initializers.SupportedFormats()
+	commands := make([]*cobra.Command, len(allFormats))
+	for i, format := range allFormats {
+		target := initializers.GetTarget(format)
+		cmd := &cobra.Command{
+			Use:   format,
+			Short: "Run queries against " + format + " as a target db",
+			Run:   createRunQueries(target),
+		}
+
+		target.TargetSpecificFlags(query.DBSpecificFlagPrefix, cmd.PersistentFlags())
+		commands[i] = cmd
+	}
+
+	return commands
+}
+
+func createRunQueries(target query.ImplementedTarget) cmdRunner {
+	return func(cmd *cobra.Command, args []string) {
+		// bind only the flags of the executed sub-command
+		// if we bind them at the time when the flags are defined in initRunSubCommands()
+		// then viper will have all the flags for all targets
+		if err := viper.BindPFlags(cmd.PersistentFlags()); err != nil {
+			panic(fmt.Errorf("could not bind db-specific flags for %s: %v", target.TargetName(), err))
+		}
+		runner, dbSpecific, err := query.ParseConfig(viper.GetViper())
+		if err != nil {
+			panic(err)
+		}
+		if err := runner.RunTarget(target, dbSpecific); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func initViperConfig(*cobra.Command, []string) {
+	if cfgFile != "" {
+		// Use config file from the flag.
+		viper.SetConfigFile(cfgFile)
+	} else {
+		// Search config in execution directory with name "config.yaml" (without extension).
+		viper.AddConfigPath(".")
+		viper.SetConfigName("config")
+		viper.SetConfigType("yaml")
+	}
+
+	if err := viper.ReadInConfig(); err == nil {
+		fmt.Println("Using config file:", viper.ConfigFileUsed())
+	}
+}
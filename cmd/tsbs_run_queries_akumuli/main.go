@@ -12,24 +12,20 @@ import (
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
-)
-
-// Program option vars:
-var (
-	endpoint string
+	"github.com/timescale/tsbs/pkg/query/targets/akumuli"
 )
 
 // Global vars:
 var (
 	runner *query.BenchmarkRunner
+	target = akumuli.NewTarget()
 )
 
 // Parse args:
 func init() {
 	var config query.BenchmarkRunnerConfig
 	config.AddToFlagSet(pflag.CommandLine)
-
-	pflag.StringVar(&endpoint, "endpoint", "http://localhost:8181", "Akumuli API endpoint IP address.")
+	target.TargetSpecificFlags("", pflag.CommandLine)
 
 	pflag.Parse()
 
@@ -43,38 +39,11 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	endpoint = viper.GetString("endpoint")
-
 	runner = query.NewBenchmarkRunner(config)
 }
 
 func main() {
-	runner.Run(&query.HTTPPool, newProcessor)
-}
-
-type processor struct {
-	w    *HTTPClient
-	opts *HTTPClientDoOptions
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.opts = &HTTPClientDoOptions{
-		Debug:          runner.DebugLevel(),
-		PrintResponses: runner.DoPrintResponses(),
-	}
-	url := endpoint
-	p.w = NewHTTPClient(url)
-}
-
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	hq := q.(*query.HTTP)
-	lag, err := p.w.Do(hq, p.opts)
-	if err != nil {
-		return nil, err
+	if err := runner.RunTarget(target, viper.GetViper()); err != nil {
+		panic(err)
 	}
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, nil
 }
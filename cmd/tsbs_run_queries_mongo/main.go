@@ -5,51 +5,26 @@
 package main
 
 import (
-	"context"
-	"encoding/gob"
 	"fmt"
-	"log"
-	"time"
-
-	"go.mongodb.org/mongo-driver/bson"
-	"go.mongodb.org/mongo-driver/mongo"
-	"go.mongodb.org/mongo-driver/mongo/options"
 
 	"github.com/blagojts/viper"
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/query"
-)
-
-// Program option vars:
-var (
-	daemonURL string
-	timeout   time.Duration
+	"github.com/timescale/tsbs/pkg/query/targets/mongo"
 )
 
 // Global vars:
 var (
 	runner *query.BenchmarkRunner
-	client *mongo.Client
+	target = mongo.NewTarget()
 )
 
 // Parse args:
 func init() {
-	// needed for deserializing the mongo query from gob
-	gob.Register([]interface{}{})
-	gob.Register(map[string]interface{}{})
-	gob.Register([]map[string]interface{}{})
-	gob.Register(bson.M{})
-	gob.Register(bson.D{})
-	gob.Register(bson.A{})
-	gob.Register([]bson.M{})
-	gob.Register(time.Time{})
-
 	var config query.BenchmarkRunnerConfig
 	config.AddToFlagSet(pflag.CommandLine)
-
-	pflag.String("url", "mongodb://localhost:27017", "Daemon URL.")
-	pflag.Duration("read-timeout", 300*time.Second, "Timeout value for individual queries")
+	target.TargetSpecificFlags("", pflag.CommandLine)
 
 	pflag.Parse()
 
@@ -63,59 +38,11 @@ func init() {
 		panic(fmt.Errorf("unable to decode config: %s", err))
 	}
 
-	daemonURL = viper.GetString("url")
-	timeout = viper.GetDuration("read-timeout")
-
 	runner = query.NewBenchmarkRunner(config)
 }
 
 func main() {
-	var err error
-	opts := options.Client().ApplyURI(daemonURL).SetSocketTimeout(timeout)
-	client, err = mongo.Connect(context.Background(), opts)
-	if err != nil {
-		log.Fatal(err)
+	if err := runner.RunTarget(target, viper.GetViper()); err != nil {
+		panic(err)
 	}
-	runner.Run(&query.MongoPool, newProcessor)
-}
-
-type processor struct {
-	collection *mongo.Collection
-}
-
-func newProcessor() query.Processor { return &processor{} }
-
-func (p *processor) Init(workerNumber int) {
-	p.collection = client.Database(runner.DatabaseName()).Collection("point_data")
-}
-
-func (p *processor) ProcessQuery(q query.Query, _ bool) ([]*query.Stat, error) {
-	mq := q.(*query.Mongo)
-	start := time.Now().UnixNano()
-
-	cursor, err := p.collection.Aggregate(context.Background(), mq.Pipeline)
-	if err != nil {
-		log.Fatal(err)
-	}
-
-	if runner.DebugLevel() > 0 {
-		fmt.Println(mq.Pipeline)
-	}
-	cnt := 0
-	for cursor.Next(context.Background()) {
-		if runner.DoPrintResponses() {
-			fmt.Printf("ID %d: %v\n", q.GetID(), cursor.Current)
-		}
-		cnt++
-	}
-	if runner.DebugLevel() > 0 {
-		fmt.Println(cnt)
-	}
-	err = cursor.Close(context.Background())
-
-	took := time.Now().UnixNano() - start
-	lag := float64(took) / 1e6 // milliseconds
-	stat := query.GetStat()
-	stat.Init(q.HumanLabelName(), lag)
-	return []*query.Stat{stat}, err
 }
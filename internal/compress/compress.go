@@ -0,0 +1,226 @@
+// Package compress compresses the data and query files written by the
+// generators, and decompresses the files read by the loaders and the query
+// runners. The supported codecs are gzip, zstd and lz4 (frame format).
+package compress
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"github.com/pierrec/lz4/v4"
+)
+
+// Codecs of the compressed files
+const (
+	None = "none"
+	Gzip = "gzip"
+	Zstd = "zstd"
+	LZ4  = "lz4"
+)
+
+const errUnknownCodecFmt = "unknown compression codec '%s'"
+
+// extensions are the file extensions of the codecs
+var extensions = map[string]string{
+	".gz":   Gzip,
+	".gzip": Gzip,
+	".zst":  Zstd,
+	".zstd": Zstd,
+	".lz4":  LZ4,
+}
+
+// magics are the first bytes of the files compressed with the codecs
+var magics = []struct {
+	codec string
+	magic []byte
+}{
+	{Gzip, []byte{0x1f, 0x8b}},
+	{Zstd, []byte{0x28, 0xb5, 0x2f, 0xfd}},
+	{LZ4, []byte{0x04, 0x22, 0x4d, 0x18}},
+}
+
+const (
+	// chunkSize is the size of the chunks decompressed ahead of the reader
+	chunkSize = 1 << 20
+	// chunksAhead is the number of chunks decompressed ahead of the reader
+	chunksAhead = 4
+)
+
+// CodecFromFileName returns the codec of a file from its extension, None if
+// it is not the extension of a compressed file.
+func CodecFromFileName(name string) string {
+	if codec, ok := extensions[strings.ToLower(filepath.Ext(name))]; ok {
+		return codec
+	}
+	return None
+}
+
+// Extension returns the extension of the file name if it is the extension of
+// a compressed file, "" otherwise.
+func Extension(name string) string {
+	if CodecFromFileName(name) == None {
+		return ""
+	}
+	return filepath.Ext(name)
+}
+
+// NewWriter returns a writer compressing to w with codec. Its Close flushes
+// the compressed data, but does not close w.
+func NewWriter(w io.Writer, codec string) (io.WriteCloser, error) {
+	switch codec {
+	case None, "":
+		return nopWriteCloser{w}, nil
+	case Gzip:
+		return gzip.NewWriter(w), nil
+	case Zstd:
+		return zstd.NewWriter(w)
+	case LZ4:
+		return lz4.NewWriter(w), nil
+	}
+	return nil, fmt.Errorf(errUnknownCodecFmt, codec)
+}
+
+// NewReader returns a reader of r, decompressed if its first bytes are those
+// of a compressed file. The decompression runs on its own goroutine, ahead of
+// the reader, so that decompressing and parsing the data overlap.
+func NewReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	codec := detect(br)
+	var decompressed io.Reader
+	closeFn := func() {}
+	switch codec {
+	case None:
+		return br, nil
+	case Gzip:
+		zr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		decompressed = zr
+	case Zstd:
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		decompressed, closeFn = zr, zr.Close
+	case LZ4:
+		decompressed = &lz4Reader{br: br, zr: lz4.NewReader(br)}
+	}
+	return newAsyncReader(decompressed, closeFn), nil
+}
+
+// detect returns the codec of the data of br from its first bytes.
+func detect(br *bufio.Reader) string {
+	for _, m := range magics {
+		if head, err := br.Peek(len(m.magic)); err == nil && bytes.Equal(head, m.magic) {
+			return m.codec
+		}
+	}
+	return None
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// lz4Reader reads all the lz4 frames of br, like the lz4 command-line tool
+// does, where an lz4.Reader stops at the end of the first one.
+type lz4Reader struct {
+	br *bufio.Reader
+	zr *lz4.Reader
+}
+
+func (z *lz4Reader) Read(p []byte) (int, error) {
+	n, err := z.zr.Read(p)
+	if err != io.EOF {
+		return n, err
+	}
+	if _, peekErr := z.br.Peek(1); peekErr != nil {
+		// no frame left
+		return n, err
+	}
+	z.zr.Reset(z.br)
+	return n, nil
+}
+
+// chunk is a chunk of decompressed data, and the error that ended the data
+// if any.
+type chunk struct {
+	data []byte
+	err  error
+}
+
+// asyncReader reads the data decompressed ahead by its own goroutine.
+type asyncReader struct {
+	chunks <-chan chunk
+	free   chan<- []byte
+	cur    chunk
+	off    int
+}
+
+func newAsyncReader(r io.Reader, closeFn func()) *asyncReader {
+	chunks := make(chan chunk, chunksAhead)
+	free := make(chan []byte, chunksAhead+1)
+	go func() {
+		defer close(chunks)
+		defer closeFn()
+		for {
+			var buf []byte
+			select {
+			case buf = <-free:
+			default:
+				buf = make([]byte, chunkSize)
+			}
+			n, err := fill(r, buf)
+			chunks <- chunk{data: buf[:n], err: err}
+			if err != nil {
+				return
+			}
+		}
+	}()
+	return &asyncReader{chunks: chunks, free: free}
+}
+
+// fill reads from r until buf is full or r returns an error, unlike
+// io.ReadFull keeping the errors of r as they are.
+func fill(r io.Reader, buf []byte) (int, error) {
+	n := 0
+	for n < len(buf) {
+		m, err := r.Read(buf[n:])
+		n += m
+		if err != nil {
+			return n, err
+		}
+	}
+	return n, nil
+}
+
+// Read reads the decompressed data into p.
+func (a *asyncReader) Read(p []byte) (int, error) {
+	for a.off == len(a.cur.data) {
+		if a.cur.err != nil {
+			return 0, a.cur.err
+		}
+		if a.cur.data != nil {
+			a.free <- a.cur.data[:cap(a.cur.data)]
+		}
+		c, ok := <-a.chunks
+		if !ok {
+			return 0, io.EOF
+		}
+		a.cur, a.off = c, 0
+	}
+	n := copy(p, a.cur.data[a.off:])
+	a.off += n
+	return n, nil
+}
@@ -0,0 +1,157 @@
+package compress
+
+import (
+	"bytes"
+	stdgzip "compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/pierrec/lz4/v4"
+)
+
+// testData returns n bytes of data looking like generated data points.
+func testData(n int) []byte {
+	r := rand.New(rand.NewSource(123))
+	var buf bytes.Buffer
+	for buf.Len() < n {
+		fmt.Fprintf(&buf, "cpu,hostname=host_%d usage_user=%d,usage_system=%.2f %d\n", r.Intn(100), r.Intn(100), r.Float64()*100, 1451606400000000000+int64(buf.Len()))
+	}
+	return buf.Bytes()[:n]
+}
+
+func TestCodecFromFileName(t *testing.T) {
+	cases := map[string]string{
+		"data.gz":         Gzip,
+		"data.GZ":         Gzip,
+		"data.zst":        Zstd,
+		"queries.zstd":    Zstd,
+		"/tmp/data.0.lz4": LZ4,
+		"data":            None,
+		"data.txt":        None,
+		"":                None,
+	}
+	for name, want := range cases {
+		if got := CodecFromFileName(name); got != want {
+			t.Errorf("incorrect codec for '%s': got %s want %s", name, got, want)
+		}
+	}
+	if got := Extension("data.lz4"); got != ".lz4" {
+		t.Errorf("incorrect extension: got %s", got)
+	}
+	if got := Extension("data.txt"); got != "" {
+		t.Errorf("incorrect extension of an uncompressed file: got %s", got)
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	sizes := []int{0, 1, 100, 3 * chunkSize, int(lz4.Block4Mb) + 12345}
+	for _, codec := range []string{None, Gzip, Zstd, LZ4} {
+		for _, size := range sizes {
+			want := testData(size)
+			var buf bytes.Buffer
+			w, err := NewWriter(&buf, codec)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", codec, err)
+			}
+			// write in pieces not aligned with the blocks
+			for rest := want; len(rest) > 0; {
+				n := len(rest)
+				if n > 100000 {
+					n = 100000
+				}
+				if _, err := w.Write(rest[:n]); err != nil {
+					t.Fatalf("%s: unexpected error writing: %v", codec, err)
+				}
+				rest = rest[n:]
+			}
+			if err := w.Close(); err != nil {
+				t.Fatalf("%s: unexpected error closing: %v", codec, err)
+			}
+			if codec != None && size > 1000 && buf.Len() > size/2 {
+				t.Errorf("%s: poor compression of %d bytes: %d bytes", codec, size, buf.Len())
+			}
+
+			r, err := NewReader(&buf)
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", codec, err)
+			}
+			got, err := ioutil.ReadAll(r)
+			if err != nil {
+				t.Fatalf("%s: unexpected error reading %d bytes: %v", codec, size, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("%s: incorrect data after a round trip of %d bytes: got %d bytes", codec, size, len(got))
+			}
+		}
+	}
+}
+
+func TestNewWriterUnknownCodec(t *testing.T) {
+	if _, err := NewWriter(&bytes.Buffer{}, "bogus"); err == nil {
+		t.Errorf("unexpected lack of error for an unknown codec")
+	}
+}
+
+// TestReadToolFiles reads files compressed by the gzip, zstd and lz4
+// command-line tools: cpu.lz4 with linked 64KB blocks and block checksums
+// (lz4 -B4 -BD -BX), cpu-independent.lz4 with independent 64KB blocks, the
+// content size and no content checksum (lz4 -B4 --content-size --no-frame-crc)
+// and cpu-frames.lz4 with two frames (lz4 -9 and lz4 -B5).
+func TestReadToolFiles(t *testing.T) {
+	f, err := os.Open("testdata/cpu.gz")
+	if err != nil {
+		t.Fatalf("could not open the gzip file: %v", err)
+	}
+	defer f.Close()
+	zr, err := stdgzip.NewReader(f)
+	if err != nil {
+		t.Fatalf("could not read the gzip file: %v", err)
+	}
+	want, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("could not read the gzip file: %v", err)
+	}
+
+	for _, name := range []string{"testdata/cpu.gz", "testdata/cpu.zst", "testdata/cpu.lz4", "testdata/cpu-independent.lz4", "testdata/cpu-frames.lz4"} {
+		f, err := os.Open(name)
+		if err != nil {
+			t.Fatalf("could not open %s: %v", name, err)
+		}
+		r, err := NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		got, err := ioutil.ReadAll(r)
+		f.Close()
+		if err != nil {
+			t.Fatalf("%s: unexpected error reading: %v", name, err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s: incorrect data: got %d bytes want %d", name, len(got), len(want))
+		}
+	}
+}
+
+func TestReaderErrors(t *testing.T) {
+	var buf bytes.Buffer
+	w, _ := NewWriter(&buf, LZ4)
+	w.Write(testData(10000))
+	w.Close()
+	compressed := buf.Bytes()
+
+	truncated := compressed[:len(compressed)/2]
+	r, _ := NewReader(bytes.NewReader(truncated))
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Errorf("unexpected lack of error for a truncated file")
+	}
+
+	corrupt := append([]byte{}, compressed...)
+	corrupt[len(corrupt)-10]++
+	r, _ = NewReader(bytes.NewReader(corrupt))
+	if _, err := ioutil.ReadAll(r); err == nil {
+		t.Errorf("unexpected lack of error for a corrupt file")
+	}
+}
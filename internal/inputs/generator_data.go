@@ -7,7 +7,10 @@ import (
 	"math/rand"
 	"os"
 	"sort"
+	"strings"
+	"time"
 
+	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/serialize"
 	"github.com/timescale/tsbs/pkg/data/usecases"
@@ -22,6 +25,11 @@ const (
 	ErrInvalidDataConfig = "invalid config: DataGenerator needs a DataGeneratorConfig"
 )
 
+const (
+	errStringFieldsFmt    = "use case '%s' has string fields, which format '%s' does not support (choose from %s)"
+	errSchemaEvolutionFmt = "format '%s' does not support schema evolution, its loader creates fixed columns from the header"
+)
+
 // DataGenerator is a type of Generator for creating data that will be consumed
 // by a database's write/insert operations. The output is specific to the type
 // of database, but is consumed by TSBS loaders like tsbs_load_timescaledb.
@@ -36,6 +44,13 @@ type DataGenerator struct {
 	// bufOut represents the buffered writer that should actually be passed to
 	// any operations that write out data.
 	bufOut *bufio.Writer
+	// outCloser closes the output once bufOut is flushed, if needed.
+	outCloser io.Closer
+
+	// evolution is the schema evolution of the points, if any, and start the
+	// time its epochs are counted from.
+	evolution *common.SchemaEvolution
+	start     time.Time
 }
 
 func (g *DataGenerator) init(config common.GeneratorConfig) error {
@@ -54,10 +69,25 @@ func (g *DataGenerator) init(config common.GeneratorConfig) error {
 		return err
 	}
 
+	g.evolution, err = common.LoadSchemaEvolution(g.config.SchemaEvolution)
+	if err != nil {
+		return err
+	}
+	if g.evolution != nil {
+		g.start, err = utils.ParseUTCTime(g.config.TimeStart)
+		if err != nil {
+			return err
+		}
+	}
+
 	if g.Out == nil {
 		g.Out = os.Stdout
 	}
-	g.bufOut, err = getBufferedWriter(g.config.File, g.Out)
+	if g.config.ShardFiles {
+		// each worker writes its own file
+		return nil
+	}
+	g.bufOut, g.outCloser, err = getBufferedWriter(g.config.File, g.Out)
 	if err != nil {
 		return err
 	}
@@ -65,11 +95,20 @@ func (g *DataGenerator) init(config common.GeneratorConfig) error {
 	return nil
 }
 
-func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.ImplementedTarget) error {
-	err := g.init(config)
+func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.ImplementedTarget) (err error) {
+	err = g.init(config)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if closeErr := closeBufferedWriter(g.bufOut, g.outCloser); err == nil {
+			err = closeErr
+		}
+	}()
+
+	if g.evolution != nil && hasHeader(target) && target.TargetName() != constants.FormatTimescaleDB {
+		return fmt.Errorf(errSchemaEvolutionFmt, target.TargetName())
+	}
 
 	rand.Seed(g.config.Seed)
 
@@ -78,13 +117,21 @@ func (g *DataGenerator) Generate(config common.GeneratorConfig, target targets.I
 		return err
 	}
 
-	sim := scfg.NewSimulator(g.config.LogInterval, g.config.Limit)
+	if g.config.Workers > 0 {
+		sharded, ok := scfg.(common.ShardedSimulatorConfig)
+		if !ok {
+			return fmt.Errorf(errNotShardableFmt, g.config.Use)
+		}
+		return g.runShards(sharded, target)
+	}
+
+	sim := g.evolve(scfg.NewSimulator(g.config.LogInterval, g.config.Limit))
 	serializer, err := g.getSerializer(sim, target)
 	if err != nil {
 		return err
 	}
 
-	return g.runSimulator(sim, serializer, g.config)
+	return g.runSimulator(sim, serializer, schemaReporter(sim, target), g.config)
 }
 
 func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (common.Simulator, error) {
@@ -98,10 +145,19 @@ func (g *DataGenerator) CreateSimulator(config *common.DataGeneratorConfig) (com
 		return nil, err
 	}
 
-	return scfg.NewSimulator(g.config.LogInterval, g.config.Limit), nil
+	return g.evolve(scfg.NewSimulator(g.config.LogInterval, g.config.Limit)), nil
+}
+
+// evolve returns sim with the schema of its points evolving as described by
+// the schema evolution of the config, if any.
+func (g *DataGenerator) evolve(sim common.Simulator) common.Simulator {
+	return g.evolution.Evolve(sim, g.start, g.config.LogInterval, g.config.Seed)
 }
 
-func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, dgc *common.DataGeneratorConfig) error {
+// runSimulator serializes the points of sim, preceded by the changes of their
+// fields reported by changes, if not nil. The changes are written whatever
+// the interleaved group of the points, since they hold for the next points.
+func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.PointSerializer, changes common.SchemaReporter, dgc *common.DataGeneratorConfig) error {
 	defer g.bufOut.Flush()
 
 	currGroupID := uint(0)
@@ -113,6 +169,9 @@ func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.
 			continue
 		}
 
+		if err := writeSchemaChange(g.bufOut, changes, point); err != nil {
+			return fmt.Errorf("can not write schema change: %s", err)
+		}
 		// in the default case this is always true
 		if currGroupID == dgc.InterleavedGroupID {
 			err := serializer.Serialize(point, g.bufOut)
@@ -128,29 +187,52 @@ func (g *DataGenerator) runSimulator(sim common.Simulator, serializer serialize.
 }
 
 func (g *DataGenerator) getSerializer(sim common.Simulator, target targets.ImplementedTarget) (serialize.PointSerializer, error) {
-	switch target.TargetName() {
-	case constants.FormatCrateDB:
-		fallthrough
-	case constants.FormatClickhouse:
-		fallthrough
-	case constants.FormatTimescaleDB:
-		g.writeHeader(sim.Headers())
+	headers := sim.Headers()
+	if hasStringFields(headers) && !utils.IsIn(target.TargetName(), stringFieldFormats) {
+		return nil, fmt.Errorf(errStringFieldsFmt, g.config.Use, target.TargetName(), strings.Join(stringFieldFormats, ", "))
+	}
+	if hasHeader(target) {
+		writeHeader(g.bufOut, headers)
 	}
 	return target.Serializer(), nil
 }
 
+// stringFieldFormats are the formats whose serializers and loaders handle
+// string fields. The others only store numbers.
+var stringFieldFormats = []string{constants.FormatInflux, constants.FormatQuestDB, constants.FormatTimescaleDB}
+
+// hasStringFields tells whether any measurement of headers has string fields.
+func hasStringFields(headers *common.GeneratedDataHeaders) bool {
+	for _, types := range headers.FieldTypes {
+		if utils.IsIn("string", types) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasHeader tells whether the data of target starts with a header of the tags
+// and fields.
+func hasHeader(target targets.ImplementedTarget) bool {
+	switch target.TargetName() {
+	case constants.FormatCrateDB, constants.FormatClickhouse, constants.FormatTimescaleDB:
+		return true
+	}
+	return false
+}
+
 //TODO should be implemented in targets package
-func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
-	g.bufOut.WriteString("tags")
+func writeHeader(bufOut *bufio.Writer, headers *common.GeneratedDataHeaders) {
+	bufOut.WriteString("tags")
 
 	types := headers.TagTypes
 	for i, key := range headers.TagKeys {
-		g.bufOut.WriteString(",")
-		g.bufOut.Write([]byte(key))
-		g.bufOut.WriteString(" ")
-		g.bufOut.WriteString(types[i])
+		bufOut.WriteString(",")
+		bufOut.Write([]byte(key))
+		bufOut.WriteString(" ")
+		bufOut.WriteString(types[i])
 	}
-	g.bufOut.WriteString("\n")
+	bufOut.WriteString("\n")
 	// sort the keys so the header is deterministic
 	keys := make([]string, 0)
 	fields := headers.FieldKeys
@@ -159,12 +241,48 @@ func (g *DataGenerator) writeHeader(headers *common.GeneratedDataHeaders) {
 	}
 	sort.Strings(keys)
 	for _, measurementName := range keys {
-		g.bufOut.WriteString(measurementName)
-		for _, field := range fields[measurementName] {
-			g.bufOut.WriteString(",")
-			g.bufOut.Write([]byte(field))
+		bufOut.Write(appendFieldsHeader(nil, measurementName, fields[measurementName], headers.FieldTypes[measurementName]))
+	}
+	bufOut.WriteString("\n")
+}
+
+// appendFieldsHeader appends the line of the header of the fields of a
+// measurement to buf, with their types unless types is nil.
+func appendFieldsHeader(buf []byte, measurementName string, fields, types []string) []byte {
+	buf = append(buf, measurementName...)
+	for i, field := range fields {
+		buf = append(buf, ',')
+		buf = append(buf, field...)
+		if types != nil {
+			buf = append(buf, ' ')
+			buf = append(buf, types[i]...)
 		}
-		g.bufOut.WriteString("\n")
 	}
-	g.bufOut.WriteString("\n")
+	return append(buf, '\n')
+}
+
+// schemaReporter returns sim if the changes of the fields of its points are
+// written with them for target, nil otherwise. Only the formats with a header
+// need them.
+func schemaReporter(sim common.Simulator, target targets.ImplementedTarget) common.SchemaReporter {
+	if r, ok := sim.(common.SchemaReporter); ok && hasHeader(target) {
+		return r
+	}
+	return nil
+}
+
+// writeSchemaChange writes the fields of the measurement of p to w if r
+// reports that they changed, as a line of the header prefixed with "schema",
+// so that the loader knows the fields of the points that follow. It writes
+// nothing if r is nil.
+func writeSchemaChange(w io.Writer, r common.SchemaReporter, p *data.Point) error {
+	if r == nil {
+		return nil
+	}
+	fields, types, changed := r.SchemaChange()
+	if !changed {
+		return nil
+	}
+	_, err := w.Write(appendFieldsHeader([]byte("schema,"), string(p.MeasurementName()), fields, types))
+	return err
 }
@@ -0,0 +1,247 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/timescale/tsbs/internal/compress"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/serialize"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+const errNotShardableFmt = "use case '%s' cannot be generated with workers, only the devops, cpu-only, cpu-single and devops-generic use cases can"
+
+// shardFileFmt is the name of the file of a shard, from the output file
+// without its compression extension, the number of the shard and the
+// compression extension
+const shardFileFmt = "%s.%d%s"
+
+// shardBatchSize is the number of points a worker serializes before handing
+// them to the merger.
+const shardBatchSize = 1000
+
+// shardBatch is a batch of serialized points of a shard, with their positions
+// among the points of all the shards. Each point is preceded by the change of
+// the fields written before it, if any.
+type shardBatch struct {
+	buf       bytes.Buffer
+	starts    []int // start of each point in buf, after its schema change
+	ends      []int // end of each point in buf
+	positions []uint64
+	next      int // next point to merge
+}
+
+// point returns the next point to merge.
+func (b *shardBatch) point() []byte {
+	return b.buf.Bytes()[b.starts[b.next]:b.ends[b.next]]
+}
+
+// schemaChange returns the schema change written before the next point to
+// merge, empty if none.
+func (b *shardBatch) schemaChange() []byte {
+	start := 0
+	if b.next > 0 {
+		start = b.ends[b.next-1]
+	}
+	return b.buf.Bytes()[start:b.starts[b.next]]
+}
+
+// runShards simulates the entities of scfg split into shards, one per worker.
+// The entities of every shard are created and simulated with random numbers
+// of their own, so the shards are simulated in parallel.
+func (g *DataGenerator) runShards(scfg common.ShardedSimulatorConfig, target targets.ImplementedTarget) error {
+	shards := int(g.config.Workers)
+	if entities := scfg.Entities(); uint64(shards) > entities {
+		shards = int(entities)
+	}
+	sims := make([]common.ShardSimulator, shards)
+	for i := range sims {
+		sim := scfg.NewShardSimulator(g.config.LogInterval, g.config.Seed, i, shards)
+		sims[i] = g.evolution.EvolveShard(sim, g.start, g.config.LogInterval, g.config.Seed)
+	}
+
+	if g.config.ShardFiles {
+		return g.writeShardFiles(sims, target)
+	}
+	return g.mergeShards(sims, target)
+}
+
+// writeShardFiles writes the points of each shard to its own file, with its
+// own header.
+func (g *DataGenerator) writeShardFiles(sims []common.ShardSimulator, target targets.ImplementedTarget) error {
+	errs := make([]error, len(sims))
+	var wg sync.WaitGroup
+	for i := range sims {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = writeShardFile(shardFileName(g.config.File, i), sims[i], target)
+		}(i)
+	}
+	wg.Wait()
+	return firstError(errs)
+}
+
+// shardFileName returns the name of the file of the shard numbered shard, e.g.
+// data.1.gz for the data.gz output file.
+func shardFileName(filename string, shard int) string {
+	ext := compress.Extension(filename)
+	return fmt.Sprintf(shardFileFmt, strings.TrimSuffix(filename, ext), shard, ext)
+}
+
+func writeShardFile(filename string, sim common.ShardSimulator, target targets.ImplementedTarget) error {
+	w, closer, err := getBufferedWriter(filename, nil)
+	if err != nil {
+		return err
+	}
+	if err := runShardFile(w, sim, target); err != nil {
+		closer.Close()
+		return err
+	}
+	return closeBufferedWriter(w, closer)
+}
+
+func runShardFile(w *bufio.Writer, sim common.ShardSimulator, target targets.ImplementedTarget) error {
+	if hasHeader(target) {
+		writeHeader(w, sim.Headers())
+	}
+
+	serializer := target.Serializer()
+	changes := schemaReporter(sim, target)
+	point := data.NewPoint()
+	for !sim.Finished() {
+		if sim.Next(point) {
+			if err := writeSchemaChange(w, changes, point); err != nil {
+				return fmt.Errorf("can not write schema change: %s", err)
+			}
+			if err := serializer.Serialize(point, w); err != nil {
+				return fmt.Errorf("can not serialize point: %s", err)
+			}
+		}
+		point.Reset()
+	}
+	return nil
+}
+
+// mergeShards writes the points of all the shards in the order of their
+// positions, which is the order of the points of a single shard. The limit of
+// points and the interleaved groups apply to the merged points as they do to
+// the points of a single simulator.
+func (g *DataGenerator) mergeShards(sims []common.ShardSimulator, target targets.ImplementedTarget) error {
+	defer g.bufOut.Flush()
+	if hasHeader(target) {
+		writeHeader(g.bufOut, sims[0].Headers())
+	}
+
+	done := make(chan struct{})
+	batches := make([]chan *shardBatch, len(sims))
+	errs := make([]error, len(sims))
+	var wg sync.WaitGroup
+	for i := range sims {
+		batches[i] = make(chan *shardBatch, 4)
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer close(batches[i])
+			errs[i] = serializeShard(sims[i], target.Serializer(), schemaReporter(sims[i], target), batches[i], done)
+		}(i)
+	}
+
+	err := g.mergeBatches(batches)
+	close(done)
+	wg.Wait()
+	if err != nil {
+		return err
+	}
+	return firstError(errs)
+}
+
+// serializeShard serializes the points of sim in batches sent to out, until
+// the simulation is finished or done is closed, with the changes of their
+// fields reported by changes, if not nil.
+func serializeShard(sim common.ShardSimulator, serializer serialize.PointSerializer, changes common.SchemaReporter, out chan<- *shardBatch, done <-chan struct{}) error {
+	point := data.NewPoint()
+	batch := &shardBatch{}
+	for !sim.Finished() {
+		if sim.Next(point) {
+			if err := writeSchemaChange(&batch.buf, changes, point); err != nil {
+				return fmt.Errorf("can not write schema change: %s", err)
+			}
+			batch.starts = append(batch.starts, batch.buf.Len())
+			if err := serializer.Serialize(point, &batch.buf); err != nil {
+				return fmt.Errorf("can not serialize point: %s", err)
+			}
+			batch.ends = append(batch.ends, batch.buf.Len())
+			batch.positions = append(batch.positions, sim.Position())
+		}
+		point.Reset()
+
+		if len(batch.ends) == shardBatchSize || (sim.Finished() && len(batch.ends) > 0) {
+			select {
+			case out <- batch:
+			case <-done:
+				return nil
+			}
+			batch = &shardBatch{}
+		}
+	}
+	return nil
+}
+
+// mergeBatches writes the points of the batches of the shards in the order of
+// their positions.
+func (g *DataGenerator) mergeBatches(batches []chan *shardBatch) error {
+	heads := make([]*shardBatch, len(batches))
+	for i := range batches {
+		heads[i] = <-batches[i]
+	}
+
+	currGroupID := uint(0)
+	for {
+		// there are few shards, a linear search is as fast as a heap
+		next := -1
+		for i, b := range heads {
+			if b != nil && (next < 0 || b.positions[b.next] < heads[next].positions[heads[next].next]) {
+				next = i
+			}
+		}
+		if next < 0 {
+			return nil
+		}
+
+		b := heads[next]
+		if g.config.Limit > 0 && b.positions[b.next] >= g.config.Limit {
+			return nil
+		}
+		// the schema changes hold for the next points of every group
+		if _, err := g.bufOut.Write(b.schemaChange()); err != nil {
+			return fmt.Errorf("can not write schema change: %s", err)
+		}
+		// in the default case this is always true
+		if currGroupID == g.config.InterleavedGroupID {
+			if _, err := g.bufOut.Write(b.point()); err != nil {
+				return fmt.Errorf("can not write point: %s", err)
+			}
+		}
+		currGroupID = (currGroupID + 1) % g.config.InterleavedNumGroups
+
+		b.next++
+		if b.next == len(b.ends) {
+			heads[next] = <-batches[next]
+		}
+	}
+}
+
+func firstError(errs []error) error {
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
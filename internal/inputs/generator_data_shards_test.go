@@ -0,0 +1,198 @@
+package inputs
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"github.com/timescale/tsbs/pkg/targets/influx"
+)
+
+func shardTestConfig(useCase string, workers uint) *common.DataGeneratorConfig {
+	return &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Seed:      123,
+			Format:    constants.FormatInflux,
+			Use:       useCase,
+			Scale:     10,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   "2016-01-01T00:10:00Z",
+		},
+		InitialScale:          4,
+		LogInterval:           defaultLogInterval,
+		InterleavedNumGroups:  1,
+		MaxMetricCountPerHost: 5,
+		MetricPatterns:        common.PatternSpikes,
+		Workers:               workers,
+	}
+}
+
+func generateToString(t *testing.T, c *common.DataGeneratorConfig) string {
+	var buf bytes.Buffer
+	dg := &DataGenerator{Out: &buf}
+	target := &mockTarget{name: c.Format, serializer: &influx.Serializer{}}
+	if err := dg.Generate(c, target); err != nil {
+		t.Fatalf("unexpected error when generating: %v", err)
+	}
+	return buf.String()
+}
+
+func TestGenerateWorkers(t *testing.T) {
+	useCases := []string{common.UseCaseDevops, common.UseCaseCPUOnly, common.UseCaseCPUSingle, common.UseCaseDevopsGeneric}
+	for _, useCase := range useCases {
+		want := generateToString(t, shardTestConfig(useCase, 1))
+		if want == "" {
+			t.Fatalf("%s: no points generated", useCase)
+		}
+		serial := generateToString(t, shardTestConfig(useCase, 0))
+		if got, wantLines := strings.Count(want, "\n"), strings.Count(serial, "\n"); got != wantLines {
+			t.Errorf("%s: incorrect number of points: got %d want %d", useCase, got, wantLines)
+		}
+		// 16 workers are more than the hosts
+		for _, workers := range []uint{2, 3, 16} {
+			if got := generateToString(t, shardTestConfig(useCase, workers)); got != want {
+				t.Errorf("%s: output with %d workers differs from the output with 1", useCase, workers)
+			}
+		}
+	}
+}
+
+func TestGenerateWorkersLimitAndGroups(t *testing.T) {
+	all := strings.SplitAfter(generateToString(t, shardTestConfig(common.UseCaseCPUOnly, 1)), "\n")
+
+	// the limit counts the points of the inactive hosts, as with a single
+	// simulator
+	c := shardTestConfig(common.UseCaseCPUOnly, 3)
+	c.Limit = 25
+	serial := shardTestConfig(common.UseCaseCPUOnly, 0)
+	serial.Limit = 25
+	got := generateToString(t, c)
+	if wantCount := strings.Count(generateToString(t, serial), "\n"); strings.Count(got, "\n") != wantCount {
+		t.Errorf("incorrect number of points with a limit: got %d want %d", strings.Count(got, "\n"), wantCount)
+	}
+	if want := strings.Join(all[:strings.Count(got, "\n")], ""); got != want {
+		t.Errorf("incorrect points with a limit: got\n%s\nwant\n%s", got, want)
+	}
+
+	c = shardTestConfig(common.UseCaseCPUOnly, 3)
+	c.InterleavedNumGroups = 3
+	c.InterleavedGroupID = 1
+	want := ""
+	for i := 1; i < len(all); i += 3 {
+		want += all[i]
+	}
+	if got := generateToString(t, c); got != want {
+		t.Errorf("incorrect points of an interleaved group")
+	}
+}
+
+func TestGenerateShardFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "shards")
+	if err != nil {
+		t.Fatalf("could not create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := shardTestConfig(common.UseCaseDevops, 3)
+	c.ShardFiles = true
+	c.File = filepath.Join(dir, "data")
+	dg := &DataGenerator{}
+	target := &mockTarget{name: constants.FormatTimescaleDB, serializer: &influx.Serializer{}}
+	if err := dg.Generate(c, target); err != nil {
+		t.Fatalf("unexpected error when generating: %v", err)
+	}
+
+	merged := shardTestConfig(common.UseCaseDevops, 3)
+	want := strings.Count(generateToString(t, merged), "\n")
+	got := 0
+	for i := 0; i < 3; i++ {
+		contents, err := ioutil.ReadFile(shardFileName(c.File, i))
+		if err != nil {
+			t.Fatalf("could not read shard file %d: %v", i, err)
+		}
+		// each file has its own header
+		parts := strings.SplitN(string(contents), "\n\n", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], "tags,") {
+			t.Fatalf("no header in shard file %d", i)
+		}
+		got += strings.Count(parts[1], "\n")
+	}
+	if got != want {
+		t.Errorf("incorrect number of points in the shard files: got %d want %d", got, want)
+	}
+	if _, err := os.Stat(c.File); !os.IsNotExist(err) {
+		t.Errorf("output file created with shard files: %v", err)
+	}
+}
+
+func TestGenerateWorkersErrors(t *testing.T) {
+	dg := &DataGenerator{Out: &bytes.Buffer{}}
+	target := &mockTarget{name: constants.FormatInflux, serializer: &influx.Serializer{}}
+
+	c := shardTestConfig(common.UseCaseIoT, 2)
+	err := dg.Generate(c, target)
+	if want := fmt.Sprintf(errNotShardableFmt, common.UseCaseIoT); err == nil || err.Error() != want {
+		t.Errorf("incorrect error for a use case without shards: got %v want %s", err, want)
+	}
+
+	c = shardTestConfig(common.UseCaseDevops, 0)
+	c.ShardFiles = true
+	c.File = "data"
+	if err := dg.Generate(c, target); err == nil {
+		t.Errorf("unexpected lack of error for shard files without workers")
+	}
+
+	c = shardTestConfig(common.UseCaseDevops, 2)
+	c.ShardFiles = true
+	if err := dg.Generate(c, target); err == nil {
+		t.Errorf("unexpected lack of error for shard files without a file")
+	}
+
+	c = shardTestConfig(common.UseCaseDevops, 2)
+	c.ShardFiles = true
+	c.File = "data"
+	c.Limit = 10
+	if err := dg.Generate(c, target); err == nil {
+		t.Errorf("unexpected lack of error for shard files with a limit")
+	}
+}
+
+func TestShardFileName(t *testing.T) {
+	cases := map[string]string{
+		"/tmp/data":     "/tmp/data.2",
+		"/tmp/data.gz":  "/tmp/data.2.gz",
+		"/tmp/data.lz4": "/tmp/data.2.lz4",
+		"data.txt":      "data.txt.2",
+	}
+	for filename, want := range cases {
+		if got := shardFileName(filename, 2); got != want {
+			t.Errorf("incorrect shard file name of %s: got %s want %s", filename, got, want)
+		}
+	}
+}
+
+func TestShardBatchPoint(t *testing.T) {
+	b := &shardBatch{}
+	changes := []string{"", "schema,m,x\n", ""}
+	for i, s := range []string{"a\n", "bc\n", "def\n"} {
+		b.buf.WriteString(changes[i])
+		b.starts = append(b.starts, b.buf.Len())
+		b.buf.WriteString(s)
+		b.ends = append(b.ends, b.buf.Len())
+	}
+	for i, want := range []string{"a\n", "bc\n", "def\n"} {
+		if got := string(b.schemaChange()); got != changes[i] {
+			t.Errorf("incorrect schema change: got %q want %q", got, changes[i])
+		}
+		if got := string(b.point()); got != want {
+			t.Errorf("incorrect point: got %q want %q", got, want)
+		}
+		b.next++
+	}
+}
@@ -5,7 +5,9 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
@@ -257,7 +259,7 @@ func TestRunSimulator(t *testing.T) {
 		}
 		serializer := &testSerializer{shouldError: c.shouldError}
 
-		err := g.runSimulator(sim, serializer, dgc)
+		err := g.runSimulator(sim, serializer, nil, dgc)
 		if c.shouldError && err == nil {
 			t.Errorf("%s: unexpected lack of error", c.desc)
 		} else if !c.shouldError && err != nil {
@@ -342,6 +344,114 @@ func TestGetSerializer(t *testing.T) {
 	checkWriteHeader(constants.FormatQuestDB, false)
 }
 
+func TestGetSerializerStringFields(t *testing.T) {
+	dgc := &common.DataGeneratorConfig{
+		BaseConfig: common.BaseConfig{
+			Use:       common.UseCaseEvents,
+			Scale:     1,
+			TimeStart: defaultTimeStart,
+			TimeEnd:   defaultTimeEnd,
+		},
+		InitialScale: 1,
+		LogInterval:  defaultLogInterval,
+	}
+	g := &DataGenerator{
+		config: dgc,
+	}
+
+	scfg, err := usecases.GetSimulatorConfig(dgc)
+	if err != nil {
+		t.Fatalf("unexpected error creating scfg: %v", err)
+	}
+
+	sim := scfg.NewSimulator(dgc.LogInterval, 0)
+	for _, format := range constants.SupportedFormats() {
+		var buf bytes.Buffer
+		g.bufOut = bufio.NewWriter(&buf)
+		target := &mockTarget{
+			name:       format,
+			serializer: &mockSerializer{},
+		}
+		_, err := g.getSerializer(sim, target)
+		switch format {
+		case constants.FormatInflux, constants.FormatQuestDB, constants.FormatTimescaleDB:
+			if err != nil {
+				t.Errorf("unexpected error for format %s: %v", format, err)
+			}
+		default:
+			want := fmt.Sprintf(errStringFieldsFmt, common.UseCaseEvents, format, "influx, questdb, timescaledb")
+			if err == nil || err.Error() != want {
+				t.Errorf("incorrect error for format %s: got %v want %s", format, err, want)
+			}
+			g.bufOut.Flush()
+			if buf.Len() > 0 {
+				t.Errorf("unexpected header for rejected format %s", format)
+			}
+		}
+	}
+}
+
+func TestGenerateSchemaEvolution(t *testing.T) {
+	f, err := ioutil.TempFile("", "schema-evolution-*.yaml")
+	if err != nil {
+		t.Fatalf("could not create the schema evolution file: %v", err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("changes: [{epoch: 30, measurement: cpu, add-fields: [usage_total]}]\n")
+	f.Close()
+
+	// the header holds the fields of the start, and the change is written
+	// before the first point of its epoch, 5 minutes in
+	const changedAt = " 1451606700000000000"
+	wantChange := "schema,cpu,usage_user,usage_system,usage_idle,usage_nice,usage_iowait,usage_irq,usage_softirq,usage_steal,usage_guest,usage_guest_nice,usage_total"
+	check := func(workers uint, wantChanges int) []string {
+		c := shardTestConfig(common.UseCaseCPUOnly, workers)
+		c.Format = constants.FormatTimescaleDB
+		c.SchemaEvolution = f.Name()
+		lines := strings.Split(strings.TrimSuffix(generateToString(t, c), "\n"), "\n")
+		if !strings.HasPrefix(lines[1], "cpu,usage_user,") || strings.Contains(lines[1], "usage_total") {
+			t.Errorf("%d workers: incorrect header: %s", workers, lines[1])
+		}
+		var points []string
+		changes, changed := 0, false
+		for i, line := range lines[3:] {
+			if strings.HasPrefix(line, "schema,") {
+				if line != wantChange {
+					t.Errorf("%d workers: incorrect schema change: got %s want %s", workers, line, wantChange)
+				}
+				if next := lines[3+i+1]; !strings.HasSuffix(next, changedAt) {
+					t.Errorf("%d workers: schema change written before the point %s", workers, next)
+				}
+				changes++
+				changed = true
+				continue
+			}
+			if strings.Contains(line, "usage_total") != changed {
+				t.Errorf("%d workers: point does not match the schema change before it: %s", workers, line)
+			}
+			points = append(points, line)
+		}
+		if changes != wantChanges {
+			t.Errorf("%d workers: incorrect number of schema changes: got %d want %d", workers, changes, wantChanges)
+		}
+		return points
+	}
+	check(0, 1)
+	// every shard writes the change of its points
+	if one, two := check(1, 1), check(2, 2); strings.Join(one, "\n") != strings.Join(two, "\n") {
+		t.Errorf("points with 2 workers differ from the points with 1")
+	}
+
+	c := shardTestConfig(common.UseCaseCPUOnly, 0)
+	c.Format = constants.FormatCrateDB
+	c.SchemaEvolution = f.Name()
+	target := &mockTarget{name: c.Format, serializer: &mockSerializer{}}
+	err = (&DataGenerator{Out: ioutil.Discard}).Generate(c, target)
+	if want := fmt.Sprintf(errSchemaEvolutionFmt, constants.FormatCrateDB); err == nil || err.Error() != want {
+		t.Errorf("incorrect error for a format with a fixed header: got %v want %s", err, want)
+	}
+}
+
 type mockSerializer struct {
 	numCalledSerialize int
 	sentPoints         []*data.Point
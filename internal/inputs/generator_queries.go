@@ -10,9 +10,11 @@ import (
 	"sort"
 	"time"
 
+	queryCommon "github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/query"
 	"github.com/timescale/tsbs/pkg/query/config"
 	"github.com/timescale/tsbs/pkg/query/factories"
 )
@@ -28,6 +30,8 @@ const (
 	errUnknownUseCaseFmt        = "use case '%s' is undefined"
 	errCannotParseTimeFmt       = "cannot parse time from string '%s': %v"
 	errBadUseFmt                = "invalid use case specified: '%v'"
+	errNoDistributionsFmt       = "query generator for format '%s' does not support time window or host distributions"
+	errZipfExponent             = "zipf exponent must be > 0"
 )
 
 // DevopsGeneratorMaker creates a query generator for devops use case
@@ -44,6 +48,32 @@ type FinanceGeneratorMaker interface {
 	NewFinance(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
 }
 
+// K8sGeneratorMaker creates a query generator for k8s use case
+type K8sGeneratorMaker interface {
+	NewK8s(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// EnergyGeneratorMaker creates a query generator for energy use case
+type EnergyGeneratorMaker interface {
+	NewEnergy(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// EventsGeneratorMaker creates a query generator for events use case
+type EventsGeneratorMaker interface {
+	NewEvents(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// NetflowGeneratorMaker creates a query generator for netflow use case
+type NetflowGeneratorMaker interface {
+	NewNetflow(start, end time.Time, scale int) (queryUtils.QueryGenerator, error)
+}
+
+// distributionSetter is implemented by query generators that can pick the
+// time windows and hosts of their queries from non-uniform distributions.
+type distributionSetter interface {
+	SetDistributions(internalUtils.WindowDistribution, queryCommon.HostDistribution)
+}
+
 // QueryGenerator is a type of Generator for creating queries to test against a
 // database. The output is specific to the type of database (due to each using
 // different querying techniques, e.g. SQL or REST), but is consumed by TSBS
@@ -56,9 +86,14 @@ type QueryGenerator struct {
 	// DebugOut is where non-generated messages should be written. If nil, it
 	// will be os.Stderr.
 	DebugOut io.Writer
+	// ParameterizedMatrix holds, per use case, the query types that accept
+	// params in a query mix file. If nil, query mix entries cannot have params.
+	ParameterizedMatrix map[string]map[string]queryUtils.ParameterizedQueryFillerMaker
 
 	conf          *config.QueryGeneratorConfig
 	useCaseMatrix map[string]map[string]queryUtils.QueryFillerMaker
+	// mix is the weighted query mix read from the query mix file, if any
+	mix []query.MixEntry
 	// factories contains all the database implementations which can create
 	// devops query generators.
 	factories map[string]interface{}
@@ -68,6 +103,8 @@ type QueryGenerator struct {
 	// bufOut represents the buffered writer that should actually be passed to
 	// any operations that write out data.
 	bufOut *bufio.Writer
+	// outCloser closes the output once bufOut is flushed, if needed.
+	outCloser io.Closer
 }
 
 // NewQueryGenerator returns a QueryGenerator that is set up to work with a given
@@ -80,19 +117,37 @@ func NewQueryGenerator(useCaseMatrix map[string]map[string]queryUtils.QueryFille
 	}
 }
 
-func (g *QueryGenerator) Generate(config common.GeneratorConfig) error {
-	err := g.init(config)
+func (g *QueryGenerator) Generate(config common.GeneratorConfig) (err error) {
+	err = g.init(config)
 	if err != nil {
 		return err
 	}
+	defer func() {
+		if closeErr := closeBufferedWriter(g.bufOut, g.outCloser); err == nil {
+			err = closeErr
+		}
+	}()
 
 	useGen, err := g.getUseCaseGenerator(g.conf)
 	if err != nil {
 		return err
 	}
+	if err := g.setDistributions(useGen); err != nil {
+		return err
+	}
 
-	filler := g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+	if g.mix == nil {
+		filler := g.useCaseMatrix[g.conf.Use][g.conf.QueryType](useGen)
+		return g.runQueryGeneration(useGen, filler, g.conf)
+	}
 
+	filler, err := g.newQueryMixFiller(useGen)
+	if err != nil {
+		return err
+	}
+	if err := g.writeHeader(); err != nil {
+		return err
+	}
 	return g.runQueryGeneration(useGen, filler, g.conf)
 }
 
@@ -120,7 +175,22 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 		return fmt.Errorf(errBadUseFmt, g.conf.Use)
 	}
 
-	if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
+	g.mix = nil
+	if g.conf.QueryMixFile != "" || g.conf.QueryTemplateFile != "" {
+		if g.conf.QueryMixFile != "" {
+			g.mix, err = loadQueryMix(g.conf.QueryMixFile)
+			if err != nil {
+				return err
+			}
+		} else {
+			g.mix = templateQueryMix(g.conf.QueryTemplateFile)
+		}
+		for _, e := range g.mix {
+			if _, err := g.getQueryMixMaker(e); err != nil {
+				return err
+			}
+		}
+	} else if _, ok := g.useCaseMatrix[g.conf.Use][g.conf.QueryType]; !ok {
 		return fmt.Errorf(errBadQueryTypeFmt, g.conf.Use, g.conf.QueryType)
 	}
 
@@ -136,7 +206,7 @@ func (g *QueryGenerator) init(conf common.GeneratorConfig) error {
 	if g.Out == nil {
 		g.Out = os.Stdout
 	}
-	g.bufOut, err = getBufferedWriter(g.conf.File, g.Out)
+	g.bufOut, g.outCloser, err = getBufferedWriter(g.conf.File, g.Out)
 	if err != nil {
 		return err
 	}
@@ -162,7 +232,7 @@ func (g *QueryGenerator) addFactory(database string, factory interface{}) error
 	validFactory := false
 
 	switch factory.(type) {
-	case DevopsGeneratorMaker, IoTGeneratorMaker:
+	case DevopsGeneratorMaker, IoTGeneratorMaker, K8sGeneratorMaker, EnergyGeneratorMaker, EventsGeneratorMaker, NetflowGeneratorMaker:
 		validFactory = true
 	}
 
@@ -207,11 +277,75 @@ func (g *QueryGenerator) getUseCaseGenerator(c *config.QueryGeneratorConfig) (qu
 		}
 
 		return financeFactory.NewFinance(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseK8s:
+		k8sFactory, ok := factory.(K8sGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return k8sFactory.NewK8s(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseEnergy:
+		energyFactory, ok := factory.(EnergyGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return energyFactory.NewEnergy(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseEvents:
+		eventsFactory, ok := factory.(EventsGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return eventsFactory.NewEvents(g.tsStart, g.tsEnd, scale)
+	case common.UseCaseNetflow:
+		netflowFactory, ok := factory.(NetflowGeneratorMaker)
+		if !ok {
+			return nil, fmt.Errorf(errUseCaseNotImplementedFmt, c.Use, c.Format)
+		}
+
+		return netflowFactory.NewNetflow(g.tsStart, g.tsEnd, scale)
 	default:
 		return nil, fmt.Errorf(errUnknownUseCaseFmt, c.Use)
 	}
 }
 
+// setDistributions configures how useGen picks time windows and hosts. The
+// default uniform distributions need no configuration.
+func (g *QueryGenerator) setDistributions(useGen queryUtils.QueryGenerator) error {
+	c := g.conf
+	windowName, hostName := c.TimeWindowDistribution, c.HostDistribution
+	if (windowName == "" || windowName == internalUtils.WindowDistUniform) &&
+		(hostName == "" || hostName == queryCommon.HostDistUniform) {
+		return nil
+	}
+	if windowName == "" {
+		windowName = internalUtils.WindowDistUniform
+	}
+	if hostName == "" {
+		hostName = queryCommon.HostDistUniform
+	}
+	if (windowName == internalUtils.WindowDistZipfChunks || hostName == queryCommon.HostDistZipf) && c.ZipfExponent <= 0 {
+		return fmt.Errorf(errZipfExponent)
+	}
+
+	windowDist, err := internalUtils.NewWindowDistribution(windowName, c.TimeWindowRecentMean, c.TimeWindowChunk, c.ZipfExponent)
+	if err != nil {
+		return err
+	}
+	hostDist, err := queryCommon.NewHostDistribution(hostName, c.ZipfExponent)
+	if err != nil {
+		return err
+	}
+
+	ds, ok := useGen.(distributionSetter)
+	if !ok {
+		return fmt.Errorf(errNoDistributionsFmt, c.Format)
+	}
+	ds.SetDistributions(windowDist, hostDist)
+	return nil
+}
+
 func (g *QueryGenerator) runQueryGeneration(useGen queryUtils.QueryGenerator, filler queryUtils.QueryFiller, c *config.QueryGeneratorConfig) error {
 	stats := make(map[string]int64)
 	currentGroup := uint(0)
@@ -21,6 +21,7 @@ import (
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/questdb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/siridb"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/databases/timescaledb"
+	queryCommon "github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/common"
 	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
 	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
 	internalUtils "github.com/timescale/tsbs/internal/utils"
@@ -67,7 +68,62 @@ func TestQueryGeneratorConfigValidate(t *testing.T) {
 	if err == nil {
 		t.Errorf("unexpected lack of error for empty query type")
 	}
+
+	// Test query mix validation
+	c.QueryMixFile = "mix.yaml"
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for query mix without query type: %v", err)
+	}
 	c.QueryType = "foo"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for query type and query mix")
+	} else if got := err.Error(); got != config.ErrQueryTypeAndQueryMix {
+		t.Errorf("incorrect error for query type and query mix: got\n%s\nwant\n%s", got, config.ErrQueryTypeAndQueryMix)
+	}
+	c.QueryMixFile = ""
+
+	// Test query template validation
+	c.QueryTemplateFile = "template.yaml"
+	err = c.Validate()
+	if err == nil {
+		t.Errorf("unexpected lack of error for query type and query template")
+	} else if got := err.Error(); got != config.ErrQueryTypeAndQueryMix {
+		t.Errorf("incorrect error for query type and query template: got\n%s\nwant\n%s", got, config.ErrQueryTypeAndQueryMix)
+	}
+	c.QueryType = ""
+	err = c.Validate()
+	if err != nil {
+		t.Errorf("unexpected error for query template without query type: %v", err)
+	}
+	c.QueryTemplateFile = ""
+	c.QueryType = "foo"
+
+	// Test time window validation
+	for _, tc := range []struct {
+		dist       string
+		recentMean time.Duration
+		chunk      time.Duration
+		want       string
+	}{
+		{internalUtils.WindowDistExponentialRecent, 0, time.Hour, config.ErrRecentMeanNotPositive},
+		{internalUtils.WindowDistExponentialRecent, -time.Hour, time.Hour, config.ErrRecentMeanNotPositive},
+		{internalUtils.WindowDistZipfChunks, time.Hour, 0, config.ErrChunkNotPositive},
+	} {
+		c.TimeWindowDistribution, c.TimeWindowRecentMean, c.TimeWindowChunk = tc.dist, tc.recentMean, tc.chunk
+		err = c.Validate()
+		if err == nil {
+			t.Errorf("unexpected lack of error for %s with recent mean %v and chunk %v", tc.dist, tc.recentMean, tc.chunk)
+		} else if got := err.Error(); got != tc.want {
+			t.Errorf("incorrect error for %s: got\n%s\nwant\n%s", tc.dist, got, tc.want)
+		}
+	}
+	c.TimeWindowDistribution, c.TimeWindowRecentMean, c.TimeWindowChunk = internalUtils.WindowDistExponentialRecent, time.Hour, 0
+	if err = c.Validate(); err != nil {
+		t.Errorf("unexpected error for a positive recent mean: %v", err)
+	}
+	c.TimeWindowDistribution = ""
 
 	// Test groups validation
 	c.InterleavedNumGroups = 0
@@ -623,3 +679,54 @@ func TestQueryGeneratorGenerate(t *testing.T) {
 	}
 	checkGeneratedOutput(t, &buf)
 }
+
+// testGenerator is a query generator without time window or host distributions
+type testGenerator struct{}
+
+func (testGenerator) GenerateEmptyQuery() query.Query {
+	return query.NewHTTP()
+}
+
+func TestQueryGeneratorSetDistributions(t *testing.T) {
+	c, g := getTestConfigAndGenerator()
+	if err := g.initFactories(); err != nil {
+		t.Fatalf("could not init factories: %v", err)
+	}
+	useGen, err := g.getUseCaseGenerator(c)
+	if err != nil {
+		t.Fatalf("could not get use case gen: %v", err)
+	}
+
+	// defaults need no distribution setter
+	if err := g.setDistributions(&testGenerator{}); err != nil {
+		t.Errorf("unexpected error for default distributions: %v", err)
+	}
+
+	c.HostDistribution = "zipf"
+	if err := g.setDistributions(useGen); err == nil || err.Error() != errZipfExponent {
+		t.Errorf("incorrect error for zero zipf exponent: %v", err)
+	}
+	c.ZipfExponent = 1.1
+
+	c.TimeWindowDistribution = "foo"
+	if err := g.setDistributions(useGen); err == nil {
+		t.Errorf("unexpected lack of error for unknown time window distribution")
+	}
+	c.TimeWindowDistribution = internalUtils.WindowDistSlidingNow
+
+	if err := g.setDistributions(&testGenerator{}); err == nil {
+		t.Errorf("unexpected lack of error for generator without distributions")
+	}
+
+	if err := g.setDistributions(useGen); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	d := useGen.(*timescaledb.Devops)
+	if _, ok := d.HostDistribution().(*queryCommon.ZipfHosts); !ok {
+		t.Errorf("host distribution not set to zipf")
+	}
+	x := d.Interval.MustRandWindow(time.Hour)
+	if !x.End().Equal(d.Interval.End()) {
+		t.Errorf("time window distribution not set to sliding-now")
+	}
+}
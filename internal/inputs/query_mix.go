@@ -0,0 +1,139 @@
+package inputs
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/templates"
+	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// Error messages when using a query mix
+const (
+	errCannotReadQueryMixFmt  = "cannot read query mix '%s': %v"
+	errEmptyQueryMix          = "query mix has no query types"
+	errZeroWeightFmt          = "query mix entry '%s' must have a weight > 0"
+	errNotParameterizedFmt    = "query type '%s' of use case '%s' does not accept params"
+	errBadQueryMixParamsFmt   = "invalid params for query type '%s': %v"
+	errCouldNotWriteHeaderFmt = "could not write query file header: %v"
+	errQueryMixQueryTypeFmt   = "invalid query type in query mix for use case '%s': '%s'"
+	errCannotParseQueryMixFmt = "cannot parse query mix '%s': %v"
+)
+
+// queryMixSpec is the layout of a query mix (workload) file, e.g.:
+//
+//	queries:
+//	  - query-type: double-groupby-1
+//	    weight: 5
+//	  - query-type: single-groupby
+//	    weight: 2
+//	    params:
+//	      metrics: 5
+//	      hosts: 8
+type queryMixSpec struct {
+	Queries []query.MixEntry `yaml:"queries"`
+}
+
+// loadQueryMix reads and parses the query mix file at path.
+func loadQueryMix(path string) ([]query.MixEntry, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotReadQueryMixFmt, path, err)
+	}
+	spec := queryMixSpec{}
+	if err := yaml.UnmarshalStrict(contents, &spec); err != nil {
+		return nil, fmt.Errorf(errCannotParseQueryMixFmt, path, err)
+	}
+	if len(spec.Queries) == 0 {
+		return nil, fmt.Errorf(errEmptyQueryMix)
+	}
+	for _, e := range spec.Queries {
+		if e.Weight == 0 {
+			return nil, fmt.Errorf(errZeroWeightFmt, e.QueryType)
+		}
+	}
+	return spec.Queries, nil
+}
+
+// templateQueryMix returns the query mix of a single query template file.
+func templateQueryMix(path string) []query.MixEntry {
+	return []query.MixEntry{{
+		QueryType: templates.LabelTemplate,
+		Weight:    1,
+		Params:    map[string]string{"file": path},
+	}}
+}
+
+// weightedFiller is a QueryFiller that fills each query using one of its
+// fillers, picked at random in proportion to its weight.
+type weightedFiller struct {
+	fillers    []queryUtils.QueryFiller
+	cumulative []uint
+}
+
+// Fill fills in the query.Query using a randomly picked filler
+func (f *weightedFiller) Fill(q query.Query) query.Query {
+	total := f.cumulative[len(f.cumulative)-1]
+	n := uint(rand.Int63n(int64(total)))
+	i := sort.Search(len(f.cumulative), func(i int) bool { return f.cumulative[i] > n })
+	return f.fillers[i].Fill(q)
+}
+
+// getQueryMixMaker returns the QueryFillerMaker for one entry of the query mix.
+// Entries with params are looked up in the ParameterizedMatrix, all others in
+// the use case matrix.
+func (g *QueryGenerator) getQueryMixMaker(e query.MixEntry) (queryUtils.QueryFillerMaker, error) {
+	use := g.conf.Use
+	if len(e.Params) == 0 {
+		maker, ok := g.useCaseMatrix[use][e.QueryType]
+		if !ok {
+			return nil, fmt.Errorf(errQueryMixQueryTypeFmt, use, e.QueryType)
+		}
+		return maker, nil
+	}
+
+	paramMaker, ok := g.ParameterizedMatrix[use][e.QueryType]
+	if !ok {
+		return nil, fmt.Errorf(errNotParameterizedFmt, e.QueryType, use)
+	}
+	maker, err := paramMaker(queryUtils.QueryParams(e.Params))
+	if err != nil {
+		return nil, fmt.Errorf(errBadQueryMixParamsFmt, e.QueryType, err)
+	}
+	return maker, nil
+}
+
+// newQueryMixFiller creates a weightedFiller for the query mix using useGen.
+func (g *QueryGenerator) newQueryMixFiller(useGen queryUtils.QueryGenerator) (queryUtils.QueryFiller, error) {
+	f := &weightedFiller{}
+	total := uint(0)
+	for _, e := range g.mix {
+		maker, err := g.getQueryMixMaker(e)
+		if err != nil {
+			return nil, err
+		}
+		total += e.Weight
+		f.fillers = append(f.fillers, maker(useGen))
+		f.cumulative = append(f.cumulative, total)
+	}
+	return f, nil
+}
+
+// writeHeader writes the header describing the query mix to the output.
+func (g *QueryGenerator) writeHeader() error {
+	h := &query.Header{
+		Use:    g.conf.Use,
+		Format: g.conf.Format,
+		Seed:   g.conf.Seed,
+		Mix:    g.mix,
+	}
+	if err := query.WriteHeader(g.bufOut, h); err != nil {
+		return fmt.Errorf(errCouldNotWriteHeaderFmt, err)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package inputs
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	queryUtils "github.com/timescale/tsbs/cmd/tsbs_generate_queries/utils"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func writeQueryMix(t *testing.T, contents string) string {
+	f, err := ioutil.TempFile("", "query-mix-*.yaml")
+	if err != nil {
+		t.Fatalf("could not create query mix file: %v", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(contents); err != nil {
+		t.Fatalf("could not write query mix file: %v", err)
+	}
+	return f.Name()
+}
+
+func TestLoadQueryMix(t *testing.T) {
+	cases := []struct {
+		desc     string
+		contents string
+		wantErr  string
+		wantLen  int
+	}{
+		{
+			desc: "ok",
+			contents: `
+queries:
+  - query-type: lastpoint
+    weight: 2
+  - query-type: single-groupby
+    weight: 1
+    params:
+      hosts: 8
+`,
+			wantLen: 2,
+		},
+		{
+			desc:     "empty",
+			contents: "queries: []\n",
+			wantErr:  errEmptyQueryMix,
+		},
+		{
+			desc:     "zero weight",
+			contents: "queries:\n  - query-type: lastpoint\n",
+			wantErr:  fmt.Sprintf(errZeroWeightFmt, "lastpoint"),
+		},
+		{
+			desc:     "unknown field",
+			contents: "queries:\n  - query-type: lastpoint\n    wieght: 2\n",
+			wantErr:  "cannot parse query mix",
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.desc, func(t *testing.T) {
+			path := writeQueryMix(t, c.contents)
+			defer os.Remove(path)
+
+			mix, err := loadQueryMix(path)
+			if c.wantErr != "" {
+				if err == nil {
+					t.Fatalf("unexpected lack of error")
+				} else if !strings.HasPrefix(err.Error(), c.wantErr) {
+					t.Fatalf("incorrect error: got\n%s\nwant\n%s", err, c.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(mix) != c.wantLen {
+				t.Errorf("incorrect number of entries: got %d want %d", len(mix), c.wantLen)
+			}
+		})
+	}
+
+	if _, err := loadQueryMix("/does/not/exist.yaml"); err == nil {
+		t.Errorf("unexpected lack of error for missing file")
+	}
+}
+
+type countingFiller struct {
+	count int
+}
+
+func (f *countingFiller) Fill(q query.Query) query.Query {
+	f.count++
+	return q
+}
+
+func TestWeightedFiller(t *testing.T) {
+	a, b := &countingFiller{}, &countingFiller{}
+	f := &weightedFiller{
+		fillers:    []queryUtils.QueryFiller{a, b},
+		cumulative: []uint{3, 4},
+	}
+	rand.Seed(123)
+	for i := 0; i < 4000; i++ {
+		f.Fill(nil)
+	}
+	// expect a 3:1 split, allowing some slack for randomness
+	if a.count < 2800 || a.count > 3200 {
+		t.Errorf("incorrect share for weight 3 of 4: got %d of 4000", a.count)
+	}
+	if a.count+b.count != 4000 {
+		t.Errorf("incorrect total fills: got %d", a.count+b.count)
+	}
+}
+
+func TestQueryGeneratorGenerateQueryMix(t *testing.T) {
+	path := writeQueryMix(t, `
+queries:
+  - query-type: single-groupby-1-1-1
+    weight: 1
+  - query-type: single-groupby
+    weight: 1
+    params:
+      hosts: 2
+`)
+	defer os.Remove(path)
+
+	c, g := getTestConfigAndGenerator()
+	c.QueryType = ""
+	c.QueryMixFile = path
+	c.Limit = 20
+
+	// params need the ParameterizedMatrix
+	g.DebugOut = ioutil.Discard
+	err := g.Generate(c)
+	want := fmt.Sprintf(errNotParameterizedFmt, "single-groupby", c.Use)
+	if err == nil || err.Error() != want {
+		t.Fatalf("incorrect error without ParameterizedMatrix: got %v want %s", err, want)
+	}
+
+	g.ParameterizedMatrix = map[string]map[string]queryUtils.ParameterizedQueryFillerMaker{
+		c.Use: {devops.LabelSingleGroupby: devops.NewSingleGroupbyFromParams},
+	}
+	var buf bytes.Buffer
+	g.Out = &buf
+	if err := g.Generate(c); err != nil {
+		t.Fatalf("unexpected error when generating: %v", err)
+	}
+
+	r := bufio.NewReader(&buf)
+	h, err := query.ReadHeader(r)
+	if err != nil || h == nil {
+		t.Fatalf("missing query mix header: %v", err)
+	}
+	if len(h.Mix) != 2 || h.Seed != c.Seed || h.Use != c.Use {
+		t.Errorf("incorrect header: %+v", h)
+	}
+
+	labels := make(map[string]int)
+	decoder := gob.NewDecoder(r)
+	for {
+		var q query.TimescaleDB
+		err := decoder.Decode(&q)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("unexpected error while decoding: %v", err)
+		}
+		labels[string(q.HumanLabel)]++
+	}
+	if len(labels) != 2 {
+		t.Errorf("expected both query types to be generated, got %v", labels)
+	}
+	total := 0
+	for _, n := range labels {
+		total += n
+	}
+	if total != int(c.Limit) {
+		t.Errorf("incorrect number of queries: got %d want %d", total, c.Limit)
+	}
+
+	// bad params are reported when initializing
+	badPath := writeQueryMix(t, "queries:\n  - query-type: single-groupby\n    weight: 1\n    params:\n      racks: 2\n")
+	defer os.Remove(badPath)
+	c.QueryMixFile = badPath
+	if err := g.Generate(c); err == nil || !strings.HasPrefix(err.Error(), "invalid params for query type 'single-groupby'") {
+		t.Errorf("incorrect error for bad params: %v", err)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"io"
 	"os"
+
+	"github.com/timescale/tsbs/internal/compress"
 )
 
 const (
@@ -13,15 +15,51 @@ const (
 
 const defaultWriteSize = 4 << 20 // 4 MB
 
-func getBufferedWriter(filename string, fallback io.Writer) (*bufio.Writer, error) {
+// getBufferedWriter returns the buffered writer of the output, and the closer
+// to call after flushing it. If filename is given, the output goes to that
+// file, compressed if its extension is the one of a codec of package compress.
+func getBufferedWriter(filename string, fallback io.Writer) (*bufio.Writer, io.Closer, error) {
 	// If filename is given, output should go to a file
 	if len(filename) > 0 {
 		file, err := os.Create(filename)
 		if err != nil {
-			return nil, fmt.Errorf("cannot open file for write %s: %v", filename, err)
+			return nil, nil, fmt.Errorf("cannot open file for write %s: %v", filename, err)
+		}
+		w, err := compress.NewWriter(file, compress.CodecFromFileName(filename))
+		if err != nil {
+			file.Close()
+			return nil, nil, err
 		}
-		return bufio.NewWriterSize(file, defaultWriteSize), nil
+		return bufio.NewWriterSize(w, defaultWriteSize), fileCloser{w, file}, nil
 	}
 
-	return bufio.NewWriterSize(fallback, defaultWriteSize), nil
+	return bufio.NewWriterSize(fallback, defaultWriteSize), nil, nil
+}
+
+// fileCloser closes the compressor of a file, then the file.
+type fileCloser struct {
+	compressor io.Closer
+	file       io.Closer
+}
+
+func (c fileCloser) Close() error {
+	if err := c.compressor.Close(); err != nil {
+		c.file.Close()
+		return err
+	}
+	return c.file.Close()
+}
+
+// closeBufferedWriter flushes w, if any, and closes closer, if any.
+func closeBufferedWriter(w *bufio.Writer, closer io.Closer) error {
+	if w == nil {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	if closer != nil {
+		return closer.Close()
+	}
+	return nil
 }
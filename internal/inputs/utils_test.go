@@ -1,7 +1,11 @@
 package inputs
 
 import (
+	"github.com/timescale/tsbs/internal/compress"
 	"github.com/timescale/tsbs/internal/utils"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -56,3 +60,37 @@ func TestParseUTCTime(t *testing.T) {
 		t.Errorf("unexpected lack of error")
 	}
 }
+
+func TestGetBufferedWriterCompressed(t *testing.T) {
+	dir, err := ioutil.TempDir("", "compressed")
+	if err != nil {
+		t.Fatalf("could not create a temp dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"data", "data.gz", "data.zst", "data.lz4"} {
+		filename := filepath.Join(dir, name)
+		w, closer, err := getBufferedWriter(filename, nil)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		w.WriteString("some data")
+		if err := closeBufferedWriter(w, closer); err != nil {
+			t.Fatalf("%s: unexpected error closing: %v", name, err)
+		}
+
+		f, err := os.Open(filename)
+		if err != nil {
+			t.Fatalf("%s: could not open: %v", name, err)
+		}
+		r, err := compress.NewReader(f)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		got, err := ioutil.ReadAll(r)
+		f.Close()
+		if err != nil || string(got) != "some data" {
+			t.Errorf("%s: incorrect data: got %q (%v)", name, got, err)
+		}
+	}
+}
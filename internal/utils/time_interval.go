@@ -2,7 +2,6 @@ package utils
 
 import (
 	"fmt"
-	"math/rand"
 	"time"
 )
 
@@ -20,6 +19,9 @@ const (
 type TimeInterval struct {
 	start time.Time
 	end   time.Time
+
+	// dist decides where RandWindow places windows; nil means uniformly
+	dist WindowDistribution
 }
 
 // NewTimeInterval creates a new TimeInterval for a given start and end. If end
@@ -28,7 +30,13 @@ func NewTimeInterval(start, end time.Time) (*TimeInterval, error) {
 	if end.Before(start) {
 		return nil, fmt.Errorf(ErrEndBeforeStart)
 	}
-	return &TimeInterval{start.UTC(), end.UTC()}, nil
+	return &TimeInterval{start: start.UTC(), end: end.UTC()}, nil
+}
+
+// SetWindowDistribution sets the WindowDistribution used by RandWindow.
+// Passing nil restores the default uniform distribution.
+func (ti *TimeInterval) SetWindowDistribution(dist WindowDistribution) {
+	ti.dist = dist
 }
 
 // Duration returns the time.Duration of the TimeInterval.
@@ -67,8 +75,9 @@ func (ti *TimeInterval) Overlap(other *TimeInterval) bool {
 	return true
 }
 
-// RandWindow creates a TimeInterval of duration `window` at a uniformly-random
-// start time within the time period represented by this TimeInterval.
+// RandWindow creates a TimeInterval of duration `window` at a random start
+// time within the time period represented by this TimeInterval. The start
+// time is uniformly random unless a WindowDistribution has been set.
 func (ti *TimeInterval) RandWindow(window time.Duration) (*TimeInterval, error) {
 	lower := ti.start.UnixNano()
 	upper := ti.end.Add(-window).UnixNano()
@@ -78,7 +87,11 @@ func (ti *TimeInterval) RandWindow(window time.Duration) (*TimeInterval, error)
 
 	}
 
-	start := lower + rand.Int63n(upper-lower)
+	dist := ti.dist
+	if dist == nil {
+		dist = UniformWindow{}
+	}
+	start := lower + dist.Offset(upper-lower)
 	end := start + window.Nanoseconds()
 
 	x, err := NewTimeInterval(time.Unix(0, start), time.Unix(0, end))
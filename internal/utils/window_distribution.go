@@ -0,0 +1,127 @@
+package utils
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// Names of the supported WindowDistributions
+const (
+	WindowDistUniform           = "uniform"
+	WindowDistExponentialRecent = "exponential-recent"
+	WindowDistZipfChunks        = "zipf-chunks"
+	WindowDistSlidingNow        = "sliding-now"
+)
+
+const (
+	errUnknownWindowDistFmt = "unknown time window distribution '%s'"
+	errBadChunkFmt          = "time window chunk must be > 0, got %v"
+	errBadRecentMeanFmt     = "time window recent mean must be > 0, got %v"
+)
+
+// WindowDistributionChoices returns the names of the supported WindowDistributions.
+func WindowDistributionChoices() []string {
+	return []string{
+		WindowDistUniform,
+		WindowDistExponentialRecent,
+		WindowDistZipfChunks,
+		WindowDistSlidingNow,
+	}
+}
+
+// WindowDistribution decides where the random windows of a TimeInterval start.
+type WindowDistribution interface {
+	// Offset returns the offset, in nanoseconds from the start of the
+	// interval, of a window start. span is the latest possible offset, i.e.
+	// the one where the window ends at the end of the interval.
+	Offset(span int64) int64
+}
+
+// NewWindowDistribution returns the WindowDistribution with the given name.
+// recentMean is used by exponential-recent, chunk and s by zipf-chunks.
+func NewWindowDistribution(name string, recentMean, chunk time.Duration, s float64) (WindowDistribution, error) {
+	switch name {
+	case WindowDistUniform:
+		return UniformWindow{}, nil
+	case WindowDistExponentialRecent:
+		if recentMean <= 0 {
+			return nil, fmt.Errorf(errBadRecentMeanFmt, recentMean)
+		}
+		return &ExponentialRecentWindow{Mean: recentMean}, nil
+	case WindowDistZipfChunks:
+		if chunk <= 0 {
+			return nil, fmt.Errorf(errBadChunkFmt, chunk)
+		}
+		return &ZipfChunksWindow{Chunk: chunk, S: s}, nil
+	case WindowDistSlidingNow:
+		return SlidingNowWindow{}, nil
+	default:
+		return nil, fmt.Errorf(errUnknownWindowDistFmt, name)
+	}
+}
+
+// UniformWindow starts windows uniformly at random over the whole interval.
+type UniformWindow struct{}
+
+// Offset returns a uniformly random offset in [0, span).
+func (UniformWindow) Offset(span int64) int64 {
+	return rand.Int63n(span)
+}
+
+// ExponentialRecentWindow favors the most recent data: the distance of a
+// window from the end of the interval is exponentially distributed with the
+// given Mean.
+type ExponentialRecentWindow struct {
+	Mean time.Duration
+}
+
+// Offset returns an offset whose distance from span is exponentially distributed.
+func (w *ExponentialRecentWindow) Offset(span int64) int64 {
+	back := int64(rand.ExpFloat64() * float64(w.Mean.Nanoseconds()))
+	if back > span {
+		back = span
+	}
+	return span - back
+}
+
+// ZipfChunksWindow splits the interval into chunks of size Chunk, counted
+// back from the end, and picks the chunk a window starts in following a Zipf
+// distribution with exponent S, so the most recent chunk is the hottest one.
+// Within a chunk the window start is uniformly random.
+type ZipfChunksWindow struct {
+	Chunk time.Duration
+	S     float64
+
+	zipfs map[int]*Zipf // per number of chunks
+}
+
+// Offset returns a uniformly random offset within a Zipf distributed chunk.
+func (w *ZipfChunksWindow) Offset(span int64) int64 {
+	chunk := w.Chunk.Nanoseconds()
+	n := int((span + chunk - 1) / chunk)
+	if w.zipfs == nil {
+		w.zipfs = make(map[int]*Zipf)
+	}
+	z, ok := w.zipfs[n]
+	if !ok {
+		z = NewZipf(w.S, n)
+		w.zipfs[n] = z
+	}
+
+	hi := span - int64(z.Rank())*chunk
+	lo := hi - chunk
+	if lo < 0 {
+		lo = 0
+	}
+	return lo + rand.Int63n(hi-lo)
+}
+
+// SlidingNowWindow always places windows at the end of the interval, the
+// way a dashboard showing the last N hours queries the newest data.
+type SlidingNowWindow struct{}
+
+// Offset always returns span.
+func (SlidingNowWindow) Offset(span int64) int64 {
+	return span
+}
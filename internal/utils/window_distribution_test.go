@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestNewWindowDistribution(t *testing.T) {
+	for _, name := range WindowDistributionChoices() {
+		if _, err := NewWindowDistribution(name, time.Hour, time.Hour, 1.1); err != nil {
+			t.Errorf("unexpected error for %s: %v", name, err)
+		}
+	}
+	if _, err := NewWindowDistribution("foo", time.Hour, time.Hour, 1.1); err == nil {
+		t.Errorf("unexpected lack of error for unknown distribution")
+	}
+	if _, err := NewWindowDistribution(WindowDistZipfChunks, time.Hour, 0, 1.1); err == nil {
+		t.Errorf("unexpected lack of error for zero chunk")
+	}
+	if _, err := NewWindowDistribution(WindowDistExponentialRecent, -time.Hour, time.Hour, 1.1); err == nil {
+		t.Errorf("unexpected lack of error for negative recent mean")
+	}
+}
+
+func TestWindowDistributionOffsets(t *testing.T) {
+	const span = int64(100 * time.Hour)
+	const n = 10000
+	rand.Seed(123)
+
+	cases := []struct {
+		desc string
+		dist WindowDistribution
+		// minimum fraction of offsets expected in the last 10% of the span
+		minRecent float64
+		maxRecent float64
+	}{
+		{"uniform", UniformWindow{}, 0.05, 0.15},
+		{"exponential-recent", &ExponentialRecentWindow{Mean: 5 * time.Hour}, 0.8, 1},
+		{"zipf-chunks", &ZipfChunksWindow{Chunk: 10 * time.Hour, S: 1.5}, 0.4, 0.8},
+		{"sliding-now", SlidingNowWindow{}, 1, 1},
+	}
+	for _, c := range cases {
+		recent := 0
+		for i := 0; i < n; i++ {
+			o := c.dist.Offset(span)
+			if o < 0 || o > span {
+				t.Fatalf("%s: offset out of range: %d", c.desc, o)
+			}
+			if o >= span-span/10 {
+				recent++
+			}
+		}
+		frac := float64(recent) / n
+		if frac < c.minRecent || frac > c.maxRecent {
+			t.Errorf("%s: incorrect fraction of recent windows: got %f want [%f, %f]", c.desc, frac, c.minRecent, c.maxRecent)
+		}
+	}
+}
+
+func TestTimeIntervalRandWindowDistribution(t *testing.T) {
+	s := time.Unix(0, 0)
+	e := s.Add(24 * time.Hour)
+	ti, err := NewTimeInterval(s, e)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	ti.SetWindowDistribution(SlidingNowWindow{})
+	x := ti.MustRandWindow(time.Hour)
+	if !x.End().Equal(ti.End()) {
+		t.Errorf("sliding-now window does not end at interval end: got %v want %v", x.End(), ti.End())
+	}
+	if x.Duration() != time.Hour {
+		t.Errorf("incorrect window duration: got %v", x.Duration())
+	}
+}
+
+func TestZipf(t *testing.T) {
+	rand.Seed(123)
+	z := NewZipf(1.0, 10)
+	if z.N() != 10 {
+		t.Fatalf("incorrect number of ranks: got %d", z.N())
+	}
+	counts := make([]int, 10)
+	for i := 0; i < 10000; i++ {
+		r := z.Rank()
+		if r < 0 || r >= 10 {
+			t.Fatalf("rank out of range: %d", r)
+		}
+		counts[r]++
+	}
+	// rank 0 has twice the weight of rank 1 and ten times the weight of rank 9
+	if counts[0] <= counts[1] || counts[1] <= counts[9] {
+		t.Errorf("ranks not zipf distributed: %v", counts)
+	}
+}
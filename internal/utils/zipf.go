@@ -0,0 +1,42 @@
+package utils
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Zipf draws ranks in [0, n) where rank k is picked with a probability
+// proportional to 1/(k+1)^s, i.e. low ranks are the most popular ones.
+// Unlike rand.Zipf it uses the global math/rand source, so the ranks it
+// draws follow the seed set with rand.Seed.
+type Zipf struct {
+	cdf []float64
+}
+
+// NewZipf creates a Zipf for n ranks with exponent s (s > 0).
+func NewZipf(s float64, n int) *Zipf {
+	cdf := make([]float64, n)
+	sum := 0.0
+	for k := 0; k < n; k++ {
+		sum += ZipfWeight(s, k)
+		cdf[k] = sum
+	}
+	return &Zipf{cdf: cdf}
+}
+
+// ZipfWeight returns the unnormalized probability of rank k with exponent s.
+func ZipfWeight(s float64, k int) float64 {
+	return 1 / math.Pow(float64(k+1), s)
+}
+
+// N returns the number of ranks of the Zipf.
+func (z *Zipf) N() int {
+	return len(z.cdf)
+}
+
+// Rank draws a random rank.
+func (z *Zipf) Rank() int {
+	u := rand.Float64() * z.cdf[len(z.cdf)-1]
+	return sort.SearchFloat64s(z.cdf, u)
+}
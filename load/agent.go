@@ -0,0 +1,100 @@
+package load
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/coordination"
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/targets"
+)
+
+// joinCoordinator joins the Coordinator as an agent loading its slice of the
+// data. Only the first agent creates the database.
+func (l *CommonBenchmarkRunner) joinCoordinator() {
+	agent, err := coordination.Join(l.Coordinator, coordination.KindLoad)
+	if err != nil {
+		panic(err)
+	}
+	l.agent = agent
+	l.agentDone = make(chan struct{})
+	fmt.Printf("loading as agent %d/%d of coordinator %s\n", agent.Agent+1, agent.Agents, l.Coordinator)
+	if agent.Agent > 0 {
+		l.DoCreateDB = false
+		l.DoAbortOnExist = false
+	}
+	if l.DoCreateRollups {
+		fmt.Println("rollups are not built when loading as an agent, as the other agents may still be loading")
+		l.DoCreateRollups = false
+	}
+}
+
+// dataSource returns the DataSource of b, reduced to the slice of the agent
+// when loading as one.
+func (l *CommonBenchmarkRunner) dataSource(b targets.Benchmark) targets.DataSource {
+	ds := b.GetDataSource()
+	if l.agent == nil {
+		return ds
+	}
+	return &slicedDataSource{DataSource: ds, slice: l.agent.Assignment}
+}
+
+// reportProgress sends the counters of the agent to the coordinator every
+// period until the load is done.
+func (l *CommonBenchmarkRunner) reportProgress(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-l.agentDone:
+			return
+		case <-ticker.C:
+			// progress is best effort, the result is what counts
+			l.agent.ReportProgress(l.agentCounters())
+		}
+	}
+}
+
+func (l *CommonBenchmarkRunner) agentCounters() map[string]uint64 {
+	return map[string]uint64{
+		coordination.CounterMetrics: atomic.LoadUint64(&l.metricCnt),
+		coordination.CounterRows:    atomic.LoadUint64(&l.rowCnt),
+	}
+}
+
+// reportResult sends the counters of the load, which ran from start to end,
+// to the coordinator.
+func (l *CommonBenchmarkRunner) reportResult(start, end time.Time) {
+	close(l.agentDone)
+	err := l.agent.ReportResult(&coordination.AgentResult{
+		Start:    start,
+		End:      end,
+		Counters: l.agentCounters(),
+	})
+	if err != nil {
+		fatal("%v", err)
+	}
+}
+
+// slicedDataSource passes on the points of a DataSource that belong to the
+// slice of an agent.
+type slicedDataSource struct {
+	targets.DataSource
+	slice coordination.Assignment
+	i     uint64
+}
+
+func (d *slicedDataSource) NextItem() data.LoadedPoint {
+	for {
+		item := d.DataSource.NextItem()
+		if item.Data == nil {
+			return item
+		}
+		i := d.i
+		d.i++
+		if d.slice.InSlice(i) {
+			return item
+		}
+	}
+}
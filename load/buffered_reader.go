@@ -2,7 +2,10 @@ package load
 
 import (
 	"bufio"
+	"io"
 	"os"
+
+	"github.com/timescale/tsbs/internal/compress"
 )
 
 const (
@@ -10,17 +13,23 @@ const (
 )
 
 // GetBufferedReader returns the buffered Reader that should be used by the file loader
-// if no file name is specified a buffer for STDIN is returned
+// if no file name is specified a buffer for STDIN is returned. The data is
+// decompressed if it was compressed with one of the codecs of package compress.
 func GetBufferedReader(fileName string) *bufio.Reader {
-	if len(fileName) == 0 {
-		// Read from STDIN
-		return bufio.NewReaderSize(os.Stdin, defaultReadSize)
+	var in io.Reader = os.Stdin
+	if len(fileName) > 0 {
+		// Read from specified file
+		file, err := os.Open(fileName)
+		if err != nil {
+			fatal("cannot open file for read %s: %v", fileName, err)
+			return nil
+		}
+		in = file
 	}
-	// Read from specified file
-	file, err := os.Open(fileName)
+	r, err := compress.NewReader(in)
 	if err != nil {
-		fatal("cannot open file for read %s: %v", fileName, err)
+		fatal("cannot decompress %s: %v", fileName, err)
 		return nil
 	}
-	return bufio.NewReaderSize(file, defaultReadSize)
+	return bufio.NewReaderSize(r, defaultReadSize)
 }
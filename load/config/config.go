@@ -1,9 +1,12 @@
-package main
+// Package config holds the layout of the config file of the data loaders
+// and its parsing into the load.BenchmarkRunner of a target.
+package config
 
 import (
 	"time"
 )
 
+// LoadConfig is the layout of the yaml config file of tsbs_load.
 type LoadConfig struct {
 	DataSource *DataSourceConfig `yaml:"data-source" mapstructure:"data-source"`
 	Loader     *LoaderConfig     `yaml:"loader"`
@@ -29,6 +32,10 @@ type RunnerConfig struct {
 	InsertIntervals string `yaml:"insert-intervals" mapstructure:"insert-intervals"`
 	FlowControl     bool   `yaml:"flow-control" mapstructure:"flow-control"`
 	ChannelCapacity uint   `yaml:"channel-capacity" mapstructure:"channel-capacity"`
+	DoCreateRollups bool   `yaml:"do-create-rollups" mapstructure:"do-create-rollups"`
+	InsertRate      uint64 `yaml:"insert-rate" mapstructure:"insert-rate"`
+	MetricsAddr     string `yaml:"metrics-addr" mapstructure:"metrics-addr"`
+	Coordinator     string `yaml:"coordinator" mapstructure:"coordinator"`
 }
 
 type DataSourceConfig struct {
@@ -51,4 +58,13 @@ type SimulatorDataSourceConfig struct {
 	Limit                 uint64        `yaml:"max-data-points" mapstructure:"max-data-points"`
 	LogInterval           time.Duration `yaml:"log-interval" mapstructure:"log-interval"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	PodChurnRate          float64       `yaml:"pod-churn-rate" mapstructure:"pod-churn-rate"`
+	LateArrivalRate       float64       `yaml:"late-arrival-rate" mapstructure:"late-arrival-rate"`
+	LateArrivalDelay      time.Duration `yaml:"late-arrival-delay" mapstructure:"late-arrival-delay"`
+	SrcIPZipfS            float64       `yaml:"src-ip-zipf-s" mapstructure:"src-ip-zipf-s"`
+	DstIPZipfS            float64       `yaml:"dst-ip-zipf-s" mapstructure:"dst-ip-zipf-s"`
+	SrcPortCount          int           `yaml:"src-port-count" mapstructure:"src-port-count"`
+	UseCaseSpec           string        `yaml:"use-case-spec" mapstructure:"use-case-spec"`
+	MetricPatterns        string        `yaml:"metric-patterns" mapstructure:"metric-patterns"`
+	SchemaEvolution       string        `yaml:"schema-evolution" mapstructure:"schema-evolution"`
 }
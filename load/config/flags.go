@@ -1,4 +1,4 @@
-package main
+package config
 
 import (
 	"fmt"
@@ -16,7 +16,8 @@ const (
 	defaultScale       = 1
 )
 
-func addLoaderRunnerFlags(fs *pflag.FlagSet) {
+// AddLoaderRunnerFlags adds the flags of the loader.runner object to fs.
+func AddLoaderRunnerFlags(fs *pflag.FlagSet) {
 	fs.String(
 		"loader.runner.insert-intervals",
 		"",
@@ -45,6 +46,14 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 		true,
 		"Whether to create the database. Disable on all but one client if running on a multi client setup.",
 	)
+	fs.Bool(
+		"loader.runner.do-create-rollups",
+		false,
+		"Whether to build the rollups (continuous aggregates, materialized views...) after loading.",
+	)
+	fs.Uint64("loader.runner.insert-rate", 0, "Limit the rate of inserted items per second across all workers, 0 = no limit")
+	fs.String("loader.runner.metrics-addr", "", "Serve live metrics in the Prometheus format on /metrics at this address (e.g. :9100), '' = disabled")
+	fs.String("loader.runner.coordinator", "", "Address of a tsbs_coordinator to load a slice of the data for, together with its other agents. '' = load all the data alone")
 	fs.Uint("loader.runner.workers", 1, "Number of parallel clients inserting")
 	fs.Uint64("loader.runner.limit", 0, "Number of items to insert (0 = all of them).")
 	fs.String("loader.runner.db-name", "benchmark", "Name of database")
@@ -69,7 +78,8 @@ func addLoaderRunnerFlags(fs *pflag.FlagSet) {
 	)
 }
 
-func addDataSourceFlags(fs *pflag.FlagSet) {
+// AddDataSourceFlags adds the flags of the data-source object to fs.
+func AddDataSourceFlags(fs *pflag.FlagSet) {
 	fs.String(
 		"data-source.type",
 		source.SimulatorDataSourceType,
@@ -96,9 +106,75 @@ func addDataSourceFlags(fs *pflag.FlagSet) {
 		100,
 		"Max number of metric fields to generate per host. Used only in devops-generic use-case",
 	)
+	fs.Float64(
+		"data-source.simulator.pod-churn-rate",
+		0.1,
+		"Fraction of the pods replaced by new ones per hour. Used only in k8s use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.late-arrival-rate",
+		0.05,
+		"Fraction of the meters per day whose readings are delivered late. Used only in energy use-case",
+	)
+	fs.Duration(
+		"data-source.simulator.late-arrival-delay",
+		6*time.Hour,
+		"How late the late readings are delivered. Used only in energy use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.src-ip-zipf-s",
+		1.2,
+		"Exponent (> 1) of the Zipf distribution of the source IPs of the flows. Used only in netflow use-case",
+	)
+	fs.Float64(
+		"data-source.simulator.dst-ip-zipf-s",
+		1.5,
+		"Exponent (> 1) of the Zipf distribution of the destination IPs of the flows. Used only in netflow use-case",
+	)
+	fs.Int(
+		"data-source.simulator.src-port-count",
+		100,
+		"Number of source ports each host opens connections from. Used only in netflow use-case",
+	)
+	fs.String(
+		"data-source.simulator.use-case-spec",
+		"",
+		"YAML file describing the tags and measurements to generate. Used only in custom use-case",
+	)
+	fs.String(
+		"data-source.simulator.metric-patterns",
+		"",
+		"Comma-separated patterns to layer over the gauges of the measurements (seasonality, trend, growth, steps, spikes, flatlines). Used only in devops and iot use-cases",
+	)
+	fs.String(
+		"data-source.simulator.schema-evolution",
+		"",
+		"YAML file describing the fields and tags added, dropped, left out or switched to float over time",
+	)
 	fs.Uint64(
 		"data-source.simulator.scale",
 		defaultScale,
 		"Scaling value specific to use case (e.g., devices in 'devops', trucks in iot).")
 	fs.Duration("data-source.simulator.log-interval", defaultLogInterval, "Duration between data points")
 }
+
+// CleanDataSourceFlags returns the flags of fs but the ones of the data source
+// types other than dataSource.
+func CleanDataSourceFlags(dataSource string, fs *pflag.FlagSet) *pflag.FlagSet {
+	var unwantedPrefix string
+	switch dataSource {
+	case source.FileDataSourceType:
+		unwantedPrefix = "data-source.simulator"
+	case source.SimulatorDataSourceType:
+		unwantedPrefix = "data-source.file"
+	default:
+		panic("unsupported data source type: " + dataSource)
+	}
+	reducedFs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if !strings.HasPrefix(f.Name, unwantedPrefix) {
+			reducedFs.AddFlag(f)
+		}
+	})
+	return reducedFs
+}
@@ -1,4 +1,4 @@
-package main
+package config
 
 import (
 	"errors"
@@ -10,7 +10,9 @@ import (
 	"github.com/timescale/tsbs/pkg/targets"
 )
 
-func parseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
+// ParseConfig creates the Benchmark of target and the load.BenchmarkRunner
+// from the data-source and loader objects of the config.
+func ParseConfig(target targets.ImplementedTarget, v *viper.Viper) (targets.Benchmark, load.BenchmarkRunner, error) {
 	dataSourceViper := v.Sub("data-source")
 	if dataSourceViper == nil {
 		return nil, nil, fmt.Errorf("config file didn't have a top-level 'data-source' object")
@@ -74,6 +76,10 @@ func convertRunnerConfigToInternalRep(r *RunnerConfig) *load.BenchmarkRunnerConf
 		InsertIntervals: r.InsertIntervals,
 		NoFlowControl:   !r.FlowControl,
 		ChannelCapacity: r.ChannelCapacity,
+		DoCreateRollups: r.DoCreateRollups,
+		InsertRate:      r.InsertRate,
+		MetricsAddr:     r.MetricsAddr,
+		Coordinator:     r.Coordinator,
 	}
 }
 
@@ -137,6 +143,15 @@ func convertDataSourceConfigToInternalRepresentation(format string, d *DataSourc
 			Limit:                 d.Simulator.Limit,
 			LogInterval:           d.Simulator.LogInterval,
 			MaxMetricCountPerHost: d.Simulator.MaxMetricCountPerHost,
+			PodChurnRate:          d.Simulator.PodChurnRate,
+			LateArrivalRate:       d.Simulator.LateArrivalRate,
+			LateArrivalDelay:      d.Simulator.LateArrivalDelay,
+			SrcIPZipfS:            d.Simulator.SrcIPZipfS,
+			DstIPZipfS:            d.Simulator.DstIPZipfS,
+			SrcPortCount:          d.Simulator.SrcPortCount,
+			UseCaseSpec:           d.Simulator.UseCaseSpec,
+			MetricPatterns:        d.Simulator.MetricPatterns,
+			SchemaEvolution:       d.Simulator.SchemaEvolution,
 			InterleavedNumGroups:  1,
 		}
 	}
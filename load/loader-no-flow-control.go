@@ -27,7 +27,7 @@ func (l *noFlowBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 		go l.work(b, wg, channels[i%numChannels], i)
 	}
 	// Start scan process - actual data read process
-	scanWithoutFlowControl(b.GetDataSource(), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
+	scanWithoutFlowControl(l.dataSource(b), b.GetPointIndexer(numChannels), b.GetBatchFactory(), channels, l.BatchSize, l.Limit)
 	for _, c := range channels {
 		close(c)
 	}
@@ -51,11 +51,14 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// Prepare processor
 	proc := b.GetProcessor()
 	proc.Init(int(workerNum), l.DoLoad, l.HashWorkers)
+	l.metrics.workerStarted()
 
 	// Process batches coming from the incoming queue (c)
 	for batch := range c {
+		l.waitForInsertRate(batch)
 		startedWorkAt := time.Now()
 		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		l.metrics.batchProcessed(startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
 		l.timeToSleep(workerNum, startedWorkAt)
@@ -67,5 +70,6 @@ func (l *noFlowBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 		c.Close(l.DoLoad)
 	}
 
+	l.metrics.workerDone()
 	wg.Done()
 }
@@ -13,6 +13,9 @@ import (
 
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/load/insertstrategy"
+	"github.com/timescale/tsbs/pkg/coordination"
+	"github.com/timescale/tsbs/pkg/metrics"
+	"golang.org/x/time/rate"
 )
 
 const (
@@ -44,6 +47,10 @@ type BenchmarkRunnerConfig struct {
 	ChannelCapacity uint          `yaml:"channel-capacity" mapstructure:"channel-capacity" json:"channel-capacity"`
 	InsertIntervals string        `yaml:"insert-intervals" mapstructure:"insert-intervals" json:"insert-intervals"`
 	ResultsFile     string        `yaml:"results-file" mapstructure:"results-file" json:"results-file"`
+	DoCreateRollups bool          `yaml:"do-create-rollups" mapstructure:"do-create-rollups" json:"do-create-rollups"`
+	InsertRate      uint64        `yaml:"insert-rate" mapstructure:"insert-rate" json:"insert-rate"`
+	MetricsAddr     string        `yaml:"metrics-addr" mapstructure:"metrics-addr" json:"metrics-addr"`
+	Coordinator     string        `yaml:"coordinator" mapstructure:"coordinator" json:"coordinator"`
 	// deprecated, should not be used in other places other than tsbs_load_xx commands
 	FileName string `yaml:"file" mapstructure:"file" json:"file"`
 	Seed     int64  `yaml:"seed" mapstructure:"seed" json:"seed"`
@@ -64,6 +71,10 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.String("insert-intervals", "", "Time to wait between each insert, default '' => all workers insert ASAP. '1,2' = worker 1 waits 1s between inserts, worker 2 and others wait 2s")
 	fs.Bool("hash-workers", false, "Whether to consistently hash insert data to the same workers (i.e., the data for a particular host always goes to the same worker)")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.Uint64("insert-rate", 0, "Limit the rate of inserted items per second across all workers, 0 = no limit")
+	fs.String("metrics-addr", "", "Serve live metrics in the Prometheus format on /metrics at this address (e.g. :9100), '' = disabled")
+	fs.String("coordinator", "", "Address of a tsbs_coordinator to load a slice of the data for, together with its other agents. '' = load all the data alone")
+	fs.Bool("do-create-rollups", false, "Whether to build the rollups (continuous aggregates, materialized views...) after loading. Only supported by some targets.")
 }
 
 type BenchmarkRunner interface {
@@ -79,6 +90,13 @@ type CommonBenchmarkRunner struct {
 	rowCnt         uint64
 	initialRand    *rand.Rand
 	sleepRegulator insertstrategy.SleepRegulator
+	rateLimiter    *rate.Limiter  // limits the items inserted per second, nil for no limit
+	metrics        *loaderMetrics // live metrics, nil unless MetricsAddr is set
+	stopMetrics    func()
+	agent          *coordination.Agent // set when loading as an agent of a coordinator
+	agentDone      chan struct{}
+	dbc            targets.DBCreator
+	rollupTook     time.Duration
 }
 
 // GetBenchmarkRunnerWithBatchSize returns the singleton CommonBenchmarkRunner for use in a benchmark program
@@ -102,6 +120,10 @@ func GetBenchmarkRunner(c BenchmarkRunnerConfig) BenchmarkRunner {
 			panic(fmt.Sprintf("could not initialize BenchmarkRunner: %v", err))
 		}
 	}
+	if c.InsertRate > 0 {
+		// a whole batch must fit in the burst
+		loader.rateLimiter = rate.NewLimiter(rate.Limit(c.InsertRate), int(loader.BatchSize))
+	}
 	if !c.NoFlowControl {
 		return &loader
 	}
@@ -125,8 +147,31 @@ func (l *CommonBenchmarkRunner) DatabaseName() string {
 func (l *CommonBenchmarkRunner) preRun(b targets.Benchmark) (*sync.WaitGroup, *time.Time, func()) {
 	// Create required DB
 	var cleanupFn func()
-	if b.GetDBCreator() != nil {
-		cleanupFn = l.useDBCreator(b.GetDBCreator())
+	if l.Coordinator != "" {
+		l.joinCoordinator()
+	}
+	if l.MetricsAddr != "" {
+		l.metrics = newLoaderMetrics(l)
+		var err error
+		if l.stopMetrics, err = metrics.Serve(l.MetricsAddr, l.metrics.registry); err != nil {
+			panic(err)
+		}
+	}
+	l.dbc = b.GetDBCreator()
+	if l.dbc != nil && (l.agent == nil || l.agent.Agent == 0) {
+		cleanupFn = l.useDBCreator(l.dbc)
+	}
+	if l.agent != nil {
+		// the first agent created the database by the time all agents are ready
+		if err := l.agent.WaitForStart(); err != nil {
+			panic(err)
+		}
+		if l.dbc != nil && l.agent.Agent > 0 {
+			cleanupFn = l.useDBCreator(l.dbc)
+		}
+		if l.ReportingPeriod > 0 {
+			go l.reportProgress(l.ReportingPeriod)
+		}
 	}
 
 	if l.ReportingPeriod.Nanoseconds() > 0 {
@@ -144,11 +189,20 @@ func (l *CommonBenchmarkRunner) postRun(wg *sync.WaitGroup, start *time.Time) {
 	end := time.Now()
 	took := end.Sub(*start)
 	l.summary(took)
+	if l.DoLoad && l.DoCreateRollups {
+		l.createRollups()
+	}
 	if l.BenchmarkRunnerConfig.ResultsFile != "" {
 		metricRate := float64(l.metricCnt) / took.Seconds()
 		rowRate := float64(l.rowCnt) / took.Seconds()
 		l.saveTestResult(took, *start, end, metricRate, rowRate)
 	}
+	if l.agent != nil {
+		l.reportResult(*start, end)
+	}
+	if l.stopMetrics != nil {
+		l.stopMetrics()
+	}
 }
 
 func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, metricRate, rowRate float64) {
@@ -157,6 +211,9 @@ func (l *CommonBenchmarkRunner) saveTestResult(took time.Duration, start time.Ti
 	if l.rowCnt > 0 {
 		totals["rowRate"] = rowRate
 	}
+	if l.rollupTook > 0 {
+		totals["rollupBuildMillis"] = l.rollupTook.Milliseconds()
+	}
 
 	testResult := LoaderTestResult{
 		ResultFormatVersion: LoaderTestResultVersion,
@@ -199,7 +256,7 @@ func (l *CommonBenchmarkRunner) RunBenchmark(b targets.Benchmark) {
 	}
 
 	// Start scan process - actual data read process
-	scanWithFlowControl(channels, l.BatchSize, l.Limit, b.GetDataSource(), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
+	scanWithFlowControl(channels, l.BatchSize, l.Limit, l.dataSource(b), b.GetBatchFactory(), b.GetPointIndexer(uint(len(channels))))
 	// After scan process completed (no more data to come) - begin shutdown process
 
 	// Close all communication channels to/from workers
@@ -260,6 +317,22 @@ func (l *CommonBenchmarkRunner) useDBCreator(dbc targets.DBCreator) func() {
 	return closeFn
 }
 
+// createRollups builds the rollups of the loaded data, if the target's DBCreator
+// supports it, and reports how long the build took
+func (l *CommonBenchmarkRunner) createRollups() {
+	dbcr, ok := l.dbc.(targets.DBCreatorRollup)
+	if !ok {
+		printFn("target does not support rollups, skipping rollup creation\n")
+		return
+	}
+	start := time.Now()
+	if err := dbcr.CreateRollups(l.DBName); err != nil {
+		panic(fmt.Sprintf("could not create rollups: %v", err))
+	}
+	l.rollupTook = time.Since(start)
+	printFn("built rollups in %0.3fsec\n", l.rollupTook.Seconds())
+}
+
 // createChannels create channels from which workers would receive tasks
 func (l *CommonBenchmarkRunner) createChannels(numChannels, capacity uint) []*duplexChannel {
 	// Result - channels to be created
@@ -278,12 +351,15 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 	// Prepare processor
 	proc := b.GetProcessor()
 	proc.Init(int(workerNum), l.DoLoad, l.HashWorkers)
+	l.metrics.workerStarted()
 
 	// Process batches coming from duplexChannel.toWorker queue
 	// and send ACKs into duplexChannel.toScanner queue
 	for batch := range c.toWorker {
+		l.waitForInsertRate(batch)
 		startedWorkAt := time.Now()
 		metricCnt, rowCnt := proc.ProcessBatch(batch, l.DoLoad)
+		l.metrics.batchProcessed(startedWorkAt)
 		atomic.AddUint64(&l.metricCnt, metricCnt)
 		atomic.AddUint64(&l.rowCnt, rowCnt)
 		c.sendToScanner()
@@ -296,9 +372,23 @@ func (l *CommonBenchmarkRunner) work(b targets.Benchmark, wg *sync.WaitGroup, c
 		c.Close(l.DoLoad)
 	}
 
+	l.metrics.workerDone()
 	wg.Done()
 }
 
+// waitForInsertRate blocks until inserting batch keeps the insert rate within
+// the limit, if any
+func (l *CommonBenchmarkRunner) waitForInsertRate(batch targets.Batch) {
+	if l.rateLimiter == nil {
+		return
+	}
+	n := int(batch.Len())
+	if n > l.rateLimiter.Burst() {
+		n = l.rateLimiter.Burst()
+	}
+	time.Sleep(l.rateLimiter.ReserveN(time.Now(), n).Delay())
+}
+
 func (l *CommonBenchmarkRunner) timeToSleep(workerNum uint, startedWorkAt time.Time) {
 	if l.sleepRegulator != nil {
 		l.sleepRegulator.Sleep(int(workerNum), startedWorkAt)
@@ -378,6 +378,30 @@ func TestWorkWithSleep(t *testing.T) {
 	}
 }
 
+func TestWorkWithInsertRate(t *testing.T) {
+	br := GetBenchmarkRunner(BenchmarkRunnerConfig{BatchSize: 10, InsertRate: 100}).(*CommonBenchmarkRunner)
+	b := &testBenchmark{}
+	b.processors = append(b.processors, &testProcessor{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	c := newDuplexChannel(3)
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		c.sendToWorker(&testBatch{len: 10})
+	}
+	go br.work(b, &wg, c, 0)
+	for i := 0; i < 3; i++ {
+		<-c.toScanner
+	}
+	c.close()
+	wg.Wait()
+
+	// the first batch uses up the burst, the next two wait 100ms each
+	if took := time.Since(start); took < 190*time.Millisecond {
+		t.Errorf("insert rate not limited: 30 items at 100 items/sec took %v", took)
+	}
+}
+
 func TestSummary(t *testing.T) {
 	cases := []struct {
 		desc    string
@@ -0,0 +1,51 @@
+package load
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/metrics"
+)
+
+// loaderMetrics are the live metrics of a load, served on MetricsAddr. Their
+// methods do nothing on a nil *loaderMetrics, so the workers call them
+// whether the metrics are enabled or not.
+type loaderMetrics struct {
+	registry      *metrics.Registry
+	batchDuration *metrics.Histogram
+	activeWorkers *metrics.Gauge
+}
+
+func newLoaderMetrics(l *CommonBenchmarkRunner) *loaderMetrics {
+	r := metrics.NewRegistry("tsbs_load_")
+	r.CounterFunc("metrics_total", "Number of metrics loaded.", func() float64 {
+		return float64(atomic.LoadUint64(&l.metricCnt))
+	})
+	r.CounterFunc("rows_total", "Number of rows loaded.", func() float64 {
+		return float64(atomic.LoadUint64(&l.rowCnt))
+	})
+	return &loaderMetrics{
+		registry:      r,
+		batchDuration: r.Histogram("batch_duration_seconds", "Time to insert a batch.", metrics.LatencyBuckets),
+		activeWorkers: r.Gauge("active_workers", "Number of workers inserting data."),
+	}
+}
+
+func (m *loaderMetrics) workerStarted() {
+	if m != nil {
+		m.activeWorkers.Add(1)
+	}
+}
+
+func (m *loaderMetrics) workerDone() {
+	if m != nil {
+		m.activeWorkers.Add(-1)
+	}
+}
+
+// batchProcessed records a batch whose processing started at start.
+func (m *loaderMetrics) batchProcessed(start time.Time) {
+	if m != nil {
+		m.batchDuration.ObserveDuration(time.Since(start))
+	}
+}
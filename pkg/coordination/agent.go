@@ -0,0 +1,78 @@
+package coordination
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Agent is a client process running its slice of a benchmark for a
+// Coordinator.
+type Agent struct {
+	Assignment
+	url  string
+	kind string
+}
+
+// Join joins the coordinator at url (e.g. http://host:8090) as an agent
+// running a kind benchmark.
+func Join(url, kind string) (*Agent, error) {
+	a := &Agent{url: strings.TrimSuffix(url, "/"), kind: kind}
+	if !strings.Contains(a.url, "://") {
+		a.url = "http://" + a.url
+	}
+	if err := a.post("/join", joinRequest{Kind: kind}, &a.Assignment); err != nil {
+		return nil, fmt.Errorf("could not join coordinator %s: %v", url, err)
+	}
+	return a, nil
+}
+
+// WaitForStart tells the coordinator the agent is ready, and blocks until all
+// agents are and the start time has come.
+func (a *Agent) WaitForStart() error {
+	var resp readyResponse
+	if err := a.post("/ready", readyRequest{Agent: a.Agent}, &resp); err != nil {
+		return fmt.Errorf("could not wait for the start: %v", err)
+	}
+	time.Sleep(time.Until(resp.Start))
+	return nil
+}
+
+// ReportProgress sends the counters of the agent so far.
+func (a *Agent) ReportProgress(counters map[string]uint64) error {
+	return a.post("/progress", AgentProgress{Agent: a.Agent, Counters: counters}, nil)
+}
+
+// ReportResult sends the result of the agent once it is done.
+func (a *Agent) ReportResult(r *AgentResult) error {
+	r.Agent = a.Agent
+	r.Kind = a.kind
+	if err := a.post("/result", r, nil); err != nil {
+		return fmt.Errorf("could not report the result: %v", err)
+	}
+	return nil
+}
+
+func (a *Agent) post(path string, body, response interface{}) error {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := http.Post(a.url+path, "application/json", bytes.NewReader(encoded))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	if response == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(response)
+}
@@ -0,0 +1,75 @@
+// Package coordination runs one benchmark from several client processes at
+// once, for databases a single tsbs_load or tsbs_run_queries process cannot
+// saturate. A Coordinator hands every Agent a disjoint slice of the data or
+// query stream, starts them all at the same time and merges their counters
+// and HDR histograms into one result.
+//
+// The agents talk to the coordinator over HTTP with JSON bodies:
+//
+//	POST /join      an agent joins and gets its Assignment
+//	POST /ready     an agent is ready; answers once all agents are, with the start time
+//	POST /progress  an agent reports its counters so far
+//	POST /result    an agent reports its AgentResult once done
+package coordination
+
+import "time"
+
+// Kinds of benchmark an agent runs
+const (
+	KindLoad    = "load"
+	KindQueries = "queries"
+)
+
+// Names of the counters reported by the agents
+const (
+	CounterMetrics  = "metrics"
+	CounterRows     = "rows"
+	CounterQueries  = "queries"
+	CounterErrors   = "errors"
+	CounterTimeouts = "timeouts"
+)
+
+// Assignment is the slice of the data or query stream of an agent: every
+// item (data point or query) whose index modulo Agents is Agent, the same
+// way data and queries are split by the interleaved generation groups.
+type Assignment struct {
+	Agent  uint `json:"agent"`
+	Agents uint `json:"agents"`
+}
+
+// InSlice returns whether the item at index i of the stream belongs to the
+// slice of the agent.
+func (a Assignment) InSlice(i uint64) bool {
+	return i%uint64(a.Agents) == uint64(a.Agent)
+}
+
+type joinRequest struct {
+	Kind string `json:"kind"`
+}
+
+type readyRequest struct {
+	Agent uint `json:"agent"`
+}
+
+type readyResponse struct {
+	Start time.Time `json:"start"`
+}
+
+// AgentProgress are the counters of an agent so far.
+type AgentProgress struct {
+	Agent    uint              `json:"agent"`
+	Counters map[string]uint64 `json:"counters"`
+}
+
+// AgentResult is the result of the run of one agent. Counters are summed
+// and Histograms merged over all agents.
+type AgentResult struct {
+	Agent    uint              `json:"agent"`
+	Kind     string            `json:"kind"`
+	Start    time.Time         `json:"start"`
+	End      time.Time         `json:"end"`
+	Counters map[string]uint64 `json:"counters"`
+	// Histograms are HDR histograms of latencies in microseconds by label,
+	// in the compressed encoding of hdrhistogram.Histogram.Encode
+	Histograms map[string][]byte `json:"histograms,omitempty"`
+}
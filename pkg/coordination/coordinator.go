@@ -0,0 +1,339 @@
+package coordination
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+const mergedResultVersion = "0.1"
+
+// startDelay is how far in the future the agents are told to start, so they
+// all receive the start time before it passes.
+const startDelay = 500 * time.Millisecond
+
+// MergedResult is the result of a benchmark run by all the agents of a
+// Coordinator, written to its results file.
+type MergedResult struct {
+	ResultFormatVersion string `json:"ResultFormatVersion"`
+	Kind                string `json:"Kind"`
+	Agents              uint   `json:"Agents"`
+
+	StartTime      int64 `json:"StartTime"`
+	EndTime        int64 `json:"EndTime"`
+	DurationMillis int64 `json:"DurationMillis"`
+
+	Totals       map[string]interface{} `json:"Totals"`
+	AgentResults []AgentSummary         `json:"AgentResults"`
+}
+
+// AgentSummary is the part of the result of an agent kept in the
+// MergedResult, without its histograms.
+type AgentSummary struct {
+	Agent          uint              `json:"agent"`
+	DurationMillis int64             `json:"durationMillis"`
+	Counters       map[string]uint64 `json:"counters"`
+}
+
+// Coordinator hands out the slices of a benchmark to a fixed number of agents,
+// starts them together and merges their results.
+type Coordinator struct {
+	agents      uint
+	resultsFile string
+
+	mu       sync.Mutex
+	kind     string
+	joined   uint
+	ready    uint
+	start    time.Time
+	started  chan struct{} // closed once all agents are ready
+	progress map[uint]map[string]uint64
+	results  map[uint]*AgentResult
+	done     chan struct{} // closed once all agents reported their result
+
+	srv *http.Server
+}
+
+// NewCoordinator returns a Coordinator waiting for agents agents, which
+// writes the merged result to resultsFile if it is not empty.
+func NewCoordinator(agents uint, resultsFile string) *Coordinator {
+	return &Coordinator{
+		agents:      agents,
+		resultsFile: resultsFile,
+		started:     make(chan struct{}),
+		progress:    map[uint]map[string]uint64{},
+		results:     map[uint]*AgentResult{},
+		done:        make(chan struct{}),
+	}
+}
+
+// Start starts serving the agents on addr, e.g. ":8090", and returns the
+// address it listens on.
+func (c *Coordinator) Start(addr string) (string, error) {
+	if c.agents == 0 {
+		return "", fmt.Errorf("a coordinator needs at least one agent")
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return "", err
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/join", c.handleJoin)
+	mux.HandleFunc("/ready", c.handleReady)
+	mux.HandleFunc("/progress", c.handleProgress)
+	mux.HandleFunc("/result", c.handleResult)
+	c.srv = &http.Server{Handler: mux}
+	go c.srv.Serve(ln)
+	return ln.Addr().String(), nil
+}
+
+// Wait blocks until all agents reported their result, prints the progress of
+// the agents every reportPeriod (if not 0) meanwhile, and returns the merged
+// result once it is saved.
+func (c *Coordinator) Wait(reportPeriod time.Duration) (*MergedResult, error) {
+	if reportPeriod > 0 {
+		go c.report(reportPeriod)
+	}
+	<-c.done
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	c.srv.Shutdown(ctx)
+
+	c.mu.Lock()
+	results := make([]*AgentResult, 0, len(c.results))
+	for _, r := range c.results {
+		results = append(results, r)
+	}
+	c.mu.Unlock()
+
+	merged, err := Merge(results)
+	if err != nil {
+		return nil, err
+	}
+	if c.resultsFile != "" {
+		encoded, err := json.MarshalIndent(merged, "", " ")
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("Saving merged results json file to %s\n", c.resultsFile)
+		if err = ioutil.WriteFile(c.resultsFile, encoded, 0644); err != nil {
+			return nil, err
+		}
+	}
+	return merged, nil
+}
+
+func (c *Coordinator) handleJoin(w http.ResponseWriter, r *http.Request) {
+	var req joinRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.joined == c.agents {
+		http.Error(w, fmt.Sprintf("all %d agents already joined", c.agents), http.StatusConflict)
+		return
+	}
+	if c.kind != "" && req.Kind != c.kind {
+		http.Error(w, fmt.Sprintf("agent runs %s but the other agents run %s", req.Kind, c.kind), http.StatusConflict)
+		return
+	}
+	c.kind = req.Kind
+	a := Assignment{Agent: c.joined, Agents: c.agents}
+	c.joined++
+	fmt.Printf("agent %d/%d joined (%s)\n", a.Agent+1, a.Agents, r.RemoteAddr)
+	json.NewEncoder(w).Encode(a)
+}
+
+// handleReady answers once all agents are ready, with the time to start at.
+func (c *Coordinator) handleReady(w http.ResponseWriter, r *http.Request) {
+	var req readyRequest
+	if !decodeRequest(w, r, &req) {
+		return
+	}
+	c.mu.Lock()
+	c.ready++
+	if c.ready == c.agents {
+		c.start = time.Now().Add(startDelay)
+		fmt.Printf("all %d agents ready, starting\n", c.agents)
+		close(c.started)
+	}
+	c.mu.Unlock()
+
+	select {
+	case <-c.started:
+	case <-r.Context().Done():
+		return
+	}
+	json.NewEncoder(w).Encode(readyResponse{Start: c.start})
+}
+
+func (c *Coordinator) handleProgress(w http.ResponseWriter, r *http.Request) {
+	var p AgentProgress
+	if !decodeRequest(w, r, &p) {
+		return
+	}
+	c.mu.Lock()
+	c.progress[p.Agent] = p.Counters
+	c.mu.Unlock()
+}
+
+func (c *Coordinator) handleResult(w http.ResponseWriter, r *http.Request) {
+	var res AgentResult
+	if !decodeRequest(w, r, &res) {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.results[res.Agent]; ok || res.Agent >= c.agents {
+		http.Error(w, fmt.Sprintf("unexpected result of agent %d", res.Agent), http.StatusConflict)
+		return
+	}
+	c.results[res.Agent] = &res
+	c.progress[res.Agent] = res.Counters
+	fmt.Printf("agent %d/%d done\n", res.Agent+1, c.agents)
+	if uint(len(c.results)) == c.agents {
+		close(c.done)
+	}
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, v interface{}) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "POST only", http.StatusMethodNotAllowed)
+		return false
+	}
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// report prints the counters summed over all agents every period.
+func (c *Coordinator) report(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.done:
+			return
+		case now := <-ticker.C:
+			c.mu.Lock()
+			sums := map[string]uint64{}
+			for _, counters := range c.progress {
+				for k, v := range counters {
+					sums[k] += v
+				}
+			}
+			c.mu.Unlock()
+			if len(sums) == 0 {
+				continue
+			}
+			keys := make([]string, 0, len(sums))
+			for k := range sums {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			line := fmt.Sprintf("%d", now.Unix())
+			for _, k := range keys {
+				line += fmt.Sprintf(",%s=%d", k, sums[k])
+			}
+			fmt.Println(line)
+		}
+	}
+}
+
+// Merge merges the results of all the agents of a run.
+func Merge(results []*AgentResult) (*MergedResult, error) {
+	if len(results) == 0 {
+		return nil, fmt.Errorf("no agent results to merge")
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Agent < results[j].Agent })
+
+	m := &MergedResult{
+		ResultFormatVersion: mergedResultVersion,
+		Kind:                results[0].Kind,
+		Agents:              uint(len(results)),
+	}
+	start, end := results[0].Start, results[0].End
+	counters := map[string]uint64{}
+	histograms := map[string]*hdrhistogram.Histogram{}
+	for _, r := range results {
+		if r.Start.Before(start) {
+			start = r.Start
+		}
+		if r.End.After(end) {
+			end = r.End
+		}
+		for k, v := range r.Counters {
+			counters[k] += v
+		}
+		for label, encoded := range r.Histograms {
+			h, err := hdrhistogram.Decode(encoded)
+			if err != nil {
+				return nil, fmt.Errorf("could not decode histogram %s of agent %d: %v", label, r.Agent, err)
+			}
+			if merged, ok := histograms[label]; ok {
+				merged.Merge(h)
+			} else {
+				histograms[label] = h
+			}
+		}
+		m.AgentResults = append(m.AgentResults, AgentSummary{
+			Agent:          r.Agent,
+			DurationMillis: r.End.Sub(r.Start).Milliseconds(),
+			Counters:       r.Counters,
+		})
+	}
+	took := end.Sub(start)
+	m.StartTime = start.Unix()
+	m.EndTime = end.Unix()
+	m.DurationMillis = took.Milliseconds()
+
+	totals := map[string]interface{}{}
+	for k, v := range counters {
+		totals[k] = v
+	}
+	switch m.Kind {
+	case KindLoad:
+		totals["metricRate"] = float64(counters[CounterMetrics]) / took.Seconds()
+		if counters[CounterRows] > 0 {
+			totals["rowRate"] = float64(counters[CounterRows]) / took.Seconds()
+		}
+	case KindQueries:
+		totals["queryRate"] = float64(counters[CounterQueries]) / took.Seconds()
+	}
+	if len(histograms) > 0 {
+		quantiles := map[string]interface{}{}
+		rates := map[string]interface{}{}
+		for label, h := range histograms {
+			quantiles[label] = quantileMap(h)
+			rates[label] = float64(h.TotalCount()) / took.Seconds()
+		}
+		totals["overallQuantiles"] = quantiles
+		totals["overallQueryRates"] = rates
+	}
+	m.Totals = totals
+	return m, nil
+}
+
+// quantileMap returns the quantiles of h, in milliseconds, named like in the
+// results of the query runners.
+func quantileMap(h *hdrhistogram.Histogram) map[string]float64 {
+	q := map[string]float64{"q0": 0, "q50": 0, "q95": 0, "q99": 0, "q999": 0, "q100": 0}
+	if h.TotalCount() == 0 {
+		return q
+	}
+	for name, quantile := range map[string]float64{"q0": 0, "q50": 50, "q95": 95, "q99": 99, "q999": 99.9, "q100": 100} {
+		q[name] = float64(h.ValueAtQuantile(quantile)) / 1e3
+	}
+	return q
+}
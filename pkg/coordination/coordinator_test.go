@@ -0,0 +1,112 @@
+package coordination
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+)
+
+func TestAssignmentInSlice(t *testing.T) {
+	agents := []Assignment{{Agent: 0, Agents: 3}, {Agent: 1, Agents: 3}, {Agent: 2, Agents: 3}}
+	for i := uint64(0); i < 30; i++ {
+		in := 0
+		for _, a := range agents {
+			if a.InSlice(i) {
+				in++
+			}
+		}
+		if in != 1 {
+			t.Errorf("item %d is in %d slices, want 1", i, in)
+		}
+	}
+}
+
+func TestCoordinatorRun(t *testing.T) {
+	const agents = 2
+	c := NewCoordinator(agents, "")
+	addr, err := c.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, agents)
+	for i := 0; i < agents; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			a, err := Join(addr, KindQueries)
+			if err != nil {
+				errs <- err
+				return
+			}
+			if err = a.WaitForStart(); err != nil {
+				errs <- err
+				return
+			}
+			start := time.Now()
+			h := hdrhistogram.New(1, 3600000000, 4)
+			for v := int64(1); v <= 100; v++ {
+				h.RecordValue(v * 1000 * int64(a.Agent+1))
+			}
+			encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+			if err != nil {
+				errs <- err
+				return
+			}
+			errs <- a.ReportResult(&AgentResult{
+				Start:      start,
+				End:        start.Add(time.Second),
+				Counters:   map[string]uint64{CounterQueries: 100, CounterErrors: uint64(a.Agent)},
+				Histograms: map[string][]byte{"all_queries": encoded},
+			})
+		}()
+	}
+
+	m, err := c.Wait(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("agent failed: %v", err)
+		}
+	}
+
+	if m.Agents != agents || m.Kind != KindQueries || len(m.AgentResults) != agents {
+		t.Fatalf("incorrect merged result: %+v", m)
+	}
+	if got := m.Totals[CounterQueries]; got != uint64(200) {
+		t.Errorf("incorrect queries: got %v want 200", got)
+	}
+	if got := m.Totals[CounterErrors]; got != uint64(1) {
+		t.Errorf("incorrect errors: got %v want 1", got)
+	}
+	q := m.Totals["overallQuantiles"].(map[string]interface{})["all_queries"].(map[string]float64)
+	// agent 1 recorded 1, 2, .., 100ms, agent 2 2, 4, .., 200ms: 100 values are <= 67ms
+	if q["q100"] < 199.9 || q["q100"] > 200.1 {
+		t.Errorf("incorrect merged max: got %f want 200", q["q100"])
+	}
+	if q["q50"] < 66.9 || q["q50"] > 67.1 {
+		t.Errorf("incorrect merged median: got %f want 67", q["q50"])
+	}
+}
+
+func TestJoinTooManyAgents(t *testing.T) {
+	c := NewCoordinator(1, "")
+	addr, err := c.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.srv.Close()
+	if _, err = Join(addr, KindLoad); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = Join(addr, KindLoad); err == nil {
+		t.Errorf("an agent joined a full coordinator")
+	}
+}
@@ -16,12 +16,14 @@ var (
 	TestColFloat    = []byte("usage_guest_nice")
 	TestColInt      = []byte("usage_guest")
 	TestColInt64    = []byte("big_usage_guest")
+	TestColString   = []byte("message")
 )
 
 const (
 	TestFloat             = float64(38.24311829)
 	TestInt               = 38
 	TestInt64             = int64(5000000000)
+	TestString            = `key "cart" not found`
 	ErrWriterAlwaysErr    = "bad write: I always error"
 	ErrWriterSometimesErr = "bad write: I sometimes error"
 )
@@ -81,6 +83,11 @@ func TestPointWithNilTag() *data.Point {
 		[][]byte{TestColFloat}, []interface{}{TestFloat})
 }
 
+func TestPointStringField() *data.Point {
+	return generateTestPoint(TestMeasurement, TestTagKeys, TestTagVals, &TestNow,
+		[][]byte{TestColString, TestColFloat}, []interface{}{TestString, TestFloat})
+}
+
 func TestPointWithNilField() *data.Point {
 	return generateTestPoint(TestMeasurement, [][]byte{}, []interface{}{}, &TestNow,
 		[][]byte{TestColInt64, TestColFloat}, []interface{}{nil, TestFloat})
@@ -34,3 +34,27 @@ func FastFormatAppend(v interface{}, buf []byte) []byte {
 		panic(fmt.Sprintf("unknown field type for %#v", v))
 	}
 }
+
+// QuotedFormatAppend appends v to buf like FastFormatAppend, but with string
+// and []byte values in double quotes, escaping the double quotes and
+// backslashes they contain, as string fields are written in the InfluxDB line
+// protocol.
+func QuotedFormatAppend(v interface{}, buf []byte) []byte {
+	var s []byte
+	switch v := v.(type) {
+	case string:
+		s = []byte(v)
+	case []byte:
+		s = v
+	default:
+		return FastFormatAppend(v, buf)
+	}
+	buf = append(buf, '"')
+	for _, c := range s {
+		if c == '"' || c == '\\' {
+			buf = append(buf, '\\')
+		}
+		buf = append(buf, c)
+	}
+	return append(buf, '"')
+}
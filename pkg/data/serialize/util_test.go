@@ -90,3 +90,39 @@ func TestFastFormatAppend(t *testing.T) {
 		}
 	}
 }
+
+func TestQuotedFormatAppend(t *testing.T) {
+	cases := []struct {
+		desc   string
+		input  interface{}
+		output string
+	}{
+		{
+			desc:   "a float64 is not quoted",
+			input:  float64(29.37),
+			output: "values,29.37",
+		},
+		{
+			desc:   "a string is quoted",
+			input:  "GET /api/cart 200",
+			output: `values,"GET /api/cart 200"`,
+		},
+		{
+			desc:   "a byte string is quoted",
+			input:  []byte("bytestring"),
+			output: `values,"bytestring"`,
+		},
+		{
+			desc:   "double quotes and backslashes are escaped",
+			input:  `key "a\b" not found`,
+			output: `values,"key \"a\\b\" not found"`,
+		},
+	}
+
+	for _, c := range cases {
+		got := QuotedFormatAppend(c.input, []byte("values,"))
+		if string(got) != c.output {
+			t.Errorf("%s \nOutput incorrect: Want: %s Got: %s", c.desc, c.output, got)
+		}
+	}
+}
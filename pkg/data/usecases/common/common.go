@@ -25,6 +25,11 @@ const (
 	UseCaseIoT           = "iot"
 	UseCaseDevopsGeneric = "devops-generic"
 	UseCaseFinance       = "finance"
+	UseCaseK8s           = "k8s"
+	UseCaseEnergy        = "energy"
+	UseCaseEvents        = "events"
+	UseCaseNetflow       = "netflow"
+	UseCaseCustom        = "custom"
 )
 
 var UseCaseChoices = []string{
@@ -34,4 +39,9 @@ var UseCaseChoices = []string{
 	UseCaseIoT,
 	UseCaseDevopsGeneric,
 	UseCaseFinance,
+	UseCaseK8s,
+	UseCaseEnergy,
+	UseCaseEvents,
+	UseCaseNetflow,
+	UseCaseCustom,
 }
@@ -16,6 +16,7 @@ type NormalDistribution struct {
 	Mean   float64
 	StdDev float64
 
+	rng   *rand.Rand // nil = the global source
 	value float64
 }
 
@@ -30,7 +31,7 @@ func ND(mean, stddev float64) *NormalDistribution {
 // Advance advances this distribution. Since the distribution is
 // stateless, this just overwrites the internal cache value.
 func (d *NormalDistribution) Advance() {
-	d.value = rand.NormFloat64()*d.StdDev + d.Mean
+	d.value = normFloat64(d.rng)*d.StdDev + d.Mean
 }
 
 // Get returns the last computed value for this distribution.
@@ -43,6 +44,7 @@ type UniformDistribution struct {
 	Low  float64
 	High float64
 
+	rng   *rand.Rand // nil = the global source
 	value float64
 }
 
@@ -57,7 +59,7 @@ func UD(low, high float64) *UniformDistribution {
 // Advance advances this distribution. Since the distribution is
 // stateless, this just overwrites the internal cache value.
 func (d *UniformDistribution) Advance() {
-	x := rand.Float64() // uniform
+	x := float64From(d.rng) // uniform
 	x *= d.High - d.Low
 	x += d.Low
 	d.value = x
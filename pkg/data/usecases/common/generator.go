@@ -24,6 +24,14 @@ type Tag struct {
 
 const (
 	errMaxMetricCountValue = "max metric count per host has to be greater than 0"
+	errPodChurnRateValue   = "pod churn rate cannot be negative"
+	errLateArrivalValue    = "late arrival rate and delay cannot be negative"
+	errZipfSValue          = "zipf exponents of the source and destination IPs have to be greater than 1"
+	errSrcPortCountValue   = "source port count has to be greater than 0"
+	errNoUseCaseSpec       = "custom use case requires a use case spec file"
+	errShardFilesWorkers   = "shard files require workers"
+	errShardFilesNoFile    = "shard files require an output file"
+	errShardFilesLimit     = "shard files cannot be combined with a max number of data points or interleaved generation groups"
 	errLogIntervalZero     = "cannot have log interval of 0"
 	defaultLogInterval     = 10 * time.Second
 )
@@ -40,6 +48,17 @@ type DataGeneratorConfig struct {
 	InterleavedGroupID    uint          `yaml:"interleaved-generation-group-id" mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups  uint          `yaml:"interleaved-generation-groups" mapstructure:"interleaved-generation-groups"`
 	MaxMetricCountPerHost uint64        `yaml:"max-metric-count" mapstructure:"max-metric-count"`
+	PodChurnRate          float64       `yaml:"pod-churn-rate" mapstructure:"pod-churn-rate"`
+	LateArrivalRate       float64       `yaml:"late-arrival-rate" mapstructure:"late-arrival-rate"`
+	LateArrivalDelay      time.Duration `yaml:"late-arrival-delay" mapstructure:"late-arrival-delay"`
+	SrcIPZipfS            float64       `yaml:"src-ip-zipf-s" mapstructure:"src-ip-zipf-s"`
+	DstIPZipfS            float64       `yaml:"dst-ip-zipf-s" mapstructure:"dst-ip-zipf-s"`
+	SrcPortCount          int           `yaml:"src-port-count" mapstructure:"src-port-count"`
+	UseCaseSpec           string        `yaml:"use-case-spec" mapstructure:"use-case-spec"`
+	MetricPatterns        string        `yaml:"metric-patterns" mapstructure:"metric-patterns"`
+	Workers               uint          `yaml:"workers" mapstructure:"workers"`
+	ShardFiles            bool          `yaml:"shard-files" mapstructure:"shard-files"`
+	SchemaEvolution       string        `yaml:"schema-evolution" mapstructure:"schema-evolution"`
 }
 
 // Validate checks that the values of the DataGeneratorConfig are reasonable.
@@ -63,6 +82,43 @@ func (c *DataGeneratorConfig) Validate() error {
 		return fmt.Errorf(errMaxMetricCountValue)
 	}
 
+	if c.PodChurnRate < 0 {
+		return fmt.Errorf(errPodChurnRateValue)
+	}
+
+	if c.LateArrivalRate < 0 || c.LateArrivalDelay < 0 {
+		return fmt.Errorf(errLateArrivalValue)
+	}
+
+	if c.Use == UseCaseNetflow {
+		if c.SrcIPZipfS <= 1 || c.DstIPZipfS <= 1 {
+			return fmt.Errorf(errZipfSValue)
+		}
+		if c.SrcPortCount < 1 {
+			return fmt.Errorf(errSrcPortCountValue)
+		}
+	}
+
+	if c.Use == UseCaseCustom && c.UseCaseSpec == "" {
+		return fmt.Errorf(errNoUseCaseSpec)
+	}
+
+	if _, err := ParseMetricPatterns(c.MetricPatterns); err != nil {
+		return err
+	}
+
+	if c.ShardFiles {
+		if c.Workers == 0 {
+			return fmt.Errorf(errShardFilesWorkers)
+		}
+		if c.File == "" {
+			return fmt.Errorf(errShardFilesNoFile)
+		}
+		if c.Limit > 0 || c.InterleavedNumGroups > 1 {
+			return fmt.Errorf(errShardFilesLimit)
+		}
+	}
+
 	return err
 }
 
@@ -77,6 +133,17 @@ func (c *DataGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Uint("interleaved-generation-groups", 1,
 		"The number of round-robin serialization groups. Use this to scale up data generation to multiple processes.")
 	fs.Uint64("max-metric-count", 100, "Max number of metric fields to generate per host. Used only in devops-generic use-case")
+	fs.Float64("pod-churn-rate", 0.1, "Fraction of the pods replaced by new ones per hour. Used only in k8s use-case")
+	fs.Float64("late-arrival-rate", 0.05, "Fraction of the meters per day whose readings are delivered late. Used only in energy use-case")
+	fs.Duration("late-arrival-delay", 6*time.Hour, "How late the late readings are delivered. Used only in energy use-case")
+	fs.Float64("src-ip-zipf-s", 1.2, "Exponent (> 1) of the Zipf distribution of the source IPs of the flows. Used only in netflow use-case")
+	fs.Float64("dst-ip-zipf-s", 1.5, "Exponent (> 1) of the Zipf distribution of the destination IPs of the flows. Used only in netflow use-case")
+	fs.Int("src-port-count", 100, "Number of source ports each host opens connections from. Used only in netflow use-case")
+	fs.String("use-case-spec", "", "YAML file describing the tags and measurements to generate. Used only in custom use-case")
+	fs.String("metric-patterns", "", fmt.Sprintf("Comma-separated patterns to layer over the gauges of the measurements (choices: %s). Used only in devops and iot use-cases", strings.Join(PatternChoices, ", ")))
+	fs.Uint("workers", 0, "Number of workers simulating disjoint sets of hosts in parallel, 0 = a single simulator. The output is the same for any number of workers, but differs from a single simulator's. Used only in devops, cpu-only, cpu-single and devops-generic use-cases")
+	fs.Bool("shard-files", false, "Write the points of each worker to <file>.<worker> instead of merging them in time order")
+	fs.String("schema-evolution", "", "YAML file describing the fields and tags added, dropped, left out or switched to float over time")
 }
 
 const defaultTimeStart = "2016-01-01T00:00:00Z"
@@ -126,7 +193,7 @@ func (c *BaseConfig) AddToFlagSet(fs *pflag.FlagSet) {
 
 	fs.Int64("seed", 0, "PRNG seed (default: 0, which uses the current timestamp)")
 	fs.Int("debug", 0, "Control level of debug output")
-	fs.String("file", "", "Write the output to this path")
+	fs.String("file", "", "Write the output to this path, compressed if it ends with .gz, .zst or .lz4")
 }
 
 func (c *BaseConfig) Validate() error {
@@ -1,8 +1,10 @@
 package common
 
 import (
-	"github.com/timescale/tsbs/pkg/data"
+	"math/rand"
 	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
 )
 
 // SubsystemMeasurement represents a collection of measurement distributions and a start time.
@@ -33,10 +35,28 @@ func NewSubsystemMeasurementWithDistributionMakers(start time.Time, makers []Lab
 func (m *SubsystemMeasurement) Tick(d time.Duration) {
 	m.Timestamp = m.Timestamp.Add(d)
 	for i := range m.Distributions {
+		setTime(m.Distributions[i], m.Timestamp)
 		m.Distributions[i].Advance()
 	}
 }
 
+// applyPatterns layers the patterns over the gauges of the
+// SubsystemMeasurement, drawing from r.
+func (m *SubsystemMeasurement) applyPatterns(p *MetricPatterns, r *rand.Rand) {
+	for i := range m.Distributions {
+		m.Distributions[i] = p.Apply(m.Distributions[i], r)
+		setTime(m.Distributions[i], m.Timestamp)
+	}
+}
+
+// bindRand makes the distributions of the SubsystemMeasurement draw their
+// random numbers from r.
+func (m *SubsystemMeasurement) bindRand(r *rand.Rand) {
+	for i := range m.Distributions {
+		m.Distributions[i] = WithRand(m.Distributions[i], r)
+	}
+}
+
 // ToPoint fills the provided serialize.Point with measurements from the SubsystemMeasurement.
 func (m *SubsystemMeasurement) ToPoint(p *data.Point, measurementName []byte, labels []LabeledDistributionMaker) {
 	p.SetMeasurementName(measurementName)
@@ -0,0 +1,315 @@
+package common
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// TimedDistribution is a Distribution whose values depend on the simulated
+// time, which is set before every Advance.
+type TimedDistribution interface {
+	Distribution
+	SetTime(time.Time)
+}
+
+// setTime sets the simulated time of d if it depends on it.
+func setTime(d Distribution, t time.Time) {
+	if td, ok := d.(TimedDistribution); ok {
+		td.SetTime(t)
+	}
+}
+
+// clock keeps the simulated time of a TimedDistribution, and the time of its
+// last Advance.
+type clock struct {
+	now  time.Time
+	last time.Time
+}
+
+// elapsed returns the time elapsed since the last call, 0 on the first one.
+func (c *clock) elapsed() time.Duration {
+	if c.last.IsZero() {
+		c.last = c.now
+	}
+	d := c.now.Sub(c.last)
+	c.last = c.now
+	return d
+}
+
+// poissonFires tells whether an event of a Poisson process of rate events per
+// hour happens within d, drawing from r or the global source if nil.
+func poissonFires(r *rand.Rand, rate float64, d time.Duration) bool {
+	return float64From(r) < 1-math.Exp(-rate*d.Hours())
+}
+
+// SeasonalDistribution adds a sinusoidal cycle of the simulated time to an
+// underlying distribution, e.g. a daily or weekly cycle. The cycles start at
+// the Unix epoch, shifted by Phase radians.
+type SeasonalDistribution struct {
+	Base      Distribution
+	Amplitude float64
+	Period    time.Duration
+	Phase     float64
+
+	now time.Time
+}
+
+// SD creates a new SeasonalDistribution over base with the given amplitude,
+// period and phase
+func SD(base Distribution, amplitude float64, period time.Duration, phase float64) *SeasonalDistribution {
+	return &SeasonalDistribution{
+		Base:      base,
+		Amplitude: amplitude,
+		Period:    period,
+		Phase:     phase,
+	}
+}
+
+// SetTime sets the simulated time of the distribution.
+func (d *SeasonalDistribution) SetTime(t time.Time) {
+	d.now = t
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution.
+func (d *SeasonalDistribution) Advance() {
+	d.Base.Advance()
+}
+
+// Get returns the value of the underlying distribution plus the cycle at the
+// simulated time.
+func (d *SeasonalDistribution) Get() float64 {
+	cycle := float64(d.now.UnixNano()%int64(d.Period)) / float64(d.Period)
+	return d.Base.Get() + d.Amplitude*math.Sin(2*math.Pi*cycle+d.Phase)
+}
+
+// TrendDistribution adds a trend to an underlying distribution: its value is
+// multiplied by e^(Growth*h) and Slope*h is added to it, h being the hours
+// elapsed since the first simulated time.
+type TrendDistribution struct {
+	Base   Distribution
+	Slope  float64
+	Growth float64
+
+	start time.Time
+	now   time.Time
+}
+
+// LTD creates a new TrendDistribution over base growing linearly by slope per
+// hour
+func LTD(base Distribution, slope float64) *TrendDistribution {
+	return &TrendDistribution{
+		Base:  base,
+		Slope: slope,
+	}
+}
+
+// ETD creates a new TrendDistribution over base growing exponentially at rate
+// per hour
+func ETD(base Distribution, rate float64) *TrendDistribution {
+	return &TrendDistribution{
+		Base:   base,
+		Growth: rate,
+	}
+}
+
+// SetTime sets the simulated time of the distribution, the first one being
+// the start of the trend.
+func (d *TrendDistribution) SetTime(t time.Time) {
+	if d.start.IsZero() {
+		d.start = t
+	}
+	d.now = t
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution.
+func (d *TrendDistribution) Advance() {
+	d.Base.Advance()
+}
+
+// Get returns the value of the underlying distribution with the trend at the
+// simulated time.
+func (d *TrendDistribution) Get() float64 {
+	h := d.now.Sub(d.start).Hours()
+	return d.Base.Get()*math.Exp(d.Growth*h) + d.Slope*h
+}
+
+// SpikeDistribution adds spikes to an underlying distribution. Spikes start
+// as a Poisson process of Rate spikes per hour, with a height drawn from
+// Magnitude, and decay exponentially with a half-life of HalfLife.
+type SpikeDistribution struct {
+	Base      Distribution
+	Rate      float64
+	Magnitude Distribution
+	HalfLife  time.Duration
+
+	clock
+	rng   *rand.Rand
+	spike float64
+}
+
+// SPD creates a new SpikeDistribution over base with the given rate per hour,
+// magnitude and half-life
+func SPD(base Distribution, rate float64, magnitude Distribution, halfLife time.Duration) *SpikeDistribution {
+	return &SpikeDistribution{
+		Base:      base,
+		Rate:      rate,
+		Magnitude: magnitude,
+		HalfLife:  halfLife,
+	}
+}
+
+// SetTime sets the simulated time of the distribution.
+func (d *SpikeDistribution) SetTime(t time.Time) {
+	d.now = t
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution, decays the current spike and
+// starts a new one now and then.
+func (d *SpikeDistribution) Advance() {
+	d.Base.Advance()
+	elapsed := d.elapsed()
+	d.spike *= math.Pow(0.5, float64(elapsed)/float64(d.HalfLife))
+	if poissonFires(d.rng, d.Rate, elapsed) {
+		d.Magnitude.Advance()
+		d.spike += d.Magnitude.Get()
+	}
+}
+
+// Get returns the value of the underlying distribution plus the current
+// spike.
+func (d *SpikeDistribution) Get() float64 {
+	return d.Base.Get() + d.spike
+}
+
+// StepChangeDistribution adds level shifts to an underlying distribution.
+// Shifts happen as a Poisson process of Rate shifts per hour, each moving
+// the level by a value drawn from Step.
+type StepChangeDistribution struct {
+	Base Distribution
+	Rate float64
+	Step Distribution
+
+	clock
+	rng   *rand.Rand
+	level float64
+}
+
+// SCD creates a new StepChangeDistribution over base with the given rate per
+// hour and step
+func SCD(base Distribution, rate float64, step Distribution) *StepChangeDistribution {
+	return &StepChangeDistribution{
+		Base: base,
+		Rate: rate,
+		Step: step,
+	}
+}
+
+// SetTime sets the simulated time of the distribution.
+func (d *StepChangeDistribution) SetTime(t time.Time) {
+	d.now = t
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution, and shifts the level now and
+// then.
+func (d *StepChangeDistribution) Advance() {
+	d.Base.Advance()
+	if poissonFires(d.rng, d.Rate, d.elapsed()) {
+		d.Step.Advance()
+		d.level += d.Step.Get()
+	}
+}
+
+// Get returns the value of the underlying distribution plus the level.
+func (d *StepChangeDistribution) Get() float64 {
+	return d.Base.Get() + d.level
+}
+
+// FlatlineDistribution freezes the value of an underlying distribution now
+// and then, as a stuck sensor would. Flatlines start as a Poisson process of
+// Rate flatlines per hour and last Duration, the underlying distribution
+// advancing in the meantime.
+type FlatlineDistribution struct {
+	Base     Distribution
+	Rate     float64
+	Duration time.Duration
+
+	clock
+	rng   *rand.Rand
+	until time.Time
+	value float64
+}
+
+// FLD creates a new FlatlineDistribution over base with the given rate per
+// hour and duration
+func FLD(base Distribution, rate float64, duration time.Duration) *FlatlineDistribution {
+	return &FlatlineDistribution{
+		Base:     base,
+		Rate:     rate,
+		Duration: duration,
+	}
+}
+
+// SetTime sets the simulated time of the distribution.
+func (d *FlatlineDistribution) SetTime(t time.Time) {
+	d.now = t
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution, and starts a flatline at its
+// current value now and then.
+func (d *FlatlineDistribution) Advance() {
+	d.Base.Advance()
+	elapsed := d.elapsed()
+	if d.now.Before(d.until) {
+		return
+	}
+	if poissonFires(d.rng, d.Rate, elapsed) {
+		d.until = d.now.Add(d.Duration)
+		d.value = d.Base.Get()
+	}
+}
+
+// Get returns the frozen value during a flatline, the value of the
+// underlying distribution otherwise.
+func (d *FlatlineDistribution) Get() float64 {
+	if d.now.Before(d.until) {
+		return d.value
+	}
+	return d.Base.Get()
+}
+
+// ClampedDistribution bounds the values of an underlying distribution.
+type ClampedDistribution struct {
+	Base Distribution
+	Min  float64
+	Max  float64
+}
+
+// CD creates a new ClampedDistribution bounding base to [min, max]
+func CD(base Distribution, min, max float64) *ClampedDistribution {
+	return &ClampedDistribution{
+		Base: base,
+		Min:  min,
+		Max:  max,
+	}
+}
+
+// SetTime sets the simulated time of the underlying distribution.
+func (d *ClampedDistribution) SetTime(t time.Time) {
+	setTime(d.Base, t)
+}
+
+// Advance advances the underlying distribution.
+func (d *ClampedDistribution) Advance() {
+	d.Base.Advance()
+}
+
+// Get returns the value of the underlying distribution, clamped.
+func (d *ClampedDistribution) Get() float64 {
+	return math.Max(d.Min, math.Min(d.Max, d.Base.Get()))
+}
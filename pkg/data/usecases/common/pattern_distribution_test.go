@@ -0,0 +1,131 @@
+package common
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+)
+
+var testPatternStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// advanceAt sets the time of d to start + i*step and advances it, for each
+// of n steps, and returns the values.
+func advanceAt(d TimedDistribution, step time.Duration, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		d.SetTime(testPatternStart.Add(time.Duration(i) * step))
+		d.Advance()
+		values[i] = d.Get()
+	}
+	return values
+}
+
+func TestSeasonalDistribution(t *testing.T) {
+	d := SD(&ConstantDistribution{State: 10}, 5, 24*time.Hour, -math.Pi/2)
+	values := advanceAt(d, 6*time.Hour, 5)
+	want := []float64{5, 10, 15, 10, 5}
+	for i, w := range want {
+		if math.Abs(values[i]-w) > 1e-9 {
+			t.Errorf("incorrect value at %dh: got %f want %f", 6*i, values[i], w)
+		}
+	}
+}
+
+func TestTrendDistribution(t *testing.T) {
+	linear := advanceAt(LTD(&ConstantDistribution{State: 10}, 2), time.Hour, 3)
+	exponential := advanceAt(ETD(&ConstantDistribution{State: 10}, math.Log(2)), time.Hour, 3)
+	wantLinear := []float64{10, 12, 14}
+	wantExponential := []float64{10, 20, 40}
+	for i := range wantLinear {
+		if math.Abs(linear[i]-wantLinear[i]) > 1e-9 {
+			t.Errorf("incorrect linear trend at %dh: got %f want %f", i, linear[i], wantLinear[i])
+		}
+		if math.Abs(exponential[i]-wantExponential[i]) > 1e-9 {
+			t.Errorf("incorrect exponential trend at %dh: got %f want %f", i, exponential[i], wantExponential[i])
+		}
+	}
+}
+
+func TestSpikeDistribution(t *testing.T) {
+	rand.Seed(123)
+	d := SPD(&ConstantDistribution{State: 0}, 1, &ConstantDistribution{State: 100}, time.Minute)
+	values := advanceAt(d, time.Minute, 600)
+	spikes := 0
+	for i := 1; i < len(values); i++ {
+		// the previous spikes halve in a half-life, and new ones add to them
+		want := values[i-1] / 2
+		if values[i] > values[i-1] {
+			spikes++
+			want += 100
+		}
+		if math.Abs(values[i]-want) > 1e-9 {
+			t.Errorf("incorrect value at %d: got %f want %f", i, values[i], want)
+		}
+	}
+	// about one spike per hour
+	if spikes < 3 || spikes > 20 {
+		t.Errorf("incorrect number of spikes in 10h: %d", spikes)
+	}
+}
+
+func TestStepChangeDistribution(t *testing.T) {
+	rand.Seed(123)
+	d := SCD(&ConstantDistribution{State: 0}, 1, &ConstantDistribution{State: 5})
+	values := advanceAt(d, time.Minute, 600)
+	for i := 1; i < len(values); i++ {
+		if diff := values[i] - values[i-1]; diff != 0 && diff != 5 {
+			t.Errorf("incorrect level shift at %d: %f", i, diff)
+		}
+	}
+	if last := values[len(values)-1]; last < 15 || last > 100 {
+		t.Errorf("incorrect level after 10h: %f", last)
+	}
+}
+
+func TestFlatlineDistribution(t *testing.T) {
+	rand.Seed(123)
+	d := FLD(MWD(&ConstantDistribution{State: 1}, 0), 1, 10*time.Minute)
+	values := advanceAt(d, time.Minute, 600)
+	flat, longest := 0, 0
+	for i := 1; i < len(values); i++ {
+		if values[i] == values[i-1] {
+			flat++
+			if flat > longest {
+				longest = flat
+			}
+		} else {
+			flat = 0
+		}
+	}
+	// the value is frozen for 10 minutes, i.e. 9 unchanged values after the
+	// first one, unless a new flatline starts right after
+	if longest < 9 {
+		t.Errorf("no flatline: longest run of unchanged values %d", longest)
+	}
+	// the underlying distribution goes on advancing during the flatlines
+	if got := d.Base.Get(); got != 600 {
+		t.Errorf("incorrect value of the underlying distribution: got %f want 600", got)
+	}
+}
+
+func TestClampedDistribution(t *testing.T) {
+	d := CD(LTD(&ConstantDistribution{State: 0}, 10), 0, 25)
+	values := advanceAt(d, time.Hour, 4)
+	want := []float64{0, 10, 20, 25}
+	for i, w := range want {
+		if values[i] != w {
+			t.Errorf("incorrect clamped value at %dh: got %f want %f", i, values[i], w)
+		}
+	}
+}
+
+func TestSetTimeNested(t *testing.T) {
+	inner := SD(&ConstantDistribution{}, 1, time.Hour, 0)
+	outer := FP(CD(inner, -1, 1), 2)
+	setTime(outer, testPatternStart)
+	setTime(CD(inner, -1, 1), testPatternStart.Add(15*time.Minute))
+	if got := inner.Get(); math.Abs(got-1) > 1e-9 {
+		t.Errorf("time not set through the clamp: got %f want 1", got)
+	}
+}
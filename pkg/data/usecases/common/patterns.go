@@ -0,0 +1,155 @@
+package common
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+)
+
+// Patterns that can be layered over the gauges of the simulated measurements
+const (
+	PatternSeasonality = "seasonality"
+	PatternTrend       = "trend"
+	PatternGrowth      = "growth"
+	PatternSteps       = "steps"
+	PatternSpikes      = "spikes"
+	PatternFlatlines   = "flatlines"
+)
+
+// PatternChoices are the patterns in the order they are layered
+var PatternChoices = []string{
+	PatternSeasonality,
+	PatternTrend,
+	PatternGrowth,
+	PatternSteps,
+	PatternSpikes,
+	PatternFlatlines,
+}
+
+const errUnknownPatternFmt = "unknown metric pattern '%s' (choices: %s)"
+
+const (
+	// dailyAmplitude and weeklyAmplitude are the amplitudes of the cycles,
+	// relative to the range of the gauge
+	dailyAmplitude  = 0.15
+	weeklyAmplitude = 0.05
+	// phaseJitter is the largest shift of the cycles of a gauge, in radians
+	// (an hour of the daily cycle)
+	phaseJitter = math.Pi / 12
+	// trendPerDay is the standard deviation of the linear trends, relative to
+	// the range of the gauge, and growthPerDay the largest exponential growth
+	trendPerDay  = 0.05
+	growthPerDay = 0.05
+	// stepsPerHour is the rate of the level shifts, and stepSize the standard
+	// deviation of their size relative to the range of the gauge
+	stepsPerHour = 1.0 / 24
+	stepSize     = 0.2
+	// spikesPerHour is the rate of the spikes, spikeLow and spikeHigh the
+	// bounds of their height relative to the range of the gauge
+	spikesPerHour    = 0.1
+	spikeLow         = 0.3
+	spikeHigh        = 0.6
+	spikeHalfLife    = 5 * time.Minute
+	flatlinesPerHour = 1.0 / 48
+	flatlineLength   = 30 * time.Minute
+)
+
+// MetricPatterns are the patterns layered over the gauges of the simulated
+// measurements, to make them look more like real metrics than stationary
+// noise.
+type MetricPatterns struct {
+	enabled map[string]bool
+}
+
+// ParseMetricPatterns parses a comma-separated list of patterns. It returns
+// nil for an empty list.
+func ParseMetricPatterns(s string) (*MetricPatterns, error) {
+	if strings.TrimSpace(s) == "" {
+		return nil, nil
+	}
+	p := &MetricPatterns{enabled: make(map[string]bool)}
+	for _, name := range strings.Split(s, ",") {
+		name = strings.TrimSpace(name)
+		if !isPattern(name) {
+			return nil, fmt.Errorf(errUnknownPatternFmt, name, strings.Join(PatternChoices, ", "))
+		}
+		p.enabled[name] = true
+	}
+	return p, nil
+}
+
+func isPattern(name string) bool {
+	for _, c := range PatternChoices {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns d with the patterns layered over it if it is a gauge, i.e. a
+// clamped random walk, possibly with a float precision, and d otherwise.
+// The result stays within the bounds of the gauge. The random phase, trend and
+// growth of the patterns are drawn from r, or the global source if nil.
+func (p *MetricPatterns) Apply(d Distribution, r *rand.Rand) Distribution {
+	if p == nil {
+		return d
+	}
+	switch g := d.(type) {
+	case *ClampedRandomWalkDistribution:
+		return p.wrap(g, r)
+	case *FloatPrecision:
+		if cwd, ok := g.step.(*ClampedRandomWalkDistribution); ok {
+			g.step = p.wrap(cwd, r)
+		}
+	}
+	return d
+}
+
+func (p *MetricPatterns) wrap(g *ClampedRandomWalkDistribution, r *rand.Rand) Distribution {
+	span := g.Max - g.Min
+	var d Distribution = g
+	if p.enabled[PatternSeasonality] {
+		// lowest at midnight UTC, give or take an hour, and on Sundays (the
+		// weekly cycle starts on Thursday, the Unix epoch)
+		d = SD(d, dailyAmplitude*span, 24*time.Hour, -math.Pi/2+(2*float64From(r)-1)*phaseJitter)
+		d = SD(d, weeklyAmplitude*span, 7*24*time.Hour, math.Pi/2)
+	}
+	if p.enabled[PatternTrend] {
+		d = LTD(d, normFloat64(r)*trendPerDay*span/24)
+	}
+	if p.enabled[PatternGrowth] {
+		d = ETD(d, float64From(r)*growthPerDay/24)
+	}
+	if p.enabled[PatternSteps] {
+		d = SCD(d, stepsPerHour, ND(0, stepSize*span))
+	}
+	if p.enabled[PatternSpikes] {
+		d = SPD(d, spikesPerHour, UD(spikeLow*span, spikeHigh*span), spikeHalfLife)
+	}
+	if p.enabled[PatternFlatlines] {
+		d = FLD(d, flatlinesPerHour, flatlineLength)
+	}
+	return CD(d, g.Min, g.Max)
+}
+
+// ApplyTo layers the patterns over the gauges of the measurements, as of
+// their current time, drawing from r like Apply.
+func (p *MetricPatterns) ApplyTo(measurements []SimulatedMeasurement, r *rand.Rand) {
+	if p == nil {
+		return
+	}
+	for _, m := range measurements {
+		if pm, ok := m.(patternedMeasurement); ok {
+			pm.applyPatterns(p, r)
+		}
+	}
+}
+
+// patternedMeasurement is a measurement whose distributions can have
+// patterns layered over them.
+type patternedMeasurement interface {
+	applyPatterns(*MetricPatterns, *rand.Rand)
+}
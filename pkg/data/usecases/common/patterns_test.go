@@ -0,0 +1,103 @@
+package common
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+func TestParseMetricPatterns(t *testing.T) {
+	p, err := ParseMetricPatterns("")
+	if err != nil || p != nil {
+		t.Errorf("incorrect patterns for an empty list: %v %v", p, err)
+	}
+	p, err = ParseMetricPatterns("seasonality, spikes")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !p.enabled[PatternSeasonality] || !p.enabled[PatternSpikes] || len(p.enabled) != 2 {
+		t.Errorf("incorrect patterns: %v", p.enabled)
+	}
+	_, err = ParseMetricPatterns("seasonality,bogus")
+	if err == nil || !strings.Contains(err.Error(), "'bogus'") {
+		t.Errorf("incorrect error for an unknown pattern: %v", err)
+	}
+}
+
+func TestMetricPatternsApply(t *testing.T) {
+	rand.Seed(123)
+	var none *MetricPatterns
+	cwd := CWD(ND(0, 1), 0, 100, 50)
+	if got := none.Apply(cwd, nil); got != cwd {
+		t.Errorf("nil patterns changed the distribution: %T", got)
+	}
+
+	p, err := ParseMetricPatterns(strings.Join(PatternChoices, ","))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mwd := MWD(ND(0, 1), 0)
+	if got := p.Apply(mwd, nil); got != mwd {
+		t.Errorf("patterns applied to a counter: %T", got)
+	}
+	fp := FP(CWD(ND(0, 1), 0, 100, 50), 2)
+	if got := p.Apply(fp, nil); got != fp {
+		t.Errorf("float precision not kept: %T", got)
+	} else if _, ok := fp.step.(*ClampedDistribution); !ok {
+		t.Errorf("patterns not applied under the float precision: %T", fp.step)
+	}
+
+	d, ok := p.Apply(cwd, nil).(TimedDistribution)
+	if !ok {
+		t.Fatalf("patterns did not give a timed distribution")
+	}
+	values := advanceAt(d, time.Minute, 7*24*60)
+	var night, day float64
+	for i, v := range values {
+		if v < 0 || v > 100 {
+			t.Fatalf("value out of the bounds of the gauge at %d: %f", i, v)
+		}
+		switch hour := (i / 60) % 24; {
+		case hour < 2 || hour >= 22:
+			night += v
+		case hour >= 10 && hour < 14:
+			day += v
+		}
+	}
+	if day <= night {
+		t.Errorf("no daily cycle: %f by day <= %f by night", day, night)
+	}
+}
+
+func TestSubsystemMeasurementPatterns(t *testing.T) {
+	rand.Seed(123)
+	p, err := ParseMetricPatterns(PatternSeasonality)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	m := NewSubsystemMeasurement(testPatternStart, 2)
+	m.Distributions[0] = CWD(&ConstantDistribution{}, 0, 100, 50)
+	m.Distributions[1] = MWD(&ConstantDistribution{State: 1}, 0)
+	p.ApplyTo([]SimulatedMeasurement{&testSubsystemMeasurement{m}}, nil)
+	if _, ok := m.Distributions[0].(*ClampedDistribution); !ok {
+		t.Errorf("patterns not applied to the gauge: %T", m.Distributions[0])
+	}
+	if _, ok := m.Distributions[1].(*MonotonicRandomWalkDistribution); !ok {
+		t.Errorf("patterns applied to the counter: %T", m.Distributions[1])
+	}
+
+	// the daily cycle is at its highest at noon, give or take an hour
+	m.Tick(12 * time.Hour)
+	if got := m.Distributions[0].Get(); got < 60 {
+		t.Errorf("gauge too low at noon: %f", got)
+	}
+}
+
+type testSubsystemMeasurement struct {
+	*SubsystemMeasurement
+}
+
+func (m *testSubsystemMeasurement) ToPoint(*data.Point) {}
@@ -0,0 +1,147 @@
+package common
+
+import (
+	"math/rand"
+)
+
+// splitMix64 is a small rand.Source64, cheap enough to give one to every
+// simulated entity: the sources of package math/rand take about 5KB each.
+type splitMix64 uint64
+
+// Uint64 returns the next value of the source.
+func (s *splitMix64) Uint64() uint64 {
+	*s += 0x9e3779b97f4a7c15
+	z := uint64(*s)
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// Int63 returns the next value of the source as a non-negative int64.
+func (s *splitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed resets the source to seed.
+func (s *splitMix64) Seed(seed int64) {
+	*s = splitMix64(seed)
+}
+
+// Streams of random numbers derived for each entity
+const (
+	creationStream = iota
+	simulationStream
+)
+
+// entitySeed derives the seed of a stream of the entity numbered i from seed.
+func entitySeed(seed int64, i int, stream uint64) int64 {
+	s := splitMix64(seed)
+	s = splitMix64(s.Uint64() ^ uint64(i))
+	s = splitMix64(s.Uint64() ^ stream)
+	return int64(s.Uint64())
+}
+
+// EntityCreationRand returns the source of random numbers to create the
+// entity numbered i with, so that its tags and initial values only depend on
+// seed and i.
+func EntityCreationRand(seed int64, i int) *rand.Rand {
+	s := splitMix64(entitySeed(seed, i, creationStream))
+	return rand.New(&s)
+}
+
+// EntityRand returns the source of random numbers of the entity numbered i,
+// to be bound to its distributions with BindRand.
+func EntityRand(seed int64, i int) *rand.Rand {
+	s := splitMix64(entitySeed(seed, i, simulationStream))
+	return rand.New(&s)
+}
+
+// globalSource draws from the global source of package math/rand.
+type globalSource struct{}
+
+func (globalSource) Int63() int64    { return rand.Int63() }
+func (globalSource) Uint64() uint64  { return rand.Uint64() }
+func (globalSource) Seed(seed int64) { rand.Seed(seed) }
+
+// GlobalRand returns a *rand.Rand drawing from the global source, which gives
+// the same numbers as the functions of package math/rand would, for the
+// entities created without a source of their own.
+func GlobalRand() *rand.Rand {
+	return rand.New(globalSource{})
+}
+
+func float64From(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.Float64()
+	}
+	return r.Float64()
+}
+
+func normFloat64(r *rand.Rand) float64 {
+	if r == nil {
+		return rand.NormFloat64()
+	}
+	return r.NormFloat64()
+}
+
+// WithRand returns d drawing its random numbers from r instead of the global
+// source. The stateless distributions, which are often shared by several
+// others, are copied; the others are bound in place. Distributions of types
+// unknown to this package are returned as is.
+func WithRand(d Distribution, r *rand.Rand) Distribution {
+	switch d := d.(type) {
+	case *NormalDistribution:
+		c := *d
+		c.rng = r
+		return &c
+	case *UniformDistribution:
+		c := *d
+		c.rng = r
+		return &c
+	case *RandomWalkDistribution:
+		d.Step = WithRand(d.Step, r)
+	case *ClampedRandomWalkDistribution:
+		d.Step = WithRand(d.Step, r)
+	case *MonotonicRandomWalkDistribution:
+		d.Step = WithRand(d.Step, r)
+	case *FloatPrecision:
+		d.step = WithRand(d.step, r)
+	case *LazyDistribution:
+		d.motive = WithRand(d.motive, r)
+		d.step = WithRand(d.step, r)
+	case *SeasonalDistribution:
+		d.Base = WithRand(d.Base, r)
+	case *TrendDistribution:
+		d.Base = WithRand(d.Base, r)
+	case *SpikeDistribution:
+		d.Base = WithRand(d.Base, r)
+		d.Magnitude = WithRand(d.Magnitude, r)
+		d.rng = r
+	case *StepChangeDistribution:
+		d.Base = WithRand(d.Base, r)
+		d.Step = WithRand(d.Step, r)
+		d.rng = r
+	case *FlatlineDistribution:
+		d.Base = WithRand(d.Base, r)
+		d.rng = r
+	case *ClampedDistribution:
+		d.Base = WithRand(d.Base, r)
+	}
+	return d
+}
+
+// BindRand makes the distributions of the measurements draw their random
+// numbers from r, which must then only be used by one goroutine at a time.
+func BindRand(measurements []SimulatedMeasurement, r *rand.Rand) {
+	for _, m := range measurements {
+		if rm, ok := m.(randMeasurement); ok {
+			rm.bindRand(r)
+		}
+	}
+}
+
+// randMeasurement is a measurement whose distributions can be bound to a
+// source of random numbers.
+type randMeasurement interface {
+	bindRand(*rand.Rand)
+}
@@ -0,0 +1,106 @@
+package common
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+)
+
+func TestEntityRand(t *testing.T) {
+	a, b, other := EntityRand(123, 4), EntityRand(123, 4), EntityRand(123, 5)
+	same, differs := true, false
+	for i := 0; i < 100; i++ {
+		x, y, z := a.Int63(), b.Int63(), other.Int63()
+		same = same && x == y
+		differs = differs || x != z
+	}
+	if !same {
+		t.Errorf("entity random numbers depend on more than the seed and the entity")
+	}
+	if !differs {
+		t.Errorf("entities have the same random numbers")
+	}
+}
+
+func TestEntityCreationRand(t *testing.T) {
+	a, b := EntityCreationRand(123, 4), EntityCreationRand(123, 4)
+	simulation := EntityRand(123, 4)
+	same, differs := true, false
+	for i := 0; i < 100; i++ {
+		x, y, z := a.Int63(), b.Int63(), simulation.Int63()
+		same = same && x == y
+		differs = differs || x != z
+	}
+	if !same {
+		t.Errorf("entity creation random numbers depend on more than the seed and the entity")
+	}
+	if !differs {
+		t.Errorf("entity creation and simulation have the same random numbers")
+	}
+}
+
+func TestGlobalRand(t *testing.T) {
+	rand.Seed(123)
+	want := []interface{}{rand.Float64(), rand.Intn(10), rand.NormFloat64(), rand.Int63n(1000), rand.Uint64()}
+	rand.Seed(123)
+	r := GlobalRand()
+	got := []interface{}{r.Float64(), r.Intn(10), r.NormFloat64(), r.Int63n(1000), r.Uint64()}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("incorrect random number %d: got %v want %v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestWithRand(t *testing.T) {
+	shared := ND(0, 1)
+	a := CWD(shared, -1000, 1000, 0)
+	b := CWD(shared, -1000, 1000, 0)
+	if got := WithRand(a, EntityRand(1, 0)); got != a {
+		t.Errorf("distribution with a state not bound in place: %T", got)
+	}
+	WithRand(b, EntityRand(1, 0))
+	if a.Step == shared || a.Step == b.Step {
+		t.Errorf("shared stateless distribution not copied")
+	}
+
+	// the global source does not change the values of bound distributions
+	rand.Seed(1)
+	a.Advance()
+	rand.Seed(2)
+	b.Advance()
+	if a.Get() != b.Get() {
+		t.Errorf("bound distributions differ: %f != %f", a.Get(), b.Get())
+	}
+}
+
+func TestWithRandPatterns(t *testing.T) {
+	newDistribution := func(r *rand.Rand) TimedDistribution {
+		d := SPD(SCD(&ConstantDistribution{}, 1, ND(0, 10)), 1, UD(10, 20), time.Minute)
+		WithRand(d, r)
+		return d
+	}
+	rand.Seed(1)
+	want := advanceAt(newDistribution(EntityRand(1, 0)), time.Minute, 600)
+	rand.Seed(2)
+	got := advanceAt(newDistribution(EntityRand(1, 0)), time.Minute, 600)
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("bound pattern distributions differ at %d: %f != %f", i, got[i], want[i])
+		}
+	}
+}
+
+func TestShardRange(t *testing.T) {
+	next := uint64(0)
+	for shard := 0; shard < 3; shard++ {
+		lo, hi := ShardRange(10, shard, 3)
+		if lo != next || hi <= lo {
+			t.Errorf("incorrect range of shard %d: [%d, %d)", shard, lo, hi)
+		}
+		next = hi
+	}
+	if next != 10 {
+		t.Errorf("shards end at %d instead of 10", next)
+	}
+}
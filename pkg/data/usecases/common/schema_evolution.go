@@ -0,0 +1,536 @@
+package common
+
+import (
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+const (
+	errCannotReadEvolutionFmt  = "cannot read schema evolution '%s': %v"
+	errCannotParseEvolutionFmt = "cannot parse schema evolution '%s': %v"
+	errSparseRateValue         = "sparse rate has to be in [0, 1)"
+	errEmptyChangeFmt          = "schema change %d changes nothing"
+	errEmptyNameFmt            = "schema change %d has an empty field or tag name"
+
+	// addedFieldType is the type of the values of the added fields
+	addedFieldType = "float64"
+	// addedFieldMax is the upper bound of the values of the added fields
+	addedFieldMax = 100
+)
+
+// SchemaEvolution describes how the schema of the simulated points changes
+// over time, the way it does when a fleet rolls out new versions of its
+// agents, e.g.:
+//
+//	sparse-rate: 0.05
+//	changes:
+//	  - epoch: 360
+//	    measurement: cpu
+//	    add-fields: [usage_total]
+//	    drop-fields: [usage_guest_nice]
+//	    add-tags: {agent_version: "2.0"}
+//	    drop-tags: [rack]
+//	    to-float: [usage_user, usage_system]
+//
+// Dropped fields and tags are left in the points with nil values, which the
+// serializers skip or write as empty values, and the added ones follow the
+// others. The headers only describe the points of the start of the
+// simulation; the simulators report the later changes of the fields as
+// SchemaReporters, so that they can be written where they happen for the
+// loaders creating fixed columns from the headers. The added tags are not
+// reported, such loaders keep them with the tags missing from the headers.
+type SchemaEvolution struct {
+	// SparseRate is the fraction of the field values left out (nil) of the
+	// points, chosen at random; a point keeps at least one of its values
+	SparseRate float64 `yaml:"sparse-rate"`
+	// Changes are the changes of the schema, applied in the order of their
+	// epochs
+	Changes []SchemaChange `yaml:"changes"`
+}
+
+// SchemaChange is a change of the schema of the points of a measurement, or of
+// every measurement, from the points of an epoch on.
+type SchemaChange struct {
+	// Epoch is the number of log intervals since the start of the simulation
+	// of the first points changed
+	Epoch uint64 `yaml:"epoch"`
+	// Measurement is the name of the measurement changed; empty for every
+	// measurement
+	Measurement string `yaml:"measurement"`
+	// AddFields are fields added to the points, with random float values in
+	// [0, 100); adding a dropped field brings it back
+	AddFields []string `yaml:"add-fields"`
+	// DropFields are fields whose values are left out of the points
+	DropFields []string `yaml:"drop-fields"`
+	// AddTags are tags added to the points, or whose values are replaced if
+	// the points already have them
+	AddTags map[string]string `yaml:"add-tags"`
+	// DropTags are tags whose values are left out of the points
+	DropTags []string `yaml:"drop-tags"`
+	// ToFloat are integer fields whose values become floats
+	ToFloat []string `yaml:"to-float"`
+}
+
+// LoadSchemaEvolution reads and parses the schema evolution file at path. It
+// returns nil for an empty path.
+func LoadSchemaEvolution(path string) (*SchemaEvolution, error) {
+	if path == "" {
+		return nil, nil
+	}
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotReadEvolutionFmt, path, err)
+	}
+	e, err := ParseSchemaEvolution(contents)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotParseEvolutionFmt, path, err)
+	}
+	return e, nil
+}
+
+// ParseSchemaEvolution parses and validates the contents of a schema evolution
+// file.
+func ParseSchemaEvolution(contents []byte) (*SchemaEvolution, error) {
+	e := &SchemaEvolution{}
+	if err := yaml.UnmarshalStrict(contents, e); err != nil {
+		return nil, err
+	}
+	if e.SparseRate < 0 || e.SparseRate >= 1 {
+		return nil, fmt.Errorf(errSparseRateValue)
+	}
+	for i, c := range e.Changes {
+		if len(c.AddFields)+len(c.DropFields)+len(c.AddTags)+len(c.DropTags)+len(c.ToFloat) == 0 {
+			return nil, fmt.Errorf(errEmptyChangeFmt, i)
+		}
+		for _, names := range [][]string{c.AddFields, c.DropFields, c.DropTags, c.ToFloat} {
+			for _, name := range names {
+				if name == "" {
+					return nil, fmt.Errorf(errEmptyNameFmt, i)
+				}
+			}
+		}
+		if _, ok := c.AddTags[""]; ok {
+			return nil, fmt.Errorf(errEmptyNameFmt, i)
+		}
+	}
+	sort.SliceStable(e.Changes, func(i, j int) bool {
+		return e.Changes[i].Epoch < e.Changes[j].Epoch
+	})
+	return e, nil
+}
+
+// SchemaReporter is a Simulator whose points change schema over time, which
+// reports the changes of their fields.
+type SchemaReporter interface {
+	// SchemaChange returns the fields of the measurement of the last point of
+	// Next and their types, nil if they are all float64, and whether they
+	// changed since the previous point of the measurement, or since the
+	// headers for its first point.
+	SchemaChange() (fields []string, types []string, changed bool)
+}
+
+// schema is the schema of the points of a measurement from an epoch on.
+type schema struct {
+	epoch         uint64
+	addedFields   [][]byte
+	droppedFields map[string]bool
+	addedTags     []Tag
+	droppedTags   map[string]bool
+	floatFields   map[string]bool
+}
+
+// next returns a copy of s with the change c applied from its epoch on.
+func (s *schema) next(c *SchemaChange) *schema {
+	n := &schema{
+		epoch:         c.Epoch,
+		addedFields:   append([][]byte{}, s.addedFields...),
+		droppedFields: copySet(s.droppedFields),
+		addedTags:     append([]Tag{}, s.addedTags...),
+		droppedTags:   copySet(s.droppedTags),
+		floatFields:   copySet(s.floatFields),
+	}
+	for _, f := range c.AddFields {
+		delete(n.droppedFields, f)
+		if indexOf(n.addedFields, []byte(f)) < 0 {
+			n.addedFields = append(n.addedFields, []byte(f))
+		}
+	}
+	for _, f := range c.DropFields {
+		n.droppedFields[f] = true
+	}
+	// sort the added tags so that they are in the same order in every point
+	keys := make([]string, 0, len(c.AddTags))
+	for k := range c.AddTags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		delete(n.droppedTags, k)
+		n.setTag(k, c.AddTags[k])
+	}
+	for _, t := range c.DropTags {
+		n.droppedTags[t] = true
+	}
+	for _, f := range c.ToFloat {
+		n.floatFields[f] = true
+	}
+	return n
+}
+
+func (s *schema) setTag(key, value string) {
+	for i := range s.addedTags {
+		if string(s.addedTags[i].Key) == key {
+			s.addedTags[i].Value = value
+			return
+		}
+	}
+	s.addedTags = append(s.addedTags, Tag{Key: []byte(key), Value: value})
+}
+
+func copySet(s map[string]bool) map[string]bool {
+	c := make(map[string]bool, len(s))
+	for k := range s {
+		c[k] = true
+	}
+	return c
+}
+
+// schemas returns the successive schemas of the points of the measurement.
+func (e *SchemaEvolution) schemas(measurement string) []*schema {
+	s := &schema{
+		droppedFields: map[string]bool{},
+		droppedTags:   map[string]bool{},
+		floatFields:   map[string]bool{},
+	}
+	schemas := []*schema{s}
+	for i := range e.Changes {
+		c := &e.Changes[i]
+		if c.Measurement != "" && c.Measurement != measurement {
+			continue
+		}
+		s = s.next(c)
+		if s.epoch == schemas[len(schemas)-1].epoch {
+			schemas[len(schemas)-1] = s
+		} else {
+			schemas = append(schemas, s)
+		}
+	}
+	return schemas
+}
+
+// Evolve returns sim with the schema of its points evolving as described by
+// e, or sim if e is nil. The epochs of the points are counted in intervals
+// from start, and seed seeds the random values and omissions, which only
+// depend on the point they are drawn for.
+func (e *SchemaEvolution) Evolve(sim Simulator, start time.Time, interval time.Duration, seed int64) Simulator {
+	if e == nil {
+		return sim
+	}
+	return e.newEvolvingSimulator(sim, start, interval, seed)
+}
+
+// EvolveShard is Evolve for the simulator of a shard. As the random values
+// and omissions only depend on the points, the points of all the shards are
+// the same whatever their number.
+func (e *SchemaEvolution) EvolveShard(sim ShardSimulator, start time.Time, interval time.Duration, seed int64) ShardSimulator {
+	if e == nil {
+		return sim
+	}
+	return &evolvingShardSimulator{e.newEvolvingSimulator(sim, start, interval, seed), sim}
+}
+
+func (e *SchemaEvolution) newEvolvingSimulator(sim Simulator, start time.Time, interval time.Duration, seed int64) *evolvingSimulator {
+	return &evolvingSimulator{
+		Simulator: sim,
+		evolution: e,
+		start:     start,
+		interval:  interval,
+		seed:      seed,
+		schemas:   map[string][]*schema{},
+		reported:  map[string]*schema{},
+	}
+}
+
+// evolvingSimulator is a Simulator whose points change schema over time.
+type evolvingSimulator struct {
+	Simulator
+	evolution *SchemaEvolution
+	start     time.Time
+	interval  time.Duration
+	seed      int64
+	// schemas are the successive schemas of the measurements, by name
+	schemas map[string][]*schema
+	// base are the headers of the simulator, before any change
+	base *GeneratedDataHeaders
+	// last is the schema of the last point of Next, and lastName the name
+	// of its measurement
+	last     *schema
+	lastName []byte
+	// reported are the schemas of the fields last reported by SchemaChange,
+	// by measurement
+	reported map[string]*schema
+}
+
+type evolvingShardSimulator struct {
+	*evolvingSimulator
+	shard ShardSimulator
+}
+
+// Position returns the position of the last point of Next among the points
+// of all the shards.
+func (s *evolvingShardSimulator) Position() uint64 {
+	return s.shard.Position()
+}
+
+// Next advances p to the next point of the simulator and applies the schema
+// of its epoch to it.
+func (s *evolvingSimulator) Next(p *data.Point) bool {
+	write := s.Simulator.Next(p)
+	if write {
+		s.evolve(p)
+	}
+	return write
+}
+
+func (s *evolvingSimulator) schemaOf(measurement []byte) []*schema {
+	schemas, ok := s.schemas[string(measurement)]
+	if !ok {
+		schemas = s.evolution.schemas(string(measurement))
+		s.schemas[string(measurement)] = schemas
+	}
+	return schemas
+}
+
+func (s *evolvingSimulator) evolve(p *data.Point) {
+	schemas := s.schemaOf(p.MeasurementName())
+	epoch := uint64(0)
+	if elapsed := p.Timestamp().Sub(s.start); elapsed > 0 {
+		epoch = uint64(elapsed / s.interval)
+	}
+	i := sort.Search(len(schemas), func(i int) bool { return schemas[i].epoch > epoch }) - 1
+	sch := schemas[i]
+	s.last, s.lastName = sch, append(s.lastName[:0], p.MeasurementName()...)
+
+	h := s.pointHash(p)
+
+	tagKeys, tagValues := p.TagKeys(), p.TagValues()
+	for i, k := range tagKeys {
+		if sch.droppedTags[string(k)] {
+			tagValues[i] = nil
+		}
+	}
+	for _, t := range sch.addedTags {
+		if i := indexOf(tagKeys, t.Key); i >= 0 {
+			tagValues[i] = t.Value
+		} else {
+			p.AppendTag(t.Key, t.Value)
+		}
+	}
+
+	fieldKeys, fieldValues := p.FieldKeys(), p.FieldValues()
+	for i, k := range fieldKeys {
+		if sch.droppedFields[string(k)] {
+			fieldValues[i] = nil
+		} else if sch.floatFields[string(k)] {
+			fieldValues[i] = toFloat(fieldValues[i])
+		}
+	}
+	for i, k := range sch.addedFields {
+		if indexOf(fieldKeys, k) >= 0 {
+			continue
+		}
+		if sch.droppedFields[string(k)] {
+			p.AppendField(k, nil)
+		} else {
+			p.AppendField(k, addedFieldMax*hashFloat64(h, valueStream, uint64(i)))
+		}
+	}
+
+	if s.evolution.SparseRate > 0 {
+		s.omitFields(p, h)
+	}
+}
+
+// omitFields leaves out the values of a random fraction of the fields of p,
+// keeping at least one.
+func (s *evolvingSimulator) omitFields(p *data.Point, h uint64) {
+	values := p.FieldValues()
+	first, kept := -1, false
+	var firstValue interface{}
+	for i, v := range values {
+		if v == nil {
+			continue
+		}
+		if first < 0 {
+			first, firstValue = i, v
+		}
+		if hashFloat64(h, omissionStream, uint64(i)) < s.evolution.SparseRate {
+			values[i] = nil
+		} else {
+			kept = true
+		}
+	}
+	if !kept && first >= 0 {
+		values[first] = firstValue
+	}
+}
+
+// pointHash returns a hash of the measurement, time and tags of p, so that the
+// random choices made for p do not depend on the order of the points.
+func (s *evolvingSimulator) pointHash(p *data.Point) uint64 {
+	h := fnv.New64a()
+	h.Write(p.MeasurementName())
+	fmt.Fprintf(h, "%d", p.Timestamp().UnixNano())
+	for _, v := range p.TagValues() {
+		switch v := v.(type) {
+		case string:
+			h.Write([]byte(v))
+		case []byte:
+			h.Write(v)
+		default:
+			fmt.Fprint(h, v)
+		}
+		h.Write([]byte{0})
+	}
+	return h.Sum64() ^ uint64(s.seed)
+}
+
+// Streams of random numbers derived for each point
+const (
+	valueStream = iota
+	omissionStream
+)
+
+// hashFloat64 returns a float in [0, 1) of a stream derived from the hash h of
+// a point and the number i of a field.
+func hashFloat64(h, stream, i uint64) float64 {
+	s := splitMix64(h)
+	s = splitMix64(s.Uint64() ^ stream)
+	s = splitMix64(s.Uint64() ^ i)
+	return float64(s.Uint64()>>11) / (1 << 53)
+}
+
+func indexOf(keys [][]byte, key []byte) int {
+	for i, k := range keys {
+		if string(k) == string(key) {
+			return i
+		}
+	}
+	return -1
+}
+
+func toFloat(v interface{}) interface{} {
+	switch v := v.(type) {
+	case int:
+		return float64(v)
+	case int32:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case uint64:
+		return float64(v)
+	}
+	return v
+}
+
+// Fields returns the fields of the measurements at the start of the
+// simulation.
+func (s *evolvingSimulator) Fields() map[string][]string {
+	fields := s.Simulator.Fields()
+	for m := range fields {
+		fields[m], _ = s.fieldsOf(m, s.schemaOf([]byte(m))[0])
+	}
+	return fields
+}
+
+// Headers returns the headers of the simulator for the points of the start of
+// the simulation, with the fields added and switched to float from then on.
+// The later changes are reported by SchemaChange.
+func (s *evolvingSimulator) Headers() *GeneratedDataHeaders {
+	headers := s.Simulator.Headers()
+	for m := range headers.FieldKeys {
+		keys, types := s.fieldsOf(m, s.schemaOf([]byte(m))[0])
+		headers.FieldKeys[m] = keys
+		if types != nil {
+			headers.FieldTypes[m] = types
+		}
+	}
+	return headers
+}
+
+// SchemaChange returns the fields of the measurement of the last point of Next
+// and their types, and whether they changed since the previous point of the
+// measurement. Late points may change them back to those of an earlier epoch.
+func (s *evolvingSimulator) SchemaChange() ([]string, []string, bool) {
+	if s.last == nil {
+		return nil, nil, false
+	}
+	reported, ok := s.reported[string(s.lastName)]
+	if !ok {
+		reported = s.schemaOf(s.lastName)[0]
+	}
+	if s.last == reported {
+		return nil, nil, false
+	}
+	measurement := string(s.lastName)
+	s.reported[measurement] = s.last
+	keys, types := s.fieldsOf(measurement, s.last)
+	oldKeys, oldTypes := s.fieldsOf(measurement, reported)
+	if equal(keys, oldKeys) && equal(types, oldTypes) {
+		return nil, nil, false
+	}
+	return keys, types, true
+}
+
+// fieldsOf returns the fields of the points of the measurement with the schema
+// sch and their types, nil if they are all float64.
+func (s *evolvingSimulator) fieldsOf(measurement string, sch *schema) ([]string, []string) {
+	if s.base == nil {
+		s.base = s.Simulator.Headers()
+	}
+	keys := append([]string(nil), s.base.FieldKeys[measurement]...)
+	types := append([]string(nil), s.base.FieldTypes[measurement]...)
+	for i, f := range keys {
+		if types != nil && sch.floatFields[f] {
+			types[i] = addedFieldType
+		}
+	}
+	for _, f := range sch.addedFields {
+		if contains(keys, string(f)) {
+			continue
+		}
+		keys = append(keys, string(f))
+		if types != nil {
+			types = append(types, addedFieldType)
+		}
+	}
+	return keys, types
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
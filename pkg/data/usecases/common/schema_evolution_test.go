@@ -0,0 +1,286 @@
+package common
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+const testEvolution = `
+sparse-rate: 0
+changes:
+  - epoch: 5
+    measurement: m
+    drop-fields: [b]
+    add-fields: [d]
+    add-tags: {version: "2"}
+    drop-tags: [rack]
+    to-float: [a]
+  - epoch: 2
+    add-fields: [d]
+`
+
+var testEvolutionStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// evolutionTestSimulator writes a point of the measurements m and n per epoch
+// and host, with the int fields a and b and the float field c.
+type evolutionTestSimulator struct {
+	hosts  int
+	epochs int
+	made   int
+}
+
+func (s *evolutionTestSimulator) Finished() bool {
+	return s.made >= 2*s.hosts*s.epochs
+}
+
+func (s *evolutionTestSimulator) Next(p *data.Point) bool {
+	epoch, measurement, host := s.made/(2*s.hosts), s.made/s.hosts%2, s.made%s.hosts
+	ts := testEvolutionStart.Add(time.Duration(epoch) * time.Second)
+	p.SetTimestamp(&ts)
+	p.SetMeasurementName([]byte("mn"[measurement : measurement+1]))
+	p.AppendTag([]byte("hostname"), "host_"+string(rune('0'+host)))
+	p.AppendTag([]byte("rack"), "r1")
+	p.AppendField([]byte("a"), int64(epoch))
+	p.AppendField([]byte("b"), 2)
+	p.AppendField([]byte("c"), 3.5)
+	s.made++
+	return true
+}
+
+func (s *evolutionTestSimulator) Fields() map[string][]string {
+	return map[string][]string{"m": {"a", "b", "c"}, "n": {"a", "b", "c"}}
+}
+
+func (s *evolutionTestSimulator) TagKeys() []string {
+	return []string{"hostname", "rack"}
+}
+
+func (s *evolutionTestSimulator) TagTypes() []string {
+	return []string{"string", "string"}
+}
+
+func (s *evolutionTestSimulator) Headers() *GeneratedDataHeaders {
+	return &GeneratedDataHeaders{
+		TagTypes:   s.TagTypes(),
+		TagKeys:    s.TagKeys(),
+		FieldKeys:  s.Fields(),
+		FieldTypes: map[string][]string{"m": {"int64", "int64", "float64"}},
+	}
+}
+
+func TestParseSchemaEvolution(t *testing.T) {
+	e, err := ParseSchemaEvolution([]byte(testEvolution))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(e.Changes) != 2 || e.Changes[0].Epoch != 2 || e.Changes[1].Epoch != 5 {
+		t.Errorf("changes not sorted by epoch: %v", e.Changes)
+	}
+
+	cases := map[string]string{
+		"sparse-rate: 1":                       "sparse rate",
+		"changes: [{epoch: 1}]":                "changes nothing",
+		"changes: [{drop-fields: ['']}]":       "empty field or tag name",
+		"changes: [{add-tags: {'': x}}]":       "empty field or tag name",
+		"changes: [{epoch: 1, bogus: [a]}]":    "bogus",
+		"sparse-rate: 0.1\nsparse-rate: 0.2\n": "already set",
+	}
+	for contents, want := range cases {
+		if _, err := ParseSchemaEvolution([]byte(contents)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("incorrect error for %q: got %v want %s", contents, err, want)
+		}
+	}
+
+	if e, err := LoadSchemaEvolution(""); e != nil || err != nil {
+		t.Errorf("incorrect schema evolution for no file: %v %v", e, err)
+	}
+}
+
+func TestSchemaEvolutionEvolve(t *testing.T) {
+	var none *SchemaEvolution
+	sim := &evolutionTestSimulator{hosts: 2, epochs: 8}
+	if got := none.Evolve(sim, testEvolutionStart, time.Second, 0); got != sim {
+		t.Errorf("nil schema evolution changed the simulator: %T", got)
+	}
+
+	e, err := ParseSchemaEvolution([]byte(testEvolution))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	evolved := e.Evolve(sim, testEvolutionStart, time.Second, 123)
+	p := data.NewPoint()
+	for i := 0; !evolved.Finished(); i++ {
+		evolved.Next(p)
+		epoch, name := i/4, string(p.MeasurementName())
+		a, b, d := p.GetFieldValue([]byte("a")), p.GetFieldValue([]byte("b")), p.GetFieldValue([]byte("d"))
+
+		if epoch < 2 && len(p.FieldKeys()) != 3 {
+			t.Errorf("epoch %d: incorrect fields before the changes: %s", epoch, p.FieldKeys())
+		}
+		if epoch >= 2 {
+			if v, ok := d.(float64); !ok || v < 0 || v >= addedFieldMax {
+				t.Errorf("epoch %d: incorrect added field: %v", epoch, d)
+			}
+		}
+		switch {
+		case name == "m" && epoch >= 5:
+			if b != nil || p.GetTagValue([]byte("rack")) != nil {
+				t.Errorf("epoch %d: field or tag not dropped: %v %v", epoch, b, p.GetTagValue([]byte("rack")))
+			}
+			if _, ok := a.(float64); !ok {
+				t.Errorf("epoch %d: field not switched to float: %T", epoch, a)
+			}
+			if got := p.GetTagValue([]byte("version")); got != "2" {
+				t.Errorf("epoch %d: incorrect added tag: %v", epoch, got)
+			}
+		default:
+			if b == nil || p.GetTagValue([]byte("rack")) == nil || p.GetTagValue([]byte("version")) != nil {
+				t.Errorf("epoch %d: measurement %s changed too early or by another's change", epoch, name)
+			}
+			if _, ok := a.(int64); !ok {
+				t.Errorf("epoch %d: field switched to float too early: %T", epoch, a)
+			}
+		}
+		p.Reset()
+	}
+}
+
+func TestSchemaEvolutionSparse(t *testing.T) {
+	e := &SchemaEvolution{SparseRate: 0.5}
+	points := func(seed int64) [][]interface{} {
+		sim := e.Evolve(&evolutionTestSimulator{hosts: 10, epochs: 100}, testEvolutionStart, time.Second, seed)
+		var values [][]interface{}
+		p := data.NewPoint()
+		for !sim.Finished() {
+			sim.Next(p)
+			values = append(values, append([]interface{}{}, p.FieldValues()...))
+			p.Reset()
+		}
+		return values
+	}
+
+	values := points(1)
+	omitted := 0
+	for _, v := range values {
+		nils := 0
+		for _, f := range v {
+			if f == nil {
+				nils++
+			}
+		}
+		if nils == len(v) {
+			t.Fatalf("every field of a point left out: %v", v)
+		}
+		omitted += nils
+	}
+	if rate := float64(omitted) / float64(3*len(values)); rate < 0.4 || rate > 0.5 {
+		t.Errorf("incorrect rate of left out fields: got %f want about 0.5 (less the kept ones)", rate)
+	}
+
+	// the omissions only depend on the points and the seed
+	again, other := points(1), points(2)
+	same, sameOther := true, true
+	for i := range values {
+		for j := range values[i] {
+			same = same && values[i][j] == again[i][j]
+			sameOther = sameOther && values[i][j] == other[i][j]
+		}
+	}
+	if !same || sameOther {
+		t.Errorf("incorrect determinism of the omissions: same seed %t, other seed %t", same, sameOther)
+	}
+}
+
+func TestSchemaEvolutionHeaders(t *testing.T) {
+	e, err := ParseSchemaEvolution([]byte(testEvolution))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sim := &evolutionTestSimulator{hosts: 1, epochs: 1}
+	evolved := e.Evolve(sim, testEvolutionStart, time.Second, 0)
+	// the headers only describe the points of the start
+	headers := evolved.Headers()
+	if got := strings.Join(headers.FieldKeys["m"], ","); got != "a,b,c" {
+		t.Errorf("incorrect fields of m: got %s want a,b,c", got)
+	}
+	if got := strings.Join(headers.FieldKeys["n"], ","); got != "a,b,c" {
+		t.Errorf("incorrect fields of n: got %s want a,b,c", got)
+	}
+	if got := strings.Join(headers.FieldTypes["m"], ","); got != "int64,int64,float64" {
+		t.Errorf("incorrect field types of m: got %s", got)
+	}
+	if headers.FieldTypes["n"] != nil {
+		t.Errorf("field types added to n: %v", headers.FieldTypes["n"])
+	}
+	if got := strings.Join(headers.TagKeys, ","); got != "hostname,rack" {
+		t.Errorf("incorrect tags: got %s want hostname,rack", got)
+	}
+	if got := strings.Join(evolved.Fields()["m"], ","); got != "a,b,c" {
+		t.Errorf("incorrect fields: got %s want a,b,c", got)
+	}
+
+	// the changes of the first epoch are in the headers
+	e, err = ParseSchemaEvolution([]byte("changes: [{measurement: m, add-fields: [d], to-float: [b]}]"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	headers = e.Evolve(sim, testEvolutionStart, time.Second, 0).Headers()
+	if got := strings.Join(headers.FieldKeys["m"], ","); got != "a,b,c,d" {
+		t.Errorf("incorrect fields of m from the start: got %s want a,b,c,d", got)
+	}
+	if got := strings.Join(headers.FieldTypes["m"], ","); got != "int64,float64,float64,float64" {
+		t.Errorf("incorrect field types of m from the start: got %s", got)
+	}
+}
+
+func TestSchemaEvolutionSchemaChange(t *testing.T) {
+	e, err := ParseSchemaEvolution([]byte(testEvolution + `
+  - epoch: 6
+    add-tags: {version: "3"}
+  - epoch: 7
+    measurement: n
+    drop-fields: [d]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	sim := e.Evolve(&evolutionTestSimulator{hosts: 2, epochs: 8}, testEvolutionStart, time.Second, 0)
+	reporter, ok := sim.(SchemaReporter)
+	if !ok {
+		t.Fatalf("evolving simulator is not a SchemaReporter: %T", sim)
+	}
+	if _, _, changed := reporter.SchemaChange(); changed {
+		t.Errorf("change reported before any point")
+	}
+
+	// the changes of the fields are reported at the first point of their
+	// epoch, and neither the changes of the tags nor the dropped fields,
+	// which are left in the points
+	want := map[int]string{
+		8:  "m a,b,c,d int64,int64,float64,float64",
+		10: "n a,b,c,d ",
+		20: "m a,b,c,d float64,int64,float64,float64",
+	}
+	p := data.NewPoint()
+	for i := 0; !sim.Finished(); i++ {
+		sim.Next(p)
+		fields, types, changed := reporter.SchemaChange()
+		got := ""
+		if changed {
+			got = string(p.MeasurementName()) + " " + strings.Join(fields, ",") + " " + strings.Join(types, ",")
+		}
+		if got != want[i] {
+			t.Errorf("point %d: incorrect change: got %q want %q", i, got, want[i])
+		}
+		if len(p.FieldKeys()) != len(fields) && changed {
+			t.Errorf("point %d: fields reported do not match the point: %s", i, p.FieldKeys())
+		}
+		if i/4 == 7 && string(p.MeasurementName()) == "n" && p.GetFieldValue([]byte("d")) != nil {
+			t.Errorf("point %d: added field not dropped: %v", i, p.GetFieldValue([]byte("d")))
+		}
+		p.Reset()
+	}
+}
@@ -24,6 +24,8 @@ type BaseSimulatorConfig struct {
 	GeneratorScale uint64
 	// GeneratorConstructor is the function used to create a new Generator given an id number and start time
 	GeneratorConstructor func(i int, start time.Time, interval time.Duration) Generator
+	// Patterns are layered over the gauges of the measurements of the Generators, if not nil
+	Patterns *MetricPatterns
 }
 
 func calculateEpochs(duration time.Duration, interval time.Duration) uint64 {
@@ -35,6 +37,7 @@ func (sc *BaseSimulatorConfig) NewSimulator(interval time.Duration, limit uint64
 	generators := make([]Generator, sc.GeneratorScale)
 	for i := 0; i < len(generators); i++ {
 		generators[i] = sc.GeneratorConstructor(i, sc.Start, interval)
+		sc.Patterns.ApplyTo(generators[i].Measurements(), nil)
 	}
 
 	epochs := calculateEpochs(sc.End.Sub(sc.Start), interval)
@@ -68,6 +71,9 @@ type GeneratedDataHeaders struct {
 	TagTypes  []string
 	TagKeys   []string
 	FieldKeys map[string][]string
+	// FieldTypes holds the types of the fields of the measurements whose
+	// fields are not all float64, in the order of FieldKeys
+	FieldTypes map[string][]string
 }
 
 // Simulator simulates a use case.
@@ -80,6 +86,33 @@ type Simulator interface {
 	Headers() *GeneratedDataHeaders
 }
 
+// ShardedSimulatorConfig is a SimulatorConfig that can also split its
+// entities into shards simulated independently, e.g. by parallel workers.
+type ShardedSimulatorConfig interface {
+	SimulatorConfig
+	// Entities returns the number of entities to simulate
+	Entities() uint64
+	// NewShardSimulator produces a Simulator of the entities of the shard
+	// numbered shard out of shards. Each entity is seeded from seed and its
+	// number only, so the points of all the shards are the same whatever
+	// their number.
+	NewShardSimulator(interval time.Duration, seed int64, shard, shards int) ShardSimulator
+}
+
+// ShardSimulator simulates a shard of the entities of a use case.
+type ShardSimulator interface {
+	Simulator
+	// Position returns the position of the last point of Next among the
+	// points of all the shards, which interleave in its order.
+	Position() uint64
+}
+
+// ShardRange returns the entities [lo, hi) of the shard numbered shard out of
+// shards when simulating count entities.
+func ShardRange(count uint64, shard, shards int) (lo, hi uint64) {
+	return count * uint64(shard) / uint64(shards), count * uint64(shard+1) / uint64(shards)
+}
+
 // BaseSimulator generates data similar to truck readings.
 type BaseSimulator struct {
 	madePoints uint64
@@ -0,0 +1,254 @@
+package custom
+
+import (
+	"math"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitEntityCount is the number of entities to start with, the others are
+	// added one by one over the run
+	InitEntityCount uint64
+	// EntityCount is the total number of entities, unless set by the spec
+	EntityCount uint64
+	// Spec describes the entities and their measurements
+	Spec *Spec
+}
+
+// NewSimulator produces a Simulator writing the measurements of every entity
+// every interval, or every interval of the spec if it has one.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	if c.Spec.Interval > 0 {
+		interval = c.Spec.Interval
+	}
+	count, initCount := c.EntityCount, c.InitEntityCount
+	if c.Spec.Entities > 0 {
+		// the count of the spec replaces the scale, and the initial scale
+		// unless it was set lower
+		if initCount == 0 || initCount >= count {
+			initCount = c.Spec.Entities
+		}
+		count = c.Spec.Entities
+	}
+	if initCount == 0 || initCount > count {
+		initCount = count
+	}
+
+	s := &Simulator{
+		maxPoints:   limit,
+		epochs:      uint64(c.End.Sub(c.Start).Nanoseconds() / interval.Nanoseconds()),
+		start:       c.Start,
+		interval:    interval,
+		initCount:   initCount,
+		entityCount: count,
+		spec:        c.Spec,
+	}
+	for i := range c.Spec.Tags {
+		t := &c.Spec.Tags[i]
+		s.tagKeys = append(s.tagKeys, []byte(t.Name))
+		s.tagValues = append(s.tagValues, t.newValueMaker())
+	}
+	for _, m := range c.Spec.Measurements {
+		every := uint64(1)
+		if m.Interval > 0 {
+			every = uint64(math.Max(1, math.Round(float64(m.Interval)/float64(interval))))
+		}
+		s.every = append(s.every, every)
+	}
+	for uint64(len(s.entities)) < initCount {
+		s.entities = append(s.entities, s.newEntity(len(s.entities)))
+	}
+	return s
+}
+
+// measurement is a measurement of an entity, with a distribution per field.
+type measurement struct {
+	name          []byte
+	fieldKeys     [][]byte
+	intFields     []bool
+	distributions []common.Distribution
+}
+
+func newMeasurement(spec *MeasurementSpec) *measurement {
+	m := &measurement{name: []byte(spec.Name)}
+	for _, f := range spec.Fields {
+		m.fieldKeys = append(m.fieldKeys, []byte(f.Name))
+		m.intFields = append(m.intFields, f.Type == fieldTypeInt)
+		m.distributions = append(m.distributions, f.Distribution.build())
+	}
+	return m
+}
+
+// tickToPoint advances the distributions of m and writes their values at ts
+// to p.
+func (m *measurement) tickToPoint(ts time.Time, p *data.Point) {
+	p.SetMeasurementName(m.name)
+	p.SetTimestamp(&ts)
+	for i, d := range m.distributions {
+		d.Advance()
+		if m.intFields[i] {
+			p.AppendField(m.fieldKeys[i], int64(d.Get()))
+		} else {
+			p.AppendField(m.fieldKeys[i], d.Get())
+		}
+	}
+}
+
+// entity is one of the simulated entities, e.g. a host or a device.
+type entity struct {
+	tagValues    []string
+	measurements []*measurement
+}
+
+// Simulator simulates entities writing the measurements of a spec. The
+// entities are visited in order every interval, and write the measurements
+// that are due in it.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+
+	epoch    uint64
+	epochs   uint64
+	start    time.Time
+	interval time.Duration
+
+	initCount   uint64
+	entityCount uint64
+	entities    []*entity
+
+	spec      *Spec
+	tagKeys   [][]byte
+	tagValues []func(int) string
+	// every is the number of intervals between two points of each
+	// measurement
+	every []uint64
+
+	entityIndex      int
+	measurementIndex int
+}
+
+func (s *Simulator) newEntity(i int) *entity {
+	e := &entity{}
+	for _, makeValue := range s.tagValues {
+		e.tagValues = append(e.tagValues, makeValue(i))
+	}
+	for j := range s.spec.Measurements {
+		e.measurements = append(e.measurements, newMeasurement(&s.spec.Measurements[j]))
+	}
+	return e
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.epoch >= s.epochs || (s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next point due to p.
+func (s *Simulator) Next(p *data.Point) bool {
+	for s.epoch < s.epochs {
+		if s.entityIndex == len(s.entities) {
+			s.nextEpoch()
+			continue
+		}
+		e := s.entities[s.entityIndex]
+		for s.measurementIndex < len(e.measurements) {
+			i := s.measurementIndex
+			s.measurementIndex++
+			if s.epoch%s.every[i] != 0 {
+				continue
+			}
+			for j, v := range e.tagValues {
+				p.AppendTag(s.tagKeys[j], v)
+			}
+			e.measurements[i].tickToPoint(s.start.Add(time.Duration(s.epoch)*s.interval), p)
+			s.madePoints++
+			return true
+		}
+		s.measurementIndex = 0
+		s.entityIndex++
+	}
+	return false
+}
+
+// nextEpoch advances to the next epoch and adds the entities joining in it.
+func (s *Simulator) nextEpoch() {
+	s.epoch++
+	s.entityIndex = 0
+	if s.epochs > 1 {
+		missing := float64(s.entityCount - s.initCount)
+		target := s.initCount + uint64(missing*float64(s.epoch)/float64(s.epochs-1))
+		for uint64(len(s.entities)) < target && uint64(len(s.entities)) < s.entityCount {
+			s.entities = append(s.entities, s.newEntity(len(s.entities)))
+		}
+	}
+}
+
+// Fields returns the fields of every measurement of the spec.
+func (s *Simulator) Fields() map[string][]string {
+	fields := make(map[string][]string, len(s.spec.Measurements))
+	for _, m := range s.spec.Measurements {
+		keys := make([]string, len(m.Fields))
+		for i, f := range m.Fields {
+			keys[i] = f.Name
+		}
+		fields[m.Name] = keys
+	}
+	return fields
+}
+
+// TagKeys returns the tag keys of the entities.
+func (s *Simulator) TagKeys() []string {
+	keys := make([]string, len(s.spec.Tags))
+	for i, t := range s.spec.Tags {
+		keys[i] = t.Name
+	}
+	return keys
+}
+
+// TagTypes returns the types of the tags of the entities, which are all
+// strings.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(s.spec.Tags))
+	for i := range types {
+		types[i] = tagType
+	}
+	return types
+}
+
+// fieldTypes returns the types of the fields of the measurements having
+// integer fields.
+func (s *Simulator) fieldTypes() map[string][]string {
+	var types map[string][]string
+	for _, m := range s.spec.Measurements {
+		mTypes := make([]string, len(m.Fields))
+		hasInt := false
+		for i, f := range m.Fields {
+			mTypes[i] = f.Type
+			hasInt = hasInt || f.Type == fieldTypeInt
+		}
+		if hasInt {
+			if types == nil {
+				types = make(map[string][]string)
+			}
+			types[m.Name] = mTypes
+		}
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:   s.TagTypes(),
+		TagKeys:    s.TagKeys(),
+		FieldKeys:  s.Fields(),
+		FieldTypes: s.fieldTypes(),
+	}
+}
@@ -0,0 +1,113 @@
+package custom
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var testStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestSimulator(t *testing.T) {
+	rand.Seed(123)
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	c := &SimulatorConfig{
+		Start:           testStart,
+		End:             testStart.Add(time.Minute),
+		InitEntityCount: 100,
+		EntityCount:     100,
+		Spec:            spec,
+	}
+	s := c.NewSimulator(time.Second, 0).(*Simulator)
+
+	points := map[string]int{}
+	sensors := map[string]bool{}
+	energy := map[string]int64{}
+	p := data.NewPoint()
+	for !s.Finished() && s.Next(p) {
+		name := string(p.MeasurementName())
+		points[name]++
+		if ts := p.Timestamp(); ts.Before(testStart) || !ts.Before(c.End) || ts.Sub(testStart)%(10*time.Second) != 0 {
+			t.Errorf("incorrect timestamp: %v", ts)
+		}
+		sensor := p.GetTagValue([]byte("sensor")).(string)
+		sensors[sensor] = true
+		switch name {
+		case "climate":
+			temp := p.GetFieldValue([]byte("temperature")).(float64)
+			if temp < 0 || temp > 40 {
+				t.Errorf("temperature out of bounds: %f", temp)
+			}
+		case "power":
+			e := p.GetFieldValue([]byte("energy")).(int64)
+			if e != energy[sensor]+2 {
+				t.Errorf("incorrect energy of %s: got %d want %d", sensor, e, energy[sensor]+2)
+			}
+			energy[sensor] = e
+		}
+		p.Reset()
+	}
+
+	// the spec's 3 entities and 10s interval replace the config's
+	if len(sensors) != 3 || !sensors["sensor_1"] || !sensors["sensor_3"] {
+		t.Errorf("incorrect sensors: %v", sensors)
+	}
+	if points["climate"] != 3*6 || points["power"] != 3*2 {
+		t.Errorf("incorrect number of points: %v", points)
+	}
+
+	h := s.Headers()
+	if len(h.TagKeys) != 2 || h.TagTypes[1] != tagType {
+		t.Errorf("incorrect tag headers: %v %v", h.TagKeys, h.TagTypes)
+	}
+	if len(h.FieldKeys) != 2 || h.FieldKeys["power"][0] != "energy" {
+		t.Errorf("incorrect field keys: %v", h.FieldKeys)
+	}
+	if len(h.FieldTypes) != 1 || h.FieldTypes["power"][0] != fieldTypeInt {
+		t.Errorf("incorrect field types: %v", h.FieldTypes)
+	}
+}
+
+func TestSimulatorEntitiesJoin(t *testing.T) {
+	rand.Seed(123)
+	spec, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	spec.Entities = 0
+	spec.Interval = 0
+	c := &SimulatorConfig{
+		Start:           testStart,
+		End:             testStart.Add(time.Hour),
+		InitEntityCount: 2,
+		EntityCount:     20,
+		Spec:            spec,
+	}
+	s := c.NewSimulator(time.Minute, 10).(*Simulator)
+	if s.interval != time.Minute || s.every[1] != 1 {
+		t.Errorf("incorrect intervals: %v %v", s.interval, s.every)
+	}
+	if len(s.entities) != 2 {
+		t.Errorf("incorrect initial number of entities: got %d want 2", len(s.entities))
+	}
+	p := data.NewPoint()
+	for !s.Finished() && s.Next(p) {
+		p.Reset()
+	}
+	if s.madePoints != 10 {
+		t.Errorf("incorrect number of points with a limit: got %d want 10", s.madePoints)
+	}
+
+	s = c.NewSimulator(time.Minute, 0).(*Simulator)
+	for !s.Finished() && s.Next(p) {
+		p.Reset()
+	}
+	if len(s.entities) != 20 {
+		t.Errorf("incorrect number of entities at the end: got %d want 20", len(s.entities))
+	}
+}
@@ -0,0 +1,376 @@
+// Package custom implements a use case described by a YAML spec file instead
+// of Go code: the spec declares the tags of the simulated entities with the
+// generators of their values, and their measurements with the distributions
+// of the fields, composed from the distributions of the common package.
+package custom
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"time"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// Tag value generators
+const (
+	TagEnum     = "enum"
+	TagSequence = "sequence"
+	TagRandom   = "random"
+	TagZipf     = "zipf"
+)
+
+// Distributions, named after their constructors in the common package
+const (
+	DistNormal         = "nd"
+	DistUniform        = "ud"
+	DistRandomWalk     = "wd"
+	DistClampedWalk    = "cwd"
+	DistMonotonicWalk  = "mwd"
+	DistFloatPrecision = "fp"
+	DistLazy           = "ld"
+	DistConstant       = "constant"
+)
+
+const (
+	fieldTypeFloat = "float64"
+	fieldTypeInt   = "int64"
+	// tagType is the type of all the tags, whatever their generator
+	tagType = "string"
+
+	// sequenceFmtSuffix follows the name of the tag in the default format of
+	// the sequence and zipf values
+	sequenceFmtSuffix   = "_%d"
+	defaultRandomLength = 8
+	randomTagAlphabet   = "abcdefghijklmnopqrstuvwxyz0123456789"
+)
+
+const (
+	errCannotReadFmt     = "cannot read use case spec '%s': %v"
+	errCannotParseFmt    = "cannot parse use case spec '%s': %v"
+	errNoTags            = "use case spec has no tags"
+	errNoMeasurements    = "use case spec has no measurements"
+	errNegativeFmt       = "'%s' cannot be negative"
+	errNoNameFmt         = "%s %d has no name"
+	errDuplicateNameFmt  = "duplicate %s name '%s'"
+	errNoFieldsFmt       = "measurement '%s' has no fields"
+	errBadFieldTypeFmt   = "field '%s' has unknown type '%s'"
+	errNoDistributionFmt = "field '%s' has no distribution"
+	errBadDistFmt        = "field '%s': %v"
+	errBadTagFmt         = "tag '%s': %v"
+	errUnknownTagFmt     = "unknown value generator '%s'"
+	errTagNoValues       = "no values to pick from"
+	errTagZipfS          = "zipf exponent has to be greater than 1"
+	errUnknownDistFmt    = "unknown distribution '%s'"
+	errDistNoStepFmt     = "distribution '%s' needs a step distribution"
+	errDistNoMotive      = "distribution 'ld' needs a motive distribution"
+	errDistBadBoundsFmt  = "distribution '%s' has its lower bound above the upper one"
+)
+
+// Spec is the layout of a use case spec file, e.g.:
+//
+//	entities: 100
+//	interval: 10s
+//	tags:
+//	  - name: sensor
+//	    type: sequence
+//	    format: sensor_%d
+//	  - name: site
+//	    type: zipf
+//	    count: 20
+//	    s: 1.5
+//	measurements:
+//	  - name: climate
+//	    fields:
+//	      - name: temperature
+//	        distribution:
+//	          type: cwd
+//	          step: {type: nd, mean: 0, stddev: 0.1}
+//	          min: -20
+//	          max: 40
+//	          state: 20
+type Spec struct {
+	// Entities is the number of entities, e.g. hosts or devices, writing the
+	// measurements; 0 to use the scale of the generator
+	Entities uint64 `yaml:"entities"`
+	// Interval is the time between two points of a measurement of an entity;
+	// 0 to use the log interval of the generator
+	Interval time.Duration `yaml:"interval"`
+	// Tags are the tags of every entity, their values set once per entity
+	Tags []TagSpec `yaml:"tags"`
+	// Measurements are the measurements every entity writes
+	Measurements []MeasurementSpec `yaml:"measurements"`
+}
+
+// TagSpec is a tag of the entities and the generator of its values.
+type TagSpec struct {
+	Name string `yaml:"name"`
+	// Type is the value generator: enum picks one of Values at random,
+	// sequence formats the number of the entity with Format (name_%d by
+	// default) starting at Start, random draws a string of Length letters
+	// and digits, and zipf picks one of Values, or a number below Count
+	// formatted with Format, with a Zipf distribution of exponent S
+	Type   string   `yaml:"type"`
+	Values []string `yaml:"values"`
+	Format string   `yaml:"format"`
+	Start  int      `yaml:"start"`
+	Length int      `yaml:"length"`
+	S      float64  `yaml:"s"`
+	Count  int      `yaml:"count"`
+}
+
+// MeasurementSpec is a measurement of the entities.
+type MeasurementSpec struct {
+	Name string `yaml:"name"`
+	// Interval is the time between two points of the measurement; it is
+	// rounded to a multiple of the interval of the spec, which it defaults to
+	Interval time.Duration `yaml:"interval"`
+	Fields   []FieldSpec   `yaml:"fields"`
+}
+
+// FieldSpec is a field of a measurement.
+type FieldSpec struct {
+	Name string `yaml:"name"`
+	// Type is float64 (default) or int64
+	Type         string            `yaml:"type"`
+	Distribution *DistributionSpec `yaml:"distribution"`
+}
+
+// DistributionSpec describes a distribution of the common package by the name
+// of its constructor and its arguments: nd (Mean, StdDev), ud (Low, High), wd
+// (Step, State), cwd (Step, Min, Max, State), mwd (Step, State), fp (Step,
+// Precision), ld (Motive, Step, Threshold) and constant (State).
+type DistributionSpec struct {
+	Type      string            `yaml:"type"`
+	Mean      float64           `yaml:"mean"`
+	StdDev    float64           `yaml:"stddev"`
+	Low       float64           `yaml:"low"`
+	High      float64           `yaml:"high"`
+	Min       float64           `yaml:"min"`
+	Max       float64           `yaml:"max"`
+	State     float64           `yaml:"state"`
+	Precision int               `yaml:"precision"`
+	Threshold float64           `yaml:"threshold"`
+	Step      *DistributionSpec `yaml:"step"`
+	Motive    *DistributionSpec `yaml:"motive"`
+}
+
+// Load reads and parses the use case spec file at path.
+func Load(path string) (*Spec, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotReadFmt, path, err)
+	}
+	s, err := Parse(contents)
+	if err != nil {
+		return nil, fmt.Errorf(errCannotParseFmt, path, err)
+	}
+	return s, nil
+}
+
+// Parse parses and validates the contents of a use case spec file.
+func Parse(contents []byte) (*Spec, error) {
+	s := &Spec{}
+	if err := yaml.UnmarshalStrict(contents, s); err != nil {
+		return nil, err
+	}
+	if err := s.validate(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *Spec) validate() error {
+	if len(s.Tags) == 0 {
+		return fmt.Errorf(errNoTags)
+	}
+	if len(s.Measurements) == 0 {
+		return fmt.Errorf(errNoMeasurements)
+	}
+	if s.Interval < 0 {
+		return fmt.Errorf(errNegativeFmt, "interval")
+	}
+
+	tags := map[string]bool{}
+	for i := range s.Tags {
+		t := &s.Tags[i]
+		if err := checkName("tag", i, t.Name, tags); err != nil {
+			return err
+		}
+		if err := t.validate(); err != nil {
+			return fmt.Errorf(errBadTagFmt, t.Name, err)
+		}
+	}
+
+	measurements := map[string]bool{}
+	for i := range s.Measurements {
+		m := &s.Measurements[i]
+		if err := checkName("measurement", i, m.Name, measurements); err != nil {
+			return err
+		}
+		if m.Interval < 0 {
+			return fmt.Errorf(errNegativeFmt, m.Name+" interval")
+		}
+		if len(m.Fields) == 0 {
+			return fmt.Errorf(errNoFieldsFmt, m.Name)
+		}
+		fields := map[string]bool{}
+		for j := range m.Fields {
+			f := &m.Fields[j]
+			if err := checkName("field", j, f.Name, fields); err != nil {
+				return err
+			}
+			if f.Type == "" {
+				f.Type = fieldTypeFloat
+			}
+			if f.Type != fieldTypeFloat && f.Type != fieldTypeInt {
+				return fmt.Errorf(errBadFieldTypeFmt, f.Name, f.Type)
+			}
+			if f.Distribution == nil {
+				return fmt.Errorf(errNoDistributionFmt, f.Name)
+			}
+			if err := f.Distribution.validate(); err != nil {
+				return fmt.Errorf(errBadDistFmt, f.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+func checkName(kind string, i int, name string, seen map[string]bool) error {
+	if name == "" {
+		return fmt.Errorf(errNoNameFmt, kind, i)
+	}
+	if seen[name] {
+		return fmt.Errorf(errDuplicateNameFmt, kind, name)
+	}
+	seen[name] = true
+	return nil
+}
+
+func (t *TagSpec) validate() error {
+	if t.Start < 0 || t.Length < 0 || t.Count < 0 {
+		return fmt.Errorf(errNegativeFmt, "start, length and count")
+	}
+	switch t.Type {
+	case TagEnum:
+		if len(t.Values) == 0 {
+			return fmt.Errorf(errTagNoValues)
+		}
+	case TagSequence, TagRandom:
+	case TagZipf:
+		if t.S <= 1 {
+			return fmt.Errorf(errTagZipfS)
+		}
+		if len(t.Values) == 0 && t.Count == 0 {
+			return fmt.Errorf(errTagNoValues)
+		}
+	default:
+		return fmt.Errorf(errUnknownTagFmt, t.Type)
+	}
+	return nil
+}
+
+// newValueMaker returns the function making the value of the tag for the
+// entity numbered i.
+func (t *TagSpec) newValueMaker() func(i int) string {
+	format := t.Format
+	if format == "" {
+		format = t.Name + sequenceFmtSuffix
+	}
+	switch t.Type {
+	case TagEnum:
+		return func(int) string {
+			return common.RandomStringSliceChoice(t.Values)
+		}
+	case TagSequence:
+		return func(i int) string {
+			return fmt.Sprintf(format, t.Start+i)
+		}
+	case TagRandom:
+		length := t.Length
+		if length == 0 {
+			length = defaultRandomLength
+		}
+		return func(int) string {
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = randomTagAlphabet[rand.Intn(len(randomTagAlphabet))]
+			}
+			return string(b)
+		}
+	case TagZipf:
+		count := t.Count
+		if len(t.Values) > 0 {
+			count = len(t.Values)
+		}
+		zipf := rand.NewZipf(rand.New(rand.NewSource(rand.Int63())), t.S, 1, uint64(count-1))
+		return func(int) string {
+			k := int(zipf.Uint64())
+			if len(t.Values) > 0 {
+				return t.Values[k]
+			}
+			return fmt.Sprintf(format, k)
+		}
+	}
+	panic(fmt.Sprintf(errUnknownTagFmt, t.Type))
+}
+
+func (d *DistributionSpec) validate() error {
+	switch d.Type {
+	case DistNormal:
+		if d.StdDev < 0 {
+			return fmt.Errorf(errNegativeFmt, "stddev")
+		}
+	case DistUniform:
+		if d.Low > d.High {
+			return fmt.Errorf(errDistBadBoundsFmt, d.Type)
+		}
+	case DistConstant:
+	case DistRandomWalk, DistMonotonicWalk, DistFloatPrecision, DistClampedWalk, DistLazy:
+		if d.Step == nil {
+			return fmt.Errorf(errDistNoStepFmt, d.Type)
+		}
+		if d.Type == DistClampedWalk && d.Min > d.Max {
+			return fmt.Errorf(errDistBadBoundsFmt, d.Type)
+		}
+		if d.Type == DistLazy {
+			if d.Motive == nil {
+				return fmt.Errorf(errDistNoMotive)
+			}
+			if err := d.Motive.validate(); err != nil {
+				return err
+			}
+		}
+		return d.Step.validate()
+	default:
+		return fmt.Errorf(errUnknownDistFmt, d.Type)
+	}
+	return nil
+}
+
+// build returns a new distribution as described by d, which must be valid.
+func (d *DistributionSpec) build() common.Distribution {
+	switch d.Type {
+	case DistNormal:
+		return common.ND(d.Mean, d.StdDev)
+	case DistUniform:
+		return common.UD(d.Low, d.High)
+	case DistRandomWalk:
+		return common.WD(d.Step.build(), d.State)
+	case DistClampedWalk:
+		return common.CWD(d.Step.build(), d.Min, d.Max, d.State)
+	case DistMonotonicWalk:
+		return common.MWD(d.Step.build(), d.State)
+	case DistFloatPrecision:
+		return common.FP(d.Step.build(), d.Precision)
+	case DistLazy:
+		return common.LD(d.Motive.build(), d.Step.build(), d.Threshold)
+	case DistConstant:
+		return &common.ConstantDistribution{State: d.State}
+	}
+	panic(fmt.Sprintf(errUnknownDistFmt, d.Type))
+}
@@ -0,0 +1,210 @@
+package custom
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const testSpec = `
+entities: 3
+interval: 10s
+tags:
+  - name: sensor
+    type: sequence
+    format: sensor_%d
+    start: 1
+  - name: site
+    type: zipf
+    values: [a, b, c]
+    s: 2
+measurements:
+  - name: climate
+    fields:
+      - name: temperature
+        distribution:
+          type: cwd
+          step: {type: nd, mean: 0, stddev: 1}
+          min: 0
+          max: 40
+          state: 20
+  - name: power
+    interval: 30s
+    fields:
+      - name: energy
+        type: int64
+        distribution:
+          type: mwd
+          step: {type: constant, state: 2}
+`
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(testSpec))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Entities != 3 || s.Interval.Seconds() != 10 {
+		t.Errorf("incorrect entities or interval: %d %v", s.Entities, s.Interval)
+	}
+	if len(s.Tags) != 2 || len(s.Measurements) != 2 {
+		t.Fatalf("incorrect number of tags or measurements: %d %d", len(s.Tags), len(s.Measurements))
+	}
+	if got := s.Measurements[0].Fields[0].Type; got != fieldTypeFloat {
+		t.Errorf("incorrect default field type: got %s want %s", got, fieldTypeFloat)
+	}
+	if got := s.Measurements[1].Fields[0].Distribution.Step.State; got != 2 {
+		t.Errorf("incorrect nested distribution state: got %f want 2", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	field := "\n    fields:\n      - name: f\n        distribution: {type: nd, stddev: 1}"
+	cases := []struct {
+		desc string
+		spec string
+		want string
+	}{
+		{
+			desc: "no tags",
+			spec: "measurements:\n  - name: m" + field,
+			want: errNoTags,
+		},
+		{
+			desc: "no measurements",
+			spec: "tags:\n  - {name: t, type: sequence}",
+			want: errNoMeasurements,
+		},
+		{
+			desc: "unknown key",
+			spec: "tags:\n  - {name: t, type: sequence, bogus: 1}",
+			want: "bogus",
+		},
+		{
+			desc: "duplicate tag",
+			spec: "tags:\n  - {name: t, type: sequence}\n  - {name: t, type: random}\nmeasurements:\n  - name: m" + field,
+			want: "duplicate tag name 't'",
+		},
+		{
+			desc: "enum without values",
+			spec: "tags:\n  - {name: t, type: enum}\nmeasurements:\n  - name: m" + field,
+			want: errTagNoValues,
+		},
+		{
+			desc: "zipf exponent",
+			spec: "tags:\n  - {name: t, type: zipf, count: 10, s: 1}\nmeasurements:\n  - name: m" + field,
+			want: errTagZipfS,
+		},
+		{
+			desc: "unknown tag generator",
+			spec: "tags:\n  - {name: t, type: uuid}\nmeasurements:\n  - name: m" + field,
+			want: "unknown value generator 'uuid'",
+		},
+		{
+			desc: "no fields",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m",
+			want: "measurement 'm' has no fields",
+		},
+		{
+			desc: "bad field type",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f\n        type: string\n        distribution: {type: nd}",
+			want: "field 'f' has unknown type 'string'",
+		},
+		{
+			desc: "no distribution",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f",
+			want: "field 'f' has no distribution",
+		},
+		{
+			desc: "unknown distribution",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f\n        distribution: {type: pareto}",
+			want: "unknown distribution 'pareto'",
+		},
+		{
+			desc: "walk without step",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f\n        distribution: {type: cwd, max: 1}",
+			want: "distribution 'cwd' needs a step distribution",
+		},
+		{
+			desc: "lazy without motive",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f\n        distribution: {type: ld, step: {type: nd}}",
+			want: errDistNoMotive,
+		},
+		{
+			desc: "bad nested distribution",
+			spec: "tags:\n  - {name: t, type: sequence}\nmeasurements:\n  - name: m\n    fields:\n      - name: f\n        distribution: {type: wd, step: {type: ud, low: 1, high: 0}}",
+			want: "distribution 'ud' has its lower bound above the upper one",
+		},
+	}
+	for _, c := range cases {
+		_, err := Parse([]byte(c.spec))
+		if err == nil {
+			t.Errorf("%s: unexpected lack of error", c.desc)
+		} else if !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: incorrect error: got %v want %s", c.desc, err, c.want)
+		}
+	}
+}
+
+func TestLoadSample(t *testing.T) {
+	if _, err := Load("../../../../docs/sample-configs/custom-use-case-spec.yaml"); err != nil {
+		t.Errorf("cannot load the sample spec: %v", err)
+	}
+	if _, err := Load("does-not-exist.yaml"); err == nil {
+		t.Errorf("unexpected lack of error for a missing spec")
+	}
+}
+
+func TestTagValueMakers(t *testing.T) {
+	rand.Seed(123)
+	sequence := (&TagSpec{Name: "host", Type: TagSequence}).newValueMaker()
+	if got := sequence(3); got != "host_3" {
+		t.Errorf("incorrect sequence value: got %s want host_3", got)
+	}
+	random := (&TagSpec{Name: "id", Type: TagRandom, Length: 5}).newValueMaker()
+	if got := random(0); len(got) != 5 || strings.Trim(got, randomTagAlphabet) != "" {
+		t.Errorf("incorrect random value: %s", got)
+	}
+	enum := (&TagSpec{Name: "e", Type: TagEnum, Values: []string{"x"}}).newValueMaker()
+	if got := enum(0); got != "x" {
+		t.Errorf("incorrect enum value: got %s want x", got)
+	}
+
+	zipf := (&TagSpec{Name: "site", Type: TagZipf, Count: 10, S: 2}).newValueMaker()
+	counts := map[string]int{}
+	for i := 0; i < 1000; i++ {
+		counts[zipf(i)]++
+	}
+	if counts["site_0"] <= counts["site_1"] || counts["site_1"] <= counts["site_9"] {
+		t.Errorf("zipf values not skewed: %v", counts)
+	}
+	if len(counts) > 10 {
+		t.Errorf("too many zipf values: %v", counts)
+	}
+}
+
+func TestDistributionBuild(t *testing.T) {
+	d := &DistributionSpec{
+		Type:      DistFloatPrecision,
+		Precision: 1,
+		Step: &DistributionSpec{
+			Type:  DistClampedWalk,
+			Min:   0,
+			Max:   1,
+			State: 0.5,
+			Step:  &DistributionSpec{Type: DistConstant, State: 0.33},
+		},
+	}
+	dist := d.build()
+	if _, ok := dist.(*common.FloatPrecision); !ok {
+		t.Fatalf("incorrect distribution type: %T", dist)
+	}
+	want := []float64{0.8, 1, 1}
+	for i, w := range want {
+		dist.Advance()
+		if got := dist.Get(); got != w {
+			t.Errorf("incorrect value %d: got %f want %f", i, got, w)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package devops
 import (
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"math/rand"
 	"time"
 )
 
@@ -13,6 +14,8 @@ type HostContext struct {
 	// used for devops-generic use-case
 	metricCount  uint64 // number of metrics to generate
 	epochsToLive uint64 // number of epochs to live
+	// source of random numbers to create the host with, the global one if nil
+	rng *rand.Rand
 }
 
 type commonDevopsSimulatorConfig struct {
@@ -28,14 +31,24 @@ type commonDevopsSimulatorConfig struct {
 	HostConstructor func(ctx *HostContext) Host
 	// MaxMetricCount is the max number of metrics per host to create when using generic-devops use-case
 	MaxMetricCount uint64
+	// Patterns are layered over the gauges of the measurements of the hosts, if not nil
+	Patterns *common.MetricPatterns
 }
 
 func NewHostCtx(id int, start time.Time) *HostContext {
-	return &HostContext{id, start, 0, 0}
+	return &HostContext{id, start, 0, 0, nil}
 }
 
 func NewHostCtxTime(start time.Time) *HostContext {
-	return &HostContext{0, start, 0, 0}
+	return &HostContext{0, start, 0, 0, nil}
+}
+
+// random returns the source of random numbers to create the host with.
+func (ctx *HostContext) random() *rand.Rand {
+	if ctx.rng == nil {
+		return common.GlobalRand()
+	}
+	return ctx.rng
 }
 
 func calculateEpochs(c commonDevopsSimulatorConfig, interval time.Duration) uint64 {
@@ -57,6 +70,26 @@ type commonDevopsSimulator struct {
 	timestampStart time.Time
 	timestampEnd   time.Time
 	interval       time.Duration
+
+	// firstHost is the number of the first of the hosts and hostCount the
+	// number of hosts of all the shards, when simulating a shard
+	firstHost uint64
+	hostCount uint64
+	position  uint64
+}
+
+// allHosts returns the number of hosts of all the shards.
+func (s *commonDevopsSimulator) allHosts() uint64 {
+	if s.hostCount == 0 {
+		return uint64(len(s.hosts))
+	}
+	return s.hostCount
+}
+
+// Position returns the position of the last point of Next among the points
+// of all the shards.
+func (s *commonDevopsSimulator) Position() uint64 {
+	return s.position
 }
 
 // Finished tells whether we have simulated all the necessary points
@@ -128,7 +161,11 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 	// Populate measurement-specific tags and fields:
 	host.SimulatedMeasurements[measureIdx].ToPoint(p)
 
-	ret := s.hostIndex < s.epochHosts
+	// the points are ordered by epoch, measurement and host
+	globalIndex := s.firstHost + s.hostIndex
+	s.position = (s.epoch*uint64(len(host.SimulatedMeasurements))+uint64(measureIdx))*s.allHosts() + globalIndex
+
+	ret := globalIndex < s.epochHosts
 	s.madePoints++
 	s.hostIndex++
 	return ret
@@ -145,6 +182,43 @@ func (s *commonDevopsSimulator) populatePoint(p *data.Point, measureIdx int) boo
 // we check whether the point should be recorded by the calling process.
 func (s *commonDevopsSimulator) adjustNumHostsForEpoch() {
 	s.epoch++
-	missingScale := float64(uint64(len(s.hosts)) - s.initHosts)
+	missingScale := float64(s.allHosts() - s.initHosts)
 	s.epochHosts = s.initHosts + uint64(missingScale*float64(s.epoch)/float64(s.epochs-1))
 }
+
+// newShardHosts creates the hosts [lo, hi) with the contexts made by newCtx.
+// Each host is created and simulated with random numbers seeded from seed and
+// its number only, whatever the shard it is in.
+func newShardHosts(c commonDevopsSimulatorConfig, seed int64, lo, hi uint64, newCtx func(i int) *HostContext) []Host {
+	hosts := make([]Host, 0, hi-lo)
+	for i := lo; i < hi; i++ {
+		ctx := newCtx(int(i))
+		ctx.rng = common.EntityCreationRand(seed, int(i))
+		h := c.HostConstructor(ctx)
+		c.Patterns.ApplyTo(h.SimulatedMeasurements, ctx.rng)
+		common.BindRand(h.SimulatedMeasurements, common.EntityRand(seed, int(i)))
+		hosts = append(hosts, h)
+	}
+	return hosts
+}
+
+// newShardSimulator creates the simulator of the hosts of a shard, starting
+// with host number firstHost, writing pointsPerEpoch points per host and epoch.
+func newShardSimulator(c commonDevopsSimulatorConfig, interval time.Duration, hosts []Host, firstHost, pointsPerEpoch uint64) *commonDevopsSimulator {
+	epochs := calculateEpochs(c, interval)
+	return &commonDevopsSimulator{
+		maxPoints: epochs * uint64(len(hosts)) * pointsPerEpoch,
+
+		hosts: hosts,
+
+		epochs:         epochs,
+		epochHosts:     c.InitHostCount,
+		initHosts:      c.InitHostCount,
+		timestampStart: c.Start,
+		timestampEnd:   c.End,
+		interval:       interval,
+
+		firstHost: firstHost,
+		hostCount: c.HostCount,
+	}
+}
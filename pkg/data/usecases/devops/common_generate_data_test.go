@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/timescale/tsbs/pkg/data"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"math/rand"
 	"testing"
 	"time"
 )
@@ -304,3 +305,48 @@ func TestCommonDevopsSimulatorTagTypes(t *testing.T) {
 		}
 	}
 }
+
+func TestNewShardSimulator(t *testing.T) {
+	start, _ := time.Parse(testLayout, "2016-01-01")
+	c := &DevopsSimulatorConfig{
+		Start:           start,
+		End:             start.Add(time.Minute),
+		InitHostCount:   5,
+		HostCount:       5,
+		HostConstructor: NewHost,
+	}
+	collect := func(shards int) map[uint64]string {
+		points := make(map[uint64]string)
+		for shard := 0; shard < shards; shard++ {
+			sim := c.NewShardSimulator(10*time.Second, 123, shard, shards)
+			p := data.NewPoint()
+			for !sim.Finished() {
+				if sim.Next(p) {
+					points[sim.Position()] = fmt.Sprintf("%s %v %v", p.MeasurementName(), p.TagValues(), p.FieldValues())
+				}
+				p.Reset()
+			}
+		}
+		return points
+	}
+
+	rand.Seed(123)
+	next := rand.Int63()
+	rand.Seed(123)
+	want := collect(1)
+	if got := rand.Int63(); got != next {
+		t.Errorf("shard simulators drew from the global source")
+	}
+	if got := uint64(len(want)); got != 6*5*uint64(len(c.HostConstructor(NewHostCtx(0, start)).SimulatedMeasurements)) {
+		t.Errorf("incorrect number of points: %d", got)
+	}
+	got := collect(2)
+	if len(got) != len(want) {
+		t.Errorf("incorrect number of points with 2 shards: got %d want %d", len(got), len(want))
+	}
+	for pos, p := range want {
+		if got[pos] != p {
+			t.Errorf("incorrect point at %d with 2 shards: got %s want %s", pos, got[pos], p)
+		}
+	}
+}
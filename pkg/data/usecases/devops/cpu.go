@@ -8,18 +8,20 @@ import (
 )
 
 var (
-	labelCPU  = []byte("cpu") // heap optimization
+	labelCPU = []byte("cpu") // heap optimization
+	// cpuFields are the fields of the cpu measurement, whose distributions
+	// are made by newCPUMeasurementNumDistributions as they start at random
 	cpuFields = []common.LabeledDistributionMaker{
-		{Label: []byte("usage_user"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_system"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_idle"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_nice"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_iowait"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_irq"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_softirq"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_steal"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_guest"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
-		{Label: []byte("usage_guest_nice"), DistributionMaker: func() common.Distribution { return common.CWD(cpuND, 0.0, 100.0, rand.Float64()*100.0) }},
+		{Label: []byte("usage_user")},
+		{Label: []byte("usage_system")},
+		{Label: []byte("usage_idle")},
+		{Label: []byte("usage_nice")},
+		{Label: []byte("usage_iowait")},
+		{Label: []byte("usage_irq")},
+		{Label: []byte("usage_softirq")},
+		{Label: []byte("usage_steal")},
+		{Label: []byte("usage_guest")},
+		{Label: []byte("usage_guest_nice")},
 	}
 )
 
@@ -33,15 +35,22 @@ type CPUMeasurement struct {
 }
 
 func NewCPUMeasurement(start time.Time) *CPUMeasurement {
-	return newCPUMeasurementNumDistributions(start, len(cpuFields))
+	return newCPUMeasurement(start, common.GlobalRand())
 }
 
-func newSingleCPUMeasurement(start time.Time) *CPUMeasurement {
-	return newCPUMeasurementNumDistributions(start, 1)
+func newCPUMeasurement(start time.Time, r *rand.Rand) *CPUMeasurement {
+	return newCPUMeasurementNumDistributions(start, len(cpuFields), r)
 }
 
-func newCPUMeasurementNumDistributions(start time.Time, numDistributions int) *CPUMeasurement {
-	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, cpuFields[:numDistributions])
+func newSingleCPUMeasurement(start time.Time, r *rand.Rand) *CPUMeasurement {
+	return newCPUMeasurementNumDistributions(start, 1, r)
+}
+
+func newCPUMeasurementNumDistributions(start time.Time, numDistributions int, r *rand.Rand) *CPUMeasurement {
+	sub := common.NewSubsystemMeasurement(start, numDistributions)
+	for i := range sub.Distributions {
+		sub.Distributions[i] = common.CWD(cpuND, 0.0, 100.0, r.Float64()*100.0)
+	}
 	return &CPUMeasurement{sub}
 }
 
@@ -49,6 +49,7 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 	hostInfos := make([]Host, c.HostCount)
 	for i := 0; i < len(hostInfos); i++ {
 		hostInfos[i] = c.HostConstructor(NewHostCtx(i, c.Start))
+		c.Patterns.ApplyTo(hostInfos[i].SimulatedMeasurements, nil)
 	}
 
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c), interval)
@@ -75,3 +76,19 @@ func (c *CPUOnlySimulatorConfig) NewSimulator(interval time.Duration, limit uint
 
 	return sim
 }
+
+// Entities returns the number of hosts to simulate.
+func (c *CPUOnlySimulatorConfig) Entities() uint64 {
+	return c.HostCount
+}
+
+// NewShardSimulator produces a Simulator of the hosts of the shard numbered
+// shard out of shards, as described by common.ShardedSimulatorConfig.
+func (c *CPUOnlySimulatorConfig) NewShardSimulator(interval time.Duration, seed int64, shard, shards int) common.ShardSimulator {
+	cc := commonDevopsSimulatorConfig(*c)
+	lo, hi := common.ShardRange(c.HostCount, shard, shards)
+	hosts := newShardHosts(cc, seed, lo, hi, func(i int) *HostContext {
+		return NewHostCtx(i, c.Start)
+	})
+	return &CPUOnlySimulator{newShardSimulator(cc, interval, hosts, lo, 1)}
+}
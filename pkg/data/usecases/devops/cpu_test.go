@@ -75,7 +75,7 @@ func TestCPUMeasurementToPoint(t *testing.T) {
 
 func TestSingleCPUMeasurementTick(t *testing.T) {
 	now := time.Now()
-	m := newSingleCPUMeasurement(now)
+	m := newSingleCPUMeasurement(now, common.GlobalRand())
 	duration := time.Second
 	oldVals := map[string]float64{}
 	fields := ldmToFieldLabels(cpuFields[:1]) // only the first field in this use case
@@ -101,7 +101,7 @@ func TestSingleCPUMeasurementTick(t *testing.T) {
 
 func TestSingleCPUMeasurementToPoint(t *testing.T) {
 	now := time.Now()
-	m := newSingleCPUMeasurement(now)
+	m := newSingleCPUMeasurement(now, common.GlobalRand())
 	duration := time.Second
 	fields := cpuFields[:1] // only the first field in this use case
 	m.Tick(duration)
@@ -57,8 +57,12 @@ type DiskMeasurement struct {
 
 // NewDiskMeasurement returns a new populated DiskMeasurement
 func NewDiskMeasurement(start time.Time) *DiskMeasurement {
-	path := fmt.Sprintf(pathFmt, rand.Intn(10))
-	fsType := common.RandomStringSliceChoice(diskFSTypeChoices)
+	return newDiskMeasurement(start, common.GlobalRand())
+}
+
+func newDiskMeasurement(start time.Time, r *rand.Rand) *DiskMeasurement {
+	path := fmt.Sprintf(pathFmt, r.Intn(10))
+	fsType := diskFSTypeChoices[r.Intn(len(diskFSTypeChoices))]
 	sub := common.NewSubsystemMeasurement(start, 1)
 	sub.Distributions[0] = common.CWD(common.ND(50, 1), 0, oneTerabyte, oneTerabyte/2)
 
@@ -40,8 +40,12 @@ type DiskIOMeasurement struct {
 }
 
 func NewDiskIOMeasurement(start time.Time) *DiskIOMeasurement {
+	return newDiskIOMeasurement(start, common.GlobalRand())
+}
+
+func newDiskIOMeasurement(start time.Time, r *rand.Rand) *DiskIOMeasurement {
 	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, diskIOFields)
-	serial := fmt.Sprintf(diskSerialFmt, rand.Intn(1000), rand.Intn(1000), rand.Intn(1000))
+	serial := fmt.Sprintf(diskSerialFmt, r.Intn(1000), r.Intn(1000), r.Intn(1000))
 	return &DiskIOMeasurement{
 		SubsystemMeasurement: sub,
 		serial:               serial,
@@ -66,6 +66,7 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 	hostInfos := make([]Host, d.HostCount)
 	for i := 0; i < len(hostInfos); i++ {
 		hostInfos[i] = d.HostConstructor(NewHostCtx(i, d.Start))
+		d.Patterns.ApplyTo(hostInfos[i].SimulatedMeasurements, nil)
 	}
 
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*d), interval)
@@ -95,3 +96,21 @@ func (d *DevopsSimulatorConfig) NewSimulator(interval time.Duration, limit uint6
 
 	return dg
 }
+
+// Entities returns the number of hosts to simulate.
+func (d *DevopsSimulatorConfig) Entities() uint64 {
+	return d.HostCount
+}
+
+// NewShardSimulator produces a Simulator of the hosts of the shard numbered
+// shard out of shards, as described by common.ShardedSimulatorConfig.
+func (d *DevopsSimulatorConfig) NewShardSimulator(interval time.Duration, seed int64, shard, shards int) common.ShardSimulator {
+	c := commonDevopsSimulatorConfig(*d)
+	lo, hi := common.ShardRange(d.HostCount, shard, shards)
+	hosts := newShardHosts(c, seed, lo, hi, func(i int) *HostContext {
+		return NewHostCtx(i, d.Start)
+	})
+	return &DevopsSimulator{
+		commonDevopsSimulator: newShardSimulator(c, interval, hosts, lo, uint64(len(hosts[0].SimulatedMeasurements))),
+	}
+}
@@ -25,13 +25,22 @@ func initGenericMetricFields(size uint64) {
 	if genericMetricFields == nil {
 		genericMetricFields = make([]common.LabeledDistributionMaker, size)
 		for i := range genericMetricFields {
-			genericMetricFields[i] = common.LabeledDistributionMaker{Label: []byte(fmt.Sprintf("metric_%d", i)), DistributionMaker: func() common.Distribution { return common.CWD(metricND, 0.0, 1000, rand.Float64()*1000) }}
+			genericMetricFields[i] = common.LabeledDistributionMaker{Label: []byte(fmt.Sprintf("metric_%d", i))}
 		}
 	}
 }
 
 func NewGenericMeasurements(start time.Time, count uint64) *GenericMeasurements {
-	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, genericMetricFields[:count])
+	return newGenericMeasurements(start, count, common.GlobalRand())
+}
+
+// newGenericMeasurements makes the distributions of the first count generic
+// metric fields, which start at random.
+func newGenericMeasurements(start time.Time, count uint64, r *rand.Rand) *GenericMeasurements {
+	sub := common.NewSubsystemMeasurement(start, int(count))
+	for i := range sub.Distributions {
+		sub.Distributions[i] = common.CWD(metricND, 0.0, 1000, r.Float64()*1000)
+	}
 	return &GenericMeasurements{sub}
 }
 
@@ -16,6 +16,8 @@ type GenericMetricsSimulatorConfig struct {
 // follows zipf distribution)
 type GenericMetricsSimulator struct {
 	*commonDevopsSimulator
+	// allFields are the fields of all the shards, when simulating a shard
+	allFields map[string][]string
 }
 
 // NewSimulator creates GenericMetricsSimulator for generic-devops use-case. Number of metrics assigned to each host follow zipf distribution.
@@ -28,7 +30,8 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 	epochs := calculateEpochs(commonDevopsSimulatorConfig(*c.DevopsSimulatorConfig), interval)
 	epochsToLive := generateHostEpochsToLive(c.HostCount, epochs)
 	for i := 0; i < len(hostInfos); i++ {
-		hostInfos[i] = c.HostConstructor(&HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i]})
+		hostInfos[i] = c.HostConstructor(&HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i], nil})
+		c.Patterns.ApplyTo(hostInfos[i].SimulatedMeasurements, nil)
 	}
 
 	// This is not an optimal upper limit as it doesn't take into account host liveness but should be good enough
@@ -61,6 +64,9 @@ func (c *GenericMetricsSimulatorConfig) NewSimulator(interval time.Duration, lim
 // Since each host has different number of fields (we use zipf distribution to assign # fields) we search
 // for the host with the max number of fields
 func (gms *GenericMetricsSimulator) Fields() map[string][]string {
+	if gms.allFields != nil {
+		return gms.allFields
+	}
 	maxIndex := 0
 	for i, h := range gms.hosts {
 		if h.GenericMetricCount > gms.hosts[maxIndex].GenericMetricCount {
@@ -93,7 +99,7 @@ func (gms *GenericMetricsSimulator) Next(p *data.Point) bool {
 		gms.adjustNumHostsForEpoch()
 	}
 
-	if gms.hostIndex < gms.epochHosts {
+	if gms.firstHost+gms.hostIndex < gms.epochHosts {
 		host := &gms.hosts[gms.hostIndex]
 		if host.StartEpoch == math.MaxUint64 {
 			// mark the start time of the host
@@ -114,3 +120,26 @@ func (gms *GenericMetricsSimulator) Next(p *data.Point) bool {
 	gms.madePoints++
 	return false
 }
+
+// NewShardSimulator produces a Simulator of the hosts of the shard numbered
+// shard out of shards, as described by common.ShardedSimulatorConfig. The
+// metric counts and lifetimes are drawn for all the hosts, as in NewSimulator.
+func (c *GenericMetricsSimulatorConfig) NewShardSimulator(interval time.Duration, seed int64, shard, shards int) common.ShardSimulator {
+	cc := commonDevopsSimulatorConfig(*c.DevopsSimulatorConfig)
+	initGenericMetricFields(c.MaxMetricCount)
+	hostMetricCount := generateHostMetricCount(c.HostCount, c.MaxMetricCount)
+	epochsToLive := generateHostEpochsToLive(c.HostCount, calculateEpochs(cc, interval))
+	newCtx := func(i int) *HostContext {
+		return &HostContext{i, c.Start, hostMetricCount[i], epochsToLive[i], nil}
+	}
+
+	// the last host has the most metrics, and gives the fields of all
+	last := c.HostCount - 1
+	lastHost := newShardHosts(cc, seed, last, last+1, newCtx)
+	lo, hi := common.ShardRange(c.HostCount, shard, shards)
+	sim := &GenericMetricsSimulator{
+		commonDevopsSimulator: newShardSimulator(cc, interval, newShardHosts(cc, seed, lo, hi, newCtx), lo, 1),
+	}
+	sim.allFields = sim.fields(lastHost[0].SimulatedMeasurements[:1])
+	return sim
+}
@@ -160,33 +160,34 @@ type Host struct {
 type generator func(ctx *HostContext) []common.SimulatedMeasurement
 
 func newHostMeasurements(ctx *HostContext) []common.SimulatedMeasurement {
+	r := ctx.random()
 	return []common.SimulatedMeasurement{
-		NewCPUMeasurement(ctx.start),
-		NewDiskIOMeasurement(ctx.start),
-		NewDiskMeasurement(ctx.start),
-		NewKernelMeasurement(ctx.start),
-		NewMemMeasurement(ctx.start),
-		NewNetMeasurement(ctx.start),
-		NewNginxMeasurement(ctx.start),
+		newCPUMeasurement(ctx.start, r),
+		newDiskIOMeasurement(ctx.start, r),
+		newDiskMeasurement(ctx.start, r),
+		newKernelMeasurement(ctx.start, r),
+		newMemMeasurement(ctx.start, r),
+		newNetMeasurement(ctx.start, r),
+		newNginxMeasurement(ctx.start, r),
 		NewPostgresqlMeasurement(ctx.start),
-		NewRedisMeasurement(ctx.start),
+		newRedisMeasurement(ctx.start, r),
 	}
 }
 
 func newCPUOnlyHostMeasurements(ctx *HostContext) []common.SimulatedMeasurement {
 	return []common.SimulatedMeasurement{
-		NewCPUMeasurement(ctx.start),
+		newCPUMeasurement(ctx.start, ctx.random()),
 	}
 }
 
 func newCPUSingleHostMeasurements(ctx *HostContext) []common.SimulatedMeasurement {
 	return []common.SimulatedMeasurement{
-		newSingleCPUMeasurement(ctx.start),
+		newSingleCPUMeasurement(ctx.start, ctx.random()),
 	}
 }
 
 func newGenericHostMeasurements(ctx *HostContext) []common.SimulatedMeasurement {
-	return []common.SimulatedMeasurement{NewGenericMeasurements(ctx.start, ctx.metricCount)}
+	return []common.SimulatedMeasurement{newGenericMeasurements(ctx.start, ctx.metricCount, ctx.random())}
 }
 
 // NewHost creates a new host in a simulated devops use case
@@ -215,20 +216,21 @@ func NewHostGenericMetrics(ctx *HostContext) Host {
 func newHostWithMeasurementGenerator(gen generator, ctx *HostContext) Host {
 	sm := gen(ctx)
 
-	region := randomRegionSliceChoice(regions)
+	r := ctx.random()
+	region := randomRegionSliceChoice(r, regions)
 
 	h := Host{
 		// Tag Values that are static throughout the life of a Host:
 		Name:               fmt.Sprintf(hostFmt, ctx.id),
 		Region:             region.Name,
-		Datacenter:         common.RandomStringSliceChoice(region.Datacenters),
-		Rack:               getStringRandomInt(machineRackChoicesPerDatacenter),
-		Arch:               common.RandomStringSliceChoice(MachineArchChoices),
-		OS:                 common.RandomStringSliceChoice(MachineOSChoices),
-		Service:            getStringRandomInt(machineServiceChoices),
-		ServiceVersion:     getStringRandomInt(machineServiceVersionChoices),
-		ServiceEnvironment: common.RandomStringSliceChoice(MachineServiceEnvironmentChoices),
-		Team:               common.RandomStringSliceChoice(MachineTeamChoices),
+		Datacenter:         randomStringSliceChoice(r, region.Datacenters),
+		Rack:               getStringRandomInt(r, machineRackChoicesPerDatacenter),
+		Arch:               randomStringSliceChoice(r, MachineArchChoices),
+		OS:                 randomStringSliceChoice(r, MachineOSChoices),
+		Service:            getStringRandomInt(r, machineServiceChoices),
+		ServiceVersion:     getStringRandomInt(r, machineServiceVersionChoices),
+		ServiceEnvironment: randomStringSliceChoice(r, MachineServiceEnvironmentChoices),
+		Team:               randomStringSliceChoice(r, MachineTeamChoices),
 
 		SimulatedMeasurements: sm,
 		GenericMetricCount:    ctx.metricCount,
@@ -246,10 +248,14 @@ func (h *Host) TickAll(d time.Duration) {
 	}
 }
 
-func getStringRandomInt(limit int64) string {
-	return strconv.FormatInt(rand.Int63n(limit), 10)
+func getStringRandomInt(r *rand.Rand, limit int64) string {
+	return strconv.FormatInt(r.Int63n(limit), 10)
 }
 
-func randomRegionSliceChoice(s []region) *region {
-	return &s[rand.Intn(len(s))]
+func randomRegionSliceChoice(r *rand.Rand, s []region) *region {
+	return &s[r.Intn(len(s))]
+}
+
+func randomStringSliceChoice(r *rand.Rand, s []string) string {
+	return s[r.Intn(len(s))]
 }
@@ -165,7 +165,7 @@ func TestNewHostGenericMeasurments(t *testing.T) {
 	initGenericMetricFields(metricCount)
 	// test 1000 times to get diversity of results
 	for i := 0; i < 1000; i++ {
-		h := NewHostGenericMetrics(&HostContext{i, now, metricCount, 0})
+		h := NewHostGenericMetrics(&HostContext{i, now, metricCount, 0, nil})
 		if got := len(h.SimulatedMeasurements); got != 1 {
 			t.Errorf("incorrect number of measurements: got %d want %d", got, 1)
 		}
@@ -263,8 +263,9 @@ func TestHostTickAll(t *testing.T) {
 
 func TestGetStringRandomInt(t *testing.T) {
 	limit := int64(100)
+	r := common.GlobalRand()
 	for i := 0; i < 1000000; i++ {
-		s := getStringRandomInt(limit)
+		s := getStringRandomInt(r, limit)
 		testStringNumberIsValid(t, limit, s)
 	}
 }
@@ -279,8 +280,9 @@ func testIfInRegionSlice(t *testing.T, arr []region, choice *region) {
 }
 
 func TestRandomRegionSliceChoice(t *testing.T) {
+	r := common.GlobalRand()
 	for i := 0; i < 1000000; i++ {
-		r := randomRegionSliceChoice(regions)
-		testIfInRegionSlice(t, regions, r)
+		choice := randomRegionSliceChoice(r, regions)
+		testIfInRegionSlice(t, regions, choice)
 	}
 }
@@ -31,8 +31,12 @@ type KernelMeasurement struct {
 }
 
 func NewKernelMeasurement(start time.Time) *KernelMeasurement {
+	return newKernelMeasurement(start, common.GlobalRand())
+}
+
+func newKernelMeasurement(start time.Time, r *rand.Rand) *KernelMeasurement {
 	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, kernelFields)
-	bootTime := rand.Int63n(240)
+	bootTime := r.Int63n(240)
 	return &KernelMeasurement{
 		SubsystemMeasurement: sub,
 		bootTime:             bootTime,
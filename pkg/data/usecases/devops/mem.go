@@ -32,8 +32,12 @@ type MemMeasurement struct {
 }
 
 func NewMemMeasurement(start time.Time) *MemMeasurement {
+	return newMemMeasurement(start, common.GlobalRand())
+}
+
+func newMemMeasurement(start time.Time, r *rand.Rand) *MemMeasurement {
 	sub := common.NewSubsystemMeasurement(start, 3)
-	bytesTotal := common.RandomInt64SliceChoice(memoryTotalChoices)
+	bytesTotal := memoryTotalChoices[r.Intn(len(memoryTotalChoices))]
 
 	// Reuse NormalDistributions as arguments to other distributions. This is
 	// safe to do because the higher-level distribution advances the ND and
@@ -41,11 +45,11 @@ func NewMemMeasurement(start time.Time) *MemMeasurement {
 	nd := common.ND(0.0, float64(bytesTotal)/64)
 
 	// used bytes
-	sub.Distributions[0] = common.CWD(nd, 0.0, float64(bytesTotal), rand.Float64()*float64(bytesTotal))
+	sub.Distributions[0] = common.CWD(nd, 0.0, float64(bytesTotal), r.Float64()*float64(bytesTotal))
 	// cached bytes
-	sub.Distributions[1] = common.CWD(nd, 0.0, float64(bytesTotal), rand.Float64()*float64(bytesTotal))
+	sub.Distributions[1] = common.CWD(nd, 0.0, float64(bytesTotal), r.Float64()*float64(bytesTotal))
 	// buffered bytes
-	sub.Distributions[2] = common.CWD(nd, 0.0, float64(bytesTotal), rand.Float64()*float64(bytesTotal))
+	sub.Distributions[2] = common.CWD(nd, 0.0, float64(bytesTotal), r.Float64()*float64(bytesTotal))
 	return &MemMeasurement{
 		SubsystemMeasurement: sub,
 		bytesTotal:           bytesTotal,
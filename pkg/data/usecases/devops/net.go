@@ -36,8 +36,12 @@ type NetMeasurement struct {
 }
 
 func NewNetMeasurement(start time.Time) *NetMeasurement {
+	return newNetMeasurement(start, common.GlobalRand())
+}
+
+func newNetMeasurement(start time.Time, r *rand.Rand) *NetMeasurement {
 	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, netFields)
-	interfaceName := fmt.Sprintf("eth%d", rand.Intn(4))
+	interfaceName := fmt.Sprintf("eth%d", r.Intn(4))
 	return &NetMeasurement{
 		SubsystemMeasurement: sub,
 		interfaceName:        interfaceName,
@@ -36,9 +36,13 @@ type NginxMeasurement struct {
 }
 
 func NewNginxMeasurement(start time.Time) *NginxMeasurement {
+	return newNginxMeasurement(start, common.GlobalRand())
+}
+
+func newNginxMeasurement(start time.Time, r *rand.Rand) *NginxMeasurement {
 	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, nginxFields)
-	serverName := fmt.Sprintf("nginx_%d", rand.Intn(100000))
-	port := strconv.FormatInt(rand.Int63n(20000)+1024, 10)
+	serverName := fmt.Sprintf("nginx_%d", r.Intn(100000))
+	port := strconv.FormatInt(r.Int63n(20000)+1024, 10)
 	return &NginxMeasurement{
 		SubsystemMeasurement: sub,
 		port:                 port,
@@ -67,9 +67,13 @@ type RedisMeasurement struct {
 }
 
 func NewRedisMeasurement(start time.Time) *RedisMeasurement {
+	return newRedisMeasurement(start, common.GlobalRand())
+}
+
+func newRedisMeasurement(start time.Time, r *rand.Rand) *RedisMeasurement {
 	sub := common.NewSubsystemMeasurementWithDistributionMakers(start, redisFields)
-	serverName := fmt.Sprintf("redis_%d", rand.Intn(100000))
-	port := strconv.FormatInt(rand.Int63n(20000)+1024, 10)
+	serverName := fmt.Sprintf("redis_%d", r.Intn(100000))
+	port := strconv.FormatInt(r.Int63n(20000)+1024, 10)
 	return &RedisMeasurement{
 		SubsystemMeasurement: sub,
 		port:                 port,
@@ -0,0 +1,94 @@
+package energy
+
+import "fmt"
+
+const (
+	// MetersPerFeeder is the number of meters on a feeder; meter i is on
+	// feeder i / MetersPerFeeder.
+	MetersPerFeeder = 200
+	// FeedersPerRegion is the number of feeders of a region; feeder i is in
+	// region i / FeedersPerRegion.
+	FeedersPerRegion = 50
+)
+
+// meterType is a kind of customer, with its own load curve.
+type meterType struct {
+	name string
+	// share is the fraction of the meters of this type
+	share float64
+	// medianLoad is the median of the mean load of the meters, in kW
+	medianLoad float64
+	// powerFactor is the mean power factor of the meters
+	powerFactor float64
+	// heating and cooling are the increases of the load in the middle of the
+	// winter and of the summer
+	heating float64
+	cooling float64
+	// daily returns the load at hour of the day (UTC) of a weekday or a
+	// weekend day, relative to the mean load
+	daily func(hour float64, weekend bool) float64
+}
+
+var meterTypes = []meterType{
+	{
+		name:        "residential",
+		share:       0.85,
+		medianLoad:  0.6,
+		powerFactor: 0.95,
+		heating:     0.4,
+		cooling:     0.3,
+		daily:       residentialLoad,
+	},
+	{
+		name:        "commercial",
+		share:       0.12,
+		medianLoad:  12,
+		powerFactor: 0.9,
+		heating:     0.2,
+		cooling:     0.35,
+		daily:       commercialLoad,
+	},
+	{
+		name:        "industrial",
+		share:       0.03,
+		medianLoad:  150,
+		powerFactor: 0.85,
+		heating:     0.05,
+		cooling:     0.05,
+		daily:       industrialLoad,
+	},
+}
+
+// MeterTypes returns the names of the meter types.
+func MeterTypes() []string {
+	names := make([]string, len(meterTypes))
+	for i, t := range meterTypes {
+		names[i] = t.name
+	}
+	return names
+}
+
+// FeederCount returns the number of feeders of meters meters.
+func FeederCount(meters int) int {
+	return (meters + MetersPerFeeder - 1) / MetersPerFeeder
+}
+
+// RegionCount returns the number of regions of meters meters.
+func RegionCount(meters int) int {
+	return (FeederCount(meters) + FeedersPerRegion - 1) / FeedersPerRegion
+}
+
+// MeterName returns the name of meter i, e.g. "meter_0".
+func MeterName(i int) string {
+	return fmt.Sprintf("meter_%d", i)
+}
+
+// FeederName returns the name of feeder i, e.g. "feeder_0".
+func FeederName(i int) string {
+	return fmt.Sprintf("feeder_%d", i)
+}
+
+// RegionName returns the name of region i, e.g. "region_0".
+func RegionName(i int) string {
+	return fmt.Sprintf("region_%d", i)
+}
@@ -0,0 +1,70 @@
+package energy
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// winterPeak and summerPeak are the days of the year with the most
+	// heating and cooling, in the northern hemisphere.
+	winterPeak = 15
+	summerPeak = 196
+
+	daysPerYear = 365.25
+)
+
+// bump is a bell curve of height 1 centered on hour center and of width
+// width, in hours.
+func bump(hour, center, width float64) float64 {
+	d := (hour - center) / width
+	return math.Exp(-d * d / 2)
+}
+
+// rampUp goes from 0 to 1 around hour at, over about an hour.
+func rampUp(hour, at float64) float64 {
+	return 1 / (1 + math.Exp(-4*(hour-at)))
+}
+
+// residentialLoad has a morning and a higher evening peak, later in the
+// morning on weekends.
+func residentialLoad(hour float64, weekend bool) float64 {
+	morning := 7.5
+	if weekend {
+		morning = 9.5
+	}
+	return 0.55 + 0.6*bump(hour, morning, 1.5) + 1.2*bump(hour, 19, 2)
+}
+
+// commercialLoad follows the business hours of the weekdays.
+func commercialLoad(hour float64, weekend bool) float64 {
+	if weekend {
+		return 0.45
+	}
+	return 0.45 + 1.5*rampUp(hour, 8)*(1-rampUp(hour, 18))
+}
+
+// industrialLoad is high over two shifts on weekdays.
+func industrialLoad(hour float64, weekend bool) float64 {
+	if weekend {
+		return 0.7
+	}
+	return 0.7 + 0.5*rampUp(hour, 6)*(1-rampUp(hour, 22))
+}
+
+// seasonalLoad returns the load of a meter type at the day of the year of t,
+// relative to its load in spring or autumn.
+func seasonalLoad(mt *meterType, t time.Time) float64 {
+	day := float64(t.YearDay())
+	winter := math.Cos(2 * math.Pi * (day - winterPeak) / daysPerYear)
+	summer := math.Cos(2 * math.Pi * (day - summerPeak) / daysPerYear)
+	return 1 + mt.heating*math.Max(0, winter) + mt.cooling*math.Max(0, summer)
+}
+
+// typicalLoad returns the load of a meter type at t relative to its mean
+// load, without the noise of the meters.
+func typicalLoad(mt *meterType, t time.Time) float64 {
+	hour := float64(t.Hour()) + float64(t.Minute())/60
+	weekend := t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	return mt.daily(hour, weekend) * seasonalLoad(mt, t)
+}
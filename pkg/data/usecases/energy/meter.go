@@ -0,0 +1,137 @@
+package energy
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+const (
+	nominalVoltage   = 230.0
+	nominalFrequency = 50.0
+
+	// voltageDrop is the relative drop of the voltage of a meter drawing
+	// twice its mean load
+	voltageDrop = 0.02
+	// loadSpread is the standard deviation of the logarithm of the mean load
+	// of the meters of a type
+	loadSpread = 0.5
+	// noiseMemory and noiseSpread are the autocorrelation between two
+	// readings and the standard deviation of the logarithm of the noise of
+	// the load of a meter
+	noiseMemory = 0.8
+	noiseSpread = 0.25
+	// eventsPerHour is the rate of the power quality events of a meter
+	eventsPerHour = 0.002
+)
+
+var (
+	tagKeys = [][]byte{
+		[]byte("meter"),
+		[]byte("region"),
+		[]byte("feeder"),
+		[]byte("meter_type"),
+	}
+
+	readingMeasurement = []byte("meter_readings")
+	eventMeasurement   = []byte("power_quality")
+
+	readingFields = [][]byte{
+		[]byte("energy_kwh"),
+		[]byte("demand_kw"),
+		[]byte("voltage"),
+		[]byte("power_factor"),
+	}
+	eventFields = [][]byte{
+		[]byte("voltage"),
+		[]byte("frequency"),
+		[]byte("duration_seconds"),
+	}
+)
+
+// meter is a smart meter reading the consumption of a customer.
+type meter struct {
+	tags     []string
+	kind     *meterType
+	meanLoad float64 // kW
+	noise    float64
+
+	// deliverAt is when the readings held back in backlog are delivered, or
+	// zero if the readings of the meter are delivered on time
+	deliverAt time.Time
+	backlog   []*data.Point
+}
+
+// newMeter returns meter i, of a random type.
+func newMeter(i int) *meter {
+	kind := &meterTypes[len(meterTypes)-1]
+	r := rand.Float64()
+	for j := range meterTypes {
+		if r < meterTypes[j].share {
+			kind = &meterTypes[j]
+			break
+		}
+		r -= meterTypes[j].share
+	}
+	feeder := i / MetersPerFeeder
+	return &meter{
+		tags: []string{
+			MeterName(i),
+			RegionName(feeder / FeedersPerRegion),
+			FeederName(feeder),
+			kind.name,
+		},
+		kind:     kind,
+		meanLoad: kind.medianLoad * math.Exp(loadSpread*rand.NormFloat64()),
+	}
+}
+
+// load returns the load of the meter at t, in kW.
+func (m *meter) load(t time.Time) float64 {
+	m.noise = noiseMemory*m.noise + math.Sqrt(1-noiseMemory*noiseMemory)*noiseSpread*rand.NormFloat64()
+	return m.meanLoad * typicalLoad(m.kind, t) * math.Exp(m.noise)
+}
+
+func (m *meter) appendTags(p *data.Point) {
+	for i, v := range m.tags {
+		p.AppendTag(tagKeys[i], v)
+	}
+}
+
+// readingToPoint writes the reading of the interval d starting at t to p.
+func (m *meter) readingToPoint(t time.Time, d time.Duration, p *data.Point) {
+	load := m.load(t)
+	p.SetTimestamp(&t)
+	p.SetMeasurementName(readingMeasurement)
+	m.appendTags(p)
+	p.AppendField(readingFields[0], load*d.Hours())
+	p.AppendField(readingFields[1], load*(1+0.3*math.Abs(rand.NormFloat64())))
+	p.AppendField(readingFields[2], nominalVoltage*(1-voltageDrop*(load/m.meanLoad-1))+0.8*rand.NormFloat64())
+	p.AppendField(readingFields[3], math.Min(1, m.kind.powerFactor+0.02*rand.NormFloat64()))
+}
+
+// eventToPoint writes a voltage sag or swell, or a frequency excursion, seen
+// by the meter at t to p.
+func (m *meter) eventToPoint(t time.Time, frequency float64, p *data.Point) {
+	voltage := nominalVoltage + 0.8*rand.NormFloat64()
+	switch rand.Intn(3) {
+	case 0:
+		voltage = nominalVoltage * (0.7 + 0.2*rand.Float64())
+	case 1:
+		voltage = nominalVoltage * (1.1 + 0.1*rand.Float64())
+	default:
+		excursion := 0.2 + 0.3*rand.Float64()
+		if rand.Intn(2) == 0 {
+			excursion = -excursion
+		}
+		frequency = nominalFrequency + excursion
+	}
+	p.SetTimestamp(&t)
+	p.SetMeasurementName(eventMeasurement)
+	m.appendTags(p)
+	p.AppendField(eventFields[0], voltage)
+	p.AppendField(eventFields[1], frequency)
+	p.AppendField(eventFields[2], math.Exp(1+rand.NormFloat64()))
+}
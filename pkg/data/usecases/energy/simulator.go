@@ -0,0 +1,211 @@
+package energy
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+)
+
+// SimulatorConfig is used to create a Simulator.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitMeterCount is the number of meters to start with, the others are
+	// installed one by one over the run
+	InitMeterCount uint64
+	// MeterCount is the total number of meters
+	MeterCount uint64
+	// LateArrivalRate is the fraction of the meters per day whose readings
+	// are held back and delivered in bulk LateArrivalDelay later
+	LateArrivalRate float64
+	// LateArrivalDelay is how late the late readings are delivered
+	LateArrivalDelay time.Duration
+}
+
+// NewSimulator produces a Simulator reading every meter every interval. Its
+// readings are delivered in batches with missing and out of order readings,
+// the same way as the ones of the IoT use case.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	return iot.NewBatchSimulator(c.newSimulator(interval, limit))
+}
+
+func (c *SimulatorConfig) newSimulator(interval time.Duration, limit uint64) *Simulator {
+	initMeters := c.InitMeterCount
+	if initMeters == 0 || initMeters > c.MeterCount {
+		initMeters = c.MeterCount
+	}
+	s := &Simulator{
+		maxPoints:   limit,
+		epochs:      uint64(c.End.Sub(c.Start).Nanoseconds() / interval.Nanoseconds()),
+		start:       c.Start,
+		interval:    interval,
+		initMeters:  initMeters,
+		meterCount:  c.MeterCount,
+		frequency:   nominalFrequency,
+		lateChance:  math.Min(1, c.LateArrivalRate*interval.Hours()/24),
+		lateDelay:   c.LateArrivalDelay,
+		eventChance: math.Min(1, eventsPerHour*interval.Hours()),
+	}
+	for uint64(len(s.meters)) < initMeters {
+		s.meters = append(s.meters, newMeter(len(s.meters)))
+	}
+	return s
+}
+
+// Simulator simulates the readings of a set of smart meters and the power
+// quality events they report. The readings of some meters are held back and
+// delivered hours later in bulk, as when a meter or its collector goes
+// offline; the events are always delivered on time.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+
+	epoch    uint64
+	epochs   uint64
+	start    time.Time
+	interval time.Duration
+
+	initMeters uint64
+	meterCount uint64
+	meters     []*meter
+	meterIndex int
+
+	frequency   float64 // of the grid
+	lateChance  float64 // of a meter going late, per reading
+	lateDelay   time.Duration
+	eventChance float64 // of a meter reporting an event, per reading
+
+	// queue holds the points to deliver before simulating the next meter
+	queue []*data.Point
+	// late is the number of readings held back by the meters
+	late int
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return (s.epoch >= s.epochs && len(s.queue) == 0 && s.late == 0) ||
+		(s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next point to deliver to p. The readings still held back
+// at the end time are delivered last.
+func (s *Simulator) Next(p *data.Point) bool {
+	for len(s.queue) == 0 {
+		if s.epoch >= s.epochs {
+			for _, m := range s.meters {
+				s.deliverBacklog(m)
+			}
+			if len(s.queue) == 0 {
+				return false
+			}
+			break
+		}
+		s.simulateMeter()
+	}
+	p.Copy(s.queue[0])
+	s.queue[0] = nil
+	s.queue = s.queue[1:]
+	s.madePoints++
+	return true
+}
+
+// simulateMeter queues the reading of the current meter in the current
+// epoch, or holds it back, and its event if it reports one.
+func (s *Simulator) simulateMeter() {
+	m := s.meters[s.meterIndex]
+	ts := s.start.Add(time.Duration(s.epoch) * s.interval)
+
+	reading := data.NewPoint()
+	m.readingToPoint(ts, s.interval, reading)
+	if m.deliverAt.IsZero() && rand.Float64() < s.lateChance {
+		m.deliverAt = ts.Add(s.lateDelay)
+	}
+	if m.deliverAt.IsZero() {
+		s.queue = append(s.queue, reading)
+	} else {
+		m.backlog = append(m.backlog, reading)
+		s.late++
+		if !ts.Before(m.deliverAt) {
+			s.deliverBacklog(m)
+		}
+	}
+
+	if rand.Float64() < s.eventChance {
+		event := data.NewPoint()
+		m.eventToPoint(ts.Add(time.Duration(rand.Int63n(int64(s.interval)))), s.frequency, event)
+		s.queue = append(s.queue, event)
+	}
+
+	s.meterIndex++
+	if s.meterIndex == len(s.meters) {
+		s.meterIndex = 0
+		s.nextEpoch()
+	}
+}
+
+// deliverBacklog queues the readings held back by m.
+func (s *Simulator) deliverBacklog(m *meter) {
+	s.queue = append(s.queue, m.backlog...)
+	s.late -= len(m.backlog)
+	m.backlog = nil
+	m.deliverAt = time.Time{}
+}
+
+// nextEpoch advances the grid frequency and installs the meters of the
+// epoch.
+func (s *Simulator) nextEpoch() {
+	s.epoch++
+	s.frequency = nominalFrequency + 0.9*(s.frequency-nominalFrequency) + 0.01*rand.NormFloat64()
+
+	if s.epochs > 1 {
+		missing := float64(s.meterCount - s.initMeters)
+		target := s.initMeters + uint64(missing*float64(s.epoch)/float64(s.epochs-1))
+		for uint64(len(s.meters)) < target && uint64(len(s.meters)) < s.meterCount {
+			s.meters = append(s.meters, newMeter(len(s.meters)))
+		}
+	}
+}
+
+// Fields returns the fields of the readings and of the events.
+func (s *Simulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(readingMeasurement): bytesToStrings(readingFields),
+		string(eventMeasurement):   bytesToStrings(eventFields),
+	}
+}
+
+// TagKeys returns the tag keys of the meters.
+func (s *Simulator) TagKeys() []string {
+	return bytesToStrings(tagKeys)
+}
+
+// TagTypes returns the types of the tags of the meters.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+func bytesToStrings(in [][]byte) []string {
+	out := make([]string, len(in))
+	for i, b := range in {
+		out[i] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package energy
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var testStart = time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC) // a Monday
+
+func TestRegionCount(t *testing.T) {
+	cases := []struct {
+		meters  int
+		feeders int
+		regions int
+	}{
+		{meters: 1, feeders: 1, regions: 1},
+		{meters: MetersPerFeeder, feeders: 1, regions: 1},
+		{meters: MetersPerFeeder + 1, feeders: 2, regions: 1},
+		{meters: MetersPerFeeder*FeedersPerRegion + 1, feeders: FeedersPerRegion + 1, regions: 2},
+		{meters: 1000000, feeders: 5000, regions: 100},
+	}
+	for _, c := range cases {
+		if got := FeederCount(c.meters); got != c.feeders {
+			t.Errorf("incorrect feeder count for %d meters: got %d want %d", c.meters, got, c.feeders)
+		}
+		if got := RegionCount(c.meters); got != c.regions {
+			t.Errorf("incorrect region count for %d meters: got %d want %d", c.meters, got, c.regions)
+		}
+	}
+}
+
+func TestNewMeterTags(t *testing.T) {
+	i := MetersPerFeeder*FeedersPerRegion + MetersPerFeeder + 3
+	m := newMeter(i)
+	want := []string{MeterName(i), RegionName(1), FeederName(FeedersPerRegion + 1)}
+	for j, v := range want {
+		if m.tags[j] != v {
+			t.Errorf("incorrect tag %s: got %s want %s", tagKeys[j], m.tags[j], v)
+		}
+	}
+	if m.tags[3] != m.kind.name {
+		t.Errorf("incorrect meter type tag: got %s want %s", m.tags[3], m.kind.name)
+	}
+}
+
+func TestTypicalLoad(t *testing.T) {
+	at := func(day, hour int) time.Time {
+		return testStart.Add(time.Duration(24*day+hour) * time.Hour)
+	}
+	residential, commercial := &meterTypes[0], &meterTypes[1]
+	if typicalLoad(residential, at(0, 19)) <= typicalLoad(residential, at(0, 3)) {
+		t.Errorf("residential evening load not above the night load")
+	}
+	if typicalLoad(commercial, at(0, 12)) <= typicalLoad(commercial, at(5, 12)) {
+		t.Errorf("commercial weekday load not above the weekend load")
+	}
+
+	spring := time.Date(2016, 4, 15, 12, 0, 0, 0, time.UTC)
+	summer := time.Date(2016, 7, 15, 12, 0, 0, 0, time.UTC)
+	winter := time.Date(2016, 1, 15, 12, 0, 0, 0, time.UTC)
+	for _, mt := range []*meterType{residential, commercial} {
+		if seasonalLoad(mt, winter) <= seasonalLoad(mt, spring) || seasonalLoad(mt, summer) <= seasonalLoad(mt, spring) {
+			t.Errorf("%s load not higher in winter and summer than in spring", mt.name)
+		}
+	}
+}
+
+// runSimulator runs s and returns the readings delivered, per meter, and the
+// events.
+func runSimulator(t *testing.T, s *Simulator) (map[string][]time.Time, int) {
+	readings := map[string][]time.Time{}
+	events := 0
+	p := data.NewPoint()
+	for !s.Finished() {
+		if !s.Next(p) {
+			t.Fatalf("point not written")
+		}
+		if string(p.MeasurementName()) == string(eventMeasurement) {
+			events++
+		} else {
+			meter := p.GetTagValue(tagKeys[0]).(string)
+			readings[meter] = append(readings[meter], *p.Timestamp())
+		}
+		p.Reset()
+	}
+	return readings, events
+}
+
+func TestSimulatorOnTime(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:      testStart,
+		End:        testStart.Add(24 * time.Hour),
+		MeterCount: 20,
+	}
+	readings, _ := runSimulator(t, c.newSimulator(15*time.Minute, 0))
+	if len(readings) != 20 {
+		t.Errorf("incorrect number of meters: got %d want 20", len(readings))
+	}
+	for meter, ts := range readings {
+		if len(ts) != 96 {
+			t.Errorf("incorrect number of readings of %s: got %d want 96", meter, len(ts))
+		}
+		for i := 1; i < len(ts); i++ {
+			if !ts[i].Equal(ts[i-1].Add(15 * time.Minute)) {
+				t.Errorf("readings of %s not delivered in order: %v after %v", meter, ts[i], ts[i-1])
+				break
+			}
+		}
+	}
+}
+
+func TestSimulatorLateArrival(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:            testStart,
+		End:              testStart.Add(48 * time.Hour),
+		MeterCount:       50,
+		LateArrivalRate:  1,
+		LateArrivalDelay: 4 * time.Hour,
+	}
+	s := c.newSimulator(15*time.Minute, 0)
+	readings := map[string]int{}
+	var latest time.Time
+	late := 0
+	p := data.NewPoint()
+	for !s.Finished() {
+		s.Next(p)
+		if string(p.MeasurementName()) == string(readingMeasurement) {
+			readings[p.GetTagValue(tagKeys[0]).(string)]++
+			ts := *p.Timestamp()
+			if ts.After(latest) {
+				latest = ts
+			}
+			if latest.Sub(ts) > c.LateArrivalDelay && latest.Before(c.End.Add(-c.LateArrivalDelay)) {
+				t.Errorf("reading at %v delivered after one at %v", ts, latest)
+			}
+			if latest.Sub(ts) > c.LateArrivalDelay/2 {
+				late++
+			}
+		}
+		p.Reset()
+	}
+
+	for meter, n := range readings {
+		if n != 192 {
+			t.Errorf("incorrect number of readings of %s: got %d want 192", meter, n)
+		}
+	}
+	// every meter goes late about twice, holding back 17 readings each time,
+	// the first half of which are more than half the delay late
+	if want := 50 * 2 * 8; late < want/2 || late > 2*want {
+		t.Errorf("incorrect number of late readings: got %d want about %d", late, want)
+	}
+	if s.late != 0 {
+		t.Errorf("readings still held back at the end: %d", s.late)
+	}
+}
+
+func TestSimulatorMetersInstalled(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:          testStart,
+		End:            testStart.Add(24 * time.Hour),
+		InitMeterCount: 1,
+		MeterCount:     10,
+	}
+	s := c.newSimulator(15*time.Minute, 0)
+	readings, _ := runSimulator(t, s)
+	if len(s.meters) != 10 || len(readings) != 10 {
+		t.Errorf("incorrect number of meters at the end: got %d want 10", len(s.meters))
+	}
+	if n := len(readings[MeterName(0)]); n != 96 {
+		t.Errorf("incorrect number of readings of the first meter: got %d want 96", n)
+	}
+	if n := len(readings[MeterName(9)]); n >= 96 {
+		t.Errorf("last meter installed from the start: %d readings", n)
+	}
+}
+
+func TestSimulatorEvents(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:      testStart,
+		End:        testStart.Add(10 * 24 * time.Hour),
+		MeterCount: 100,
+	}
+	_, events := runSimulator(t, c.newSimulator(15*time.Minute, 0))
+	want := eventsPerHour * 100 * 240
+	if math.Abs(float64(events)-want) > want/2 {
+		t.Errorf("incorrect number of events: got %d want about %f", events, want)
+	}
+}
+
+func TestNewSimulatorOutOfOrder(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:      testStart,
+		End:        testStart.Add(24 * time.Hour),
+		MeterCount: 20,
+	}
+	sim := c.NewSimulator(15*time.Minute, 0)
+	written := 0
+	p := data.NewPoint()
+	for !sim.Finished() {
+		if sim.Next(p) {
+			written++
+		}
+		p.Reset()
+	}
+	if written == 0 || written >= 20*96 {
+		t.Errorf("readings not dropped by the batches: %d written", written)
+	}
+}
@@ -0,0 +1,214 @@
+package events
+
+import (
+	"encoding/hex"
+	"fmt"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var (
+	tagKeys = [][]byte{
+		[]byte("host"),
+		[]byte("service"),
+		[]byte("region"),
+	}
+
+	logMeasurement = []byte("logs")
+
+	// logFields are the fields of the log lines. Only the first four are
+	// set on every line, the others are attributes set depending on the
+	// service and on what is logged.
+	logFields = [][]byte{
+		[]byte("severity"),
+		[]byte("message"),
+		[]byte("trace_id"),
+		[]byte("span_id"),
+		[]byte("http_method"),
+		[]byte("http_route"),
+		[]byte("http_status"),
+		[]byte("duration_ms"),
+		[]byte("user_id"),
+		[]byte("db_statement"),
+		[]byte("error_type"),
+		[]byte("retry_count"),
+		[]byte("bytes"),
+	}
+	logFieldTypes = []string{
+		"string",
+		"string",
+		"string",
+		"string",
+		"string",
+		"string",
+		"int64",
+		"float64",
+		"string",
+		"string",
+		"string",
+		"int64",
+		"int64",
+	}
+)
+
+const (
+	fieldSeverity = iota
+	fieldMessage
+	fieldTraceID
+	fieldSpanID
+	fieldHTTPMethod
+	fieldHTTPRoute
+	fieldHTTPStatus
+	fieldDuration
+	fieldUserID
+	fieldDBStatement
+	fieldErrorType
+	fieldRetryCount
+	fieldBytes
+)
+
+const (
+	// userShare is the fraction of the requests made by a signed in user
+	userShare = 0.4
+	// userCount is the number of users of the services
+	userCount = 100000
+	// resourceCount is the number of resources (products, orders...) the
+	// requests are made on
+	resourceCount = 1000000
+	// medianDuration is the median duration of a request or a job, in ms
+	medianDuration = 40.0
+	// slowDuration is the duration above which a request is logged as slow,
+	// in ms
+	slowDuration = 1000.0
+)
+
+// host is a server running a service.
+type host struct {
+	tags    []string
+	service *service
+}
+
+// newHost returns host i.
+func newHost(i int) *host {
+	svc := &services[i%len(services)]
+	return &host{
+		tags: []string{
+			HostName(i),
+			svc.name,
+			Regions[i/len(services)%len(Regions)],
+		},
+		service: svc,
+	}
+}
+
+// logToPoint writes a log line of the given severity written by the host at
+// t to p. The errors are the ones of incident, if not nil.
+func (h *host) logToPoint(t time.Time, severity int, incident *serviceError, p *data.Point) {
+	values := make([]interface{}, len(logFields))
+	values[fieldSeverity] = Severities[severity]
+	values[fieldTraceID] = randomHex(16)
+	values[fieldSpanID] = randomHex(8)
+
+	svc := h.service
+	switch severity {
+	case severityDebug:
+		h.debug(values)
+	case severityInfo:
+		if svc.routes != nil {
+			h.request(values, 200+int64(rand.Intn(2)), "completed with status %d in %.0fms")
+		} else {
+			duration := randomDuration()
+			values[fieldDuration] = duration
+			values[fieldMessage] = fmt.Sprintf("processed job %s in %.0fms", randomHex(6), duration)
+		}
+	case severityWarn:
+		if svc.routes != nil && rand.Intn(2) == 0 {
+			h.request(values, 200, "")
+			values[fieldDuration] = slowDuration * (1 + rand.ExpFloat64())
+			values[fieldMessage] = fmt.Sprintf("slow request %s %s took %.0fms", values[fieldHTTPMethod], values[fieldHTTPRoute], values[fieldDuration])
+		} else {
+			retry := int64(1 + rand.Intn(3))
+			dependency := svc.dependencies[rand.Intn(len(svc.dependencies))]
+			values[fieldRetryCount] = retry
+			values[fieldMessage] = fmt.Sprintf("retrying call to %s (attempt %d)", dependency, retry+1)
+		}
+	default:
+		e := incident
+		if e == nil || rand.Intn(4) == 0 {
+			e = &svc.errors[rand.Intn(len(svc.errors))]
+		}
+		if svc.routes != nil {
+			h.request(values, e.status, "")
+		}
+		values[fieldErrorType] = e.errorType
+		values[fieldMessage] = e.message
+	}
+
+	p.SetTimestamp(&t)
+	p.SetMeasurementName(logMeasurement)
+	for i, v := range h.tags {
+		p.AppendTag(tagKeys[i], v)
+	}
+	for i, v := range values {
+		p.AppendField(logFields[i], v)
+	}
+}
+
+// request sets the HTTP attributes of a request to a random route of the
+// service returning status, and its message from format, which is given the
+// status and the duration, if not empty.
+func (h *host) request(values []interface{}, status int64, format string) {
+	r := h.service.routes[rand.Intn(len(h.service.routes))]
+	path := r.path
+	if strings.Contains(r.path, "%d") {
+		path = fmt.Sprintf(r.path, rand.Intn(resourceCount))
+	}
+	route := strings.Replace(r.path, "%d", "{id}", 1)
+	duration := randomDuration()
+	values[fieldHTTPMethod] = r.method
+	values[fieldHTTPRoute] = route
+	values[fieldHTTPStatus] = status
+	values[fieldDuration] = duration
+	values[fieldBytes] = int64(200 + rand.ExpFloat64()*4000)
+	if rand.Float64() < userShare {
+		values[fieldUserID] = fmt.Sprintf("user_%d", rand.Intn(userCount))
+	}
+	if format != "" {
+		values[fieldMessage] = fmt.Sprintf("%s %s "+format, r.method, path, status, duration)
+	}
+}
+
+// debug sets the message of a debug line: a query, with its statement, for
+// the services using a database, or a cache lookup.
+func (h *host) debug(values []interface{}) {
+	svc := h.service
+	if svc.tables != nil && rand.Intn(2) == 0 {
+		table := svc.tables[rand.Intn(len(svc.tables))]
+		duration := randomDuration() / 10
+		values[fieldDBStatement] = fmt.Sprintf("SELECT * FROM %s WHERE id = $1", table)
+		values[fieldDuration] = duration
+		values[fieldMessage] = fmt.Sprintf("query on %s returned %d rows in %.1fms", table, rand.Intn(100), duration)
+		return
+	}
+	outcome := "hit"
+	if rand.Intn(3) == 0 {
+		outcome = "miss"
+	}
+	values[fieldMessage] = fmt.Sprintf("cache %s for key %s:%d", outcome, svc.name, rand.Intn(resourceCount))
+}
+
+// randomDuration returns the duration of a request or a job, in ms.
+func randomDuration() float64 {
+	return math.Round(10*medianDuration*math.Exp(0.8*rand.NormFloat64())) / 10
+}
+
+// randomHex returns n random bytes in hexadecimal.
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,161 @@
+package events
+
+import "fmt"
+
+// Severities are the severities of the log lines, from the least to the most
+// severe.
+var Severities = []string{"debug", "info", "warn", "error"}
+
+const (
+	severityDebug = iota
+	severityInfo
+	severityWarn
+	severityError
+)
+
+// severityShares are the fractions of the log lines of each severity, outside
+// of and during an incident.
+var (
+	severityShares = []float64{0.3, 0.55, 0.1, 0.05}
+	incidentShares = []float64{0.2, 0.3, 0.15, 0.35}
+)
+
+// serviceError is a failure a service logs, always with the same message so
+// that the most frequent errors can be told apart.
+type serviceError struct {
+	errorType string
+	message   string
+	status    int64 // returned to the client, for HTTP services
+}
+
+// route is an HTTP endpoint of a service. Its path has a %d verb for the id
+// of the resource requested, written {id} in the route attribute.
+type route struct {
+	method string
+	path   string
+}
+
+// service is an application running on hosts and writing log lines.
+type service struct {
+	name string
+	// routes are the endpoints of HTTP services, nil for workers
+	routes []route
+	// dependencies are the services and databases called by the service
+	dependencies []string
+	// tables are the tables the service queries
+	tables []string
+	errors []serviceError
+}
+
+var services = []service{
+	{
+		name: "api-gateway",
+		routes: []route{
+			{"GET", "/api/v1/products/%d"},
+			{"GET", "/api/v1/orders/%d"},
+			{"POST", "/api/v1/orders"},
+		},
+		dependencies: []string{"checkout", "search", "auth"},
+		errors: []serviceError{
+			{"TimeoutError", "upstream checkout timed out after 30s", 504},
+			{"ConnectionError", "connection refused by auth:8080", 502},
+			{"RateLimitError", "rate limit exceeded for client", 429},
+		},
+	},
+	{
+		name: "checkout",
+		routes: []route{
+			{"POST", "/checkout/%d/confirm"},
+			{"GET", "/checkout/%d"},
+			{"PUT", "/checkout/%d/items"},
+		},
+		dependencies: []string{"payments", "inventory", "checkout-db"},
+		tables:       []string{"carts", "orders"},
+		errors: []serviceError{
+			{"KeyError", `key "cart" not found in session`, 500},
+			{"DeadlockError", "deadlock detected while updating table orders", 500},
+			{"TimeoutError", "call to payments timed out after 10s", 503},
+		},
+	},
+	{
+		name: "search",
+		routes: []route{
+			{"GET", "/search/products/%d"},
+			{"GET", "/search/suggest/%d"},
+		},
+		dependencies: []string{"search-index"},
+		errors: []serviceError{
+			{"ConnectionError", "connection reset by search-index:9200", 502},
+			{"QueryError", "query parse error near unexpected token", 400},
+		},
+	},
+	{
+		name: "auth",
+		routes: []route{
+			{"POST", "/auth/login"},
+			{"POST", "/auth/token/%d/refresh"},
+		},
+		dependencies: []string{"auth-db"},
+		tables:       []string{"users", "sessions"},
+		errors: []serviceError{
+			{"AuthenticationError", "invalid token signature", 401},
+			{"ConnectionError", "connection refused by auth-db:5432", 503},
+		},
+	},
+	{
+		name:         "payments",
+		dependencies: []string{"payment-provider", "payments-db"},
+		tables:       []string{"payments", "refunds"},
+		errors: []serviceError{
+			{"PaymentError", "card declined by payment provider", 0},
+			{"TimeoutError", "payment provider did not answer within 15s", 0},
+			{"NullPointerException", "NullPointerException in PaymentProcessor.capture", 0},
+		},
+	},
+	{
+		name:         "inventory",
+		dependencies: []string{"inventory-db", "warehouse-api"},
+		tables:       []string{"stock", "reservations"},
+		errors: []serviceError{
+			{"ConstraintError", "duplicate key value violates unique constraint reservations_pkey", 0},
+			{"OutOfMemoryError", "java.lang.OutOfMemoryError: Java heap space", 0},
+		},
+	},
+	{
+		name:         "notifications",
+		dependencies: []string{"smtp-relay", "push-gateway"},
+		errors: []serviceError{
+			{"ConnectionError", "connection timed out to smtp-relay:587", 0},
+			{"ValidationError", "invalid recipient address", 0},
+		},
+	},
+}
+
+// Services returns the names of the services.
+func Services() []string {
+	names := make([]string, len(services))
+	for i, s := range services {
+		names[i] = s.name
+	}
+	return names
+}
+
+// ErrorMessages returns the messages of the errors logged by the services.
+func ErrorMessages() []string {
+	var messages []string
+	for _, s := range services {
+		for _, e := range s.errors {
+			messages = append(messages, e.message)
+		}
+	}
+	return messages
+}
+
+// Regions are the regions the hosts are in.
+var Regions = []string{"us-east-1", "us-west-2", "eu-west-1", "eu-central-1", "ap-southeast-1"}
+
+// HostName returns the name of host i, which runs service
+// i % len(Services()) in region i / len(Services()) % len(Regions).
+func HostName(i int) string {
+	return fmt.Sprintf("host_%d", i)
+}
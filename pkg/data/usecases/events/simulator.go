@@ -0,0 +1,235 @@
+package events
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	// logsPerSecond is the mean rate of the log lines of a host
+	logsPerSecond = 1.0
+	// incidentsPerHour is the rate of the incidents of a service
+	incidentsPerHour = 0.1
+	// incidentDuration is the mean duration of an incident
+	incidentDuration = 15 * time.Minute
+)
+
+// SimulatorConfig is used to create a Simulator.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitHostCount is the number of hosts to start with, the others are
+	// started one by one over the run
+	InitHostCount uint64
+	// HostCount is the total number of hosts
+	HostCount uint64
+}
+
+// NewSimulator produces a Simulator writing the log lines of every host in
+// every interval.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	initHosts := c.InitHostCount
+	if initHosts == 0 || initHosts > c.HostCount {
+		initHosts = c.HostCount
+	}
+	s := &Simulator{
+		maxPoints:      limit,
+		epochs:         uint64(c.End.Sub(c.Start).Nanoseconds() / interval.Nanoseconds()),
+		start:          c.Start,
+		interval:       interval,
+		initHosts:      initHosts,
+		hostCount:      c.HostCount,
+		meanLogs:       logsPerSecond * interval.Seconds(),
+		incidentChance: math.Min(1, incidentsPerHour*interval.Hours()),
+		incidents:      make([]incident, len(services)),
+	}
+	for uint64(len(s.hosts)) < initHosts {
+		s.hosts = append(s.hosts, newHost(len(s.hosts)))
+	}
+	return s
+}
+
+// incident is a failure of a service, during which its hosts log many more
+// errors, most of them the same one.
+type incident struct {
+	until time.Time
+	err   *serviceError
+}
+
+// Simulator simulates the log lines written by a set of hosts. Each host
+// writes a random number of lines at random times in every interval, more of
+// them errors while its service has an incident.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+
+	epoch    uint64
+	epochs   uint64
+	start    time.Time
+	interval time.Duration
+
+	initHosts uint64
+	hostCount uint64
+	hosts     []*host
+	hostIndex int
+
+	meanLogs       float64 // per host per interval
+	incidentChance float64 // of a service having an incident, per interval
+	incidents      []incident
+
+	// times are the times of the lines the current host has still to
+	// write in the current epoch
+	times []time.Time
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.epoch >= s.epochs || (s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next log line to p.
+func (s *Simulator) Next(p *data.Point) bool {
+	for len(s.times) == 0 {
+		if s.epoch >= s.epochs {
+			return false
+		}
+		s.times = s.logTimes()
+		if len(s.times) == 0 {
+			s.nextHost()
+		}
+	}
+
+	h := s.hosts[s.hostIndex]
+	inc := s.incidents[s.hostIndex%len(services)]
+	shares := severityShares
+	var err *serviceError
+	if s.times[0].Before(inc.until) {
+		shares = incidentShares
+		err = inc.err
+	}
+	h.logToPoint(s.times[0], randomSeverity(shares), err, p)
+	s.times = s.times[1:]
+	s.madePoints++
+
+	if len(s.times) == 0 {
+		s.nextHost()
+	}
+	return true
+}
+
+// logTimes returns the sorted times of the lines of the current host in the
+// current epoch.
+func (s *Simulator) logTimes() []time.Time {
+	epochStart := s.start.Add(time.Duration(s.epoch) * s.interval)
+	times := make([]time.Time, poisson(s.meanLogs))
+	for i := range times {
+		times[i] = epochStart.Add(time.Duration(rand.Int63n(int64(s.interval))))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	return times
+}
+
+// nextHost moves on to the next host, and to the next epoch after the last
+// one.
+func (s *Simulator) nextHost() {
+	s.hostIndex++
+	if s.hostIndex == len(s.hosts) {
+		s.hostIndex = 0
+		s.nextEpoch()
+	}
+}
+
+// nextEpoch starts the incidents and the hosts of the epoch.
+func (s *Simulator) nextEpoch() {
+	s.epoch++
+	epochStart := s.start.Add(time.Duration(s.epoch) * s.interval)
+	for i := range s.incidents {
+		inc := &s.incidents[i]
+		if !epochStart.Before(inc.until) && rand.Float64() < s.incidentChance {
+			errs := services[i].errors
+			inc.err = &errs[rand.Intn(len(errs))]
+			inc.until = epochStart.Add(time.Duration(rand.ExpFloat64() * float64(incidentDuration)))
+		}
+	}
+
+	if s.epochs > 1 {
+		missing := float64(s.hostCount - s.initHosts)
+		target := s.initHosts + uint64(missing*float64(s.epoch)/float64(s.epochs-1))
+		for uint64(len(s.hosts)) < target && uint64(len(s.hosts)) < s.hostCount {
+			s.hosts = append(s.hosts, newHost(len(s.hosts)))
+		}
+	}
+}
+
+// Fields returns the fields of the log lines.
+func (s *Simulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(logMeasurement): bytesToStrings(logFields),
+	}
+}
+
+// TagKeys returns the tag keys of the hosts.
+func (s *Simulator) TagKeys() []string {
+	return bytesToStrings(tagKeys)
+}
+
+// TagTypes returns the types of the tags of the hosts.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+		FieldTypes: map[string][]string{
+			string(logMeasurement): logFieldTypes,
+		},
+	}
+}
+
+// randomSeverity returns a severity drawn with the given shares.
+func randomSeverity(shares []float64) int {
+	r := rand.Float64()
+	for i, share := range shares {
+		if r < share {
+			return i
+		}
+		r -= share
+	}
+	return len(shares) - 1
+}
+
+// poisson returns a number drawn from the Poisson distribution of the given
+// mean, approximated by a normal distribution for large means.
+func poisson(mean float64) int {
+	if mean > 100 {
+		return int(math.Max(0, math.Round(mean+math.Sqrt(mean)*rand.NormFloat64())))
+	}
+	limit := math.Exp(-mean)
+	n := 0
+	for prod := rand.Float64(); prod > limit; prod *= rand.Float64() {
+		n++
+	}
+	return n
+}
+
+func bytesToStrings(in [][]byte) []string {
+	out := make([]string, len(in))
+	for i, b := range in {
+		out[i] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,174 @@
+package events
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var testStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestNewHostTags(t *testing.T) {
+	i := 2*len(services) + 3
+	h := newHost(i)
+	want := []string{HostName(i), services[3].name, Regions[2]}
+	for j, v := range want {
+		if h.tags[j] != v {
+			t.Errorf("incorrect tag %s: got %s want %s", tagKeys[j], h.tags[j], v)
+		}
+	}
+}
+
+func TestPoisson(t *testing.T) {
+	rand.Seed(123)
+	for _, mean := range []float64{0.5, 10, 1000} {
+		sum := 0
+		for i := 0; i < 1000; i++ {
+			sum += poisson(mean)
+		}
+		if got := float64(sum) / 1000; math.Abs(got-mean) > 0.1*mean {
+			t.Errorf("incorrect mean: got %f want %f", got, mean)
+		}
+	}
+}
+
+func TestLogToPoint(t *testing.T) {
+	rand.Seed(123)
+	p := data.NewPoint()
+	for i := range services {
+		h := newHost(i)
+		for severity := range Severities {
+			for j := 0; j < 100; j++ {
+				h.logToPoint(testStart, severity, nil, p)
+				values := p.FieldValues()
+				if len(values) != len(logFields) {
+					t.Fatalf("incorrect number of fields: got %d want %d", len(values), len(logFields))
+				}
+				if values[fieldSeverity] != Severities[severity] {
+					t.Errorf("incorrect severity: got %v want %s", values[fieldSeverity], Severities[severity])
+				}
+				for k, v := range values {
+					if v == nil {
+						if k <= fieldSpanID {
+							t.Errorf("field %s not set", logFields[k])
+						}
+						continue
+					}
+					switch v.(type) {
+					case string:
+						if logFieldTypes[k] != "string" || strings.ContainsAny(v.(string), ",\n") {
+							t.Errorf("invalid value of field %s: %v", logFields[k], v)
+						}
+					case int64:
+						if logFieldTypes[k] != "int64" {
+							t.Errorf("invalid value of field %s: %v", logFields[k], v)
+						}
+					case float64:
+						if logFieldTypes[k] != "float64" {
+							t.Errorf("invalid value of field %s: %v", logFields[k], v)
+						}
+					}
+				}
+				if severity == severityError && values[fieldErrorType] == nil {
+					t.Errorf("error type of %s not set", services[i].name)
+				}
+				if (values[fieldHTTPStatus] != nil) != (services[i].routes != nil && severity != severityDebug && values[fieldRetryCount] == nil) {
+					t.Errorf("HTTP attributes of %s %s line set incorrectly: %v", services[i].name, Severities[severity], values)
+				}
+				p.Reset()
+			}
+		}
+	}
+}
+
+// runSimulator runs s and returns the lines written per host and per
+// severity.
+func runSimulator(t *testing.T, s *Simulator) (map[string]int, map[string]int) {
+	hosts := map[string]int{}
+	severities := map[string]int{}
+	var last time.Time
+	lastHost := ""
+	p := data.NewPoint()
+	for !s.Finished() {
+		if !s.Next(p) {
+			break
+		}
+		host := p.GetTagValue(tagKeys[0]).(string)
+		ts := *p.Timestamp()
+		if host == lastHost && ts.Before(last) {
+			t.Errorf("lines of %s not written in order: %v after %v", host, ts, last)
+		}
+		lastHost, last = host, ts
+		hosts[host]++
+		severities[p.GetFieldValue(logFields[fieldSeverity]).(string)]++
+		p.Reset()
+	}
+	return hosts, severities
+}
+
+func TestSimulator(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:     testStart,
+		End:       testStart.Add(time.Hour),
+		HostCount: 10,
+	}
+	s := c.NewSimulator(10*time.Second, 0).(*Simulator)
+	hosts, severities := runSimulator(t, s)
+	if len(hosts) != 10 {
+		t.Errorf("incorrect number of hosts: got %d want 10", len(hosts))
+	}
+	for host, n := range hosts {
+		if want := 3600 * logsPerSecond; math.Abs(float64(n)-want) > 0.1*want {
+			t.Errorf("incorrect number of lines of %s: got %d want about %f", host, n, want)
+		}
+	}
+	if severities["info"] <= severities["error"] {
+		t.Errorf("not fewer errors than info lines: %v", severities)
+	}
+}
+
+func TestSimulatorIncidents(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:     testStart,
+		End:       testStart.Add(24 * time.Hour),
+		HostCount: uint64(len(services)),
+	}
+	s := c.NewSimulator(time.Minute, 0).(*Simulator)
+	incidents := 0
+	p := data.NewPoint()
+	for !s.Finished() {
+		before := s.incidents[0].until
+		s.Next(p)
+		if !s.incidents[0].until.Equal(before) {
+			incidents++
+		}
+		p.Reset()
+	}
+	if want := incidentsPerHour * 24; math.Abs(float64(incidents)-want) > want {
+		t.Errorf("incorrect number of incidents: got %d want about %f", incidents, want)
+	}
+}
+
+func TestSimulatorHostsStarted(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:         testStart,
+		End:           testStart.Add(time.Hour),
+		InitHostCount: 1,
+		HostCount:     10,
+	}
+	s := c.NewSimulator(time.Minute, 0).(*Simulator)
+	hosts, _ := runSimulator(t, s)
+	if len(s.hosts) != 10 || len(hosts) != 10 {
+		t.Errorf("incorrect number of hosts at the end: got %d want 10", len(s.hosts))
+	}
+	if hosts[HostName(9)] >= hosts[HostName(0)] {
+		t.Errorf("last host started from the start: %d lines", hosts[HostName(9)])
+	}
+}
@@ -0,0 +1,132 @@
+package finance
+
+import (
+	"container/heap"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	symbolTagKey   = "symbol"
+	exchangeTagKey = "exchange"
+)
+
+var (
+	tradeMeasurement = []byte("trade")
+	quoteMeasurement = []byte("quote")
+
+	tradeFields = [][]byte{[]byte("price"), []byte("size"), []byte("condition")}
+	quoteFields = [][]byte{[]byte("bid"), []byte("ask"), []byte("bid_size"), []byte("ask_size")}
+)
+
+// MarketSimulatorConfig is used to create a MarketSimulator.
+type MarketSimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitSymbolCount is the number of symbols trading from the start, the
+	// others start trading one by one over the run
+	InitSymbolCount uint64
+	// SymbolCount is the total number of symbols
+	SymbolCount uint64
+}
+
+// NewSimulator produces a MarketSimulator whose median symbol trades every
+// interval on average in the middle of the trading day.
+func (c *MarketSimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	s := &MarketSimulator{
+		maxPoints: limit,
+		symbols:   make(symbolHeap, 0, c.SymbolCount),
+	}
+	duration := c.End.Sub(c.Start)
+	added := c.SymbolCount - c.InitSymbolCount
+	for i := uint64(0); i < c.SymbolCount; i++ {
+		start := c.Start
+		if i >= c.InitSymbolCount {
+			start = start.Add(time.Duration(float64(duration) * float64(i-c.InitSymbolCount+1) / float64(added+1)))
+		}
+		sym := newSymbol(int(i), start, c.End, interval)
+		if sym.Next().Before(c.End) {
+			s.symbols = append(s.symbols, sym)
+		}
+	}
+	heap.Init(&s.symbols)
+	return s
+}
+
+// MarketSimulator generates the trades and quote updates of a set of symbols,
+// in time order.
+type MarketSimulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+	symbols    symbolHeap
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *MarketSimulator) Finished() bool {
+	return len(s.symbols) == 0 || (s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next event of all symbols, a trade or a quote update, to p.
+func (s *MarketSimulator) Next(p *data.Point) bool {
+	sym := s.symbols[0]
+	sym.emit(p)
+	if sym.Next().Before(sym.end) {
+		heap.Fix(&s.symbols, 0)
+	} else {
+		heap.Pop(&s.symbols)
+	}
+	s.madePoints++
+	return true
+}
+
+// Fields returns the fields of the trade and quote measurements.
+func (s *MarketSimulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(tradeMeasurement): bytesToStrings(tradeFields),
+		string(quoteMeasurement): bytesToStrings(quoteFields),
+	}
+}
+
+// TagKeys returns the tag keys of the trades and quotes.
+func (s *MarketSimulator) TagKeys() []string {
+	return []string{symbolTagKey, exchangeTagKey}
+}
+
+// TagTypes returns the types of the tags of the trades and quotes.
+func (s *MarketSimulator) TagTypes() []string {
+	return []string{"string", "string"}
+}
+
+func (s *MarketSimulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+func bytesToStrings(in [][]byte) []string {
+	out := make([]string, len(in))
+	for i, b := range in {
+		out[i] = string(b)
+	}
+	return out
+}
+
+// symbolHeap orders the symbols by the time of their next event.
+type symbolHeap []*Symbol
+
+func (h symbolHeap) Len() int            { return len(h) }
+func (h symbolHeap) Less(i, j int) bool  { return h[i].next.Before(h[j].next) }
+func (h symbolHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *symbolHeap) Push(x interface{}) { *h = append(*h, x.(*Symbol)) }
+func (h *symbolHeap) Pop() interface{} {
+	old := *h
+	sym := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return sym
+}
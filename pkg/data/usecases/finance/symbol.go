@@ -1,60 +1,226 @@
 package finance
 
 import (
+	"math"
 	"math/rand"
 	"time"
 
-	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data"
 )
 
-func RandString(size int) string {
-	const letters = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
-	s := make([]byte, size)
-	for i := range s {
-		s[i] = letters[rand.Intn(len(letters))]
+const (
+	// symbolLetters is the number of letters of the symbol names, more are
+	// used if there are more symbols than names of this length
+	symbolLetters = 4
+	// symbolStride scatters the symbol names over all names of a length; it
+	// is coprime with 26 so no two symbols get the same name
+	symbolStride = 7919
+
+	// activityAlpha is the shape of the Pareto distribution of the activity
+	// of the symbols: a few symbols trade far more often than most
+	activityAlpha = 1.2
+	maxActivity   = 1000.0
+
+	// quotesPerTrade is the mean number of quote updates per trade
+	quotesPerTrade = 3
+
+	// secondsPerYear is the number of trading seconds in a year, to turn the
+	// annual volatility of a symbol into a volatility per second
+	secondsPerYear = 252 * 6.5 * 3600
+)
+
+// Exchanges are the market identifier codes of the exchanges trades and
+// quotes are reported on, the values of the exchange tag.
+var Exchanges = []string{"XNYS", "XNAS", "ARCX", "BATS", "EDGX", "IEXG", "XCHI", "FINR"}
+
+// exchangeWeights is the share of the trades reported on each exchange.
+var exchangeWeights = []float64{0.22, 0.20, 0.12, 0.10, 0.08, 0.03, 0.02, 0.23}
+
+// Trade conditions; the condition field of a trade is one of these codes.
+const (
+	ConditionRegular            = 0
+	ConditionOddLot             = 1
+	ConditionIntermarketSweep   = 2
+	ConditionExtendedHours      = 3
+	intermarketSweepProbability = 0.05
+	oddLotProbability           = 0.2
+	midpointTradeProbability    = 0.1
+)
+
+// SymbolName returns the deterministic name of symbol i, e.g. "MTWA". The
+// names of the symbols of a run are unique.
+func SymbolName(i int) string {
+	letters := symbolLetters
+	names := 1
+	for j := 0; j < letters; j++ {
+		names *= 26
+	}
+	for i >= names {
+		i -= names
+		letters++
+		names *= 26
+	}
+	n := ((i + 1) * symbolStride) % names
+	s := make([]byte, letters)
+	for j := letters - 1; j >= 0; j-- {
+		s[j] = byte('A' + n%26)
+		n /= 26
 	}
 	return string(s)
 }
 
+// Symbol is a traded instrument. Its trades and quote updates arrive as a
+// Poisson process whose rate follows the trading hours, and its price follows
+// a geometric random walk.
 type Symbol struct {
-	simulatedMeasurements []common.SimulatedMeasurement
-	tags                  []common.Tag
+	name string
+	// rate is the number of events (trades and quote updates) per second at
+	// a trading intensity of 1
+	rate       float64
+	volatility float64 // of the log price, per sqrt(second)
+	spreadBps  float64
+	mid        float64
+
+	// next is the time of the next event, which is a trade if nextTrade
+	next      time.Time
+	nextTrade bool
+	last      time.Time // time of the previous event
+	end       time.Time
 }
 
-func (t *Symbol) TickAll(d time.Duration) {
-	for i := range t.simulatedMeasurements {
-		t.simulatedMeasurements[i].Tick(d)
+// newSymbol returns symbol i, whose first event comes after start. interval
+// is the mean time between trades of a symbol of median activity at a
+// trading intensity of 1.
+func newSymbol(i int, start, end time.Time, interval time.Duration) *Symbol {
+	// Pareto distributed, scaled so the median symbol has an activity of 1
+	activity := math.Min(math.Pow(1-rand.Float64(), -1/activityAlpha), maxActivity) / math.Pow(2, 1/activityAlpha)
+	s := &Symbol{
+		name:       SymbolName(i),
+		rate:       activity * (1 + quotesPerTrade) / interval.Seconds(),
+		volatility: (0.2 + 0.4*rand.Float64()) / math.Sqrt(secondsPerYear),
+		// the more a symbol trades, the tighter its spread
+		spreadBps: math.Max(1, math.Min(50, 10/math.Sqrt(activity))),
+		// log-uniform between 5 and 500
+		mid:  math.Exp(math.Log(5) + rand.Float64()*math.Log(100)),
+		last: start,
+		next: start,
+		end:  end,
 	}
+	s.schedule()
+	return s
 }
 
-func (t Symbol) Measurements() []common.SimulatedMeasurement {
-	return t.simulatedMeasurements
+// Name returns the name of the symbol.
+func (s *Symbol) Name() string {
+	return s.name
 }
 
-func (t Symbol) Tags() []common.Tag {
-	return t.tags
+// Next returns the time of the next event of the symbol, which is after the
+// end of the simulation if the symbol has none left.
+func (s *Symbol) Next() time.Time {
+	return s.next
 }
 
-func newSymbolPrice(start time.Time, interval time.Duration) []common.SimulatedMeasurement {
-	return []common.SimulatedMeasurement{
-		NewPrice(start, interval),
+// schedule draws the time of the next event by thinning: candidate events
+// arrive at the highest rate of the day and are kept in proportion to the
+// trading intensity at their time.
+func (s *Symbol) schedule() {
+	peak := s.rate * maxTradingIntensity
+	t := s.next
+	for t.Before(s.end) {
+		t = t.Add(time.Duration(rand.ExpFloat64() / peak * float64(time.Second)))
+		if rand.Float64()*maxTradingIntensity < TradingIntensity(t) {
+			break
+		}
 	}
+	s.next = t
+	s.nextTrade = rand.Float64() < 1.0/(1+quotesPerTrade)
 }
 
-func NewSymbol(i int, start time.Time, interval time.Duration) common.Generator {
-	symbol := newSymbolWithPriceGenerator(i, start, interval, newSymbolPrice)
-	return &symbol
+// emit writes the next event of the symbol to p and schedules the one after.
+func (s *Symbol) emit(p *data.Point) {
+	s.advancePrice(s.next.Sub(s.last))
+	ts := s.next
+	p.SetTimestamp(&ts)
+	p.AppendTag([]byte(symbolTagKey), s.name)
+	p.AppendTag([]byte(exchangeTagKey), Exchanges[pickExchange()])
+	if s.nextTrade {
+		s.tradeToPoint(p)
+	} else {
+		s.quoteToPoint(p)
+	}
+	s.last = s.next
+	s.schedule()
 }
 
-func newSymbolWithPriceGenerator(i int, start time.Time, interval time.Duration, generator func(time.Time, time.Duration) []common.SimulatedMeasurement) Symbol {
-	sm := generator(start, interval)
+// advancePrice moves the mid price by a step of the random walk over d.
+func (s *Symbol) advancePrice(d time.Duration) {
+	s.mid *= math.Exp(s.volatility * math.Sqrt(d.Seconds()) * rand.NormFloat64())
+	s.mid = math.Max(s.mid, 0.05)
+}
+
+// bidAsk returns the best bid and ask around the mid price, in cents.
+func (s *Symbol) bidAsk() (float64, float64) {
+	half := math.Max(0.005, s.mid*s.spreadBps/2e4)
+	bid := math.Floor((s.mid-half)*100) / 100
+	ask := math.Ceil((s.mid+half)*100) / 100
+	if ask <= bid {
+		ask = bid + 0.01
+	}
+	return bid, ask
+}
+
+func (s *Symbol) tradeToPoint(p *data.Point) {
+	bid, ask := s.bidAsk()
+	var price float64
+	switch r := rand.Float64(); {
+	case r < midpointTradeProbability:
+		price = math.Round((bid+ask)*50) / 100
+	case r < (1+midpointTradeProbability)/2:
+		price = bid
+	default:
+		price = ask
+	}
 
-	measurement := Symbol{
-		tags: []common.Tag{
-			{Key: []byte("symbol"), Value: RandString(6)},
-		},
-		simulatedMeasurements: sm,
+	var size int64
+	condition := int64(ConditionRegular)
+	if rand.Float64() < oddLotProbability {
+		size = 1 + rand.Int63n(99)
+		condition = ConditionOddLot
+	} else {
+		// round lots, heavy-tailed
+		lots := math.Min(math.Pow(1-rand.Float64(), -1/1.5), 1000)
+		size = 100 * int64(lots)
+		if rand.Float64() < intermarketSweepProbability {
+			condition = ConditionIntermarketSweep
+		}
 	}
+	if !inTradingHours(s.next) {
+		condition = ConditionExtendedHours
+	}
+
+	p.SetMeasurementName(tradeMeasurement)
+	p.AppendField(tradeFields[0], price)
+	p.AppendField(tradeFields[1], size)
+	p.AppendField(tradeFields[2], condition)
+}
 
-	return measurement
+func (s *Symbol) quoteToPoint(p *data.Point) {
+	bid, ask := s.bidAsk()
+	p.SetMeasurementName(quoteMeasurement)
+	p.AppendField(quoteFields[0], bid)
+	p.AppendField(quoteFields[1], ask)
+	p.AppendField(quoteFields[2], 100*(1+rand.Int63n(20)))
+	p.AppendField(quoteFields[3], 100*(1+rand.Int63n(20)))
+}
+
+func pickExchange() int {
+	r := rand.Float64()
+	for i, w := range exchangeWeights {
+		if r < w {
+			return i
+		}
+		r -= w
+	}
+	return len(exchangeWeights) - 1
 }
@@ -1,72 +1,278 @@
 package finance
 
 import (
+	"math"
+	"math/rand"
 	"testing"
 	"time"
 
 	"github.com/timescale/tsbs/pkg/data"
-	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
-func testGenerator(start time.Time, interval time.Duration) []common.SimulatedMeasurement {
-	return []common.SimulatedMeasurement{
-		&testMeasurement{ticks: 0},
+func TestSymbolName(t *testing.T) {
+	if SymbolName(3) != SymbolName(3) {
+		t.Errorf("symbol names are not deterministic")
+	}
+	names := 26 * 26 * 26 * 26
+	seen := map[string]bool{}
+	for _, i := range []int{0, 1, 2, 1000, names - 1, names, names + 1} {
+		name := SymbolName(i)
+		if seen[name] {
+			t.Errorf("duplicate name of symbol %d: %s", i, name)
+		}
+		seen[name] = true
+		wantLen := symbolLetters
+		if i >= names {
+			wantLen++
+		}
+		if len(name) != wantLen {
+			t.Errorf("incorrect length of the name of symbol %d: got %s", i, name)
+		}
+	}
+	for i := 0; i < 10000; i++ {
+		seen[SymbolName(i)] = true
+	}
+	// the first symbols and names-1, names and names+1
+	if len(seen) != 10000+3 {
+		t.Errorf("symbol names are not unique: %d names for %d symbols", len(seen), 10000+3)
 	}
 }
 
-type testMeasurement struct {
-	ticks int
+// isCents tells whether v is a whole number of cents.
+func isCents(v float64) bool {
+	return math.Abs(v*100-math.Round(v*100)) < 1e-6
 }
 
-func (m *testMeasurement) Tick(_ time.Duration)  { m.ticks++ }
-func (m *testMeasurement) ToPoint(_ *data.Point) {}
+func TestNewSymbol(t *testing.T) {
+	rand.Seed(123)
+	start := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)
+	var symbols []*Symbol
+	for i := 0; i < 1000; i++ {
+		s := newSymbol(i, start, start.Add(24*time.Hour), time.Minute)
+		if s.mid < 5 || s.mid > 500 {
+			t.Errorf("symbol %d: mid price out of range: %f", i, s.mid)
+		}
+		if s.spreadBps < 1 || s.spreadBps > 50 {
+			t.Errorf("symbol %d: spread out of range: %f", i, s.spreadBps)
+		}
+		if annual := s.volatility * math.Sqrt(secondsPerYear); annual < 0.2 || annual > 0.6 {
+			t.Errorf("symbol %d: annual volatility out of range: %f", i, annual)
+		}
+		if s.Next().Before(start) {
+			t.Errorf("symbol %d: first event before the start: %v", i, s.Next())
+		}
+		symbols = append(symbols, s)
+	}
+	// the more a symbol trades, the tighter its spread
+	for i := 1; i < len(symbols); i++ {
+		a, b := symbols[i-1], symbols[i]
+		if (a.rate > b.rate && a.spreadBps > b.spreadBps) || (a.rate < b.rate && a.spreadBps < b.spreadBps) {
+			t.Errorf("spread not tighter for the more active symbol: rates %f %f, spreads %f %f", a.rate, b.rate, a.spreadBps, b.spreadBps)
+		}
+	}
+}
 
-func TestNewSymbolPrice(t *testing.T) {
-	start := time.Now()
+func TestSymbolBidAsk(t *testing.T) {
+	cases := []struct {
+		mid, spreadBps float64
+	}{
+		{mid: 100, spreadBps: 10},
+		{mid: 100.004, spreadBps: 1},
+		{mid: 5.123, spreadBps: 50},
+		{mid: 0.05, spreadBps: 1},
+	}
+	for _, c := range cases {
+		s := &Symbol{mid: c.mid, spreadBps: c.spreadBps}
+		bid, ask := s.bidAsk()
+		if !isCents(bid) || !isCents(ask) {
+			t.Errorf("mid %f: bid or ask not in cents: %f %f", c.mid, bid, ask)
+		}
+		if !(bid < c.mid && c.mid < ask) {
+			t.Errorf("mid %f: not between the bid and the ask: %f %f", c.mid, bid, ask)
+		}
+		// at most a cent wider on each side than the spread
+		if half := math.Max(0.005, c.mid*c.spreadBps/2e4); ask-bid > 2*half+0.02+1e-9 {
+			t.Errorf("mid %f: spread too wide: %f %f", c.mid, bid, ask)
+		}
+	}
+}
 
-	measurements := newSymbolPrice(start, 1*time.Second)
+func TestSymbolAdvancePrice(t *testing.T) {
+	rand.Seed(123)
+	s := &Symbol{mid: 100, volatility: 0.001}
+	s.advancePrice(0)
+	if s.mid != 100 {
+		t.Errorf("price moved without time passing: %f", s.mid)
+	}
 
-	if got := len(measurements); got != 1 {
-		t.Errorf("incorrect number of measurements: got %d want %d", got, 2)
+	// the log returns over a second have a standard deviation of the
+	// volatility
+	const steps = 10000
+	sum, sumSq := 0.0, 0.0
+	for i := 0; i < steps; i++ {
+		prev := s.mid
+		s.advancePrice(time.Second)
+		r := math.Log(s.mid / prev)
+		sum += r
+		sumSq += r * r
+	}
+	mean := sum / steps
+	if std := math.Sqrt(sumSq/steps - mean*mean); math.Abs(std-s.volatility) > 0.05*s.volatility {
+		t.Errorf("incorrect volatility of the log returns: got %f want %f", std, s.volatility)
 	}
 
-	readings := measurements[0].(*Price)
-	if got := readings.Timestamp; got != start {
-		t.Errorf("incorrect readings measurement timestamp: got %v want %v", got, start)
+	s = &Symbol{mid: 0.06, volatility: 1}
+	for i := 0; i < 1000; i++ {
+		s.advancePrice(time.Second)
+		if s.mid < 0.05 {
+			t.Fatalf("price below its floor: %f", s.mid)
+		}
 	}
 }
 
-func TestNewSymbol(t *testing.T) {
-	start := time.Now()
-	generator := NewSymbol(1, start, 1*time.Second)
-
-	symbol := generator.(*Symbol)
+func TestSymbolTradeToPoint(t *testing.T) {
+	rand.Seed(123)
+	// Monday, in the regular session
+	regular := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC).Add(sessionOpen + time.Hour)
+	night := regular.Add(-sessionOpen)
+	for _, next := range []time.Time{regular, night} {
+		s := &Symbol{mid: 50, spreadBps: 20, next: next}
+		bid, ask := s.bidAsk()
+		conditions := map[int64]int{}
+		for i := 0; i < 1000; i++ {
+			p := data.NewPoint()
+			s.tradeToPoint(p)
+			price := p.GetFieldValue(tradeFields[0]).(float64)
+			size := p.GetFieldValue(tradeFields[1]).(int64)
+			condition := p.GetFieldValue(tradeFields[2]).(int64)
+			conditions[condition]++
+			if price != bid && price != ask && price != math.Round((bid+ask)*50)/100 {
+				t.Fatalf("price %f neither the bid %f, the ask %f nor the midpoint", price, bid, ask)
+			}
+			switch {
+			case next == night && condition != ConditionExtendedHours:
+				t.Fatalf("condition of a trade at night: got %d want %d", condition, ConditionExtendedHours)
+			case condition == ConditionOddLot && (size < 1 || size > 99):
+				t.Fatalf("odd lot of %d", size)
+			case condition != ConditionOddLot && next == regular && (size < 100 || size%100 != 0):
+				t.Fatalf("round lot of %d", size)
+			}
+		}
+		if next == regular && (conditions[ConditionRegular] == 0 || conditions[ConditionOddLot] == 0 || conditions[ConditionIntermarketSweep] == 0) {
+			t.Errorf("incorrect mix of conditions: %v", conditions)
+		}
+	}
+}
 
-	if got := len(symbol.Measurements()); got != 1 {
-		t.Errorf("incorrect truck measurement count: got %v want %v", got, 2)
+func TestPickExchange(t *testing.T) {
+	rand.Seed(123)
+	if len(exchangeWeights) != len(Exchanges) {
+		t.Fatalf("%d exchange weights for %d exchanges", len(exchangeWeights), len(Exchanges))
+	}
+	const draws = 100000
+	counts := make([]int, len(Exchanges))
+	for i := 0; i < draws; i++ {
+		counts[pickExchange()]++
 	}
+	for i, w := range exchangeWeights {
+		if share := float64(counts[i]) / draws; math.Abs(share-w) > 0.01 {
+			t.Errorf("incorrect share of %s: got %f want %f", Exchanges[i], share, w)
+		}
+	}
+}
 
-	if got := len(symbol.Tags()); got != 1 {
-		t.Errorf("incorrect truck tag count: got %v want %v", got, 8)
+func TestTradingIntensity(t *testing.T) {
+	// Monday
+	day := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)
+	open := TradingIntensity(day.Add(sessionOpen))
+	midday := TradingIntensity(day.Add(sessionOpen + (sessionClose-sessionOpen)/2))
+	close := TradingIntensity(day.Add(sessionClose - time.Minute))
+	preMarket := TradingIntensity(day.Add(sessionOpen - time.Hour))
+	night := TradingIntensity(day.Add(3 * time.Hour))
+	weekend := TradingIntensity(day.Add(-12 * time.Hour))
+
+	if !(open > midday && close > midday) {
+		t.Errorf("no peaks at the open and the close: open %f, midday %f, close %f", open, midday, close)
+	}
+	if !(midday > preMarket && preMarket > night) {
+		t.Errorf("session not busier than the extended hours and the night: midday %f, pre-market %f, night %f", midday, preMarket, night)
+	}
+	if weekend != closedIntensity {
+		t.Errorf("incorrect weekend intensity: got %f want %f", weekend, closedIntensity)
+	}
+	for d := time.Duration(0); d < 24*time.Hour; d += time.Minute {
+		if i := TradingIntensity(day.Add(d)); i > maxTradingIntensity {
+			t.Fatalf("intensity at %s above its bound: %f", d, i)
+		}
 	}
 }
 
-func TestSymbolTickAll(t *testing.T) {
-	now := time.Now()
-	symbol := newSymbolWithPriceGenerator(0, now, 1*time.Second, testGenerator)
-	if got := symbol.simulatedMeasurements[0].(*testMeasurement).ticks; got != 0 {
-		t.Errorf("ticks not equal to 0 to start: got %d", got)
+func TestMarketSimulator(t *testing.T) {
+	rand.Seed(123)
+	start := time.Date(2016, 1, 4, 0, 0, 0, 0, time.UTC)
+	end := start.Add(24 * time.Hour)
+	c := &MarketSimulatorConfig{Start: start, End: end, InitSymbolCount: 5, SymbolCount: 10}
+	sim := c.NewSimulator(time.Minute, 0)
+
+	var last time.Time
+	counts := map[string]int{}
+	symbols := map[string]time.Time{}
+	for !sim.Finished() {
+		p := data.NewPoint()
+		if !sim.Next(p) {
+			t.Fatalf("point not written")
+		}
+		ts := *p.Timestamp()
+		if ts.Before(last) || !ts.Before(end) {
+			t.Fatalf("point at %v out of order or after the end, previous at %v", ts, last)
+		}
+		last = ts
+		m := string(p.MeasurementName())
+		counts[m]++
+		fields := sim.Fields()[m]
+		for _, f := range fields {
+			if p.GetFieldValue([]byte(f)) == nil {
+				t.Fatalf("%s without field %s", m, f)
+			}
+		}
+		if m == string(quoteMeasurement) && p.GetFieldValue([]byte("bid")).(float64) >= p.GetFieldValue([]byte("ask")).(float64) {
+			t.Errorf("crossed quote: %v", p.FieldValues())
+		}
+		symbol := p.TagValues()[0].(string)
+		exchange := p.GetTagValue([]byte(exchangeTagKey))
+		if !containsString(Exchanges, exchange) {
+			t.Fatalf("incorrect exchange of %s: %v", symbol, exchange)
+		}
+		if _, ok := symbols[symbol]; !ok {
+			symbols[symbol] = ts
+		}
+	}
+	if counts["trade"] == 0 || counts["quote"] < counts["trade"] {
+		t.Errorf("incorrect mix of trades and quotes: %v", counts)
 	}
-	symbol.TickAll(time.Second)
-	if got := symbol.simulatedMeasurements[0].(*testMeasurement).ticks; got != 1 {
-		t.Errorf("ticks incorrect: got %d want %d", got, 1)
+	if len(symbols) != 10 {
+		t.Errorf("incorrect number of symbols: got %d want 10", len(symbols))
 	}
-	symbol.simulatedMeasurements = append(symbol.simulatedMeasurements, &testMeasurement{})
-	symbol.TickAll(time.Second)
-	if got := symbol.simulatedMeasurements[0].(*testMeasurement).ticks; got != 2 {
-		t.Errorf("ticks incorrect after 2nd tick: got %d want %d", got, 2)
+	if first := symbols[SymbolName(9)]; first.Before(start.Add(20 * time.Hour)) {
+		t.Errorf("last symbol traded before it was added: %v", first)
+	}
+
+	sim = c.NewSimulator(time.Minute, 100)
+	n := 0
+	for !sim.Finished() {
+		sim.Next(data.NewPoint())
+		n++
 	}
-	if got := symbol.simulatedMeasurements[1].(*testMeasurement).ticks; got != 1 {
-		t.Errorf("ticks incorrect after 2nd tick: got %d want %d", got, 1)
+	if n != 100 {
+		t.Errorf("limit not respected: got %d points want 100", n)
+	}
+}
+
+func containsString(list []string, v interface{}) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
 	}
+	return false
 }
@@ -0,0 +1,59 @@
+package finance
+
+import (
+	"math"
+	"time"
+)
+
+// The hours of the simulated market, in UTC: the regular session is 9:30 to
+// 16:00 in New York (ignoring daylight saving time), with pre-market trading
+// from 4:00 and post-market trading until 19:00.
+const (
+	preMarketOpen   = 9 * time.Hour
+	sessionOpen     = 14*time.Hour + 30*time.Minute
+	sessionClose    = 21 * time.Hour
+	postMarketClose = 24 * time.Hour
+
+	extendedHoursIntensity = 0.05
+	closedIntensity        = 0.005
+	// openCloseBoost and openCloseDecay shape the activity peaks at the open
+	// and the close of the session, the decay as a fraction of the session
+	openCloseBoost = 1.5
+	openCloseDecay = 0.08
+
+	// maxTradingIntensity is an upper bound of TradingIntensity
+	maxTradingIntensity = 1 + 2*openCloseBoost
+)
+
+// TradingIntensity returns the activity of the market at t relative to the
+// middle of the regular session: a U shape over the session, with peaks at
+// the open and the close, little activity in the pre- and post-market hours
+// and almost none when the market is closed and on weekends.
+func TradingIntensity(t time.Time) float64 {
+	sinceMidnight, weekday := marketTime(t)
+	switch {
+	case !weekday:
+		return closedIntensity
+	case sinceMidnight >= sessionOpen && sinceMidnight < sessionClose:
+		x := float64(sinceMidnight-sessionOpen) / float64(sessionClose-sessionOpen)
+		return 1 + openCloseBoost*(math.Exp(-x/openCloseDecay)+math.Exp(-(1-x)/openCloseDecay))
+	case sinceMidnight >= preMarketOpen && sinceMidnight < postMarketClose:
+		return extendedHoursIntensity
+	default:
+		return closedIntensity
+	}
+}
+
+// inTradingHours returns whether t is in the regular session.
+func inTradingHours(t time.Time) bool {
+	sinceMidnight, weekday := marketTime(t)
+	return weekday && sinceMidnight >= sessionOpen && sinceMidnight < sessionClose
+}
+
+// marketTime returns the time of day of t in UTC and whether it is a weekday.
+func marketTime(t time.Time) (time.Duration, bool) {
+	t = t.UTC()
+	wd := t.Weekday()
+	midnight := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return t.Sub(midnight), wd != time.Saturday && wd != time.Sunday
+}
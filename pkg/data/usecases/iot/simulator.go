@@ -19,17 +19,23 @@ type SimulatorConfig common.BaseSimulatorConfig
 // config over the specified interval and points limit.
 func (sc *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
 	s := (*common.BaseSimulatorConfig)(sc).NewSimulator(interval, limit)
+	return NewBatchSimulator(s)
+}
 
+// NewBatchSimulator produces a Simulator which emits the entries of base in
+// batches, introducing missing or out of order entries and batches the same
+// way as the IoT use case does.
+func NewBatchSimulator(base common.Simulator) *Simulator {
 	maxFieldCount := 0
 
-	for _, fields := range s.Fields() {
+	for _, fields := range base.Fields() {
 		if len(fields) > maxFieldCount {
 			maxFieldCount = len(fields)
 		}
 	}
 
 	return &Simulator{
-		base:            s,
+		base:            base,
 		batchSize:       defaultBatchSize,
 		configGenerator: newBatchConfig,
 		maxFieldCount:   maxFieldCount,
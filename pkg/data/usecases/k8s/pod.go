@@ -0,0 +1,296 @@
+package k8s
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+const (
+	// restartsPerHour is the rate of the restarts of a container besides the
+	// ones of running out of memory
+	restartsPerHour = 0.005
+	// leakingProbability is the probability of a container to leak memory
+	// until it is OOM killed
+	leakingProbability = 0.05
+
+	// bytesPerCoreSecond is the network traffic received per second by a
+	// container using one core
+	bytesPerCoreSecond = 2e6
+	// bytesPerPacket is the average size of a network packet
+	bytesPerPacket = 900
+	// packetErrorProbability is the probability of a network error per tick
+	packetErrorProbability = 0.001
+	// fsWriteBytesPerCoreSecond is the amount written to the container file
+	// system per second by a container using one core
+	fsWriteBytesPerCoreSecond = 2e5
+	// fsBlockSize is the size of a file system read or write operation
+	fsBlockSize = 4096
+)
+
+var (
+	tagKeys = [][]byte{
+		[]byte("cluster"),
+		[]byte("node"),
+		[]byte("namespace"),
+		[]byte("deployment"),
+		[]byte("pod"),
+		[]byte("container"),
+	}
+
+	cpuMeasurement     = []byte("container_cpu")
+	memoryMeasurement  = []byte("container_memory")
+	networkMeasurement = []byte("container_network")
+	fsMeasurement      = []byte("container_fs")
+	stateMeasurement   = []byte("kube_pod_container")
+
+	cpuFields = [][]byte{
+		[]byte("usage_seconds_total"),
+		[]byte("user_seconds_total"),
+		[]byte("system_seconds_total"),
+		[]byte("cfs_throttled_seconds_total"),
+	}
+	memoryFields = [][]byte{
+		[]byte("usage_bytes"),
+		[]byte("working_set_bytes"),
+		[]byte("rss_bytes"),
+		[]byte("cache_bytes"),
+	}
+	networkFields = [][]byte{
+		[]byte("receive_bytes_total"),
+		[]byte("transmit_bytes_total"),
+		[]byte("receive_packets_total"),
+		[]byte("transmit_packets_total"),
+		[]byte("receive_errors_total"),
+		[]byte("transmit_errors_total"),
+	}
+	fsFields = [][]byte{
+		[]byte("reads_bytes_total"),
+		[]byte("writes_bytes_total"),
+		[]byte("reads_total"),
+		[]byte("writes_total"),
+		[]byte("usage_bytes"),
+	}
+	stateFields = [][]byte{
+		[]byte("ready"),
+		[]byte("restarts_total"),
+		[]byte("cpu_request_cores"),
+		[]byte("cpu_limit_cores"),
+		[]byte("memory_request_bytes"),
+		[]byte("memory_limit_bytes"),
+	}
+
+	// measurements are the measurements written for every container, in order
+	measurements = []struct {
+		name    []byte
+		fields  [][]byte
+		toPoint func(*container, *data.Point)
+	}{
+		{cpuMeasurement, cpuFields, (*container).cpuToPoint},
+		{memoryMeasurement, memoryFields, (*container).memoryToPoint},
+		{networkMeasurement, networkFields, (*container).networkToPoint},
+		{fsMeasurement, fsFields, (*container).fsToPoint},
+		{stateMeasurement, stateFields, (*container).stateToPoint},
+	}
+)
+
+// pod is a running pod of a workload and its containers.
+type pod struct {
+	workload   int
+	node       int
+	tags       []string // tag values but the container name
+	containers []*container
+}
+
+// newPod returns a new pod of workload w scheduled on node. Deployment pods
+// are named after their replica set, e.g. "web-5d8f9c6b7d-x2kqz", and daemon
+// set pods after their daemon set, e.g. "kube-proxy-7tq4m".
+func newPod(w int, node int, replicaSet string) *pod {
+	wl := &Workloads[w]
+	name := fmt.Sprintf("%s-%s", wl.Name, randomSuffix(5))
+	if !wl.DaemonSet {
+		name = fmt.Sprintf("%s-%s-%s", wl.Name, replicaSet, randomSuffix(5))
+	}
+	p := &pod{
+		workload: w,
+		node:     node,
+		tags: []string{
+			ClusterName(node / NodesPerCluster),
+			NodeName(node),
+			wl.Namespace,
+			wl.Name,
+			name,
+		},
+	}
+	for i, c := range wl.Containers {
+		cpu, memory := wl.cpuRequest, wl.memoryRequest
+		if i > 0 {
+			cpu, memory = sidecarCPURequest, sidecarMemoryRequest
+		}
+		p.containers = append(p.containers, newContainer(c, cpu, memory))
+	}
+	return p
+}
+
+// container is a container of a pod, with cAdvisor style counters.
+type container struct {
+	name string
+
+	cpuRequest    float64
+	cpuLimit      float64
+	memoryRequest int64
+	memoryLimit   int64
+
+	// load is the usual fraction of the requests the container uses
+	load float64
+	// leak is the memory leaked per second, in bytes, leaked since the
+	// container started
+	leak   float64
+	leaked float64
+
+	cores        float64
+	cpuUser      float64
+	cpuSystem    float64
+	cpuThrottled float64
+
+	workingSet float64
+	cache      float64
+
+	rxBytes   float64
+	txBytes   float64
+	rxPackets float64
+	txPackets float64
+	rxErrors  int64
+	txErrors  int64
+
+	fsReadBytes  float64
+	fsWriteBytes float64
+	fsUsage      float64
+
+	ready    bool
+	restarts int64
+}
+
+func newContainer(name string, cpuRequest float64, memoryRequest int64) *container {
+	c := &container{
+		name:          name,
+		cpuRequest:    cpuRequest,
+		cpuLimit:      cpuRequest * limitFactor,
+		memoryRequest: memoryRequest,
+		memoryLimit:   memoryRequest * limitFactor,
+		load:          math.Min(1.5, math.Exp(-0.5+0.5*rand.NormFloat64())),
+		ready:         true,
+	}
+	if rand.Float64() < leakingProbability {
+		// runs out of memory after a few hours
+		c.leak = float64(c.memoryLimit) / (3600 * (1 + 5*rand.Float64()))
+	}
+	c.start()
+	return c
+}
+
+// start (re)starts the container with a fresh memory and file system.
+func (c *container) start() {
+	c.cores = c.cpuRequest * c.load / 2
+	c.workingSet = float64(c.memoryRequest) * c.load / 2
+	c.leaked = 0
+	c.cache = 0
+	c.fsUsage = 16 * mebibyte
+}
+
+func (c *container) restart() {
+	c.restarts++
+	c.ready = false
+	c.start()
+}
+
+// tick advances the container by d.
+func (c *container) tick(d time.Duration) {
+	s := d.Seconds()
+	c.ready = true
+
+	targetCores := c.cpuRequest * c.load
+	c.cores += (targetCores-c.cores)*0.2 + 0.1*c.cpuRequest*rand.NormFloat64()
+	c.cores = math.Max(0.001, math.Min(c.cpuLimit, c.cores))
+	c.cpuUser += 0.8 * c.cores * s
+	c.cpuSystem += 0.2 * c.cores * s
+	if saturation := c.cores/c.cpuLimit - 0.9; saturation > 0 {
+		c.cpuThrottled += math.Min(1, 10*saturation) * s
+	}
+
+	c.leaked += c.leak * s
+	targetMemory := float64(c.memoryRequest)*c.load + c.leaked
+	c.workingSet += (targetMemory-c.workingSet)*0.05 + 0.01*float64(c.memoryRequest)*rand.NormFloat64()
+	c.workingSet = math.Max(float64(mebibyte), c.workingSet)
+	c.cache = math.Max(0, math.Min(0.3*float64(c.memoryRequest), c.cache+0.01*float64(c.memoryRequest)*rand.NormFloat64()))
+
+	rx := c.cores * bytesPerCoreSecond * (0.5 + rand.Float64()) * s
+	tx := 0.6 * rx
+	c.rxBytes += rx
+	c.txBytes += tx
+	c.rxPackets += math.Ceil(rx / bytesPerPacket)
+	c.txPackets += math.Ceil(tx / bytesPerPacket)
+	if rand.Float64() < packetErrorProbability {
+		c.rxErrors++
+	}
+	if rand.Float64() < packetErrorProbability {
+		c.txErrors++
+	}
+
+	written := c.cores * fsWriteBytesPerCoreSecond * (0.5 + rand.Float64()) * s
+	c.fsWriteBytes += written
+	c.fsReadBytes += 0.5 * written
+	c.fsUsage += 0.1 * written
+
+	if c.workingSet >= float64(c.memoryLimit) || rand.Float64() < restartsPerHour*d.Hours() {
+		// OOM killed or crashed
+		c.restart()
+	}
+}
+
+func (c *container) cpuToPoint(p *data.Point) {
+	p.AppendField(cpuFields[0], c.cpuUser+c.cpuSystem)
+	p.AppendField(cpuFields[1], c.cpuUser)
+	p.AppendField(cpuFields[2], c.cpuSystem)
+	p.AppendField(cpuFields[3], c.cpuThrottled)
+}
+
+func (c *container) memoryToPoint(p *data.Point) {
+	p.AppendField(memoryFields[0], int64(c.workingSet+c.cache))
+	p.AppendField(memoryFields[1], int64(c.workingSet))
+	p.AppendField(memoryFields[2], int64(0.9*c.workingSet))
+	p.AppendField(memoryFields[3], int64(c.cache))
+}
+
+func (c *container) networkToPoint(p *data.Point) {
+	p.AppendField(networkFields[0], int64(c.rxBytes))
+	p.AppendField(networkFields[1], int64(c.txBytes))
+	p.AppendField(networkFields[2], int64(c.rxPackets))
+	p.AppendField(networkFields[3], int64(c.txPackets))
+	p.AppendField(networkFields[4], c.rxErrors)
+	p.AppendField(networkFields[5], c.txErrors)
+}
+
+func (c *container) fsToPoint(p *data.Point) {
+	p.AppendField(fsFields[0], int64(c.fsReadBytes))
+	p.AppendField(fsFields[1], int64(c.fsWriteBytes))
+	p.AppendField(fsFields[2], int64(c.fsReadBytes/fsBlockSize))
+	p.AppendField(fsFields[3], int64(c.fsWriteBytes/fsBlockSize))
+	p.AppendField(fsFields[4], int64(c.fsUsage))
+}
+
+func (c *container) stateToPoint(p *data.Point) {
+	ready := int64(0)
+	if c.ready {
+		ready = 1
+	}
+	p.AppendField(stateFields[0], ready)
+	p.AppendField(stateFields[1], c.restarts)
+	p.AppendField(stateFields[2], c.cpuRequest)
+	p.AppendField(stateFields[3], c.cpuLimit)
+	p.AppendField(stateFields[4], c.memoryRequest)
+	p.AppendField(stateFields[5], c.memoryLimit)
+}
@@ -0,0 +1,224 @@
+package k8s
+
+import (
+	"math/rand"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+// SimulatorConfig is used to create a Simulator.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitNodeCount is the number of nodes to start with, the others join the
+	// clusters one by one over the run
+	InitNodeCount uint64
+	// NodeCount is the total number of nodes
+	NodeCount uint64
+	// PodChurnRate is the fraction of the deployment pods deleted and
+	// replaced by new ones per hour
+	PodChurnRate float64
+}
+
+// NewSimulator produces a Simulator writing the metrics of every container
+// every interval.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	initNodes := c.InitNodeCount
+	if initNodes == 0 || initNodes > c.NodeCount {
+		initNodes = c.NodeCount
+	}
+	s := &Simulator{
+		maxPoints: limit,
+		epochs:    uint64(c.End.Sub(c.Start).Nanoseconds() / interval.Nanoseconds()),
+		start:     c.Start,
+		interval:  interval,
+		initNodes: initNodes,
+		nodes:     c.NodeCount,
+		churnRate: c.PodChurnRate,
+	}
+	for s.activeNodes < int(initNodes) {
+		s.addNode()
+	}
+	return s
+}
+
+// Simulator simulates the Kubernetes clusters of a set of nodes: deployment
+// pods are deleted and replaced by new ones on any node of their cluster at
+// the churn rate, so the container series come and go over the run.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+
+	epoch    uint64
+	epochs   uint64
+	start    time.Time
+	interval time.Duration
+
+	initNodes   uint64
+	nodes       uint64
+	activeNodes int
+	// replicaSets are the replica set name hashes of the deployments, per
+	// cluster and workload
+	replicaSets [][]string
+
+	pods           []*pod
+	deploymentPods int
+	churnRate      float64
+	churnDebt      float64 // pods due for replacement but not replaced yet
+
+	podIndex         int
+	containerIndex   int
+	measurementIndex int
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.epoch >= s.epochs || (s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next measurement of the current epoch to p.
+func (s *Simulator) Next(p *data.Point) bool {
+	pd := s.pods[s.podIndex]
+	c := pd.containers[s.containerIndex]
+	m := measurements[s.measurementIndex]
+
+	ts := s.start.Add(time.Duration(s.epoch) * s.interval)
+	p.SetTimestamp(&ts)
+	p.SetMeasurementName(m.name)
+	for i, v := range pd.tags {
+		p.AppendTag(tagKeys[i], v)
+	}
+	p.AppendTag(tagKeys[len(pd.tags)], c.name)
+	m.toPoint(c, p)
+	s.madePoints++
+
+	s.measurementIndex++
+	if s.measurementIndex == len(measurements) {
+		s.measurementIndex = 0
+		s.containerIndex++
+	}
+	if s.containerIndex == len(pd.containers) {
+		s.containerIndex = 0
+		s.podIndex++
+	}
+	if s.podIndex == len(s.pods) {
+		s.podIndex = 0
+		s.nextEpoch()
+	}
+	return true
+}
+
+// nextEpoch advances all containers to the next epoch, replaces the churned
+// pods and adds the nodes joining in this epoch.
+func (s *Simulator) nextEpoch() {
+	s.epoch++
+	for _, pd := range s.pods {
+		for _, c := range pd.containers {
+			c.tick(s.interval)
+		}
+	}
+
+	s.churnDebt += s.churnRate * float64(s.deploymentPods) * s.interval.Hours()
+	for ; s.churnDebt >= 1; s.churnDebt-- {
+		s.replaceRandomPod()
+	}
+
+	if s.epochs > 1 {
+		missing := float64(s.nodes - s.initNodes)
+		target := s.initNodes + uint64(missing*float64(s.epoch)/float64(s.epochs-1))
+		for uint64(s.activeNodes) < target && uint64(s.activeNodes) < s.nodes {
+			s.addNode()
+		}
+	}
+}
+
+// addNode adds the next node to its cluster, with a pod of every daemon set
+// and deploymentPodsPerNode deployment pods.
+func (s *Simulator) addNode() {
+	node := s.activeNodes
+	s.activeNodes++
+	cluster := node / NodesPerCluster
+	if cluster == len(s.replicaSets) {
+		hashes := make([]string, len(Workloads))
+		for i := range hashes {
+			hashes[i] = randomSuffix(10)
+		}
+		s.replicaSets = append(s.replicaSets, hashes)
+	}
+
+	for i, w := range Workloads {
+		if w.DaemonSet {
+			s.pods = append(s.pods, newPod(i, node, ""))
+		}
+	}
+	first := (node % NodesPerCluster) * deploymentPodsPerNode
+	for j := 0; j < deploymentPodsPerNode; j++ {
+		w := deploymentSlots[(first+j)%len(deploymentSlots)]
+		s.pods = append(s.pods, newPod(w, node, s.replicaSets[cluster][w]))
+		s.deploymentPods++
+	}
+}
+
+// replaceRandomPod deletes a random deployment pod and schedules its
+// replacement on a random node of the same cluster.
+func (s *Simulator) replaceRandomPod() {
+	if s.deploymentPods == 0 {
+		return
+	}
+	i := rand.Intn(len(s.pods))
+	for Workloads[s.pods[i].workload].DaemonSet {
+		i = rand.Intn(len(s.pods))
+	}
+	w := s.pods[i].workload
+	cluster := s.pods[i].node / NodesPerCluster
+	first := cluster * NodesPerCluster
+	last := first + NodesPerCluster
+	if last > s.activeNodes {
+		last = s.activeNodes
+	}
+	node := first + rand.Intn(last-first)
+	s.pods[i] = newPod(w, node, s.replicaSets[cluster][w])
+}
+
+// Fields returns the fields of every measurement.
+func (s *Simulator) Fields() map[string][]string {
+	fields := make(map[string][]string, len(measurements))
+	for _, m := range measurements {
+		names := make([]string, len(m.fields))
+		for i, f := range m.fields {
+			names[i] = string(f)
+		}
+		fields[string(m.name)] = names
+	}
+	return fields
+}
+
+// TagKeys returns the tag keys of the containers.
+func (s *Simulator) TagKeys() []string {
+	keys := make([]string, len(tagKeys))
+	for i, k := range tagKeys {
+		keys[i] = string(k)
+	}
+	return keys
+}
+
+// TagTypes returns the types of the tags of the containers.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
@@ -0,0 +1,182 @@
+package k8s
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var testStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestDeploymentSlots(t *testing.T) {
+	deployments := 0
+	replicas := 0
+	for _, w := range Workloads {
+		if !w.DaemonSet {
+			deployments++
+			replicas += w.replicas
+		}
+	}
+	if len(deploymentSlots) != replicas {
+		t.Errorf("incorrect number of slots: got %d want %d", len(deploymentSlots), replicas)
+	}
+	seen := map[int]bool{}
+	for _, w := range deploymentSlots[:deployments] {
+		if Workloads[w].DaemonSet {
+			t.Errorf("daemon set %s in the deployment slots", Workloads[w].Name)
+		}
+		seen[w] = true
+	}
+	if len(seen) != deployments {
+		t.Errorf("not every deployment in the first %d slots: got %d", deployments, len(seen))
+	}
+	if 2*deploymentPodsPerNode < deployments {
+		t.Errorf("a cluster of two nodes does not run every deployment")
+	}
+}
+
+func TestClusterCount(t *testing.T) {
+	cases := []struct {
+		nodes int
+		want  int
+	}{
+		{nodes: 1, want: 1},
+		{nodes: NodesPerCluster, want: 1},
+		{nodes: NodesPerCluster + 1, want: 2},
+		{nodes: 4000, want: 80},
+	}
+	for _, c := range cases {
+		if got := ClusterCount(c.nodes); got != c.want {
+			t.Errorf("incorrect cluster count for %d nodes: got %d want %d", c.nodes, got, c.want)
+		}
+	}
+}
+
+// runSimulator runs a simulator of c and returns the points per pod name and
+// the number of points per epoch.
+func runSimulator(t *testing.T, c *SimulatorConfig, interval time.Duration) (map[string]int, map[time.Time]int) {
+	rand.Seed(123)
+	sim := c.NewSimulator(interval, 0)
+	pods := map[string]int{}
+	epochs := map[time.Time]int{}
+	p := data.NewPoint()
+	for !sim.Finished() {
+		if !sim.Next(p) {
+			t.Fatalf("point not written")
+		}
+		pods[p.GetTagValue([]byte("pod")).(string)]++
+		epochs[*p.Timestamp()]++
+		p.Reset()
+	}
+	return pods, epochs
+}
+
+func TestSimulatorWithoutChurn(t *testing.T) {
+	c := &SimulatorConfig{
+		Start:         testStart,
+		End:           testStart.Add(time.Hour),
+		InitNodeCount: 2,
+		NodeCount:     2,
+	}
+	pods, epochs := runSimulator(t, c, 10*time.Second)
+
+	wantPods := 2 * (2 + deploymentPodsPerNode)
+	if len(pods) != wantPods {
+		t.Errorf("incorrect number of pods: got %d want %d", len(pods), wantPods)
+	}
+	if len(epochs) != 360 {
+		t.Errorf("incorrect number of epochs: got %d want 360", len(epochs))
+	}
+	perEpoch := epochs[testStart]
+	for ts, n := range epochs {
+		if n != perEpoch {
+			t.Errorf("incorrect number of points at %v: got %d want %d", ts, n, perEpoch)
+		}
+		if ts.Before(testStart) || !ts.Before(c.End) {
+			t.Errorf("point out of the time range: %v", ts)
+		}
+	}
+}
+
+func TestSimulatorChurn(t *testing.T) {
+	c := &SimulatorConfig{
+		Start:         testStart,
+		End:           testStart.Add(4 * time.Hour),
+		InitNodeCount: 2,
+		NodeCount:     2,
+		PodChurnRate:  0.5,
+	}
+	pods, epochs := runSimulator(t, c, time.Minute)
+
+	// half of the deployment pods are replaced every hour, the ones replaced
+	// after the last epoch are never seen
+	deploymentPods := 2 * deploymentPodsPerNode
+	want := 4 * deploymentPods / 2
+	if replaced := len(pods) - 2*(2+deploymentPodsPerNode); replaced < want-2 || replaced > want {
+		t.Errorf("incorrect number of replaced pods: got %d want %d", replaced, want)
+	}
+	for pod := range pods {
+		for _, w := range Workloads {
+			if w.DaemonSet && strings.HasPrefix(pod, w.Name+"-") && pods[pod] != len(epochs)*len(measurements) {
+				t.Errorf("daemon set pod %s churned: %d points", pod, pods[pod])
+			}
+		}
+	}
+}
+
+func TestSimulatorNodesJoin(t *testing.T) {
+	c := &SimulatorConfig{
+		Start:         testStart,
+		End:           testStart.Add(time.Hour),
+		InitNodeCount: 1,
+		NodeCount:     NodesPerCluster + 1,
+	}
+	rand.Seed(123)
+	sim := c.NewSimulator(10*time.Second, 0).(*Simulator)
+	clusters := map[string]bool{}
+	first := map[string]time.Time{}
+	p := data.NewPoint()
+	for !sim.Finished() {
+		sim.Next(p)
+		clusters[p.GetTagValue([]byte("cluster")).(string)] = true
+		node := p.GetTagValue([]byte("node")).(string)
+		if _, ok := first[node]; !ok {
+			first[node] = *p.Timestamp()
+		}
+		p.Reset()
+	}
+	if sim.activeNodes != NodesPerCluster+1 {
+		t.Errorf("incorrect number of nodes at the end: got %d want %d", sim.activeNodes, NodesPerCluster+1)
+	}
+	if len(clusters) != 2 {
+		t.Errorf("incorrect number of clusters: got %d want 2", len(clusters))
+	}
+	if !first[NodeName(0)].Equal(testStart) || !first[NodeName(NodesPerCluster)].After(first[NodeName(1)]) {
+		t.Errorf("nodes did not join one by one: %v", first)
+	}
+}
+
+func TestContainerOOMKilled(t *testing.T) {
+	rand.Seed(123)
+	c := newContainer("app", 1, gibibyte)
+	c.leak = float64(c.memoryLimit) / 3600
+	for i := 0; i < 4*360 && c.restarts == 0; i++ {
+		c.tick(10 * time.Second)
+		if c.workingSet > float64(c.memoryLimit) {
+			t.Fatalf("working set above the limit: %f", c.workingSet)
+		}
+	}
+	if c.restarts == 0 {
+		t.Errorf("leaking container not restarted")
+	}
+	if c.ready {
+		t.Errorf("container ready right after a restart")
+	}
+	c.tick(10 * time.Second)
+	if !c.ready {
+		t.Errorf("container not ready after a restart")
+	}
+}
@@ -0,0 +1,123 @@
+package k8s
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+const (
+	// NodesPerCluster is the number of nodes of a cluster; node i is in
+	// cluster i / NodesPerCluster.
+	NodesPerCluster = 50
+
+	// deploymentPodsPerNode is the number of deployment pods scheduled on a
+	// node when it joins, besides one pod of each daemon set.
+	deploymentPodsPerNode = 6
+
+	// nameAlphabet is the alphabet of the random suffixes of the pod names,
+	// the one of Kubernetes, without vowels and look-alike characters.
+	nameAlphabet = "bcdfghjklmnpqrstvwxz2456789"
+)
+
+// Workload is a deployment or a daemon set of a cluster, whose pods all run
+// the same containers.
+type Workload struct {
+	Namespace  string
+	Name       string
+	Containers []string
+	// DaemonSet workloads run one pod on every node and are never churned
+	DaemonSet bool
+
+	// replicas is the relative number of pods of a deployment
+	replicas int
+	// cpuRequest and memoryRequest are the requests of the main (first)
+	// container, the sidecars request sidecarCPURequest and
+	// sidecarMemoryRequest
+	cpuRequest    float64
+	memoryRequest int64
+}
+
+const (
+	mebibyte = 1 << 20
+	gibibyte = 1 << 30
+
+	sidecarCPURequest    = 0.05
+	sidecarMemoryRequest = 64 * mebibyte
+
+	// limits are this many times the requests
+	limitFactor = 2
+)
+
+// Workloads are the workloads run in every cluster.
+var Workloads = []Workload{
+	{Namespace: "kube-system", Name: "kube-proxy", Containers: []string{"kube-proxy"}, DaemonSet: true, cpuRequest: 0.1, memoryRequest: 128 * mebibyte},
+	{Namespace: "monitoring", Name: "node-exporter", Containers: []string{"node-exporter"}, DaemonSet: true, cpuRequest: 0.1, memoryRequest: 64 * mebibyte},
+	{Namespace: "kube-system", Name: "coredns", Containers: []string{"coredns"}, replicas: 1, cpuRequest: 0.1, memoryRequest: 128 * mebibyte},
+	{Namespace: "monitoring", Name: "prometheus", Containers: []string{"prometheus", "config-reloader"}, replicas: 1, cpuRequest: 1, memoryRequest: 4 * gibibyte},
+	{Namespace: "ingress", Name: "ingress-nginx", Containers: []string{"controller"}, replicas: 1, cpuRequest: 0.5, memoryRequest: 512 * mebibyte},
+	{Namespace: "frontend", Name: "web", Containers: []string{"web", "envoy"}, replicas: 3, cpuRequest: 0.5, memoryRequest: 512 * mebibyte},
+	{Namespace: "frontend", Name: "api-gateway", Containers: []string{"gateway", "envoy"}, replicas: 2, cpuRequest: 0.5, memoryRequest: 256 * mebibyte},
+	{Namespace: "checkout", Name: "cart", Containers: []string{"cart", "envoy"}, replicas: 2, cpuRequest: 0.25, memoryRequest: 256 * mebibyte},
+	{Namespace: "checkout", Name: "checkout", Containers: []string{"checkout", "envoy"}, replicas: 2, cpuRequest: 0.5, memoryRequest: 512 * mebibyte},
+	{Namespace: "payments", Name: "payments", Containers: []string{"payments", "envoy"}, replicas: 1, cpuRequest: 0.5, memoryRequest: 512 * mebibyte},
+	{Namespace: "search", Name: "search", Containers: []string{"search", "envoy"}, replicas: 2, cpuRequest: 2, memoryRequest: 4 * gibibyte},
+	{Namespace: "search", Name: "indexer", Containers: []string{"indexer"}, replicas: 1, cpuRequest: 1, memoryRequest: 2 * gibibyte},
+	{Namespace: "batch", Name: "report-worker", Containers: []string{"worker"}, replicas: 2, cpuRequest: 1, memoryRequest: gibibyte},
+	{Namespace: "batch", Name: "etl", Containers: []string{"etl", "log-shipper"}, replicas: 1, cpuRequest: 2, memoryRequest: 2 * gibibyte},
+}
+
+// deploymentSlots lists the indexes of the deployments in Workloads, each
+// repeated by its replicas. Every deployment comes once before any repeats,
+// so a cluster of two nodes already runs all of them.
+var deploymentSlots = func() []int {
+	var slots []int
+	for round := 0; ; round++ {
+		added := false
+		for i, w := range Workloads {
+			if !w.DaemonSet && round < w.replicas {
+				slots = append(slots, i)
+				added = true
+			}
+		}
+		if !added {
+			return slots
+		}
+	}
+}()
+
+// Namespaces returns the namespaces of the workloads.
+func Namespaces() []string {
+	var namespaces []string
+	seen := map[string]bool{}
+	for _, w := range Workloads {
+		if !seen[w.Namespace] {
+			seen[w.Namespace] = true
+			namespaces = append(namespaces, w.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// ClusterCount returns the number of clusters of nodes nodes.
+func ClusterCount(nodes int) int {
+	return (nodes + NodesPerCluster - 1) / NodesPerCluster
+}
+
+// ClusterName returns the name of cluster i, e.g. "cluster_0".
+func ClusterName(i int) string {
+	return fmt.Sprintf("cluster_%d", i)
+}
+
+// NodeName returns the name of node i, e.g. "node_12".
+func NodeName(i int) string {
+	return fmt.Sprintf("node_%d", i)
+}
+
+// randomSuffix returns n random characters of the pod name alphabet.
+func randomSuffix(n int) string {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = nameAlphabet[rand.Intn(len(nameAlphabet))]
+	}
+	return string(b)
+}
@@ -0,0 +1,102 @@
+package netflow
+
+import (
+	"math"
+	"math/rand"
+	"strconv"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var (
+	tagKeys = [][]byte{
+		[]byte("src_ip"),
+		[]byte("dst_ip"),
+		[]byte("src_port"),
+		[]byte("dst_port"),
+		[]byte("protocol"),
+		[]byte("exporter"),
+	}
+
+	flowMeasurement = []byte("flows")
+
+	flowFields = [][]byte{
+		[]byte("bytes"),
+		[]byte("packets"),
+		[]byte("duration_ms"),
+	}
+)
+
+// scanPacketSize is the size of the packets of a port scan, a TCP SYN and
+// the reset it gets
+const scanPacketSize = 44
+
+// flow is a flow record: the traffic from a source address and port to a
+// destination address and port over the duration of the flow.
+type flow struct {
+	end      time.Time
+	src      int
+	dst      int
+	srcPort  int
+	dstPort  int
+	protocol string
+	bytes    float64
+	packets  float64
+	duration float64 // ms
+}
+
+// newFlow returns a flow from src to dst, ending at end, to a random service.
+func newFlow(end time.Time, src, dst, srcPorts int) *flow {
+	svc := &services[len(services)-1]
+	r := rand.Float64()
+	for i := range services {
+		if r < services[i].share {
+			svc = &services[i]
+			break
+		}
+		r -= services[i].share
+	}
+	bytes := math.Round(svc.medianBytes * math.Exp(rand.NormFloat64()))
+	return &flow{
+		end:      end,
+		src:      src,
+		dst:      dst,
+		srcPort:  ephemeralPortStart + rand.Intn(srcPorts),
+		dstPort:  svc.port,
+		protocol: svc.protocol,
+		bytes:    bytes,
+		packets:  math.Max(1, math.Round(bytes/svc.packetSize)),
+		duration: math.Round(10*svc.medianDuration*math.Exp(rand.NormFloat64())) / 10,
+	}
+}
+
+// newScanFlow returns the flow of a port scan of port of dst by src.
+func newScanFlow(end time.Time, src, dst, port int) *flow {
+	return &flow{
+		end:      end,
+		src:      src,
+		dst:      dst,
+		srcPort:  ephemeralPortStart + rand.Intn(65536-ephemeralPortStart),
+		dstPort:  port,
+		protocol: "tcp",
+		bytes:    2 * scanPacketSize,
+		packets:  2,
+		duration: math.Round(10*rand.ExpFloat64()) / 10,
+	}
+}
+
+// toPoint writes the flow record to p.
+func (f *flow) toPoint(p *data.Point) {
+	p.SetTimestamp(&f.end)
+	p.SetMeasurementName(flowMeasurement)
+	p.AppendTag(tagKeys[0], HostAddress(f.src))
+	p.AppendTag(tagKeys[1], HostAddress(f.dst))
+	p.AppendTag(tagKeys[2], strconv.Itoa(f.srcPort))
+	p.AppendTag(tagKeys[3], strconv.Itoa(f.dstPort))
+	p.AppendTag(tagKeys[4], f.protocol)
+	p.AppendTag(tagKeys[5], ExporterName(f.src/HostsPerSubnet/SubnetsPerExporter))
+	p.AppendField(flowFields[0], f.bytes)
+	p.AppendField(flowFields[1], f.packets)
+	p.AppendField(flowFields[2], f.duration)
+}
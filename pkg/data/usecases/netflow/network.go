@@ -0,0 +1,68 @@
+package netflow
+
+import "fmt"
+
+const (
+	// HostsPerSubnet is the number of hosts of a /24 subnet; host i is in
+	// subnet i / HostsPerSubnet.
+	HostsPerSubnet = 254
+	// SubnetsPerExporter is the number of subnets whose flows are exported by
+	// the same router; the flows of subnet i are exported by exporter
+	// i / SubnetsPerExporter.
+	SubnetsPerExporter = 16
+
+	// ephemeralPortStart is the first port the clients pick their source
+	// port from
+	ephemeralPortStart = 49152
+)
+
+// service is a kind of traffic, to a well-known port.
+type service struct {
+	port     int
+	protocol string
+	// share is the fraction of the flows to the service
+	share float64
+	// medianBytes and medianDuration are the median size of a flow, in
+	// bytes, and its median duration, in ms
+	medianBytes    float64
+	medianDuration float64
+	// packetSize is the mean size of the packets, in bytes
+	packetSize float64
+}
+
+var services = []service{
+	{port: 443, protocol: "tcp", share: 0.45, medianBytes: 20000, medianDuration: 800, packetSize: 900},
+	{port: 80, protocol: "tcp", share: 0.1, medianBytes: 8000, medianDuration: 300, packetSize: 700},
+	{port: 53, protocol: "udp", share: 0.2, medianBytes: 120, medianDuration: 5, packetSize: 80},
+	{port: 22, protocol: "tcp", share: 0.03, medianBytes: 50000, medianDuration: 60000, packetSize: 300},
+	{port: 5432, protocol: "tcp", share: 0.07, medianBytes: 4000, medianDuration: 20, packetSize: 400},
+	{port: 6379, protocol: "tcp", share: 0.08, medianBytes: 600, medianDuration: 2, packetSize: 200},
+	{port: 123, protocol: "udp", share: 0.04, medianBytes: 76, medianDuration: 1, packetSize: 76},
+	{port: 8080, protocol: "tcp", share: 0.03, medianBytes: 12000, medianDuration: 500, packetSize: 800},
+}
+
+// SubnetCount returns the number of subnets of hosts hosts.
+func SubnetCount(hosts int) int {
+	return (hosts + HostsPerSubnet - 1) / HostsPerSubnet
+}
+
+// ExporterCount returns the number of exporters of hosts hosts.
+func ExporterCount(hosts int) int {
+	return (SubnetCount(hosts) + SubnetsPerExporter - 1) / SubnetsPerExporter
+}
+
+// SubnetPrefix returns the first three bytes of the addresses of subnet i,
+// e.g. "10.0.3.".
+func SubnetPrefix(i int) string {
+	return fmt.Sprintf("10.%d.%d.", i/256%256, i%256)
+}
+
+// HostAddress returns the IP address of host i.
+func HostAddress(i int) string {
+	return fmt.Sprintf("%s%d", SubnetPrefix(i/HostsPerSubnet), i%HostsPerSubnet+1)
+}
+
+// ExporterName returns the name of exporter i.
+func ExporterName(i int) string {
+	return fmt.Sprintf("exporter_%d", i)
+}
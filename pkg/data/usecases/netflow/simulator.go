@@ -0,0 +1,235 @@
+package netflow
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+	"github.com/timescale/tsbs/pkg/data/usecases/common"
+)
+
+const (
+	// scansPerHour is the rate of the port scans over the whole network
+	scansPerHour = 1.0
+	// scanPorts is the number of ports probed by a port scan
+	scanPorts = 1000
+	// scanPortsPerSecond is the rate a port scan probes the ports at
+	scanPortsPerSecond = 20.0
+)
+
+// SimulatorConfig is used to create a Simulator.
+type SimulatorConfig struct {
+	// Start is the beginning time for the Simulator
+	Start time.Time
+	// End is the ending time for the Simulator
+	End time.Time
+	// InitHostCount is the number of hosts to start with, the others join the
+	// network one by one over the run
+	InitHostCount uint64
+	// HostCount is the total number of hosts
+	HostCount uint64
+	// SrcZipfS and DstZipfS are the exponents of the Zipf distributions of
+	// the source and of the destination hosts of the flows; both must be
+	// greater than 1, the larger the more the traffic goes from and to a few
+	// hosts
+	SrcZipfS float64
+	DstZipfS float64
+	// SrcPortCount is the number of source ports a host picks from when
+	// opening a connection
+	SrcPortCount int
+}
+
+// NewSimulator produces a Simulator writing as many flow records as there are
+// hosts every interval.
+func (c *SimulatorConfig) NewSimulator(interval time.Duration, limit uint64) common.Simulator {
+	initHosts := c.InitHostCount
+	if initHosts == 0 || initHosts > c.HostCount {
+		initHosts = c.HostCount
+	}
+	s := &Simulator{
+		maxPoints:    limit,
+		epochs:       uint64(c.End.Sub(c.Start).Nanoseconds() / interval.Nanoseconds()),
+		start:        c.Start,
+		interval:     interval,
+		initHosts:    initHosts,
+		hostCount:    c.HostCount,
+		srcZipfS:     c.SrcZipfS,
+		dstZipfS:     c.DstZipfS,
+		srcPorts:     c.SrcPortCount,
+		rng:          rand.New(rand.NewSource(rand.Int63())),
+		scanChance:   math.Min(1, scansPerHour*interval.Hours()),
+		scanPerEpoch: int(math.Max(1, scanPortsPerSecond*interval.Seconds())),
+	}
+	s.setHosts(initHosts)
+	s.flows = s.epochFlows()
+	return s
+}
+
+// portScan is a host probing the ports of another one.
+type portScan struct {
+	src, dst int
+	// next is the next port to probe
+	next int
+	// left is the number of ports left to probe
+	left int
+}
+
+// Simulator simulates the flows of a network of hosts. The sources and the
+// destinations of the flows follow Zipf distributions, the most active
+// sources being the least popular destinations, and now and then a host
+// scans the ports of another one.
+type Simulator struct {
+	madePoints uint64
+	maxPoints  uint64 // 0 = until the end time
+
+	epoch    uint64
+	epochs   uint64
+	start    time.Time
+	interval time.Duration
+
+	initHosts uint64
+	hostCount uint64
+	hosts     int
+
+	srcZipfS float64
+	dstZipfS float64
+	srcPorts int
+	rng      *rand.Rand
+	srcZipf  *rand.Zipf
+	dstZipf  *rand.Zipf
+
+	scanChance   float64 // of a port scan starting, per interval
+	scanPerEpoch int     // ports probed per interval
+	scans        []*portScan
+
+	// flows are the flows of the current epoch still to write, in time
+	// order
+	flows []*flow
+}
+
+// Finished tells whether we have simulated all the necessary points.
+func (s *Simulator) Finished() bool {
+	return s.epoch >= s.epochs || (s.maxPoints > 0 && s.madePoints >= s.maxPoints)
+}
+
+// Next writes the next flow record to p.
+func (s *Simulator) Next(p *data.Point) bool {
+	for len(s.flows) == 0 {
+		s.nextEpoch()
+		if s.epoch >= s.epochs {
+			return false
+		}
+		s.flows = s.epochFlows()
+	}
+	s.flows[0].toPoint(p)
+	s.flows[0] = nil
+	s.flows = s.flows[1:]
+	s.madePoints++
+	return true
+}
+
+// setHosts sets the number of hosts in the network and the distributions of
+// the sources and destinations over them.
+func (s *Simulator) setHosts(hosts uint64) {
+	s.hosts = int(hosts)
+	s.srcZipf = rand.NewZipf(s.rng, s.srcZipfS, 1, hosts-1)
+	s.dstZipf = rand.NewZipf(s.rng, s.dstZipfS, 1, hosts-1)
+}
+
+// epochFlows returns the flows ending in the current epoch, sorted by time.
+func (s *Simulator) epochFlows() []*flow {
+	epochStart := s.start.Add(time.Duration(s.epoch) * s.interval)
+	randomTime := func() time.Time {
+		return epochStart.Add(time.Duration(rand.Int63n(int64(s.interval))))
+	}
+
+	flows := make([]*flow, 0, s.hosts)
+	for i := 0; i < s.hosts; i++ {
+		src := int(s.srcZipf.Uint64())
+		// the most popular destinations are the least active sources
+		dst := s.hosts - 1 - int(s.dstZipf.Uint64())
+		if dst == src {
+			dst = (dst + 1) % s.hosts
+		}
+		flows = append(flows, newFlow(randomTime(), src, dst, s.srcPorts))
+	}
+
+	if rand.Float64() < s.scanChance {
+		s.scans = append(s.scans, &portScan{
+			src:  rand.Intn(s.hosts),
+			dst:  rand.Intn(s.hosts),
+			next: 1 + rand.Intn(65535-scanPorts),
+			left: scanPorts,
+		})
+	}
+	scans := s.scans[:0]
+	for _, scan := range s.scans {
+		for i := 0; i < s.scanPerEpoch && scan.left > 0; i++ {
+			flows = append(flows, newScanFlow(randomTime(), scan.src, scan.dst, scan.next))
+			scan.next++
+			scan.left--
+		}
+		if scan.left > 0 {
+			scans = append(scans, scan)
+		}
+	}
+	s.scans = scans
+
+	sort.Slice(flows, func(i, j int) bool { return flows[i].end.Before(flows[j].end) })
+	return flows
+}
+
+// nextEpoch advances to the next epoch and adds the hosts joining the
+// network in it.
+func (s *Simulator) nextEpoch() {
+	s.epoch++
+	if s.epochs > 1 {
+		missing := float64(s.hostCount - s.initHosts)
+		target := s.initHosts + uint64(missing*float64(s.epoch)/float64(s.epochs-1))
+		if target > s.hostCount {
+			target = s.hostCount
+		}
+		if target > uint64(s.hosts) {
+			s.setHosts(target)
+		}
+	}
+}
+
+// Fields returns the fields of the flow records.
+func (s *Simulator) Fields() map[string][]string {
+	return map[string][]string{
+		string(flowMeasurement): bytesToStrings(flowFields),
+	}
+}
+
+// TagKeys returns the tag keys of the flow records.
+func (s *Simulator) TagKeys() []string {
+	return bytesToStrings(tagKeys)
+}
+
+// TagTypes returns the types of the tags of the flow records.
+func (s *Simulator) TagTypes() []string {
+	types := make([]string, len(tagKeys))
+	for i := range types {
+		types[i] = "string"
+	}
+	return types
+}
+
+func (s *Simulator) Headers() *common.GeneratedDataHeaders {
+	return &common.GeneratedDataHeaders{
+		TagTypes:  s.TagTypes(),
+		TagKeys:   s.TagKeys(),
+		FieldKeys: s.Fields(),
+	}
+}
+
+func bytesToStrings(in [][]byte) []string {
+	out := make([]string, len(in))
+	for i, b := range in {
+		out[i] = string(b)
+	}
+	return out
+}
@@ -0,0 +1,133 @@
+package netflow
+
+import (
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/data"
+)
+
+var testStart = time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestHostAddress(t *testing.T) {
+	cases := []struct {
+		host int
+		want string
+	}{
+		{host: 0, want: "10.0.0.1"},
+		{host: HostsPerSubnet - 1, want: "10.0.0.254"},
+		{host: HostsPerSubnet, want: "10.0.1.1"},
+		{host: 256*HostsPerSubnet + 2, want: "10.1.0.3"},
+	}
+	for _, c := range cases {
+		if got := HostAddress(c.host); got != c.want {
+			t.Errorf("incorrect address of host %d: got %s want %s", c.host, got, c.want)
+		}
+	}
+	if got := ExporterCount(SubnetsPerExporter*HostsPerSubnet + 1); got != 2 {
+		t.Errorf("incorrect number of exporters: got %d want 2", got)
+	}
+}
+
+// runSimulator runs s and returns the bytes sent per source address and the
+// destination ports probed per source and destination address.
+func runSimulator(t *testing.T, s *Simulator) (map[string]float64, map[string]map[string]bool) {
+	sent := map[string]float64{}
+	ports := map[string]map[string]bool{}
+	var last time.Time
+	p := data.NewPoint()
+	for !s.Finished() {
+		if !s.Next(p) {
+			break
+		}
+		ts := *p.Timestamp()
+		if ts.Before(last) {
+			t.Errorf("flow at %v written after one at %v", ts, last)
+		}
+		last = ts
+		src := p.GetTagValue(tagKeys[0]).(string)
+		pair := src + ">" + p.GetTagValue(tagKeys[1]).(string)
+		if ports[pair] == nil {
+			ports[pair] = map[string]bool{}
+		}
+		ports[pair][p.GetTagValue(tagKeys[3]).(string)] = true
+		sent[src] += p.GetFieldValue(flowFields[0]).(float64)
+		p.Reset()
+	}
+	return sent, ports
+}
+
+func TestSimulator(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:        testStart,
+		End:          testStart.Add(time.Hour),
+		HostCount:    1000,
+		SrcZipfS:     1.2,
+		DstZipfS:     1.5,
+		SrcPortCount: 10,
+	}
+	s := c.NewSimulator(time.Minute, 0).(*Simulator)
+	sent, ports := runSimulator(t, s)
+	if s.madePoints < 60*1000 {
+		t.Errorf("too few flows: got %d want at least %d", s.madePoints, 60*1000)
+	}
+	if sent[HostAddress(0)] <= sent[HostAddress(999)] {
+		t.Errorf("first host did not send more than the last one: %f <= %f", sent[HostAddress(0)], sent[HostAddress(999)])
+	}
+
+	scans := 0
+	for pair, dstPorts := range ports {
+		if len(dstPorts) > len(services) {
+			scans++
+			if len(dstPorts) < scanPorts/2 {
+				t.Errorf("too few ports probed by %s: %d", pair, len(dstPorts))
+			}
+		}
+	}
+	if scans == 0 {
+		t.Errorf("no port scan")
+	}
+}
+
+func TestSimulatorSrcPorts(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:        testStart,
+		End:          testStart.Add(10 * time.Minute),
+		HostCount:    10,
+		SrcZipfS:     1.2,
+		DstZipfS:     1.5,
+		SrcPortCount: 3,
+	}
+	s := c.NewSimulator(time.Minute, 0).(*Simulator)
+	s.scanChance = 0
+	p := data.NewPoint()
+	for !s.Finished() && s.Next(p) {
+		port, err := strconv.Atoi(p.GetTagValue(tagKeys[2]).(string))
+		if err != nil || port < ephemeralPortStart || port >= ephemeralPortStart+3 {
+			t.Errorf("incorrect source port: %v", p.GetTagValue(tagKeys[2]))
+		}
+		p.Reset()
+	}
+}
+
+func TestSimulatorHostsJoin(t *testing.T) {
+	rand.Seed(123)
+	c := &SimulatorConfig{
+		Start:         testStart,
+		End:           testStart.Add(time.Hour),
+		InitHostCount: 10,
+		HostCount:     100,
+		SrcZipfS:      1.01,
+		DstZipfS:      1.01,
+		SrcPortCount:  10,
+	}
+	s := c.NewSimulator(time.Minute, 0).(*Simulator)
+	runSimulator(t, s)
+	if s.hosts != 100 {
+		t.Errorf("incorrect number of hosts at the end: got %d want 100", s.hosts)
+	}
+}
@@ -6,9 +6,14 @@ import (
 
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/custom"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
+	"github.com/timescale/tsbs/pkg/data/usecases/energy"
+	"github.com/timescale/tsbs/pkg/data/usecases/events"
 	"github.com/timescale/tsbs/pkg/data/usecases/finance"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/k8s"
+	"github.com/timescale/tsbs/pkg/data/usecases/netflow"
 )
 
 const errCannotParseTimeFmt = "cannot parse time from string '%s': %v"
@@ -24,6 +29,10 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 	if err != nil {
 		return nil, fmt.Errorf(errCannotParseTimeFmt, dgc.TimeEnd, err)
 	}
+	patterns, err := common.ParseMetricPatterns(dgc.MetricPatterns)
+	if err != nil {
+		return nil, err
+	}
 
 	switch dgc.Use {
 	case common.UseCaseDevops:
@@ -34,6 +43,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHost,
+			Patterns:        patterns,
 		}
 	case common.UseCaseIoT:
 		ret = &iot.SimulatorConfig{
@@ -43,6 +53,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitGeneratorScale:   dgc.InitialScale,
 			GeneratorScale:       dgc.Scale,
 			GeneratorConstructor: iot.NewTruck,
+			Patterns:             patterns,
 		}
 	case common.UseCaseCPUOnly:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -52,6 +63,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUOnly,
+			Patterns:        patterns,
 		}
 	case common.UseCaseCPUSingle:
 		ret = &devops.CPUOnlySimulatorConfig{
@@ -61,6 +73,7 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 			InitHostCount:   dgc.InitialScale,
 			HostCount:       dgc.Scale,
 			HostConstructor: devops.NewHostCPUSingle,
+			Patterns:        patterns,
 		}
 	case common.UseCaseDevopsGeneric:
 		if dgc.InitialScale == dgc.Scale {
@@ -76,16 +89,67 @@ func GetSimulatorConfig(dgc *common.DataGeneratorConfig) (common.SimulatorConfig
 				HostCount:       dgc.Scale,
 				HostConstructor: devops.NewHostGenericMetrics,
 				MaxMetricCount:  dgc.MaxMetricCountPerHost,
+				Patterns:        patterns,
 			},
 		}
 	case common.UseCaseFinance:
-		ret = &common.BaseSimulatorConfig{
+		ret = &finance.MarketSimulatorConfig{
 			Start: tsStart,
 			End:   tsEnd,
 
-			InitGeneratorScale:   dgc.InitialScale,
-			GeneratorScale:       dgc.Scale,
-			GeneratorConstructor: finance.NewSymbol,
+			InitSymbolCount: dgc.InitialScale,
+			SymbolCount:     dgc.Scale,
+		}
+	case common.UseCaseK8s:
+		ret = &k8s.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitNodeCount: dgc.InitialScale,
+			NodeCount:     dgc.Scale,
+			PodChurnRate:  dgc.PodChurnRate,
+		}
+	case common.UseCaseEnergy:
+		ret = &energy.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitMeterCount:   dgc.InitialScale,
+			MeterCount:       dgc.Scale,
+			LateArrivalRate:  dgc.LateArrivalRate,
+			LateArrivalDelay: dgc.LateArrivalDelay,
+		}
+	case common.UseCaseEvents:
+		ret = &events.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitHostCount: dgc.InitialScale,
+			HostCount:     dgc.Scale,
+		}
+	case common.UseCaseNetflow:
+		ret = &netflow.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitHostCount: dgc.InitialScale,
+			HostCount:     dgc.Scale,
+			SrcZipfS:      dgc.SrcIPZipfS,
+			DstZipfS:      dgc.DstIPZipfS,
+			SrcPortCount:  dgc.SrcPortCount,
+		}
+	case common.UseCaseCustom:
+		spec, err := custom.Load(dgc.UseCaseSpec)
+		if err != nil {
+			return nil, err
+		}
+		ret = &custom.SimulatorConfig{
+			Start: tsStart,
+			End:   tsEnd,
+
+			InitEntityCount: dgc.InitialScale,
+			EntityCount:     dgc.Scale,
+			Spec:            spec,
 		}
 	default:
 		err = fmt.Errorf("unknown use case: '%s'", dgc.Use)
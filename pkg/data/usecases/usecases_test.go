@@ -2,8 +2,13 @@ package usecases
 
 import (
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
+	"github.com/timescale/tsbs/pkg/data/usecases/custom"
 	"github.com/timescale/tsbs/pkg/data/usecases/devops"
+	"github.com/timescale/tsbs/pkg/data/usecases/energy"
+	"github.com/timescale/tsbs/pkg/data/usecases/events"
 	"github.com/timescale/tsbs/pkg/data/usecases/iot"
+	"github.com/timescale/tsbs/pkg/data/usecases/k8s"
+	"github.com/timescale/tsbs/pkg/data/usecases/netflow"
 	"reflect"
 	"testing"
 	"time"
@@ -38,6 +43,24 @@ func TestGetSimulatorConfig(t *testing.T) {
 	checkType(common.UseCaseIoT, &iot.SimulatorConfig{})
 	checkType(common.UseCaseCPUOnly, &devops.CPUOnlySimulatorConfig{})
 	checkType(common.UseCaseCPUSingle, &devops.CPUOnlySimulatorConfig{})
+	checkType(common.UseCaseK8s, &k8s.SimulatorConfig{})
+	checkType(common.UseCaseEnergy, &energy.SimulatorConfig{})
+	checkType(common.UseCaseEvents, &events.SimulatorConfig{})
+	checkType(common.UseCaseNetflow, &netflow.SimulatorConfig{})
+
+	dgc.UseCaseSpec = "../../../docs/sample-configs/custom-use-case-spec.yaml"
+	checkType(common.UseCaseCustom, &custom.SimulatorConfig{})
+	dgc.UseCaseSpec = "does-not-exist.yaml"
+	if _, err := GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for missing use case spec")
+	}
+
+	dgc.MetricPatterns = common.PatternSeasonality
+	checkType(common.UseCaseDevops, &devops.DevopsSimulatorConfig{})
+	dgc.MetricPatterns = "bogus pattern"
+	if _, err := GetSimulatorConfig(dgc); err == nil {
+		t.Errorf("unexpected lack of error for bogus metric pattern")
+	}
 
 	dgc.Use = "bogus use case"
 	_, err := GetSimulatorConfig(dgc)
@@ -0,0 +1,297 @@
+// Package metrics serves live metrics of a running benchmark in the Prometheus
+// text exposition format, so long runs can be watched with Prometheus and
+// Grafana next to the metrics of the database under test.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LatencyBuckets are the upper bounds, in seconds, of the buckets of the
+// latency histograms.
+var LatencyBuckets = []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60}
+
+// Counter is a monotonically increasing value.
+type Counter struct {
+	v uint64
+}
+
+// Add increases c by n.
+func (c *Counter) Add(n uint64) {
+	atomic.AddUint64(&c.v, n)
+}
+
+// Inc increases c by one.
+func (c *Counter) Inc() {
+	c.Add(1)
+}
+
+func (c *Counter) value() float64 {
+	return float64(atomic.LoadUint64(&c.v))
+}
+
+// Gauge is a value that goes up and down.
+type Gauge struct {
+	v int64
+}
+
+// Add changes g by n.
+func (g *Gauge) Add(n int64) {
+	atomic.AddInt64(&g.v, n)
+}
+
+func (g *Gauge) value() float64 {
+	return float64(atomic.LoadInt64(&g.v))
+}
+
+// Histogram counts observations in buckets of configurable upper bounds.
+type Histogram struct {
+	buckets []float64
+	counts  []uint64 // counts[i] is the count of buckets[i] alone, the last one is +Inf
+	sumBits uint64
+}
+
+func newHistogram(buckets []float64) *Histogram {
+	return &Histogram{buckets: buckets, counts: make([]uint64, len(buckets)+1)}
+}
+
+// Observe adds v to h.
+func (h *Histogram) Observe(v float64) {
+	i := sort.SearchFloat64s(h.buckets, v)
+	atomic.AddUint64(&h.counts[i], 1)
+	for {
+		old := atomic.LoadUint64(&h.sumBits)
+		sum := math.Float64bits(math.Float64frombits(old) + v)
+		if atomic.CompareAndSwapUint64(&h.sumBits, old, sum) {
+			return
+		}
+	}
+}
+
+// ObserveDuration adds d, in seconds, to h.
+func (h *Histogram) ObserveDuration(d time.Duration) {
+	h.Observe(d.Seconds())
+}
+
+type metricType string
+
+const (
+	typeCounter   metricType = "counter"
+	typeGauge     metricType = "gauge"
+	typeHistogram metricType = "histogram"
+)
+
+// family is a metric and its series, one per value of its label if it has one.
+type family struct {
+	name  string
+	help  string
+	typ   metricType
+	label string // name of the label of the series, empty for a single series
+
+	mu     sync.RWMutex
+	series map[string]interface{} // by label value
+	newFn  func() interface{}
+}
+
+func (f *family) with(labelValue string) interface{} {
+	f.mu.RLock()
+	s, ok := f.series[labelValue]
+	f.mu.RUnlock()
+	if ok {
+		return s
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if s, ok = f.series[labelValue]; !ok {
+		s = f.newFn()
+		f.series[labelValue] = s
+	}
+	return s
+}
+
+// Registry holds the metrics of a benchmark and writes them in the Prometheus
+// text exposition format.
+type Registry struct {
+	prefix   string
+	mu       sync.Mutex
+	families []*family
+}
+
+// NewRegistry returns an empty Registry whose metric names start with prefix.
+func NewRegistry(prefix string) *Registry {
+	return &Registry{prefix: prefix}
+}
+
+func (r *Registry) register(name, help string, typ metricType, label string, newFn func() interface{}) *family {
+	f := &family{
+		name:   r.prefix + name,
+		help:   help,
+		typ:    typ,
+		label:  label,
+		series: map[string]interface{}{},
+		newFn:  newFn,
+	}
+	r.mu.Lock()
+	r.families = append(r.families, f)
+	r.mu.Unlock()
+	return f
+}
+
+// Counter registers a counter.
+func (r *Registry) Counter(name, help string) *Counter {
+	return r.register(name, help, typeCounter, "", func() interface{} { return &Counter{} }).with("").(*Counter)
+}
+
+// CounterFunc registers a counter whose value is read with fn when the
+// metrics are written, for values the benchmark already counts.
+func (r *Registry) CounterFunc(name, help string, fn func() float64) {
+	r.register(name, help, typeCounter, "", func() interface{} { return fn }).with("")
+}
+
+// Gauge registers a gauge.
+func (r *Registry) Gauge(name, help string) *Gauge {
+	return r.register(name, help, typeGauge, "", func() interface{} { return &Gauge{} }).with("").(*Gauge)
+}
+
+// Histogram registers a histogram with the given bucket upper bounds.
+func (r *Registry) Histogram(name, help string, buckets []float64) *Histogram {
+	return r.register(name, help, typeHistogram, "", func() interface{} { return newHistogram(buckets) }).with("").(*Histogram)
+}
+
+// CounterVec is a counter with a series per value of its label.
+type CounterVec struct {
+	f *family
+}
+
+// CounterVec registers a counter with a series per value of label.
+func (r *Registry) CounterVec(name, help, label string) *CounterVec {
+	return &CounterVec{r.register(name, help, typeCounter, label, func() interface{} { return &Counter{} })}
+}
+
+// With returns the series of labelValue.
+func (v *CounterVec) With(labelValue string) *Counter {
+	return v.f.with(labelValue).(*Counter)
+}
+
+// HistogramVec is a histogram with a series per value of its label.
+type HistogramVec struct {
+	f *family
+}
+
+// HistogramVec registers a histogram with a series per value of label.
+func (r *Registry) HistogramVec(name, help, label string, buckets []float64) *HistogramVec {
+	return &HistogramVec{r.register(name, help, typeHistogram, label, func() interface{} { return newHistogram(buckets) })}
+}
+
+// With returns the series of labelValue.
+func (v *HistogramVec) With(labelValue string) *Histogram {
+	return v.f.with(labelValue).(*Histogram)
+}
+
+// Write writes all metrics to w in the Prometheus text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	families := append([]*family(nil), r.families...)
+	r.mu.Unlock()
+
+	var sb strings.Builder
+	for _, f := range families {
+		fmt.Fprintf(&sb, "# HELP %s %s\n# TYPE %s %s\n", f.name, f.help, f.name, f.typ)
+		f.mu.RLock()
+		labelValues := make([]string, 0, len(f.series))
+		for lv := range f.series {
+			labelValues = append(labelValues, lv)
+		}
+		sort.Strings(labelValues)
+		for _, lv := range labelValues {
+			f.writeSeries(&sb, lv, f.series[lv])
+		}
+		f.mu.RUnlock()
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func (f *family) writeSeries(sb *strings.Builder, labelValue string, s interface{}) {
+	labels := ""
+	if f.label != "" {
+		labels = f.label + `="` + escapeLabelValue(labelValue) + `"`
+	}
+	switch m := s.(type) {
+	case *Counter:
+		writeSample(sb, f.name, labels, m.value())
+	case func() float64:
+		writeSample(sb, f.name, labels, m())
+	case *Gauge:
+		writeSample(sb, f.name, labels, m.value())
+	case *Histogram:
+		cumulative := uint64(0)
+		for i, upper := range m.buckets {
+			cumulative += atomic.LoadUint64(&m.counts[i])
+			writeSample(sb, f.name+"_bucket", joinLabels(labels, `le="`+formatFloat(upper)+`"`), float64(cumulative))
+		}
+		cumulative += atomic.LoadUint64(&m.counts[len(m.buckets)])
+		writeSample(sb, f.name+"_bucket", joinLabels(labels, `le="+Inf"`), float64(cumulative))
+		writeSample(sb, f.name+"_sum", labels, math.Float64frombits(atomic.LoadUint64(&m.sumBits)))
+		writeSample(sb, f.name+"_count", labels, float64(cumulative))
+	}
+}
+
+func writeSample(sb *strings.Builder, name, labels string, v float64) {
+	sb.WriteString(name)
+	if labels != "" {
+		sb.WriteString("{" + labels + "}")
+	}
+	sb.WriteString(" " + formatFloat(v) + "\n")
+}
+
+func joinLabels(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + "," + b
+}
+
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func escapeLabelValue(v string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(v)
+}
+
+// ServeHTTP writes the metrics as the response.
+func (r *Registry) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.Write(w)
+}
+
+// Serve serves the metrics of r on /metrics at addr, e.g. ":9100", until the
+// returned function is called.
+func Serve(addr string, r *Registry) (func(), error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("could not listen on %s for metrics: %v", addr, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", r)
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(ln)
+	fmt.Printf("Serving metrics on http://%s/metrics\n", ln.Addr())
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}, nil
+}
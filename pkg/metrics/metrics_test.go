@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegistryWrite(t *testing.T) {
+	r := NewRegistry("test_")
+	rows := uint64(7)
+	r.CounterFunc("rows_total", "Rows.", func() float64 { return float64(rows) })
+	workers := r.Gauge("active_workers", "Workers.")
+	workers.Add(3)
+	workers.Add(-1)
+	errs := r.CounterVec("errors_total", "Errors.", "label")
+	errs.With(`b "quoted"`).Inc()
+	errs.With("a").Add(2)
+	latencies := r.HistogramVec("duration_seconds", "Latencies.", "label", []float64{0.1, 1})
+	latencies.With("a").Observe(0.05)
+	latencies.With("a").Observe(0.1)
+	latencies.With("a").ObserveDuration(2 * time.Second)
+
+	var buf bytes.Buffer
+	if err := r.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := `# HELP test_rows_total Rows.
+# TYPE test_rows_total counter
+test_rows_total 7
+# HELP test_active_workers Workers.
+# TYPE test_active_workers gauge
+test_active_workers 2
+# HELP test_errors_total Errors.
+# TYPE test_errors_total counter
+test_errors_total{label="a"} 2
+test_errors_total{label="b \"quoted\""} 1
+# HELP test_duration_seconds Latencies.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{label="a",le="0.1"} 2
+test_duration_seconds_bucket{label="a",le="1"} 2
+test_duration_seconds_bucket{label="a",le="+Inf"} 3
+test_duration_seconds_sum{label="a"} 2.15
+test_duration_seconds_count{label="a"} 3
+`
+	if got := buf.String(); got != want {
+		t.Errorf("incorrect output: got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	r := NewRegistry("test_")
+	r.Counter("queries_total", "Queries.").Add(3)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("incorrect content type: %s", ct)
+	}
+	if body := w.Body.String(); !strings.Contains(body, "test_queries_total 3\n") {
+		t.Errorf("counter missing from response:\n%s", body)
+	}
+
+	if _, err := Serve("127.0.0.1:-1", r); err == nil {
+		t.Errorf("unexpected lack of error for invalid address")
+	}
+}
@@ -0,0 +1,72 @@
+package query
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/pkg/coordination"
+)
+
+// joinCoordinator joins the Coordinator as an agent running its slice of the
+// queries.
+func (b *BenchmarkRunner) joinCoordinator() {
+	agent, err := coordination.Join(b.Coordinator, coordination.KindQueries)
+	if err != nil {
+		panic(err.Error())
+	}
+	b.agent = agent
+	b.agentDone = make(chan struct{})
+	b.scanner.setSlice(agent.InSlice)
+	fmt.Printf("running queries as agent %d/%d of coordinator %s\n", agent.Agent+1, agent.Agents, b.Coordinator)
+}
+
+// reportProgress sends the number of completed queries to the coordinator
+// every period until the run is done.
+func (b *BenchmarkRunner) reportProgress(period time.Duration) {
+	ticker := time.NewTicker(period)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-b.agentDone:
+			return
+		case <-ticker.C:
+			// progress is best effort, the result is what counts
+			b.agent.ReportProgress(map[string]uint64{
+				coordination.CounterQueries: atomic.LoadUint64(&b.completed),
+			})
+		}
+	}
+}
+
+// reportResult sends the latency histograms and the counters of the run, which
+// went from start to end, to the coordinator.
+func (b *BenchmarkRunner) reportResult(start, end time.Time, totals map[string]interface{}) {
+	close(b.agentDone)
+	r := &coordination.AgentResult{
+		Start:      start,
+		End:        end,
+		Counters:   map[string]uint64{},
+		Histograms: map[string][]byte{},
+	}
+	all := stripRegex(labelAllQueries)
+	for label, h := range b.sp.getHistograms() {
+		encoded, err := h.Encode(hdrhistogram.V2CompressedEncodingCookieBase)
+		if err != nil {
+			panic(fmt.Sprintf("could not encode the histogram of %s: %v", label, err))
+		}
+		r.Histograms[label] = encoded
+		if label == all {
+			r.Counters[coordination.CounterQueries] = uint64(h.TotalCount())
+		}
+	}
+	// the failure counts of "all queries" are the totals of the other labels
+	errs, _ := totals["errors"].(map[string]uint64)
+	timeouts, _ := totals["timeouts"].(map[string]uint64)
+	r.Counters[coordination.CounterErrors] = errs[all]
+	r.Counters[coordination.CounterTimeouts] = timeouts[all]
+	if err := b.agent.ReportResult(r); err != nil {
+		panic(err.Error())
+	}
+}
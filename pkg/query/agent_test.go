@@ -0,0 +1,55 @@
+package query
+
+import (
+	"io/ioutil"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/coordination"
+)
+
+func TestRunStartsStatsWithAgents(t *testing.T) {
+	c := coordination.NewCoordinator(1, "")
+	addr, err := c.Start("127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	queryFile, err := ioutil.TempFile("", "queries*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(queryFile.Name())
+
+	var started time.Time
+	wg := &sync.WaitGroup{}
+	sp := &mockStatProcessor{
+		args:      &statProcessorArgs{},
+		onProcess: func(_ uint) { started = time.Now() },
+		wg:        wg,
+	}
+	limit := uint64(0)
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			Workers:     1,
+			FileName:    queryFile.Name(),
+			Coordinator: addr,
+		},
+		sp:      sp,
+		scanner: newScanner(&limit),
+	}
+
+	// the coordinator tells the agents to start some time after they are all
+	// ready, the stats must not be collected while waiting for it
+	joined := time.Now()
+	wg.Add(1)
+	b.Run(&TimescaleDBPool, func() Processor { return &mockProcessor{} })
+	wg.Wait()
+	if started.IsZero() {
+		t.Fatalf("stat processor wasn't started")
+	}
+	if waited := started.Sub(joined); waited < 500*time.Millisecond {
+		t.Errorf("stat processor started before the coordinator's start time, %v after joining", waited)
+	}
+}
@@ -0,0 +1,62 @@
+package query
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Names of the supported open-loop arrival distributions
+const (
+	ArrivalFixed   = "fixed"
+	ArrivalPoisson = "poisson"
+)
+
+const errUnknownArrivalFmt = "unknown arrival distribution '%s', valid: %s, %s"
+
+// scheduledQuery is a query of an open-loop run with the time it was meant to
+// be sent at, which its response time is measured from.
+type scheduledQuery struct {
+	query    Query
+	intended time.Time
+}
+
+// interArrival returns the time between two consecutive query arrivals.
+type interArrival func() time.Duration
+
+// newInterArrival returns the interArrival of the given distribution for an
+// average of rate queries per second.
+func newInterArrival(distribution string, rate float64) (interArrival, error) {
+	mean := float64(time.Second) / rate
+	switch distribution {
+	case ArrivalFixed:
+		return func() time.Duration { return time.Duration(mean) }, nil
+	case ArrivalPoisson:
+		return func() time.Duration { return time.Duration(rand.ExpFloat64() * mean) }, nil
+	default:
+		return nil, fmt.Errorf(errUnknownArrivalFmt, distribution, ArrivalFixed, ArrivalPoisson)
+	}
+}
+
+// schedule hands the queries read from b.ch over to the open-loop workers at
+// their intended send times, regardless of how long the queries in flight
+// take. A query arriving when b.QueueSize queries already wait for a worker
+// is dropped and counted as overflow.
+func (b *BenchmarkRunner) schedule(queryPool *sync.Pool, next interArrival) {
+	intended := time.Now()
+	for q := range b.ch {
+		intended = intended.Add(next())
+		if wait := time.Until(intended); wait > 0 {
+			time.Sleep(wait)
+		}
+		select {
+		case b.scheduled <- scheduledQuery{query: q, intended: intended}:
+		default:
+			atomic.AddUint64(&b.overflow, 1)
+			queryPool.Put(q)
+		}
+	}
+	close(b.scheduled)
+}
@@ -0,0 +1,64 @@
+package query
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewInterArrival(t *testing.T) {
+	next, err := newInterArrival(ArrivalFixed, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := next(); got != 10*time.Millisecond {
+		t.Errorf("incorrect fixed inter-arrival: got %v want %v", got, 10*time.Millisecond)
+	}
+
+	next, err = newInterArrival(ArrivalPoisson, 100)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	const n = 10000
+	var sum time.Duration
+	for i := 0; i < n; i++ {
+		sum += next()
+	}
+	if mean := sum / n; math.Abs(float64(mean-10*time.Millisecond)) > float64(time.Millisecond) {
+		t.Errorf("incorrect poisson mean inter-arrival: got %v want ~%v", mean, 10*time.Millisecond)
+	}
+
+	if _, err = newInterArrival("bursty", 100); err == nil {
+		t.Errorf("unexpected lack of error for unknown distribution")
+	}
+}
+
+func TestScheduleOverflow(t *testing.T) {
+	const queries = 5
+	b := &BenchmarkRunner{
+		ch:        make(chan Query, queries),
+		scheduled: make(chan scheduledQuery, 2),
+	}
+	for i := 0; i < queries; i++ {
+		b.ch <- testQueryPool.Get().(*testQuery)
+	}
+	close(b.ch)
+
+	start := time.Now()
+	// no workers take the scheduled queries, so all but the first two overflow
+	b.schedule(&testQueryPool, func() time.Duration { return time.Millisecond })
+	if took := time.Since(start); took < queries*time.Millisecond {
+		t.Errorf("queries were not sent at their intended times: took %v", took)
+	}
+	if b.overflow != queries-2 {
+		t.Errorf("incorrect overflow: got %d want %d", b.overflow, queries-2)
+	}
+
+	prev := start
+	for sq := range b.scheduled {
+		if !sq.intended.After(prev) {
+			t.Errorf("intended send times are not increasing: %v after %v", sq.intended, prev)
+		}
+		prev = sq.intended
+	}
+}
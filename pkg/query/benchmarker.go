@@ -2,16 +2,26 @@ package query
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"runtime/pprof"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/internal/compress"
+	"github.com/timescale/tsbs/pkg/coordination"
+	"github.com/timescale/tsbs/pkg/metrics"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 	"golang.org/x/time/rate"
 )
 
@@ -23,6 +33,20 @@ const (
 	defaultReadSize = 4 << 20 // 4 MB
 )
 
+// Error policies, i.e. what to do when a query fails
+const (
+	// ErrorPolicyAbort stops the benchmark on the first failed query
+	ErrorPolicyAbort = "abort"
+	// ErrorPolicySkip records the failed query as an error and moves on
+	ErrorPolicySkip = "skip"
+	// ErrorPolicyRetry retries the failed query up to Retries times before
+	// recording it as an error
+	ErrorPolicyRetry = "retry"
+)
+
+// errQueryTimeout is the outcome of a query that did not complete within the timeout
+var errQueryTimeout = errors.New("query timed out")
+
 // BenchmarkRunnerConfig is the configuration of the benchmark runner.
 type BenchmarkRunnerConfig struct {
 	DBName           string `mapstructure:"db-name"`
@@ -38,6 +62,33 @@ type BenchmarkRunnerConfig struct {
 	PrintInterval    uint64 `mapstructure:"print-interval"`
 	PrewarmQueries   bool   `mapstructure:"prewarm-queries"`
 	ResultsFile      string `mapstructure:"results-file"`
+	RollupBaseline   string `mapstructure:"rollup-baseline"`
+	// ArrivalRate > 0 runs the benchmark open-loop: queries are sent at this
+	// rate (queries/sec) no matter how long the queries in flight take.
+	ArrivalRate         float64 `mapstructure:"arrival-rate"`
+	ArrivalDistribution string  `mapstructure:"arrival-distribution"`
+	QueueSize           uint    `mapstructure:"queue-size"`
+	// Timeout is the per-query timeout, 0 for none
+	Timeout     time.Duration `mapstructure:"timeout"`
+	ErrorPolicy string        `mapstructure:"error-policy"`
+	Retries     uint          `mapstructure:"retries"`
+	// ResultsDigestFile is where the digests of the query results are
+	// written, for tsbs_compare_results to check them against another run
+	ResultsDigestFile string `mapstructure:"results-digest"`
+	DigestPrecision   int    `mapstructure:"digest-precision"`
+	// Duration > 0 bounds the run by time instead of by the number of
+	// queries: the query file is looped over until the warm-up and the
+	// duration have passed. The stats of the warm-up are discarded.
+	Duration       time.Duration `mapstructure:"duration"`
+	WarmupDuration time.Duration `mapstructure:"warmup-duration"`
+	// TraceFile is where a record of every query execution is written, in
+	// TraceFormat
+	TraceFile   string `mapstructure:"trace-file"`
+	TraceFormat string `mapstructure:"trace-format"`
+	MetricsAddr string `mapstructure:"metrics-addr"`
+	// Coordinator is the address of a tsbs_coordinator to run a slice of the
+	// queries for, together with its other agents
+	Coordinator string `mapstructure:"coordinator"`
 }
 
 // AddToFlagSet adds command line flags needed by the BenchmarkRunnerConfig to the flag set.
@@ -55,6 +106,21 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Int("debug", 0, "Whether to print debug messages.")
 	fs.String("file", "", "File name to read queries from")
 	fs.String("results-file", "", "Write the test results summary json to this file")
+	fs.String("rollup-baseline", "", "Results json file of a run against the raw data. Used to report the speedup of queries reading from rollups")
+	fs.Float64("arrival-rate", 0, "Run open-loop: send queries at this rate (queries/sec) and measure their response time from the intended send time, 0 = closed-loop")
+	fs.String("arrival-distribution", ArrivalFixed, fmt.Sprintf("Distribution of the open-loop query arrivals (%s, %s)", ArrivalFixed, ArrivalPoisson))
+	fs.Uint("queue-size", 1000, "Max number of open-loop queries waiting for a worker, queries arriving when the queue is full are dropped and counted as overflow")
+	fs.Duration("timeout", 0, "Per-query timeout, timed out queries are recorded as timeouts. 0 = no timeout")
+	fs.String("error-policy", ErrorPolicyAbort, fmt.Sprintf("What to do when a query fails (%s, %s, %s). Failed queries are recorded as errors", ErrorPolicyAbort, ErrorPolicySkip, ErrorPolicyRetry))
+	fs.String("results-digest", "", "Write a canonical digest of the result of each query to this file, to check the results against another run with tsbs_compare_results")
+	fs.Int("digest-precision", defaultDigestPrecision, "Number of significant digits numbers are rounded to in the result digests")
+	fs.Duration("duration", 0, "Run queries for this long after the warm-up, looping over the query file as needed. 0 = until the queries run out")
+	fs.Duration("warmup-duration", 0, "Run queries for this long before collecting statistics")
+	fs.Uint("retries", 3, "Number of times a failed query is retried with error-policy="+ErrorPolicyRetry)
+	fs.String("trace-file", "", "Write a record of every query execution (ID, label, worker, start, latency, warm, result rows, error) to this file")
+	fs.String("trace-format", TraceFormatJSONL, fmt.Sprintf("Format of the trace file (%s, %s)", TraceFormatJSONL, TraceFormatCSV))
+	fs.String("metrics-addr", "", "Serve live metrics in the Prometheus format on /metrics at this address (e.g. :9101), '' = disabled")
+	fs.String("coordinator", "", "Address of a tsbs_coordinator to run a slice of the queries for, together with its other agents. '' = run all the queries alone")
 }
 
 // BenchmarkRunner contains the common components for running a query benchmarking
@@ -62,9 +128,22 @@ func (c BenchmarkRunnerConfig) AddToFlagSet(fs *pflag.FlagSet) {
 type BenchmarkRunner struct {
 	BenchmarkRunnerConfig
 	br      *bufio.Reader
+	file    *os.File // query file, if not reading from STDIN
 	sp      statProcessor
 	scanner *scanner
 	ch      chan Query
+
+	scheduled chan scheduledQuery // open-loop queries waiting for a worker
+	overflow  uint64              // open-loop queries dropped because the queue was full
+
+	digests *digestCollector // result digests, if ResultsDigestFile is set
+	tracer  *tracer          // per-query trace, if TraceFile is set
+	metrics *queryMetrics    // live metrics, if MetricsAddr is set
+	totals  map[string]interface{}
+
+	agent     *coordination.Agent // set when running as an agent of a coordinator
+	agentDone chan struct{}
+	completed uint64 // successfully run queries, reported as progress to the coordinator
 }
 
 // NewBenchmarkRunner creates a new instance of BenchmarkRunner which is
@@ -78,6 +157,7 @@ func NewBenchmarkRunner(config BenchmarkRunnerConfig) *BenchmarkRunner {
 		prewarmQueries:   runner.PrewarmQueries,
 		burnIn:           runner.BurnIn,
 		hdrLatenciesFile: runner.HDRLatenciesFile,
+		warmupDuration:   runner.WarmupDuration,
 	}
 
 	runner.sp = newStatProcessor(spArgs)
@@ -99,6 +179,12 @@ func (b *BenchmarkRunner) DebugLevel() int {
 	return b.Debug
 }
 
+// Totals returns the summary of the results of the last run, as saved in the
+// results file.
+func (b *BenchmarkRunner) Totals() map[string]interface{} {
+	return b.totals
+}
+
 // DatabaseName returns the name of the database to run queries against
 func (b *BenchmarkRunner) DatabaseName() string {
 	return b.DBName
@@ -116,24 +202,58 @@ type Processor interface {
 	ProcessQuery(q Query, isWarm bool) ([]*Stat, error)
 }
 
-// GetBufferedReader returns the buffered Reader that should be used by the loader
+// ContextProcessor is a Processor that cancels the query it is processing
+// when ctx is done, which is how the per-query timeout is enforced. The queries
+// of a Processor that is not a ContextProcessor run to completion, and are
+// recorded as timeouts if they took longer than the timeout.
+type ContextProcessor interface {
+	Processor
+
+	// ProcessQueryContext handles a given query within ctx and reports its stats
+	ProcessQueryContext(ctx context.Context, q Query, isWarm bool) ([]*Stat, error)
+}
+
+// GetBufferedReader returns the buffered Reader that should be used by the loader.
+// The queries are decompressed if they were compressed with one of the codecs
+// of package compress.
 func (b *BenchmarkRunner) GetBufferedReader() *bufio.Reader {
 	if b.br == nil {
+		var in io.Reader = os.Stdin
 		if len(b.FileName) > 0 {
 			// Read from specified file
 			file, err := os.Open(b.FileName)
 			if err != nil {
 				panic(fmt.Sprintf("cannot open file for read %s: %v", b.FileName, err))
 			}
-			b.br = bufio.NewReaderSize(file, defaultReadSize)
-		} else {
-			// Read from STDIN
-			b.br = bufio.NewReaderSize(os.Stdin, defaultReadSize)
+			b.file = file
+			in = file
 		}
+		r, err := compress.NewReader(in)
+		if err != nil {
+			panic(fmt.Sprintf("cannot decompress %s: %v", b.FileName, err))
+		}
+		b.br = bufio.NewReaderSize(r, defaultReadSize)
 	}
 	return b.br
 }
 
+// rewindQueryFile returns the reader of the query file positioned at its
+// first query again, for the scanner to loop over it.
+func (b *BenchmarkRunner) rewindQueryFile() (io.Reader, error) {
+	if _, err := b.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	r, err := compress.NewReader(b.file)
+	if err != nil {
+		return nil, err
+	}
+	b.br.Reset(r)
+	if _, err := ReadHeader(b.br); err != nil {
+		return nil, err
+	}
+	return b.br, nil
+}
+
 // Run does the bulk of the benchmark execution.
 // It launches a gorountine to track stats, creates workers to process queries,
 // read in the input, execute the queries, and then does cleanup.
@@ -146,29 +266,116 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 	if spArgs.burnIn > b.Limit {
 		panic("burn-in is larger than limit")
 	}
+	if b.Duration > 0 && len(b.FileName) == 0 {
+		panic("duration needs a query file to loop over, STDIN cannot be read twice")
+	}
 	b.ch = make(chan Query, b.Workers)
 
-	// Launch the stats processor:
-	go b.sp.process(b.Workers)
+	// (Optional) read the header describing how the queries were generated:
+	br := b.GetBufferedReader()
+	header, headerErr := ReadHeader(br)
+	if headerErr != nil {
+		log.Fatal(headerErr)
+	}
+	if header != nil && len(header.Mix) > 0 {
+		printQueryMix(header)
+	}
+
+	switch b.ErrorPolicy {
+	case ErrorPolicyAbort, ErrorPolicySkip, ErrorPolicyRetry:
+	case "":
+		b.ErrorPolicy = ErrorPolicyAbort
+	default:
+		panic(fmt.Sprintf("unknown error policy '%s'", b.ErrorPolicy))
+	}
+
+	var next interArrival
+	if b.ArrivalRate > 0 {
+		if b.LimitRPS > 0 {
+			panic("max-rps cannot be used with an open-loop arrival-rate")
+		}
+		var err error
+		next, err = newInterArrival(b.ArrivalDistribution, b.ArrivalRate)
+		if err != nil {
+			panic(err.Error())
+		}
+	}
+
+	if b.DigestResults() {
+		b.digests = newDigestCollector(b.DigestPrecision)
+	}
+	if len(b.TraceFile) > 0 {
+		var err error
+		b.tracer, err = newFileTracer(b.TraceFile, b.TraceFormat)
+		if err != nil {
+			panic(fmt.Sprintf("cannot create trace file %s: %v", b.TraceFile, err))
+		}
+		b.tracer.loopOf = b.scanner.loop
+	}
+
+	if len(b.MetricsAddr) > 0 {
+		b.metrics = newQueryMetrics()
+		stopMetrics, err := metrics.Serve(b.MetricsAddr, b.metrics.registry)
+		if err != nil {
+			panic(err.Error())
+		}
+		defer stopMetrics()
+	}
+
+	if len(b.Coordinator) > 0 {
+		b.joinCoordinator()
+	}
 
 	rateLimiter := getRateLimiter(b.LimitRPS, b.Workers)
 
 	// Launch query processors
 	var wg sync.WaitGroup
-	for i := 0; i < int(b.Workers); i++ {
-		wg.Add(1)
-		go b.processorHandler(&wg, rateLimiter, queryPool, processorCreateFn(), i)
+	if next != nil {
+		b.scheduled = make(chan scheduledQuery, b.QueueSize)
+		for i := 0; i < int(b.Workers); i++ {
+			wg.Add(1)
+			go b.openLoopHandler(&wg, queryPool, processorCreateFn(), i)
+		}
+	} else {
+		for i := 0; i < int(b.Workers); i++ {
+			wg.Add(1)
+			go b.processorHandler(&wg, rateLimiter, queryPool, processorCreateFn(), i)
+		}
+	}
+
+	if b.agent != nil {
+		// start together with the other agents
+		if err := b.agent.WaitForStart(); err != nil {
+			panic(err.Error())
+		}
+		go b.reportProgress(time.Second)
+	}
+
+	// Launch the stats processor, and the scheduler of an open-loop run, once
+	// the run starts so that the wait for the other agents is not part of the
+	// warm-up, the query rates or the response times:
+	b.sp.start(b.Workers)
+	if next != nil {
+		go b.schedule(queryPool, next)
 	}
 
 	// Read in jobs, closing the job channel when done:
 	// Wall clock start time
 	wallStart := time.Now()
-	b.scanner.setReader(b.GetBufferedReader()).scan(queryPool, b.ch)
+	if b.Duration > 0 {
+		b.scanner.setDeadline(wallStart.Add(b.WarmupDuration+b.Duration), b.rewindQueryFile)
+	}
+	b.scanner.setReader(br).scan(queryPool, b.ch)
 	close(b.ch)
 
 	// Block for workers to finish sending requests, closing the stats channel when done:
 	wg.Wait()
 	b.sp.CloseAndWait()
+	if b.tracer != nil {
+		if err := b.tracer.close(); err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	// Wall clock end time
 	wallEnd := time.Now()
@@ -188,20 +395,130 @@ func (b *BenchmarkRunner) Run(queryPool *sync.Pool, processorCreateFn ProcessorC
 		f.Close()
 	}
 
+	totals := b.sp.GetTotalsMap()
+	if next != nil {
+		fmt.Printf("open-loop %s arrivals at %0.2f queries/sec, %d queries dropped (queue full)\n",
+			b.ArrivalDistribution, b.ArrivalRate, b.overflow)
+		totals["openLoopOverflow"] = b.overflow
+	}
+	if header != nil {
+		totals["queryFileHeader"] = header
+	}
+
+	// (Optional) compare rollup queries with the raw data queries of a previous run:
+	if len(b.RollupBaseline) > 0 {
+		speedups := b.reportRollupSpeedups(totals)
+		totals["rollupSpeedups"] = speedups
+	}
+
+	// (Optional) save the result digests:
+	if b.digests != nil {
+		b.saveResultDigests(header)
+	}
+
+	b.totals = totals
+	if b.agent != nil {
+		b.reportResult(wallStart, wallEnd, totals)
+	}
+
 	// (Optional) save the results file:
 	if len(b.BenchmarkRunnerConfig.ResultsFile) > 0 {
-		b.saveTestResult(wallTook, wallStart, wallEnd)
+		b.saveTestResult(wallTook, wallStart, wallEnd, totals)
 	}
 }
 
-func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time) {
+// printQueryMix prints the weighted query mix the query file was generated with.
+func printQueryMix(h *Header) {
+	total := h.TotalWeight()
+	fmt.Printf("query mix (use case %s, seed %d):\n", h.Use, h.Seed)
+	for _, e := range h.Mix {
+		share := 0.0
+		if total > 0 {
+			share = 100 * float64(e.Weight) / float64(total)
+		}
+		fmt.Printf("  %s: weight %d (%0.1f%%)", e.QueryType, e.Weight, share)
+		if len(e.Params) > 0 {
+			keys := make([]string, 0, len(e.Params))
+			for k := range e.Params {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Printf(" %s=%s", k, e.Params[k])
+			}
+		}
+		fmt.Println()
+	}
+}
+
+// reportRollupSpeedups prints, for every query type read from a rollup, how
+// much faster its median latency is than the median of the same query type
+// in the RollupBaseline results file.
+func (b *BenchmarkRunner) reportRollupSpeedups(totals map[string]interface{}) map[string]float64 {
+	file, err := ioutil.ReadFile(b.RollupBaseline)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var baseline LoaderTestResult
+	if err = json.Unmarshal(file, &baseline); err != nil {
+		log.Fatalf("could not parse rollup baseline %s: %v", b.RollupBaseline, err)
+	}
+
+	speedups := rollupSpeedups(baseline.Totals, totals)
+	labels := make([]string, 0, len(speedups))
+	for label := range speedups {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fmt.Printf("rollup speedup %s: %0.2fx\n", label, speedups[label])
+	}
+	return speedups
+}
+
+// rollupSpeedups returns the ratio of the baseline median latency to the
+// rollup median latency, keyed by the (stripped) label of the rollup query.
+// Query types missing from the baseline are skipped.
+func rollupSpeedups(baseline, current map[string]interface{}) map[string]float64 {
+	speedups := make(map[string]float64)
+	baseQuantiles, _ := baseline["overallQuantiles"].(map[string]interface{})
+	currQuantiles, _ := current["overallQuantiles"].(map[string]interface{})
+	suffix := stripRegex(constants.RollupLabelSuffix)
+	for label, q := range currQuantiles {
+		if !strings.HasSuffix(label, suffix) {
+			continue
+		}
+		rollupMedian := medianOf(q)
+		baseMedian := medianOf(baseQuantiles[strings.TrimSuffix(label, suffix)])
+		if rollupMedian <= 0 || baseMedian <= 0 {
+			continue
+		}
+		speedups[label] = baseMedian / rollupMedian
+	}
+	return speedups
+}
+
+// medianOf returns the q50 entry of a quantile map, either as generated by
+// generateQuantileMap or as read back from a results file.
+func medianOf(quantiles interface{}) float64 {
+	switch q := quantiles.(type) {
+	case map[string]float64:
+		return q["q50"]
+	case map[string]interface{}:
+		median, _ := q["q50"].(float64)
+		return median
+	}
+	return 0
+}
+
+func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, end time.Time, totals map[string]interface{}) {
 	testResult := LoaderTestResult{
 		ResultFormatVersion: BenchmarkTestResultVersion,
 		RunnerConfig:        b.BenchmarkRunnerConfig,
 		StartTime:           start.UTC().Unix() * 1000,
 		EndTime:             end.UTC().Unix() * 1000,
 		DurationMillis:      took.Milliseconds(),
-		Totals:              b.sp.GetTotalsMap(),
+		Totals:              totals,
 	}
 
 	_, _ = fmt.Printf("Saving results json file to %s\n", b.BenchmarkRunnerConfig.ResultsFile)
@@ -218,31 +535,145 @@ func (b *BenchmarkRunner) saveTestResult(took time.Duration, start time.Time, en
 
 func (b *BenchmarkRunner) processorHandler(wg *sync.WaitGroup, rateLimiter *rate.Limiter, queryPool *sync.Pool, processor Processor, workerNum int) {
 	processor.Init(workerNum)
+	b.metrics.workerStarted()
 	for query := range b.ch {
 		r := rateLimiter.Reserve()
 		time.Sleep(r.Delay())
 
-		stats, err := processor.ProcessQuery(query, false)
-		if err != nil {
-			panic(err)
+		b.processQuery(processor, query, workerNum)
+		queryPool.Put(query)
+	}
+	b.metrics.workerDone()
+	wg.Done()
+}
+
+// openLoopHandler processes the scheduled queries of an open-loop run. On top
+// of the service time reported by the processor, it reports the response time
+// of each query: the time from its intended send time to its completion, which
+// includes the time it waited for a worker.
+func (b *BenchmarkRunner) openLoopHandler(wg *sync.WaitGroup, queryPool *sync.Pool, processor Processor, workerNum int) {
+	processor.Init(workerNum)
+	b.metrics.workerStarted()
+	for sq := range b.scheduled {
+		responseTime, ok := b.processQuery(processor, sq.query, workerNum)
+		if ok {
+			took := float64(responseTime.Sub(sq.intended).Nanoseconds()) / 1e6
+			b.sp.send([]*Stat{getResponseStat().Init(sq.query.HumanLabelName(), took)})
 		}
-		b.sp.send(stats)
-
-		// If PrewarmQueries is set, we run the query as 'cold' first (see above),
-		// then we immediately run it a second time and report that as the 'warm' stat.
-		// This guarantees that the warm stat will reflect optimal cache performance.
-		spArgs := b.sp.getArgs()
-		if spArgs.prewarmQueries {
-			// Warm run
-			stats, err = processor.ProcessQuery(query, true)
-			if err != nil {
-				panic(err)
-			}
+		queryPool.Put(sq.query)
+	}
+	b.metrics.workerDone()
+	wg.Done()
+}
+
+// processQuery runs query on worker workerNum and sends its stats, returning
+// when the (cold) run completed and whether it succeeded.
+func (b *BenchmarkRunner) processQuery(processor Processor, query Query, workerNum int) (time.Time, bool) {
+	stats, err := b.runQuery(processor, query, false, workerNum)
+	done := time.Now()
+	if err != nil {
+		b.sendFailure(query, err, false)
+		return done, false
+	}
+	b.sp.send(stats)
+
+	// If PrewarmQueries is set, we run the query as 'cold' first (see above),
+	// then we immediately run it a second time and report that as the 'warm' stat.
+	// This guarantees that the warm stat will reflect optimal cache performance.
+	spArgs := b.sp.getArgs()
+	if spArgs.prewarmQueries {
+		// Warm run
+		stats, err = b.runQuery(processor, query, true, workerNum)
+		if err != nil {
+			b.sendFailure(query, err, true)
+		} else {
 			b.sp.sendWarm(stats)
 		}
-		queryPool.Put(query)
 	}
-	wg.Done()
+	return done, true
+}
+
+// runQuery runs query, retrying it if it fails and the error policy allows
+// it. Timed out queries are not retried.
+func (b *BenchmarkRunner) runQuery(processor Processor, query Query, isWarm bool, workerNum int) ([]*Stat, error) {
+	attempts := uint(1)
+	if b.ErrorPolicy == ErrorPolicyRetry {
+		attempts += b.Retries
+	}
+	var err error
+	for i := uint(0); i < attempts; i++ {
+		var stats []*Stat
+		start := time.Now()
+		stats, err = b.runQueryWithTimeout(processor, query, isWarm)
+		// the latency of a successful run is the one measured by the
+		// processor, the wall time also includes the handling of the results
+		latency := time.Since(start)
+		if err == nil {
+			latency = statsLatency(stats, latency)
+		}
+		if b.tracer != nil {
+			b.tracer.trace(query, workerNum, start, latency, isWarm, err)
+		}
+		if err == nil {
+			b.metrics.queryDone(query, latency)
+			atomic.AddUint64(&b.completed, 1)
+		}
+		if err == nil || err == errQueryTimeout {
+			return stats, err
+		}
+		if b.Debug > 0 {
+			fmt.Fprintf(os.Stderr, "query %d (%s) failed, attempt %d/%d: %v\n", query.GetID(), query.HumanLabelName(), i+1, attempts, err)
+		}
+	}
+	return nil, err
+}
+
+// statsLatency returns the latency of the query reported by stats, i.e. the
+// value of its stat for the whole query, or took if there is none.
+func statsLatency(stats []*Stat, took time.Duration) time.Duration {
+	for _, s := range stats {
+		if !s.isPartial && !s.isResponse {
+			return time.Duration(s.value * float64(time.Millisecond))
+		}
+	}
+	return took
+}
+
+// runQueryWithTimeout runs query once, returning errQueryTimeout if it did not
+// complete within the timeout.
+func (b *BenchmarkRunner) runQueryWithTimeout(processor Processor, query Query, isWarm bool) ([]*Stat, error) {
+	if b.Timeout <= 0 {
+		return processor.ProcessQuery(query, isWarm)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), b.Timeout)
+	defer cancel()
+
+	var stats []*Stat
+	var err error
+	if cp, ok := processor.(ContextProcessor); ok {
+		stats, err = cp.ProcessQueryContext(ctx, query, isWarm)
+	} else {
+		stats, err = processor.ProcessQuery(query, isWarm)
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		for _, s := range stats {
+			statPool.Put(s)
+		}
+		return nil, errQueryTimeout
+	}
+	return stats, err
+}
+
+// sendFailure records a failed query as an error or a timeout, unless the
+// error policy is to abort on errors.
+func (b *BenchmarkRunner) sendFailure(query Query, err error, isWarm bool) {
+	b.metrics.queryFailed(query, err == errQueryTimeout)
+	if err != errQueryTimeout && b.ErrorPolicy == ErrorPolicyAbort {
+		panic(err)
+	}
+	s := getFailureStat(err == errQueryTimeout).Init(query.HumanLabelName(), 0)
+	s.isWarm = isWarm
+	b.sp.send([]*Stat{s})
 }
 
 func getRateLimiter(limitRPS uint64, workers uint) *rate.Limiter {
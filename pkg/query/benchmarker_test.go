@@ -1,7 +1,10 @@
 package query
 
 import (
+	"github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/timescale/tsbs/internal/compress"
 	"golang.org/x/time/rate"
+	"io"
 	"io/ioutil"
 	"math"
 	"os"
@@ -157,6 +160,42 @@ func TestBenchmarkRunnerGetBufferedReaderCached(t *testing.T) {
 	b.GetBufferedReader()
 }
 
+func TestBenchmarkRunnerGetBufferedReaderCompressed(t *testing.T) {
+	queryFile, err := ioutil.TempFile("", "queries_*.lz4")
+	if err != nil {
+		t.Fatalf("Could not create temp file: %v", err)
+	}
+	defer os.Remove(queryFile.Name())
+	want := "some queries"
+	w, err := compress.NewWriter(queryFile, compress.LZ4)
+	if err != nil {
+		t.Fatalf("Could not compress: %v", err)
+	}
+	io.WriteString(w, want)
+	w.Close()
+	queryFile.Close()
+
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			FileName: queryFile.Name(),
+		},
+	}
+	got, err := ioutil.ReadAll(b.GetBufferedReader())
+	if err != nil || string(got) != want {
+		t.Errorf("incorrect decompressed queries: got %q (%v) want %q", got, err, want)
+	}
+
+	// the file is decompressed again from the start when looping over it
+	r, err := b.rewindQueryFile()
+	if err != nil {
+		t.Fatalf("Could not rewind: %v", err)
+	}
+	got, err = ioutil.ReadAll(r)
+	if err != nil || string(got) != want {
+		t.Errorf("incorrect decompressed queries after a rewind: got %q (%v) want %q", got, err, want)
+	}
+}
+
 func TestBenchmarkRunnerRunPanicOnNoWorkers(t *testing.T) {
 	runner := &BenchmarkRunner{}
 	defer func() {
@@ -314,7 +353,7 @@ func (m *mockStatProcessor) sendWarm(stats []*Stat) {
 		m.onSend(stats)
 	}
 }
-func (m *mockStatProcessor) process(workers uint) {
+func (m *mockStatProcessor) start(workers uint) {
 	if m.onProcess != nil {
 		m.onProcess(workers)
 	}
@@ -327,6 +366,9 @@ func (m *mockStatProcessor) GetTotalsMap() map[string]interface{} {
 	totals := make(map[string]interface{})
 	return totals
 }
+func (m *mockStatProcessor) getHistograms() map[string]*hdrhistogram.Histogram {
+	return nil
+}
 
 type mockProcessor struct {
 	processRes []*Stat
@@ -362,3 +404,28 @@ func TestGetRateLimiter(t *testing.T) {
 		})
 	}
 }
+
+func TestRollupSpeedups(t *testing.T) {
+	// baseline as read back from a results json file
+	baseline := map[string]interface{}{
+		"overallQuantiles": map[string]interface{}{
+			"all_queries":    map[string]interface{}{"q50": 100.0},
+			"double_groupby": map[string]interface{}{"q50": 100.0},
+			"lastpoint":      map[string]interface{}{"q50": 10.0},
+		},
+	}
+	current := map[string]interface{}{
+		"overallQuantiles": map[string]interface{}{
+			"all_queries":            map[string]float64{"q50": 20.0},
+			"double_groupby_rollup_": map[string]float64{"q50": 20.0},
+			"lastpoint_rollup_":      map[string]float64{"q50": 0.0},
+			"high_cpu_rollup_":       map[string]float64{"q50": 5.0},
+		},
+	}
+
+	got := rollupSpeedups(baseline, current)
+	want := map[string]float64{"double_groupby_rollup_": 5.0}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect speedups: got %v want %v", got, want)
+	}
+}
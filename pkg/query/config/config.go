@@ -2,13 +2,19 @@ package config
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/pflag"
 	"github.com/timescale/tsbs/internal/utils"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 )
 
-const ErrEmptyQueryType = "query type cannot be empty"
+const (
+	ErrEmptyQueryType        = "query type cannot be empty"
+	ErrQueryTypeAndQueryMix  = "only one of query type, query mix and query template can be used"
+	ErrRecentMeanNotPositive = "time window recent mean must be > 0"
+	ErrChunkNotPositive      = "time window chunk must be > 0"
+)
 
 // QueryGeneratorConfig is the GeneratorConfig that should be used with a
 // QueryGenerator. It includes all the fields from a BaseConfig, as well as
@@ -18,6 +24,8 @@ type QueryGeneratorConfig struct {
 	common.BaseConfig
 	Limit                uint64 `mapstructure:"queries"`
 	QueryType            string `mapstructure:"query-type"`
+	QueryMixFile         string `mapstructure:"query-mix"`
+	QueryTemplateFile    string `mapstructure:"query-template"`
 	InterleavedGroupID   uint   `mapstructure:"interleaved-generation-group-id"`
 	InterleavedNumGroups uint   `mapstructure:"interleaved-generation-groups"`
 
@@ -30,6 +38,14 @@ type QueryGeneratorConfig struct {
 
 	MongoUseNaive bool   `mapstructure:"mongo-use-naive"`
 	DbName        string `mapstructure:"db-name"`
+
+	UseRollups bool `mapstructure:"use-rollups"`
+
+	TimeWindowDistribution string        `mapstructure:"time-window-distribution"`
+	TimeWindowRecentMean   time.Duration `mapstructure:"time-window-recent-mean"`
+	TimeWindowChunk        time.Duration `mapstructure:"time-window-chunk"`
+	HostDistribution       string        `mapstructure:"host-distribution"`
+	ZipfExponent           float64       `mapstructure:"zipf-exponent"`
 }
 
 // Validate checks that the values of the QueryGeneratorConfig are reasonable.
@@ -39,9 +55,25 @@ func (c *QueryGeneratorConfig) Validate() error {
 		return err
 	}
 
-	if c.QueryType == "" {
+	set := 0
+	for _, s := range []string{c.QueryType, c.QueryMixFile, c.QueryTemplateFile} {
+		if s != "" {
+			set++
+		}
+	}
+	if set == 0 {
 		return fmt.Errorf(ErrEmptyQueryType)
 	}
+	if set > 1 {
+		return fmt.Errorf(ErrQueryTypeAndQueryMix)
+	}
+
+	switch {
+	case c.TimeWindowDistribution == utils.WindowDistExponentialRecent && c.TimeWindowRecentMean <= 0:
+		return fmt.Errorf(ErrRecentMeanNotPositive)
+	case c.TimeWindowDistribution == utils.WindowDistZipfChunks && c.TimeWindowChunk <= 0:
+		return fmt.Errorf(ErrChunkNotPositive)
+	}
 
 	err = utils.ValidateGroups(c.InterleavedGroupID, c.InterleavedNumGroups)
 	return err
@@ -51,6 +83,8 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	c.BaseConfig.AddToFlagSet(fs)
 	fs.Uint64("queries", 1000, "Number of queries to generate.")
 	fs.String("query-type", "", "Query type. (Choices are in the use case matrix.)")
+	fs.String("query-mix", "", "YAML file with a weighted mix of query types to generate instead of a single --query-type.")
+	fs.String("query-template", "", "YAML file with per-format query templates to generate instead of a single --query-type.")
 
 	fs.Uint("interleaved-generation-group-id", 0,
 		"Group (0-indexed) to perform round-robin serialization within. Use this to scale up data generation to multiple processes.")
@@ -64,4 +98,16 @@ func (c *QueryGeneratorConfig) AddToFlagSet(fs *pflag.FlagSet) {
 	fs.Bool("timescale-use-time-bucket", true, "TimescaleDB only: Use time bucket. Set to false to test on native PostgreSQL")
 
 	fs.String("db-name", "benchmark", "Specify database name. Timestream requires it in order to generate the queries")
+
+	fs.String("time-window-distribution", utils.WindowDistUniform,
+		fmt.Sprintf("How the time windows of queries are picked. Valid values: %v", utils.WindowDistributionChoices()))
+	fs.Duration("time-window-recent-mean", 6*time.Hour,
+		"exponential-recent only: Mean distance of a time window from the end of the dataset")
+	fs.Duration("time-window-chunk", 12*time.Hour,
+		"zipf-chunks only: Size of the chunks the dataset is split into, the most recent one being the hottest")
+	fs.String("host-distribution", "uniform",
+		"How the hosts (or trucks) of queries are picked. Valid values: uniform, zipf (host_0 the hottest)")
+	fs.Float64("zipf-exponent", 1.1, "Exponent of the zipf time window and host distributions. Higher is more skewed")
+
+	fs.Bool("use-rollups", false, "Rewrite the devops double-groupby queries to read from the hourly rollups built by the loader's --do-create-rollups (timescaledb, clickhouse, influx, mongo only)")
 }
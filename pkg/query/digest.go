@@ -0,0 +1,291 @@
+package query
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultDigestPrecision is the number of significant digits numbers are
+// rounded to before being digested.
+const defaultDigestPrecision = 6
+
+// timestampLayouts are the layouts a string is tried against to tell whether
+// it is a timestamp. Timestamps without a zone are taken to be UTC.
+var timestampLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999 -0700 MST",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// ResultDigest is the canonical digest of the result rows of one query.
+type ResultDigest struct {
+	ID     uint64 `json:"id"`
+	Label  string `json:"label"`
+	Rows   int    `json:"rows"`
+	Digest string `json:"digest"`
+}
+
+// ResultDigests are the result digests of a run, written to the file set
+// with --results-digest and compared by tsbs_compare_results.
+type ResultDigests struct {
+	QueryFileHeader *Header        `json:"queryFileHeader,omitempty"`
+	Precision       int            `json:"precision"`
+	Results         []ResultDigest `json:"results"`
+}
+
+// digestCollector collects the result digests of the queries run by all workers.
+type digestCollector struct {
+	mu        sync.Mutex
+	precision int
+	digests   map[uint64]ResultDigest
+}
+
+func newDigestCollector(precision int) *digestCollector {
+	if precision <= 0 {
+		precision = defaultDigestPrecision
+	}
+	return &digestCollector{precision: precision, digests: map[uint64]ResultDigest{}}
+}
+
+// DigestResults returns whether the digests of the query results are saved.
+// Targets that cannot record their result rows reject the configuration.
+func (b *BenchmarkRunner) DigestResults() bool {
+	return len(b.ResultsDigestFile) > 0
+}
+
+// RecordResults returns whether the processors should record the result rows
+// of their queries with RecordResult, for the result digests.
+func (b *BenchmarkRunner) RecordResults() bool {
+	return b.DigestResults()
+}
+
+// CountResults returns whether the processors should report the number of
+// result rows of their queries, for the per-query trace. Processors that do
+// not record the rows count them as they fetch them, without keeping them,
+// and report the count with RecordResultCount once the query is timed.
+func (b *BenchmarkRunner) CountResults() bool {
+	return len(b.TraceFile) > 0
+}
+
+// RecordResultCount records the number of result rows of q in the trace.
+func (b *BenchmarkRunner) RecordResultCount(q Query, rows int) {
+	if b.tracer != nil {
+		b.tracer.setRows(q, rows)
+	}
+}
+
+// RecordResult records the result rows of q: their number in the trace and
+// their digest. Each row holds the values of one result row in column order;
+// column names are left out as they differ between databases. If a query is
+// run more than once, e.g. with --prewarm-queries, the result of its last run
+// is kept.
+func (b *BenchmarkRunner) RecordResult(q Query, rows [][]interface{}) {
+	b.RecordResultCount(q, len(rows))
+	if b.digests == nil {
+		return
+	}
+	d := ResultDigest{
+		ID:     q.GetID(),
+		Label:  string(q.HumanLabelName()),
+		Rows:   len(rows),
+		Digest: DigestRows(rows, b.digests.precision),
+	}
+	b.digests.mu.Lock()
+	b.digests.digests[d.ID] = d
+	b.digests.mu.Unlock()
+}
+
+// saveResultDigests writes the collected result digests, sorted by query ID,
+// to the results digest file.
+func (b *BenchmarkRunner) saveResultDigests(header *Header) {
+	out := ResultDigests{
+		QueryFileHeader: header,
+		Precision:       b.digests.precision,
+		Results:         make([]ResultDigest, 0, len(b.digests.digests)),
+	}
+	for _, d := range b.digests.digests {
+		out.Results = append(out.Results, d)
+	}
+	sort.Slice(out.Results, func(i, j int) bool { return out.Results[i].ID < out.Results[j].ID })
+
+	encoded, err := json.MarshalIndent(out, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Saving result digests of %d queries to %s\n", len(out.Results), b.ResultsDigestFile)
+	if err = ioutil.WriteFile(b.ResultsDigestFile, encoded, 0644); err != nil {
+		panic(err)
+	}
+}
+
+// ReadResultDigests reads the result digests saved by a previous run.
+func ReadResultDigests(fileName string) (*ResultDigests, error) {
+	encoded, err := ioutil.ReadFile(fileName)
+	if err != nil {
+		return nil, err
+	}
+	var digests ResultDigests
+	if err = json.Unmarshal(encoded, &digests); err != nil {
+		return nil, fmt.Errorf("could not parse result digests %s: %v", fileName, err)
+	}
+	return &digests, nil
+}
+
+// DigestMismatch is a query whose result differs between two runs.
+type DigestMismatch struct {
+	Reference ResultDigest
+	Result    ResultDigest
+}
+
+// DigestComparison is the outcome of comparing the result digests of a run
+// with those of a reference run.
+type DigestComparison struct {
+	Matched    int
+	Mismatched []DigestMismatch
+	Missing    []ResultDigest // queries of the reference without a result
+	Extra      []ResultDigest // results of queries not in the reference
+}
+
+// OK returns whether the results of all queries of both runs match.
+func (c *DigestComparison) OK() bool {
+	return len(c.Mismatched) == 0 && len(c.Missing) == 0 && len(c.Extra) == 0
+}
+
+// CompareResultDigests compares the result digests of a run with those of a
+// reference run, query by query. Both runs must have used the same query file
+// (or query files generated with the same parameters) for the query IDs to
+// refer to the same queries.
+func CompareResultDigests(reference, results *ResultDigests) *DigestComparison {
+	byID := make(map[uint64]ResultDigest, len(results.Results))
+	for _, d := range results.Results {
+		byID[d.ID] = d
+	}
+	c := &DigestComparison{}
+	for _, ref := range reference.Results {
+		d, ok := byID[ref.ID]
+		if !ok {
+			c.Missing = append(c.Missing, ref)
+			continue
+		}
+		delete(byID, ref.ID)
+		if d.Digest == ref.Digest {
+			c.Matched++
+		} else {
+			c.Mismatched = append(c.Mismatched, DigestMismatch{Reference: ref, Result: d})
+		}
+	}
+	for _, d := range results.Results {
+		if _, ok := byID[d.ID]; ok {
+			c.Extra = append(c.Extra, d)
+		}
+	}
+	return c
+}
+
+// DigestRows returns the digest of the canonical form of rows: every value is
+// normalized (see NormalizeValue) and the rows are sorted, so databases that
+// return the same result in a different order or with a different number
+// representation get the same digest.
+func DigestRows(rows [][]interface{}, precision int) string {
+	lines := NormalizeRows(rows, precision)
+	h := sha256.New()
+	for _, l := range lines {
+		h.Write([]byte(l))
+		h.Write([]byte{'\n'})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// NormalizeRows returns the canonical form of each row, sorted.
+func NormalizeRows(rows [][]interface{}, precision int) []string {
+	lines := make([]string, 0, len(rows))
+	values := make([]string, 0)
+	for _, row := range rows {
+		values = values[:0]
+		for _, v := range row {
+			values = append(values, NormalizeValue(v, precision))
+		}
+		lines = append(lines, strings.Join(values, "\x1f"))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+// NormalizeValue returns the canonical form of v: numbers, including numeric
+// strings, are rounded to precision significant digits, timestamps, including
+// timestamp strings, are written in UTC as RFC 3339, and everything else is
+// written with its default format.
+func NormalizeValue(v interface{}, precision int) string {
+	switch x := v.(type) {
+	case nil:
+		return "null"
+	case []byte:
+		return NormalizeValue(string(x), precision)
+	case string:
+		if f, err := strconv.ParseFloat(x, 64); err == nil {
+			return normalizeFloat(f, precision)
+		}
+		for _, layout := range timestampLayouts {
+			if t, err := time.Parse(layout, x); err == nil {
+				return normalizeTime(t)
+			}
+		}
+		return x
+	case time.Time:
+		return normalizeTime(x)
+	case float64:
+		return normalizeFloat(x, precision)
+	case float32:
+		return normalizeFloat(float64(x), precision)
+	case int:
+		return normalizeFloat(float64(x), precision)
+	case int8:
+		return normalizeFloat(float64(x), precision)
+	case int16:
+		return normalizeFloat(float64(x), precision)
+	case int32:
+		return normalizeFloat(float64(x), precision)
+	case int64:
+		return normalizeFloat(float64(x), precision)
+	case uint:
+		return normalizeFloat(float64(x), precision)
+	case uint8:
+		return normalizeFloat(float64(x), precision)
+	case uint16:
+		return normalizeFloat(float64(x), precision)
+	case uint32:
+		return normalizeFloat(float64(x), precision)
+	case uint64:
+		return normalizeFloat(float64(x), precision)
+	case json.Number:
+		return NormalizeValue(string(x), precision)
+	default:
+		return fmt.Sprintf("%v", x)
+	}
+}
+
+func normalizeFloat(f float64, precision int) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if f == 0 {
+		// no negative zero
+		return "0"
+	}
+	return strconv.FormatFloat(f, 'g', precision, 64)
+}
+
+func normalizeTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
@@ -0,0 +1,135 @@
+package query
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestNormalizeValue(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600))
+	cases := []struct {
+		desc string
+		in   interface{}
+		want string
+	}{
+		{desc: "nil", in: nil, want: "null"},
+		{desc: "float", in: 12.3456789, want: "12.3457"},
+		{desc: "float32", in: float32(0.5), want: "0.5"},
+		{desc: "int", in: int64(42), want: "42"},
+		{desc: "negative zero", in: -0.0, want: "0"},
+		{desc: "numeric string", in: "42.0", want: "42"},
+		{desc: "numeric bytes", in: []byte("12.3456789"), want: "12.3457"},
+		{desc: "time", in: ts, want: "2016-01-01T00:00:00Z"},
+		{desc: "RFC 3339 string", in: "2016-01-01T01:00:00+01:00", want: "2016-01-01T00:00:00Z"},
+		{desc: "SQL timestamp string", in: "2016-01-01 00:00:00.000000000", want: "2016-01-01T00:00:00Z"},
+		{desc: "string", in: "host_1", want: "host_1"},
+		{desc: "bool", in: true, want: "true"},
+	}
+	for _, c := range cases {
+		if got := NormalizeValue(c.in, defaultDigestPrecision); got != c.want {
+			t.Errorf("%s: incorrect normalized value: got %s want %s", c.desc, got, c.want)
+		}
+	}
+}
+
+func TestDigestRows(t *testing.T) {
+	ts := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	a := [][]interface{}{
+		{ts, "host_1", 12.3456789},
+		{ts.Add(time.Minute), "host_1", int64(42)},
+	}
+	// same result in a different order and representation
+	b := [][]interface{}{
+		{"2016-01-01 00:01:00", []byte("host_1"), "42"},
+		{"2016-01-01T00:00:00Z", "host_1", 12.34568},
+	}
+	if DigestRows(a, defaultDigestPrecision) != DigestRows(b, defaultDigestPrecision) {
+		t.Errorf("digests of equivalent results differ:\n%v\n%v", NormalizeRows(a, defaultDigestPrecision), NormalizeRows(b, defaultDigestPrecision))
+	}
+
+	c := [][]interface{}{a[0]}
+	if DigestRows(a, defaultDigestPrecision) == DigestRows(c, defaultDigestPrecision) {
+		t.Errorf("digests of different results are equal")
+	}
+	if DigestRows(nil, defaultDigestPrecision) == DigestRows([][]interface{}{{}}, defaultDigestPrecision) {
+		t.Errorf("digest of no rows equals digest of an empty row")
+	}
+}
+
+func TestRecordAndSaveResultDigests(t *testing.T) {
+	f, err := ioutil.TempFile("", "digests*.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	defer os.Remove(f.Name())
+
+	b := &BenchmarkRunner{BenchmarkRunnerConfig: BenchmarkRunnerConfig{ResultsDigestFile: f.Name()}}
+	if !b.DigestResults() {
+		t.Fatalf("digests not enabled with a digest file")
+	}
+	b.digests = newDigestCollector(0)
+	for id := uint64(3); id > 0; id-- {
+		q := &testQuery{HumanLabel: []byte("q")}
+		q.SetID(id)
+		b.RecordResult(q, [][]interface{}{{id}})
+	}
+	header := &Header{Use: "devops", Seed: 123}
+	b.saveResultDigests(header)
+
+	digests, err := ReadResultDigests(f.Name())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if digests.Precision != defaultDigestPrecision {
+		t.Errorf("incorrect precision: got %d want %d", digests.Precision, defaultDigestPrecision)
+	}
+	if digests.QueryFileHeader == nil || digests.QueryFileHeader.Seed != header.Seed {
+		t.Errorf("incorrect query file header: got %v", digests.QueryFileHeader)
+	}
+	if len(digests.Results) != 3 {
+		t.Fatalf("incorrect number of results: got %d want 3", len(digests.Results))
+	}
+	for i, d := range digests.Results {
+		if d.ID != uint64(i+1) {
+			t.Errorf("results not sorted by ID: got %d at %d", d.ID, i)
+		}
+		if d.Rows != 1 || d.Label != "q" {
+			t.Errorf("incorrect result digest: %+v", d)
+		}
+	}
+}
+
+func TestCompareResultDigests(t *testing.T) {
+	reference := &ResultDigests{Results: []ResultDigest{
+		{ID: 1, Digest: "a"},
+		{ID: 2, Digest: "b"},
+		{ID: 3, Digest: "c"},
+	}}
+	results := &ResultDigests{Results: []ResultDigest{
+		{ID: 1, Digest: "a"},
+		{ID: 2, Digest: "x"},
+		{ID: 4, Digest: "d"},
+	}}
+	c := CompareResultDigests(reference, results)
+	if c.Matched != 1 {
+		t.Errorf("incorrect matched: got %d want 1", c.Matched)
+	}
+	if len(c.Mismatched) != 1 || c.Mismatched[0].Reference.ID != 2 || c.Mismatched[0].Result.Digest != "x" {
+		t.Errorf("incorrect mismatched: got %+v", c.Mismatched)
+	}
+	if len(c.Missing) != 1 || c.Missing[0].ID != 3 {
+		t.Errorf("incorrect missing: got %+v", c.Missing)
+	}
+	if len(c.Extra) != 1 || c.Extra[0].ID != 4 {
+		t.Errorf("incorrect extra: got %+v", c.Extra)
+	}
+	if c.OK() {
+		t.Errorf("comparison with differences is OK")
+	}
+	if !CompareResultDigests(reference, reference).OK() {
+		t.Errorf("comparison of a run with itself is not OK")
+	}
+}
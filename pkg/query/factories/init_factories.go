@@ -20,18 +20,23 @@ func InitQueryFactories(config *config.QueryGeneratorConfig) map[string]interfac
 	factories := make(map[string]interface{})
 	factories[constants.FormatCassandra] = &cassandra.BaseGenerator{}
 	factories[constants.FormatClickhouse] = &clickhouse.BaseGenerator{
-		UseTags: config.ClickhouseUseTags,
+		UseTags:    config.ClickhouseUseTags,
+		UseRollups: config.UseRollups,
 	}
 	factories[constants.FormatCrateDB] = &cratedb.BaseGenerator{}
-	factories[constants.FormatInflux] = &influx.BaseGenerator{}
+	factories[constants.FormatInflux] = &influx.BaseGenerator{
+		UseRollups: config.UseRollups,
+	}
 	factories[constants.FormatTimescaleDB] = &timescaledb.BaseGenerator{
 		UseJSON:       config.TimescaleUseJSON,
 		UseTags:       config.TimescaleUseTags,
 		UseTimeBucket: config.TimescaleUseTimeBucket,
+		UseRollups:    config.UseRollups,
 	}
 	factories[constants.FormatSiriDB] = &siridb.BaseGenerator{}
 	factories[constants.FormatMongo] = &mongo.BaseGenerator{
-		UseNaive: config.MongoUseNaive,
+		UseNaive:   config.MongoUseNaive,
+		UseRollups: config.UseRollups,
 	}
 	factories[constants.FormatAkumuli] = &akumuli.BaseGenerator{}
 	factories[constants.FormatVictoriaMetrics] = &victoriametrics.BaseGenerator{}
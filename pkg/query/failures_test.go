@@ -0,0 +1,163 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// failingProcessor fails its first failures queries, and blocks until ctx is
+// done if it is slow.
+type failingProcessor struct {
+	failures int
+	calls    int
+	slow     bool
+}
+
+func (p *failingProcessor) Init(_ int) {}
+
+func (p *failingProcessor) ProcessQuery(q Query, isWarm bool) ([]*Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *failingProcessor) ProcessQueryContext(ctx context.Context, q Query, _ bool) ([]*Stat, error) {
+	p.calls++
+	if p.slow {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	if p.calls <= p.failures {
+		return nil, errors.New("query failed")
+	}
+	return []*Stat{GetStat().Init(q.HumanLabelName(), 1)}, nil
+}
+
+func newFailureTestRunner(policy string, retries uint, timeout time.Duration) (*BenchmarkRunner, *[]*Stat) {
+	var sent []*Stat
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			ErrorPolicy: policy,
+			Retries:     retries,
+			Timeout:     timeout,
+		},
+		sp: &mockStatProcessor{
+			args:   &statProcessorArgs{},
+			onSend: func(stats []*Stat) { sent = append(sent, stats...) },
+		},
+	}
+	return b, &sent
+}
+
+func TestProcessQueryFailures(t *testing.T) {
+	q := &testQuery{HumanLabel: []byte("q")}
+	cases := []struct {
+		desc        string
+		policy      string
+		retries     uint
+		timeout     time.Duration
+		processor   *failingProcessor
+		wantOK      bool
+		wantCalls   int
+		wantError   bool
+		wantTimeout bool
+	}{
+		{
+			desc:      "skip",
+			policy:    ErrorPolicySkip,
+			processor: &failingProcessor{failures: 1},
+			wantCalls: 1,
+			wantError: true,
+		},
+		{
+			desc:      "retry until success",
+			policy:    ErrorPolicyRetry,
+			retries:   2,
+			processor: &failingProcessor{failures: 2},
+			wantOK:    true,
+			wantCalls: 3,
+		},
+		{
+			desc:      "retries exhausted",
+			policy:    ErrorPolicyRetry,
+			retries:   2,
+			processor: &failingProcessor{failures: 3},
+			wantCalls: 3,
+			wantError: true,
+		},
+		{
+			desc:        "timeout is not retried",
+			policy:      ErrorPolicyRetry,
+			retries:     2,
+			timeout:     time.Millisecond,
+			processor:   &failingProcessor{slow: true},
+			wantCalls:   1,
+			wantTimeout: true,
+		},
+		{
+			desc:        "timeout does not abort",
+			policy:      ErrorPolicyAbort,
+			timeout:     time.Millisecond,
+			processor:   &failingProcessor{slow: true},
+			wantCalls:   1,
+			wantTimeout: true,
+		},
+	}
+	for _, c := range cases {
+		b, sent := newFailureTestRunner(c.policy, c.retries, c.timeout)
+		_, ok := b.processQuery(c.processor, q, 0)
+		if ok != c.wantOK {
+			t.Errorf("%s: incorrect success: got %v want %v", c.desc, ok, c.wantOK)
+		}
+		if c.processor.calls != c.wantCalls {
+			t.Errorf("%s: incorrect number of calls: got %d want %d", c.desc, c.processor.calls, c.wantCalls)
+		}
+		if len(*sent) != 1 {
+			t.Fatalf("%s: incorrect number of stats sent: got %d want 1", c.desc, len(*sent))
+		}
+		s := (*sent)[0]
+		if s.isError != c.wantError || s.isTimeout != c.wantTimeout {
+			t.Errorf("%s: incorrect outcome: got error %v timeout %v, want error %v timeout %v", c.desc, s.isError, s.isTimeout, c.wantError, c.wantTimeout)
+		}
+	}
+}
+
+func TestProcessQueryAbort(t *testing.T) {
+	b, _ := newFailureTestRunner(ErrorPolicyAbort, 0, 0)
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("did not panic on failed query with abort policy")
+		}
+	}()
+	b.processQuery(&failingProcessor{failures: 1}, &testQuery{HumanLabel: []byte("q")}, 0)
+}
+
+func TestStatProcessorFailures(t *testing.T) {
+	limit := uint64(0)
+	sp := &defaultStatProcessor{args: &statProcessorArgs{limit: &limit}}
+	sp.start(1)
+	sp.send([]*Stat{
+		GetStat().Init([]byte("a"), 10),
+		getFailureStat(false).Init([]byte("a"), 0),
+		getFailureStat(true).Init([]byte("a"), 0),
+		getFailureStat(true).Init([]byte("b"), 0),
+	})
+	sp.CloseAndWait()
+
+	totals := sp.GetTotalsMap()
+	wantErrors := map[string]uint64{labelAllQueries: 1, "a": 1, "b": 0}
+	wantTimeouts := map[string]uint64{labelAllQueries: 2, "a": 1, "b": 1}
+	errs := totals["errors"].(map[string]uint64)
+	timeouts := totals["timeouts"].(map[string]uint64)
+	for label, want := range wantErrors {
+		if got := errs[stripRegex(label)]; got != want {
+			t.Errorf("incorrect errors for %s: got %d want %d", label, got, want)
+		}
+		if got := timeouts[stripRegex(label)]; got != wantTimeouts[label] {
+			t.Errorf("incorrect timeouts for %s: got %d want %d", label, got, wantTimeouts[label])
+		}
+	}
+	if got := sp.statMapping[labelAllQueries].count; got != 1 {
+		t.Errorf("failed queries were pushed to the latencies: got %d want 1", got)
+	}
+}
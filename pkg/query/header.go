@@ -0,0 +1,77 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// headerMagic marks the start of an optional header that precedes the
+// gob-encoded queries of a query file. Files without it are plain gob streams,
+// as produced by older versions of tsbs_generate_queries.
+const headerMagic = "TSBS-QUERY-HEADER\n"
+
+// MixEntry describes one query type of a weighted query mix.
+type MixEntry struct {
+	QueryType string            `json:"query-type" yaml:"query-type"`
+	Weight    uint              `json:"weight" yaml:"weight"`
+	Params    map[string]string `json:"params,omitempty" yaml:"params,omitempty"`
+}
+
+// Header holds the information about how a query file was generated that
+// the query runners report along with the results.
+type Header struct {
+	Use    string     `json:"use"`
+	Format string     `json:"format"`
+	Seed   int64      `json:"seed"`
+	Mix    []MixEntry `json:"mix"`
+}
+
+// WriteHeader writes the header to w. It must be written before any query.
+func WriteHeader(w io.Writer, h *Header) error {
+	encoded, err := json.Marshal(h)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, headerMagic); err != nil {
+		return err
+	}
+	_, err = w.Write(append(encoded, '\n'))
+	return err
+}
+
+// ReadHeader reads the header from the beginning of a query file. If the file
+// has no header nothing is consumed from br and nil is returned.
+func ReadHeader(br *bufio.Reader) (*Header, error) {
+	magic, err := br.Peek(len(headerMagic))
+	if err == io.EOF || (err == nil && !bytes.Equal(magic, []byte(headerMagic))) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if _, err = br.Discard(len(headerMagic)); err != nil {
+		return nil, err
+	}
+
+	line, err := br.ReadBytes('\n')
+	if err != nil {
+		return nil, fmt.Errorf("could not read query file header: %v", err)
+	}
+	h := &Header{}
+	if err = json.Unmarshal(line, h); err != nil {
+		return nil, fmt.Errorf("could not parse query file header: %v", err)
+	}
+	return h, nil
+}
+
+// TotalWeight returns the sum of the weights of all the entries in the mix.
+func (h *Header) TotalWeight() uint {
+	total := uint(0)
+	for _, e := range h.Mix {
+		total += e.Weight
+	}
+	return total
+}
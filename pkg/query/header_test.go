@@ -0,0 +1,83 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/gob"
+	"reflect"
+	"testing"
+)
+
+func TestHeaderRoundTrip(t *testing.T) {
+	h := &Header{
+		Use:    "devops",
+		Format: "timescaledb",
+		Seed:   123,
+		Mix: []MixEntry{
+			{QueryType: "lastpoint", Weight: 3},
+			{QueryType: "single-groupby", Weight: 1, Params: map[string]string{"hosts": "8"}},
+		},
+	}
+	q := NewHTTP()
+	q.HumanLabel = []byte("label")
+
+	var buf bytes.Buffer
+	if err := WriteHeader(&buf, h); err != nil {
+		t.Fatalf("unexpected error writing header: %v", err)
+	}
+	if err := gob.NewEncoder(&buf).Encode(q); err != nil {
+		t.Fatalf("unexpected error encoding query: %v", err)
+	}
+
+	br := bufio.NewReader(&buf)
+	got, err := ReadHeader(br)
+	if err != nil {
+		t.Fatalf("unexpected error reading header: %v", err)
+	}
+	if !reflect.DeepEqual(got, h) {
+		t.Errorf("incorrect header: got\n%+v\nwant\n%+v", got, h)
+	}
+	if got.TotalWeight() != 4 {
+		t.Errorf("incorrect total weight: got %d want 4", got.TotalWeight())
+	}
+
+	// the queries following the header must still decode
+	decoded := NewHTTP()
+	if err := gob.NewDecoder(br).Decode(decoded); err != nil {
+		t.Fatalf("unexpected error decoding query after header: %v", err)
+	}
+	if string(decoded.HumanLabel) != "label" {
+		t.Errorf("incorrect query after header: got %s", decoded.HumanLabel)
+	}
+}
+
+func TestReadHeaderMissing(t *testing.T) {
+	q := NewHTTP()
+	q.HumanLabel = []byte("label")
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(q); err != nil {
+		t.Fatalf("unexpected error encoding query: %v", err)
+	}
+	br := bufio.NewReader(&buf)
+	h, err := ReadHeader(br)
+	if err != nil || h != nil {
+		t.Fatalf("unexpected header or error for plain gob stream: %v %v", h, err)
+	}
+	decoded := NewHTTP()
+	if err := gob.NewDecoder(br).Decode(decoded); err != nil {
+		t.Fatalf("header check consumed the query stream: %v", err)
+	}
+
+	// empty input
+	h, err = ReadHeader(bufio.NewReader(&bytes.Buffer{}))
+	if err != nil || h != nil {
+		t.Errorf("unexpected header or error for empty input: %v %v", h, err)
+	}
+}
+
+func TestReadHeaderBadJSON(t *testing.T) {
+	br := bufio.NewReader(bytes.NewBufferString(headerMagic + "{not json\n"))
+	if _, err := ReadHeader(br); err == nil {
+		t.Errorf("unexpected lack of error for bad header")
+	}
+}
@@ -0,0 +1,60 @@
+package query
+
+import (
+	"time"
+
+	"github.com/timescale/tsbs/pkg/metrics"
+)
+
+// queryMetrics are the live metrics of a query benchmark, served on
+// MetricsAddr. Their methods do nothing on a nil *queryMetrics, so the workers
+// call them whether the metrics are enabled or not.
+type queryMetrics struct {
+	registry      *metrics.Registry
+	duration      *metrics.HistogramVec
+	errors        *metrics.CounterVec
+	timeouts      *metrics.CounterVec
+	activeWorkers *metrics.Gauge
+}
+
+func newQueryMetrics() *queryMetrics {
+	r := metrics.NewRegistry("tsbs_query_")
+	return &queryMetrics{
+		registry:      r,
+		duration:      r.HistogramVec("duration_seconds", "Latency of the successful query executions.", "label", metrics.LatencyBuckets),
+		errors:        r.CounterVec("errors_total", "Number of failed queries.", "label"),
+		timeouts:      r.CounterVec("timeouts_total", "Number of timed out queries.", "label"),
+		activeWorkers: r.Gauge("active_workers", "Number of workers running queries."),
+	}
+}
+
+func (m *queryMetrics) workerStarted() {
+	if m != nil {
+		m.activeWorkers.Add(1)
+	}
+}
+
+func (m *queryMetrics) workerDone() {
+	if m != nil {
+		m.activeWorkers.Add(-1)
+	}
+}
+
+// queryDone records a successful execution of q that took took.
+func (m *queryMetrics) queryDone(q Query, took time.Duration) {
+	if m != nil {
+		m.duration.With(string(q.HumanLabelName())).ObserveDuration(took)
+	}
+}
+
+// queryFailed records a failed query.
+func (m *queryMetrics) queryFailed(q Query, timeout bool) {
+	if m == nil {
+		return
+	}
+	if timeout {
+		m.timeouts.With(string(q.HumanLabelName())).Inc()
+	} else {
+		m.errors.With(string(q.HumanLabelName())).Inc()
+	}
+}
@@ -0,0 +1,27 @@
+package query
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestQueryMetricsLatency(t *testing.T) {
+	b, _ := newFailureTestRunner(ErrorPolicySkip, 0, 0)
+	b.metrics = newQueryMetrics()
+	q := &testQuery{HumanLabel: []byte("q")}
+	if _, ok := b.processQuery(&failingProcessor{}, q, 0); !ok {
+		t.Fatalf("query failed")
+	}
+
+	var buf bytes.Buffer
+	if err := b.metrics.registry.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// the histogram observes the 1ms reported by the processor, not the
+	// time the runner waited for it
+	want := `tsbs_query_duration_seconds_sum{label="q"} 0.001` + "\n"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("incorrect duration: got\n%s\nwant a line %s", buf.String(), want)
+	}
+}
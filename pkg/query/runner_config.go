@@ -0,0 +1,58 @@
+package query
+
+import (
+	"fmt"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+)
+
+// RunnerFlagPrefix is the prefix of the BenchmarkRunnerConfig flags of the
+// commands reading the runner settings from the queries object of a config file
+const RunnerFlagPrefix = "queries.runner."
+
+// DBSpecificFlagPrefix is the prefix of the target specific flags of the
+// commands reading them from the queries object of a config file
+const DBSpecificFlagPrefix = "queries.db-specific."
+
+// RunnerFlags returns the flags of BenchmarkRunnerConfig, nested under
+// queries.runner.
+func RunnerFlags() *pflag.FlagSet {
+	var config BenchmarkRunnerConfig
+	runnerFlags := pflag.NewFlagSet("", pflag.ContinueOnError)
+	config.AddToFlagSet(runnerFlags)
+
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+	runnerFlags.VisitAll(func(f *pflag.Flag) {
+		prefixed := *f
+		prefixed.Name = RunnerFlagPrefix + f.Name
+		fs.AddFlag(&prefixed)
+	})
+	return fs
+}
+
+// ParseConfig creates the BenchmarkRunner from queries.runner and returns it
+// with the target specific settings in queries.db-specific.
+func ParseConfig(v *viper.Viper) (*BenchmarkRunner, *viper.Viper, error) {
+	queriesViper := v.Sub("queries")
+	if queriesViper == nil {
+		return nil, nil, fmt.Errorf("config file didn't have a top-level 'queries' object")
+	}
+
+	runnerViper := queriesViper.Sub("runner")
+	if runnerViper == nil {
+		return nil, nil, fmt.Errorf("config file didn't have queries.runner specified")
+	}
+
+	var runnerConfig BenchmarkRunnerConfig
+	if err := runnerViper.Unmarshal(&runnerConfig); err != nil {
+		return nil, nil, err
+	}
+
+	dbSpecificViper := queriesViper.Sub("db-specific")
+	if dbSpecificViper == nil {
+		return nil, nil, fmt.Errorf("config file didn't have queries.db-specific specified")
+	}
+
+	return NewBenchmarkRunner(runnerConfig), dbSpecificViper, nil
+}
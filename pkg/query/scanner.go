@@ -5,6 +5,8 @@ import (
 	"io"
 	"log"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // scanner is used to read in Queries from a Reader where they are
@@ -12,6 +14,17 @@ import (
 type scanner struct {
 	r     io.Reader
 	limit *uint64
+	// deadline, if set, is when the scanner stops sending queries. Until then
+	// it starts over from the beginning of the input, which rewind returns,
+	// every time the input runs out.
+	deadline time.Time
+	rewind   func() (io.Reader, error)
+	// loopSize is the number of queries in the input, set once the scanner
+	// has looped over it
+	loopSize uint64
+	// inSlice, if set, tells whether the query with the given ID is sent; the
+	// others are left to the other agents of a coordinator
+	inSlice func(id uint64) bool
 }
 
 // newScanner returns a new scanner for a given Reader and its limit
@@ -25,33 +38,86 @@ func (s *scanner) setReader(r io.Reader) *scanner {
 	return s
 }
 
+// setDeadline makes the scanner loop over its input until deadline
+func (s *scanner) setDeadline(deadline time.Time, rewind func() (io.Reader, error)) *scanner {
+	s.deadline = deadline
+	s.rewind = rewind
+	return s
+}
+
+// setSlice makes the scanner only send the queries whose ID is in the slice
+func (s *scanner) setSlice(inSlice func(id uint64) bool) *scanner {
+	s.inSlice = inSlice
+	return s
+}
+
+// loop returns the number of the loop over the input, from 0, in which the
+// query with the given ID was sent.
+func (s *scanner) loop(id uint64) uint64 {
+	if size := atomic.LoadUint64(&s.loopSize); size > 0 {
+		return id / size
+	}
+	return 0
+}
+
 // scan reads encoded Queries and places them into a channel
 func (s *scanner) scan(pool *sync.Pool, c chan Query) {
 	decoder := gob.NewDecoder(s.r)
 
 	n := uint64(0)
+	// id is the position of the query in the input, which keeps increasing
+	// when the scanner loops over the input so that every query sent has its
+	// own ID
+	id := uint64(0)
+	loopStart := uint64(0) // id of the first query of the current loop
 	for {
 		if *s.limit > 0 && n >= *s.limit {
 			// request queries limit reached, time to quit
 			break
 		}
+		if !s.deadline.IsZero() && !time.Now().Before(s.deadline) {
+			// run duration reached, time to quit
+			break
+		}
 
 		q := pool.Get().(Query)
 		err := decoder.Decode(q)
 		if err == io.EOF {
-			// EOF, all done
-			break
+			pool.Put(q)
+			if s.deadline.IsZero() || id == loopStart {
+				// EOF, all done
+				break
+			}
+			if loopStart == 0 {
+				atomic.StoreUint64(&s.loopSize, id)
+			}
+			// EOF before the deadline, start over
+			r, err := s.rewind()
+			if err != nil {
+				log.Fatal(err)
+			}
+			decoder = gob.NewDecoder(r)
+			loopStart = id
+			continue
 		}
 		if err != nil {
 			// Can't read, time to quit
 			log.Fatal(err)
 		}
 
+		if s.inSlice != nil && !s.inSlice(id) {
+			// left to another agent
+			pool.Put(q)
+			id++
+			continue
+		}
+
 		// We have a query, send it to the runner
-		q.SetID(n)
+		q.SetID(id)
 		c <- q
 
 		// Queries counter
 		n++
+		id++
 	}
 }
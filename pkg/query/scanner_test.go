@@ -5,8 +5,10 @@ import (
 	"bytes"
 	"encoding/gob"
 	"fmt"
+	"io"
 	"sync"
 	"testing"
+	"time"
 )
 
 type testQuery struct {
@@ -150,3 +152,91 @@ func TestScanTimescaleDB(t *testing.T) {
 		return nil
 	})
 }
+
+func TestScannerDeadline(t *testing.T) {
+	totalQueries := uint64(3)
+	var b bytes.Buffer
+	err := encodeQueries(&b, totalQueries, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("testlabel")}
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	limit := uint64(0)
+	rewinds := 0
+	scanner := newScanner(&limit).setDeadline(time.Now().Add(50*time.Millisecond), func() (io.Reader, error) {
+		rewinds++
+		return bytes.NewReader(b.Bytes()), nil
+	})
+	queryChan := make(chan Query, 1)
+	var ids []uint64
+	done := make(chan struct{})
+	go func() {
+		for q := range queryChan {
+			ids = append(ids, q.GetID())
+			time.Sleep(time.Millisecond)
+		}
+		close(done)
+	}()
+	start := time.Now()
+	scanner.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+	close(queryChan)
+	<-done
+
+	if took := time.Since(start); took < 50*time.Millisecond {
+		t.Errorf("scanner stopped before the deadline: took %v", took)
+	}
+	if rewinds == 0 || uint64(len(ids)) <= totalQueries {
+		t.Fatalf("scanner did not loop over its input: %d rewinds, %d queries", rewinds, len(ids))
+	}
+	// the IDs keep increasing across the loops
+	for i, id := range ids {
+		if id != uint64(i) {
+			t.Errorf("incorrect ID of query %d: got %d want %d", i, id, i)
+		}
+		if loop := scanner.loop(id); loop != uint64(i)/totalQueries {
+			t.Errorf("incorrect loop of query %d: got %d want %d", i, loop, uint64(i)/totalQueries)
+		}
+	}
+
+	// the limit still applies
+	limit = 5
+	queryChan = make(chan Query, limit)
+	scanner.setDeadline(time.Now().Add(time.Minute), scanner.rewind)
+	scanner.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+	if got := len(queryChan); got != int(limit) {
+		t.Errorf("incorrect number of queries with a limit: got %d want %d", got, limit)
+	}
+}
+
+func TestScannerSlice(t *testing.T) {
+	totalQueries := uint64(10)
+	var b bytes.Buffer
+	err := encodeQueries(&b, totalQueries, func(i uint64) Query {
+		return &testQuery{HumanLabel: []byte("testlabel")}
+	})
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	limit := uint64(0)
+	queryChan := make(chan Query, totalQueries)
+	scanner := newScanner(&limit).setSlice(func(id uint64) bool { return id%3 == 1 })
+	scanner.setReader(bytes.NewReader(b.Bytes())).scan(&testQueryPool, queryChan)
+	close(queryChan)
+	var ids []uint64
+	for q := range queryChan {
+		ids = append(ids, q.GetID())
+	}
+	want := []uint64{1, 4, 7}
+	if len(ids) != len(want) {
+		t.Fatalf("incorrect queries in the slice: got %v want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("incorrect queries in the slice: got %v want %v", ids, want)
+			break
+		}
+	}
+}
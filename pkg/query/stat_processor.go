@@ -5,10 +5,13 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/HdrHistogram/hdrhistogram-go"
+	"io"
 	"io/ioutil"
 	"log"
 	"os"
 	"regexp"
+	"sort"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -19,17 +22,22 @@ type statProcessor interface {
 	getArgs() *statProcessorArgs
 	send(stats []*Stat)
 	sendWarm(stats []*Stat)
-	process(workers uint)
+	// start processes the stats sent from now on, on its own goroutine
+	start(workers uint)
 	CloseAndWait()
 	GetTotalsMap() map[string]interface{}
+	// getHistograms returns the latency histograms, in microseconds, of the
+	// labels named like in GetTotalsMap
+	getHistograms() map[string]*hdrhistogram.Histogram
 }
 
 type statProcessorArgs struct {
-	prewarmQueries   bool    // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
-	limit            *uint64 // limit is the number of statistics to analyze before stopping
-	burnIn           uint64  // burnIn is the number of statistics to ignore before analyzing
-	printInterval    uint64  // printInterval is how often print intermediate stats (number of queries)
-	hdrLatenciesFile string  // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	prewarmQueries   bool          // PrewarmQueries tells the StatProcessor whether we're running each query twice to prewarm the cache
+	limit            *uint64       // limit is the number of statistics to analyze before stopping
+	burnIn           uint64        // burnIn is the number of statistics to ignore before analyzing
+	printInterval    uint64        // printInterval is how often print intermediate stats (number of queries)
+	hdrLatenciesFile string        // hdrLatenciesFile is the filename to Write the High Dynamic Range (HDR) Histogram of Response Latencies to
+	warmupDuration   time.Duration // warmupDuration is how long to discard statistics for before analyzing
 
 }
 
@@ -42,6 +50,18 @@ type defaultStatProcessor struct {
 	startTime   time.Time
 	endTime     time.Time
 	statMapping map[string]*statGroup
+	// responseMapping holds the response times of open-loop queries
+	responseMapping map[string]*statGroup
+	// failureMapping holds the number of failed queries per label
+	failureMapping map[string]*failureCount
+	// now returns the current time, time.Now if nil
+	now func() time.Time
+}
+
+// failureCount is the number of queries of a label that failed or timed out
+type failureCount struct {
+	errors   uint64
+	timeouts uint64
 }
 
 func newStatProcessor(args *statProcessorArgs) statProcessor {
@@ -76,11 +96,24 @@ func (sp *defaultStatProcessor) sendWarm(stats []*Stat) {
 	sp.send(stats)
 }
 
+func (sp *defaultStatProcessor) clock() time.Time {
+	if sp.now == nil {
+		return time.Now()
+	}
+	return sp.now()
+}
+
+// start creates the channel of the stats before launching their processing,
+// so that stats can be sent as soon as it returns.
+func (sp *defaultStatProcessor) start(workers uint) {
+	sp.c = make(chan *Stat, workers)
+	sp.wg.Add(1)
+	go sp.process(workers)
+}
+
 // process collects latency results, aggregating them into summary
 // statistics. Optionally, they are printed to stderr at regular intervals.
 func (sp *defaultStatProcessor) process(workers uint) {
-	sp.c = make(chan *Stat, workers)
-	sp.wg.Add(1)
 	const allQueriesLabel = labelAllQueries
 	sp.statMapping = map[string]*statGroup{
 		allQueriesLabel: newStatGroup(*sp.args.limit),
@@ -92,11 +125,38 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	}
 
 	i := uint64(0)
-	sp.startTime = time.Now()
+	sp.startTime = sp.clock()
 	prevTime := sp.startTime
 	prevRequestCount := uint64(0)
 
+	warmupEnd := sp.startTime.Add(sp.args.warmupDuration)
+	warmedUp := sp.args.warmupDuration <= 0
+
 	for stat := range sp.c {
+		if !warmedUp {
+			now := sp.clock()
+			if now.Before(warmupEnd) {
+				statPool.Put(stat)
+				continue
+			}
+			// the query rates cover the time after the warm-up only
+			warmedUp = true
+			sp.startTime = now
+			prevTime = now
+			_, err := fmt.Fprintf(os.Stderr, "warm-up complete after %v with %d workers\n", sp.args.warmupDuration, workers)
+			if err != nil {
+				log.Fatal(err)
+			}
+		}
+		if stat.isResponse {
+			// response times follow the service time of their query, which
+			// already went through the burn-in count
+			if i >= sp.args.burnIn {
+				sp.pushResponse(stat)
+			}
+			statPool.Put(stat)
+			continue
+		}
 		atomic.AddUint64(&sp.opsCount, 1)
 		if i < sp.args.burnIn {
 			i++
@@ -108,6 +168,14 @@ func (sp *defaultStatProcessor) process(workers uint) {
 				log.Fatal(err)
 			}
 		}
+		if stat.isError || stat.isTimeout {
+			sp.pushFailure(stat)
+			if !sp.args.prewarmQueries || !stat.isWarm {
+				i++
+			}
+			statPool.Put(stat)
+			continue
+		}
 		if _, ok := sp.statMapping[string(stat.label)]; !ok {
 			sp.statMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
 		}
@@ -138,7 +206,7 @@ func (sp *defaultStatProcessor) process(workers uint) {
 
 		// print stats to stderr (if printInterval is greater than zero):
 		if sp.args.printInterval > 0 && i > 0 && i%sp.args.printInterval == 0 && (i < *sp.args.limit || *sp.args.limit == 0) {
-			now := time.Now()
+			now := sp.clock()
 			sinceStart := now.Sub(sp.startTime)
 			took := now.Sub(prevTime)
 			intervalQueryRate := float64(sp.opsCount-prevRequestCount) / float64(took.Seconds())
@@ -164,7 +232,7 @@ func (sp *defaultStatProcessor) process(workers uint) {
 			prevTime = now
 		}
 	}
-	sinceStart := time.Now().Sub(sp.startTime)
+	sinceStart := sp.clock().Sub(sp.startTime)
 	overallQueryRate := float64(sp.opsCount) / float64(sinceStart.Seconds())
 	// the final stats output goes to stdout:
 	_, err := fmt.Printf("Run complete after %d queries with %d workers (Overall query rate %0.2f queries/sec):\n", i-sp.args.burnIn, workers, overallQueryRate)
@@ -175,6 +243,20 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	if err != nil {
 		log.Fatal(err)
 	}
+	if len(sp.responseMapping) > 0 {
+		fmt.Println("Response times (from the intended send time, including time queued):")
+		err = writeStatGroupMap(os.Stdout, sp.responseMapping)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
+	if len(sp.failureMapping) > 0 {
+		fmt.Println("Errors and timeouts:")
+		err = writeFailureMap(os.Stdout, sp.failureMapping)
+		if err != nil {
+			log.Fatal(err)
+		}
+	}
 
 	if len(sp.args.hdrLatenciesFile) > 0 {
 		_, _ = fmt.Printf("Saving High Dynamic Range (HDR) Histogram of Response Latencies to %s\n", sp.args.hdrLatenciesFile)
@@ -194,6 +276,67 @@ func (sp *defaultStatProcessor) process(workers uint) {
 	sp.wg.Done()
 }
 
+// pushResponse records the response time of an open-loop query, both under
+// its own label and under all queries.
+func (sp *defaultStatProcessor) pushResponse(stat *Stat) {
+	if sp.responseMapping == nil {
+		sp.responseMapping = map[string]*statGroup{
+			labelAllQueries: newStatGroup(*sp.args.limit),
+		}
+	}
+	if _, ok := sp.responseMapping[string(stat.label)]; !ok {
+		sp.responseMapping[string(stat.label)] = newStatGroup(*sp.args.limit)
+	}
+	sp.responseMapping[string(stat.label)].push(stat.value)
+	sp.responseMapping[labelAllQueries].push(stat.value)
+}
+
+// pushFailure records a failed or timed out query, both under its own label
+// and under all queries.
+func (sp *defaultStatProcessor) pushFailure(stat *Stat) {
+	if sp.failureMapping == nil {
+		sp.failureMapping = map[string]*failureCount{
+			labelAllQueries: {},
+		}
+	}
+	label := string(stat.label)
+	if stat.isWarm {
+		label += " (warm)"
+	}
+	if _, ok := sp.failureMapping[label]; !ok {
+		sp.failureMapping[label] = &failureCount{}
+	}
+	for _, fc := range []*failureCount{sp.failureMapping[label], sp.failureMapping[labelAllQueries]} {
+		if stat.isTimeout {
+			fc.timeouts++
+		} else {
+			fc.errors++
+		}
+	}
+}
+
+// writeFailureMap writes the error and timeout counts of each label, sorted
+// by label.
+func writeFailureMap(w io.Writer, failures map[string]*failureCount) error {
+	labels := make([]string, 0, len(failures))
+	maxLabelLen := 0
+	for label := range failures {
+		labels = append(labels, label)
+		if len(label) > maxLabelLen {
+			maxLabelLen = len(label)
+		}
+	}
+	sort.Strings(labels)
+	for _, label := range labels {
+		fc := failures[label]
+		_, err := fmt.Fprintf(w, "%s:\nerrors: %d, timeouts: %d\n", label+strings.Repeat(" ", maxLabelLen-len(label)), fc.errors, fc.timeouts)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func generateQuantileMap(hist *hdrhistogram.Histogram) (int64, map[string]float64) {
 	ops := hist.TotalCount()
 	q0 := 0.0
@@ -223,7 +366,7 @@ func (sp *defaultStatProcessor) GetTotalsMap() map[string]interface{} {
 	totals["limit"] = sp.args.limit
 	// burnIn is the number of statistics to ignore before analyzing
 	totals["burnIn"] = sp.args.burnIn
-	sinceStart := time.Now().Sub(sp.startTime)
+	sinceStart := sp.clock().Sub(sp.startTime)
 	// calculate overall query rates
 	queryRates := make(map[string]interface{})
 	for label, statGroup := range sp.statMapping {
@@ -238,9 +381,35 @@ func (sp *defaultStatProcessor) GetTotalsMap() map[string]interface{} {
 		quantiles[stripRegex(label)] = all
 	}
 	totals["overallQuantiles"] = quantiles
+	if len(sp.responseMapping) > 0 {
+		responseQuantiles := make(map[string]interface{})
+		for label, statGroup := range sp.responseMapping {
+			_, all := generateQuantileMap(statGroup.latencyHDRHistogram)
+			responseQuantiles[stripRegex(label)] = all
+		}
+		totals["responseQuantiles"] = responseQuantiles
+	}
+	if len(sp.failureMapping) > 0 {
+		errs := make(map[string]uint64)
+		timeouts := make(map[string]uint64)
+		for label, fc := range sp.failureMapping {
+			errs[stripRegex(label)] = fc.errors
+			timeouts[stripRegex(label)] = fc.timeouts
+		}
+		totals["errors"] = errs
+		totals["timeouts"] = timeouts
+	}
 	return totals
 }
 
+func (sp *defaultStatProcessor) getHistograms() map[string]*hdrhistogram.Histogram {
+	histograms := make(map[string]*hdrhistogram.Histogram, len(sp.statMapping))
+	for label, statGroup := range sp.statMapping {
+		histograms[stripRegex(label)] = statGroup.latencyHDRHistogram
+	}
+	return histograms
+}
+
 func stripRegex(in string) string {
 	reg, _ := regexp.Compile("[^a-zA-Z0-9]+")
 	return reg.ReplaceAllString(in, "_")
@@ -1,6 +1,7 @@
 package query
 
 import (
+	"sync"
 	"testing"
 	"time"
 )
@@ -77,3 +78,44 @@ func TestStatProcessorSendWarm(t *testing.T) {
 		t.Errorf("empty stat array changed channel length: got %d want %d", got, wantLen)
 	}
 }
+
+// stepClock is a clock moving forward by step each time it is read.
+type stepClock struct {
+	mu   sync.Mutex
+	t    time.Time
+	step time.Duration
+}
+
+func (c *stepClock) now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.t = c.t.Add(c.step)
+	return c.t
+}
+
+func TestStatProcessorWarmup(t *testing.T) {
+	limit := uint64(0)
+	warmup := 30 * time.Millisecond
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	// the clock is read once when the processing starts, then once per stat
+	// during the warm-up: the first stat is at 20ms, the second at 40ms
+	clock := &stepClock{t: start.Add(-20 * time.Millisecond), step: 20 * time.Millisecond}
+	sp := &defaultStatProcessor{args: &statProcessorArgs{limit: &limit, warmupDuration: warmup}, now: clock.now}
+	sp.start(1)
+	sp.send([]*Stat{
+		GetStat().Init([]byte("a"), 10),
+		GetStat().Init([]byte("a"), 20),
+		GetStat().Init([]byte("a"), 30),
+	})
+	sp.CloseAndWait()
+
+	if got := sp.statMapping[labelAllQueries].count; got != 2 {
+		t.Errorf("incorrect number of stats after the warm-up: got %d want 2", got)
+	}
+	if got := sp.opsCount; got != 2 {
+		t.Errorf("incorrect ops count after the warm-up: got %d want 2", got)
+	}
+	if want := start.Add(40 * time.Millisecond); !sp.startTime.Equal(want) {
+		t.Errorf("incorrect start time after the warm-up: got %v want %v", sp.startTime, want)
+	}
+}
@@ -16,10 +16,13 @@ var (
 // Stat represents one statistical measurement, typically used to store the
 // latency of a query (or part of query).
 type Stat struct {
-	label     []byte
-	value     float64
-	isWarm    bool
-	isPartial bool
+	label      []byte
+	value      float64
+	isWarm     bool
+	isPartial  bool
+	isResponse bool // response time of an open-loop query, see BenchmarkRunner.openLoopHandler
+	isError    bool // the query failed, value is meaningless
+	isTimeout  bool // the query timed out, value is meaningless
 }
 
 var statPool = &sync.Pool{
@@ -43,6 +46,21 @@ func GetPartialStat() *Stat {
 	return s
 }
 
+// getResponseStat returns a response time Stat for use from a pool
+func getResponseStat() *Stat {
+	s := GetStat()
+	s.isResponse = true
+	return s
+}
+
+// getFailureStat returns a Stat recording a failed (or timed out) query
+func getFailureStat(timeout bool) *Stat {
+	s := GetStat()
+	s.isError = !timeout
+	s.isTimeout = timeout
+	return s
+}
+
 // Init safely initializes a Stat while minimizing heap allocations.
 func (s *Stat) Init(label []byte, value float64) *Stat {
 	s.label = s.label[:0] // clear
@@ -57,6 +75,9 @@ func (s *Stat) reset() *Stat {
 	s.value = 0.0
 	s.isWarm = false
 	s.isPartial = false
+	s.isResponse = false
+	s.isError = false
+	s.isTimeout = false
 	return s
 }
 
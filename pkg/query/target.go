@@ -0,0 +1,40 @@
+package query
+
+import (
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+)
+
+// ImplementedTarget is a database queries can be run against.
+type ImplementedTarget interface {
+	// TargetName returns the format name of the target, e.g. timescaledb
+	TargetName() string
+
+	// TargetSpecificFlags adds to the supplied flagSet a number of target-specific
+	// flags that will be enabled only when running queries against this target.
+	// flagPrefix is a string that should be concatenated with the names of all flags
+	// defined here, so they can be nested in a yaml config
+	TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet)
+
+	// QueryPool returns the pool of the Query type the target executes
+	QueryPool() *sync.Pool
+
+	// ProcessorFactory sets up the connection to the target with the settings
+	// in v (named as in TargetSpecificFlags, without the prefix) and returns the
+	// ProcessorCreate used for every worker of runner, and a function releasing
+	// what was set up once the benchmark is done.
+	ProcessorFactory(runner *BenchmarkRunner, v *viper.Viper) (ProcessorCreate, func(), error)
+}
+
+// RunTarget runs the benchmark against target, configured with the settings in v.
+func (b *BenchmarkRunner) RunTarget(target ImplementedTarget, v *viper.Viper) error {
+	create, closeFn, err := target.ProcessorFactory(b, v)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+	b.Run(target.QueryPool(), create)
+	return nil
+}
@@ -0,0 +1,63 @@
+package query
+
+import (
+	"errors"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+)
+
+type mockTarget struct {
+	factoryErr error
+	closed     bool
+	processor  *mockProcessor
+}
+
+func (t *mockTarget) TargetName() string                             { return "mock" }
+func (t *mockTarget) TargetSpecificFlags(_ string, _ *pflag.FlagSet) {}
+func (t *mockTarget) QueryPool() *sync.Pool                          { return &TimescaleDBPool }
+func (t *mockTarget) ProcessorFactory(_ *BenchmarkRunner, _ *viper.Viper) (ProcessorCreate, func(), error) {
+	if t.factoryErr != nil {
+		return nil, nil, t.factoryErr
+	}
+	return func() Processor { return t.processor }, func() { t.closed = true }, nil
+}
+
+func TestBenchmarkRunnerRunTarget(t *testing.T) {
+	fakeQueriesFile, err := ioutil.TempFile("", "fake_queries*")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limit := uint64(0)
+	wg := &sync.WaitGroup{}
+	b := &BenchmarkRunner{
+		BenchmarkRunnerConfig: BenchmarkRunnerConfig{
+			Workers:  1,
+			FileName: fakeQueriesFile.Name(),
+		},
+		sp:      &mockStatProcessor{args: &statProcessorArgs{}, wg: wg},
+		scanner: newScanner(&limit),
+	}
+
+	target := &mockTarget{processor: &mockProcessor{}}
+	wg.Add(1)
+	if err := b.RunTarget(target, viper.New()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	wg.Wait()
+	if !target.processor.initCalled {
+		t.Errorf("init of processor wasn't called")
+	}
+	if !target.closed {
+		t.Errorf("target wasn't closed after the run")
+	}
+
+	target = &mockTarget{factoryErr: errors.New("no connection")}
+	if err := b.RunTarget(target, viper.New()); err != target.factoryErr {
+		t.Errorf("expected factory error, got %v", err)
+	}
+}
@@ -1,8 +1,9 @@
-package main
+package akumuli
 
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -37,29 +38,29 @@ func NewHTTPClient(host string) *HTTPClient {
 	}
 }
 
-// Do performs the action specified by the given Query. It uses fasthttp, and
-// tries to minimize heap allocations.
-func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+// Do performs the action specified by the given Query within ctx. It uses
+// fasthttp, and tries to minimize heap allocations.
+func (w *HTTPClient) Do(ctx context.Context, q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
 	// populate uri from the reusable byte slice:
 	w.uri = w.uri[:0]
 	w.uri = append(w.uri, w.Host...)
 	w.uri = append(w.uri, q.Path...)
 
 	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), string(w.uri), bytes.NewReader(q.Body))
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), string(w.uri), bytes.NewReader(q.Body))
 	if err != nil {
-		panic(err)
+		return 0, fmt.Errorf("error while creating request: %s", err)
 	}
 
 	// Perform the request while tracking latency:
 	start := time.Now()
 	resp, err := w.client.Do(req)
 	if err != nil {
-		panic(err)
+		return 0, fmt.Errorf("query execution error: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		panic("http request did not return status 200 OK")
+		return 0, fmt.Errorf("non-200 statuscode received: %d", resp.StatusCode)
 	}
 
 	reader := bufio.NewReader(resp.Body)
@@ -70,7 +71,7 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 			err = nil
 			break
 		} else if err != nil {
-			panic(err)
+			return 0, fmt.Errorf("error while reading response body: %s", err)
 		}
 	}
 	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
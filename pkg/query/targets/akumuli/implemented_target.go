@@ -0,0 +1,38 @@
+package akumuli
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &akumuliTarget{}
+}
+
+type akumuliTarget struct {
+}
+
+func (t *akumuliTarget) TargetName() string {
+	return constants.FormatAkumuli
+}
+
+func (t *akumuliTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *akumuliTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"endpoint", "http://localhost:8181", "Akumuli API endpoint IP address.")
+}
+
+func (t *akumuliTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	if runner.DigestResults() {
+		return nil, nil, errors.New("result digests are not supported by Akumuli")
+	}
+	endpoint := v.GetString("endpoint")
+	return func() query.Processor { return newProcessor(runner, endpoint) }, func() {}, nil
+}
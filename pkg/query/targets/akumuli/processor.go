@@ -0,0 +1,41 @@
+package akumuli
+
+import (
+	"context"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type processor struct {
+	w        *HTTPClient
+	opts     *HTTPClientDoOptions
+	runner   *query.BenchmarkRunner
+	endpoint string
+}
+
+func newProcessor(runner *query.BenchmarkRunner, endpoint string) query.Processor {
+	return &processor{runner: runner, endpoint: endpoint}
+}
+
+func (p *processor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:          p.runner.DebugLevel(),
+		PrintResponses: p.runner.DoPrintResponses(),
+	}
+	url := p.endpoint
+	p.w = NewHTTPClient(url)
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, err := p.w.Do(ctx, hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
@@ -0,0 +1,88 @@
+package cassandra
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+const (
+	BucketDuration   = 24 * time.Hour
+	BucketTimeLayout = "2006-01-02"
+)
+
+// Blessed tables that hold benchmark data:
+var (
+	BlessedTables = []string{
+		"series_bigint",
+		"series_float",
+		"series_double",
+		"series_boolean",
+		"series_blob",
+	}
+)
+
+// Helpers for choice-like flags:
+var (
+	aggrPlanChoices = map[string]int{
+		"server": AggrPlanTypeWithServerAggregation,
+		"client": AggrPlanTypeWithoutServerAggregation,
+	}
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &cassandraTarget{}
+}
+
+type cassandraTarget struct {
+}
+
+func (t *cassandraTarget) TargetName() string {
+	return constants.FormatCassandra
+}
+
+func (t *cassandraTarget) QueryPool() *sync.Pool {
+	return &query.CassandraPool
+}
+
+func (t *cassandraTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"host", "localhost:9042", "Cassandra hostname and port combination.")
+	flagSet.String(flagPrefix+"aggregation-plan", "", "Aggregation plan (choices: server, client)")
+	flagSet.Duration(flagPrefix+"read-timeout", 1*time.Second, "Maximum request timeout.")
+	flagSet.Duration(flagPrefix+"client-side-index-timeout", 10*time.Second, "Maximum client-side index timeout (only used at initialization).")
+}
+
+func (t *cassandraTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	if runner.DigestResults() {
+		return nil, nil, errors.New("result digests are not supported by Cassandra")
+	}
+	daemonURL := v.GetString("host")
+	aggrPlanLabel := v.GetString("aggregation-plan")
+	aggrPlan, ok := aggrPlanChoices[aggrPlanLabel]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid aggregation plan '%s'", aggrPlanLabel)
+	}
+
+	// Make client-side index:
+	session := NewCassandraSession(daemonURL, runner.DatabaseName(), v.GetDuration("client-side-index-timeout"))
+	csi := NewClientSideIndex(FetchSeriesCollection(session))
+	session.Close()
+
+	// Make database connection pool:
+	session = NewCassandraSession(daemonURL, runner.DatabaseName(), v.GetDuration("read-timeout"))
+
+	return func() query.Processor {
+		return &processor{
+			runner:   runner,
+			aggrPlan: aggrPlan,
+			csi:      csi,
+			session:  session,
+		}
+	}, session.Close, nil
+}
@@ -0,0 +1,53 @@
+package cassandra
+
+import (
+	"github.com/gocql/gocql"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type processor struct {
+	qe   *HLQueryExecutor
+	opts *HLQueryExecutorDoOptions
+
+	runner   *query.BenchmarkRunner
+	aggrPlan int
+	csi      *ClientSideIndex
+	session  *gocql.Session
+}
+
+func (p *processor) Init(workerNumber int) {
+	p.opts = &HLQueryExecutorDoOptions{
+		AggregationPlan:      p.aggrPlan,
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+	}
+	p.qe = NewHLQueryExecutor(p.session, p.csi, p.runner.DebugLevel())
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	cq := q.(*query.Cassandra)
+	hlq := &HLQuery{*cq}
+	hlq.ForceUTC()
+	labels := [][]byte{
+		q.HumanLabelName(),
+		append(q.HumanLabelName(), "-qp"...),
+		append(q.HumanLabelName(), "-req"...),
+	}
+	if isWarm {
+		for i, l := range labels {
+			labels[i] = append(l, " (warm)"...)
+		}
+	}
+	qpLagMs, reqLagMs, err := p.qe.Do(hlq, *p.opts)
+	if err != nil {
+		return nil, err
+	}
+	// total stat
+	totalMs := qpLagMs + reqLagMs
+	stats := []*query.Stat{
+		query.GetPartialStat().Init(labels[1], qpLagMs),
+		query.GetPartialStat().Init(labels[2], reqLagMs),
+		query.GetStat().Init(labels[0], totalMs),
+	}
+	return stats, nil
+}
@@ -0,0 +1,51 @@
+package clickhouse
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &clickhouseTarget{}
+}
+
+type clickhouseTarget struct {
+}
+
+func (t *clickhouseTarget) TargetName() string {
+	return constants.FormatClickhouse
+}
+
+func (t *clickhouseTarget) QueryPool() *sync.Pool {
+	return &query.ClickHousePool
+}
+
+func (t *clickhouseTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"additional-params", "sslmode=disable",
+		"String of additional ClickHouse connection parameters, e.g., 'sslmode=disable'.")
+	flagSet.String(flagPrefix+"hosts", "localhost",
+		"Comma separated list of ClickHouse hosts (pass multiple values for sharding reads on a multi-node setup)")
+	flagSet.String(flagPrefix+"user", "default", "User to connect to ClickHouse as")
+	flagSet.String(flagPrefix+"password", "", "Password to connect to ClickHouse")
+}
+
+func (t *clickhouseTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	opts := &options{
+		chConnect: v.GetString("additional-params"),
+		user:      v.GetString("user"),
+		password:  v.GetString("password"),
+		runner:    runner,
+	}
+
+	// Parse comma separated string of hosts and put in a slice (for multi-node setups)
+	for _, host := range strings.Split(v.GetString("hosts"), ",") {
+		opts.hostsList = append(opts.hostsList, host)
+	}
+
+	return func() query.Processor { return newProcessor(opts) }, func() {}, nil
+}
@@ -0,0 +1,179 @@
+package clickhouse
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/kshvakov/clickhouse"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// options are the settings shared by the processors of all workers
+type options struct {
+	chConnect string
+	hostsList []string
+	user      string
+	password  string
+	runner    *query.BenchmarkRunner
+}
+
+// Get the connection string for a connection to PostgreSQL.
+
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func (o *options) getConnectString(workerNumber int) string {
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := o.hostsList[workerNumber%len(o.hostsList)]
+
+	return fmt.Sprintf("tcp://%s:9000?username=%s&password=%s&database=%s", host, o.user, o.password, o.runner.DatabaseName())
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(cols []string, values [][]interface{}, q *query.ClickHouse) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+
+	results := []map[string]interface{}{}
+	for _, v := range values {
+		r := make(map[string]interface{})
+		for i, col := range cols {
+			r[col] = v[i]
+		}
+		results = append(results, r)
+		resp["results"] = results
+	}
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+// readRows reads all the rows of r, returning the column names and the values
+// of each row.
+func readRows(rows *sqlx.Rows) ([]string, [][]interface{}, error) {
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	values := [][]interface{}{}
+	for rows.Next() {
+		v, err := rows.SliceScan()
+		if err != nil {
+			return nil, nil, err
+		}
+		values = append(values, v)
+	}
+	return cols, values, rows.Err()
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+	recordResults bool
+	countResults  bool
+}
+
+// query.Processor interface implementation
+type processor struct {
+	db      *sqlx.DB
+	opts    *queryExecutorOptions
+	options *options
+}
+
+// query.Processor interface implementation
+func newProcessor(o *options) query.Processor {
+	return &processor{options: o}
+}
+
+// query.Processor interface implementation
+func (p *processor) Init(workerNumber int) {
+	p.db = sqlx.MustConnect("clickhouse", p.options.getConnectString(workerNumber))
+	p.opts = &queryExecutorOptions{
+		// ClickHouse could not do EXPLAIN
+		showExplain:   false,
+		debug:         p.options.runner.DebugLevel() > 0,
+		printResponse: p.options.runner.DoPrintResponses(),
+		recordResults: p.options.runner.RecordResults(),
+		countResults:  p.options.runner.CountResults(),
+	}
+}
+
+// query.Processor interface implementation
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+// query.ContextProcessor interface implementation
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+
+	// Ensure ClickHouse query
+	chQuery := q.(*query.ClickHouse)
+
+	start := time.Now()
+
+	// SqlQuery is []byte, so cast is needed
+	sql := string(chQuery.SqlQuery)
+
+	// Main action - run the query
+	rows, err := p.db.QueryxContext(ctx, sql)
+	if err != nil {
+		return nil, err
+	}
+
+	// Print some extra info if needed
+	if p.opts.debug {
+		fmt.Println(sql)
+	}
+	var values [][]interface{}
+	count := 0
+	if p.opts.printResponse || p.opts.recordResults {
+		var cols []string
+		cols, values, err = readRows(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if p.opts.printResponse {
+			prettyPrintResponse(cols, values, chQuery)
+		}
+		count = len(values)
+	} else if p.opts.countResults {
+		// the rows are fetched to be counted, but not kept
+		for rows.Next() {
+			count++
+		}
+		if err = rows.Err(); err != nil {
+			rows.Close()
+			return nil, err
+		}
+	}
+
+	// Finalize the query
+	rows.Close()
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	if p.opts.recordResults {
+		p.options.runner.RecordResult(q, values)
+	} else if p.opts.countResults {
+		p.options.runner.RecordResultCount(q, count)
+	}
+
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,55 @@
+package cratedb
+
+import (
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &crateTarget{}
+}
+
+type crateTarget struct {
+}
+
+func (t *crateTarget) TargetName() string {
+	return constants.FormatCrateDB
+}
+
+func (t *crateTarget) QueryPool() *sync.Pool {
+	return &query.CrateDBPool
+}
+
+func (t *crateTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"hosts", "localhost", "CrateDB hostnames")
+	flagSet.String(flagPrefix+"user", "crate", "User to connect to CrateDB")
+	flagSet.String(flagPrefix+"pass", "", "Password for user connecting to CrateDB")
+	flagSet.Int(flagPrefix+"port", 5432, "A port to connect to database instances")
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
+
+func (t *crateTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	showExplain := v.GetBool("show-explain")
+	if showExplain {
+		runner.SetLimit(1)
+	}
+
+	processor, err := newProcessor(
+		runner,
+		v.GetString("hosts"),
+		v.GetInt("port"),
+		v.GetString("user"),
+		v.GetString("pass"),
+		showExplain,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func() query.Processor {
+		return processor
+	}, func() {}, nil
+}
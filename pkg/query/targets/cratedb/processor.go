@@ -0,0 +1,176 @@
+package cratedb
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v4"
+	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type processor struct {
+	conn    *pgx.Conn
+	connCfg *pgx.ConnConfig
+	opts    *executorOptions
+	runner  *query.BenchmarkRunner
+}
+
+type executorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+	recordResults bool
+	countResults  bool
+}
+
+func newProcessor(runner *query.BenchmarkRunner, hosts string, port int, user, pass string, showExplain bool) (query.Processor, error) {
+	connStr := fmt.Sprintf("host=%s port=%d user=%s password='%s' dbname=%s", hosts, port, user, pass, runner.DatabaseName())
+	connConfig, err := pgx.ParseConfig(connStr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not parse connection config")
+	}
+	return &processor{
+		connCfg: connConfig,
+		opts: &executorOptions{
+			showExplain:   showExplain,
+			debug:         runner.DebugLevel() > 0,
+			printResponse: runner.DoPrintResponses(),
+			recordResults: runner.RecordResults(),
+			countResults:  runner.CountResults(),
+		},
+		runner: runner,
+	}, nil
+}
+
+func (p *processor) Init(workerNumber int) {
+	conn, err := pgx.ConnectConfig(context.Background(), p.connCfg)
+	if err != nil {
+		panic(err)
+	}
+	p.conn = conn
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.CrateDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.showExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.conn.Query(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+	defer rows.Close()
+	var values [][]interface{}
+	count := 0
+	if p.opts.showExplain {
+		fmt.Printf("Explian Query:\n")
+		prettyPrintResponse(rows, tq)
+		fmt.Printf("\n-----------\n\n")
+	} else if p.opts.printResponse || p.opts.recordResults {
+		var cols []string
+		cols, values, err = readRows(rows)
+		if err != nil {
+			return nil, err
+		}
+		if p.opts.printResponse {
+			printRows(cols, values, tq)
+		}
+		count = len(values)
+	} else if p.opts.countResults {
+		for rows.Next() {
+			count++
+		}
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	switch {
+	case p.opts.showExplain:
+	case p.opts.recordResults:
+		p.runner.RecordResult(q, values)
+	case p.opts.countResults:
+		p.runner.RecordResultCount(q, count)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(rows pgx.Rows, q *query.CrateDB) {
+	cols, values, err := readRows(rows)
+	if err != nil {
+		panic(err)
+	}
+	printRows(cols, values, q)
+}
+
+// printRows prints the rows of the response to a Query, see prettyPrintResponse.
+func printRows(cols []string, values [][]interface{}, q *query.CrateDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(cols, values)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+// readRows reads all the rows of r, returning the column names and the values
+// of each row.
+func readRows(r pgx.Rows) ([]string, [][]interface{}, error) {
+	var cols []string
+	for _, fd := range r.FieldDescriptions() {
+		cols = append(cols, string(fd.Name))
+	}
+	var values [][]interface{}
+	for r.Next() {
+		v, err := r.Values()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "error while reading values")
+		}
+		values = append(values, v)
+	}
+	return cols, values, r.Err()
+}
+
+func mapRows(cols []string, values [][]interface{}) []map[string]interface{} {
+	var rows []map[string]interface{}
+	for _, v := range values {
+		row := make(map[string]interface{})
+		for i, column := range cols {
+			row[column] = v[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
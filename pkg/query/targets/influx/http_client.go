@@ -1,6 +1,7 @@
-package main
+package influx
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -55,9 +56,9 @@ func NewHTTPClient(host string) *HTTPClient {
 	}
 }
 
-// Do performs the action specified by the given Query. It uses fasthttp, and
-// tries to minimize heap allocations.
-func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, err error) {
+// Do performs the action specified by the given Query within ctx, returning
+// the response body. It uses fasthttp, and tries to minimize heap allocations.
+func (w *HTTPClient) Do(ctx context.Context, q *query.HTTP, opts *HTTPClientDoOptions) (lag float64, body []byte, err error) {
 	// populate uri from the reusable byte slice:
 	w.uri = w.uri[:0]
 	w.uri = append(w.uri, w.Host...)
@@ -70,27 +71,26 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 	}
 
 	// populate a request with data from the Query:
-	req, err := http.NewRequest(string(q.Method), string(w.uri), nil)
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), string(w.uri), nil)
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("error while creating request: %s", err)
 	}
 
 	// Perform the request while tracking latency:
 	start := time.Now()
 	resp, err := w.client.Do(req)
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("query execution error: %s", err)
 	}
 	defer resp.Body.Close()
 	if resp.StatusCode != http.StatusOK {
-		panic("http request did not return status 200 OK")
+		return 0, nil, fmt.Errorf("non-200 statuscode received: %d", resp.StatusCode)
 	}
 
-	var body []byte
 	body, err = ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		panic(err)
+		return 0, nil, fmt.Errorf("error while reading response body: %s", err)
 	}
 
 	lag = float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
@@ -132,5 +132,5 @@ func (w *HTTPClient) Do(q *query.HTTP, opts *HTTPClientDoOptions) (lag float64,
 		}
 	}
 
-	return lag, err
+	return lag, body, err
 }
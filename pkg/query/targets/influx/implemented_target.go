@@ -0,0 +1,45 @@
+package influx
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &influxTarget{}
+}
+
+type influxTarget struct {
+}
+
+func (t *influxTarget) TargetName() string {
+	return constants.FormatInflux
+}
+
+func (t *influxTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *influxTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:8086", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
+	flagSet.Uint64(flagPrefix+"chunk-response-size", 0, "Number of series to chunk results into. 0 means no chunking.")
+}
+
+func (t *influxTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	csvDaemonUrls := v.GetString("urls")
+	if len(csvDaemonUrls) == 0 {
+		return nil, nil, errors.New("missing 'urls' flag")
+	}
+	daemonUrls := strings.Split(csvDaemonUrls, ",")
+	chunkSize := v.GetUint64("chunk-response-size")
+
+	return func() query.Processor {
+		return newProcessor(runner, daemonUrls, chunkSize)
+	}, func() {}, nil
+}
@@ -0,0 +1,51 @@
+package influx
+
+import (
+	"context"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type processor struct {
+	w          *HTTPClient
+	opts       *HTTPClientDoOptions
+	runner     *query.BenchmarkRunner
+	daemonUrls []string
+	chunkSize  uint64
+}
+
+func newProcessor(runner *query.BenchmarkRunner, daemonUrls []string, chunkSize uint64) query.Processor {
+	return &processor{runner: runner, daemonUrls: daemonUrls, chunkSize: chunkSize}
+}
+
+func (p *processor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+		chunkSize:            p.chunkSize,
+		database:             p.runner.DatabaseName(),
+	}
+	url := p.daemonUrls[workerNumber%len(p.daemonUrls)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.w.Do(ctx, hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	if p.runner.RecordResults() || p.runner.CountResults() {
+		rows, err := resultRows(body)
+		if err != nil {
+			return nil, err
+		}
+		p.runner.RecordResult(q, rows)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
@@ -0,0 +1,126 @@
+package influx
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// response is the JSON response of InfluxDB to a query. Chunked responses are
+// a stream of them.
+type response struct {
+	Results []struct {
+		StatementID int `json:"statement_id"`
+		Series      []struct {
+			Tags   map[string]string `json:"tags"`
+			Values [][]interface{}   `json:"values"`
+		} `json:"series"`
+		Error string `json:"error"`
+	} `json:"results"`
+	Error string `json:"error"`
+}
+
+// statementRows are the rows of the results of a statement, with the number
+// of columns of each that identify it: its tag values and time.
+type statementRows struct {
+	rows    [][]interface{}
+	keyLens []int
+}
+
+// resultRows returns the rows of the series of an InfluxDB response. The tag
+// values of a series, sorted by tag key, come first in each of its rows, as
+// they would be columns of a GROUP BY in SQL. The rows of the statements of a
+// query with several are joined, see joinRows.
+func resultRows(body []byte) ([][]interface{}, error) {
+	var statements []*statementRows
+	byID := map[int]*statementRows{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	for {
+		var resp response
+		if err := dec.Decode(&resp); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+		if resp.Error != "" {
+			return nil, errors.New(resp.Error)
+		}
+		for _, result := range resp.Results {
+			if result.Error != "" {
+				return nil, errors.New(result.Error)
+			}
+			// the results of a statement span several chunks of a chunked
+			// response
+			statement, ok := byID[result.StatementID]
+			if !ok {
+				statement = &statementRows{}
+				byID[result.StatementID] = statement
+				statements = append(statements, statement)
+			}
+			for _, series := range result.Series {
+				keys := make([]string, 0, len(series.Tags))
+				for k := range series.Tags {
+					keys = append(keys, k)
+				}
+				sort.Strings(keys)
+				for _, values := range series.Values {
+					row := make([]interface{}, 0, len(keys)+len(values))
+					for _, k := range keys {
+						row = append(row, series.Tags[k])
+					}
+					statement.rows = append(statement.rows, append(row, values...))
+					statement.keyLens = append(statement.keyLens, len(keys)+1)
+				}
+			}
+		}
+	}
+	switch len(statements) {
+	case 0:
+		return [][]interface{}{}, nil
+	case 1:
+		return statements[0].rows, nil
+	}
+	return joinRows(statements), nil
+}
+
+// joinRows joins the rows of the statements of a query on their tag values and
+// time. InfluxQL cannot join measurements, so the join queries read each one
+// with a statement of their own and the client joins them. A joined row has
+// the tag values and time followed by the values of each statement, and only
+// the rows of the first statement found in every other are kept, as with an
+// inner join in SQL.
+func joinRows(statements []*statementRows) [][]interface{} {
+	others := make([]map[string][]interface{}, len(statements)-1)
+	for i, statement := range statements[1:] {
+		others[i] = make(map[string][]interface{}, len(statement.rows))
+		for j, row := range statement.rows {
+			others[i][rowKey(row[:statement.keyLens[j]])] = row[statement.keyLens[j]:]
+		}
+	}
+
+	joined := [][]interface{}{}
+	first := statements[0]
+rows:
+	for j, row := range first.rows {
+		key := rowKey(row[:first.keyLens[j]])
+		joinedRow := append([]interface{}{}, row...)
+		for _, other := range others {
+			values, ok := other[key]
+			if !ok {
+				continue rows
+			}
+			joinedRow = append(joinedRow, values...)
+		}
+		joined = append(joined, joinedRow)
+	}
+	return joined
+}
+
+// rowKey returns the key of the identifying columns of a row.
+func rowKey(columns []interface{}) string {
+	return fmt.Sprintf("%q", columns)
+}
@@ -0,0 +1,60 @@
+package influx
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestResultRows(t *testing.T) {
+	cases := []struct {
+		desc string
+		body string
+		want string
+	}{
+		{
+			desc: "no series",
+			body: `{"results":[{"statement_id":0}]}`,
+			want: "[]",
+		},
+		{
+			desc: "tags before the values",
+			body: `{"results":[{"statement_id":0,"series":[` +
+				`{"name":"cpu","tags":{"region":"eu","hostname":"host_1"},"values":[["2016-01-01T00:00:00Z",1.5],["2016-01-01T01:00:00Z",2]]}]}]}`,
+			want: "[[host_1 eu 2016-01-01T00:00:00Z 1.5] [host_1 eu 2016-01-01T01:00:00Z 2]]",
+		},
+		{
+			desc: "statements joined on the tags and time",
+			body: `{"results":[` +
+				`{"statement_id":0,"series":[` +
+				`{"name":"cpu","tags":{"hostname":"host_1"},"values":[["2016-01-01T00:00:00Z",1],["2016-01-01T01:00:00Z",2]]},` +
+				`{"name":"cpu","tags":{"hostname":"host_2"},"values":[["2016-01-01T00:00:00Z",3]]}]},` +
+				`{"statement_id":1,"series":[` +
+				`{"name":"mem","tags":{"hostname":"host_2"},"values":[["2016-01-01T00:00:00Z",30]]},` +
+				`{"name":"mem","tags":{"hostname":"host_1"},"values":[["2016-01-01T00:00:00Z",10]]}]}]}`,
+			want: "[[host_1 2016-01-01T00:00:00Z 1 10] [host_2 2016-01-01T00:00:00Z 3 30]]",
+		},
+		{
+			desc: "chunked statements",
+			body: `{"results":[{"statement_id":0,"series":[{"name":"readings","tags":{"name":"truck_1"},"values":[["2016-01-01T00:00:00Z",50]]}],"partial":true}]}` + "\n" +
+				`{"results":[{"statement_id":0,"series":[{"name":"readings","tags":{"name":"truck_1"},"values":[["2016-01-01T01:00:00Z",60]]}]}]}` + "\n" +
+				`{"results":[{"statement_id":1,"series":[{"name":"diagnostics","tags":{"name":"truck_1"},"values":[["2016-01-01T01:00:00Z",0.5,1000],["2016-01-01T00:00:00Z",0.75,1500]]}]}]}` + "\n",
+			want: "[[truck_1 2016-01-01T00:00:00Z 50 0.75 1500] [truck_1 2016-01-01T01:00:00Z 60 0.5 1000]]",
+		},
+	}
+	for _, c := range cases {
+		rows, err := resultRows([]byte(c.body))
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", c.desc, err)
+			continue
+		}
+		if got := fmt.Sprint(rows); got != c.want {
+			t.Errorf("%s: incorrect rows:\ngot\n%s\nwant\n%s", c.desc, got, c.want)
+		}
+	}
+
+	for _, body := range []string{`{"error":"bad query"}`, `{"results":[{"statement_id":1,"error":"bad statement"}]}`, `{`} {
+		if _, err := resultRows([]byte(body)); err == nil {
+			t.Errorf("unexpected lack of error for %s", body)
+		}
+	}
+}
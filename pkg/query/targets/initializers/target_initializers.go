@@ -0,0 +1,67 @@
+package initializers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/query/targets/akumuli"
+	"github.com/timescale/tsbs/pkg/query/targets/cassandra"
+	"github.com/timescale/tsbs/pkg/query/targets/clickhouse"
+	"github.com/timescale/tsbs/pkg/query/targets/cratedb"
+	"github.com/timescale/tsbs/pkg/query/targets/influx"
+	"github.com/timescale/tsbs/pkg/query/targets/mongo"
+	"github.com/timescale/tsbs/pkg/query/targets/questdb"
+	"github.com/timescale/tsbs/pkg/query/targets/siridb"
+	"github.com/timescale/tsbs/pkg/query/targets/timescaledb"
+	"github.com/timescale/tsbs/pkg/query/targets/timestream"
+	"github.com/timescale/tsbs/pkg/query/targets/victoriametrics"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+// SupportedFormats returns the formats queries can be run against.
+func SupportedFormats() []string {
+	return []string{
+		constants.FormatCassandra,
+		constants.FormatClickhouse,
+		constants.FormatInflux,
+		constants.FormatMongo,
+		constants.FormatSiriDB,
+		constants.FormatTimescaleDB,
+		constants.FormatAkumuli,
+		constants.FormatCrateDB,
+		constants.FormatVictoriaMetrics,
+		constants.FormatTimestream,
+		constants.FormatQuestDB,
+	}
+}
+
+func GetTarget(format string) query.ImplementedTarget {
+	switch format {
+	case constants.FormatTimescaleDB:
+		return timescaledb.NewTarget()
+	case constants.FormatAkumuli:
+		return akumuli.NewTarget()
+	case constants.FormatCassandra:
+		return cassandra.NewTarget()
+	case constants.FormatClickhouse:
+		return clickhouse.NewTarget()
+	case constants.FormatCrateDB:
+		return cratedb.NewTarget()
+	case constants.FormatInflux:
+		return influx.NewTarget()
+	case constants.FormatMongo:
+		return mongo.NewTarget()
+	case constants.FormatSiriDB:
+		return siridb.NewTarget()
+	case constants.FormatVictoriaMetrics:
+		return victoriametrics.NewTarget()
+	case constants.FormatTimestream:
+		return timestream.NewTarget()
+	case constants.FormatQuestDB:
+		return questdb.NewTarget()
+	}
+
+	supportedFormatsStr := strings.Join(SupportedFormats(), ",")
+	panic(fmt.Sprintf("Unrecognized format %s, supported: %s", format, supportedFormatsStr))
+}
@@ -0,0 +1,63 @@
+package mongo
+
+import (
+	"context"
+	"encoding/gob"
+	"sync"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func init() {
+	// needed for deserializing the mongo query from gob
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+	gob.Register([]map[string]interface{}{})
+	gob.Register(bson.M{})
+	gob.Register(bson.D{})
+	gob.Register(bson.A{})
+	gob.Register([]bson.M{})
+	gob.Register(time.Time{})
+	gob.Register(primitive.DateTime(0))
+}
+
+func NewTarget() query.ImplementedTarget {
+	return &mongoTarget{}
+}
+
+type mongoTarget struct {
+}
+
+func (t *mongoTarget) TargetName() string {
+	return constants.FormatMongo
+}
+
+func (t *mongoTarget) QueryPool() *sync.Pool {
+	return &query.MongoPool
+}
+
+func (t *mongoTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"url", "mongodb://localhost:27017", "Daemon URL.")
+	flagSet.Duration(flagPrefix+"read-timeout", 300*time.Second, "Timeout value for individual queries")
+}
+
+func (t *mongoTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	opts := options.Client().ApplyURI(v.GetString("url")).SetSocketTimeout(v.GetDuration("read-timeout"))
+	client, err := mongo.Connect(context.Background(), opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return func() query.Processor {
+			return newProcessor(runner, client)
+		}, func() {
+			client.Disconnect(context.Background())
+		}, nil
+}
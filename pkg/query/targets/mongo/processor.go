@@ -0,0 +1,78 @@
+package mongo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type processor struct {
+	collection *mongo.Collection
+	runner     *query.BenchmarkRunner
+	client     *mongo.Client
+}
+
+func newProcessor(runner *query.BenchmarkRunner, client *mongo.Client) query.Processor {
+	return &processor{runner: runner, client: client}
+}
+
+func (p *processor) Init(workerNumber int) {
+	p.collection = p.client.Database(p.runner.DatabaseName()).Collection("point_data")
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	mq := q.(*query.Mongo)
+	start := time.Now().UnixNano()
+
+	cursor, err := p.collection.Aggregate(ctx, mq.Pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.runner.DebugLevel() > 0 {
+		fmt.Println(mq.Pipeline)
+	}
+	cnt := 0
+	var rows [][]interface{}
+	for cursor.Next(ctx) {
+		if p.runner.DoPrintResponses() {
+			fmt.Printf("ID %d: %v\n", q.GetID(), cursor.Current)
+		}
+		if p.runner.RecordResults() {
+			row, err := resultRow(cursor.Current)
+			if err != nil {
+				cursor.Close(context.Background())
+				return nil, err
+			}
+			rows = append(rows, row)
+		}
+		cnt++
+	}
+	if p.runner.DebugLevel() > 0 {
+		fmt.Println(cnt)
+	}
+	err = cursor.Err()
+	if closeErr := cursor.Close(context.Background()); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+	took := time.Now().UnixNano() - start
+	if p.runner.RecordResults() {
+		p.runner.RecordResult(q, rows)
+	} else if p.runner.CountResults() {
+		p.runner.RecordResultCount(q, cnt)
+	}
+	lag := float64(took) / 1e6 // milliseconds
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,44 @@
+package mongo
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+// resultRow returns the values of a result document in field order. Embedded
+// documents, like the _id of a $group stage, are flattened in place.
+func resultRow(doc bson.Raw) ([]interface{}, error) {
+	elems, err := doc.Elements()
+	if err != nil {
+		return nil, err
+	}
+	row := make([]interface{}, 0, len(elems))
+	for _, e := range elems {
+		v := e.Value()
+		switch v.Type {
+		case bsontype.EmbeddedDocument:
+			embedded, err := resultRow(v.Document())
+			if err != nil {
+				return nil, err
+			}
+			row = append(row, embedded...)
+		case bsontype.Double:
+			row = append(row, v.Double())
+		case bsontype.Int32:
+			row = append(row, v.Int32())
+		case bsontype.Int64:
+			row = append(row, v.Int64())
+		case bsontype.String:
+			row = append(row, v.StringValue())
+		case bsontype.DateTime:
+			row = append(row, time.Unix(0, v.DateTime()*int64(time.Millisecond)))
+		case bsontype.Null:
+			row = append(row, nil)
+		default:
+			row = append(row, v.String())
+		}
+	}
+	return row, nil
+}
@@ -0,0 +1,51 @@
+package questdb
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &questTarget{}
+}
+
+type questTarget struct {
+}
+
+func (t *questTarget) TargetName() string {
+	return constants.FormatQuestDB
+}
+
+func (t *questTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *questTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:9000/", "Daemon URLs, comma-separated. Will be used in a round-robin fashion.")
+}
+
+func (t *questTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	csvDaemonUrls := v.GetString("urls")
+	if len(csvDaemonUrls) == 0 {
+		return nil, nil, errors.New("missing 'urls' flag")
+	}
+	daemonUrls := strings.Split(csvDaemonUrls, ",")
+
+	// Add an index to the hostname column in the cpu table
+	r, err := execQuery(daemonUrls[0], "show columns from cpu")
+	if err == nil && r.Count != 0 {
+		_, err := execQuery(daemonUrls[0], "ALTER TABLE cpu ALTER COLUMN hostname ADD INDEX")
+		if err == nil {
+			fmt.Println("Added index to hostname column of cpu table")
+		}
+	}
+
+	return func() query.Processor { return newProcessor(runner, daemonUrls) }, func() {}, nil
+}
@@ -0,0 +1,113 @@
+package questdb
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+type processor struct {
+	w          *HTTPClient
+	opts       *HTTPClientDoOptions
+	runner     *query.BenchmarkRunner
+	daemonUrls []string
+}
+
+func newProcessor(runner *query.BenchmarkRunner, daemonUrls []string) query.Processor {
+	return &processor{runner: runner, daemonUrls: daemonUrls}
+}
+
+func (p *processor) Init(workerNumber int) {
+	p.opts = &HTTPClientDoOptions{
+		Debug:                p.runner.DebugLevel(),
+		PrettyPrintResponses: p.runner.DoPrintResponses(),
+	}
+	url := p.daemonUrls[workerNumber%len(p.daemonUrls)]
+	p.w = NewHTTPClient(url)
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.w.Do(ctx, hq, p.opts)
+	if err != nil {
+		return nil, err
+	}
+	if p.runner.RecordResults() || p.runner.CountResults() {
+		rows, err := resultRows(body)
+		if err != nil {
+			return nil, err
+		}
+		p.runner.RecordResult(q, rows)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+type QueryResponseColumns struct {
+	Name string
+	Type string
+}
+
+type QueryResponse struct {
+	Query   string
+	Columns []QueryResponseColumns
+	Dataset []interface{}
+	Count   int
+	Error   string
+}
+
+// resultRows returns the rows of the dataset of a QuestDB response.
+func resultRows(body []byte) ([][]interface{}, error) {
+	var qr QueryResponse
+	if err := json.Unmarshal(body, &qr); err != nil {
+		return nil, err
+	}
+	if qr.Error != "" {
+		return nil, errors.New(qr.Error)
+	}
+	rows := make([][]interface{}, 0, len(qr.Dataset))
+	for _, r := range qr.Dataset {
+		row, ok := r.([]interface{})
+		if !ok {
+			return nil, errors.New("malformed row in response dataset")
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+func execQuery(uriRoot string, query string) (QueryResponse, error) {
+	var qr QueryResponse
+	if strings.HasSuffix(uriRoot, "/") {
+		uriRoot = uriRoot[:len(uriRoot)-1]
+	}
+	uriRoot = uriRoot + "/exec?query=" + url.QueryEscape(query)
+	resp, err := http.Get(uriRoot)
+	if err != nil {
+		return qr, err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return qr, err
+	}
+	err = json.Unmarshal(body, &qr)
+	if err != nil {
+		return qr, err
+	}
+	if qr.Error != "" {
+		return qr, errors.New(qr.Error)
+	}
+	return qr, nil
+}
@@ -0,0 +1,85 @@
+package siridb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	siridb "github.com/SiriDB/go-siridb-connector"
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &siriTarget{}
+}
+
+type siriTarget struct {
+}
+
+func (t *siriTarget) TargetName() string {
+	return constants.FormatSiriDB
+}
+
+func (t *siriTarget) QueryPool() *sync.Pool {
+	return &query.SiriDBPool
+}
+
+func (t *siriTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"dbuser", "iris", "Username to enter SiriDB")
+	flagSet.String(flagPrefix+"dbpass", "siri", "Password to enter SiriDB")
+	flagSet.String(flagPrefix+"hosts", "localhost:9000", "Comma separated list of SiriDB hosts in a cluster.")
+	flagSet.Uint64(flagPrefix+"scale", 8, "Scaling variable (Must be the equal to the scalevar used for data generation).")
+	flagSet.Uint64(flagPrefix+"query-limit", 1000000, "Changes the maximum points which can be returned by a select query.")
+	flagSet.Int(flagPrefix+"write-timeout", 10, "Write timeout.")
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+}
+
+func (t *siriTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	if runner.DigestResults() {
+		return nil, nil, errors.New("result digests are not supported by SiriDB")
+	}
+	opts := &options{
+		scale:        v.GetUint64("scale"),
+		queryLimit:   v.GetUint64("query-limit"),
+		writeTimeout: v.GetInt("write-timeout"),
+		showExplain:  v.GetBool("show-explain"),
+		runner:       runner,
+	}
+
+	if opts.showExplain {
+		runner.SetLimit(1)
+	}
+
+	hostlist := [][]interface{}{}
+	listhosts := strings.Split(v.GetString("hosts"), ",")
+
+	for _, hostport := range listhosts {
+		x := strings.Split(hostport, ":")
+		host := x[0]
+		port, err := strconv.ParseInt(x[1], 10, 0)
+		if err != nil {
+			return nil, nil, err
+		}
+		hostlist = append(hostlist, []interface{}{host, int(port)})
+	}
+
+	opts.connector = siridb.NewClient(
+		v.GetString("dbuser"), // username
+		v.GetString("dbpass"), // password
+		runner.DatabaseName(), // database
+		hostlist,              // siridb server(s)
+		nil,                   // optional log channel
+	)
+
+	opts.connector.Connect()
+	opts.changeQueryLimit()
+	opts.createGroups()
+
+	return func() query.Processor {
+		return newProcessor(opts)
+	}, opts.connector.Close, nil
+}
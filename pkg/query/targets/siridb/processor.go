@@ -0,0 +1,123 @@
+package siridb
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	siridb "github.com/SiriDB/go-siridb-connector"
+	"github.com/timescale/tsbs/cmd/tsbs_generate_queries/uses/devops"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// options are the settings and connection shared by the processors of all workers
+type options struct {
+	writeTimeout int
+	showExplain  bool
+	scale        uint64
+	queryLimit   uint64
+	connector    *siridb.Client
+	runner       *query.BenchmarkRunner
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+}
+
+type processor struct {
+	opts    *queryExecutorOptions
+	options *options
+}
+
+func newProcessor(o *options) query.Processor { return &processor{options: o} }
+
+// Changes the maximum points which can be returned by a select query. The default
+// and recommended value is set to one million points. This value is chosen to
+// prevent a single query for taking to much memory and ensures SiriDB can respond
+// to almost any query in a reasonable amount of time.
+func (o *options) changeQueryLimit() {
+	qry := fmt.Sprintf("alter database set select_points_limit %d", o.queryLimit)
+
+	if o.connector.IsConnected() {
+		if _, err := o.connector.Query(qry, uint16(o.writeTimeout)); err != nil {
+			log.Fatal(err)
+		}
+	} else {
+		log.Fatal("not even a single server is connected...")
+	}
+}
+
+// createGroups makes groups representing regular expression to enhance performance
+func (o *options) createGroups() {
+	created := true
+	metrics := devops.GetAllCPUMetrics()
+	siriql := make([]string, 0, 2048)
+	for _, m := range metrics {
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s$/", m, m))
+	}
+
+	var n uint64
+	for n = 0; n < o.scale; n++ {
+		host := fmt.Sprintf("host_%d", n)
+		siriql = append(siriql, fmt.Sprintf("create group `%s` for /.*%s,.*/", host, host))
+	}
+	siriql = append(siriql, fmt.Sprintf("create group `cpu` for /.*^cpu.*/"))
+	for _, qry := range siriql {
+		if o.connector.IsConnected() {
+			if _, err := o.connector.Query(qry, uint16(o.writeTimeout)); err != nil {
+				created = false
+			}
+		} else {
+			log.Fatal("not even a single server is connected...")
+		}
+	}
+	if created {
+		time.Sleep(6 * time.Second) // because the groups are created in a seperate thread every 2 seconds.
+	}
+}
+
+func (p *processor) Init(numWorker int) {
+	p.opts = &queryExecutorOptions{
+		showExplain:   p.options.showExplain,
+		debug:         p.options.runner.DebugLevel() > 0,
+		printResponse: p.options.runner.DoPrintResponses(),
+	}
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.SiriDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	connector := p.options.connector
+	if !connector.IsConnected() {
+		return nil, errors.New("not even a single server is connected...")
+	}
+	res, err := connector.Query(qry, uint16(p.options.writeTimeout))
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+
+	if p.opts.printResponse {
+		fmt.Println("\n", res)
+	}
+
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,70 @@
+package timescaledb
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+const pgxDriver = "pgx" // default driver
+const pqDriver = "postgres"
+
+func NewTarget() query.ImplementedTarget {
+	return &timescaleTarget{}
+}
+
+type timescaleTarget struct {
+}
+
+func (t *timescaleTarget) TargetName() string {
+	return constants.FormatTimescaleDB
+}
+
+func (t *timescaleTarget) QueryPool() *sync.Pool {
+	return &query.TimescaleDBPool
+}
+
+func (t *timescaleTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"postgres", "host=postgres user=postgres sslmode=disable",
+		"String of additional PostgreSQL connection parameters, e.g., 'sslmode=disable'. Parameters for host and database will be ignored.")
+	flagSet.String(flagPrefix+"hosts", "localhost", "Comma separated list of PostgreSQL hosts (pass multiple values for sharding reads on a multi-node setup)")
+	flagSet.String(flagPrefix+"user", "postgres", "User to connect to PostgreSQL as")
+	flagSet.String(flagPrefix+"pass", "", "Password for the user connecting to PostgreSQL (leave blank if not password protected)")
+	flagSet.String(flagPrefix+"port", "5432", "Which port to connect to on the database host")
+
+	flagSet.Bool(flagPrefix+"show-explain", false, "Print out the EXPLAIN output for sample query")
+	flagSet.Bool(flagPrefix+"force-text-format", false, "Send/receive data in text format")
+}
+
+func (t *timescaleTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	opts := &options{
+		postgresConnect: v.GetString("postgres"),
+		user:            v.GetString("user"),
+		pass:            v.GetString("pass"),
+		port:            v.GetString("port"),
+		showExplain:     v.GetBool("show-explain"),
+		forceTextFormat: v.GetBool("force-text-format"),
+		runner:          runner,
+	}
+
+	if opts.showExplain {
+		runner.SetLimit(1)
+	}
+
+	if opts.forceTextFormat {
+		opts.driver = pqDriver
+	} else {
+		opts.driver = pgxDriver
+	}
+
+	// Parse comma separated string of hosts and put in a slice (for multi-node setups)
+	for _, host := range strings.Split(v.GetString("hosts"), ",") {
+		opts.hostList = append(opts.hostList, host)
+	}
+
+	return func() query.Processor { return newProcessor(opts) }, func() {}, nil
+}
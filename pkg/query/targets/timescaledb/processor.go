@@ -0,0 +1,210 @@
+package timescaledb
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	_ "github.com/jackc/pgx/v4/stdlib"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+// options are the settings shared by the processors of all workers
+type options struct {
+	postgresConnect string
+	hostList        []string
+	user            string
+	pass            string
+	port            string
+	showExplain     bool
+	forceTextFormat bool
+	driver          string
+	runner          *query.BenchmarkRunner
+}
+
+// Get the connection string for a connection to PostgreSQL.
+
+// If we're running queries against multiple nodes we need to balance the queries
+// across replicas. Each worker is assigned a sequence number -- we'll use that
+// to evenly distribute hosts to worker connections
+func (o *options) getConnectString(workerNumber int) string {
+	// User might be passing in host=hostname the connect string out of habit which may override the
+	// multi host configuration. Same for dbname= and user=. This sanitizes that.
+	re := regexp.MustCompile(`(host|dbname|user)=\S*\b`)
+	connectString := re.ReplaceAllString(o.postgresConnect, "")
+
+	// Round robin the host/worker assignment by assigning a host based on workerNumber % totalNumberOfHosts
+	host := o.hostList[workerNumber%len(o.hostList)]
+	connectString = fmt.Sprintf("host=%s dbname=%s user=%s %s", host, o.runner.DatabaseName(), o.user, connectString)
+
+	// For optional parameters, ensure they exist then interpolate them into the connectString
+	if len(o.port) > 0 {
+		connectString = fmt.Sprintf("%s port=%s", connectString, o.port)
+	}
+	if len(o.pass) > 0 {
+		connectString = fmt.Sprintf("%s password=%s", connectString, o.pass)
+	}
+	if o.forceTextFormat {
+		connectString = fmt.Sprintf("%s disable_prepared_binary_result=yes binary_parameters=no", connectString)
+	}
+
+	return connectString
+}
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(cols []string, values [][]interface{}, q *query.TimescaleDB) {
+	resp := make(map[string]interface{})
+	resp["query"] = string(q.SqlQuery)
+	resp["results"] = mapRows(cols, values)
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+// readRows reads all the rows of r, returning the column names and the values
+// of each row.
+func readRows(r *sql.Rows) ([]string, [][]interface{}, error) {
+	cols, err := r.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+	values := [][]interface{}{}
+	for r.Next() {
+		row := make([]interface{}, len(cols))
+		dest := make([]interface{}, len(cols))
+		for i := range dest {
+			dest[i] = &row[i]
+		}
+
+		if err := r.Scan(dest...); err != nil {
+			return nil, nil, errors.Wrap(err, "error while reading values")
+		}
+		values = append(values, row)
+	}
+	return cols, values, r.Err()
+}
+
+func mapRows(cols []string, values [][]interface{}) []map[string]interface{} {
+	rows := []map[string]interface{}{}
+	for _, v := range values {
+		row := make(map[string]interface{})
+		for i, column := range cols {
+			row[column] = v[i]
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+	recordResults bool
+	countResults  bool
+}
+
+type processor struct {
+	db      *sql.DB
+	opts    *queryExecutorOptions
+	options *options
+}
+
+func newProcessor(o *options) query.Processor { return &processor{options: o} }
+
+func (p *processor) Init(workerNumber int) {
+	db, err := sql.Open(p.options.driver, p.options.getConnectString(workerNumber))
+	if err != nil {
+		panic(err)
+	}
+	p.db = db
+	p.opts = &queryExecutorOptions{
+		showExplain:   p.options.showExplain,
+		debug:         p.options.runner.DebugLevel() > 0,
+		printResponse: p.options.runner.DoPrintResponses(),
+		recordResults: p.options.runner.RecordResults(),
+		countResults:  p.options.runner.CountResults(),
+	}
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, isWarm bool) ([]*query.Stat, error) {
+	// No need to run again for EXPLAIN
+	if isWarm && p.opts.showExplain {
+		return nil, nil
+	}
+	tq := q.(*query.TimescaleDB)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+	if p.opts.showExplain {
+		qry = "EXPLAIN ANALYZE " + qry
+	}
+	rows, err := p.db.QueryContext(ctx, qry)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.opts.debug {
+		fmt.Println(qry)
+	}
+	var values [][]interface{}
+	if p.opts.showExplain {
+		text := ""
+		for rows.Next() {
+			var s string
+			if err2 := rows.Scan(&s); err2 != nil {
+				panic(err2)
+			}
+			text += s + "\n"
+		}
+		fmt.Printf("%s\n\n%s\n-----\n\n", qry, text)
+	} else if p.opts.printResponse || p.opts.recordResults {
+		var cols []string
+		cols, values, err = readRows(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if p.opts.printResponse {
+			prettyPrintResponse(cols, values, tq)
+		}
+	}
+	// Fetching all the rows to confirm that the query is fully completed.
+	count := len(values)
+	for rows.Next() {
+		count++
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+
+	// digesting the result is not part of the query time
+	switch {
+	case p.opts.showExplain:
+	case p.opts.recordResults:
+		p.options.runner.RecordResult(q, values)
+	case p.opts.countResults:
+		p.options.runner.RecordResultCount(q, count)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,39 @@
+package timestream
+
+import (
+	"sync"
+	"time"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &timestreamTarget{}
+}
+
+type timestreamTarget struct {
+}
+
+func (t *timestreamTarget) TargetName() string {
+	return constants.FormatTimestream
+}
+
+func (t *timestreamTarget) QueryPool() *sync.Pool {
+	return &query.TimestreamPool
+}
+
+func (t *timestreamTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"aws-region", "us-east-1", "Region where the database is")
+	flagSet.Duration(flagPrefix+"query-timeout", time.Minute, "Configuration for aws sdk client to timeout after")
+}
+
+func (t *timestreamTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	awsRegion := v.GetString("aws-region")
+	queryTimeout := v.GetDuration("query-timeout")
+	return func() query.Processor {
+		return newProcessor(runner, awsRegion, queryTimeout)
+	}, func() {}, nil
+}
@@ -0,0 +1,149 @@
+package timestream
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/aws/aws-sdk-go/service/timestreamquery"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/timestream"
+)
+
+// prettyPrintResponse prints a Query and its response in JSON format with two
+// keys: 'query' which has a value of the SQL used to generate the second key
+// 'results' which is an array of each row in the return set.
+func prettyPrintResponse(qry string, page *timestreamquery.QueryOutput, pageNum int) {
+	resp := make(map[string]interface{})
+	resp["query"] = qry
+	resp["results"] = mapRows(page)
+	resp["page"] = pageNum
+
+	line, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Println(string(line) + "\n")
+}
+
+func mapRows(page *timestreamquery.QueryOutput) []map[string]string {
+	var rows []map[string]string
+	cols := page.ColumnInfo
+	for _, row := range page.Rows {
+		rowAsMap := make(map[string]string)
+		for i, val := range row.Data {
+			colName := cols[i].Name
+			rowAsMap[*colName] = val.String()
+		}
+
+		rows = append(rows, rowAsMap)
+	}
+	return rows
+}
+
+// resultRows returns the scalar values of the rows of a page, or null for the
+// values that are not scalars.
+func resultRows(page *timestreamquery.QueryOutput) [][]interface{} {
+	rows := make([][]interface{}, 0, len(page.Rows))
+	for _, r := range page.Rows {
+		row := make([]interface{}, 0, len(r.Data))
+		for _, d := range r.Data {
+			if d.ScalarValue != nil {
+				row = append(row, *d.ScalarValue)
+			} else {
+				row = append(row, nil)
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+type queryExecutorOptions struct {
+	showExplain   bool
+	debug         bool
+	printResponse bool
+	recordResults bool
+	countResults  bool
+}
+
+type processor struct {
+	_opts        *queryExecutorOptions
+	_readSvc     *timestreamquery.TimestreamQuery
+	runner       *query.BenchmarkRunner
+	awsRegion    string
+	queryTimeout time.Duration
+}
+
+func newProcessor(runner *query.BenchmarkRunner, awsRegion string, queryTimeout time.Duration) query.Processor {
+	return &processor{runner: runner, awsRegion: awsRegion, queryTimeout: queryTimeout}
+}
+
+func (p *processor) Init(_ int) {
+	awsSession, err := timestream.OpenAWSSession(&p.awsRegion, p.queryTimeout)
+	if err != nil {
+		panic("could not open aws session")
+	}
+	p._readSvc = timestreamquery.New(awsSession)
+	p._opts = &queryExecutorOptions{
+		debug:         p.runner.DebugLevel() > 0,
+		printResponse: p.runner.DoPrintResponses(),
+		recordResults: p.runner.RecordResults(),
+		countResults:  p.runner.CountResults(),
+	}
+}
+
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	tq := q.(*query.Timestream)
+
+	start := time.Now()
+	qry := string(tq.SqlQuery)
+
+	if p._opts.debug {
+		fmt.Println(qry)
+	}
+
+	queryInput := &timestreamquery.QueryInput{
+		QueryString: &qry,
+	}
+	totalRows := 0
+	pageNum := 1
+	var rows [][]interface{}
+	err := p._readSvc.QueryPagesWithContext(ctx, queryInput,
+		func(page *timestreamquery.QueryOutput, lastPage bool) bool {
+			// process query response
+			// making sure all the returned data is read
+			totalRows += len(page.Rows)
+			if p._opts.printResponse {
+				prettyPrintResponse(qry, page, pageNum)
+			}
+			if p._opts.recordResults {
+				rows = append(rows, resultRows(page)...)
+			}
+			pageNum++
+			// return true to continue to next page
+			return true
+		})
+	if err != nil {
+		return nil, err
+	}
+	if p._opts.debug {
+		fmt.Printf("Total rows: %d\n", totalRows)
+	}
+	took := float64(time.Since(start).Nanoseconds()) / 1e6
+	if p._opts.recordResults {
+		p.runner.RecordResult(q, rows)
+	} else if p._opts.countResults {
+		p.runner.RecordResultCount(q, totalRows)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), took)
+
+	return []*query.Stat{stat}, err
+}
@@ -0,0 +1,43 @@
+package victoriametrics
+
+import (
+	"errors"
+	"strings"
+	"sync"
+
+	"github.com/blagojts/viper"
+	"github.com/spf13/pflag"
+	"github.com/timescale/tsbs/pkg/query"
+	"github.com/timescale/tsbs/pkg/targets/constants"
+)
+
+func NewTarget() query.ImplementedTarget {
+	return &vmTarget{}
+}
+
+type vmTarget struct {
+}
+
+func (t *vmTarget) TargetName() string {
+	return constants.FormatVictoriaMetrics
+}
+
+func (t *vmTarget) QueryPool() *sync.Pool {
+	return &query.HTTPPool
+}
+
+func (t *vmTarget) TargetSpecificFlags(flagPrefix string, flagSet *pflag.FlagSet) {
+	flagSet.String(flagPrefix+"urls", "http://localhost:8428",
+		"Comma-separated list of VictoriaMetrics ingestion URLs(single-node or VMSelect)")
+}
+
+func (t *vmTarget) ProcessorFactory(runner *query.BenchmarkRunner, v *viper.Viper) (query.ProcessorCreate, func(), error) {
+	urls := v.GetString("urls")
+	if len(urls) == 0 {
+		return nil, nil, errors.New("missing `urls` flag")
+	}
+	vmURLs := strings.Split(urls, ",")
+	return func() query.Processor {
+		return newProcessor(runner, vmURLs)
+	}, func() {}, nil
+}
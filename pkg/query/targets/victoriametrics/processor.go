@@ -0,0 +1,95 @@
+package victoriametrics
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/timescale/tsbs/pkg/query"
+)
+
+func newProcessor(runner *query.BenchmarkRunner, vmURLs []string) query.Processor {
+	return &processor{runner: runner, vmURLs: vmURLs}
+}
+
+// query.Processor interface implementation
+type processor struct {
+	url string
+
+	prettyPrintResponses bool
+
+	runner *query.BenchmarkRunner
+	vmURLs []string
+}
+
+// query.Processor interface implementation
+func (p *processor) Init(workerNum int) {
+	p.url = p.vmURLs[workerNum%len(p.vmURLs)]
+	p.prettyPrintResponses = p.runner.DoPrintResponses()
+}
+
+// query.Processor interface implementation
+func (p *processor) ProcessQuery(q query.Query, isWarm bool) ([]*query.Stat, error) {
+	return p.ProcessQueryContext(context.Background(), q, isWarm)
+}
+
+// query.ContextProcessor interface implementation
+func (p *processor) ProcessQueryContext(ctx context.Context, q query.Query, _ bool) ([]*query.Stat, error) {
+	hq := q.(*query.HTTP)
+	lag, body, err := p.do(ctx, hq)
+	if err != nil {
+		return nil, err
+	}
+	if p.runner.RecordResults() || p.runner.CountResults() {
+		rows, err := resultRows(body)
+		if err != nil {
+			return nil, err
+		}
+		p.runner.RecordResult(q, rows)
+	}
+	stat := query.GetStat()
+	stat.Init(q.HumanLabelName(), lag)
+	return []*query.Stat{stat}, nil
+}
+
+func (p *processor) do(ctx context.Context, q *query.HTTP) (float64, []byte, error) {
+	// populate a request with data from the Query:
+	req, err := http.NewRequestWithContext(ctx, string(q.Method), p.url+string(q.Path), nil)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error while creating request: %s", err)
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, fmt.Errorf("query execution error: %s", err)
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error while reading response body: %s", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, nil, fmt.Errorf("non-200 statuscode received: %d; Body: %s", resp.StatusCode, string(body))
+	}
+	lag := float64(time.Since(start).Nanoseconds()) / 1e6 // milliseconds
+
+	// Pretty print JSON responses, if applicable:
+	if p.prettyPrintResponses {
+		var pretty bytes.Buffer
+		prefix := fmt.Sprintf("ID %d: ", q.GetID())
+		if err := json.Indent(&pretty, body, prefix, "  "); err != nil {
+			return lag, nil, err
+		}
+		_, err = fmt.Fprintf(os.Stderr, "%s%s\n", prefix, pretty.Bytes())
+		if err != nil {
+			return lag, nil, err
+		}
+	}
+	return lag, body, nil
+}
@@ -0,0 +1,67 @@
+package victoriametrics
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"time"
+)
+
+// response is the Prometheus-compatible JSON response of VictoriaMetrics to
+// a query.
+type response struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Data   struct {
+		Result []struct {
+			Metric map[string]string `json:"metric"`
+			Value  []interface{}     `json:"value"`  // instant vector
+			Values [][]interface{}   `json:"values"` // range matrix
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// resultRows returns a row per sample of a VictoriaMetrics response: the label
+// values of its series sorted by label name, but the metric name, followed by
+// its timestamp and value.
+func resultRows(body []byte) ([][]interface{}, error) {
+	var resp response
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	if resp.Status != "success" {
+		return nil, errors.New(resp.Error)
+	}
+	rows := [][]interface{}{}
+	for _, series := range resp.Data.Result {
+		keys := make([]string, 0, len(series.Metric))
+		for k := range series.Metric {
+			if k != "__name__" {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		samples := series.Values
+		if series.Value != nil {
+			samples = append(samples, series.Value)
+		}
+		for _, sample := range samples {
+			if len(sample) != 2 {
+				return nil, errors.New("malformed sample in response")
+			}
+			row := make([]interface{}, 0, len(keys)+2)
+			for _, k := range keys {
+				row = append(row, series.Metric[k])
+			}
+			if ts, ok := sample[0].(float64); ok {
+				// timestamps are in seconds, with millisecond precision
+				row = append(row, time.Unix(0, int64(math.Round(ts*1e3))*int64(time.Millisecond)))
+			} else {
+				row = append(row, sample[0])
+			}
+			rows = append(rows, append(row, sample[1]))
+		}
+	}
+	return rows, nil
+}
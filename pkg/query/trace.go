@@ -0,0 +1,193 @@
+package query
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Formats of the per-query trace
+const (
+	TraceFormatJSONL = "jsonl"
+	TraceFormatCSV   = "csv"
+)
+
+// traceBufferSize is the number of trace records that can wait to be written
+// before new ones are dropped.
+const traceBufferSize = 64 * 1024
+
+var traceCSVHeader = []string{"id", "loop", "label", "worker", "start", "latencyMs", "warm", "rows", "error"}
+
+// TraceRecord is one execution of a query, as written to the trace file. A
+// query that is retried or prewarmed has a record for each execution.
+type TraceRecord struct {
+	ID uint64 `json:"id"`
+	// Loop is the number of the loop over the query file, from 0, that the
+	// query was sent in
+	Loop      uint64    `json:"loop"`
+	Label     string    `json:"label"`
+	Worker    int       `json:"worker"`
+	Start     time.Time `json:"start"`
+	LatencyMs float64   `json:"latencyMs"`
+	Warm      bool      `json:"warm"`
+	// Rows is the number of result rows, -1 if the target does not report them
+	Rows  int    `json:"rows"`
+	Error string `json:"error,omitempty"`
+}
+
+// tracer writes a TraceRecord for every query execution. Records are written
+// by a separate goroutine so the workers are never blocked on the trace file;
+// if the writer falls behind by more than traceBufferSize records, the new
+// ones are dropped and counted.
+type tracer struct {
+	c       chan *TraceRecord
+	done    chan struct{}
+	w       *bufio.Writer
+	closer  io.Closer
+	write   func(*TraceRecord) error
+	flush   func() error
+	dropped uint64
+
+	mu   sync.Mutex
+	rows map[uint64]int // result rows recorded by the processors, per ID of the query in flight
+
+	// loopOf returns the loop over the query file of the query with the
+	// given ID, the loop is 0 if nil
+	loopOf func(id uint64) uint64
+}
+
+// newTracer returns a tracer writing format records to w, closed with closer.
+func newTracer(w io.Writer, closer io.Closer, format string) (*tracer, error) {
+	t := &tracer{
+		c:      make(chan *TraceRecord, traceBufferSize),
+		done:   make(chan struct{}),
+		w:      bufio.NewWriter(w),
+		closer: closer,
+		rows:   map[uint64]int{},
+	}
+	switch format {
+	case TraceFormatJSONL:
+		enc := json.NewEncoder(t.w)
+		t.write = func(r *TraceRecord) error { return enc.Encode(r) }
+		t.flush = t.w.Flush
+	case TraceFormatCSV:
+		cw := csv.NewWriter(t.w)
+		if err := cw.Write(traceCSVHeader); err != nil {
+			return nil, err
+		}
+		t.write = func(r *TraceRecord) error { return cw.Write(r.csvRecord()) }
+		t.flush = func() error {
+			cw.Flush()
+			if err := cw.Error(); err != nil {
+				return err
+			}
+			return t.w.Flush()
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace format '%s', valid: %s, %s", format, TraceFormatJSONL, TraceFormatCSV)
+	}
+	go t.run()
+	return t, nil
+}
+
+// newFileTracer returns a tracer writing format records to the file fileName.
+func newFileTracer(fileName, format string) (*tracer, error) {
+	f, err := os.Create(fileName)
+	if err != nil {
+		return nil, err
+	}
+	t, err := newTracer(f, f, format)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *TraceRecord) csvRecord() []string {
+	return []string{
+		strconv.FormatUint(r.ID, 10),
+		strconv.FormatUint(r.Loop, 10),
+		r.Label,
+		strconv.Itoa(r.Worker),
+		r.Start.UTC().Format(time.RFC3339Nano),
+		strconv.FormatFloat(r.LatencyMs, 'f', -1, 64),
+		strconv.FormatBool(r.Warm),
+		strconv.Itoa(r.Rows),
+		r.Error,
+	}
+}
+
+func (t *tracer) run() {
+	defer close(t.done)
+	for r := range t.c {
+		if err := t.write(r); err != nil {
+			panic(fmt.Sprintf("could not write query trace: %v", err))
+		}
+	}
+	if err := t.flush(); err != nil {
+		panic(fmt.Sprintf("could not write query trace: %v", err))
+	}
+}
+
+// setRows records the number of result rows of q, reported by its processor.
+// The rows are kept by query ID rather than by q itself, as queries are
+// pooled and reused once done.
+func (t *tracer) setRows(q Query, rows int) {
+	t.mu.Lock()
+	t.rows[q.GetID()] = rows
+	t.mu.Unlock()
+}
+
+// trace queues the record of an execution of q by worker, which started at
+// start and failed with err, if not nil.
+func (t *tracer) trace(q Query, worker int, start time.Time, took time.Duration, isWarm bool, err error) {
+	t.mu.Lock()
+	rows, ok := t.rows[q.GetID()]
+	delete(t.rows, q.GetID())
+	t.mu.Unlock()
+	if !ok {
+		rows = -1
+	}
+
+	r := &TraceRecord{
+		ID:        q.GetID(),
+		Label:     string(q.HumanLabelName()),
+		Worker:    worker,
+		Start:     start.UTC(),
+		LatencyMs: float64(took.Nanoseconds()) / 1e6,
+		Warm:      isWarm,
+		Rows:      rows,
+	}
+	if t.loopOf != nil {
+		r.Loop = t.loopOf(r.ID)
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+	select {
+	case t.c <- r:
+	default:
+		atomic.AddUint64(&t.dropped, 1)
+	}
+}
+
+// close writes the queued records and closes the trace file.
+func (t *tracer) close() error {
+	close(t.c)
+	<-t.done
+	if dropped := atomic.LoadUint64(&t.dropped); dropped > 0 {
+		fmt.Fprintf(os.Stderr, "query trace fell behind, %d records dropped\n", dropped)
+	}
+	if t.closer != nil {
+		return t.closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,165 @@
+package query
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestTracer(t *testing.T) {
+	start := time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC)
+	traceQueries := func(tr *tracer) {
+		q := &testQuery{HumanLabel: []byte("q")}
+		q.SetID(4)
+		// the query file has 3 queries, the fifth query is of the second loop
+		tr.loopOf = func(id uint64) uint64 { return id / 3 }
+		tr.setRows(q, 5)
+		tr.trace(q, 2, start, 1500*time.Microsecond, false, nil)
+		// the rows of an execution are not carried over to the next one
+		tr.trace(q, 2, start, time.Millisecond, true, errors.New("failed"))
+		if err := tr.close(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	tr, err := newTracer(&buf, nil, TraceFormatJSONL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	traceQueries(tr)
+	var records []TraceRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("could not parse trace record %s: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	want := []TraceRecord{
+		{ID: 4, Loop: 1, Label: "q", Worker: 2, Start: start, LatencyMs: 1.5, Rows: 5},
+		{ID: 4, Loop: 1, Label: "q", Worker: 2, Start: start, LatencyMs: 1, Warm: true, Rows: -1, Error: "failed"},
+	}
+	if len(records) != len(want) {
+		t.Fatalf("incorrect number of records: got %d want %d", len(records), len(want))
+	}
+	for i, r := range records {
+		if r != want[i] {
+			t.Errorf("incorrect record %d: got %+v want %+v", i, r, want[i])
+		}
+	}
+
+	buf.Reset()
+	tr, err = newTracer(&buf, nil, TraceFormatCSV)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	traceQueries(tr)
+	lines, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("could not parse csv trace: %v", err)
+	}
+	wantLines := [][]string{
+		traceCSVHeader,
+		{"4", "1", "q", "2", "2016-01-01T00:00:00Z", "1.5", "false", "5", ""},
+		{"4", "1", "q", "2", "2016-01-01T00:00:00Z", "1", "true", "-1", "failed"},
+	}
+	if len(lines) != len(wantLines) {
+		t.Fatalf("incorrect number of csv lines: got %d want %d", len(lines), len(wantLines))
+	}
+	for i, l := range lines {
+		for j := range l {
+			if l[j] != wantLines[i][j] {
+				t.Errorf("incorrect csv line %d: got %v want %v", i, l, wantLines[i])
+				break
+			}
+		}
+	}
+
+	if _, err = newTracer(&buf, nil, "xml"); err == nil {
+		t.Errorf("unexpected lack of error for unknown format")
+	}
+}
+
+func TestTracerResultCounts(t *testing.T) {
+	b := &BenchmarkRunner{BenchmarkRunnerConfig: BenchmarkRunnerConfig{TraceFile: "trace.jsonl"}}
+	if b.RecordResults() || !b.CountResults() {
+		t.Fatalf("incorrect result options with only a trace: record %t, count %t", b.RecordResults(), b.CountResults())
+	}
+
+	var buf bytes.Buffer
+	var err error
+	b.tracer, err = newTracer(&buf, nil, TraceFormatJSONL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// queries are pooled, so the rows of a query must follow its ID rather
+	// than the object that carried it
+	q := &testQuery{HumanLabel: []byte("q")}
+	q.SetID(1)
+	b.RecordResultCount(q, 3)
+	reused := &testQuery{HumanLabel: []byte("q")}
+	reused.SetID(1)
+	b.tracer.trace(reused, 0, time.Time{}, time.Millisecond, false, nil)
+	q.SetID(2)
+	b.tracer.trace(q, 0, time.Time{}, time.Millisecond, false, nil)
+	if err := b.tracer.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var rows []int
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("could not parse trace record %s: %v", scanner.Text(), err)
+		}
+		rows = append(rows, r.Rows)
+	}
+	if len(rows) != 2 || rows[0] != 3 || rows[1] != -1 {
+		t.Errorf("incorrect result rows: got %v want [3 -1]", rows)
+	}
+}
+
+func TestRunQueryTraceLatency(t *testing.T) {
+	b, _ := newFailureTestRunner(ErrorPolicyRetry, 1, 0)
+	var buf bytes.Buffer
+	var err error
+	b.tracer, err = newTracer(&buf, nil, TraceFormatJSONL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	q := &testQuery{HumanLabel: []byte("q")}
+	if _, ok := b.processQuery(&failingProcessor{failures: 1}, q, 0); !ok {
+		t.Fatalf("query failed after a retry")
+	}
+	if err := b.tracer.close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var records []TraceRecord
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var r TraceRecord
+		if err := json.Unmarshal(scanner.Bytes(), &r); err != nil {
+			t.Fatalf("could not parse trace record %s: %v", scanner.Text(), err)
+		}
+		records = append(records, r)
+	}
+	if len(records) != 2 {
+		t.Fatalf("incorrect number of records: got %d want 2", len(records))
+	}
+	// the failed run has no stats, its latency is the wall time
+	if records[0].Error == "" || records[0].LatencyMs == 1 {
+		t.Errorf("incorrect failed record: %+v", records[0])
+	}
+	// the successful run has the latency reported by the processor
+	if records[1].Error != "" || records[1].LatencyMs != 1 {
+		t.Errorf("incorrect latency: got %v want 1", records[1].LatencyMs)
+	}
+}
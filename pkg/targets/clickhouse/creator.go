@@ -8,6 +8,7 @@ import (
 	_ "github.com/kshvakov/clickhouse"
 	"github.com/timescale/tsbs/pkg/data/usecases/common"
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 )
 
 // loader.DBCreator interface implementation
@@ -97,6 +98,56 @@ func (d *dbCreator) CreateDB(dbName string) error {
 	return nil
 }
 
+// loader.DBCreatorRollup interface implementation
+func (d *dbCreator) CreateRollups(dbName string) error {
+	db := sqlx.MustConnect(dbType, getConnectString(d.config, true))
+	defer db.Close()
+
+	for tableName, fieldColumns := range d.headers.FieldKeys {
+		for _, sql := range generateRollupQueries(tableName, fieldColumns) {
+			if d.config.Debug > 0 {
+				fmt.Println(sql)
+			}
+			if _, err := db.Exec(sql); err != nil {
+				return fmt.Errorf("could not create rollup for %s: %v", tableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// generateRollupQueries builds the statements creating an hourly AggregatingMergeTree
+// materialized view for the given table. Each column holds the avgState of the
+// original column of the same name and must be read back with avgMerge.
+func generateRollupQueries(tableName string, fieldColumns []string) []string {
+	rollupName := tableName + constants.RollupSuffix
+	var selectClauses []string
+	for _, column := range fieldColumns {
+		if len(column) == 0 {
+			continue
+		}
+		selectClauses = append(selectClauses, fmt.Sprintf("avgState(%[1]s) AS %[1]s", column))
+	}
+
+	return []string{
+		fmt.Sprintf("DROP TABLE IF EXISTS %s", rollupName),
+		fmt.Sprintf(`
+			CREATE MATERIALIZED VIEW %s
+			ENGINE = AggregatingMergeTree() PARTITION BY toYYYYMM(hour) ORDER BY (tags_id, hour)
+			POPULATE AS SELECT
+				toStartOfInterval(created_at, INTERVAL %d second) AS hour,
+				tags_id,
+				%s
+			FROM %s
+			GROUP BY hour, tags_id
+			`,
+			rollupName,
+			constants.RollupBucketSeconds,
+			strings.Join(selectClauses, ", "),
+			tableName),
+	}
+}
+
 // createTagsTable builds CREATE TABLE SQL statement and runs it
 func createTagsTable(conf *ClickhouseConfig, db *sqlx.DB, tagNames, tagTypes []string) {
 	sql := generateTagsTableQuery(tagNames, tagTypes)
@@ -2,6 +2,7 @@ package clickhouse
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -66,3 +67,24 @@ func TestGenerateTagsTableQueryPanicOnWrongType(t *testing.T) {
 
 	t.Fatalf("test should have stopped at this point")
 }
+
+func TestGenerateRollupQueries(t *testing.T) {
+	got := generateRollupQueries("cpu", []string{"usage_user", "", "usage_system"})
+	if len(got) != 2 {
+		t.Fatalf("incorrect number of queries: got %d want 2", len(got))
+	}
+	if got[0] != "DROP TABLE IF EXISTS cpu_1h" {
+		t.Errorf("incorrect drop query: %s", got[0])
+	}
+	for _, want := range []string{
+		"CREATE MATERIALIZED VIEW cpu_1h",
+		"ENGINE = AggregatingMergeTree()",
+		"toStartOfInterval(created_at, INTERVAL 3600 second) AS hour",
+		"avgState(usage_user) AS usage_user, avgState(usage_system) AS usage_system",
+		"FROM cpu",
+	} {
+		if !strings.Contains(got[1], want) {
+			t.Errorf("create query does not contain %q:\n%s", want, got[1])
+		}
+	}
+}
@@ -32,3 +32,13 @@ func SupportedFormats() []string {
 		FormatQuestDB,
 	}
 }
+
+// Rollups built between load and query phases
+const (
+	// RollupSuffix is appended to a measurement name to get the name of its rollup
+	RollupSuffix = "_1h"
+	// RollupBucketSeconds is the size of the time buckets aggregated in a rollup
+	RollupBucketSeconds = 3600
+	// RollupLabelSuffix is appended to the human label of queries reading from a rollup
+	RollupLabelSuffix = " (rollup)"
+)
@@ -34,3 +34,13 @@ type DBCreatorPost interface {
 	// PostCreateDB does further initialization after the database is created
 	PostCreateDB(dbName string) error
 }
+
+// DBCreatorRollup is a DBCreator that can also build pre-aggregated rollups
+// (e.g. continuous aggregates or materialized views) of the loaded data once
+// the load has finished.
+type DBCreatorRollup interface {
+	DBCreator
+
+	// CreateRollups builds the rollups for the data loaded into the given database
+	CreateRollups(dbName string) error
+}
@@ -83,7 +83,7 @@ func appendField(buf, key []byte, v interface{}) []byte {
 	buf = append(buf, key...)
 	buf = append(buf, '=')
 
-	buf = serialize.FastFormatAppend(v, buf)
+	buf = serialize.QuotedFormatAppend(v, buf)
 
 	// Influx uses 'i' to indicate integers:
 	switch v.(type) {
@@ -30,6 +30,10 @@ func TestInfluxSerializerSerialize(t *testing.T) {
 			Desc:       "a Point with a nil tag",
 			InputPoint: serialize.TestPointWithNilTag(),
 			Output:     "cpu usage_guest_nice=38.24311829 1451606400000000000\n",
+		}, {
+			Desc:       "a Point with a string field",
+			InputPoint: serialize.TestPointStringField(),
+			Output:     "cpu,hostname=host_0,region=eu-west-1,datacenter=eu-west-1b message=\"key \\\"cart\\\" not found\",usage_guest_nice=38.24311829 1451606400000000000\n",
 		}, {
 			Desc:       "a Point with a nil field",
 			InputPoint: serialize.TestPointWithNilField(),
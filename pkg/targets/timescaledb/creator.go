@@ -9,12 +9,14 @@ import (
 	"time"
 
 	"github.com/timescale/tsbs/pkg/targets"
+	"github.com/timescale/tsbs/pkg/targets/constants"
 
 	_ "github.com/jackc/pgx/v4/stdlib"
 )
 
 const (
 	tagsKey      = "tags"
+	schemaKey    = "schema"
 	TimeValueIdx = "TIME-VALUE"
 	ValueTimeIdx = "VALUE-TIME"
 )
@@ -24,6 +26,10 @@ var fatal = log.Fatalf
 
 var tableCols = make(map[string][]string)
 
+// tableColTypes holds the types of the columns of the tables whose columns
+// are not all float64, in the order of tableCols
+var tableColTypes = make(map[string][]string)
+
 type dbCreator struct {
 	driver  string
 	ds      targets.DataSource
@@ -100,6 +106,7 @@ func (d *dbCreator) PostCreateDB(dbName string) error {
 	for tableName, columns := range headers.FieldKeys {
 		// tableCols is a global map. Globally cache the available columns for the given table
 		tableCols[tableName] = columns
+		tableColTypes[tableName] = headers.FieldTypes[tableName]
 		fieldDefs, indexDefs := d.getFieldAndIndexDefinitions(tableName, columns)
 		if d.opts.CreateMetricsTable {
 			d.createTableAndIndexes(dbBench, tableName, fieldDefs, indexDefs)
@@ -138,11 +145,15 @@ func (d *dbCreator) getFieldAndIndexDefinitions(tableName string, columns []stri
 
 	allCols = append(allCols, columns...)
 	extraCols := 0 // set to 1 when hostname is kept in-table
+	fieldTypes := tableColTypes[tableName]
 	for idx, field := range allCols {
 		if len(field) == 0 {
 			continue
 		}
 		fieldType := "DOUBLE PRECISION"
+		if fieldTypes != nil && idx >= len(allCols)-len(columns) {
+			fieldType = serializedTypeToPgType(fieldTypes[idx-len(allCols)+len(columns)])
+		}
 		idxType := d.opts.FieldIndex
 		// This condition handles the case where we keep the primary tag key in the table
 		// and partition on it. Since under the current implementation this tag is always
@@ -249,6 +260,68 @@ func (d *dbCreator) getCreateIndexOnFieldCmds(hypertable, field, idxType string)
 	return ret
 }
 
+// CreateRollups builds an hourly rollup of every metrics table. With hypertables
+// the rollup is a continuous aggregate, otherwise a regular materialized view.
+func (d *dbCreator) CreateRollups(dbName string) error {
+	dbBench := MustConnect(d.driver, d.opts.GetConnectString(dbName))
+	defer dbBench.Close()
+
+	headers := d.ds.Headers()
+	for tableName, columns := range headers.FieldKeys {
+		for _, q := range generateRollupQueries(tableName, numericColumns(columns, headers.FieldTypes[tableName]), d.opts.UseHypertable) {
+			if _, err := dbBench.Exec(q); err != nil {
+				return fmt.Errorf("could not create rollup for %s: %v", tableName, err)
+			}
+		}
+	}
+	return nil
+}
+
+// numericColumns returns columns with the ones of type string blanked out,
+// since they cannot be averaged.
+func numericColumns(columns, types []string) []string {
+	if types == nil {
+		return columns
+	}
+	numeric := make([]string, len(columns))
+	for i, column := range columns {
+		if types[i] != "string" {
+			numeric[i] = column
+		}
+	}
+	return numeric
+}
+
+// generateRollupQueries returns the statements needed to create and populate the
+// rollup of tableName. The rollup keeps the column names of the original table,
+// holding the average of each column per tags_id and time bucket.
+func generateRollupQueries(tableName string, columns []string, useHypertable bool) []string {
+	rollupName := tableName + constants.RollupSuffix
+	var selectClauses []string
+	for _, column := range columns {
+		if len(column) == 0 {
+			continue
+		}
+		selectClauses = append(selectClauses, fmt.Sprintf("avg(%[1]s) AS %[1]s", column))
+	}
+
+	if !useHypertable {
+		return []string{
+			fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s", rollupName),
+			fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS SELECT to_timestamp(((extract(epoch from time)::int)/%[2]d)*%[2]d) AS bucket, tags_id, %[3]s FROM %[4]s GROUP BY 1, 2",
+				rollupName, constants.RollupBucketSeconds, strings.Join(selectClauses, ", "), tableName),
+			fmt.Sprintf("CREATE INDEX ON %s(tags_id, bucket DESC)", rollupName),
+		}
+	}
+
+	return []string{
+		fmt.Sprintf("DROP MATERIALIZED VIEW IF EXISTS %s", rollupName),
+		fmt.Sprintf("CREATE MATERIALIZED VIEW %s WITH (timescaledb.continuous) AS SELECT time_bucket('%d seconds', time) AS bucket, tags_id, %s FROM %s GROUP BY 1, 2 WITH NO DATA",
+			rollupName, constants.RollupBucketSeconds, strings.Join(selectClauses, ", "), tableName),
+		fmt.Sprintf("CALL refresh_continuous_aggregate('%s', NULL, NULL)", rollupName),
+	}
+}
+
 func createTagsTable(db *sql.DB, tagNames, tagTypes []string, useJSON bool) {
 	MustExec(db, "DROP TABLE IF EXISTS tags")
 	if useJSON {
@@ -131,6 +131,7 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 		columns         []string
 		fieldIndexCount int
 		inTableTag      bool
+		columnTypes     []string
 		wantFieldDefs   []string
 		wantIndexDefs   []string
 	}{
@@ -179,6 +180,16 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 			wantFieldDefs:   []string{"usage_user DOUBLE PRECISION", "usage_system DOUBLE PRECISION", "usage_idle DOUBLE PRECISION", "usage_nice DOUBLE PRECISION"},
 			wantIndexDefs:   []string{"CREATE INDEX ON cpu (usage_user, time DESC)", "CREATE INDEX ON cpu (usage_system, time DESC)"},
 		},
+		{
+			desc:            "typed fields, in table tag",
+			tableName:       "logs",
+			columns:         []string{"message", "status", "duration"},
+			columnTypes:     []string{"string", "int64", "float64"},
+			fieldIndexCount: 0,
+			inTableTag:      true,
+			wantFieldDefs:   []string{"hostname TEXT", "message TEXT", "status BIGINT", "duration DOUBLE PRECISION"},
+			wantIndexDefs:   []string{},
+		},
 	}
 
 	for _, c := range cases {
@@ -186,6 +197,7 @@ func TestDBCreatorGetFieldAndIndexDefinitions(t *testing.T) {
 		// Initialize global cache
 		tableCols[tagsKey] = []string{}
 		tableCols[tagsKey] = append(tableCols[tagsKey], "hostname")
+		tableColTypes[c.tableName] = c.columnTypes
 		dbc := &dbCreator{opts: &LoadingOptions{
 			InTableTag:      c.inTableTag,
 			FieldIndexCount: c.fieldIndexCount,
@@ -254,3 +266,44 @@ func TestGenerateTagsTableQueryPanicOnWrongType(t *testing.T) {
 
 	t.Fatalf("test should have stopped at this point")
 }
+
+func TestGenerateRollupQueries(t *testing.T) {
+	testCases := []struct {
+		desc          string
+		useHypertable bool
+		want          []string
+	}{
+		{
+			desc:          "continuous aggregate",
+			useHypertable: true,
+			want: []string{
+				"DROP MATERIALIZED VIEW IF EXISTS cpu_1h",
+				"CREATE MATERIALIZED VIEW cpu_1h WITH (timescaledb.continuous) AS SELECT time_bucket('3600 seconds', time) AS bucket, tags_id, avg(usage_user) AS usage_user, avg(usage_system) AS usage_system FROM cpu GROUP BY 1, 2 WITH NO DATA",
+				"CALL refresh_continuous_aggregate('cpu_1h', NULL, NULL)",
+			},
+		},
+		{
+			desc:          "plain materialized view",
+			useHypertable: false,
+			want: []string{
+				"DROP MATERIALIZED VIEW IF EXISTS cpu_1h",
+				"CREATE MATERIALIZED VIEW cpu_1h AS SELECT to_timestamp(((extract(epoch from time)::int)/3600)*3600) AS bucket, tags_id, avg(usage_user) AS usage_user, avg(usage_system) AS usage_system FROM cpu GROUP BY 1, 2",
+				"CREATE INDEX ON cpu_1h(tags_id, bucket DESC)",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			got := generateRollupQueries("cpu", []string{"usage_user", "", "usage_system"}, tc.useHypertable)
+			if len(got) != len(tc.want) {
+				t.Fatalf("incorrect number of queries: got %d want %d", len(got), len(tc.want))
+			}
+			for i := range got {
+				if got[i] != tc.want[i] {
+					t.Errorf("incorrect query %d:\ngot\n%s\nwant\n%s", i, got[i], tc.want[i])
+				}
+			}
+		})
+	}
+}
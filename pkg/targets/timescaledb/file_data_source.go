@@ -18,6 +18,9 @@ func newFileDataSource(fileName string) targets.DataSource {
 type fileDataSource struct {
 	scanner *bufio.Scanner
 	headers *common.GeneratedDataHeaders
+	// columns are the columns of the tables written by the last schema
+	// changes of the data, if any
+	columns map[string]*tableColumns
 }
 
 func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
@@ -61,16 +64,21 @@ func (d *fileDataSource) Headers() *common.GeneratedDataHeaders {
 	}
 	tagNames, tagTypes := extractTagNamesAndTypes(tagsarr[1:])
 	fieldKeys := make(map[string][]string)
+	fieldTypes := make(map[string][]string)
 	for _, tableDef := range cols {
 		columns := strings.Split(tableDef, ",")
 		tableName := columns[0]
-		colNames := columns[1:]
+		colNames, colTypes := extractFieldNamesAndTypes(columns[1:])
 		fieldKeys[tableName] = colNames
+		if colTypes != nil {
+			fieldTypes[tableName] = colTypes
+		}
 	}
 	d.headers = &common.GeneratedDataHeaders{
-		TagTypes:  tagTypes,
-		TagKeys:   tagNames,
-		FieldKeys: fieldKeys,
+		TagTypes:   tagTypes,
+		TagKeys:    tagNames,
+		FieldKeys:  fieldKeys,
+		FieldTypes: fieldTypes,
 	}
 	return d.headers
 }
@@ -81,16 +89,25 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 		return data.LoadedPoint{}
 	}
 	newPoint := &insertData{}
-	ok := d.scanner.Scan()
-	if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
-		return data.LoadedPoint{}
-	} else if !ok {
-		fatal("scan error: %v", d.scanner.Err())
-		return data.LoadedPoint{}
+	var parts []string
+	for {
+		ok := d.scanner.Scan()
+		if !ok && d.scanner.Err() == nil { // nothing scanned & no error = EOF
+			return data.LoadedPoint{}
+		} else if !ok {
+			fatal("scan error: %v", d.scanner.Err())
+			return data.LoadedPoint{}
+		}
+		parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
+		// Schema changes can come before the points, with the new columns
+		// of a table in the format of the header
+		if parts[0] != schemaKey || len(parts) < 2 {
+			break
+		}
+		d.changeSchema(parts[1])
 	}
 
 	// The first line is a CSV line of tags with the first element being "tags"
-	parts := strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
 	prefix := parts[0]
 	if prefix != tagsKey {
 		fatal("data file in invalid format; got %s expected %s", prefix, tagsKey)
@@ -99,7 +116,7 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	newPoint.tags = parts[1]
 
 	// Scan again to get the data line
-	ok = d.scanner.Scan()
+	ok := d.scanner.Scan()
 	if !ok {
 		fatal("scan error: %v", d.scanner.Err())
 		return data.LoadedPoint{}
@@ -107,9 +124,42 @@ func (d *fileDataSource) NextItem() data.LoadedPoint {
 	parts = strings.SplitN(d.scanner.Text(), ",", 2) // prefix & then rest of line
 	prefix = parts[0]
 	newPoint.fields = parts[1]
+	newPoint.columns = d.columns[prefix]
 
 	return data.NewLoadedPoint(&point{
 		hypertable: prefix,
 		row:        newPoint,
 	})
 }
+
+// changeSchema sets the columns of the table of the schema change tableDef,
+// the table name followed by its columns, for the rows that follow.
+func (d *fileDataSource) changeSchema(tableDef string) {
+	columns := strings.Split(tableDef, ",")
+	names, types := extractFieldNamesAndTypes(columns[1:])
+	if d.columns == nil {
+		d.columns = make(map[string]*tableColumns)
+	}
+	d.columns[columns[0]] = &tableColumns{names: names, types: types}
+}
+
+// extractFieldNamesAndTypes splits the columns of a table of the header into
+// their names and types. The columns of the tables whose fields are all
+// float64 have no type, and nil types are returned for them.
+func extractFieldNamesAndTypes(columns []string) ([]string, []string) {
+	if len(columns) == 0 || !strings.Contains(columns[0], " ") {
+		return columns, nil
+	}
+	names := make([]string, len(columns))
+	types := make([]string, len(columns))
+	for i, colWithType := range columns {
+		colAndType := strings.Split(colWithType, " ")
+		if len(colAndType) != 2 {
+			fatal("field header has invalid format: '%s'", colWithType)
+			return nil, nil
+		}
+		names[i] = colAndType[0]
+		types[i] = colAndType[1]
+	}
+	return names, types
+}
@@ -34,8 +34,27 @@ type syncCSI struct {
 type insertData struct {
 	tags   string
 	fields string
+	// columns are the columns of the fields, nil for those of the header
+	columns *tableColumns
 }
 
+// tableColumns are the columns of a table written by a schema change of the
+// data, which hold for the rows that follow it.
+type tableColumns struct {
+	names []string
+	types []string // nil if all float64
+	// altered is done once the table has the columns
+	altered sync.Once
+}
+
+// tableSchemas holds the types of the columns of the tables, by table and
+// column, as altered by the schema changes of the data. It is shared by all
+// the workers.
+var tableSchemas = struct {
+	sync.Mutex
+	m map[string]map[string]string
+}{m: make(map[string]map[string]string)}
+
 func newSyncCSI() *syncCSI {
 	return &syncCSI{
 		m:     make(map[string]int64),
@@ -108,25 +127,36 @@ func (p *processor) sqlTagsToCacheLine(res *sql.Rows, err error, tagCols []strin
 			panic(err)
 		}
 
-		var key string
+		values := make([]string, len(tagCols))
 		if p.opts.UseJSON {
 			decodedTagset := map[string]string{}
 			json.Unmarshal(resVals[1].([]byte), &decodedTagset)
-			key = decodedTagset[tagCols[0]]
+			for i, col := range tagCols {
+				values[i] = decodedTagset[col]
+			}
 		} else {
-			key = fmt.Sprintf("%v", resVals[1])
+			for i := range tagCols {
+				values[i] = fmt.Sprintf("%v", resVals[i+1])
+			}
 		}
-		ret[key] = resVals[0].(int64)
+		ret[tagSetKey(values)] = resVals[0].(int64)
 	}
 	res.Close()
 	return ret
 }
 
+// tagSetKey returns the key of the tags cache for the values of the common
+// tags. The first tag alone does not tell the tag sets apart in every use
+// case, e.g. many flows share their source IP in netflow.
+func tagSetKey(values []string) string {
+	return strings.Join(values, ",")
+}
+
 // splitTagsAndMetrics takes an array of insertData (sharded by hypertable) and
 // divides the tags from data into appropriate slices that can then be used in
 // SQL queries to insert into their respective tables. Additionally, it also
 // returns the number of metrics (i.e., non-tag fields) for the data processed.
-func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]string, [][]interface{}, uint64) {
+func (p *processor) splitTagsAndMetrics(rows []*insertData, fieldTypes []string, dataCols int) ([][]string, [][]interface{}, uint64) {
 	tagRows := make([][]string, 0, len(rows))
 	dataRows := make([][]interface{}, 0, len(rows))
 	numMetrics := uint64(0)
@@ -162,18 +192,32 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 		if p.opts.InTableTag {
 			r = append(r, tags[0])
 		}
-		for _, v := range metrics[1:] {
+		for i, v := range metrics[1:] {
 			if v == "" {
 				r = append(r, nil)
 				continue
 			}
 
-			num, err := strconv.ParseFloat(v, 64)
-			if err != nil {
-				panic(err)
+			fieldType := "float64"
+			if fieldTypes != nil {
+				fieldType = fieldTypes[i]
+			}
+			switch fieldType {
+			case "string":
+				r = append(r, v)
+			case "int32", "int64":
+				num, err := strconv.ParseInt(v, 10, 64)
+				if err != nil {
+					panic(err)
+				}
+				r = append(r, num)
+			default:
+				num, err := strconv.ParseFloat(v, 64)
+				if err != nil {
+					panic(err)
+				}
+				r = append(r, num)
 			}
-
-			r = append(r, num)
 		}
 
 		dataRows = append(dataRows, r)
@@ -184,17 +228,39 @@ func (p *processor) splitTagsAndMetrics(rows []*insertData, dataCols int) ([][]s
 }
 
 func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
-	colLen := len(tableCols[hypertable]) + numExtraCols
+	numMetrics := uint64(0)
+	// The rows after a schema change have its columns, so the consecutive
+	// rows with the same columns are inserted together
+	for len(rows) > 0 {
+		n := 1
+		for n < len(rows) && rows[n].columns == rows[0].columns {
+			n++
+		}
+		numMetrics += p.insertRows(hypertable, rows[:n])
+		rows = rows[n:]
+	}
+	return numMetrics
+}
+
+// insertRows inserts rows with the same columns into hypertable, once it has
+// their columns.
+func (p *processor) insertRows(hypertable string, rows []*insertData) uint64 {
+	columns, fieldTypes := tableCols[hypertable], tableColTypes[hypertable]
+	if c := rows[0].columns; c != nil {
+		p.alterTable(hypertable, c)
+		columns, fieldTypes = c.names, c.types
+	}
+	colLen := len(columns) + numExtraCols
 	if p.opts.InTableTag {
 		colLen++
 	}
-	tagRows, dataRows, numMetrics := p.splitTagsAndMetrics(rows, colLen)
+	tagRows, dataRows, numMetrics := p.splitTagsAndMetrics(rows, fieldTypes, colLen)
 
 	// Check if any of these tags has yet to be inserted
 	newTags := make([][]string, 0, len(rows))
 	p._csi.mutex.RLock()
 	for _, cols := range tagRows {
-		if _, ok := p._csi.m[cols[0]]; !ok {
+		if _, ok := p._csi.m[tagSetKey(cols)]; !ok {
 			newTags = append(newTags, cols)
 		}
 	}
@@ -210,8 +276,7 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 
 	p._csi.mutex.RLock()
 	for i := range dataRows {
-		tagKey := tagRows[i][0]
-		dataRows[i][1] = p._csi.m[tagKey]
+		dataRows[i][1] = p._csi.m[tagSetKey(tagRows[i])]
 	}
 	p._csi.mutex.RUnlock()
 
@@ -220,7 +285,7 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	if p.opts.InTableTag {
 		cols = append(cols, tableCols[tagsKey][0])
 	}
-	cols = append(cols, tableCols[hypertable]...)
+	cols = append(cols, columns...)
 
 	if p.opts.ForceTextFormat {
 		tx := MustBegin(p._db)
@@ -287,6 +352,64 @@ func (p *processor) processCSI(hypertable string, rows []*insertData) uint64 {
 	return numMetrics
 }
 
+// alterTable alters hypertable so that it has the columns c, once for all the
+// workers.
+func (p *processor) alterTable(hypertable string, c *tableColumns) {
+	c.altered.Do(func() {
+		tableSchemas.Lock()
+		defer tableSchemas.Unlock()
+		types, ok := tableSchemas.m[hypertable]
+		if !ok {
+			types = make(map[string]string)
+			for i, name := range tableCols[hypertable] {
+				types[name] = columnType(tableColTypes[hypertable], i)
+			}
+			tableSchemas.m[hypertable] = types
+		}
+		for _, q := range alterTableQueries(hypertable, types, c) {
+			MustExec(p._db, q)
+		}
+	})
+}
+
+// alterTableQueries returns the statements adding the columns of c missing
+// from the columns of hypertable of types, and switching the integer columns
+// whose type became float to it. Columns never switch back, the late rows
+// of an earlier schema are inserted into the columns as they are. It updates
+// types with the changes.
+func alterTableQueries(hypertable string, types map[string]string, c *tableColumns) []string {
+	var queries []string
+	for i, name := range c.names {
+		if len(name) == 0 {
+			continue
+		}
+		newType := columnType(c.types, i)
+		oldType, ok := types[name]
+		if !ok {
+			queries = append(queries, fmt.Sprintf("ALTER TABLE %s ADD COLUMN IF NOT EXISTS %s %s", hypertable, name, serializedTypeToPgType(newType)))
+		} else if isIntType(oldType) && !isIntType(newType) && newType != "string" {
+			queries = append(queries, fmt.Sprintf("ALTER TABLE %s ALTER COLUMN %s TYPE %s", hypertable, name, serializedTypeToPgType(newType)))
+		} else {
+			continue
+		}
+		types[name] = newType
+	}
+	return queries
+}
+
+// columnType returns the type of the column i of types, float64 if types is
+// nil.
+func columnType(types []string, i int) string {
+	if types == nil {
+		return "float64"
+	}
+	return types[i]
+}
+
+func isIntType(serializedType string) bool {
+	return serializedType == "int32" || serializedType == "int64"
+}
+
 func newProcessor(opts *LoadingOptions, driver, dbName string) *processor {
 	return &processor{
 		opts:   opts,
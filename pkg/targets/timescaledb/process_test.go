@@ -52,6 +52,7 @@ func TestSubsystemTagsToJSON(t *testing.T) {
 func TestSplitTagsAndMetrics(t *testing.T) {
 	numCols := 3
 	tableCols[tagsKey] = []string{"tag1", "tag2"}
+	tableColTypes["typed"] = []string{"string", "int64", "float64"}
 	toTS := func(s string) string {
 		timeInt, err := strconv.ParseInt(s, 10, 64)
 		if err != nil {
@@ -84,6 +85,7 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 
 	cases := []struct {
 		desc        string
+		hypertable  string
 		rows        []*insertData
 		inTableTag  bool
 		wantMetrics uint64
@@ -182,6 +184,26 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 				[]interface{}{toTS("100"), nil, nil, nil, 5.0, 42.0},
 			},
 		},
+		{
+			desc:       "typed fields",
+			hypertable: "typed",
+			rows: []*insertData{
+				{
+					tags:   "tag1=foo,tag2=bar",
+					fields: "100,not found,5,42",
+				},
+				{
+					tags:   "tag1=foo,tag2=bar",
+					fields: "200,,,42.5",
+				},
+			},
+			wantMetrics: 6,
+			wantTags:    [][]string{{"foo", "bar"}, {"foo", "bar"}},
+			wantData: [][]interface{}{
+				{toTS("100"), nil, nil, "not found", int64(5), 42.0},
+				{toTS("200"), nil, nil, nil, nil, 42.5},
+			},
+		},
 	}
 
 	for _, c := range cases {
@@ -194,13 +216,13 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 					t.Errorf("%s: did not panic when should", c.desc)
 				}
 			}()
-			p.splitTagsAndMetrics(c.rows, numCols+numExtraCols)
+			p.splitTagsAndMetrics(c.rows, tableColTypes[c.hypertable], numCols+numExtraCols)
 		}
 
 		oldInTableTag := p.opts.InTableTag
 		p.opts.InTableTag = c.inTableTag
 
-		gotTags, gotData, numMetrics := p.splitTagsAndMetrics(c.rows, numCols+numExtraCols)
+		gotTags, gotData, numMetrics := p.splitTagsAndMetrics(c.rows, tableColTypes[c.hypertable], numCols+numExtraCols)
 		if numMetrics != c.wantMetrics {
 			t.Errorf("%s: number of metrics incorrect: got %d want %d", c.desc, numMetrics, c.wantMetrics)
 		}
@@ -254,6 +276,40 @@ func TestSplitTagsAndMetrics(t *testing.T) {
 	}
 }
 
+func TestAlterTableQueries(t *testing.T) {
+	types := map[string]string{"usage": "int64", "total": "float64", "name": "string"}
+	c := &tableColumns{
+		names: []string{"usage", "total", "name", "count", "ratio"},
+		types: []string{"float64", "int64", "string", "int64", "float32"},
+	}
+	want := []string{
+		"ALTER TABLE cpu ALTER COLUMN usage TYPE DOUBLE PRECISION",
+		"ALTER TABLE cpu ADD COLUMN IF NOT EXISTS count BIGINT",
+		"ALTER TABLE cpu ADD COLUMN IF NOT EXISTS ratio FLOAT",
+	}
+	got := alterTableQueries("cpu", types, c)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect queries: got %v want %v", got, want)
+	}
+	wantTypes := map[string]string{"usage": "float64", "total": "float64", "name": "string", "count": "int64", "ratio": "float32"}
+	if !reflect.DeepEqual(types, wantTypes) {
+		t.Errorf("incorrect types after the changes: got %v want %v", types, wantTypes)
+	}
+
+	// the columns do not switch back for the late rows of an earlier schema
+	earlier := &tableColumns{names: []string{"usage", "total"}, types: []string{"int64", "float64"}}
+	if got := alterTableQueries("cpu", types, earlier); len(got) != 0 {
+		t.Errorf("unexpected queries for an earlier schema: %v", got)
+	}
+
+	// the columns of tables without types are float64
+	got = alterTableQueries("mem", map[string]string{"free": "float64"}, &tableColumns{names: []string{"free", "used"}})
+	want = []string{"ALTER TABLE mem ADD COLUMN IF NOT EXISTS used DOUBLE PRECISION"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("incorrect queries without types: got %v want %v", got, want)
+	}
+}
+
 func TestGenBatchInsertStmt(t *testing.T) {
 	cols := []string{"col1", "col2", "col3"}
 	stmt := genBatchInsertStmt("test", cols, 2)
@@ -175,14 +175,49 @@ func TestDecodeEOF(t *testing.T) {
 	}
 }
 
+func TestDecodeSchemaChange(t *testing.T) {
+	input := "tags,tag1text\ncpu,140,0.0\n" +
+		"schema,cpu,usage int64,total float64\n" +
+		"tags,tag1text\ncpu,150,1,2.5\n" +
+		"tags,tag1text\nmem,150,3.0\n" +
+		"schema,mem,free\nschema,disk,used\n" +
+		"tags,tag1text\ncpu,160,1,2.5\n" +
+		"tags,tag1text\nmem,160,3.0\n"
+	br := bufio.NewReader(bytes.NewReader([]byte(input)))
+	ds := &fileDataSource{headers: &common.GeneratedDataHeaders{}, scanner: bufio.NewScanner(br)}
+
+	// the columns of the schema changes hold for the rows that follow them,
+	// the other rows have the columns of the header
+	want := []string{"", "usage,total int64,float64", "", "usage,total int64,float64", "free "}
+	var columns []*tableColumns
+	for i, w := range want {
+		p := ds.NextItem().Data.(*point)
+		got := ""
+		if c := p.row.columns; c != nil {
+			got = strings.Join(c.names, ",") + " " + strings.Join(c.types, ",")
+		}
+		if got != w {
+			t.Errorf("point %d: incorrect columns: got %q want %q", i, got, w)
+		}
+		columns = append(columns, p.row.columns)
+	}
+	if columns[1] != columns[3] {
+		t.Errorf("rows of the same schema change have different columns")
+	}
+	if p := ds.NextItem(); p.Data != nil {
+		t.Errorf("expected no point after the last one, got %v", p)
+	}
+}
+
 func TestFileDataSourceHeaders(t *testing.T) {
 	cases := []struct {
-		desc        string
-		input       string
-		wantTags    string
-		wantTypes   string
-		wantCols    map[string]string
-		shouldFatal bool
+		desc           string
+		input          string
+		wantTags       string
+		wantTypes      string
+		wantCols       map[string]string
+		wantFieldTypes map[string]string
+		shouldFatal    bool
 	}{
 		{
 			desc:      "min case: exactly three lines",
@@ -212,6 +247,14 @@ func TestFileDataSourceHeaders(t *testing.T) {
 			wantTypes: "tagT,tag2",
 			wantCols:  map[string]string{"cols": "col1,col2", "cols2": "col21,col22"},
 		},
+		{
+			desc:           "typed fields",
+			input:          "tags,tag1 string\ncols,col1,col2\nlogs,message string,status int64,duration float64\n\n",
+			wantTags:       "tag1",
+			wantTypes:      "string",
+			wantCols:       map[string]string{"cols": "col1,col2", "logs": "message,status,duration"},
+			wantFieldTypes: map[string]string{"logs": "string,int64,float64"},
+		},
 		{
 			desc:        "too few lines",
 			input:       "tags\ncols\n",
@@ -261,6 +304,14 @@ func TestFileDataSourceHeaders(t *testing.T) {
 					t.Errorf("%s: cols for table %s, incorrect: got\n%s\nwant\n%s\n", c.desc, table, got, c.wantCols[table])
 				}
 			}
+			if len(headers.FieldTypes) != len(c.wantFieldTypes) {
+				t.Errorf("%s: incorrect field types len: got %d want %d", c.desc, len(headers.FieldTypes), len(c.wantFieldTypes))
+			}
+			for table, types := range headers.FieldTypes {
+				if got := strings.Join(types, ","); got != c.wantFieldTypes[table] {
+					t.Errorf("%s: field types for table %s, incorrect: got %s want %s", c.desc, table, got, c.wantFieldTypes[table])
+				}
+			}
 		}
 	}
 }
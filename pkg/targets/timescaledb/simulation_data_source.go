@@ -19,6 +19,9 @@ func newSimulationDataSource(sim common.Simulator) targets.DataSource {
 type simulationDataSource struct {
 	simulator common.Simulator
 	headers   *common.GeneratedDataHeaders
+	// columns are the columns of the tables after the last changes of the
+	// fields reported by the simulator, if any
+	columns map[string]*tableColumns
 }
 
 func (d *simulationDataSource) Headers() *common.GeneratedDataHeaders {
@@ -48,6 +51,16 @@ func (d *simulationDataSource) NextItem() data.LoadedPoint {
 		return data.LoadedPoint{}
 	}
 	newLoadPoint := &insertData{}
+	hypertable := string(newSimulatorPoint.MeasurementName())
+	if r, ok := d.simulator.(common.SchemaReporter); ok {
+		if fields, types, changed := r.SchemaChange(); changed {
+			if d.columns == nil {
+				d.columns = make(map[string]*tableColumns)
+			}
+			d.columns[hypertable] = &tableColumns{names: fields, types: types}
+		}
+		newLoadPoint.columns = d.columns[hypertable]
+	}
 	tagValues := newSimulatorPoint.TagValues()
 	tagKeys := newSimulatorPoint.TagKeys()
 	buf := make([]byte, 0, 256)
@@ -72,7 +85,7 @@ func (d *simulationDataSource) NextItem() data.LoadedPoint {
 	newLoadPoint.fields = string(buf)
 
 	return data.NewLoadedPoint(&point{
-		hypertable: string(newSimulatorPoint.MeasurementName()),
+		hypertable: hypertable,
 		row:        newLoadPoint,
 	})
 }